@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"fitness-center-manager/internal/config"
+	"fitness-center-manager/internal/jobs"
+	"fitness-center-manager/internal/store/pgstore"
+)
+
+// defaultSubscriptionStatusCron — каждый час в :05, чтобы смена суток
+// подхватывалась быстро, а повторные запуски ничего не стоили.
+const defaultSubscriptionStatusCron = "5 * * * *"
+
+// defaultMaintenanceOverdueCron — раз в сутки, утром до открытия.
+const defaultMaintenanceOverdueCron = "20 6 * * *"
+
+// startJobs регистрирует фоновые задачи и запускает их до ctx.Done.
+// С jobs.disabled задачи в этом экземпляре не идут (их выполнит другой),
+// но журнал запусков на /admin/jobs по-прежнему виден. Отметка
+// просроченного ТО регистрируется только с flagOverdue. Расписание и
+// «сегодня» задач — в часовом поясе клуба loc.
+func startJobs(ctx context.Context, cfg config.JobsConfig, flagOverdue bool, st *pgstore.Store, loc *time.Location) *jobs.Scheduler {
+	spec := cfg.SubscriptionStatusCron
+	if spec == "" {
+		spec = defaultSubscriptionStatusCron
+	}
+	cron, err := jobs.ParseCron(spec)
+	if err != nil {
+		log.Fatalf("❌ jobs.subscription_status_cron: %v", err)
+	}
+
+	s := jobs.New(st, st, loc)
+	s.Add(jobs.SubscriptionStatus(cron))
+	if flagOverdue {
+		spec := cfg.MaintenanceOverdueCron
+		if spec == "" {
+			spec = defaultMaintenanceOverdueCron
+		}
+		cron, err := jobs.ParseCron(spec)
+		if err != nil {
+			log.Fatalf("❌ jobs.maintenance_overdue_cron: %v", err)
+		}
+		s.Add(jobs.MaintenanceOverdue(cron))
+	}
+	if cfg.Disabled {
+		log.Println("⏰ Фоновые задачи отключены (jobs.disabled)")
+		return s
+	}
+	s.Start(ctx)
+	log.Printf("⏰ Фоновые задачи запущены: статусы абонементов «%s»", spec)
+	return s
+}
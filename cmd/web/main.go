@@ -1,12 +1,20 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
+	"os"
 	"time"
+	_ "time/tzdata" // часовые пояса в бинарнике: в образе alpine нет tzdata
 
+	"fitness-center-manager/internal/auth"
 	"fitness-center-manager/internal/config"
 	"fitness-center-manager/internal/database"
 	"fitness-center-manager/internal/handlers"
+	"fitness-center-manager/internal/repair"
+	"fitness-center-manager/internal/store/pgstore"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/compress"
@@ -19,11 +27,55 @@ import (
 )
 
 func main() {
+	autoMigrate := flag.Bool("auto-migrate", false, "применить миграции БД перед запуском сервера")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Использование: %s [-auto-migrate]\n       %s migrate up|down|status\n       %s photos migrate\n\n", os.Args[0], os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// server migrate up|down|status — управление схемой без запуска сервера
+	if flag.Arg(0) == "migrate" {
+		os.Exit(runMigrate(flag.Args()[1:]))
+	}
+	// server photos migrate — перенос фото из БД в хранилище файлов
+	if flag.Arg(0) == "photos" {
+		os.Exit(runPhotos(flag.Args()[1:]))
+	}
+
     // Загрузка конфигурации
     cfg := config.LoadConfig()
 
-	// Инициализация базы данных
-	_ = database.GetDB()
+	// Часовой пояс клуба: время тренировок, календарные ссылки, «сегодня»
+	// для фоновых задач, заморозок и входа (часы доступа тарифа)
+	tzName := cfg.Server.Timezone
+	if tzName == "" {
+		tzName = "Europe/Moscow"
+	}
+	clubTZ, err := time.LoadLocation(tzName)
+	if err != nil {
+		log.Fatalf("❌ Неверный часовой пояс server.timezone %q: %v", tzName, err)
+	}
+
+	// Инициализация базы данных (с -auto-migrate — сначала миграции)
+	database.SetAutoMigrate(*autoMigrate)
+	st := pgstore.New(database.GetDB())
+	st.SetLocation(clubTZ)
+	handlers.SetStore(st)
+	handlers.SetClubLocation(clubTZ)
+
+	// Хранилище фото (storage.driver: local или s3)
+	blobs, err := newBlobStore(cfg)
+	if err != nil {
+		log.Fatalf("❌ Хранилище файлов: %v", err)
+	}
+	handlers.SetBlobStore(blobs)
+
+	// Фоновые задачи (статусы абонементов и т.п.), журнал — /admin/jobs
+	handlers.SetScheduler(startJobs(context.Background(), cfg.Jobs, cfg.Equipment.FlagOverdueMaintenance, st, clubTZ))
+	handlers.SetMaintenanceOptions(cfg.Equipment.FlagOverdueMaintenance)
+	sla := cfg.Equipment.RepairSLAHours
+	handlers.SetRepairSLA(repair.NewSLA(sla.High, sla.Medium, sla.Low))
 
 	// Инициализация шаблонов
 	engine := html.New(cfg.Server.TemplatePath, ".html")
@@ -34,6 +86,8 @@ func main() {
         AppName:     "FitnessCenterManager",
         ViewsLayout: "layouts/base",
         BodyLimit:   10 * 1024 * 1024, // до 10 МБ на запрос
+        // Locals (в т.ч. CurrentUser) доступны во всех шаблонах
+        PassLocalsToViews: true,
     })
 
     // Настройка base URL для Problem Details (если задан в конфиге)
@@ -41,6 +95,15 @@ func main() {
         handlers.SetProblemBaseURL(cfg.Server.ProblemBaseURL)
     }
 
+	// Сессии сотрудников и первичный администратор
+	handlers.SetSessionOptions(time.Duration(cfg.Auth.SessionTTLHours)*time.Hour, cfg.Auth.CookieSecure)
+	if err := handlers.EnsureBootstrapAdmin(cfg.Auth.AdminLogin, cfg.Auth.AdminPassword); err != nil {
+		log.Fatalf("❌ Не удалось создать администратора: %v", err)
+	}
+
+	// Адрес календарных ссылок (часовой пояс клуба — выше)
+	handlers.SetCalendarOptions(cfg.Server.PublicURL)
+
 	// -------------------------------
 	// Middleware: безопасность и логика
 	// -------------------------------
@@ -63,6 +126,16 @@ func main() {
 	// -------------------------------
 	app.Static("/static", cfg.Server.StaticPath)
 
+	// вход/выход доступны без сессии, всё остальное — только сотрудникам
+	app.Get("/login", handlers.LoginPage)
+	app.Post("/login", handlers.Login)
+	app.Post("/logout", handlers.Logout)
+	// календарные ссылки защищены токеном в URL, сессия не нужна
+	app.Get("/calendar/:token", handlers.GetCalendarFeed)
+	// проверка живости для healthcheck (docker-compose.yml)
+	app.Get("/healthz", handlers.Healthz)
+	app.Use(handlers.RequireAuth)
+
 	setupRoutes(app)
 
 	log.Printf("🚀 Сервер запущен на http://localhost%s", cfg.Server.Port)
@@ -74,124 +147,186 @@ func main() {
 
 // setupRoutes — маршруты приложения
 func setupRoutes(app *fiber.App) {
+	// группы ролей (см. README, раздел «Роли и доступ»)
+	adminOnly := handlers.RequireRole(auth.RoleAdmin)
+	office := handlers.RequireRole(auth.RoleAdmin, auth.RoleReception)
+	coaching := handlers.RequireRole(auth.RoleAdmin, auth.RoleReception, auth.RoleTrainer)
+	tech := handlers.RequireRole(auth.RoleAdmin, auth.RoleTechnician)
+
     // страницы
     app.Get("/", handlers.Dashboard)
-    app.Get("/about", handlers.About)
-    app.Get("/tariffs", handlers.GetTariffsPage)
+    app.Get("/about", adminOnly, handlers.About)
+    app.Get("/tariffs", office, handlers.GetTariffsPage)
     // отчетность: запросы и операции
-    app.Post("/about/query/clients-after-date", handlers.ReportClientsAfterDate)
-    app.Post("/about/query/subscriptions-by-status", handlers.ReportSubscriptionsByStatus)
-    app.Post("/about/query/revenue-by-tariff", handlers.ReportRevenueByTariff)
-    app.Post("/about/query/personal-finished", handlers.ReportPersonalFinished)
-    app.Post("/about/query/zones-min-equip", handlers.ReportZonesWithMinEquipment)
-    app.Post("/about/query/zones-above-avg-capacity", handlers.ReportZonesAboveAvgCapacity)
-    app.Post("/about/op/insert-zone", handlers.ReportInsertZone)
-    app.Post("/about/op/update-zone-status", handlers.ReportUpdateZoneStatus)
-    app.Post("/about/op/delete-zone", handlers.ReportDeleteZone)
+    app.Post("/about/query/clients-after-date", adminOnly, handlers.ReportClientsAfterDate)
+    app.Post("/about/query/subscriptions-by-status", adminOnly, handlers.ReportSubscriptionsByStatus)
+    app.Post("/about/query/revenue-by-tariff", adminOnly, handlers.ReportRevenueByTariff)
+    app.Post("/about/query/personal-finished", adminOnly, handlers.ReportPersonalFinished)
+	app.Post("/about/query/cash-daily", adminOnly, handlers.ReportCashDaily)
+	app.Post("/about/query/debts", adminOnly, handlers.ReportDebts)
+    app.Post("/about/query/zones-min-equip", adminOnly, handlers.ReportZonesWithMinEquipment)
+    app.Post("/about/query/zones-above-avg-capacity", adminOnly, handlers.ReportZonesAboveAvgCapacity)
+    app.Post("/about/op/insert-zone", adminOnly, handlers.ReportInsertZone)
+    app.Post("/about/op/update-zone-status", adminOnly, handlers.ReportUpdateZoneStatus)
+    app.Post("/about/op/delete-zone", adminOnly, handlers.ReportDeleteZone)
 
 	// клиенты
-	app.Get("/clients", handlers.GetClients)
-	app.Post("/clients", handlers.CreateClient)
-	app.Get("/clients/:id", handlers.GetClientByID)
-	app.Put("/clients/:id", handlers.UpdateClient)
-	app.Delete("/clients/:id", handlers.DeleteClient)
+	app.Get("/clients", coaching, handlers.GetClients)
+	app.Post("/clients", office, handlers.CreateClient)
+	app.Get("/clients/:id", coaching, handlers.GetClientByID)
+	app.Put("/clients/:id", office, handlers.UpdateClient)
+	app.Delete("/clients/:id", adminOnly, handlers.DeleteClient)
 
 	// API v1 — клиенты (JSON)
-	app.Get("/api/v1/clients", handlers.APIv1ListClients)
-	app.Post("/api/v1/clients", handlers.APIv1CreateClient)
-	app.Get("/api/v1/clients/:id", handlers.GetClientByID)
-	app.Put("/api/v1/clients/:id", handlers.UpdateClient)
-	app.Delete("/api/v1/clients/:id", handlers.DeleteClient)
+	app.Get("/api/v1/clients", coaching, handlers.APIv1ListClients)
+	app.Post("/api/v1/clients", office, handlers.APIv1CreateClient)
+	app.Get("/api/v1/clients/:id", coaching, handlers.GetClientByID)
+	app.Put("/api/v1/clients/:id", office, handlers.UpdateClient)
+	app.Delete("/api/v1/clients/:id", adminOnly, handlers.DeleteClient)
+
+	// вход в зал и посещения
+	app.Get("/checkin", office, handlers.GetCheckinPage)
+	app.Post("/api/v1/checkin", office, handlers.APIv1Checkin)
+	app.Post("/api/v1/checkout", office, handlers.APIv1Checkout)
+	app.Get("/api/v1/visits/inside", office, handlers.APIv1ListInside)
+	app.Post("/api/v1/visits/:id/exit", office, handlers.APIv1VisitExit)
+	app.Get("/api/v1/clients/:id/visits", coaching, handlers.APIv1ClientVisits)
 
 	// абонементы
-	app.Get("/subscriptions", handlers.GetSubscriptionsPage)
-	app.Post("/subscriptions", handlers.CreateSubscription)
-	app.Get("/subscriptions/:id", handlers.GetSubscriptionByID)
-	app.Put("/subscriptions/:id", handlers.UpdateSubscription)
-	app.Delete("/subscriptions/:id", handlers.DeleteSubscription)
+	app.Get("/subscriptions", office, handlers.GetSubscriptionsPage)
+	app.Post("/subscriptions", office, handlers.CreateSubscription)
+	app.Get("/subscriptions/:id", office, handlers.GetSubscriptionByID)
+	app.Put("/subscriptions/:id", office, handlers.UpdateSubscription)
+	app.Delete("/subscriptions/:id", adminOnly, handlers.DeleteSubscription)
 	// API v1 — абонементы (JSON + алиасы)
-	app.Get("/api/v1/subscriptions", handlers.APIv1ListSubscriptions)
-	app.Post("/api/v1/subscriptions", handlers.APIv1CreateSubscription)
-	app.Get("/api/v1/subscriptions/:id", handlers.GetSubscriptionByID)
-	app.Put("/api/v1/subscriptions/:id", handlers.UpdateSubscription)
-	app.Delete("/api/v1/subscriptions/:id", handlers.DeleteSubscription)
+	app.Get("/api/v1/subscriptions", office, handlers.APIv1ListSubscriptions)
+	app.Post("/api/v1/subscriptions", office, handlers.APIv1CreateSubscription)
+	app.Get("/api/v1/subscriptions/:id", office, handlers.GetSubscriptionByID)
+	app.Put("/api/v1/subscriptions/:id", office, handlers.UpdateSubscription)
+	app.Delete("/api/v1/subscriptions/:id", adminOnly, handlers.DeleteSubscription)
+
+	// продление: новый абонемент со следующего дня после окончания текущего
+	app.Post("/api/v1/subscriptions/:id/renew", office, handlers.APIv1RenewSubscription)
+	app.Get("/api/v1/clients/:id/subscriptions", office, handlers.APIv1ClientSubscriptions)
+
+	// заморозки абонементов
+	app.Get("/api/v1/subscriptions/:id/freezes", office, handlers.APIv1ListFreezes)
+	app.Post("/api/v1/subscriptions/:id/freezes", office, handlers.APIv1CreateFreeze)
+	app.Post("/api/v1/freezes/:id/end", office, handlers.APIv1EndFreeze)
+	app.Delete("/api/v1/freezes/:id", adminOnly, handlers.APIv1DeleteFreeze)
+
+	// платежи: оплата частями и возвраты (возврат — администратор, проверка в хэндлере)
+	app.Get("/api/v1/subscriptions/:id/payments", office, handlers.APIv1SubscriptionPayments)
+	app.Get("/api/v1/personal-trainings/:id/payments", office, handlers.APIv1PersonalTrainingPayments)
+	app.Post("/api/v1/payments", office, handlers.APIv1CreatePayment)
+	app.Get("/api/v1/clients/:id/balance", office, handlers.APIv1ClientBalance)
 
 	// тренеры
-	app.Get("/trainers", handlers.GetTrainersPage)  
-	app.Post("/trainers", handlers.CreateTrainer)
-	app.Get("/trainers/:id", handlers.GetTrainerByID) 
-	app.Put("/trainers/:id", handlers.UpdateTrainer)
-	app.Delete("/trainers/:id", handlers.DeleteTrainer)
+	app.Get("/trainers", coaching, handlers.GetTrainersPage)
+	app.Post("/trainers", office, handlers.CreateTrainer)
+	app.Get("/trainers/:id", coaching, handlers.GetTrainerByID)
+	app.Put("/trainers/:id", office, handlers.UpdateTrainer)
+	app.Delete("/trainers/:id", adminOnly, handlers.DeleteTrainer)
 	// API v1 — тренеры (JSON + алиасы)
-	app.Get("/api/v1/trainers", handlers.APIv1ListTrainers)
-	app.Post("/api/v1/trainers", handlers.APIv1CreateTrainer)
-	app.Get("/api/v1/trainers/:id", handlers.GetTrainerByID)
-	app.Put("/api/v1/trainers/:id", handlers.UpdateTrainer)
-	app.Delete("/api/v1/trainers/:id", handlers.DeleteTrainer)
+	app.Get("/api/v1/trainers", coaching, handlers.APIv1ListTrainers)
+	app.Post("/api/v1/trainers", office, handlers.APIv1CreateTrainer)
+	app.Get("/api/v1/trainers/:id", coaching, handlers.GetTrainerByID)
+	app.Put("/api/v1/trainers/:id", office, handlers.UpdateTrainer)
+	app.Delete("/api/v1/trainers/:id", adminOnly, handlers.DeleteTrainer)
+	// рабочие часы, отсутствия и свободные слоты тренера
+	app.Get("/api/v1/trainers/:id/schedule", coaching, handlers.APIv1TrainerSchedule)
+	app.Get("/api/v1/trainers/:id/availability", coaching, handlers.APIv1TrainerAvailability)
+	app.Put("/api/v1/trainers/:id/working-hours", office, handlers.APIv1SetTrainerHours)
+	app.Post("/api/v1/trainers/:id/absences", office, handlers.APIv1CreateAbsence)
+	app.Delete("/api/v1/trainer-absences/:id", office, handlers.APIv1DeleteAbsence)
+	// ставки тренеров и стоимость персональной по ставке
+	app.Get("/api/v1/trainers/:id/rates", office, handlers.APIv1TrainerRates)
+	app.Post("/api/v1/trainers/:id/rates", adminOnly, handlers.APIv1CreateRate)
+	app.Delete("/api/v1/trainer-rates/:id", adminOnly, handlers.APIv1DeleteRate)
+	app.Get("/api/v1/trainers/:id/price-quote", coaching, handlers.APIv1PriceQuote)
+	// календарные ссылки (iCalendar): kind — trainer | zone | client
+	app.Get("/api/v1/calendar-feeds/:kind/:id", coaching, handlers.APIv1GetCalendarFeed)
+	app.Post("/api/v1/calendar-feeds/:kind/:id", coaching, handlers.APIv1CreateCalendarFeed)
+	app.Delete("/api/v1/calendar-feeds/:kind/:id", coaching, handlers.APIv1DeleteCalendarFeed)
 
 	// групповые
-	app.Get("/trainings", handlers.GetTrainingsPage)
-	app.Get("/api/group-trainings/:id", handlers.GetGroupTrainingByID)
-	app.Post("/group-trainings", handlers.CreateGroupTraining)
-	app.Put("/group-trainings/:id", handlers.UpdateGroupTraining)
-	app.Delete("/group-trainings/:id", handlers.DeleteGroupTraining)
+	app.Get("/trainings", coaching, handlers.GetTrainingsPage)
+	app.Get("/api/group-trainings/:id", coaching, handlers.GetGroupTrainingByID)
+	app.Post("/group-trainings", coaching, handlers.CreateGroupTraining)
+	app.Put("/group-trainings/:id", coaching, handlers.UpdateGroupTraining)
+	app.Delete("/group-trainings/:id", office, handlers.DeleteGroupTraining)
 	// API v1 — групповые тренировки (алиасы)
-	app.Get("/api/v1/group-trainings", handlers.APIv1ListGroupTrainings)
-	app.Get("/api/v1/group-trainings/:id", handlers.GetGroupTrainingByID)
-	app.Post("/api/v1/group-trainings", handlers.CreateGroupTraining)
-	app.Put("/api/v1/group-trainings/:id", handlers.UpdateGroupTraining)
-	app.Delete("/api/v1/group-trainings/:id", handlers.DeleteGroupTraining)
+	app.Get("/api/v1/group-trainings", coaching, handlers.APIv1ListGroupTrainings)
+	app.Get("/api/v1/group-trainings/:id", coaching, handlers.GetGroupTrainingByID)
+	app.Post("/api/v1/group-trainings", coaching, handlers.CreateGroupTraining)
+	app.Put("/api/v1/group-trainings/:id", coaching, handlers.UpdateGroupTraining)
+	app.Delete("/api/v1/group-trainings/:id", office, handlers.DeleteGroupTraining)
+	// API v1 — серии групповых (RRULE); правка занятия серии — PUT/DELETE групповой со scope
+	app.Get("/api/v1/training-series", coaching, handlers.APIv1ListSeries)
+	app.Get("/api/v1/training-series/:id", coaching, handlers.APIv1GetSeries)
+	app.Post("/api/v1/training-series", coaching, handlers.APIv1CreateSeries)
+	app.Put("/api/v1/training-series/:id", coaching, handlers.APIv1UpdateSeries)
+	app.Delete("/api/v1/training-series/:id", office, handlers.APIv1DeleteSeries)
+	app.Post("/api/v1/training-series/:id/exceptions", coaching, handlers.APIv1AddSeriesException)
+	app.Delete("/api/v1/training-series/:id/exceptions/:date", coaching, handlers.APIv1DeleteSeriesException)
 
 	// персональные
-	app.Get("/api/personal-trainings/:id", handlers.GetPersonalTrainingByID)
-	app.Post("/personal-trainings", handlers.CreatePersonalTraining)
-	app.Put("/personal-trainings/:id", handlers.UpdatePersonalTraining)
-	app.Delete("/personal-trainings/:id", handlers.DeletePersonalTraining)
+	app.Get("/api/personal-trainings/:id", coaching, handlers.GetPersonalTrainingByID)
+	app.Post("/personal-trainings", coaching, handlers.CreatePersonalTraining)
+	app.Put("/personal-trainings/:id", coaching, handlers.UpdatePersonalTraining)
+	app.Delete("/personal-trainings/:id", office, handlers.DeletePersonalTraining)
 	// API v1 — персональные тренировки (алиасы)
-	app.Get("/api/v1/personal-trainings", handlers.APIv1ListPersonalTrainings)
-	app.Get("/api/v1/personal-trainings/:id", handlers.GetPersonalTrainingByID)
-	app.Post("/api/v1/personal-trainings", handlers.CreatePersonalTraining)
-	app.Put("/api/v1/personal-trainings/:id", handlers.UpdatePersonalTraining)
-	app.Delete("/api/v1/personal-trainings/:id", handlers.DeletePersonalTraining)
+	app.Get("/api/v1/personal-trainings", coaching, handlers.APIv1ListPersonalTrainings)
+	app.Get("/api/v1/personal-trainings/:id", coaching, handlers.GetPersonalTrainingByID)
+	app.Post("/api/v1/personal-trainings", coaching, handlers.CreatePersonalTraining)
+	app.Put("/api/v1/personal-trainings/:id", coaching, handlers.UpdatePersonalTraining)
+	app.Delete("/api/v1/personal-trainings/:id", office, handlers.DeletePersonalTraining)
 
 	// запись на групповую
-	app.Get("/api/group-trainings/:id/enrollments", handlers.ListGroupEnrollments)
-	app.Post("/group-enrollments", handlers.CreateGroupEnrollment)
+	app.Get("/api/group-trainings/:id/enrollments", coaching, handlers.ListGroupEnrollments)
+	app.Post("/group-enrollments", coaching, handlers.CreateGroupEnrollment)
 	// API v1 — записи на групповые (алиасы)
-	app.Get("/api/v1/group-trainings/:id/enrollments", handlers.ListGroupEnrollments)
-	app.Post("/api/v1/group-enrollments", handlers.CreateGroupEnrollment)
+	app.Get("/api/v1/group-trainings/:id/enrollments", coaching, handlers.ListGroupEnrollments)
+	app.Post("/api/v1/group-enrollments", coaching, handlers.CreateGroupEnrollment)
+	app.Post("/api/v1/group-enrollments/:id/cancel", coaching, handlers.APIv1CancelEnrollment)
+	app.Put("/api/v1/group-enrollments/:id", coaching, handlers.APIv1UpdateEnrollment)
+	app.Delete("/api/v1/group-enrollments/:id", office, handlers.APIv1DeleteEnrollment)
+	app.Post("/api/v1/group-trainings/:id/attendance", coaching, handlers.APIv1MarkAttendance)
+
+	// уведомления клиентам (лист ожидания и т.п.)
+	app.Get("/api/v1/notifications", office, handlers.APIv1ListNotifications)
+	app.Post("/api/v1/notifications/:id/sent", office, handlers.APIv1MarkNotificationSent)
 	// API для селектов
-	app.Get("/api/clients-for-select", handlers.GetClientsForSelect)
-	app.Get("/api/tariffs-for-select", handlers.GetTariffsForSelect)
-	app.Get("/api/trainers-for-select", handlers.GetTrainersForSelect)
+	app.Get("/api/clients-for-select", coaching, handlers.GetClientsForSelect)
+	app.Get("/api/tariffs-for-select", office, handlers.GetTariffsForSelect)
+	app.Get("/api/trainers-for-select", coaching, handlers.GetTrainersForSelect)
 	app.Get("/api/zones-for-select", handlers.GetZonesForSelect)
-	app.Get("/api/subscriptions-for-select", handlers.GetSubscriptionsForSelect)
+	app.Get("/api/subscriptions-for-select", coaching, handlers.GetSubscriptionsForSelect)
 	// API v1 — селекты (алиасы)
-	app.Get("/api/v1/clients-for-select", handlers.GetClientsForSelect)
-	app.Get("/api/v1/tariffs-for-select", handlers.GetTariffsForSelect)
-	app.Get("/api/v1/trainers-for-select", handlers.GetTrainersForSelect)
+	app.Get("/api/v1/clients-for-select", coaching, handlers.GetClientsForSelect)
+	app.Get("/api/v1/tariffs-for-select", office, handlers.GetTariffsForSelect)
+	app.Get("/api/v1/trainers-for-select", coaching, handlers.GetTrainersForSelect)
 	app.Get("/api/v1/zones-for-select", handlers.GetZonesForSelect)
-	app.Get("/api/v1/subscriptions-for-select", handlers.GetSubscriptionsForSelect)
+	app.Get("/api/v1/subscriptions-for-select", coaching, handlers.GetSubscriptionsForSelect)
 	// зоны
-	app.Get("/zones", handlers.GetZones)
-	app.Post("/zones", handlers.CreateZone)
-	app.Get("/zones/:id/photo", handlers.GetZonePhoto)
-	app.Post("/zones/:id/upload-photo", handlers.UploadZonePhoto)
-	app.Delete("/zones/:id/photo", handlers.ClearZonePhoto)
-	app.Put("/zones/:id", handlers.UpdateZone)
-	app.Delete("/zones/:id", handlers.DeleteZone)
-	app.Get("/api/zones/:id", handlers.GetZoneByID)
+	app.Get("/zones", coaching, handlers.GetZones)
+	app.Post("/zones", office, handlers.CreateZone)
+	app.Get("/zones/:id/photo", coaching, handlers.GetZonePhoto)
+	app.Post("/zones/:id/upload-photo", office, handlers.UploadZonePhoto)
+	app.Delete("/zones/:id/photo", office, handlers.ClearZonePhoto)
+	app.Put("/zones/:id", office, handlers.UpdateZone)
+	app.Delete("/zones/:id", adminOnly, handlers.DeleteZone)
+	app.Get("/api/zones/:id", coaching, handlers.GetZoneByID)
 
 	// API v1 — фото зон (RESTful-алиасы)
-	app.Get("/api/v1/zones/:id/photo", handlers.GetZonePhoto)
-	app.Put("/api/v1/zones/:id/photo", handlers.UploadZonePhoto)
-	app.Delete("/api/v1/zones/:id/photo", handlers.ClearZonePhoto)
+	app.Get("/api/v1/zones/:id/photo", coaching, handlers.GetZonePhoto)
+	app.Put("/api/v1/zones/:id/photo", office, handlers.UploadZonePhoto)
+	app.Delete("/api/v1/zones/:id/photo", office, handlers.ClearZonePhoto)
 
 	// страницы
 	app.Get("/equipment", handlers.GetEquipmentPage)
 
 	// API
-	app.Get("/api/zones-for-select", handlers.GetZonesForSelect)
 	app.Get("/api/equipment/:id", handlers.GetEquipmentByID)
 	app.Get("/api/repairs/latest", handlers.GetLatestRepairs)
 	// API v1 — алиасы
@@ -200,58 +335,126 @@ func setupRoutes(app *fiber.App) {
 	app.Get("/api/v1/repairs/latest", handlers.GetLatestRepairs)
 
 	// API v1 — отчёты (алиасы под REST-префиксом)
-	app.Post("/api/v1/reports/clients-after-date", handlers.ReportClientsAfterDate)
-	app.Post("/api/v1/reports/subscriptions-by-status", handlers.ReportSubscriptionsByStatus)
-    app.Post("/api/v1/reports/revenue-by-tariff", handlers.ReportRevenueByTariff)
-    app.Post("/api/v1/reports/personal-finished", handlers.ReportPersonalFinished)
-	app.Post("/api/v1/reports/zones-min-equip", handlers.ReportZonesWithMinEquipment)
-	app.Post("/api/v1/reports/zones-above-avg-capacity", handlers.ReportZonesAboveAvgCapacity)
-	app.Post("/api/v1/reports/ops/insert-zone", handlers.ReportInsertZone)
-	app.Post("/api/v1/reports/ops/update-zone-status", handlers.ReportUpdateZoneStatus)
-	app.Post("/api/v1/reports/ops/delete-zone", handlers.ReportDeleteZone)
+	app.Post("/api/v1/reports/clients-after-date", adminOnly, handlers.ReportClientsAfterDate)
+	app.Post("/api/v1/reports/subscriptions-by-status", adminOnly, handlers.ReportSubscriptionsByStatus)
+    app.Post("/api/v1/reports/revenue-by-tariff", adminOnly, handlers.ReportRevenueByTariff)
+    app.Post("/api/v1/reports/personal-finished", adminOnly, handlers.ReportPersonalFinished)
+	app.Post("/api/v1/reports/cash-daily", adminOnly, handlers.ReportCashDaily)
+	app.Post("/api/v1/reports/debts", adminOnly, handlers.ReportDebts)
+	app.Post("/api/v1/reports/zones-min-equip", adminOnly, handlers.ReportZonesWithMinEquipment)
+	app.Post("/api/v1/reports/zones-above-avg-capacity", adminOnly, handlers.ReportZonesAboveAvgCapacity)
+	app.Post("/api/v1/reports/ops/insert-zone", adminOnly, handlers.ReportInsertZone)
+	app.Post("/api/v1/reports/ops/update-zone-status", adminOnly, handlers.ReportUpdateZoneStatus)
+	app.Post("/api/v1/reports/ops/delete-zone", adminOnly, handlers.ReportDeleteZone)
 
 	// CRUD оборудования
-	app.Post("/equipment", handlers.CreateEquipment)
-	app.Put("/equipment/:id", handlers.UpdateEquipment)
-	app.Delete("/equipment/:id", handlers.DeleteEquipment)
+	app.Post("/equipment", tech, handlers.CreateEquipment)
+	app.Put("/equipment/:id", tech, handlers.UpdateEquipment)
+	app.Delete("/equipment/:id", adminOnly, handlers.DeleteEquipment)
 	// API v1 — CRUD оборудования (алиасы)
-	app.Post("/api/v1/equipment", handlers.CreateEquipment)
-	app.Put("/api/v1/equipment/:id", handlers.UpdateEquipment)
-	app.Delete("/api/v1/equipment/:id", handlers.DeleteEquipment)
+	app.Post("/api/v1/equipment", tech, handlers.CreateEquipment)
+	app.Put("/api/v1/equipment/:id", tech, handlers.UpdateEquipment)
+	app.Delete("/api/v1/equipment/:id", adminOnly, handlers.DeleteEquipment)
 
 	// фото оборудования
-	app.Post("/equipment/:id/upload-photo", handlers.UploadEquipmentPhoto)
+	app.Post("/equipment/:id/upload-photo", tech, handlers.UploadEquipmentPhoto)
 	app.Get("/equipment/:id/photo", handlers.GetEquipmentPhoto)
-	app.Delete("/equipment/:id/photo", handlers.DeleteEquipmentPhoto)
+	app.Delete("/equipment/:id/photo", tech, handlers.DeleteEquipmentPhoto)
 
 	// API v1 — фото оборудования (RESTful-алиасы)
 	app.Get("/api/v1/equipment/:id/photo", handlers.GetEquipmentPhoto)
-	app.Put("/api/v1/equipment/:id/photo", handlers.UploadEquipmentPhoto)
-	app.Delete("/api/v1/equipment/:id/photo", handlers.DeleteEquipmentPhoto)
+	app.Put("/api/v1/equipment/:id/photo", tech, handlers.UploadEquipmentPhoto)
+	app.Delete("/api/v1/equipment/:id/photo", tech, handlers.DeleteEquipmentPhoto)
+
+	// плановое ТО: планы, сроки, история обслуживания
+	app.Get("/api/v1/maintenance/due", tech, handlers.APIv1MaintenanceDue)
+	app.Get("/api/v1/maintenance/plans", tech, handlers.APIv1MaintenancePlans)
+	app.Post("/api/v1/maintenance/plans", tech, handlers.APIv1CreateMaintenancePlan)
+	app.Put("/api/v1/maintenance/plans/:id", tech, handlers.APIv1UpdateMaintenancePlan)
+	app.Delete("/api/v1/maintenance/plans/:id", adminOnly, handlers.APIv1DeleteMaintenancePlan)
+	app.Get("/api/v1/equipment/:id/maintenance", tech, handlers.APIv1EquipmentMaintenance)
+	app.Post("/api/v1/equipment/:id/maintenance", tech, handlers.APIv1LogMaintenance)
 
 	// заявки на ремонт
-	app.Post("/repairs", handlers.CreateRepairRequest)
-	app.Get("/repairs/:id/photo", handlers.GetRepairPhoto)
-	app.Post("/repairs/:id/upload-photo", handlers.UploadRepairPhoto)
-	app.Put("/repairs/:id", handlers.UpdateRepairRequest)
-	app.Delete("/repairs/:id", handlers.DeleteRepairRequest)
+	app.Post("/repairs", tech, handlers.CreateRepairRequest)
+	app.Get("/repairs/:id/photo", tech, handlers.GetRepairPhoto)
+	app.Post("/repairs/:id/upload-photo", tech, handlers.UploadRepairPhoto)
+	app.Put("/repairs/:id", tech, handlers.UpdateRepairRequest)
+	app.Delete("/repairs/:id", adminOnly, handlers.DeleteRepairRequest)
 	// API v1 — заявки на ремонт (алиасы)
-	app.Post("/api/v1/repairs", handlers.CreateRepairRequest)
-	app.Get("/api/v1/repairs/:id/photo", handlers.GetRepairPhoto)
-	app.Put("/api/v1/repairs/:id/photo", handlers.UploadRepairPhoto)
-	app.Put("/api/v1/repairs/:id", handlers.UpdateRepairRequest)
-	app.Delete("/api/v1/repairs/:id", handlers.DeleteRepairRequest)
+	app.Post("/api/v1/repairs", tech, handlers.CreateRepairRequest)
+	app.Get("/api/v1/repairs/:id/photo", tech, handlers.GetRepairPhoto)
+	app.Put("/api/v1/repairs/:id/photo", tech, handlers.UploadRepairPhoto)
+	app.Get("/api/v1/repairs", tech, handlers.APIv1ListRepairs)
+	app.Get("/api/v1/repairs/assignees", tech, handlers.APIv1RepairAssignees)
+	app.Get("/api/v1/repairs/:id/history", tech, handlers.APIv1RepairHistory)
+	app.Post("/api/v1/repairs/:id/status", tech, handlers.APIv1RepairStatus)
+	app.Put("/api/v1/repairs/:id/assignee", tech, handlers.APIv1AssignRepair)
+	app.Put("/api/v1/repairs/:id", tech, handlers.UpdateRepairRequest)
+	app.Delete("/api/v1/repairs/:id", adminOnly, handlers.DeleteRepairRequest)
+
+	// затраты на ремонт: подрядчики, склад запчастей, расход по заявкам
+	app.Get("/equipment/costs", tech, handlers.GetRepairCostsPage)
+	app.Get("/api/v1/vendors", tech, handlers.APIv1Vendors)
+	app.Post("/api/v1/vendors", tech, handlers.APIv1CreateVendor)
+	app.Put("/api/v1/vendors/:id", tech, handlers.APIv1UpdateVendor)
+	app.Delete("/api/v1/vendors/:id", adminOnly, handlers.APIv1DeleteVendor)
+	app.Get("/api/v1/parts", tech, handlers.APIv1Parts)
+	app.Post("/api/v1/parts", tech, handlers.APIv1CreatePart)
+	app.Put("/api/v1/parts/:id", tech, handlers.APIv1UpdatePart)
+	app.Delete("/api/v1/parts/:id", adminOnly, handlers.APIv1DeletePart)
+	app.Post("/api/v1/parts/:id/receive", tech, handlers.APIv1ReceivePart)
+	app.Get("/api/v1/repairs/:id/costs", tech, handlers.APIv1RepairCosts)
+	app.Put("/api/v1/repairs/:id/costs", tech, handlers.APIv1SetRepairCosts)
+	app.Post("/api/v1/repairs/:id/parts", tech, handlers.APIv1ConsumePart)
+	app.Delete("/api/v1/repair-parts/:id", tech, handlers.APIv1ReturnPart)
+	app.Get("/api/v1/reports/repair-spend", tech, handlers.APIv1RepairSpend)
+
+	// надёжность оборудования: отказы, MTBF/MTTR, простой
+	app.Get("/equipment/reliability", tech, handlers.GetReliabilityPage)
+	app.Get("/api/v1/reports/equipment-reliability", tech, handlers.APIv1EquipmentReliability)
 
 	// тарифы (CRUD + API)
-    app.Get("/api/tariffs/:id", handlers.GetTariffByID)
-    app.Post("/tariffs", handlers.CreateTariff)
-    app.Put("/tariffs/:id", handlers.UpdateTariff)
-    app.Delete("/tariffs/:id", handlers.DeleteTariff)
+    app.Get("/api/tariffs/:id", office, handlers.GetTariffByID)
+    app.Post("/tariffs", adminOnly, handlers.CreateTariff)
+    app.Put("/tariffs/:id", adminOnly, handlers.UpdateTariff)
+    app.Delete("/tariffs/:id", adminOnly, handlers.DeleteTariff)
     // API v1 — алиасы
-    app.Get("/api/v1/tariffs/:id", handlers.GetTariffByID)
-    app.Post("/api/v1/tariffs", handlers.CreateTariff)
-    app.Put("/api/v1/tariffs/:id", handlers.UpdateTariff)
-    app.Delete("/api/v1/tariffs/:id", handlers.DeleteTariff)
+    app.Get("/api/v1/tariffs/:id", office, handlers.GetTariffByID)
+    app.Post("/api/v1/tariffs", adminOnly, handlers.CreateTariff)
+    app.Put("/api/v1/tariffs/:id", adminOnly, handlers.UpdateTariff)
+    app.Delete("/api/v1/tariffs/:id", adminOnly, handlers.DeleteTariff)
+
+	// сотрудники и учётные записи
+	app.Get("/staff", adminOnly, handlers.GetStaffPage)
+	app.Post("/staff", adminOnly, handlers.CreateStaff)
+	app.Put("/staff/:id", adminOnly, handlers.UpdateStaff)
+	app.Delete("/staff/:id", adminOnly, handlers.DeleteStaff)
+	// API v1 — сотрудники
+	app.Get("/api/v1/staff", adminOnly, handlers.APIv1ListStaff)
+	app.Get("/api/v1/staff/:id", adminOnly, handlers.GetStaffByID)
+	app.Post("/api/v1/staff", adminOnly, handlers.CreateStaff)
+	app.Put("/api/v1/staff/:id", adminOnly, handlers.UpdateStaff)
+	app.Delete("/api/v1/staff/:id", adminOnly, handlers.DeleteStaff)
+
+	// журнал аудита: полный — администратору, история записи — по правам раздела
+	app.Get("/audit", adminOnly, handlers.GetAuditPage)
+	app.Get("/api/v1/audit", adminOnly, handlers.APIv1ListAudit)
+
+	// фоновые задачи: расписание, журнал запусков, запуск вручную
+	app.Get("/admin/jobs", adminOnly, handlers.GetJobsPage)
+	app.Post("/admin/jobs/:name/run", adminOnly, handlers.RunJob)
+	app.Get("/api/v1/jobs", adminOnly, handlers.APIv1ListJobs)
+	app.Post("/api/v1/jobs/:name/run", adminOnly, handlers.RunJob)
+
+	// зарплата тренеров: правила оплаты, ведомости, закрытие месяцев, выгрузка
+	app.Get("/payroll", adminOnly, handlers.GetPayrollPage)
+	app.Get("/api/v1/payroll/rules", adminOnly, handlers.APIv1PayRules)
+	app.Put("/api/v1/trainers/:id/pay-rule", adminOnly, handlers.APIv1SetPayRule)
+	app.Get("/api/v1/payroll", adminOnly, handlers.APIv1Payroll)
+	app.Get("/api/v1/payroll/export", adminOnly, handlers.APIv1ExportPayroll)
+	app.Post("/api/v1/payroll/periods", adminOnly, handlers.APIv1ClosePayroll)
+	app.Delete("/api/v1/payroll/periods/:month", adminOnly, handlers.APIv1ReopenPayroll)
+	app.Get("/api/v1/payroll/:trainer_id", adminOnly, handlers.APIv1TrainerPayroll)
 
 }
-    
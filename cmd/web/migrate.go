@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"fitness-center-manager/internal/database"
+)
+
+const migrateUsage = `Использование: server migrate <команда>
+
+Команды:
+  up      применить все ещё не применённые миграции
+  down    откатить последнюю применённую миграцию
+  status  показать список миграций и их состояние
+`
+
+// runMigrate — подкоманда `server migrate up|down|status`. Возвращает код выхода.
+func runMigrate(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+	cmd := args[0]
+	if cmd != "up" && cmd != "down" && cmd != "status" {
+		fmt.Fprintf(os.Stderr, "Неизвестная команда migrate: %s\n\n%s", cmd, migrateUsage)
+		return 2
+	}
+
+	db, err := database.Open()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Подключение к БД: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	switch cmd {
+	case "up":
+		applied, err := database.MigrateUp(ctx, db)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("Схема актуальна, применять нечего")
+		} else {
+			fmt.Printf("Применено миграций: %d\n", len(applied))
+		}
+	case "down":
+		m, err := database.MigrateDown(ctx, db)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 1
+		}
+		if m == nil {
+			fmt.Println("Нет применённых миграций")
+		} else {
+			fmt.Printf("Откачена миграция %s\n", m.Name)
+		}
+	case "status":
+		list, err := database.MigrationStatus(ctx, db)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "Применена\tМиграция")
+		for _, s := range list {
+			at := "— ожидает —"
+			if s.Applied {
+				at = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%s\t%s\n", at, s.Name)
+		}
+		_ = w.Flush()
+	}
+	return 0
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"fitness-center-manager/internal/audit"
+	"fitness-center-manager/internal/blob"
+	"fitness-center-manager/internal/config"
+	"fitness-center-manager/internal/database"
+	"fitness-center-manager/internal/photo"
+	"fitness-center-manager/internal/store"
+	"fitness-center-manager/internal/store/pgstore"
+)
+
+const photosUsage = `Использование: server photos <команда>
+
+Команды:
+  migrate  перенести фото, ещё хранящиеся в БД, в хранилище файлов (storage)
+           и сделать миниатюры для фото, у которых их нет
+`
+
+// defaultUploadPath — каталог локального хранилища, если server.upload_path пуст.
+const defaultUploadPath = "./web/uploads"
+
+// newBlobStore — хранилище фото по storage.driver: local (каталог
+// server.upload_path) или s3.
+func newBlobStore(cfg *config.Config) (blob.Store, error) {
+	switch strings.ToLower(cfg.Storage.Driver) {
+	case "", "local":
+		dir := cfg.Server.UploadPath
+		if dir == "" {
+			dir = defaultUploadPath
+		}
+		return blob.NewLocal(dir)
+	case "s3":
+		s := cfg.Storage.S3
+		return blob.NewS3(blob.S3Config{
+			Endpoint:  s.Endpoint,
+			Region:    s.Region,
+			Bucket:    s.Bucket,
+			Prefix:    s.Prefix,
+			PathStyle: s.PathStyle,
+			AccessKey: s.AccessKey,
+			SecretKey: s.SecretKey,
+		})
+	default:
+		return nil, fmt.Errorf("неизвестный storage.driver: %q (local или s3)", cfg.Storage.Driver)
+	}
+}
+
+// photosActor — от его имени изменения попадают в журнал аудита.
+var photosActor = audit.Actor{Login: "photos-migrate"}
+
+// runPhotos — подкоманда `server photos migrate`. Переносит фото из БД
+// пачками: файл обрабатывается (пакет photo) и кладётся в хранилище,
+// затем в записи сохраняется ключ, а байты в БД обнуляются. После этого
+// обрабатываются фото, лежащие в хранилище без миниатюр (загружены до
+// появления обработки). Прерванный перенос можно просто запустить снова.
+func runPhotos(args []string) int {
+	if len(args) != 1 || args[0] != "migrate" {
+		fmt.Fprint(os.Stderr, photosUsage)
+		return 2
+	}
+
+	blobs, err := newBlobStore(config.LoadConfig())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Хранилище файлов: %v\n", err)
+		return 1
+	}
+	db, err := database.Open()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Подключение к БД: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+	st := pgstore.New(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	moved, raw := 0, 0
+	for {
+		batch, err := st.Photos().Legacy(ctx, 10)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 1
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for _, p := range batch {
+			key, processed, err := savePhotoFile(ctx, blobs, p.Data)
+			if err == nil {
+				err = st.InTx(ctx, photosActor, func(tx store.Store) error {
+					return tx.Photos().Moved(ctx, p, key)
+				})
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ %s %d: %v\n", p.Kind, p.ID, err)
+				return 1
+			}
+			moved++
+			if !processed {
+				raw++
+			}
+		}
+	}
+
+	keys, err := st.Photos().Keys(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	reprocessed := 0
+	for _, old := range keys {
+		n, err := reprocessPhoto(ctx, blobs, st, old)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ фото %s: %v\n", old, err)
+			return 1
+		}
+		reprocessed += n
+	}
+
+	fmt.Printf("Перенесено фото из БД: %d (без обработки — не JPEG/PNG/WebP: %d)\n", moved, raw)
+	fmt.Printf("Обработано фото в хранилище: %d\n", reprocessed)
+	return 0
+}
+
+// savePhotoFile кладёт фото в хранилище: JPEG/PNG/WebP — с обработкой
+// (photo.Save), остальное — как есть. Пустые
+// байты — фото нет, ключ пустой.
+func savePhotoFile(ctx context.Context, blobs blob.Store, data []byte) (string, bool, error) {
+	if len(data) == 0 {
+		return "", true, nil
+	}
+	key, err := photo.Save(ctx, blobs, data)
+	if errors.Is(err, photo.ErrUnsupported) || errors.Is(err, photo.ErrTooLarge) {
+		key = blob.Key(data)
+		return key, false, blobs.Put(ctx, key, data, http.DetectContentType(data))
+	}
+	return key, true, err
+}
+
+// reprocessPhoto обрабатывает файл old, если у него нет миниатюр: записи
+// переводятся на ключ обработанного файла, старый файл удаляется.
+// Возвращает 1, если файл обработан.
+func reprocessPhoto(ctx context.Context, blobs blob.Store, st *pgstore.Store, old string) (int, error) {
+	ok, err := photo.HasVariants(ctx, blobs, old)
+	if err != nil || ok {
+		return 0, err
+	}
+	rc, _, err := blobs.Open(ctx, old)
+	if errors.Is(err, blob.ErrNotFound) {
+		return 0, nil // файла нет — выдача и так вернёт 404
+	}
+	if err != nil {
+		return 0, err
+	}
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return 0, err
+	}
+	key, processed, err := savePhotoFile(ctx, blobs, data)
+	if err != nil || !processed || key == old {
+		return 0, err
+	}
+	err = st.InTx(ctx, photosActor, func(tx store.Store) error {
+		return tx.Photos().Replace(ctx, old, key)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return 1, blobs.Delete(ctx, old)
+}
@@ -0,0 +1,279 @@
+package access
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dayNames — названия дней (рус./англ., полные и сокращённые).
+var dayNames = map[string]time.Weekday{
+	"пн": time.Monday, "пон": time.Monday, "понедельник": time.Monday, "mon": time.Monday, "monday": time.Monday,
+	"вт": time.Tuesday, "вторник": time.Tuesday, "tue": time.Tuesday, "tuesday": time.Tuesday,
+	"ср": time.Wednesday, "среда": time.Wednesday, "wed": time.Wednesday, "wednesday": time.Wednesday,
+	"чт": time.Thursday, "четверг": time.Thursday, "thu": time.Thursday, "thursday": time.Thursday,
+	"пт": time.Friday, "пятница": time.Friday, "fri": time.Friday, "friday": time.Friday,
+	"сб": time.Saturday, "суббота": time.Saturday, "sat": time.Saturday, "saturday": time.Saturday,
+	"вс": time.Sunday, "воскресенье": time.Sunday, "sun": time.Sunday, "sunday": time.Sunday,
+}
+
+// daySets — слова, означающие сразу несколько дней.
+var daySets = map[string]Weekdays{
+	"будни": Workdays, "weekdays": Workdays,
+	"выходные": Weekend, "weekends": Weekend, "weekend": Weekend,
+	"ежедневно": AllDays, "daily": AllDays, "каждый день": AllDays, "все дни": AllDays, "everyday": AllDays,
+}
+
+// unrestrictedWords — записи «без ограничений».
+var unrestrictedWords = map[string]bool{
+	"": true, "круглосуточно": true, "24/7": true, "без ограничений": true, "всегда": true, "any time": true, "anytime": true,
+}
+
+var (
+	// 07:00–16:00, 7-16, 7.00 — 16.00, с 7:00 до 16:00 («с» — отдельным
+	// словом, иначе «вс 10-18» теряет букву дня)
+	rangeRe = regexp.MustCompile(`(?:(?:^|\s)(?:с|from)\s*)?(\d{1,2}(?:[:.]\d{2})?)\s*(?:[-–—]|до|to)\s*(\d{1,2}(?:[:.]\d{2})?)`)
+	// пн-пт, mon–fri
+	dayRangeRe = regexp.MustCompile(`^(\p{L}+)\s*[-–—]\s*(\p{L}+)$`)
+	// разделители внутри списка дней и между интервалами
+	listSepRe = regexp.MustCompile(`[,\s]+(?:(?:и|and)[,\s]+)?`)
+)
+
+// Parse разбирает запись часов доступа, например
+// «будни 07:00–16:00; выходные 09:00–14:00» или «weekdays 7-16».
+// Части разделяются «;» или переводом строки; в одной части можно указать
+// несколько интервалов для одних дней: «пн, ср 07:00–10:00, 18:00–22:00».
+// Дни без интервала и интервал без дней не допускаются, кроме
+// «круглосуточно» (пустое расписание) и одного интервала на все дни.
+func Parse(text string) (Schedule, error) {
+	src := strings.ToLower(strings.TrimSpace(text))
+	if unrestrictedWords[src] {
+		return nil, nil
+	}
+	var s Schedule
+	for _, part := range strings.FieldsFunc(src, func(r rune) bool { return r == ';' || r == '\n' }) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		ws, err := parsePart(part)
+		if err != nil {
+			return nil, fmt.Errorf("«%s»: %w", part, err)
+		}
+		s = append(s, ws...)
+	}
+	if len(s) == 0 {
+		return nil, fmt.Errorf("не найдено ни одного интервала времени")
+	}
+	return s, nil
+}
+
+func parsePart(part string) ([]Window, error) {
+	locs := rangeRe.FindAllStringSubmatchIndex(part, -1)
+	if len(locs) == 0 {
+		return nil, fmt.Errorf("нет интервала времени вида 07:00–16:00")
+	}
+	days := AllDays
+	if head := strings.TrimSpace(part[:locs[0][0]]); head != "" {
+		d, err := parseDays(head)
+		if err != nil {
+			return nil, err
+		}
+		days = d
+	}
+
+	var ws []Window
+	for i, loc := range locs {
+		// между интервалами и после последнего — только разделители
+		tailEnd := len(part)
+		if i+1 < len(locs) {
+			tailEnd = locs[i+1][0]
+		}
+		if rest := strings.Trim(part[loc[1]:tailEnd], " ,и"); rest != "" {
+			return nil, fmt.Errorf("непонятный текст «%s»", rest)
+		}
+		from, err := parseClock(part[loc[2]:loc[3]])
+		if err != nil {
+			return nil, err
+		}
+		to, err := parseClock(part[loc[4]:loc[5]])
+		if err != nil {
+			return nil, err
+		}
+		w := Window{Days: days, From: from, To: to}
+		if to == 0 { // «22:00–00:00» — до полуночи
+			w.To = dayMinutes
+		}
+		if w.From == dayMinutes {
+			return nil, fmt.Errorf("окно не может начинаться в 24:00")
+		}
+		if err := w.validate(); err != nil {
+			return nil, err
+		}
+		ws = append(ws, w)
+	}
+	return ws, nil
+}
+
+func parseDays(text string) (Weekdays, error) {
+	if d, ok := daySets[text]; ok {
+		return d, nil
+	}
+	var days Weekdays
+	for _, tok := range listSepRe.Split(text, -1) {
+		tok = strings.Trim(tok, " .:")
+		if tok == "" {
+			continue
+		}
+		if d, ok := daySets[tok]; ok {
+			days |= d
+			continue
+		}
+		if wd, ok := dayNames[tok]; ok {
+			days |= 1 << wd
+			continue
+		}
+		m := dayRangeRe.FindStringSubmatch(tok)
+		if m == nil {
+			return 0, fmt.Errorf("неизвестный день «%s»", tok)
+		}
+		from, ok1 := dayNames[m[1]]
+		to, ok2 := dayNames[m[2]]
+		if !ok1 || !ok2 {
+			return 0, fmt.Errorf("неизвестный диапазон дней «%s»", tok)
+		}
+		// пт–пн: через выходные
+		for i := isoDay(from); ; i = i%7 + 1 {
+			days |= 1 << isoOrder[i-1]
+			if i == isoDay(to) {
+				break
+			}
+		}
+	}
+	if days == 0 {
+		return 0, fmt.Errorf("не указаны дни недели")
+	}
+	return days, nil
+}
+
+// parseClock — «7», «07:00», «7.30», «24:00».
+func parseClock(s string) (Clock, error) {
+	s = strings.TrimSpace(s)
+	h, m := s, "0"
+	if i := strings.IndexAny(s, ":."); i >= 0 {
+		h, m = s[:i], s[i+1:]
+	}
+	hh, err1 := strconv.Atoi(h)
+	mm, err2 := strconv.Atoi(m)
+	if err1 != nil || err2 != nil || hh < 0 || mm < 0 || mm > 59 || hh > 24 || (hh == 24 && mm != 0) {
+		return 0, fmt.Errorf("неверное время «%s»", s)
+	}
+	return Clock(hh*60 + mm), nil
+}
+
+// formatDays — короткая запись набора дней для String.
+func formatDays(d Weekdays) string {
+	switch d {
+	case AllDays:
+		return "ежедневно"
+	case Workdays:
+		return "будни"
+	case Weekend:
+		return "выходные"
+	}
+	short := [7]string{"пн", "вт", "ср", "чт", "пт", "сб", "вс"}
+	var parts []string
+	for i := 0; i < 7; {
+		if !d.Has(isoOrder[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j+1 < 7 && d.Has(isoOrder[j+1]) {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			parts = append(parts, short[i]+"–"+short[j])
+		case j == i+1:
+			parts = append(parts, short[i], short[j])
+		default:
+			parts = append(parts, short[i])
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ParseLegacy переносит старое значение "Тариф"."Время_доступа" (INTERVAL
+// в текстовом виде) в расписание. Нулевой интервал и «сутки и больше»
+// означают круглосуточный доступ; запись расписания (если её когда-то ввели
+// текстом) разбирается Parse. Остальное — ошибка: такой тариф нужно
+// проверить вручную.
+func ParseLegacy(text string) (Schedule, error) {
+	t := strings.ToLower(strings.TrimSpace(text))
+	switch t {
+	case "", "00:00:00", "0", "@ 0":
+		return nil, nil
+	}
+	if s, err := Parse(t); err == nil {
+		return s, nil
+	}
+	if d, ok := legacyInterval(t); ok {
+		if d == 0 || d >= 24*time.Hour {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("«%s» — длительность, а не часы доступа", text)
+	}
+	return nil, fmt.Errorf("не удалось разобрать «%s»", text)
+}
+
+// legacyInterval понимает вывод interval Postgres: «1 day 02:00:00»,
+// «30 days», «1 mon», «08:00:00». Месяц и год считаются как «больше суток».
+func legacyInterval(t string) (time.Duration, bool) {
+	var total time.Duration
+	fields := strings.Fields(t)
+	if len(fields) == 0 {
+		return 0, false
+	}
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if strings.Contains(f, ":") {
+			parts := strings.Split(f, ":")
+			if len(parts) != 3 {
+				return 0, false
+			}
+			var hms [3]float64
+			for k, p := range parts {
+				v, err := strconv.ParseFloat(p, 64)
+				if err != nil {
+					return 0, false
+				}
+				hms[k] = v
+			}
+			total += time.Duration((hms[0]*3600 + hms[1]*60 + hms[2]) * float64(time.Second))
+			continue
+		}
+		n, err := strconv.Atoi(f)
+		if err != nil || i+1 >= len(fields) {
+			return 0, false
+		}
+		i++
+		unit := strings.TrimSuffix(fields[i], "s")
+		switch unit {
+		case "day":
+			total += time.Duration(n) * 24 * time.Hour
+		case "mon", "year":
+			total += time.Duration(n) * 31 * 24 * time.Hour
+		case "hour":
+			total += time.Duration(n) * time.Hour
+		case "min", "minute":
+			total += time.Duration(n) * time.Minute
+		default:
+			return 0, false
+		}
+	}
+	return total, true
+}
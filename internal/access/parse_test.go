@@ -0,0 +1,124 @@
+package access
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // String() расписания; пусто — ждём ошибку
+	}{
+		{"будни 07:00–16:00; выходные 09:00–14:00", "будни 07:00–16:00; выходные 09:00–14:00"},
+		{"weekdays 7-16", "будни 07:00–16:00"},
+		{"Weekends 10-18", "выходные 10:00–18:00"},
+		{"будни 7-16\nвыходные 9-14", "будни 07:00–16:00; выходные 09:00–14:00"},
+		{"Пн, Ср 07:00–10:00, 18:00–22:00", "пн, ср 07:00–10:00; пн, ср 18:00–22:00"},
+		{"monday, wednesday 6-9", "пн, ср 06:00–09:00"},
+		{"сб и вс 10-18", "выходные 10:00–18:00"},
+		{"saturday and sunday 10-18", "выходные 10:00–18:00"},
+		{"сб, вс 10-18", "выходные 10:00–18:00"},
+		{"пн–вс 8-22", "ежедневно 08:00–22:00"},
+		{"пн, вс с 8 до 22", "пн, вс 08:00–22:00"},
+		{"вт-чт 8-20", "вт–чт 08:00–20:00"},
+		{"mon–fri 7.30 — 22.00", "будни 07:30–22:00"},
+		{"пт–пн 20:00–02:00", "пн, пт–вс 20:00–02:00"},
+		{"с 7:00 до 16:00", "ежедневно 07:00–16:00"},
+		{"from 7 to 16", "ежедневно 07:00–16:00"},
+		{"ежедневно 22:00–00:00", "ежедневно 22:00–24:00"},
+		{"ежедневно 00:00–24:00", "ежедневно 00:00–24:00"},
+		{"пт 22:00–06:00", "пт 22:00–06:00"},
+		{"будни, сб 9-21", "пн–сб 09:00–21:00"},
+		{"  КРУГЛОСУТОЧНО  ", "круглосуточно"},
+		{"24/7", "круглосуточно"},
+		{"", "круглосуточно"},
+
+		{"abc", ""},
+		{"пн", ""},
+		{"будни", ""},
+		{"funday 7-16", ""},
+		{"пн-xx 7-16", ""},
+		{"25:00-26:00", ""},
+		{"7:60-9", ""},
+		{"24:30-25", ""},
+		{"24:00-02:00", ""},
+		{"10-10", ""},
+		{"будни 7-16 и ещё что-то", ""},
+		{"пн 7-16; вт", ""},
+		{"; ;", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			s, err := Parse(tt.in)
+			switch {
+			case tt.want == "" && err == nil:
+				t.Fatalf("нет ошибки, расписание %q", s)
+			case tt.want == "":
+				return
+			case err != nil:
+				t.Fatalf("ошибка: %v", err)
+			}
+			if got := s.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			// каноническая запись разбирается в то же расписание
+			again, err := Parse(s.String())
+			if err != nil || again.String() != tt.want {
+				t.Errorf("Parse(String()) = %q, %v", again, err)
+			}
+		})
+	}
+}
+
+func TestParseLegacy(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string // String() расписания
+		flagged bool   // не распознано — помечается для администратора
+	}{
+		// вывод interval Postgres
+		{"00:00:00", "круглосуточно", false},
+		{"@ 0", "круглосуточно", false},
+		{"", "круглосуточно", false},
+		{"24:00:00", "круглосуточно", false},
+		{"1 day", "круглосуточно", false},
+		{"30 days", "круглосуточно", false},
+		{"1 day 02:00:00", "круглосуточно", false},
+		{"1 mon", "круглосуточно", false},
+		{"1 year 2 mons", "круглосуточно", false},
+		{"08:00:00", "", true},
+		{"12:30:00", "", true},
+		{"02:00:00.5", "", true},
+		{"-01:00:00", "", true},
+		{"90 mins", "", true},
+		// текст расписания, если его когда-то ввели
+		{"будни 07:00–16:00", "будни 07:00–16:00", false},
+		{"Weekdays 7-16", "будни 07:00–16:00", false},
+		// мусор
+		{"утро", "", true},
+		{"3 fortnights", "", true},
+		{"1:2", "", true},
+		{"1 day x", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			s, err := ParseLegacy(tt.in)
+			if (err != nil) != tt.flagged {
+				t.Fatalf("ошибка %v, want flagged=%v", err, tt.flagged)
+			}
+			if tt.flagged {
+				if !s.Unrestricted() {
+					t.Errorf("при ошибке расписание %q, want пустое", s)
+				}
+				if !strings.Contains(err.Error(), tt.in) {
+					t.Errorf("в ошибке %q нет исходного значения %q", err, tt.in)
+				}
+				return
+			}
+			if got := s.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+// Package access — часы доступа тарифа: по каким дням недели и в какие
+// интервалы времени абонемент пускает в зал.
+//
+// Расписание хранится в "Тариф"."Часы_доступа" (JSONB) как список окон
+// {"days":[1..7],"from":"HH:MM","to":"HH:MM"}, дни — ISO (1 = пн).
+// Пустое расписание (и NULL в БД) — без ограничений. Окно с to <= from
+// переходит через полночь: «пт 22:00–06:00» пускает и в ночь на субботу.
+package access
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Weekdays — набор дней недели, бит i — time.Weekday(i).
+type Weekdays uint8
+
+const (
+	AllDays  Weekdays = 1<<7 - 1
+	Workdays Weekdays = 1<<time.Monday | 1<<time.Tuesday | 1<<time.Wednesday | 1<<time.Thursday | 1<<time.Friday
+	Weekend  Weekdays = 1<<time.Saturday | 1<<time.Sunday
+)
+
+// Has — входит ли день в набор.
+func (d Weekdays) Has(wd time.Weekday) bool { return d&(1<<wd) != 0 }
+
+// isoOrder — дни в порядке пн..вс.
+var isoOrder = [7]time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+
+func isoDay(wd time.Weekday) int { return (int(wd)+6)%7 + 1 }
+
+// MarshalJSON — ISO-номера дней по возрастанию.
+func (d Weekdays) MarshalJSON() ([]byte, error) {
+	days := []int{}
+	for _, wd := range isoOrder {
+		if d.Has(wd) {
+			days = append(days, isoDay(wd))
+		}
+	}
+	return json.Marshal(days)
+}
+
+func (d *Weekdays) UnmarshalJSON(b []byte) error {
+	var days []int
+	if err := json.Unmarshal(b, &days); err != nil {
+		return err
+	}
+	*d = 0
+	for _, n := range days {
+		if n < 1 || n > 7 {
+			return fmt.Errorf("день недели %d вне диапазона 1..7", n)
+		}
+		*d |= 1 << isoOrder[n-1]
+	}
+	return nil
+}
+
+// Clock — время суток в минутах от полуночи; 24:00 допустимо как конец окна.
+type Clock int
+
+const dayMinutes = 24 * 60
+
+func (c Clock) String() string { return fmt.Sprintf("%02d:%02d", int(c)/60, int(c)%60) }
+
+func (c Clock) MarshalJSON() ([]byte, error) { return json.Marshal(c.String()) }
+
+func (c *Clock) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := parseClock(s)
+	if err != nil {
+		return err
+	}
+	*c = v
+	return nil
+}
+
+// Window — окно доступа: дни недели и интервал [From, To).
+type Window struct {
+	Days Weekdays `json:"days"`
+	From Clock    `json:"from"`
+	To   Clock    `json:"to"`
+}
+
+func (w Window) overnight() bool { return w.To <= w.From }
+
+func (w Window) validate() error {
+	switch {
+	case w.Days == 0:
+		return fmt.Errorf("не указаны дни недели")
+	case w.From < 0 || w.From >= dayMinutes || w.To <= 0 || w.To > dayMinutes:
+		return fmt.Errorf("время вне суток: %s–%s", w.From, w.To)
+	case w.From == w.To:
+		return fmt.Errorf("пустой интервал %s–%s", w.From, w.To)
+	}
+	return nil
+}
+
+// closesAt — когда закрывается это окно, если t внутри него (иначе ok=false).
+func (w Window) closesAt(t time.Time) (time.Time, bool) {
+	m := Clock(t.Hour()*60 + t.Minute())
+	y, mo, d := t.Date()
+	midnight := time.Date(y, mo, d, 0, 0, 0, 0, t.Location())
+	at := func(day int, c Clock) time.Time {
+		return midnight.AddDate(0, 0, day).Add(time.Duration(c) * time.Minute)
+	}
+
+	wd := t.Weekday()
+	switch {
+	case !w.overnight():
+		if w.Days.Has(wd) && m >= w.From && m < w.To {
+			return at(0, w.To), true
+		}
+	case w.Days.Has(wd) && m >= w.From: // вечерняя часть ночного окна
+		return at(1, w.To), true
+	case w.Days.Has((wd+6)%7) && m < w.To: // утренняя часть окна, открытого накануне
+		return at(0, w.To), true
+	}
+	return time.Time{}, false
+}
+
+// Schedule — часы доступа тарифа; пустое — круглосуточно.
+type Schedule []Window
+
+// Unrestricted — расписание не ограничивает доступ.
+func (s Schedule) Unrestricted() bool { return len(s) == 0 }
+
+// Allows — пускает ли расписание в момент t (в часовом поясе t).
+func (s Schedule) Allows(t time.Time) bool {
+	_, ok := s.openUntil(t)
+	return ok
+}
+
+// AllowsRange — пускает ли расписание на весь интервал [from, to):
+// окна, идущие встык (в т.ч. через полночь), считаются одним.
+func (s Schedule) AllowsRange(from, to time.Time) bool {
+	if s.Unrestricted() {
+		return true
+	}
+	until, ok := s.openUntil(from)
+	return ok && !until.Before(to)
+}
+
+// OpenUntil — до какого момента открыт доступ, начиная с t; ok=false — сейчас закрыто.
+// Для круглосуточного расписания возвращает нулевое время и ok=true.
+func (s Schedule) OpenUntil(t time.Time) (time.Time, bool) {
+	if s.Unrestricted() {
+		return time.Time{}, true
+	}
+	return s.openUntil(t)
+}
+
+func (s Schedule) openUntil(t time.Time) (time.Time, bool) {
+	if s.Unrestricted() {
+		return t.AddDate(100, 0, 0), true
+	}
+	var until time.Time
+	cur := t
+	// неделя окон встык — доступ фактически круглосуточный, дальше не ищем
+	for i := 0; i < 7*len(s)+1; i++ {
+		next := until
+		for _, w := range s {
+			if end, ok := w.closesAt(cur); ok && end.After(next) {
+				next = end
+			}
+		}
+		if !next.After(until) {
+			break
+		}
+		until, cur = next, next
+	}
+	return until, !until.IsZero()
+}
+
+// String — каноническая запись, которую понимает Parse.
+func (s Schedule) String() string {
+	if s.Unrestricted() {
+		return "круглосуточно"
+	}
+	parts := make([]string, 0, len(s))
+	for _, w := range s {
+		parts = append(parts, formatDays(w.Days)+" "+w.From.String()+"–"+w.To.String())
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Value — JSONB для БД; пустое расписание хранится как NULL.
+func (s Schedule) Value() (driver.Value, error) {
+	if s.Unrestricted() {
+		return nil, nil
+	}
+	b, err := json.Marshal([]Window(s))
+	return string(b), err
+}
+
+// Scan читает JSONB из БД.
+func (s *Schedule) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("access.Schedule: неподдерживаемый тип %T", src)
+	}
+	var ws []Window
+	if err := json.Unmarshal(b, &ws); err != nil {
+		return err
+	}
+	*s = ws
+	return nil
+}
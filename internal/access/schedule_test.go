@@ -0,0 +1,159 @@
+package access
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// at — время в UTC; 19.10.2026 — понедельник, 25.10 — воскресенье.
+func at(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func mustParse(t *testing.T, text string) Schedule {
+	t.Helper()
+	s, err := Parse(text)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", text, err)
+	}
+	return s
+}
+
+func TestAllows(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		t        string
+		want     bool
+	}{
+		{"без ограничений", "круглосуточно", "2026-10-19 03:00", true},
+		{"будни: начало окна", "будни 07:00–16:00", "2026-10-19 07:00", true},
+		{"будни: до начала", "будни 07:00–16:00", "2026-10-19 06:59", false},
+		{"будни: конец не входит", "будни 07:00–16:00", "2026-10-19 16:00", false},
+		{"будни: суббота", "будни 07:00–16:00", "2026-10-24 10:00", false},
+		{"до полуночи", "ежедневно 22:00–24:00", "2026-10-19 23:59", true},
+		{"до полуночи: после", "ежедневно 22:00–24:00", "2026-10-20 00:00", false},
+		{"ночь: вечер пятницы", "пт 22:00–06:00", "2026-10-23 23:00", true},
+		{"ночь: утро субботы", "пт 22:00–06:00", "2026-10-24 03:00", true},
+		{"ночь: утро субботы, закрыто", "пт 22:00–06:00", "2026-10-24 06:00", false},
+		{"ночь: утро пятницы — окно четверга не задано", "пт 22:00–06:00", "2026-10-23 03:00", false},
+		{"ночь: вечер субботы", "пт 22:00–06:00", "2026-10-24 23:00", false},
+		{"ночь из воскресенья в понедельник", "вс 20:00–02:00", "2026-10-26 01:00", true},
+		{"второе окно дня", "пн, ср 07:00–10:00, 18:00–22:00", "2026-10-21 19:00", true},
+		{"между окнами", "пн, ср 07:00–10:00, 18:00–22:00", "2026-10-21 12:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustParse(t, tt.schedule).Allows(at(tt.t)); got != tt.want {
+				t.Errorf("Allows(%s) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+// Часы сравниваются в поясе t: 05:00 UTC — это 08:00 в Москве.
+func TestAllowsLocation(t *testing.T) {
+	s := mustParse(t, "будни 07:00–16:00")
+	now := at("2026-10-19 05:00")
+	if s.Allows(now) {
+		t.Errorf("Allows(05:00 UTC) = true")
+	}
+	if !s.Allows(now.In(time.FixedZone("MSK", 3*60*60))) {
+		t.Errorf("Allows(08:00 MSK) = false")
+	}
+}
+
+func TestAllowsRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		from, to string
+		want     bool
+	}{
+		{"без ограничений", "круглосуточно", "2026-10-19 03:00", "2026-10-26 03:00", true},
+		{"внутри окна", "будни 07:00–16:00", "2026-10-19 09:00", "2026-10-19 10:00", true},
+		{"до конца окна включительно", "будни 07:00–16:00", "2026-10-19 15:00", "2026-10-19 16:00", true},
+		{"за конец окна", "будни 07:00–16:00", "2026-10-19 15:30", "2026-10-19 16:30", false},
+		{"начало до окна", "будни 07:00–16:00", "2026-10-19 06:30", "2026-10-19 07:30", false},
+		{"через полночь", "ежедневно 22:00–02:00", "2026-10-19 23:00", "2026-10-20 01:30", true},
+		{"через полночь, за конец", "ежедневно 22:00–02:00", "2026-10-19 23:00", "2026-10-20 02:30", false},
+		{"окна встык", "будни 07:00–12:00; будни 12:00–16:00", "2026-10-19 11:00", "2026-10-19 13:00", true},
+		{"окна с разрывом", "будни 07:00–12:00; будни 12:30–16:00", "2026-10-19 11:00", "2026-10-19 13:00", false},
+		{"встык через полночь", "пн 18:00–24:00; вт 00:00–06:00", "2026-10-19 23:00", "2026-10-20 01:00", true},
+		{"до полуночи без продолжения", "пн 18:00–24:00", "2026-10-19 23:00", "2026-10-20 01:00", false},
+		{"цепочка ночных окон", "ежедневно 20:00–02:00; ежедневно 02:00–08:00", "2026-10-19 21:00", "2026-10-20 07:00", true},
+		{"круглосуточно окнами: неделя", "ежедневно 00:00–24:00", "2026-10-19 10:00", "2026-10-26 10:00", true},
+		{"круглосуточно двумя окнами", "ежедневно 06:00–24:00; ежедневно 00:00–06:00", "2026-10-19 10:00", "2026-10-24 10:00", true},
+		{"круглосуточно ночными окнами", "ежедневно 12:00–06:00; ежедневно 06:00–12:00", "2026-10-19 10:00", "2026-10-24 10:00", true},
+		{"все дни, кроме воскресенья", "пн–сб 00:00–24:00", "2026-10-24 10:00", "2026-10-25 10:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustParse(t, tt.schedule).AllowsRange(at(tt.from), at(tt.to)); got != tt.want {
+				t.Errorf("AllowsRange(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenUntil(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		t        string
+		want     string // пусто — закрыто
+	}{
+		{"будни", "будни 07:00–16:00", "2026-10-19 09:00", "2026-10-19 16:00"},
+		{"закрыто", "будни 07:00–16:00", "2026-10-19 17:00", ""},
+		{"ночное окно пятницы", "пт 22:00–06:00", "2026-10-23 23:00", "2026-10-24 06:00"},
+		{"окна встык", "будни 07:00–12:00; будни 12:00–16:00", "2026-10-19 08:00", "2026-10-19 16:00"},
+		{"встык через полночь и выходные", "пт 18:00–24:00; выходные 00:00–24:00", "2026-10-23 20:00", "2026-10-26 00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, ok := mustParse(t, tt.schedule).OpenUntil(at(tt.t))
+			switch {
+			case tt.want == "" && ok:
+				t.Errorf("открыто до %s, want закрыто", until)
+			case tt.want != "" && (!ok || !until.Equal(at(tt.want))):
+				t.Errorf("OpenUntil = %s, %v; want %s", until, ok, tt.want)
+			}
+		})
+	}
+
+	// без ограничений — нулевое время
+	if until, ok := Schedule(nil).OpenUntil(at("2026-10-19 09:00")); !ok || !until.IsZero() {
+		t.Errorf("OpenUntil(круглосуточно) = %s, %v", until, ok)
+	}
+	// круглосуточно окнами — открыто минимум неделю вперёд
+	from := at("2026-10-19 09:00")
+	if until, ok := mustParse(t, "ежедневно 00:00–24:00").OpenUntil(from); !ok || until.Before(from.AddDate(0, 0, 7)) {
+		t.Errorf("OpenUntil(ежедневно 00:00–24:00) = %s, %v", until, ok)
+	}
+}
+
+func TestScheduleJSON(t *testing.T) {
+	s := mustParse(t, "будни 07:00–16:00; пт 22:00–24:00")
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `[{"days":[1,2,3,4,5],"from":"07:00","to":"16:00"},{"days":[5],"from":"22:00","to":"24:00"}]`
+	if string(b) != want {
+		t.Errorf("JSON = %s, want %s", b, want)
+	}
+	var back Schedule
+	if err := back.Scan(b); err != nil || back.String() != s.String() {
+		t.Errorf("Scan = %q, %v", back, err)
+	}
+	for _, bad := range []string{`[{"days":[0],"from":"07:00","to":"16:00"}]`, `[{"days":[1],"from":"7","to":"x"}]`} {
+		if err := back.Scan(bad); err == nil {
+			t.Errorf("Scan(%s): нет ошибки", bad)
+		}
+	}
+}
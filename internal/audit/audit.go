@@ -0,0 +1,199 @@
+// Package audit — журнал изменений данных.
+//
+// Сами записи пишет триггер audit_row_change() в БД (см. миграцию
+// 20261018091000_audit_log.sql), поэтому в журнал попадает любое изменение,
+// в том числе сделанное в обход приложения. Приложение только сообщает
+// триггеру, какой сотрудник выполняет изменение: для этого мутации
+// выполняются в транзакции, открытой через Begin.
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Actor — сотрудник, от имени которого вносятся изменения.
+type Actor struct {
+	ID    int
+	Login string
+}
+
+// Begin открывает транзакцию и передаёт актора триггерам через
+// set_config(..., true): значения живут только до конца транзакции
+// и не «протекают» в другие запросы через пул соединений.
+func Begin(ctx context.Context, db *sql.DB, a Actor) (*sql.Tx, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	id := ""
+	if a.ID > 0 {
+		id = strconv.Itoa(a.ID)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`SELECT set_config('app.actor_id', $1, true), set_config('app.actor_login', $2, true)`,
+		id, a.Login,
+	); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Entities — коды сущностей журнала и их подписи для интерфейса.
+var Entities = map[string]string{
+	"client":            "Клиент",
+	"trainer":           "Тренер",
+	"zone":              "Зона",
+	"equipment":         "Оборудование",
+	"repair":            "Заявка на ремонт",
+	"tariff":            "Тариф",
+	"subscription":      "Абонемент",
+	"group_training":    "Групповая тренировка",
+	"personal_training": "Персональная тренировка",
+	"enrollment":        "Запись на групповую",
+	"staff":             "Сотрудник",
+	"visit":             "Посещение",
+	"freeze":            "Заморозка абонемента",
+	"payment":           "Платёж",
+	"training_series":   "Серия тренировок",
+	"series_exception":  "Исключение серии",
+	"trainer_absence":   "Отсутствие тренера",
+	"trainer_rate":      "Ставка тренера",
+	"pay_rule":          "Правило оплаты тренера",
+	"payroll_period":    "Расчётный период",
+	"maintenance_plan":  "План ТО",
+	"maintenance":       "Обслуживание оборудования",
+	"vendor":            "Подрядчик",
+	"spare_part":        "Запчасть",
+	"part_usage":        "Расход запчасти",
+}
+
+// Actions — допустимые значения поля «Действие».
+var Actions = map[string]string{
+	"create": "Создание",
+	"update": "Изменение",
+	"delete": "Удаление",
+}
+
+// Change — значение поля до и после изменения.
+type Change struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// Entry — запись журнала.
+type Entry struct {
+	ID       int64             `json:"id"`
+	At       time.Time         `json:"at"`
+	ActorID  int               `json:"actor_id,omitempty"`
+	Actor    string            `json:"actor"`
+	Entity   string            `json:"entity"`
+	EntityID int               `json:"entity_id"`
+	Action   string            `json:"action"`
+	Before   json.RawMessage   `json:"before,omitempty"`
+	After    json.RawMessage   `json:"after,omitempty"`
+	Changes  map[string]Change `json:"changes"`
+}
+
+// Filter — условия выборки журнала; нулевые значения не ограничивают.
+type Filter struct {
+	Entity   string
+	EntityID int
+	Action   string
+	Actor    string // логин (без учёта регистра) или id сотрудника
+	From     time.Time
+	To       time.Time // включительно по дате: фильтр «по» — до конца дня
+	Limit    int
+	Offset   int
+}
+
+// Querier — *sql.DB или *sql.Tx, из которых читается журнал.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// List возвращает страницу журнала (новые сверху) и общее число записей по фильтру.
+func List(ctx context.Context, db Querier, f Filter) ([]Entry, int, error) {
+	var (
+		where []string
+		args  []any
+	)
+	add := func(cond string, v any) {
+		args = append(args, v)
+		where = append(where, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+	if f.Entity != "" {
+		add(`"Сущность" = ?`, f.Entity)
+	}
+	if f.EntityID > 0 {
+		add(`"id_сущности" = ?`, f.EntityID)
+	}
+	if f.Action != "" {
+		add(`"Действие" = ?`, f.Action)
+	}
+	if a := strings.TrimSpace(f.Actor); a != "" {
+		if id, err := strconv.Atoi(a); err == nil {
+			add(`"id_сотрудника" = ?`, id)
+		} else {
+			add(`LOWER("Логин") = LOWER(?)`, a)
+		}
+	}
+	if !f.From.IsZero() {
+		add(`"Время" >= ?`, f.From)
+	}
+	if !f.To.IsZero() {
+		add(`"Время" < ?`, f.To.AddDate(0, 0, 1))
+	}
+	cond := ""
+	if len(where) > 0 {
+		cond = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM "Журнал_аудита" `+cond, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit := f.Limit
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	args = append(args, limit, f.Offset)
+	rows, err := db.QueryContext(ctx, `
+        SELECT "id_записи", "Время", COALESCE("id_сотрудника", 0), COALESCE("Логин", ''),
+               "Сущность", "id_сущности", "Действие",
+               "Было", "Стало", "Изменения"
+        FROM "Журнал_аудита" `+cond+`
+        ORDER BY "Время" DESC, "id_записи" DESC
+        LIMIT $`+strconv.Itoa(len(args)-1)+` OFFSET $`+strconv.Itoa(len(args)),
+		args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []Entry{}
+	for rows.Next() {
+		var (
+			e             Entry
+			before, after []byte
+			changes       []byte
+		)
+		if err := rows.Scan(&e.ID, &e.At, &e.ActorID, &e.Actor, &e.Entity, &e.EntityID, &e.Action,
+			&before, &after, &changes); err != nil {
+			return nil, 0, err
+		}
+		e.Before, e.After = before, after
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, e)
+	}
+	return list, total, rows.Err()
+}
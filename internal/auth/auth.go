@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Роли сотрудников (значения совпадают с CHECK в таблице "Сотрудник").
+const (
+	RoleAdmin      = "Администратор"
+	RoleReception  = "Ресепшн"
+	RoleTrainer    = "Тренер"
+	RoleTechnician = "Техник"
+)
+
+// Roles — все роли в порядке отображения в интерфейсе.
+var Roles = []string{RoleAdmin, RoleReception, RoleTrainer, RoleTechnician}
+
+// sections — какие разделы меню видит роль. Сами маршруты защищаются
+// в cmd/web/main.go через handlers.RequireRole.
+var sections = map[string][]string{
+	"dashboard":     {RoleAdmin, RoleReception, RoleTrainer},
+	"checkin":       {RoleAdmin, RoleReception},
+	"clients":       {RoleAdmin, RoleReception, RoleTrainer},
+	"trainers":      {RoleAdmin, RoleReception, RoleTrainer},
+	"subscriptions": {RoleAdmin, RoleReception},
+	"tariffs":       {RoleAdmin, RoleReception},
+	"trainings":     {RoleAdmin, RoleReception, RoleTrainer},
+	"zones":         {RoleAdmin, RoleReception, RoleTrainer},
+	"equipment":     {RoleAdmin, RoleReception, RoleTrainer, RoleTechnician},
+	"repairs":       {RoleAdmin, RoleTechnician},
+	"repair_costs":  {RoleAdmin, RoleTechnician},
+	"reliability":   {RoleAdmin, RoleTechnician},
+	"reports":       {RoleAdmin},
+	"staff":         {RoleAdmin},
+	"audit":         {RoleAdmin},
+	"jobs":          {RoleAdmin},
+	"payroll":       {RoleAdmin},
+	"payments":      {RoleAdmin, RoleReception},
+	"notifications": {RoleAdmin, RoleReception},
+}
+
+// Staff — сотрудник, прошедший аутентификацию.
+type Staff struct {
+	ID        int
+	Login     string
+	FIO       string
+	Role      string
+	TrainerID int // 0, если учётка не привязана к тренеру
+}
+
+// HasRole — есть ли у сотрудника одна из перечисленных ролей.
+func (s *Staff) HasRole(roles ...string) bool {
+	if s == nil {
+		return false
+	}
+	for _, r := range roles {
+		if s.Role == r {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin — сокращение для шаблонов.
+func (s *Staff) IsAdmin() bool { return s.HasRole(RoleAdmin) }
+
+// CanSee — доступен ли раздел меню (см. sections).
+func (s *Staff) CanSee(section string) bool {
+	return s.HasRole(sections[section]...)
+}
+
+// HomePath — куда отправлять сотрудника после входа.
+func (s *Staff) HomePath() string {
+	if s.HasRole(RoleTechnician) {
+		return "/equipment"
+	}
+	return "/"
+}
+
+// ValidRole — проверка значения роли из формы.
+func ValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrWeakPassword — пароль не проходит минимальные требования.
+var ErrWeakPassword = errors.New("пароль должен содержать не менее 8 символов")
+
+// HashPassword возвращает bcrypt-хеш пароля.
+func HashPassword(password string) (string, error) {
+	if len([]rune(strings.TrimSpace(password))) < 8 {
+		return "", ErrWeakPassword
+	}
+	h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(h), nil
+}
+
+// CheckPassword сравнивает пароль с хешем за постоянное время.
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// NewSessionToken генерирует случайный токен для cookie и его хеш для БД.
+func NewSessionToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken — SHA-256 (hex) от токена; в БД хранится только он.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,114 @@
+// Package availability — когда тренер может вести тренировку: рабочие
+// часы (недельные окна, как часы доступа тарифа — см. internal/access),
+// отсутствия (отпуск, больничный, отгул) и уже назначенные тренировки.
+//
+// Время — «настенное» время клуба, как и во всех тренировках. Пустые
+// рабочие часы не ограничивают: тренер доступен в любое время суток.
+package availability
+
+import (
+	"fmt"
+	"time"
+
+	"fitness-center-manager/internal/access"
+	"fitness-center-manager/internal/models"
+)
+
+// Виды отсутствия тренера.
+const (
+	Vacation  = "Отпуск"
+	SickLeave = "Больничный"
+	DayOff    = "Отгул"
+)
+
+// ValidKind — известный ли вид отсутствия.
+func ValidKind(k string) bool { return k == Vacation || k == SickLeave || k == DayOff }
+
+// Reason — код нарушения правила (уходит в JSON как есть).
+type Reason string
+
+const (
+	OffHours Reason = "outside_working_hours" // вне рабочих часов тренера
+	Absent   Reason = "trainer_absent"        // тренер в отпуске / на больничном
+	Overlap  Reason = "absence_overlap"       // отсутствие пересекается с другим
+)
+
+// Error — нарушение правила доступности; Error() — текст для сотрудника.
+type Error struct {
+	Reason Reason
+	Msg    string
+}
+
+func (e *Error) Error() string { return e.Msg }
+
+func reject(r Reason, format string, args ...any) error {
+	return &Error{Reason: r, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Check — может ли тренер с часами hours и отсутствиями absences вести
+// тренировку [start, end). Ошибка — *Error.
+func Check(hours access.Schedule, absences []models.TrainerAbsence, start, end time.Time) error {
+	for d := day(start); d.Before(end); d = d.AddDate(0, 0, 1) {
+		for _, a := range absences {
+			if a.Covers(d) {
+				return reject(Absent, "%s у тренера: %s — %s", a.Kind,
+					a.StartDate.Format("02.01.2006"), a.EndDate.Format("02.01.2006"))
+			}
+		}
+	}
+	if !hours.AllowsRange(start, end) {
+		return reject(OffHours, "Время вне рабочих часов тренера (%s)", hours)
+	}
+	return nil
+}
+
+// CheckAbsence — новое отсутствие [start, end] не пересекается
+// с уже заведёнными existing. Ошибка — *Error.
+func CheckAbsence(existing []models.TrainerAbsence, start, end time.Time) error {
+	start, end = day(start), day(end)
+	for _, a := range existing {
+		if !start.After(a.EndDate) && !end.Before(a.StartDate) {
+			return reject(Overlap, "Пересекается с отсутствием «%s» %s — %s", a.Kind,
+				a.StartDate.Format("02.01.2006"), a.EndDate.Format("02.01.2006"))
+		}
+	}
+	return nil
+}
+
+// Interval — промежуток времени [Start, End).
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (i Interval) overlaps(start, end time.Time) bool {
+	return i.Start.Before(end) && i.End.After(start)
+}
+
+// FreeSlots — свободные слоты длительностью dur в дни [from, to]: начало
+// кратно step от полуночи и не раньше now, слот целиком в рабочих часах,
+// не задевает отсутствия и занятые интервалы busy.
+func FreeSlots(hours access.Schedule, absences []models.TrainerAbsence, busy []Interval,
+	from, to time.Time, dur, step time.Duration, now time.Time) []Interval {
+	var out []Interval
+	last := day(to).AddDate(0, 0, 1)
+next:
+	for start := day(from); start.Before(last); start = start.Add(step) {
+		end := start.Add(dur)
+		if start.Before(now) || Check(hours, absences, start, end) != nil {
+			continue
+		}
+		for _, b := range busy {
+			if b.overlaps(start, end) {
+				continue next
+			}
+		}
+		out = append(out, Interval{start, end})
+	}
+	return out
+}
+
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,52 @@
+// Package blob — хранилище файлов (фото зон, оборудования и заявок) вне
+// БД: локальный каталог (Local) или S3-совместимое хранилище (S3).
+//
+// Файлы адресуются содержимым: ключ — SHA-256 в hex (Key), поэтому
+// одинаковые файлы хранятся один раз, а запись по существующему ключу
+// ничего не меняет. В БД хранится только ключ.
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+// ErrNotFound — файла с таким ключом нет.
+var ErrNotFound = errors.New("файл не найден")
+
+// ErrBadKey — ключ не похож на SHA-256 в hex.
+var ErrBadKey = errors.New("некорректный ключ файла")
+
+// Store — хранилище файлов по ключу.
+type Store interface {
+	// Put сохраняет data под ключом key.
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Open — содержимое и размер файла; ErrNotFound, если ключа нет.
+	// Закрывает reader вызывающий.
+	Open(ctx context.Context, key string) (io.ReadCloser, int64, error)
+	// Delete удаляет файл; отсутствующий ключ — не ошибка.
+	Delete(ctx context.Context, key string) error
+}
+
+// Key — ключ файла по содержимому.
+func Key(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidKey — 64 символа hex в нижнем регистре. Проверяется до обращения
+// к хранилищу: ключ попадает в путь файла и URL.
+func ValidKey(key string) bool {
+	if len(key) != sha256.Size*2 {
+		return false
+	}
+	for _, r := range key {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,212 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config — подключение к S3-совместимому хранилищу (AWS S3, MinIO и т.п.).
+type S3Config struct {
+	Endpoint  string // http://127.0.0.1:9000, https://s3.eu-central-1.amazonaws.com
+	Region    string // по умолчанию us-east-1
+	Bucket    string
+	Prefix    string // префикс ключей объектов, напр. "photos/"
+	PathStyle bool   // адрес вида endpoint/bucket/key (MinIO); иначе bucket.endpoint/key
+	AccessKey string
+	SecretKey string
+}
+
+// emptyHash — SHA-256 пустого тела (GET, DELETE).
+const emptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+var prefixRe = regexp.MustCompile(`^[A-Za-z0-9/_.-]*$`)
+
+// S3 — файлы в бакете S3-совместимого хранилища. Запросы подписываются
+// AWS Signature Version 4; ключ объекта — Prefix + ключ файла.
+type S3 struct {
+	cfg    S3Config
+	base   *url.URL
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3 проверяет настройки; к хранилищу не обращается.
+func NewS3(cfg S3Config) (*S3, error) {
+	u, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("s3: некорректный endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("s3: нужны bucket, access_key и secret_key")
+	}
+	if !prefixRe.MatchString(cfg.Prefix) {
+		return nil, fmt.Errorf("s3: префикс может содержать только латиницу, цифры и /_.-")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if !cfg.PathStyle {
+		u.Host = cfg.Bucket + "." + u.Host
+	}
+	return &S3{cfg: cfg, base: u, client: &http.Client{Timeout: time.Minute}, now: time.Now}, nil
+}
+
+func (s *S3) objectURL(key string) string {
+	u := *s.base
+	if s.cfg.PathStyle {
+		u.Path += "/" + s.cfg.Bucket
+	}
+	u.Path += "/" + s.cfg.Prefix + key
+	return u.String()
+}
+
+func (s *S3) do(ctx context.Context, method, key string, body []byte, contentType string) (*http.Response, error) {
+	if !ValidKey(key) {
+		return nil, ErrBadKey
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	payload := emptyHash
+	if body != nil {
+		sum := sha256.Sum256(body)
+		payload = hex.EncodeToString(sum[:])
+		req.ContentLength = int64(len(body))
+		req.Header.Set("Content-Type", contentType)
+	} else {
+		req.Body = http.NoBody
+	}
+	s.sign(req, payload, s.now().UTC())
+	return s.client.Do(req)
+}
+
+// s3Error — ответ хранилища с кодом не 2xx.
+func s3Error(method, key string, resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("s3: %s %s: %s: %s", method, key, resp.Status, bytes.TrimSpace(msg))
+}
+
+func (s *S3) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if data == nil {
+		data = []byte{}
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, data, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return s3Error(http.MethodPut, key, resp)
+	}
+	return nil
+}
+
+func (s *S3) Open(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, "")
+	if err != nil {
+		return nil, 0, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, 0, ErrNotFound
+	case resp.StatusCode/100 != 2:
+		defer resp.Body.Close()
+		return nil, 0, s3Error(http.MethodGet, key, resp)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		return s3Error(http.MethodDelete, key, resp)
+	}
+	return nil
+}
+
+// sign добавляет заголовки x-amz-date, x-amz-content-sha256 и Authorization
+// (AWS Signature Version 4, сервис s3, без query-параметров).
+func (s *S3) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := amzDate[:8]
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	// подписываются host и все уже выставленные заголовки
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.Join(v, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonHeaders strings.Builder
+	for _, k := range names {
+		canonHeaders.WriteString(k + ":" + strings.TrimSpace(headers[k]) + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	canonical := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.EscapedPath()),
+		"", // query-параметров нет
+		canonHeaders.String(),
+		signed,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + s.cfg.Region + "/s3/aws4_request"
+	sum := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+
+	k := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), day)
+	k = hmacSHA256(k, s.cfg.Region)
+	k = hmacSHA256(k, "s3")
+	k = hmacSHA256(k, "aws4_request")
+	sig := hex.EncodeToString(hmacSHA256(k, toSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.cfg.AccessKey+"/"+scope+
+		", SignedHeaders="+signed+", Signature="+sig)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// uriEncode — путь для канонического запроса: путь уже экранирован
+// net/url, здесь дополнительно кодируются символы, которые net/url
+// оставляет как есть, а SigV4 требует кодировать.
+func uriEncode(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/', c == '%':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,114 @@
+// Package checkin — правила пропуска клиента в зал: по списку его
+// абонементов решает, можно ли отметить вход, и если нет — почему.
+//
+// Решение не зависит от БД и HTTP: хэндлер загружает абонементы через
+// store, вызывает Decide и по Reason формирует ответ ресепшну.
+package checkin
+
+import (
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+// Reason — код причины отказа (уходит в JSON как есть).
+type Reason string
+
+const (
+	OK             Reason = ""
+	NoSubscription Reason = "no_subscription" // абонементов нет вовсе
+	NotStarted     Reason = "not_started"     // ближайший абонемент ещё не начался
+	Expired        Reason = "expired"         // все абонементы закончились
+	Frozen         Reason = "frozen"          // действующий абонемент приостановлен или заморожен
+	OutsideHours   Reason = "outside_hours"   // тариф не пускает в это время
+	AlreadyInside  Reason = "already_inside"  // предыдущий вход не закрыт
+)
+
+var messages = map[Reason]string{
+	NoSubscription: "У клиента нет абонемента",
+	NotStarted:     "Абонемент ещё не начал действовать",
+	Expired:        "Срок абонемента истёк",
+	Frozen:         "Абонемент приостановлен или заморожен",
+	OutsideHours:   "Вне часов доступа по тарифу",
+	AlreadyInside:  "Клиент уже в зале",
+}
+
+// Message — текст для ресепшна.
+func (r Reason) Message() string { return messages[r] }
+
+// Статусы абонемента (CHECK в таблице "Абонемент").
+const (
+	StatusPending  = "Ожидает" // оформлен заранее, фоновая задача активирует в день начала
+	StatusActive   = "Активен"
+	StatusFrozen   = "Приостановлен"
+	StatusFinished = "Завершен"
+)
+
+// Decision — итог проверки. Subscription — абонемент, по которому
+// пускаем (при отказе — тот, к которому относится причина, если есть).
+type Decision struct {
+	Reason       Reason
+	Subscription *models.Subscription
+}
+
+// Allowed — можно ли отметить вход.
+func (d Decision) Allowed() bool { return d.Reason == OK }
+
+// Decide выбирает абонемент для входа в момент now. Пускает только
+// «Активен» без идущей заморозки (FrozenUntil), чей период
+// [Дата_начала, Дата_окончания] покрывает сегодняшний день, а часы
+// доступа тарифа (TariffHours) — текущее время. При отказе
+// причина — самая «близкая к пропуску»: вне часов, затем заморозка,
+// ещё не начался, истёк.
+func Decide(subs []models.Subscription, now time.Time) Decision {
+	if len(subs) == 0 {
+		return Decision{Reason: NoSubscription}
+	}
+	today := Day(now)
+
+	var outside, frozen, future, past *models.Subscription
+	for i := range subs {
+		s := &subs[i]
+		start, end := Day(s.StartDate), Day(s.EndDate)
+		switch {
+		case s.Status == StatusFinished || end.Before(today):
+			if past == nil || s.EndDate.After(past.EndDate) {
+				past = s
+			}
+		case start.After(today):
+			if future == nil || s.StartDate.Before(future.StartDate) {
+				future = s
+			}
+		case s.Status == StatusFrozen || s.FrozenUntil.Valid: // вручную или идёт заморозка
+			if frozen == nil {
+				frozen = s
+			}
+		case s.Status == StatusActive || s.Status == StatusPending: // задача могла ещё не успеть
+			if !s.TariffHours.Allows(now) {
+				if outside == nil {
+					outside = s
+				}
+				continue
+			}
+			return Decision{Subscription: s}
+		}
+	}
+
+	switch {
+	case outside != nil:
+		return Decision{Reason: OutsideHours, Subscription: outside}
+	case frozen != nil:
+		return Decision{Reason: Frozen, Subscription: frozen}
+	case future != nil:
+		return Decision{Reason: NotStarted, Subscription: future}
+	default:
+		return Decision{Reason: Expired, Subscription: past}
+	}
+}
+
+// Day — календарный день t (в его поясе) для сравнения с датами абонемента
+// (DATE из БД приходит полуночью UTC).
+func Day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -12,8 +12,12 @@ import (
 
 // Config — корневая структура приложения, читается из YAML.
 type Config struct {
-	Database DatabaseConfig `yaml:"database"`
-	Server   ServerConfig   `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Server    ServerConfig    `yaml:"server"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Jobs      JobsConfig      `yaml:"jobs"`
+	Equipment EquipmentConfig `yaml:"equipment"`
+	Storage   StorageConfig   `yaml:"storage"`
 }
 
 // DatabaseConfig — настройки подключения к Postgres + параметры пула.
@@ -30,6 +34,8 @@ type DatabaseConfig struct {
 	ConnMaxLifetimeMinutes int `yaml:"conn_max_lifetime_minutes"`
 	ConnMaxIdleMinutes     int `yaml:"conn_max_idle_minutes"`
 	ConnectTimeoutSeconds  int `yaml:"connect_timeout_seconds"`
+
+	AutoMigrate bool `yaml:"auto_migrate"` // применять миграции при старте сервера (как флаг -auto-migrate)
 }
 
 // DSN формирует keyword-DSN для github.com/lib/pq.
@@ -66,6 +72,57 @@ type ServerConfig struct {
     StaticPath   string `yaml:"static_path"`
     UploadPath   string `yaml:"upload_path"`
     ProblemBaseURL string `yaml:"problem_base_url"`
+    Timezone     string `yaml:"timezone"`   // часовой пояс клуба (IANA), в нём записано время тренировок; по умолчанию Europe/Moscow
+    PublicURL    string `yaml:"public_url"` // внешний адрес для календарных ссылок; пусто — адрес запроса
+}
+
+// AuthConfig — сессии сотрудников и первичная учётка администратора.
+type AuthConfig struct {
+	SessionTTLHours int    `yaml:"session_ttl_hours"` // время жизни сессии (по умолчанию 12 ч)
+	CookieSecure    bool   `yaml:"cookie_secure"`     // ставить Secure на cookie (включать за HTTPS)
+	AdminLogin      string `yaml:"admin_login"`       // логин администратора, создаётся при пустой таблице "Сотрудник"
+	AdminPassword   string `yaml:"admin_password"`    // берём из config.secret.yaml
+}
+
+// JobsConfig — фоновые задачи (пакет jobs).
+type JobsConfig struct {
+	Disabled               bool   `yaml:"disabled"`                 // не запускать задачи в этом экземпляре
+	SubscriptionStatusCron string `yaml:"subscription_status_cron"` // статусы абонементов по датам (по умолчанию "5 * * * *")
+	MaintenanceOverdueCron string `yaml:"maintenance_overdue_cron"` // отметка просроченного ТО (по умолчанию "20 6 * * *")
+}
+
+// EquipmentConfig — оборудование и плановое ТО.
+type EquipmentConfig struct {
+	// FlagOverdueMaintenance — исправное оборудование с просроченным ТО
+	// получает статус «Требует ТО» (задача maintenance-overdue) и не
+	// становится «Исправен», пока ТО не отмечено.
+	FlagOverdueMaintenance bool `yaml:"flag_overdue_maintenance"`
+	// RepairSLAHours — срок устранения заявки на ремонт по приоритету, ч.
+	RepairSLAHours RepairSLAConfig `yaml:"repair_sla_hours"`
+}
+
+// RepairSLAConfig — часы на устранение по приоритету (0 — по умолчанию: 24/72/168).
+type RepairSLAConfig struct {
+	High   int `yaml:"high"`
+	Medium int `yaml:"medium"`
+	Low    int `yaml:"low"`
+}
+
+// StorageConfig — хранилище файлов (фото зон, оборудования и заявок).
+type StorageConfig struct {
+	Driver string   `yaml:"driver"` // local (по умолчанию, каталог server.upload_path) или s3
+	S3     S3Config `yaml:"s3"`
+}
+
+// S3Config — S3-совместимое хранилище (AWS S3, MinIO).
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint"`   // напр. "http://127.0.0.1:9000"
+	Region    string `yaml:"region"`     // по умолчанию us-east-1
+	Bucket    string `yaml:"bucket"`
+	Prefix    string `yaml:"prefix"`     // префикс ключей объектов, напр. "photos/"
+	PathStyle bool   `yaml:"path_style"` // адрес endpoint/bucket/key (нужно для MinIO)
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `yaml:"secret_key"` // берём из config.secret.yaml
 }
 
 // LoadConfig загружает конфигурацию из config.yaml и опционально из config.secret.yaml.
@@ -90,11 +147,27 @@ func LoadConfig() *Config {
 			Database struct {
 				Password string `yaml:"password"`
 			} `yaml:"database"`
+			Auth struct {
+				AdminPassword string `yaml:"admin_password"`
+			} `yaml:"auth"`
+			Storage struct {
+				S3 struct {
+					SecretKey string `yaml:"secret_key"`
+				} `yaml:"s3"`
+			} `yaml:"storage"`
 		}
 		if err := yaml.Unmarshal(secretBytes, &secret); err != nil {
 			log.Printf("⚠️  Ошибка парсинга config.secret.yaml: %v", err)
-		} else if secret.Database.Password != "" {
-			cfg.Database.Password = secret.Database.Password
+		} else {
+			if secret.Database.Password != "" {
+				cfg.Database.Password = secret.Database.Password
+			}
+			if secret.Auth.AdminPassword != "" {
+				cfg.Auth.AdminPassword = secret.Auth.AdminPassword
+			}
+			if secret.Storage.S3.SecretKey != "" {
+				cfg.Storage.S3.SecretKey = secret.Storage.S3.SecretKey
+			}
 		}
 	} else {
 		log.Println("⚠️  config.secret.yaml не найден — пароль БД не установлен (допустимо только в dev)")
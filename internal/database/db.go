@@ -12,10 +12,21 @@ import (
 )
 
 var (
-	instance *sql.DB
-	once     sync.Once
+	instance    *sql.DB
+	once        sync.Once
+	autoMigrate bool
 )
 
+// migrateTimeout — сколько ждём применения миграций при старте.
+const migrateTimeout = 5 * time.Minute
+
+// SetAutoMigrate включает применение миграций при первом GetDB
+// (флаг -auto-migrate; то же делает database.auto_migrate в конфиге).
+// Вызывать до первого GetDB.
+func SetAutoMigrate(on bool) {
+	autoMigrate = on
+}
+
 // GetDB возвращает singleton *sql.DB. При первом вызове инициализирует подключение.
 func GetDB() *sql.DB {
 	once.Do(func() {
@@ -36,8 +47,29 @@ func Close() error {
 	return nil
 }
 
-// initDB создаёт подключение к Postgres c учётом таймаутов/пула из конфига.
+// initDB открывает пул и, если включено, доводит схему до последней версии —
+// до того, как пул получит кто-то ещё.
 func initDB() (*sql.DB, error) {
+	db, err := Open()
+	if err != nil {
+		return nil, err
+	}
+	if autoMigrate || config.LoadConfig().Database.AutoMigrate {
+		ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+		defer cancel()
+		applied, err := MigrateUp(ctx, db)
+		if err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+		log.Printf("Схема БД актуальна (применено миграций: %d)", len(applied))
+	}
+	return db, nil
+}
+
+// Open создаёт новое подключение к Postgres c учётом таймаутов/пула из конфига,
+// в обход singleton (нужно, например, команде migrate).
+func Open() (*sql.DB, error) {
 	cfg := config.LoadConfig()
 	dsn := cfg.Database.DSN()
 
@@ -0,0 +1,374 @@
+package database
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fitness-center-manager/internal/database/migrations"
+)
+
+// Таблица версий совместима с goose: базы, которые раньше мигрировали
+// утилитой goose, подхватываются без повторного применения миграций.
+const versionTable = "goose_db_version"
+
+// migrateLockKey — ключ pg_advisory_lock: два экземпляра приложения,
+// стартующие одновременно с -auto-migrate, не применят миграцию дважды.
+const migrateLockKey = 7_514_020_251_105
+
+// Migration — одна миграция из internal/database/migrations.
+type Migration struct {
+	Version int64
+	Name    string // имя файла
+
+	up, down []string // готовые к выполнению операторы
+	noTx     bool     // -- +goose NO TRANSACTION
+
+	goUp, goDown func(ctx context.Context, tx *sql.Tx) error // Go-миграция вместо SQL
+}
+
+// MigrationState — строка вывода `migrate status`.
+type MigrationState struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// LoadMigrations читает вшитые миграции, отсортированные по версии.
+func LoadMigrations() ([]Migration, error) {
+	return loadMigrations(migrations.FS, migrations.Go)
+}
+
+func loadMigrations(fsys fs.FS, goMigrations []migrations.GoMigration) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Migration, 0, len(names))
+	seen := map[int64]string{}
+	for _, name := range names {
+		base := path.Base(name)
+		num, _, ok := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(num, 10, 64)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("миграция %s: имя должно начинаться с номера версии", base)
+		}
+		if prev, dup := seen[version]; dup {
+			return nil, fmt.Errorf("миграции %s и %s: одинаковая версия %d", prev, base, version)
+		}
+		seen[version] = base
+
+		src, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		m, err := parseMigration(string(src))
+		if err != nil {
+			return nil, fmt.Errorf("миграция %s: %w", base, err)
+		}
+		m.Version, m.Name = version, base
+		list = append(list, m)
+	}
+	for _, g := range goMigrations {
+		if prev, dup := seen[g.Version]; dup {
+			return nil, fmt.Errorf("миграции %s и %s: одинаковая версия %d", prev, g.Name, g.Version)
+		}
+		seen[g.Version] = g.Name
+		list = append(list, Migration{Version: g.Version, Name: g.Name, goUp: g.Up, goDown: g.Down})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// parseMigration разбирает аннотации goose: Up/Down, StatementBegin/End
+// и NO TRANSACTION. Вне StatementBegin/End оператор заканчивается строкой,
+// оканчивающейся на «;».
+func parseMigration(src string) (Migration, error) {
+	var (
+		m       Migration
+		section *[]string
+		buf     strings.Builder
+		inBlock bool
+	)
+	flush := func() {
+		if stmt := strings.TrimSpace(buf.String()); stmt != "" && section != nil {
+			*section = append(*section, stmt)
+		}
+		buf.Reset()
+	}
+
+	sc := bufio.NewScanner(strings.NewReader(src))
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if cmd, ok := strings.CutPrefix(strings.TrimSpace(line), "-- +goose "); ok {
+			switch strings.ToUpper(strings.TrimSpace(cmd)) {
+			case "UP":
+				flush()
+				section = &m.up
+			case "DOWN":
+				flush()
+				section = &m.down
+			case "STATEMENTBEGIN":
+				flush()
+				inBlock = true
+			case "STATEMENTEND":
+				if !inBlock {
+					return m, fmt.Errorf("StatementEnd без StatementBegin")
+				}
+				inBlock = false
+				flush()
+			case "NO TRANSACTION":
+				m.noTx = true
+			default:
+				return m, fmt.Errorf("неизвестная аннотация %q", line)
+			}
+			continue
+		}
+		if section == nil {
+			continue // всё до "-- +goose Up" игнорируется
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+		if !inBlock && strings.HasSuffix(strings.TrimSpace(line), ";") {
+			flush()
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return m, err
+	}
+	if inBlock {
+		return m, fmt.Errorf("StatementBegin без StatementEnd")
+	}
+	flush()
+	if m.up == nil && m.down == nil {
+		return m, fmt.Errorf("нет секции -- +goose Up")
+	}
+	return m, nil
+}
+
+// MigrateUp применяет все ещё не применённые миграции по возрастанию версии,
+// в том числе «пропущенные» — с версией ниже последней применённой
+// (появляются при слиянии веток). Возвращает применённые миграции.
+func MigrateUp(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	all, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	err = withMigrationLock(ctx, db, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range all {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			if err := runMigration(ctx, conn, m, true); err != nil {
+				return err
+			}
+			log.Printf("🗄️  миграция применена: %s", m.Name)
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// MigrateDown откатывает последнюю (по версии) применённую миграцию.
+// Возвращает nil, если откатывать нечего.
+func MigrateDown(ctx context.Context, db *sql.DB) (*Migration, error) {
+	all, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	var undone *Migration
+	err = withMigrationLock(ctx, db, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(all) - 1; i >= 0; i-- {
+			if _, ok := applied[all[i].Version]; !ok {
+				continue
+			}
+			if err := runMigration(ctx, conn, all[i], false); err != nil {
+				return err
+			}
+			log.Printf("🗄️  миграция откачена: %s", all[i].Name)
+			undone = &all[i]
+			return nil
+		}
+		return nil
+	})
+	return undone, err
+}
+
+// MigrationStatus — все известные миграции с отметкой, применены ли они.
+func MigrationStatus(ctx context.Context, db *sql.DB) ([]MigrationState, error) {
+	all, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]MigrationState, len(all))
+	for i, m := range all {
+		at, ok := applied[m.Version]
+		list[i] = MigrationState{Migration: m, Applied: ok, AppliedAt: at}
+	}
+	return list, nil
+}
+
+// withMigrationLock выполняет fn на выделенном соединении под advisory-lock.
+func withMigrationLock(ctx context.Context, db *sql.DB, fn func(*sql.Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrateLockKey); err != nil {
+		return fmt.Errorf("блокировка миграций: %w", err)
+	}
+	defer func() {
+		// контекст мог истечь — снимаем блокировку в любом случае
+		_, _ = conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrateLockKey)
+	}()
+	if err := ensureVersionTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func ensureVersionTable(ctx context.Context, conn *sql.Conn) error {
+	var exists bool
+	if err := conn.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, versionTable).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	// как у goose: нулевая версия отмечает «пустую» базу
+	_, err := conn.ExecContext(ctx, `
+        CREATE TABLE `+versionTable+` (
+            id         SERIAL    PRIMARY KEY,
+            version_id BIGINT    NOT NULL,
+            is_applied BOOLEAN   NOT NULL,
+            tstamp     TIMESTAMP DEFAULT NOW()
+        );
+        INSERT INTO `+versionTable+` (version_id, is_applied) VALUES (0, TRUE);
+    `)
+	return err
+}
+
+// appliedVersions — применённые версии и время применения. Для каждой версии
+// решает самая поздняя запись (так же читает таблицу goose).
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	var exists bool
+	if err := conn.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, versionTable).Scan(&exists); err != nil {
+		return nil, err
+	}
+	applied := map[int64]time.Time{}
+	if !exists {
+		return applied, nil // status на пустой базе — ничего не применено
+	}
+	rows, err := conn.QueryContext(ctx, `
+        SELECT version_id, is_applied, COALESCE(tstamp, NOW())
+        FROM `+versionTable+`
+        ORDER BY id DESC
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	seen := map[int64]bool{}
+	for rows.Next() {
+		var (
+			v  int64
+			ok bool
+			at time.Time
+		)
+		if err := rows.Scan(&v, &ok, &at); err != nil {
+			return nil, err
+		}
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		if ok && v > 0 {
+			applied[v] = at
+		}
+	}
+	return applied, rows.Err()
+}
+
+// runMigration выполняет одну секцию миграции и отмечает результат в таблице версий.
+// Обычно — в одной транзакции, чтобы упавшая миграция не оставила схему наполовину.
+func runMigration(ctx context.Context, conn *sql.Conn, m Migration, up bool) error {
+	stmts, mark, args := m.up, `INSERT INTO `+versionTable+` (version_id, is_applied) VALUES ($1, TRUE)`, []any{m.Version}
+	if !up {
+		stmts, mark = m.down, `DELETE FROM `+versionTable+` WHERE version_id = $1`
+	}
+	wrap := func(err error) error {
+		dir := "up"
+		if !up {
+			dir = "down"
+		}
+		return fmt.Errorf("миграция %s (%s): %w", m.Name, dir, err)
+	}
+
+	if m.noTx {
+		for _, s := range stmts {
+			if _, err := conn.ExecContext(ctx, s); err != nil {
+				return wrap(err)
+			}
+		}
+		if _, err := conn.ExecContext(ctx, mark, args...); err != nil {
+			return wrap(err)
+		}
+		return nil
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return wrap(err)
+	}
+	defer tx.Rollback()
+	for _, s := range stmts {
+		if _, err := tx.ExecContext(ctx, s); err != nil {
+			return wrap(err)
+		}
+	}
+	fn := m.goUp
+	if !up {
+		fn = m.goDown
+	}
+	if fn != nil {
+		if err := fn(ctx, tx); err != nil {
+			return wrap(err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, mark, args...); err != nil {
+		return wrap(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return wrap(err)
+	}
+	return nil
+}
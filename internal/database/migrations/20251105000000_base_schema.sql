@@ -0,0 +1,142 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Базовая схема предметной области. Раньше создавалась скриптами из ./init
+-- (docker-entrypoint-initdb.d); теперь приложение поднимает пустую БД само.
+-- IF NOT EXISTS — чтобы миграция безопасно «накатилась» и на старые базы,
+-- где эти таблицы уже созданы вручную.
+
+CREATE TABLE IF NOT EXISTS "Клиент" (
+    "id_клиента"          SERIAL       PRIMARY KEY,
+    "ФИО"                 VARCHAR(150) NOT NULL,
+    "Номер_телефона"      VARCHAR(20)  NOT NULL,
+    "Дата_рождения"       DATE         NOT NULL,
+    "Дата_регистрации"    DATE         NOT NULL DEFAULT CURRENT_DATE,
+    "Медицинские_данные"  TEXT
+);
+
+CREATE TABLE IF NOT EXISTS "Тренер" (
+    "id_тренера"      SERIAL       PRIMARY KEY,
+    "ФИО"             VARCHAR(150) NOT NULL,
+    "Номер_телефона"  VARCHAR(20)  NOT NULL,
+    "Специализация"   VARCHAR(100),
+    "Дата_найма"      DATE         NOT NULL DEFAULT CURRENT_DATE,
+    "Стаж_работы"     INTEGER      NOT NULL DEFAULT 0 CHECK ("Стаж_работы" >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS "Зона" (
+    "id_зоны"      SERIAL       PRIMARY KEY,
+    "Название"     VARCHAR(100) NOT NULL,
+    "Описание"     TEXT,
+    "Вместимость"  INTEGER      NOT NULL CHECK ("Вместимость" > 0),
+    "Статус"       VARCHAR(20)  NOT NULL DEFAULT 'Доступна',
+    "Фото"         BYTEA,
+    CONSTRAINT "Зона_Статус_check"
+        CHECK ("Статус" IN ('Доступна','На ремонте','Закрыта'))
+);
+
+CREATE TABLE IF NOT EXISTS "Оборудование" (
+    "id_оборудования"     SERIAL       PRIMARY KEY,
+    "id_зоны"             INTEGER      NOT NULL REFERENCES "Зона"("id_зоны"),
+    "Название"            VARCHAR(100) NOT NULL,
+    "Дата_покупки"        DATE,
+    "Дата_последнего_ТО"  DATE,
+    "Статус"              VARCHAR(20)  NOT NULL DEFAULT 'Исправен',
+    "Фото"                BYTEA,
+    CONSTRAINT "Оборудование_Статус_check"
+        CHECK ("Статус" IN ('Исправен','На ремонте','Списан'))
+);
+
+CREATE TABLE IF NOT EXISTS "Заявка_на_ремонт" (
+    "id_заявки"          SERIAL      PRIMARY KEY,
+    "id_оборудования"    INTEGER     NOT NULL REFERENCES "Оборудование"("id_оборудования"),
+    "Дата_создания"      TIMESTAMP   NOT NULL DEFAULT NOW(),
+    "Описание_проблемы"  TEXT        NOT NULL,
+    "Приоритет"          VARCHAR(20) NOT NULL DEFAULT 'Средний',
+    "Статус"             VARCHAR(20) NOT NULL DEFAULT 'Открыта',
+    "Фото"               BYTEA,
+    CONSTRAINT "Заявка_на_ремонт_Приоритет_check"
+        CHECK ("Приоритет" IN ('Низкий','Средний','Высокий')),
+    CONSTRAINT "Заявка_на_ремонт_Статус_check"
+        CHECK ("Статус" IN ('Открыта','В работе','Закрыта'))
+);
+
+CREATE TABLE IF NOT EXISTS "Тариф" (
+    "id_тарифа"                        SERIAL        PRIMARY KEY,
+    "Название_тарифа"                  VARCHAR(100)  NOT NULL,
+    "Описание"                         TEXT,
+    "Стоимость"                        NUMERIC(10,2) NOT NULL CHECK ("Стоимость" >= 0),
+    "Время_доступа"                    INTERVAL,
+    "Наличие_групповых_тренировок"     BOOLEAN       NOT NULL DEFAULT FALSE,
+    "Наличие_персональных_тренировок"  BOOLEAN       NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE IF NOT EXISTS "Абонемент" (
+    "id_абонемента"   SERIAL        PRIMARY KEY,
+    "id_клиента"      INTEGER       NOT NULL REFERENCES "Клиент"("id_клиента"),
+    "id_тарифа"       INTEGER       NOT NULL REFERENCES "Тариф"("id_тарифа"),
+    "Дата_начала"     DATE          NOT NULL,
+    "Дата_окончания"  DATE          NOT NULL,
+    "Статус"          VARCHAR(20)   NOT NULL DEFAULT 'Активен',
+    "Цена"            NUMERIC(10,2) NOT NULL CHECK ("Цена" >= 0),
+    CONSTRAINT "Абонемент_Статус_check"
+        CHECK ("Статус" IN ('Активен','Приостановлен','Завершен')),
+    CONSTRAINT "Абонемент_даты_check"
+        CHECK ("Дата_окончания" >= "Дата_начала")
+);
+
+CREATE TABLE IF NOT EXISTS "Групповая_тренировка" (
+    "id_групповой_тренировки"  SERIAL       PRIMARY KEY,
+    "id_тренера"               INTEGER      NOT NULL REFERENCES "Тренер"("id_тренера"),
+    "id_зоны"                  INTEGER      NOT NULL REFERENCES "Зона"("id_зоны"),
+    "Название"                 VARCHAR(100) NOT NULL,
+    "Описание"                 TEXT,
+    "Максимум_участников"      INTEGER      NOT NULL CHECK ("Максимум_участников" > 0),
+    "Время_начала"             TIMESTAMP    NOT NULL,
+    "Время_окончания"          TIMESTAMP    NOT NULL,
+    "Уровень_сложности"        VARCHAR(20),
+    CONSTRAINT "Групповая_тренировка_Уровень_сложности_check"
+        CHECK ("Уровень_сложности" IN ('Начальный','Средний','Продвинутый')),
+    CONSTRAINT "Групповая_тренировка_время_check"
+        CHECK ("Время_окончания" > "Время_начала")
+);
+
+CREATE TABLE IF NOT EXISTS "Персональная_тренировка" (
+    "id_персональной_тренировки"  SERIAL        PRIMARY KEY,
+    "id_абонемента"               INTEGER       NOT NULL REFERENCES "Абонемент"("id_абонемента"),
+    "id_тренера"                  INTEGER       NOT NULL REFERENCES "Тренер"("id_тренера"),
+    "Время_начала"                TIMESTAMP     NOT NULL,
+    "Время_окончания"             TIMESTAMP     NOT NULL,
+    "Статус"                      VARCHAR(20)   NOT NULL DEFAULT 'Запланирована',
+    "Стоимость"                   NUMERIC(10,2) CHECK ("Стоимость" >= 0),
+    CONSTRAINT "Персональная_тренировка_Статус_check"
+        CHECK ("Статус" IN ('Запланирована','Завершена','Отменена')),
+    CONSTRAINT "Персональная_тренировка_время_check"
+        CHECK ("Время_окончания" > "Время_начала")
+);
+
+CREATE TABLE IF NOT EXISTS "Запись_на_групповую_тренировку" (
+    "id_записи"                SERIAL      PRIMARY KEY,
+    "id_групповой_тренировки"  INTEGER     NOT NULL
+        REFERENCES "Групповая_тренировка"("id_групповой_тренировки") ON DELETE CASCADE,
+    "id_абонемента"            INTEGER     NOT NULL REFERENCES "Абонемент"("id_абонемента"),
+    "Статус"                   VARCHAR(20) NOT NULL DEFAULT 'Записан',
+    CONSTRAINT "Запись_на_групповую_тренировку_Статус_check"
+        CHECK ("Статус" IN ('Записан','Посетил','Отменил')),
+    CONSTRAINT "Запись_на_групповую_тренировку_uniq"
+        UNIQUE ("id_групповой_тренировки", "id_абонемента")
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "Запись_на_групповую_тренировку";
+DROP TABLE IF EXISTS "Персональная_тренировка";
+DROP TABLE IF EXISTS "Групповая_тренировка";
+DROP TABLE IF EXISTS "Абонемент";
+DROP TABLE IF EXISTS "Тариф";
+DROP TABLE IF EXISTS "Заявка_на_ремонт";
+DROP TABLE IF EXISTS "Оборудование";
+DROP TABLE IF EXISTS "Зона";
+DROP TABLE IF EXISTS "Тренер";
+DROP TABLE IF EXISTS "Клиент";
+-- +goose StatementEnd
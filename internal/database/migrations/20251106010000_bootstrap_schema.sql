@@ -1,21 +1,35 @@
 -- +goose Up
 -- +goose StatementBegin
--- Базовые права на схему и последовательности
-GRANT USAGE ON SCHEMA public TO app_user;
-GRANT CREATE ON SCHEMA public TO app_user;
+-- Базовые права на схему и последовательности.
+-- Роль app_user создаётся администратором БД; если её нет (приложение само
+-- владеет схемой) — выдавать права некому, миграция ничего не делает.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_user') THEN
+        GRANT USAGE ON SCHEMA public TO app_user;
+        GRANT CREATE ON SCHEMA public TO app_user;
 
-GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO app_user;
+        GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO app_user;
 
-ALTER DEFAULT PRIVILEGES IN SCHEMA public
-GRANT USAGE, SELECT ON SEQUENCES TO app_user;
+        ALTER DEFAULT PRIVILEGES IN SCHEMA public
+        GRANT USAGE, SELECT ON SEQUENCES TO app_user;
+    END IF;
+END
+$$;
 -- +goose StatementEnd
 
 -- +goose Down
 -- +goose StatementBegin
-REVOKE USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public FROM app_user;
-REVOKE CREATE ON SCHEMA public FROM app_user;
-REVOKE USAGE ON SCHEMA public FROM app_user;
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'app_user') THEN
+        REVOKE USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public FROM app_user;
+        REVOKE CREATE ON SCHEMA public FROM app_user;
+        REVOKE USAGE ON SCHEMA public FROM app_user;
 
-ALTER DEFAULT PRIVILEGES IN SCHEMA public
-REVOKE USAGE, SELECT ON SEQUENCES FROM app_user;
+        ALTER DEFAULT PRIVILEGES IN SCHEMA public
+        REVOKE USAGE, SELECT ON SEQUENCES FROM app_user;
+    END IF;
+END
+$$;
 -- +goose StatementEnd
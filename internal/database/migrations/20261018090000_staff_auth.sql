@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Учётные записи сотрудников (вход в систему + роль)
+CREATE TABLE IF NOT EXISTS "Сотрудник" (
+    "id_сотрудника"   SERIAL PRIMARY KEY,
+    "Логин"           VARCHAR(64)  NOT NULL,
+    "Хеш_пароля"      TEXT         NOT NULL,
+    "ФИО"             VARCHAR(150) NOT NULL,
+    "Роль"            VARCHAR(20)  NOT NULL,
+    "id_тренера"      INTEGER      REFERENCES "Тренер"("id_тренера") ON DELETE SET NULL,
+    "Активен"         BOOLEAN      NOT NULL DEFAULT TRUE,
+    "Дата_создания"   TIMESTAMP    NOT NULL DEFAULT NOW(),
+    CONSTRAINT "Сотрудник_роль_check"
+        CHECK ("Роль" IN ('Администратор','Ресепшн','Тренер','Техник'))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_staff_login_low ON "Сотрудник"(LOWER("Логин"));
+
+-- Сессии: в БД храним только SHA-256 от токена из cookie
+CREATE TABLE IF NOT EXISTS "Сессия_сотрудника" (
+    "Токен_хеш"       CHAR(64)  PRIMARY KEY,
+    "id_сотрудника"   INTEGER   NOT NULL REFERENCES "Сотрудник"("id_сотрудника") ON DELETE CASCADE,
+    "Создана"         TIMESTAMP NOT NULL DEFAULT NOW(),
+    "Истекает"        TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_staff_session_expires ON "Сессия_сотрудника"("Истекает");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "Сессия_сотрудника";
+DROP TABLE IF EXISTS "Сотрудник";
+-- +goose StatementEnd
@@ -0,0 +1,120 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Журнал аудита: кто, когда и что изменил (снимки строки до/после + дифф)
+CREATE TABLE IF NOT EXISTS "Журнал_аудита" (
+    "id_записи"       BIGSERIAL    PRIMARY KEY,
+    "Время"           TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    "id_сотрудника"   INTEGER,                 -- без FK: запись должна пережить удаление учётки
+    "Логин"           VARCHAR(64),             -- NULL — изменение не из приложения (psql, миграции)
+    "Сущность"        VARCHAR(40)  NOT NULL,   -- client, subscription, tariff, ...
+    "id_сущности"     INTEGER      NOT NULL,
+    "Действие"        VARCHAR(10)  NOT NULL,
+    "Было"            JSONB,
+    "Стало"           JSONB,
+    "Изменения"       JSONB        NOT NULL DEFAULT '{}'::jsonb,
+    CONSTRAINT "Журнал_аудита_действие_check"
+        CHECK ("Действие" IN ('create','update','delete'))
+);
+CREATE INDEX IF NOT EXISTS idx_audit_entity ON "Журнал_аудита"("Сущность", "id_сущности", "Время" DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_time   ON "Журнал_аудита"("Время" DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_actor  ON "Журнал_аудита"("id_сотрудника");
+
+-- Бинарные и секретные поля в журнал не пишем — только отпечаток,
+-- чтобы было видно сам факт изменения.
+CREATE OR REPLACE FUNCTION audit_mask(j JSONB) RETURNS JSONB
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT CASE WHEN j IS NULL THEN NULL ELSE
+        (j - 'Фото' - 'Хеш_пароля')
+        || CASE WHEN j ? 'Фото'
+                THEN jsonb_build_object('Фото', md5(j->>'Фото'))
+                ELSE '{}'::jsonb END
+        || CASE WHEN j ? 'Хеш_пароля'
+                THEN jsonb_build_object('Хеш_пароля', md5(j->>'Хеш_пароля'))
+                ELSE '{}'::jsonb END
+    END
+$$;
+
+-- Общий триггер: TG_ARGV[0] — код сущности, TG_ARGV[1] — колонка первичного ключа.
+-- Сотрудник берётся из app.actor_id / app.actor_login (см. internal/audit.Begin).
+CREATE OR REPLACE FUNCTION audit_row_change() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+DECLARE
+    old_j  JSONB;
+    new_j  JSONB;
+    diff   JSONB;
+    action TEXT;
+BEGIN
+    IF TG_OP <> 'INSERT' THEN old_j := audit_mask(to_jsonb(OLD)); END IF;
+    IF TG_OP <> 'DELETE' THEN new_j := audit_mask(to_jsonb(NEW)); END IF;
+
+    SELECT COALESCE(jsonb_object_agg(k, jsonb_build_object('old', old_j->k, 'new', new_j->k)), '{}'::jsonb)
+      INTO diff
+      FROM jsonb_object_keys(COALESCE(old_j, '{}'::jsonb) || COALESCE(new_j, '{}'::jsonb)) AS k
+     WHERE (old_j->k) IS DISTINCT FROM (new_j->k);
+
+    IF TG_OP = 'UPDATE' AND diff = '{}'::jsonb THEN
+        RETURN NULL; -- UPDATE без фактических изменений не журналируем
+    END IF;
+
+    action := CASE TG_OP WHEN 'INSERT' THEN 'create' WHEN 'UPDATE' THEN 'update' ELSE 'delete' END;
+
+    INSERT INTO "Журнал_аудита"
+        ("id_сотрудника","Логин","Сущность","id_сущности","Действие","Было","Стало","Изменения")
+    VALUES (
+        NULLIF(current_setting('app.actor_id', true), '')::INTEGER,
+        NULLIF(current_setting('app.actor_login', true), ''),
+        TG_ARGV[0],
+        (COALESCE(new_j, old_j)->>TG_ARGV[1])::INTEGER,
+        action, old_j, new_j, diff
+    );
+    RETURN NULL;
+END
+$$;
+
+DO $$
+DECLARE
+    t RECORD;
+BEGIN
+    FOR t IN
+        SELECT * FROM (VALUES
+            ('Клиент',                         'client',            'id_клиента'),
+            ('Тренер',                         'trainer',           'id_тренера'),
+            ('Зона',                           'zone',              'id_зоны'),
+            ('Оборудование',                   'equipment',         'id_оборудования'),
+            ('Заявка_на_ремонт',               'repair',            'id_заявки'),
+            ('Тариф',                          'tariff',            'id_тарифа'),
+            ('Абонемент',                      'subscription',      'id_абонемента'),
+            ('Групповая_тренировка',           'group_training',    'id_групповой_тренировки'),
+            ('Персональная_тренировка',        'personal_training', 'id_персональной_тренировки'),
+            ('Запись_на_групповую_тренировку', 'enrollment',        'id_записи'),
+            ('Сотрудник',                      'staff',             'id_сотрудника')
+        ) AS v(tbl, entity, pk)
+    LOOP
+        EXECUTE format('DROP TRIGGER IF EXISTS trg_audit ON %I', t.tbl);
+        EXECUTE format(
+            'CREATE TRIGGER trg_audit AFTER INSERT OR UPDATE OR DELETE ON %I
+                 FOR EACH ROW EXECUTE FUNCTION audit_row_change(%L, %L)',
+            t.tbl, t.entity, t.pk);
+    END LOOP;
+END
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DO $$
+DECLARE
+    tbl TEXT;
+BEGIN
+    FOREACH tbl IN ARRAY ARRAY['Клиент','Тренер','Зона','Оборудование','Заявка_на_ремонт','Тариф',
+                               'Абонемент','Групповая_тренировка','Персональная_тренировка',
+                               'Запись_на_групповую_тренировку','Сотрудник']
+    LOOP
+        EXECUTE format('DROP TRIGGER IF EXISTS trg_audit ON %I', tbl);
+    END LOOP;
+END
+$$;
+DROP FUNCTION IF EXISTS audit_row_change();
+DROP FUNCTION IF EXISTS audit_mask(JSONB);
+DROP TABLE IF EXISTS "Журнал_аудита";
+-- +goose StatementEnd
@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Представления, которые читает страница тренировок. Раньше жили только
+-- в ./init; пересоздаём целиком, т.к. в старых базах набор колонок мог отличаться.
+DROP VIEW IF EXISTS public.vw_group_training_with_slots;
+CREATE VIEW public.vw_group_training_with_slots AS
+SELECT
+  g."id_групповой_тренировки",
+  g."id_тренера",
+  g."id_зоны",
+  g."Название",
+  g."Описание",
+  g."Максимум_участников",
+  g."Время_начала",
+  g."Время_окончания",
+  g."Уровень_сложности",
+  t."ФИО"                                   AS trainer_name,    -- lookup
+  z."Название"                              AS zone_name,       -- lookup
+  COALESCE(e.enrolled_count, 0)             AS enrolled_count,  -- вычисляемая
+  GREATEST(g."Максимум_участников" - COALESCE(e.enrolled_count, 0), 0) AS free_slots
+FROM public."Групповая_тренировка" g
+JOIN public."Тренер" t ON t."id_тренера" = g."id_тренера"
+JOIN public."Зона"   z ON z."id_зоны"    = g."id_зоны"
+LEFT JOIN (
+  SELECT "id_групповой_тренировки", COUNT(*) AS enrolled_count
+  FROM public."Запись_на_групповую_тренировку"
+  WHERE "Статус" IN ('Записан','Посетил')
+  GROUP BY "id_групповой_тренировки"
+) e ON e."id_групповой_тренировки" = g."id_групповой_тренировки";
+
+DROP VIEW IF EXISTS public.vw_personal_training_enriched;
+CREATE VIEW public.vw_personal_training_enriched AS
+SELECT
+  p."id_персональной_тренировки",
+  p."id_абонемента",
+  p."id_тренера",
+  p."Время_начала",
+  p."Время_окончания",
+  p."Статус",
+  p."Стоимость",
+  a."id_клиента",
+  c."ФИО"                              AS client_fio,   -- lookup через абонемент -> клиент
+  tr."ФИО"                             AS trainer_fio,  -- lookup
+  EXTRACT(EPOCH FROM (p."Время_окончания" - p."Время_начала"))/60::int AS duration_minutes,
+  (p."Время_начала" >= NOW())          AS is_upcoming
+FROM public."Персональная_тренировка" p
+JOIN public."Абонемент" a ON a."id_абонемента" = p."id_абонемента"
+JOIN public."Клиент"    c ON c."id_клиента"    = a."id_клиента"
+JOIN public."Тренер"   tr ON tr."id_тренера"   = p."id_тренера";
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW IF EXISTS public.vw_personal_training_enriched;
+DROP VIEW IF EXISTS public.vw_group_training_with_slots;
+-- +goose StatementEnd
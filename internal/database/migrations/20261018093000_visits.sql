@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Номер клубной карты: ресепшн сканирует его на входе вместо поиска по ФИО
+ALTER TABLE "Клиент" ADD COLUMN IF NOT EXISTS "Номер_карты" VARCHAR(32);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_client_card ON "Клиент"("Номер_карты")
+    WHERE "Номер_карты" IS NOT NULL;
+
+-- Посещения: вход/выход клиента по абонементу
+CREATE TABLE IF NOT EXISTS "Посещение" (
+    "id_посещения"   SERIAL     PRIMARY KEY,
+    "id_клиента"     INTEGER    NOT NULL REFERENCES "Клиент"("id_клиента") ON DELETE CASCADE,
+    "id_абонемента"  INTEGER    REFERENCES "Абонемент"("id_абонемента") ON DELETE SET NULL,
+    "Время_входа"    TIMESTAMP  NOT NULL DEFAULT NOW(),
+    "Время_выхода"   TIMESTAMP,
+    "id_сотрудника"  INTEGER,   -- кто отметил вход; без FK, как в журнале аудита
+    CONSTRAINT "Посещение_выход_check" CHECK ("Время_выхода" IS NULL OR "Время_выхода" >= "Время_входа")
+);
+CREATE INDEX IF NOT EXISTS idx_visit_client_entered ON "Посещение"("id_клиента", "Время_входа" DESC);
+CREATE INDEX IF NOT EXISTS idx_visit_entered ON "Посещение"("Время_входа");
+-- клиент не может быть «в зале» дважды
+CREATE UNIQUE INDEX IF NOT EXISTS ux_visit_open ON "Посещение"("id_клиента")
+    WHERE "Время_выхода" IS NULL;
+
+DROP TRIGGER IF EXISTS trg_audit ON "Посещение";
+CREATE TRIGGER trg_audit AFTER INSERT OR UPDATE OR DELETE ON "Посещение"
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('visit', 'id_посещения');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "Посещение";
+DROP INDEX IF EXISTS ux_client_card;
+ALTER TABLE "Клиент" DROP COLUMN IF EXISTS "Номер_карты";
+-- +goose StatementEnd
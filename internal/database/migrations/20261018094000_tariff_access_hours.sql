@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Часы доступа тарифа: окна по дням недели (см. internal/access).
+-- NULL — без ограничений. Старое "Время_доступа" остаётся как исходник;
+-- его разбирает Go-миграция 20261018094100 и пишет сюда непонятые записи.
+ALTER TABLE "Тариф" ADD COLUMN IF NOT EXISTS "Часы_доступа" JSONB;
+ALTER TABLE "Тариф" ADD COLUMN IF NOT EXISTS "Часы_доступа_ошибка" TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "Тариф" DROP COLUMN IF EXISTS "Часы_доступа_ошибка";
+ALTER TABLE "Тариф" DROP COLUMN IF EXISTS "Часы_доступа";
+-- +goose StatementEnd
@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"fitness-center-manager/internal/access"
+)
+
+func init() {
+	register(20261018094100, "20261018094100_parse_tariff_access_hours.go", parseTariffAccessHours, nil)
+}
+
+// parseTariffAccessHours переносит "Время_доступа" в "Часы_доступа".
+// Непонятые записи остаются без ограничений и помечаются в
+// "Часы_доступа_ошибка" — страница тарифов показывает их администратору.
+func parseTariffAccessHours(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `
+        SELECT "id_тарифа", "Время_доступа"::text
+        FROM "Тариф"
+        WHERE "Время_доступа" IS NOT NULL AND "Часы_доступа" IS NULL
+    `)
+	if err != nil {
+		return err
+	}
+	type legacy struct {
+		id   int
+		text string
+	}
+	var list []legacy
+	for rows.Next() {
+		var l legacy
+		if err := rows.Scan(&l.id, &l.text); err != nil {
+			rows.Close()
+			return err
+		}
+		list = append(list, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, l := range list {
+		s, perr := access.ParseLegacy(l.text)
+		var issue any
+		if perr != nil {
+			issue = perr.Error()
+			log.Printf("⚠️  тариф %d: часы доступа не распознаны: %v", l.id, perr)
+		}
+		if _, err := tx.ExecContext(ctx, `
+            UPDATE "Тариф" SET "Часы_доступа" = $2::jsonb, "Часы_доступа_ошибка" = $3
+            WHERE "id_тарифа" = $1
+        `, l.id, s, issue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
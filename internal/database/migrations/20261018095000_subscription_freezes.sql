@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Лимиты заморозки по тарифу: суммарно дней и число заморозок на абонемент
+ALTER TABLE "Тариф" ADD COLUMN IF NOT EXISTS "Заморозка_макс_дней" INTEGER NOT NULL DEFAULT 30;
+ALTER TABLE "Тариф" ADD COLUMN IF NOT EXISTS "Заморозка_макс_раз"  INTEGER NOT NULL DEFAULT 2;
+ALTER TABLE "Тариф" DROP CONSTRAINT IF EXISTS "Тариф_заморозка_check";
+ALTER TABLE "Тариф" ADD CONSTRAINT "Тариф_заморозка_check"
+    CHECK ("Заморозка_макс_дней" >= 0 AND "Заморозка_макс_раз" >= 0);
+
+-- Заморозки абонемента: период [Дата_начала, Дата_окончания] включительно.
+-- На время заморозки вход и запись на групповые закрыты, а
+-- "Абонемент"."Дата_окончания" сдвигается на её длительность.
+CREATE TABLE IF NOT EXISTS "Заморозка_абонемента" (
+    "id_заморозки"    SERIAL       PRIMARY KEY,
+    "id_абонемента"   INTEGER      NOT NULL REFERENCES "Абонемент"("id_абонемента") ON DELETE CASCADE,
+    "Дата_начала"     DATE         NOT NULL,
+    "Дата_окончания"  DATE         NOT NULL,
+    "Причина"         VARCHAR(200) NOT NULL,
+    "Создана"         TIMESTAMP    NOT NULL DEFAULT NOW(),
+    CONSTRAINT "Заморозка_период_check" CHECK ("Дата_окончания" >= "Дата_начала")
+);
+CREATE INDEX IF NOT EXISTS idx_freeze_subscription ON "Заморозка_абонемента"("id_абонемента", "Дата_начала");
+
+DROP TRIGGER IF EXISTS trg_audit ON "Заморозка_абонемента";
+CREATE TRIGGER trg_audit AFTER INSERT OR UPDATE OR DELETE ON "Заморозка_абонемента"
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('freeze', 'id_заморозки');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "Заморозка_абонемента";
+ALTER TABLE "Тариф" DROP CONSTRAINT IF EXISTS "Тариф_заморозка_check";
+ALTER TABLE "Тариф" DROP COLUMN IF EXISTS "Заморозка_макс_раз";
+ALTER TABLE "Тариф" DROP COLUMN IF EXISTS "Заморозка_макс_дней";
+-- +goose StatementEnd
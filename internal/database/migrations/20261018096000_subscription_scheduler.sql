@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- «Ожидает» — абонемент оформлен заранее и ещё не начался; в день начала
+-- фоновая задача переводит его в «Активен».
+ALTER TABLE "Абонемент" DROP CONSTRAINT IF EXISTS "Абонемент_Статус_check";
+ALTER TABLE "Абонемент" ADD CONSTRAINT "Абонемент_Статус_check"
+    CHECK ("Статус" IN ('Ожидает','Активен','Приостановлен','Завершен'));
+UPDATE "Абонемент" SET "Статус" = 'Ожидает'
+ WHERE "Статус" = 'Активен' AND "Дата_начала" > CURRENT_DATE;
+
+-- Журнал запусков фоновых задач (страница /admin/jobs). Пишет тот
+-- экземпляр приложения, который взял advisory-lock задачи.
+CREATE TABLE IF NOT EXISTS "Запуск_задачи" (
+    "id_запуска"   BIGSERIAL    PRIMARY KEY,
+    "Задача"       VARCHAR(64)  NOT NULL,
+    "Узел"         VARCHAR(128) NOT NULL DEFAULT '',
+    "Начало"       TIMESTAMP    NOT NULL DEFAULT NOW(),
+    "Окончание"    TIMESTAMP,
+    "Статус"       VARCHAR(16)  NOT NULL DEFAULT 'Выполняется',
+    "Изменено"     INTEGER      NOT NULL DEFAULT 0,
+    "Подробности"  TEXT         NOT NULL DEFAULT '',
+    "Ошибка"       TEXT         NOT NULL DEFAULT '',
+    CONSTRAINT "Запуск_задачи_Статус_check"
+        CHECK ("Статус" IN ('Выполняется','Успешно','Ошибка'))
+);
+CREATE INDEX IF NOT EXISTS idx_job_run_job ON "Запуск_задачи"("Задача", "Начало" DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "Запуск_задачи";
+UPDATE "Абонемент" SET "Статус" = 'Активен' WHERE "Статус" = 'Ожидает';
+ALTER TABLE "Абонемент" DROP CONSTRAINT IF EXISTS "Абонемент_Статус_check";
+ALTER TABLE "Абонемент" ADD CONSTRAINT "Абонемент_Статус_check"
+    CHECK ("Статус" IN ('Активен','Приостановлен','Завершен'));
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Цепочка продлений: новый абонемент ссылается на тот, который продлевает.
+-- Продлить абонемент можно один раз — дальше продлевают уже продление.
+ALTER TABLE "Абонемент" ADD COLUMN IF NOT EXISTS "id_предыдущего" INTEGER
+    REFERENCES "Абонемент"("id_абонемента") ON DELETE SET NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_subscription_renewal
+    ON "Абонемент"("id_предыдущего") WHERE "id_предыдущего" IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS ux_subscription_renewal;
+ALTER TABLE "Абонемент" DROP COLUMN IF EXISTS "id_предыдущего";
+-- +goose StatementEnd
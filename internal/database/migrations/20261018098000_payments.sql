@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Платежи клиентов: оплата (в т.ч. частичная) и возврат по абонементу или
+-- персональной тренировке. Сумма всегда положительная, знак задаёт "Вид".
+-- Записи не удаляются и не правятся: ошибку исправляют возвратом, поэтому
+-- абонемент или тренировку с платежами удалить нельзя (RESTRICT).
+CREATE TABLE IF NOT EXISTS "Платёж" (
+    "id_платежа"                  SERIAL        PRIMARY KEY,
+    "id_клиента"                  INTEGER       NOT NULL REFERENCES "Клиент"("id_клиента") ON DELETE RESTRICT,
+    "id_абонемента"               INTEGER       REFERENCES "Абонемент"("id_абонемента") ON DELETE RESTRICT,
+    "id_персональной_тренировки"  INTEGER       REFERENCES "Персональная_тренировка"("id_персональной_тренировки") ON DELETE RESTRICT,
+    "Вид"                         VARCHAR(10)   NOT NULL DEFAULT 'Оплата',
+    "Сумма"                       NUMERIC(10,2) NOT NULL,
+    "Способ"                      VARCHAR(10)   NOT NULL,
+    "Дата_время"                  TIMESTAMP     NOT NULL DEFAULT NOW(),
+    "id_сотрудника"               INTEGER       REFERENCES "Сотрудник"("id_сотрудника") ON DELETE SET NULL,
+    "Кассир"                      VARCHAR(50)   NOT NULL,
+    "Комментарий"                 VARCHAR(200)  NOT NULL DEFAULT '',
+    CONSTRAINT "Платёж_Вид_check"    CHECK ("Вид" IN ('Оплата','Возврат')),
+    CONSTRAINT "Платёж_Способ_check" CHECK ("Способ" IN ('Наличные','Карта','Перевод')),
+    CONSTRAINT "Платёж_Сумма_check"  CHECK ("Сумма" > 0),
+    CONSTRAINT "Платёж_предмет_check"
+        CHECK (num_nonnulls("id_абонемента", "id_персональной_тренировки") = 1)
+);
+CREATE INDEX IF NOT EXISTS idx_payment_subscription ON "Платёж"("id_абонемента") WHERE "id_абонемента" IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_payment_personal ON "Платёж"("id_персональной_тренировки") WHERE "id_персональной_тренировки" IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_payment_client ON "Платёж"("id_клиента");
+CREATE INDEX IF NOT EXISTS idx_payment_time ON "Платёж"("Дата_время");
+
+DROP TRIGGER IF EXISTS trg_audit ON "Платёж";
+CREATE TRIGGER trg_audit AFTER INSERT OR UPDATE OR DELETE ON "Платёж"
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('payment', 'id_платежа');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "Платёж";
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Лист ожидания: сверх "Максимум_участников" запись получает статус
+-- «В очереди» и место не занимает. Очередь — по времени записи.
+ALTER TABLE "Запись_на_групповую_тренировку"
+    DROP CONSTRAINT IF EXISTS "Запись_на_групповую_тренировку_Статус_check";
+ALTER TABLE "Запись_на_групповую_тренировку"
+    ADD CONSTRAINT "Запись_на_групповую_тренировку_Статус_check"
+    CHECK ("Статус" IN ('Записан','Посетил','Отменил','В очереди'));
+ALTER TABLE "Запись_на_групповую_тренировку"
+    ADD COLUMN IF NOT EXISTS "Записан_в" TIMESTAMP NOT NULL DEFAULT NOW();
+CREATE INDEX IF NOT EXISTS idx_enrollment_waitlist
+    ON "Запись_на_групповую_тренировку"("id_групповой_тренировки", "Записан_в", "id_записи")
+    WHERE "Статус" = 'В очереди';
+
+-- Уведомления клиентам (исходящие): пишет приложение, доставляет внешняя
+-- интеграция (SMS, мессенджер) или администратор, отмечая "Отправлено".
+CREATE TABLE IF NOT EXISTS "Уведомление" (
+    "id_уведомления" SERIAL       PRIMARY KEY,
+    "id_клиента"     INTEGER      NOT NULL REFERENCES "Клиент"("id_клиента") ON DELETE CASCADE,
+    "Тема"           VARCHAR(100) NOT NULL,
+    "Текст"          TEXT         NOT NULL,
+    "Создано"        TIMESTAMP    NOT NULL DEFAULT NOW(),
+    "Отправлено"     TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_notification_pending ON "Уведомление"("Создано") WHERE "Отправлено" IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notification_client ON "Уведомление"("id_клиента", "Создано");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "Уведомление";
+DROP INDEX IF EXISTS idx_enrollment_waitlist;
+ALTER TABLE "Запись_на_групповую_тренировку" DROP COLUMN IF EXISTS "Записан_в";
+UPDATE "Запись_на_групповую_тренировку" SET "Статус" = 'Отменил' WHERE "Статус" = 'В очереди';
+ALTER TABLE "Запись_на_групповую_тренировку"
+    DROP CONSTRAINT IF EXISTS "Запись_на_групповую_тренировку_Статус_check";
+ALTER TABLE "Запись_на_групповую_тренировку"
+    ADD CONSTRAINT "Запись_на_групповую_тренировку_Статус_check"
+    CHECK ("Статус" IN ('Записан','Посетил','Отменил'));
+-- +goose StatementEnd
@@ -0,0 +1,109 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Серии групповых тренировок: шаблон занятия + правило повторения (RRULE).
+-- Занятия серии — обычные строки "Групповая_тренировка" со ссылкой на серию
+-- и датой по правилу ("Дата_в_серии"); правка одного занятия ставит
+-- "Изменена", и правки всей серии его больше не трогают.
+CREATE TABLE IF NOT EXISTS "Серия_тренировок" (
+    "id_серии"             SERIAL       PRIMARY KEY,
+    "id_тренера"           INTEGER      NOT NULL REFERENCES "Тренер"("id_тренера"),
+    "id_зоны"              INTEGER      NOT NULL REFERENCES "Зона"("id_зоны"),
+    "Название"             VARCHAR(100) NOT NULL,
+    "Описание"             TEXT,
+    "Максимум_участников"  INTEGER      NOT NULL CHECK ("Максимум_участников" > 0),
+    "Уровень_сложности"    VARCHAR(20)  CHECK ("Уровень_сложности" IN ('Начальный','Средний','Продвинутый')),
+    "Начало"               TIMESTAMP    NOT NULL, -- первая дата и время начала занятий
+    "Длительность_мин"     INTEGER      NOT NULL CHECK ("Длительность_мин" > 0),
+    "Правило"              TEXT         NOT NULL, -- RRULE: FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20261231
+    "Создано"              TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+-- Исключения (EXDATE): в эту дату занятия серии нет — праздник, отмена.
+CREATE TABLE IF NOT EXISTS "Исключение_серии" (
+    "id_исключения"  SERIAL       PRIMARY KEY,
+    "id_серии"       INTEGER      NOT NULL REFERENCES "Серия_тренировок"("id_серии") ON DELETE CASCADE,
+    "Дата"           DATE         NOT NULL,
+    "Причина"        VARCHAR(200),
+    CONSTRAINT ux_series_exception UNIQUE ("id_серии", "Дата")
+);
+
+ALTER TABLE "Групповая_тренировка"
+    ADD COLUMN IF NOT EXISTS "id_серии" INTEGER REFERENCES "Серия_тренировок"("id_серии") ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS "Дата_в_серии" DATE,
+    ADD COLUMN IF NOT EXISTS "Изменена" BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_group_series_date
+    ON "Групповая_тренировка"("id_серии", "Дата_в_серии");
+
+-- новые колонки — в конец представления (CREATE OR REPLACE это позволяет)
+CREATE OR REPLACE VIEW public.vw_group_training_with_slots AS
+SELECT
+  g."id_групповой_тренировки",
+  g."id_тренера",
+  g."id_зоны",
+  g."Название",
+  g."Описание",
+  g."Максимум_участников",
+  g."Время_начала",
+  g."Время_окончания",
+  g."Уровень_сложности",
+  t."ФИО"                                   AS trainer_name,    -- lookup
+  z."Название"                              AS zone_name,       -- lookup
+  COALESCE(e.enrolled_count, 0)             AS enrolled_count,  -- вычисляемая
+  GREATEST(g."Максимум_участников" - COALESCE(e.enrolled_count, 0), 0) AS free_slots,
+  g."id_серии",
+  g."Дата_в_серии",
+  g."Изменена"
+FROM public."Групповая_тренировка" g
+JOIN public."Тренер" t ON t."id_тренера" = g."id_тренера"
+JOIN public."Зона"   z ON z."id_зоны"    = g."id_зоны"
+LEFT JOIN (
+  SELECT "id_групповой_тренировки", COUNT(*) AS enrolled_count
+  FROM public."Запись_на_групповую_тренировку"
+  WHERE "Статус" IN ('Записан','Посетил')
+  GROUP BY "id_групповой_тренировки"
+) e ON e."id_групповой_тренировки" = g."id_групповой_тренировки";
+
+DROP TRIGGER IF EXISTS trg_audit ON "Серия_тренировок";
+CREATE TRIGGER trg_audit AFTER INSERT OR UPDATE OR DELETE ON "Серия_тренировок"
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('training_series', 'id_серии');
+DROP TRIGGER IF EXISTS trg_audit ON "Исключение_серии";
+CREATE TRIGGER trg_audit AFTER INSERT OR UPDATE OR DELETE ON "Исключение_серии"
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('series_exception', 'id_исключения');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW IF EXISTS public.vw_group_training_with_slots;
+CREATE VIEW public.vw_group_training_with_slots AS
+SELECT
+  g."id_групповой_тренировки",
+  g."id_тренера",
+  g."id_зоны",
+  g."Название",
+  g."Описание",
+  g."Максимум_участников",
+  g."Время_начала",
+  g."Время_окончания",
+  g."Уровень_сложности",
+  t."ФИО"                                   AS trainer_name,
+  z."Название"                              AS zone_name,
+  COALESCE(e.enrolled_count, 0)             AS enrolled_count,
+  GREATEST(g."Максимум_участников" - COALESCE(e.enrolled_count, 0), 0) AS free_slots
+FROM public."Групповая_тренировка" g
+JOIN public."Тренер" t ON t."id_тренера" = g."id_тренера"
+JOIN public."Зона"   z ON z."id_зоны"    = g."id_зоны"
+LEFT JOIN (
+  SELECT "id_групповой_тренировки", COUNT(*) AS enrolled_count
+  FROM public."Запись_на_групповую_тренировку"
+  WHERE "Статус" IN ('Записан','Посетил')
+  GROUP BY "id_групповой_тренировки"
+) e ON e."id_групповой_тренировки" = g."id_групповой_тренировки";
+
+DROP INDEX IF EXISTS ux_group_series_date;
+ALTER TABLE "Групповая_тренировка"
+    DROP COLUMN IF EXISTS "Изменена",
+    DROP COLUMN IF EXISTS "Дата_в_серии",
+    DROP COLUMN IF EXISTS "id_серии";
+DROP TABLE IF EXISTS "Исключение_серии";
+DROP TABLE IF EXISTS "Серия_тренировок";
+-- +goose StatementEnd
@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Пересечения по расписанию: тренер не ведёт две тренировки сразу, в зоне
+-- не идут две групповые одновременно. Приложение проверяет оба вида
+-- тренировок вместе и отвечает 409 со списком; ограничения ниже страхуют
+-- от гонок внутри каждой таблицы. Администратор может сохранить
+-- пересечение осознанно — строка получает "Пересечение_разрешено".
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE "Групповая_тренировка"
+    ADD COLUMN IF NOT EXISTS "Пересечение_разрешено" BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE "Персональная_тренировка"
+    ADD COLUMN IF NOT EXISTS "Пересечение_разрешено" BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- уже имеющиеся пересечения считаем разрешёнными (помечаем более позднюю
+-- строку пары), иначе ограничения не создадутся
+UPDATE "Групповая_тренировка" g SET "Пересечение_разрешено" = TRUE
+WHERE EXISTS (
+    SELECT 1 FROM "Групповая_тренировка" o
+    WHERE o."id_групповой_тренировки" < g."id_групповой_тренировки"
+      AND (o."id_тренера" = g."id_тренера" OR o."id_зоны" = g."id_зоны")
+      AND o."Время_начала" < g."Время_окончания" AND o."Время_окончания" > g."Время_начала"
+);
+UPDATE "Персональная_тренировка" p SET "Пересечение_разрешено" = TRUE
+WHERE p."Статус" <> 'Отменена' AND EXISTS (
+    SELECT 1 FROM "Персональная_тренировка" o
+    WHERE o."id_персональной_тренировки" < p."id_персональной_тренировки"
+      AND o."Статус" <> 'Отменена'
+      AND o."id_тренера" = p."id_тренера"
+      AND o."Время_начала" < p."Время_окончания" AND o."Время_окончания" > p."Время_начала"
+);
+
+ALTER TABLE "Групповая_тренировка"
+    ADD CONSTRAINT ex_group_trainer_time EXCLUDE USING gist
+        ("id_тренера" WITH =, tsrange("Время_начала", "Время_окончания") WITH &&)
+        WHERE (NOT "Пересечение_разрешено"),
+    ADD CONSTRAINT ex_group_zone_time EXCLUDE USING gist
+        ("id_зоны" WITH =, tsrange("Время_начала", "Время_окончания") WITH &&)
+        WHERE (NOT "Пересечение_разрешено");
+ALTER TABLE "Персональная_тренировка"
+    ADD CONSTRAINT ex_personal_trainer_time EXCLUDE USING gist
+        ("id_тренера" WITH =, tsrange("Время_начала", "Время_окончания") WITH &&)
+        WHERE (NOT "Пересечение_разрешено" AND "Статус" <> 'Отменена');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "Персональная_тренировка" DROP CONSTRAINT IF EXISTS ex_personal_trainer_time;
+ALTER TABLE "Групповая_тренировка"
+    DROP CONSTRAINT IF EXISTS ex_group_zone_time,
+    DROP CONSTRAINT IF EXISTS ex_group_trainer_time;
+ALTER TABLE "Персональная_тренировка" DROP COLUMN IF EXISTS "Пересечение_разрешено";
+ALTER TABLE "Групповая_тренировка" DROP COLUMN IF EXISTS "Пересечение_разрешено";
+-- +goose StatementEnd
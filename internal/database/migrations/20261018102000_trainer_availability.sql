@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Рабочие часы тренера: окна по дням недели в формате часов доступа
+-- тарифа (см. internal/access). NULL — без ограничений, как было до сих пор.
+ALTER TABLE "Тренер" ADD COLUMN IF NOT EXISTS "Рабочие_часы" JSONB;
+
+-- Отсутствия тренера: отпуск, больничный, отгул — период
+-- [Дата_начала, Дата_окончания] включительно. В эти дни тренировки
+-- тренеру не назначаются.
+CREATE TABLE IF NOT EXISTS "Отсутствие_тренера" (
+    "id_отсутствия"   SERIAL       PRIMARY KEY,
+    "id_тренера"      INTEGER      NOT NULL REFERENCES "Тренер"("id_тренера") ON DELETE CASCADE,
+    "Тип"             VARCHAR(20)  NOT NULL CHECK ("Тип" IN ('Отпуск','Больничный','Отгул')),
+    "Дата_начала"     DATE         NOT NULL,
+    "Дата_окончания"  DATE         NOT NULL,
+    "Причина"         VARCHAR(200),
+    "Создано"         TIMESTAMP    NOT NULL DEFAULT NOW(),
+    CONSTRAINT "Отсутствие_период_check" CHECK ("Дата_окончания" >= "Дата_начала")
+);
+CREATE INDEX IF NOT EXISTS idx_trainer_absence ON "Отсутствие_тренера"("id_тренера", "Дата_начала");
+
+DROP TRIGGER IF EXISTS trg_audit ON "Отсутствие_тренера";
+CREATE TRIGGER trg_audit AFTER INSERT OR UPDATE OR DELETE ON "Отсутствие_тренера"
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('trainer_absence', 'id_отсутствия');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "Отсутствие_тренера";
+ALTER TABLE "Тренер" DROP COLUMN IF EXISTS "Рабочие_часы";
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Календарные ссылки (iCalendar): расписание тренера, зоны или клиента
+-- по секретному токену в URL. Как и для сессий, в БД только SHA-256
+-- от токена; у владельца одна действующая ссылка, новая заменяет старую.
+CREATE TABLE IF NOT EXISTS "Календарная_ссылка" (
+    "id_ссылки"         SERIAL      PRIMARY KEY,
+    "Тип"               VARCHAR(10) NOT NULL CHECK ("Тип" IN ('trainer','zone','client')),
+    "id_владельца"      INTEGER     NOT NULL,
+    "Токен_хеш"         CHAR(64)    NOT NULL UNIQUE,
+    "Создана"           TIMESTAMP   NOT NULL DEFAULT NOW(),
+    "Последний_доступ"  TIMESTAMP,
+    CONSTRAINT ux_calendar_owner UNIQUE ("Тип", "id_владельца")
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "Календарная_ссылка";
+-- +goose StatementEnd
@@ -0,0 +1,58 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Запись на групповую и персональную тренировку проверяется по абонементу:
+-- тариф включает этот вид тренировок, абонемент не завершён и не
+-- приостановлен, тренировка — в сроке действия. Администратор может
+-- записать клиента вопреки правилу — только с основанием, которое
+-- хранится в самой записи (пусто — исключения не было).
+ALTER TABLE "Запись_на_групповую_тренировку"
+    ADD COLUMN IF NOT EXISTS "Основание_исключения" VARCHAR(200);
+ALTER TABLE "Персональная_тренировка"
+    ADD COLUMN IF NOT EXISTS "Основание_исключения" VARCHAR(200);
+
+-- новое поле — в конец представления (CREATE OR REPLACE не меняет порядок)
+CREATE OR REPLACE VIEW public.vw_personal_training_enriched AS
+SELECT
+  p."id_персональной_тренировки",
+  p."id_абонемента",
+  p."id_тренера",
+  p."Время_начала",
+  p."Время_окончания",
+  p."Статус",
+  p."Стоимость",
+  a."id_клиента",
+  c."ФИО"                              AS client_fio,
+  tr."ФИО"                             AS trainer_fio,
+  EXTRACT(EPOCH FROM (p."Время_окончания" - p."Время_начала"))/60::int AS duration_minutes,
+  (p."Время_начала" >= NOW())          AS is_upcoming,
+  p."Основание_исключения"
+FROM public."Персональная_тренировка" p
+JOIN public."Абонемент" a ON a."id_абонемента" = p."id_абонемента"
+JOIN public."Клиент"    c ON c."id_клиента"    = a."id_клиента"
+JOIN public."Тренер"   tr ON tr."id_тренера"   = p."id_тренера";
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW IF EXISTS public.vw_personal_training_enriched;
+CREATE VIEW public.vw_personal_training_enriched AS
+SELECT
+  p."id_персональной_тренировки",
+  p."id_абонемента",
+  p."id_тренера",
+  p."Время_начала",
+  p."Время_окончания",
+  p."Статус",
+  p."Стоимость",
+  a."id_клиента",
+  c."ФИО"                              AS client_fio,
+  tr."ФИО"                             AS trainer_fio,
+  EXTRACT(EPOCH FROM (p."Время_окончания" - p."Время_начала"))/60::int AS duration_minutes,
+  (p."Время_начала" >= NOW())          AS is_upcoming
+FROM public."Персональная_тренировка" p
+JOIN public."Абонемент" a ON a."id_абонемента" = p."id_абонемента"
+JOIN public."Клиент"    c ON c."id_клиента"    = a."id_клиента"
+JOIN public."Тренер"   tr ON tr."id_тренера"   = p."id_тренера";
+ALTER TABLE "Персональная_тренировка" DROP COLUMN IF EXISTS "Основание_исключения";
+ALTER TABLE "Запись_на_групповую_тренировку" DROP COLUMN IF EXISTS "Основание_исключения";
+-- +goose StatementEnd
@@ -0,0 +1,76 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Ставки тренеров: базовая стоимость часа персональной тренировки,
+-- категория и надбавки (в процентах) за пиковые часы и выходные. Ставка
+-- действует с "Действует_с" до начала следующей ставки того же тренера;
+-- изменение — новая строка с новой датой, прежние остаются историей.
+CREATE TABLE IF NOT EXISTS "Ставка_тренера" (
+    "id_ставки"          SERIAL        PRIMARY KEY,
+    "id_тренера"         INTEGER       NOT NULL REFERENCES "Тренер"("id_тренера") ON DELETE CASCADE,
+    "Действует_с"        DATE          NOT NULL,
+    "Категория"          VARCHAR(50)   NOT NULL,
+    "Ставка_в_час"       NUMERIC(10,2) NOT NULL CHECK ("Ставка_в_час" >= 0),
+    "Пиковые_часы"       JSONB,        -- окна как у часов доступа тарифа; NULL — пиковых нет
+    "Надбавка_пик"       NUMERIC(5,2)  NOT NULL DEFAULT 0 CHECK ("Надбавка_пик" BETWEEN 0 AND 500),
+    "Надбавка_выходные"  NUMERIC(5,2)  NOT NULL DEFAULT 0 CHECK ("Надбавка_выходные" BETWEEN 0 AND 500),
+    "Создано"            TIMESTAMP     NOT NULL DEFAULT NOW(),
+    CONSTRAINT "Ставка_тренера_дата_uniq" UNIQUE ("id_тренера", "Действует_с")
+);
+
+DROP TRIGGER IF EXISTS trg_audit ON "Ставка_тренера";
+CREATE TRIGGER trg_audit AFTER INSERT OR UPDATE OR DELETE ON "Ставка_тренера"
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('trainer_rate', 'id_ставки');
+
+-- Расчёт стоимости персональной по ставке — снимок ставки на момент
+-- расчёта; NULL — стоимость введена вручную или ставки не было. Снимок не
+-- меняется при правке ставок, поэтому прошлые тренировки не пересчитываются.
+ALTER TABLE "Персональная_тренировка"
+    ADD COLUMN IF NOT EXISTS "Расчёт_стоимости" JSONB;
+
+CREATE OR REPLACE VIEW public.vw_personal_training_enriched AS
+SELECT
+  p."id_персональной_тренировки",
+  p."id_абонемента",
+  p."id_тренера",
+  p."Время_начала",
+  p."Время_окончания",
+  p."Статус",
+  p."Стоимость",
+  a."id_клиента",
+  c."ФИО"                              AS client_fio,
+  tr."ФИО"                             AS trainer_fio,
+  EXTRACT(EPOCH FROM (p."Время_окончания" - p."Время_начала"))/60::int AS duration_minutes,
+  (p."Время_начала" >= NOW())          AS is_upcoming,
+  p."Основание_исключения",
+  p."Расчёт_стоимости"
+FROM public."Персональная_тренировка" p
+JOIN public."Абонемент" a ON a."id_абонемента" = p."id_абонемента"
+JOIN public."Клиент"    c ON c."id_клиента"    = a."id_клиента"
+JOIN public."Тренер"   tr ON tr."id_тренера"   = p."id_тренера";
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW IF EXISTS public.vw_personal_training_enriched;
+CREATE VIEW public.vw_personal_training_enriched AS
+SELECT
+  p."id_персональной_тренировки",
+  p."id_абонемента",
+  p."id_тренера",
+  p."Время_начала",
+  p."Время_окончания",
+  p."Статус",
+  p."Стоимость",
+  a."id_клиента",
+  c."ФИО"                              AS client_fio,
+  tr."ФИО"                             AS trainer_fio,
+  EXTRACT(EPOCH FROM (p."Время_окончания" - p."Время_начала"))/60::int AS duration_minutes,
+  (p."Время_начала" >= NOW())          AS is_upcoming,
+  p."Основание_исключения"
+FROM public."Персональная_тренировка" p
+JOIN public."Абонемент" a ON a."id_абонемента" = p."id_абонемента"
+JOIN public."Клиент"    c ON c."id_клиента"    = a."id_клиента"
+JOIN public."Тренер"   tr ON tr."id_тренера"   = p."id_тренера";
+ALTER TABLE "Персональная_тренировка" DROP COLUMN IF EXISTS "Расчёт_стоимости";
+DROP TABLE IF EXISTS "Ставка_тренера";
+-- +goose StatementEnd
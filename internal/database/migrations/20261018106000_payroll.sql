@@ -0,0 +1,53 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Правила оплаты тренера: фиксированная сумма за проведённую групповую,
+-- процент от стоимости завершённой персональной и бонус за групповую,
+-- заполненную (по отметкам «Посетил») не меньше чем на "Порог_заполняемости" %.
+-- Нет строки — тренеру ничего не начисляется.
+CREATE TABLE IF NOT EXISTS "Правило_оплаты_тренера" (
+    "id_тренера"            INTEGER       PRIMARY KEY REFERENCES "Тренер"("id_тренера") ON DELETE CASCADE,
+    "За_групповую"          NUMERIC(10,2) NOT NULL DEFAULT 0 CHECK ("За_групповую" >= 0),
+    "Процент_персональной"  NUMERIC(5,2)  NOT NULL DEFAULT 0 CHECK ("Процент_персональной" BETWEEN 0 AND 100),
+    "Порог_заполняемости"   NUMERIC(5,2)  NOT NULL DEFAULT 0 CHECK ("Порог_заполняемости" BETWEEN 0 AND 100),
+    "Бонус_заполняемости"   NUMERIC(10,2) NOT NULL DEFAULT 0 CHECK ("Бонус_заполняемости" >= 0)
+);
+
+DROP TRIGGER IF EXISTS trg_audit ON "Правило_оплаты_тренера";
+CREATE TRIGGER trg_audit AFTER INSERT OR UPDATE OR DELETE ON "Правило_оплаты_тренера"
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('pay_rule', 'id_тренера');
+
+-- Закрытый расчётный период (месяц): начисления на момент закрытия
+-- сохраняются строками и дальше не пересчитываются, даже если тренировки
+-- месяца потом правят. Переоткрыть период — удалить строку периода.
+CREATE TABLE IF NOT EXISTS "Расчётный_период" (
+    "id_периода"  SERIAL     PRIMARY KEY,
+    "Месяц"       DATE       NOT NULL UNIQUE CHECK (EXTRACT(DAY FROM "Месяц") = 1),
+    "Закрыт"      TIMESTAMP  NOT NULL DEFAULT NOW()
+);
+
+DROP TRIGGER IF EXISTS trg_audit ON "Расчётный_период";
+CREATE TRIGGER trg_audit AFTER INSERT OR UPDATE OR DELETE ON "Расчётный_период"
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('payroll_period', 'id_периода');
+
+-- Строки ведомости закрытого периода. id_тренировки без внешнего ключа:
+-- строка остаётся, даже если тренировку потом удалят.
+CREATE TABLE IF NOT EXISTS "Начисление_тренеру" (
+    "id_начисления"   SERIAL        PRIMARY KEY,
+    "id_периода"      INTEGER       NOT NULL REFERENCES "Расчётный_период"("id_периода") ON DELETE CASCADE,
+    "id_тренера"      INTEGER       NOT NULL REFERENCES "Тренер"("id_тренера"),
+    "Вид"             VARCHAR(10)   NOT NULL CHECK ("Вид" IN ('group','personal','bonus')),
+    "id_тренировки"   INTEGER       NOT NULL,
+    "Время"           TIMESTAMP     NOT NULL,
+    "Описание"        VARCHAR(300)  NOT NULL,
+    "База"            NUMERIC(10,2) NOT NULL,
+    "Сумма"           NUMERIC(10,2) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_payroll_line ON "Начисление_тренеру"("id_периода", "id_тренера");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "Начисление_тренеру";
+DROP TABLE IF EXISTS "Расчётный_период";
+DROP TABLE IF EXISTS "Правило_оплаты_тренера";
+-- +goose StatementEnd
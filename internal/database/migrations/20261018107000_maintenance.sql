@@ -0,0 +1,73 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Тип оборудования (для планов ТО на весь тип) и наработка в моточасах —
+-- показание счётчика, которое вносят при ТО или правке оборудования.
+ALTER TABLE "Оборудование"
+    ADD COLUMN IF NOT EXISTS "Тип"             VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS "Наработка_часов" NUMERIC(10,1) NOT NULL DEFAULT 0 CHECK ("Наработка_часов" >= 0);
+
+-- «Требует ТО» — исправное оборудование с просроченным плановым ТО
+-- (ставит задача maintenance-overdue, снимает отметка о проведённом ТО).
+ALTER TABLE "Оборудование" DROP CONSTRAINT IF EXISTS "Оборудование_Статус_check";
+ALTER TABLE "Оборудование"
+    ADD CONSTRAINT "Оборудование_Статус_check"
+    CHECK ("Статус" IN ('Исправен','Требует ТО','На ремонте','Списан'));
+
+-- План ТО: на конкретную единицу или на все единицы типа. Интервал — в
+-- днях, в моточасах или в том и другом (что наступит раньше).
+CREATE TABLE IF NOT EXISTS "План_ТО" (
+    "id_плана"          SERIAL        PRIMARY KEY,
+    "Название"          VARCHAR(100)  NOT NULL,
+    "id_оборудования"   INTEGER       REFERENCES "Оборудование"("id_оборудования") ON DELETE CASCADE,
+    "Тип_оборудования"  VARCHAR(50),
+    "Интервал_дней"     INTEGER       CHECK ("Интервал_дней" > 0),
+    "Интервал_часов"    INTEGER       CHECK ("Интервал_часов" > 0),
+    "Чек_лист"          JSONB         NOT NULL DEFAULT '[]'::jsonb, -- ["пункт", ...]
+    "Создано"           TIMESTAMP     NOT NULL DEFAULT NOW(),
+    CONSTRAINT "План_ТО_цель_check"
+        CHECK (("id_оборудования" IS NULL) <> ("Тип_оборудования" IS NULL)),
+    CONSTRAINT "План_ТО_интервал_check"
+        CHECK ("Интервал_дней" IS NOT NULL OR "Интервал_часов" IS NOT NULL)
+);
+
+DROP TRIGGER IF EXISTS trg_audit ON "План_ТО";
+CREATE TRIGGER trg_audit AFTER INSERT OR UPDATE OR DELETE ON "План_ТО"
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('maintenance_plan', 'id_плана');
+
+-- История обслуживания. id_плана пустой — внеплановое ТО; удаление плана
+-- историю не трогает. "Выполнил" — ФИО на момент записи.
+CREATE TABLE IF NOT EXISTS "Обслуживание_оборудования" (
+    "id_обслуживания"   SERIAL        PRIMARY KEY,
+    "id_оборудования"   INTEGER       NOT NULL REFERENCES "Оборудование"("id_оборудования") ON DELETE CASCADE,
+    "id_плана"          INTEGER       REFERENCES "План_ТО"("id_плана") ON DELETE SET NULL,
+    "Дата"              DATE          NOT NULL,
+    "Наработка_часов"   NUMERIC(10,1) CHECK ("Наработка_часов" >= 0),
+    "Чек_лист"          JSONB         NOT NULL DEFAULT '[]'::jsonb, -- [{"пункт": "...", "выполнен": true}, ...]
+    "Комментарий"       TEXT,
+    "id_сотрудника"     INTEGER       REFERENCES "Сотрудник"("id_сотрудника") ON DELETE SET NULL,
+    "Выполнил"          VARCHAR(150)  NOT NULL,
+    "Создано"           TIMESTAMP     NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_maintenance_equipment
+    ON "Обслуживание_оборудования"("id_оборудования", "Дата" DESC);
+CREATE INDEX IF NOT EXISTS idx_maintenance_plan
+    ON "Обслуживание_оборудования"("id_плана", "id_оборудования", "Дата" DESC);
+
+DROP TRIGGER IF EXISTS trg_audit ON "Обслуживание_оборудования";
+CREATE TRIGGER trg_audit AFTER INSERT OR UPDATE OR DELETE ON "Обслуживание_оборудования"
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('maintenance', 'id_обслуживания');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "Обслуживание_оборудования";
+DROP TABLE IF EXISTS "План_ТО";
+UPDATE "Оборудование" SET "Статус" = 'Исправен' WHERE "Статус" = 'Требует ТО';
+ALTER TABLE "Оборудование" DROP CONSTRAINT IF EXISTS "Оборудование_Статус_check";
+ALTER TABLE "Оборудование"
+    ADD CONSTRAINT "Оборудование_Статус_check"
+    CHECK ("Статус" IN ('Исправен','На ремонте','Списан'));
+ALTER TABLE "Оборудование"
+    DROP COLUMN IF EXISTS "Наработка_часов",
+    DROP COLUMN IF EXISTS "Тип";
+-- +goose StatementEnd
@@ -0,0 +1,53 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Исполнитель заявки (техник или администратор), срок устранения по SLA
+-- приоритета и время закрытия. Срок пересчитывается от "Дата_создания"
+-- при смене приоритета; "Дата_закрытия" сбрасывается при переоткрытии.
+ALTER TABLE "Заявка_на_ремонт"
+    ADD COLUMN IF NOT EXISTS "id_исполнителя"   INTEGER   REFERENCES "Сотрудник"("id_сотрудника") ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS "Срок_устранения"  TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS "Дата_закрытия"    TIMESTAMP;
+
+-- Прежним заявкам — срок по SLA по умолчанию (24/72/168 ч).
+UPDATE "Заявка_на_ремонт"
+SET "Срок_устранения" = "Дата_создания" + CASE "Приоритет"
+        WHEN 'Высокий' THEN INTERVAL '24 hours'
+        WHEN 'Средний' THEN INTERVAL '72 hours'
+        ELSE INTERVAL '168 hours'
+    END
+WHERE "Срок_устранения" IS NULL;
+ALTER TABLE "Заявка_на_ремонт" ALTER COLUMN "Срок_устранения" SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_repair_assignee ON "Заявка_на_ремонт"("id_исполнителя") WHERE "Статус" <> 'Закрыта';
+
+-- История заявки: каждая смена статуса (и комментарий без смены) с
+-- временем и сотрудником. ФИО хранится строкой — запись остаётся
+-- читаемой после удаления учётки.
+CREATE TABLE IF NOT EXISTS "История_заявки" (
+    "id_записи"      SERIAL        PRIMARY KEY,
+    "id_заявки"      INTEGER       NOT NULL REFERENCES "Заявка_на_ремонт"("id_заявки") ON DELETE CASCADE,
+    "Время"          TIMESTAMP     NOT NULL DEFAULT NOW(),
+    "Статус_до"      VARCHAR(20),  -- NULL — создание заявки
+    "Статус"         VARCHAR(20)   NOT NULL,
+    "Комментарий"    TEXT          NOT NULL DEFAULT '',
+    "id_сотрудника"  INTEGER       REFERENCES "Сотрудник"("id_сотрудника") ON DELETE SET NULL,
+    "Сотрудник"      VARCHAR(150)  NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_repair_history ON "История_заявки"("id_заявки", "Время");
+
+-- Прежним заявкам — запись о создании с текущим статусом.
+INSERT INTO "История_заявки" ("id_заявки","Время","Статус","Комментарий","Сотрудник")
+SELECT r."id_заявки", r."Дата_создания", r."Статус", 'Заявка создана до ведения истории', '—'
+FROM "Заявка_на_ремонт" r
+WHERE NOT EXISTS (SELECT 1 FROM "История_заявки" h WHERE h."id_заявки" = r."id_заявки");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "История_заявки";
+DROP INDEX IF EXISTS idx_repair_assignee;
+ALTER TABLE "Заявка_на_ремонт"
+    DROP COLUMN IF EXISTS "Дата_закрытия",
+    DROP COLUMN IF EXISTS "Срок_устранения",
+    DROP COLUMN IF EXISTS "id_исполнителя";
+-- +goose StatementEnd
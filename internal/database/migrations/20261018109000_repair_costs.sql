@@ -0,0 +1,72 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Подрядчики: сервисные организации и поставщики запчастей.
+CREATE TABLE IF NOT EXISTS "Подрядчик" (
+    "id_подрядчика"  SERIAL        PRIMARY KEY,
+    "Название"       VARCHAR(150)  NOT NULL,
+    "Контакт"        VARCHAR(150),
+    "Телефон"        VARCHAR(30),
+    "Email"          VARCHAR(100),
+    "Примечание"     TEXT
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_vendor_name ON "Подрядчик"(LOWER("Название"));
+
+DROP TRIGGER IF EXISTS trg_audit ON "Подрядчик";
+CREATE TRIGGER trg_audit AFTER INSERT OR UPDATE OR DELETE ON "Подрядчик"
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('vendor', 'id_подрядчика');
+
+-- Затраты по заявке: кто чинил и стоимость работ. Запчасти — отдельно,
+-- строками расхода со склада.
+ALTER TABLE "Заявка_на_ремонт"
+    ADD COLUMN IF NOT EXISTS "id_подрядчика"    INTEGER       REFERENCES "Подрядчик"("id_подрядчика") ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS "Стоимость_работ"  NUMERIC(10,2) NOT NULL DEFAULT 0 CHECK ("Стоимость_работ" >= 0);
+
+-- Стоимость замены единицы новой — с ней сравниваются затраты на ремонт.
+ALTER TABLE "Оборудование"
+    ADD COLUMN IF NOT EXISTS "Стоимость_замены" NUMERIC(10,2) CHECK ("Стоимость_замены" >= 0);
+
+-- Склад запчастей: остаток, порог заказа и текущая цена единицы.
+CREATE TABLE IF NOT EXISTS "Запчасть" (
+    "id_запчасти"    SERIAL        PRIMARY KEY,
+    "Название"       VARCHAR(150)  NOT NULL,
+    "Артикул"        VARCHAR(50),
+    "Единица"        VARCHAR(10)   NOT NULL DEFAULT 'шт',
+    "Остаток"        NUMERIC(10,2) NOT NULL DEFAULT 0 CHECK ("Остаток" >= 0),
+    "Порог_заказа"   NUMERIC(10,2) NOT NULL DEFAULT 0 CHECK ("Порог_заказа" >= 0),
+    "Цена"           NUMERIC(10,2) NOT NULL DEFAULT 0 CHECK ("Цена" >= 0),
+    "id_подрядчика"  INTEGER       REFERENCES "Подрядчик"("id_подрядчика") ON DELETE SET NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_part_sku ON "Запчасть"(LOWER("Артикул")) WHERE "Артикул" IS NOT NULL;
+
+DROP TRIGGER IF EXISTS trg_audit ON "Запчасть";
+CREATE TRIGGER trg_audit AFTER INSERT OR UPDATE OR DELETE ON "Запчасть"
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('spare_part', 'id_запчасти');
+
+-- Расход запчасти на заявку: цена фиксируется на момент списания. Пока
+-- есть расход, запчасть удалить нельзя.
+CREATE TABLE IF NOT EXISTS "Расход_запчасти" (
+    "id_расхода"   SERIAL        PRIMARY KEY,
+    "id_заявки"    INTEGER       NOT NULL REFERENCES "Заявка_на_ремонт"("id_заявки") ON DELETE CASCADE,
+    "id_запчасти"  INTEGER       NOT NULL REFERENCES "Запчасть"("id_запчасти"),
+    "Количество"   NUMERIC(10,2) NOT NULL CHECK ("Количество" > 0),
+    "Цена"         NUMERIC(10,2) NOT NULL CHECK ("Цена" >= 0),
+    "Дата"         TIMESTAMP     NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_part_usage_repair ON "Расход_запчасти"("id_заявки");
+CREATE INDEX IF NOT EXISTS idx_part_usage_part ON "Расход_запчасти"("id_запчасти");
+
+DROP TRIGGER IF EXISTS trg_audit ON "Расход_запчасти";
+CREATE TRIGGER trg_audit AFTER INSERT OR UPDATE OR DELETE ON "Расход_запчасти"
+    FOR EACH ROW EXECUTE FUNCTION audit_row_change('part_usage', 'id_расхода');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "Расход_запчасти";
+DROP TABLE IF EXISTS "Запчасть";
+ALTER TABLE "Оборудование" DROP COLUMN IF EXISTS "Стоимость_замены";
+ALTER TABLE "Заявка_на_ремонт"
+    DROP COLUMN IF EXISTS "Стоимость_работ",
+    DROP COLUMN IF EXISTS "id_подрядчика";
+DROP TABLE IF EXISTS "Подрядчик";
+-- +goose StatementEnd
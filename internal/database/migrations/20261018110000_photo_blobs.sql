@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Фото переезжают из БД в хранилище файлов (internal/blob): в записи
+-- остаётся только ключ — SHA-256 содержимого в hex. Прежний "Фото" (bytea)
+-- читается, пока его не перенесёт команда `server photos migrate`, и будет
+-- удалён в одной из следующих версий.
+ALTER TABLE "Зона"             ADD COLUMN IF NOT EXISTS "Фото_ключ" VARCHAR(64);
+ALTER TABLE "Оборудование"     ADD COLUMN IF NOT EXISTS "Фото_ключ" VARCHAR(64);
+ALTER TABLE "Заявка_на_ремонт" ADD COLUMN IF NOT EXISTS "Фото_ключ" VARCHAR(64);
+
+-- перед удалением файла проверяется, не ссылается ли на него другая запись
+CREATE INDEX IF NOT EXISTS idx_zone_photo_key      ON "Зона"("Фото_ключ")             WHERE "Фото_ключ" IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_equipment_photo_key ON "Оборудование"("Фото_ключ")     WHERE "Фото_ключ" IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_repair_photo_key    ON "Заявка_на_ремонт"("Фото_ключ") WHERE "Фото_ключ" IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Фото, уже перенесённые в хранилище, в БД не возвращаются: после отката
+-- они пропадут из карточек (файлы в хранилище остаются).
+ALTER TABLE "Заявка_на_ремонт" DROP COLUMN IF EXISTS "Фото_ключ";
+ALTER TABLE "Оборудование"     DROP COLUMN IF EXISTS "Фото_ключ";
+ALTER TABLE "Зона"             DROP COLUMN IF EXISTS "Фото_ключ";
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+// Package migrations — SQL-миграции схемы в формате goose, вшитые в бинарник.
+//
+// Имя файла: <версия>_<описание>.sql, версия — метка времени YYYYMMDDhhmmss.
+// Применяет их database.MigrateUp (команда `server migrate up` или флаг -auto-migrate).
+//
+// Преобразования данных, которые проще написать на Go, регистрируются
+// через register в файле <версия>_<описание>.go (как goose.AddMigration)
+// и выполняются в общем порядке версий, в своей транзакции.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+)
+
+// FS — все *.sql из этого каталога.
+//
+//go:embed *.sql
+var FS embed.FS
+
+// GoMigration — миграция на Go. Down может быть nil — откат ничего не делает.
+type GoMigration struct {
+	Version  int64
+	Name     string
+	Up, Down func(ctx context.Context, tx *sql.Tx) error
+}
+
+// Go — зарегистрированные Go-миграции (порядок не важен).
+var Go []GoMigration
+
+func register(version int64, name string, up, down func(ctx context.Context, tx *sql.Tx) error) {
+	Go = append(Go, GoMigration{Version: version, Name: name, Up: up, Down: down})
+}
@@ -0,0 +1,117 @@
+// Package enrollment — правила записи на групповую тренировку: статусы,
+// вместимость и лист ожидания.
+//
+// Место в группе занимают записи «Записан» и «Посетил». Когда мест нет,
+// новая запись встаёт в очередь («В очереди»); при отмене занятого места
+// первую по очереди запись переводят в «Записан» и уведомляют клиента.
+//
+// Смена статуса проверяется по таблице переходов (Transition); отметить
+// посещение можно только после начала тренировки.
+package enrollment
+
+import (
+	"fmt"
+	"time"
+)
+
+// Статусы записи ("Запись_на_групповую_тренировку"."Статус").
+const (
+	StatusBooked    = "Записан"
+	StatusAttended  = "Посетил"
+	StatusCancelled = "Отменил"
+	StatusWaiting   = "В очереди"
+)
+
+// HoldsSeat — занимает ли запись со статусом status место в группе.
+func HoldsSeat(status string) bool {
+	return status == StatusBooked || status == StatusAttended
+}
+
+// Occupancy — вместимость тренировки и сколько мест занято.
+type Occupancy struct {
+	Max     int `json:"max"`
+	Taken   int `json:"taken"`
+	Waiting int `json:"waiting"`
+}
+
+// Free — свободных мест.
+func (o Occupancy) Free() int { return max(o.Max-o.Taken, 0) }
+
+// Place — статус новой записи: «Записан», пока есть места, иначе «В очереди».
+func Place(o Occupancy) string {
+	if o.Free() > 0 {
+		return StatusBooked
+	}
+	return StatusWaiting
+}
+
+// Reason — код нарушения правила (уходит в JSON как есть).
+type Reason string
+
+const (
+	BadTransition Reason = "invalid_transition" // переход не из таблицы
+	NotStarted    Reason = "not_started"        // посещение до начала тренировки
+	Full          Reason = "full"               // мест нет, в обход очереди нельзя
+)
+
+// Error — нарушение правила записи; Error() — текст для сотрудника.
+type Error struct {
+	Reason Reason
+	Msg    string
+}
+
+func (e *Error) Error() string { return e.Msg }
+
+func reject(r Reason, format string, args ...any) error {
+	return &Error{Reason: r, Msg: fmt.Sprintf(format, args...)}
+}
+
+// transitions — допустимые смены статуса записи.
+var transitions = map[string][]string{
+	StatusBooked:    {StatusAttended, StatusCancelled},
+	StatusWaiting:   {StatusBooked, StatusCancelled},
+	StatusAttended:  {StatusBooked}, // снять ошибочную отметку
+	StatusCancelled: {StatusBooked}, // вернуть запись: на место или в очередь
+}
+
+// CanTransition — есть ли переход from → to в таблице.
+func CanTransition(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckAttendance — можно ли отметить посещение тренировки, начинающейся
+// в start: не раньше её начала.
+func CheckAttendance(start, now time.Time) error {
+	if now.Before(start) {
+		return reject(NotStarted, "Тренировка начнётся %s — отметить посещение пока нельзя",
+			start.Format("02.01.2006 15:04"))
+	}
+	return nil
+}
+
+// Transition проверяет смену статуса from → to для тренировки, начинающейся
+// в start, и возвращает итоговый статус. Отменённая запись, возвращаемая
+// в группу без свободных мест, встаёт в конец очереди; перевести запись из
+// очереди в «Записан» без свободного места нельзя.
+func Transition(from, to string, start, now time.Time, o Occupancy) (string, error) {
+	if !CanTransition(from, to) {
+		return "", reject(BadTransition, "Нельзя сменить статус «%s» на «%s»", from, to)
+	}
+	if to == StatusAttended {
+		if err := CheckAttendance(start, now); err != nil {
+			return "", err
+		}
+	}
+	if to == StatusBooked && !HoldsSeat(from) && o.Free() == 0 {
+		if from == StatusCancelled {
+			return StatusWaiting, nil
+		}
+		return "", reject(Full, "Мест нет: занято %d из %d", o.Taken, o.Max)
+	}
+	return to, nil
+}
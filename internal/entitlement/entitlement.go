@@ -0,0 +1,85 @@
+// Package entitlement — даёт ли абонемент право на тренировку: тариф
+// включает этот вид тренировок, абонемент не завершён и не приостановлен,
+// день тренировки — в сроке действия, сама тренировка — в часах доступа
+// тарифа и не в заморозке.
+//
+// Решение не зависит от БД и HTTP: хэндлер загружает абонемент, тариф
+// и заморозки через store и по Reason формирует ответ. Обойти отказ может
+// администратор, указав основание — оно сохраняется вместе с записью.
+package entitlement
+
+import (
+	"fmt"
+	"time"
+
+	"fitness-center-manager/internal/checkin"
+	"fitness-center-manager/internal/freeze"
+	"fitness-center-manager/internal/models"
+)
+
+// Kind — вид тренировки.
+type Kind int
+
+const (
+	Group Kind = iota
+	Personal
+)
+
+// Reason — код отказа (уходит в JSON как есть).
+type Reason string
+
+const (
+	NoGroup      Reason = "tariff_no_group_trainings"    // тариф без групповых тренировок
+	NoPersonal   Reason = "tariff_no_personal_trainings" // тариф без персональных тренировок
+	Finished     Reason = "subscription_finished"        // абонемент завершён
+	Suspended    Reason = "subscription_suspended"       // абонемент приостановлен вручную
+	NotStarted   Reason = "subscription_not_started"     // тренировка раньше начала абонемента
+	Expired      Reason = "subscription_expired"         // тренировка после окончания абонемента
+	Frozen              = Reason(checkin.Frozen)         // тренировка попадает в заморозку
+	OutsideHours        = Reason(checkin.OutsideHours)   // тренировка вне часов доступа тарифа
+)
+
+// Error — отказ; Error() — текст для сотрудника.
+type Error struct {
+	Reason Reason
+	Msg    string
+}
+
+func (e *Error) Error() string { return e.Msg }
+
+func reject(r Reason, format string, args ...any) error {
+	return &Error{Reason: r, Msg: fmt.Sprintf(format, args...)}
+}
+
+const dateFormat = "02.01.2006"
+
+// Check — можно ли по абонементу sub с тарифом tariff и заморозками freezes
+// записаться на тренировку вида kind в [start, end). Ошибка — *Error.
+func Check(sub models.Subscription, tariff models.Tariff, freezes []models.Freeze, kind Kind, start, end time.Time) error {
+	switch {
+	case kind == Group && !tariff.HasGroupTrainings:
+		return reject(NoGroup, "Тариф «%s» не включает групповые тренировки", tariff.Name)
+	case kind == Personal && !tariff.HasPersonalTrainings:
+		return reject(NoPersonal, "Тариф «%s» не включает персональные тренировки", tariff.Name)
+	}
+	switch sub.Status {
+	case checkin.StatusFinished:
+		return reject(Finished, "Абонемент завершён")
+	case checkin.StatusFrozen:
+		return reject(Suspended, "Абонемент приостановлен")
+	}
+	day := checkin.Day(start)
+	if day.Before(sub.StartDate) {
+		return reject(NotStarted, "Абонемент действует с %s — тренировка раньше", sub.StartDate.Format(dateFormat))
+	}
+	if day.After(sub.EndDate) {
+		return reject(Expired, "Абонемент действовал по %s — тренировка позже", sub.EndDate.Format(dateFormat))
+	}
+	if f, ok := freeze.Covers(freezes, start); ok {
+		return reject(Frozen, "Абонемент заморожен с %s по %s", f.StartDate.Format(dateFormat), f.EndDate.Format(dateFormat))
+	}
+	if !tariff.AccessHours.AllowsRange(start, end) {
+		return reject(OutsideHours, "Тренировка вне часов доступа по тарифу (%s)", tariff.AccessHours)
+	}
+	return nil
+}
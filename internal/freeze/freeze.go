@@ -0,0 +1,129 @@
+// Package freeze — правила заморозки (паузы) абонемента: лимиты тарифа
+// и проверка нового периода заморозки.
+//
+// Заморозка — период [начало, окончание] включительно. Пока она идёт,
+// вход и запись на групповые закрыты, а дата окончания абонемента
+// сдвигается на её длительность (это делает store). Лимиты задаёт тариф:
+// сколько раз и сколько дней суммарно можно заморозить один абонемент.
+package freeze
+
+import (
+	"fmt"
+	"time"
+
+	"fitness-center-manager/internal/checkin"
+	"fitness-center-manager/internal/models"
+)
+
+// Reason — код нарушения правила (уходит в JSON как есть).
+type Reason string
+
+const (
+	Finished      Reason = "subscription_finished" // абонемент завершён
+	Renewed       Reason = "already_renewed"       // продление начинается сразу после окончания
+	InPast        Reason = "in_past"               // заморозка задним числом
+	OutsidePeriod Reason = "outside_period"        // начало вне срока абонемента
+	Overlap       Reason = "overlap"               // пересекается с другой заморозкой
+	CountLimit    Reason = "count_limit"           // исчерпано число заморозок
+	DaysLimit     Reason = "days_limit"            // исчерпан лимит дней
+)
+
+// Error — нарушение правила заморозки; Error() — текст для сотрудника.
+type Error struct {
+	Reason Reason
+	Msg    string
+}
+
+func (e *Error) Error() string { return e.Msg }
+
+func reject(r Reason, format string, args ...any) error {
+	return &Error{Reason: r, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Allowance — лимиты тарифа и сколько из них уже использовано.
+type Allowance struct {
+	MaxDays   int `json:"max_days"`
+	MaxCount  int `json:"max_count"`
+	UsedDays  int `json:"used_days"`
+	UsedCount int `json:"used_count"`
+}
+
+// AllowanceOf считает использованный лимит по заморозкам абонемента.
+func AllowanceOf(t models.Tariff, list []models.Freeze) Allowance {
+	a := Allowance{MaxDays: t.FreezeMaxDays, MaxCount: t.FreezeMaxCount, UsedCount: len(list)}
+	for _, f := range list {
+		a.UsedDays += f.Days()
+	}
+	return a
+}
+
+// RemainingDays — сколько дней ещё можно заморозить.
+func (a Allowance) RemainingDays() int { return max(a.MaxDays-a.UsedDays, 0) }
+
+// RemainingCount — сколько заморозок ещё можно оформить.
+func (a Allowance) RemainingCount() int { return max(a.MaxCount-a.UsedCount, 0) }
+
+// Check проверяет новую заморозку [start, end] абонемента sub на день
+// today. existing — уже оформленные заморозки этого абонемента, по ним же
+// считается остаток лимита tariff. Ошибка — *Error.
+func Check(sub models.Subscription, tariff models.Tariff, existing []models.Freeze, start, end, today time.Time) error {
+	start, end, today = day(start), day(end), day(today)
+	switch {
+	case sub.Status == checkin.StatusFinished:
+		return reject(Finished, "Абонемент завершён — заморозить нельзя")
+	case sub.RenewalID.Valid:
+		// сдвиг окончания наложил бы абонемент на период продления
+		return reject(Renewed, "Абонемент уже продлён (№%d) — заморозить нельзя, заморозьте продление", sub.RenewalID.Int64)
+	case start.Before(today):
+		return reject(InPast, "Заморозка не может начинаться в прошлом")
+	case start.Before(day(sub.StartDate)) || start.After(day(sub.EndDate)):
+		return reject(OutsidePeriod, "Заморозка должна начинаться в срок абонемента (%s — %s)",
+			sub.StartDate.Format("02.01.2006"), sub.EndDate.Format("02.01.2006"))
+	}
+	for _, f := range existing {
+		if !start.After(f.EndDate) && !end.Before(f.StartDate) {
+			return reject(Overlap, "Пересекается с заморозкой %s — %s",
+				f.StartDate.Format("02.01.2006"), f.EndDate.Format("02.01.2006"))
+		}
+	}
+
+	a := AllowanceOf(tariff, existing)
+	days := models.Freeze{StartDate: start, EndDate: end}.Days()
+	switch {
+	case a.RemainingCount() == 0:
+		return reject(CountLimit, "Тариф допускает не больше %d заморозок, все использованы", a.MaxCount)
+	case days > a.RemainingDays():
+		return reject(DaysLimit, "Заморозка на %d дн. превышает остаток лимита: %d из %d дн.",
+			days, a.RemainingDays(), a.MaxDays)
+	}
+	return nil
+}
+
+// EndEarly — новая дата окончания заморозки f, если клиент вернулся
+// в день today: накануне. ok=false — заморозка ещё не началась, её
+// нужно удалить целиком; уже закончившуюся не трогаем (end == f.EndDate).
+func EndEarly(f models.Freeze, today time.Time) (end time.Time, ok bool) {
+	today = day(today)
+	if !f.StartDate.Before(today) {
+		return time.Time{}, false
+	}
+	if yesterday := today.AddDate(0, 0, -1); yesterday.Before(f.EndDate) {
+		return yesterday, true
+	}
+	return f.EndDate, true
+}
+
+// Covers — есть ли среди заморозок та, что покрывает день t.
+func Covers(list []models.Freeze, t time.Time) (models.Freeze, bool) {
+	for _, f := range list {
+		if f.Covers(t) {
+			return f, true
+		}
+	}
+	return models.Freeze{}, false
+}
+
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -1,13 +1,15 @@
 package handlers
 
 import (
+    "context"
+    "errors"
     "io"
     "net/http"
     "strconv"
     "strings"
     "time"
     "fmt"
-    "fitness-center-manager/internal/database"
+    "fitness-center-manager/internal/store"
     "github.com/gofiber/fiber/v2"
 )
 
@@ -45,36 +47,12 @@ func ReportClientsAfterDate(c *fiber.Ctx) error {
         return jsonError(c, 400, "Неверный формат даты", err)
     }
 
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    rows, err := db.QueryContext(ctx, `
-        SELECT "id_клиента", "ФИО", "Дата_регистрации"
-        FROM "Клиент"
-        WHERE "Дата_регистрации" > $1
-        ORDER BY "Дата_регистрации" DESC
-        LIMIT 100
-    `, dt)
+    out, err := data.Reports().ClientsRegisteredAfter(ctx, dt)
     if err != nil {
         return jsonError(c, 500, "DB: ошибка выборки клиентов", err)
     }
-    defer rows.Close()
-    type rowT struct{
-        ID int `json:"id_клиента"`
-        FIO string `json:"фио"`
-        Reg time.Time `json:"дата_регистрации"`
-    }
-    var out []rowT
-    for rows.Next() {
-        var r rowT
-        if err := rows.Scan(&r.ID, &r.FIO, &r.Reg); err != nil {
-            return jsonError(c, 500, "Ошибка чтения строки", err)
-        }
-        out = append(out, r)
-    }
-    if err := rows.Err(); err != nil {
-        return jsonError(c, 500, "Ошибка курсора", err)
-    }
     return jsonOK(c, fiber.Map{"rows": out})
 }
 
@@ -89,48 +67,12 @@ func ReportSubscriptionsByStatus(c *fiber.Ctx) error {
         return jsonError(c, 400, "Статус слишком длинный", nil)
     }
 
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    rows, err := db.QueryContext(ctx, `
-        SELECT s."id_абонемента",
-               c."ФИО"              AS client_name,
-               t."Название_тарифа"  AS tariff_name,
-               s."Дата_начала",
-               s."Дата_окончания",
-               s."Статус",
-               COALESCE(s."Цена", 0) AS price
-        FROM "Абонемент" s
-        JOIN "Клиент" c ON c."id_клиента" = s."id_клиента"
-        JOIN "Тариф"  t ON t."id_тарифа"  = s."id_тарифа"
-        WHERE s."Статус" = $1
-        ORDER BY s."id_абонемента" DESC
-        LIMIT 200
-    `, status)
+    out, err := data.Reports().SubscriptionsByStatus(ctx, status)
     if err != nil {
         return jsonError(c, 500, "DB: ошибка выборки абонементов", err)
     }
-    defer rows.Close()
-    type rowT struct{
-        ID int `json:"id_абонемента"`
-        Client string `json:"фио_клиента"`
-        Tariff string `json:"название_тарифа"`
-        Start time.Time `json:"дата_начала"`
-        End time.Time `json:"дата_окончания"`
-        Status string `json:"статус"`
-        Price float64 `json:"цена"`
-    }
-    var out []rowT
-    for rows.Next() {
-        var r rowT
-        if err := rows.Scan(&r.ID, &r.Client, &r.Tariff, &r.Start, &r.End, &r.Status, &r.Price); err != nil {
-            return jsonError(c, 500, "Ошибка чтения строки", err)
-        }
-        out = append(out, r)
-    }
-    if err := rows.Err(); err != nil {
-        return jsonError(c, 500, "Ошибка курсора", err)
-    }
     return jsonOK(c, fiber.Map{"rows": out})
 }
 
@@ -160,41 +102,12 @@ func ReportRevenueByTariff(c *fiber.Ctx) error {
         }
     }
 
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    rows, err := db.QueryContext(ctx, `
-        SELECT
-            t."Название_тарифа"                AS tariff,
-            COUNT(s.*)                          AS subs_count,
-            SUM(COALESCE(s."Цена", 0))         AS revenue
-        FROM "Абонемент" s
-        JOIN "Тариф" t ON t."id_тарифа" = s."id_тарифа"
-        WHERE s."Дата_начала" >= $1 AND s."Дата_окончания" <= $2
-        GROUP BY t."Название_тарифа"
-        HAVING SUM(COALESCE(s."Цена", 0)) >= $3
-        ORDER BY revenue DESC
-    `, start, end, minRev)
+    out, err := data.Reports().RevenueByTariff(ctx, start, end, minRev)
     if err != nil {
         return jsonError(c, 500, "DB: ошибка выборки выручки", err)
     }
-    defer rows.Close()
-    type rowT struct{
-        Tariff string `json:"тариф"`
-        Count int `json:"количество"`
-        Revenue float64 `json:"выручка"`
-    }
-    var out []rowT
-    for rows.Next() {
-        var r rowT
-        if err := rows.Scan(&r.Tariff, &r.Count, &r.Revenue); err != nil {
-            return jsonError(c, 500, "Ошибка чтения строки", err)
-        }
-        out = append(out, r)
-    }
-    if err := rows.Err(); err != nil {
-        return jsonError(c, 500, "Ошибка курсора", err)
-    }
     return jsonOK(c, fiber.Map{"rows": out})
 }
 
@@ -208,92 +121,28 @@ func ReportZonesWithMinEquipment(c *fiber.Ctx) error {
         return jsonError(c, 400, "min_count должен быть целым >= 0", err)
     }
 
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    rows, err := db.QueryContext(ctx, `
-        SELECT z."id_зоны", z."Название",
-               (SELECT COUNT(*) FROM "Оборудование" e WHERE e."id_зоны" = z."id_зоны") AS equip_count
-        FROM "Зона" z
-        WHERE (SELECT COUNT(*) FROM "Оборудование" e WHERE e."id_зоны" = z."id_зоны") >= $1
-        ORDER BY equip_count DESC, z."id_зоны" DESC
-    `, minN)
+    out, err := data.Reports().ZonesWithEquipment(ctx, minN)
     if err != nil {
         return jsonError(c, 500, "DB: ошибка выборки зон", err)
     }
-    defer rows.Close()
-    type rowT struct{
-        ID int `json:"id_зоны"`
-        Name string `json:"название"`
-        Count int `json:"количество_оборудования"`
-    }
-    var out []rowT
-    for rows.Next() {
-        var r rowT
-        if err := rows.Scan(&r.ID, &r.Name, &r.Count); err != nil {
-            return jsonError(c, 500, "Ошибка чтения строки", err)
-        }
-        out = append(out, r)
-    }
-    if err := rows.Err(); err != nil {
-        return jsonError(c, 500, "Ошибка курсора", err)
-    }
     return jsonOK(c, fiber.Map{"rows": out})
 }
 
 // POST /about/query/zones-above-avg-capacity
 // Некоррелированный подзапрос: зоны с вместимостью выше средней по клубу
 func ReportZonesAboveAvgCapacity(c *fiber.Ctx) error {
-	db := database.GetDB()
 	ctx, cancel := withDBTimeout()
 	defer cancel()
-
-	rows, err := db.QueryContext(ctx, `
-        WITH avg_capacity AS (
-            SELECT COALESCE(AVG("Вместимость")::float8, 0) AS avg_capacity
-            FROM "Зона"
-        )
-        SELECT z."id_зоны", z."Название", z."Вместимость", avg_capacity.avg_capacity
-        FROM "Зона" z
-        CROSS JOIN avg_capacity
-        WHERE z."Вместимость" > avg_capacity.avg_capacity
-        ORDER BY z."Вместимость" DESC, z."id_зоны" DESC
-    `)
+	out, avg, err := data.Reports().ZonesAboveAvgCapacity(ctx)
 	if err != nil {
 		return jsonError(c, 500, "DB: ошибка выборки зон по вместимости", err)
 	}
-	defer rows.Close()
-
-	type rowT struct {
-		ID       int    `json:"id_зоны"`
-		Name     string `json:"название"`
-		Capacity int    `json:"вместимость"`
-	}
-	var out []rowT
-	var avgValue float64
-	var avgSet bool
-	for rows.Next() {
-		var r rowT
-		var avg float64
-		if err := rows.Scan(&r.ID, &r.Name, &r.Capacity, &avg); err != nil {
-			return jsonError(c, 500, "Ошибка чтения строки", err)
-		}
-		avgValue = avg
-		avgSet = true
-		out = append(out, r)
-	}
-	if err := rows.Err(); err != nil {
-		return jsonError(c, 500, "Ошибка курсора", err)
-	}
-	if !avgSet {
-		if err := db.QueryRowContext(ctx, `SELECT COALESCE(AVG("Вместимость")::float8, 0) FROM "Зона"`).Scan(&avgValue); err != nil {
-			return jsonError(c, 500, "DB: ошибка подсчёта средней вместимости", err)
-		}
-	}
 	return jsonOK(c, fiber.Map{
 		"rows": out,
 		"summary": fiber.Map{
-			"avg_capacity": avgValue,
+			"avg_capacity": avg,
 		},
 	})
 }
@@ -318,19 +167,11 @@ func ReportInsertZone(c *fiber.Ctx) error {
         return jsonError(c, 400, "Заполните корректно: name, capacity>0, status", nil)
     }
 
-    db := database.GetDB()
-    var zoneID int
     ctx, cancel := withDBTimeout()
     defer cancel()
-    if err := db.QueryRowContext(ctx, `
-        INSERT INTO "Зона" ("Название","Описание","Вместимость","Статус")
-        VALUES ($1,$2,$3,$4)
-        RETURNING "id_зоны"
-    `, f.Name, f.Description, f.Capacity, f.Status).Scan(&zoneID); err != nil {
-        return jsonError(c, 500, "DB: ошибка вставки", err)
-    }
 
     //сохранение фото сразу при создании
+    var photoKey string
     if mf, err := c.MultipartForm(); err == nil {
         if files := mf.File["photo"]; len(files) > 0 && files[0] != nil {
             fh := files[0]
@@ -363,12 +204,29 @@ func ReportInsertZone(c *fiber.Ctx) error {
                 return jsonError(c, fiber.StatusBadRequest, "Разрешены JPEG/PNG/WebP", nil)
             }
 
-            if _, err := db.ExecContext(ctx, `UPDATE "Зона" SET "Фото"=$2 WHERE "id_зоны"=$1`, zoneID, buf); err != nil {
-                return jsonError(c, 500, "DB: ошибка сохранения фото", err)
+            var ok bool
+            if photoKey, ok, err = savePhoto(ctx, c, buf); !ok {
+                return err
             }
         }
     }
 
+    var zoneID int
+    err := inTx(ctx, c, func(tx store.Store) error {
+        var err error
+        if zoneID, err = tx.Zones().Create(ctx, zoneInput(f.Name, f.Description, f.Capacity, f.Status)); err != nil {
+            return err
+        }
+        if photoKey == "" {
+            return nil
+        }
+        return tx.Zones().SetPhoto(ctx, zoneID, photoKey)
+    })
+    if err != nil {
+        releasePhoto(photoKey)
+        return jsonError(c, 500, "DB: ошибка вставки", err)
+    }
+
     return jsonOK(c, fiber.Map{
         "message": "Зона добавлена",
         "zone_id": zoneID,
@@ -392,52 +250,21 @@ func ReportPersonalFinished(c *fiber.Ctx) error {
         return jsonError(c, 400, "Дата окончания раньше даты начала", nil)
     }
 
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-
-    // Фильтруем по статусу "Завершена" и дате начала в пределах периода (включая конец дня)
-    rows, err := db.QueryContext(ctx, `
-        SELECT id, client_fio, trainer_fio, starts_at, duration_minutes, price_effective
-        FROM public.v_personal_training_enriched
-        WHERE status = 'Завершена'
-          AND starts_at >= $1
-          AND starts_at < ($2::date + INTERVAL '1 day')
-        ORDER BY starts_at DESC, id DESC
-    `, start, end)
+    out, err := data.Reports().FinishedPersonal(ctx, start, end)
     if err != nil {
         return jsonError(c, 500, "DB: ошибка выборки персональных тренировок", err)
     }
-    defer rows.Close()
-
-    type rowT struct {
-        ID        int       `json:"id"`
-        Client    string    `json:"клиент"`
-        Trainer   string    `json:"тренер"`
-        Starts    time.Time `json:"начало"`
-        Duration  int       `json:"длительность_мин"`
-        Price     float64   `json:"стоимость"`
-    }
-    var out []rowT
-    var totalCount int
     var totalSum float64
-    for rows.Next() {
-        var r rowT
-        if err := rows.Scan(&r.ID, &r.Client, &r.Trainer, &r.Starts, &r.Duration, &r.Price); err != nil {
-            return jsonError(c, 500, "Ошибка чтения строки", err)
-        }
-        out = append(out, r)
-        totalCount++
+    for _, r := range out {
         totalSum += r.Price
     }
-    if err := rows.Err(); err != nil {
-        return jsonError(c, 500, "Ошибка курсора", err)
-    }
 
     return jsonOK(c, fiber.Map{
         "rows": out,
         "summary": fiber.Map{
-            "количество": totalCount,
+            "количество": len(out),
             "сумма": totalSum,
         },
     })
@@ -453,24 +280,17 @@ func ReportUpdateZoneStatus(c *fiber.Ctx) error {
         return jsonError(c, 400, "Недопустимый статус (Доступна/На ремонте/Закрыта)", nil)
     }
 
-    db := database.GetDB()
-    // Находим однозначно ID по названию
-    ctx1, cancel1 := withDBTimeout()
-    rows, err := db.QueryContext(ctx1, `SELECT "id_зоны" FROM "Зона" WHERE "Название"=$1 ORDER BY "id_зоны" LIMIT 2`, name)
-    if err != nil { cancel1(); return jsonError(c, 500, "DB: ошибка поиска зоны", err) }
-    var ids []int
-    for rows.Next() { var id int; if err := rows.Scan(&id); err == nil { ids = append(ids, id) } }
-    rows.Close(); cancel1()
-    if len(ids) == 0 { return jsonError(c, 404, "Зона с таким названием не найдена", nil) }
-    if len(ids) > 1 { return jsonError(c, 409, "Найдено несколько зон с таким названием. Уточните название.", nil) }
-    id := ids[0]
-
     ctx, cancel := withDBTimeout()
     defer cancel()
-    res, err := db.ExecContext(ctx, `UPDATE "Зона" SET "Статус"=$2 WHERE "id_зоны"=$1`, id, status)
+    id, ok, err := reportZoneByName(ctx, c, name)
+    if !ok {
+        return err
+    }
+    err = inTx(ctx, c, func(tx store.Store) error {
+        return tx.Zones().SetStatus(ctx, id, status)
+    })
+    if errors.Is(err, store.ErrNotFound) { return jsonError(c, 404, "Зона не найдена", nil) }
     if err != nil { return jsonError(c, 500, "DB: ошибка обновления", err) }
-    aff, _ := res.RowsAffected()
-    if aff == 0 { return jsonError(c, 404, "Зона не найдена", nil) }
     return jsonOK(c, fiber.Map{"message": fmt.Sprintf("Статус обновлён (ID: %d)", id)})
 }
 
@@ -478,26 +298,42 @@ func ReportDeleteZone(c *fiber.Ctx) error {
     name := strings.TrimSpace(c.FormValue("name"))
     if name == "" { return jsonError(c, 400, "Укажите название зоны", nil) }
 
-    db := database.GetDB()
-    // Находим однозначно ID по названию
-    ctx1, cancel1 := withDBTimeout()
-    rows, err := db.QueryContext(ctx1, `SELECT "id_зоны" FROM "Зона" WHERE "Название"=$1 ORDER BY "id_зоны" LIMIT 2`, name)
-    if err != nil { cancel1(); return jsonError(c, 500, "DB: ошибка поиска зоны", err) }
-    var ids []int
-    for rows.Next() { var id int; if err := rows.Scan(&id); err == nil { ids = append(ids, id) } }
-    rows.Close(); cancel1()
-    if len(ids) == 0 { return jsonError(c, 404, "Зона с таким названием не найдена", nil) }
-    if len(ids) > 1 { return jsonError(c, 409, "Найдено несколько зон с таким названием. Уточните название.", nil) }
-    id := ids[0]
-
     ctx, cancel := withDBTimeout()
     defer cancel()
-    res, err := db.ExecContext(ctx, `DELETE FROM "Зона" WHERE "id_зоны"=$1`, id)
-    if err != nil {
-        return jsonError(c, 500, "DB: ошибка удаления", err)
-    }
-    if rowsAff, _ := res.RowsAffected(); rowsAff == 0 {
+    id, ok, err := reportZoneByName(ctx, c, name)
+    if !ok {
+        return err
+    }
+    var old store.Photo
+    err = inTx(ctx, c, func(tx store.Store) error {
+        if old, err = tx.Zones().Photo(ctx, id); err != nil {
+            return err
+        }
+        return tx.Zones().Delete(ctx, id)
+    })
+    switch {
+    case errors.Is(err, store.ErrNotFound):
         return jsonError(c, 404, "Зона не найдена", nil)
+    case errors.Is(err, store.ErrInUse):
+        return jsonError(c, 409, "Невозможно удалить зону: в ней есть оборудование или тренировки", err)
+    case err != nil:
+        return jsonError(c, 500, "DB: ошибка удаления", err)
     }
+    releasePhoto(old.Key)
     return jsonOK(c, fiber.Map{"message": fmt.Sprintf("Зона удалена (ID: %d)", id)})
 }
+
+// reportZoneByName — id зоны по точному названию; неизвестное или
+// неоднозначное название — 404/409.
+func reportZoneByName(ctx context.Context, c *fiber.Ctx, name string) (int, bool, error) {
+    ids, err := data.Zones().IDsByName(ctx, name, 2)
+    switch {
+    case err != nil:
+        return 0, false, jsonError(c, 500, "DB: ошибка поиска зоны", err)
+    case len(ids) == 0:
+        return 0, false, jsonError(c, 404, "Зона с таким названием не найдена", nil)
+    case len(ids) > 1:
+        return 0, false, jsonError(c, 409, "Найдено несколько зон с таким названием. Уточните название.", nil)
+    }
+    return ids[0], true, nil
+}
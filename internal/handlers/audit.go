@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"fitness-center-manager/internal/audit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetAuditPage — журнал изменений (только администратор)
+func GetAuditPage(c *fiber.Ctx) error {
+	return c.Render("audit", fiber.Map{
+		"Title":        "Журнал изменений",
+		"Entities":     audit.Entities,
+		"Actions":      audit.Actions,
+		"ExtraScripts": tplScript(`/static/js/audit-page.js`),
+	})
+}
+
+// APIv1ListAudit — GET /api/v1/audit?entity=&entity_id=&action=&actor=&from=&to=&page=&per_page=
+func APIv1ListAudit(c *fiber.Ctx) error {
+	f := audit.Filter{
+		Entity: strings.TrimSpace(c.Query("entity")),
+		Action: strings.TrimSpace(c.Query("action")),
+		Actor:  strings.TrimSpace(c.Query("actor")),
+	}
+	if f.Entity != "" {
+		if _, ok := audit.Entities[f.Entity]; !ok {
+			return jsonError(c, 400, "Неизвестная сущность: "+f.Entity, nil)
+		}
+	}
+	if f.Action != "" {
+		if _, ok := audit.Actions[f.Action]; !ok {
+			return jsonError(c, 400, "Недопустимое действие: "+f.Action, nil)
+		}
+	}
+	if v := c.Query("entity_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			return jsonError(c, 400, "Некорректный id записи", err)
+		}
+		f.EntityID = id
+	}
+	for _, p := range []struct {
+		key string
+		dst *time.Time
+	}{{"from", &f.From}, {"to", &f.To}} {
+		if v := c.Query(p.key); v != "" {
+			t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+			if err != nil {
+				return jsonError(c, 400, "Неверный формат даты ("+p.key+")", err)
+			}
+			*p.dst = t
+		}
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && f.To.Before(f.From) {
+		return jsonError(c, 400, "Дата окончания раньше даты начала", nil)
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(c.Query("per_page", "50"))
+	if perPage < 1 || perPage > 200 {
+		perPage = 50
+	}
+	f.Limit, f.Offset = perPage, (page-1)*perPage
+
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	list, total, err := data.Audit().List(ctx, f)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка БД при чтении журнала", err)
+	}
+	return jsonOK(c, fiber.Map{
+		"items":    list,
+		"total":    total,
+		"page":     page,
+		"per_page": perPage,
+	})
+}
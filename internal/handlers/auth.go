@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"fitness-center-manager/internal/auth"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	sessionCookie = "fcm_session"
+	localsStaff   = "CurrentUser" // ключ Locals; виден в шаблонах как .CurrentUser
+
+	// dummyPasswordHash — bcrypt-хеш (той же стоимости, что и у настоящих)
+	// для неизвестного логина: вход отвечает за то же время, и по задержке
+	// нельзя перебрать существующие логины.
+	dummyPasswordHash = "$2a$10$kUNoTMthxeTmEkGFATIn4.wVAxMlwg/apwat0hjQapAT/sX5zB5EK"
+)
+
+var (
+	sessionTTL   = 12 * time.Hour
+	cookieSecure bool
+)
+
+// SetSessionOptions задаёт время жизни сессии и флаг Secure для cookie.
+func SetSessionOptions(ttl time.Duration, secure bool) {
+	if ttl > 0 {
+		sessionTTL = ttl
+	}
+	cookieSecure = secure
+}
+
+// currentStaff — сотрудник текущего запроса (nil для публичных маршрутов).
+func currentStaff(c *fiber.Ctx) *auth.Staff {
+	s, _ := c.Locals(localsStaff).(*auth.Staff)
+	return s
+}
+
+// wantsHTML — запрос страницы из браузера (для редиректа на /login вместо JSON).
+func wantsHTML(c *fiber.Ctx) bool {
+	if c.Method() != fiber.MethodGet || strings.HasPrefix(c.Path(), "/api/") {
+		return false
+	}
+	return strings.Contains(c.Get(fiber.HeaderAccept), "text/html")
+}
+
+// RequireAuth — middleware: находит сессию по cookie и кладёт сотрудника в Locals.
+func RequireAuth(c *fiber.Ctx) error {
+	token := c.Cookies(sessionCookie)
+	if token == "" {
+		return denyUnauthenticated(c)
+	}
+
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	s, err := data.Sessions().Staff(ctx, auth.HashToken(token))
+	if errors.Is(err, store.ErrNotFound) {
+		c.ClearCookie(sessionCookie)
+		return denyUnauthenticated(c)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка БД при проверке сессии", err)
+	}
+	c.Locals(localsStaff, &s)
+	return c.Next()
+}
+
+func denyUnauthenticated(c *fiber.Ctx) error {
+	if wantsHTML(c) {
+		return c.Redirect("/login?next=" + url.QueryEscape(c.OriginalURL()))
+	}
+	return jsonError(c, fiber.StatusUnauthorized, "Требуется вход в систему", nil)
+}
+
+// RequireRole — middleware: пропускает только сотрудников с одной из ролей.
+// Должен стоять после RequireAuth.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		s := currentStaff(c)
+		if s == nil {
+			return denyUnauthenticated(c)
+		}
+		if !s.HasRole(roles...) {
+			return jsonError(c, fiber.StatusForbidden, "Недостаточно прав для этой операции", nil)
+		}
+		return c.Next()
+	}
+}
+
+// ====== Вход / выход ======
+
+// safeNext — разрешаем только относительные пути внутри приложения.
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/login") {
+		return ""
+	}
+	return next
+}
+
+// LoginPage — форма входа
+func LoginPage(c *fiber.Ctx) error {
+	return c.Render("login", fiber.Map{
+		"Title": "Вход",
+		"Next":  safeNext(c.Query("next")),
+	})
+}
+
+// Login — проверка логина/пароля, создание сессии и редирект.
+func Login(c *fiber.Ctx) error {
+	login := strings.TrimSpace(c.FormValue("login"))
+	password := c.FormValue("password")
+	next := safeNext(c.FormValue("next"))
+
+	fail := func(msg string) error {
+		return c.Status(fiber.StatusUnauthorized).Render("login", fiber.Map{
+			"Title": "Вход",
+			"Next":  next,
+			"Login": login,
+			"Error": msg,
+		})
+	}
+	if login == "" || password == "" {
+		return fail("Введите логин и пароль")
+	}
+
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	s, hash, active, err := data.Staff().ByLogin(ctx, login)
+	if errors.Is(err, store.ErrNotFound) {
+		hash, active = dummyPasswordHash, false
+	} else if err != nil {
+		log.Printf("❌ login: %v", err)
+		return fail("Ошибка БД, попробуйте позже")
+	}
+	// пароль сверяется всегда, даже для неизвестного или заблокированного логина
+	if !auth.CheckPassword(hash, password) || !active {
+		log.Printf("🔒 неудачный вход: %q (%s)", login, c.IP())
+		return fail("Неверный логин или пароль")
+	}
+
+	token, tokenHash, err := auth.NewSessionToken()
+	if err != nil {
+		return jsonError(c, 500, "Не удалось создать сессию", err)
+	}
+	expires := time.Now().Add(sessionTTL)
+	if err := data.Sessions().Create(ctx, tokenHash, s.ID, expires); err != nil {
+		log.Printf("❌ create session: %v", err)
+		return fail("Ошибка БД, попробуйте позже")
+	}
+	// заодно подчищаем протухшие сессии
+	_ = data.Sessions().DeleteExpired(ctx)
+
+	c.Cookie(&fiber.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HTTPOnly: true,
+		Secure:   cookieSecure,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	log.Printf("🔓 вход: %s (%s)", s.Login, s.Role)
+
+	if next == "" {
+		next = s.HomePath()
+	}
+	return c.Redirect(next)
+}
+
+// Logout — удалить сессию и cookie.
+func Logout(c *fiber.Ctx) error {
+	if token := c.Cookies(sessionCookie); token != "" {
+		ctx, cancel := withDBTimeout()
+		defer cancel()
+		if err := data.Sessions().Delete(ctx, auth.HashToken(token)); err != nil {
+			log.Printf("❌ logout: %v", err)
+		}
+	}
+	c.ClearCookie(sessionCookie)
+	return c.Redirect("/login")
+}
+
+// EnsureBootstrapAdmin создаёт администратора, если в системе ещё нет ни одного сотрудника.
+// Вызывается из main при старте; без пароля в конфиге ничего не делает.
+func EnsureBootstrapAdmin(login, password string) error {
+	login = strings.TrimSpace(login)
+	if login == "" {
+		login = "admin"
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+
+	cnt, err := data.Staff().Count(ctx)
+	if err != nil {
+		return err
+	}
+	if cnt > 0 {
+		return nil
+	}
+	if password == "" {
+		log.Println("⚠️  Нет ни одного сотрудника: задайте auth.admin_password в config.secret.yaml, чтобы создать администратора")
+		return nil
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if _, err := data.Staff().Create(ctx, store.StaffInput{
+		Login: login, FIO: "Администратор", Role: auth.RoleAdmin, PasswordHash: hash,
+	}); err != nil {
+		return err
+	}
+	log.Printf("👤 создан администратор %q", login)
+	return nil
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"fitness-center-manager/internal/access"
+	"fitness-center-manager/internal/availability"
+	"fitness-center-manager/internal/checkin"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// checkAvailability — *availability.Error, если тренер не работает
+// в [start, end): вне рабочих часов или в отпуске/на больничном.
+// Неизвестного тренера отклонит внешний ключ при записи.
+func checkAvailability(ctx context.Context, tx store.Store, trainerID int, start, end time.Time) error {
+	hours, err := tx.Availability().Hours(ctx, trainerID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	absences, err := tx.Availability().Absences(ctx, trainerID, checkin.Day(start), checkin.Day(end))
+	if err != nil {
+		return err
+	}
+	return availability.Check(hours, absences, start, end)
+}
+
+type absenceDTO struct {
+	ID        int    `json:"id"`
+	Kind      string `json:"kind"`
+	StartDate string `json:"start_date"` // YYYY-MM-DD
+	EndDate   string `json:"end_date"`
+	Reason    string `json:"reason"`
+}
+
+func toAbsenceDTOs(list []models.TrainerAbsence) []absenceDTO {
+	out := make([]absenceDTO, 0, len(list))
+	for _, a := range list {
+		out = append(out, absenceDTO{a.ID, a.Kind, a.StartDate.Format("2006-01-02"), a.EndDate.Format("2006-01-02"), a.Reason})
+	}
+	return out
+}
+
+func trainerParam(c *fiber.Ctx) (int, bool, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return 0, false, jsonError(c, 400, "Некорректный id", err)
+	}
+	return id, true, nil
+}
+
+// APIv1TrainerSchedule — GET /api/v1/trainers/:id/schedule: рабочие часы
+// и отсутствия тренера (прошедшие — за последний месяц).
+func APIv1TrainerSchedule(c *fiber.Ctx) error {
+	id, ok, err := trainerParam(c)
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	hours, err := data.Availability().Hours(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Тренер не найден", nil)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки расписания", err)
+	}
+	absences, err := data.Availability().Absences(ctx, id, checkin.Day(time.Now()).AddDate(0, -1, 0), time.Time{})
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки отсутствий", err)
+	}
+	return jsonOK(c, fiber.Map{
+		"working_hours": hours.String(),
+		"hours":         hours,
+		"absences":      toAbsenceDTOs(absences),
+	})
+}
+
+// APIv1SetTrainerHours — PUT /api/v1/trainers/:id/working-hours
+// (working_hours — как часы доступа тарифа: «будни 09:00–21:00; сб 10:00–16:00»;
+// пусто — без ограничений). Уже назначенные тренировки не меняются.
+func APIv1SetTrainerHours(c *fiber.Ctx) error {
+	id, ok, err := trainerParam(c)
+	if !ok {
+		return err
+	}
+	hours, err := access.Parse(c.FormValue("working_hours"))
+	if err != nil {
+		return jsonError(c, 400, "Рабочие часы: "+err.Error(), nil)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		return tx.Availability().SetHours(ctx, id, hours)
+	})
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Тренер не найден", nil)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка сохранения рабочих часов", err)
+	}
+	return jsonOK(c, fiber.Map{"working_hours": hours.String(), "message": "Рабочие часы: " + hours.String()})
+}
+
+// APIv1CreateAbsence — POST /api/v1/trainers/:id/absences (kind, start_date,
+// end_date, reason). Тренировки, уже назначенные на эти дни, не отменяются —
+// они возвращаются в ответе, чтобы найти замену.
+func APIv1CreateAbsence(c *fiber.Ctx) error {
+	trainerID, ok, err := trainerParam(c)
+	if !ok {
+		return err
+	}
+	in := store.AbsenceInput{Kind: c.FormValue("kind"), Reason: strings.TrimSpace(c.FormValue("reason"))}
+	if !availability.ValidKind(in.Kind) {
+		return jsonError(c, 400, "Неверный вид отсутствия (Отпуск | Больничный | Отгул)", nil)
+	}
+	if len([]rune(in.Reason)) > 200 {
+		return jsonError(c, 400, "Причина длиннее 200 символов", nil)
+	}
+	if in.StartDate, err = time.Parse("2006-01-02", c.FormValue("start_date")); err != nil {
+		return jsonError(c, 400, "Неверная дата начала", err)
+	}
+	if in.EndDate, err = time.Parse("2006-01-02", c.FormValue("end_date")); err != nil {
+		return jsonError(c, 400, "Неверная дата окончания", err)
+	}
+	if in.EndDate.Before(in.StartDate) {
+		return jsonError(c, 400, "Дата окончания раньше даты начала", nil)
+	}
+
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var (
+		id       int
+		affected []models.ScheduleConflict
+	)
+	err = inTx(ctx, c, func(tx store.Store) error {
+		if _, err := tx.Availability().Hours(ctx, trainerID); err != nil {
+			return err
+		}
+		existing, err := tx.Availability().Absences(ctx, trainerID, in.StartDate, in.EndDate)
+		if err != nil {
+			return err
+		}
+		if err := availability.CheckAbsence(existing, in.StartDate, in.EndDate); err != nil {
+			return err
+		}
+		if id, err = tx.Availability().CreateAbsence(ctx, trainerID, in); err != nil {
+			return err
+		}
+		affected, err = tx.Trainings().Conflicts(ctx, store.ConflictQuery{
+			TrainerID: trainerID, Start: in.StartDate, End: in.EndDate.AddDate(0, 0, 1)})
+		return err
+	})
+	var rule *availability.Error
+	switch {
+	case errors.As(err, &rule):
+		return jsonReject(c, fiber.StatusConflict, string(rule.Reason), rule.Msg, nil)
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Тренер не найден", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка сохранения отсутствия", err)
+	}
+
+	trainings := make([]conflictDTO, 0, len(affected))
+	for _, x := range affected {
+		trainings = append(trainings, conflictDTO{Kind: x.Kind, ID: x.ID, Title: x.Title, Trainer: x.TrainerName,
+			Zone: x.ZoneName, Start: x.StartTime.Format(visitTimeFormat), End: x.EndTime.Format(visitTimeFormat)})
+	}
+	message := in.Kind + " добавлен"
+	if len(trainings) > 0 {
+		message += "; на эти дни у тренера назначено тренировок: " + strconv.Itoa(len(trainings)) + " — нужна замена"
+	}
+	return jsonOK(c, fiber.Map{"id": id, "trainings": trainings, "message": message})
+}
+
+// APIv1DeleteAbsence — DELETE /api/v1/trainer-absences/:id
+func APIv1DeleteAbsence(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		return tx.Availability().DeleteAbsence(ctx, id)
+	})
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Отсутствие не найдено", nil)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка удаления отсутствия", err)
+	}
+	return jsonOK(c, fiber.Map{"message": "Отсутствие удалено"})
+}
+
+// Пределы поиска свободных слотов.
+const (
+	maxAvailabilityDays = 31
+	defaultSlotMinutes  = 60
+	defaultStepMinutes  = 30
+)
+
+type slotDTO struct {
+	Date  string `json:"date"` // YYYY-MM-DD
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// APIv1TrainerAvailability — GET /api/v1/trainers/:id/availability?from=&to=&duration=&step=:
+// свободные слоты тренера длительностью duration минут (по умолчанию 60)
+// с шагом step (30) в дни [from, to] (по умолчанию — неделя с сегодня).
+// Слот свободен, если он в рабочих часах, не в отсутствии и не пересекается
+// ни с одной групповой или неотменённой персональной тренировкой тренера.
+func APIv1TrainerAvailability(c *fiber.Ctx) error {
+	id, ok, err := trainerParam(c)
+	if !ok {
+		return err
+	}
+	now := clubWallNow()
+	from, to := checkin.Day(now), checkin.Day(now).AddDate(0, 0, 6)
+	if s := c.Query("from"); s != "" {
+		if from, err = time.Parse("2006-01-02", s); err != nil {
+			return jsonError(c, 400, "Неверная дата from", err)
+		}
+		to = from.AddDate(0, 0, 6)
+	}
+	if s := c.Query("to"); s != "" {
+		if to, err = time.Parse("2006-01-02", s); err != nil {
+			return jsonError(c, 400, "Неверная дата to", err)
+		}
+	}
+	if to.Before(from) || to.Sub(from) >= maxAvailabilityDays*24*time.Hour {
+		return jsonError(c, 400, "Период — от 1 до "+strconv.Itoa(maxAvailabilityDays)+" дней", nil)
+	}
+	dur := c.QueryInt("duration", defaultSlotMinutes)
+	step := c.QueryInt("step", defaultStepMinutes)
+	if dur < 15 || dur > 8*60 || step < 5 || step > 4*60 {
+		return jsonError(c, 400, "duration — от 15 до 480 минут, step — от 5 до 240", nil)
+	}
+
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	hours, err := data.Availability().Hours(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Тренер не найден", nil)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки расписания", err)
+	}
+	absences, err := data.Availability().Absences(ctx, id, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки отсутствий", err)
+	}
+	// слот в последний день может заканчиваться уже на следующий
+	booked, err := data.Trainings().Conflicts(ctx, store.ConflictQuery{
+		TrainerID: id, Start: from, End: to.AddDate(0, 0, 1).Add(time.Duration(dur) * time.Minute)})
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки тренировок", err)
+	}
+	busy := make([]availability.Interval, 0, len(booked))
+	for _, b := range booked {
+		busy = append(busy, availability.Interval{Start: b.StartTime, End: b.EndTime})
+	}
+
+	slots := availability.FreeSlots(hours, absences, busy, from, to,
+		time.Duration(dur)*time.Minute, time.Duration(step)*time.Minute, now)
+	out := make([]slotDTO, 0, len(slots))
+	for _, s := range slots {
+		out = append(out, slotDTO{s.Start.Format("2006-01-02"), s.Start.Format("15:04"), s.End.Format("15:04")})
+	}
+	return jsonOK(c, fiber.Map{
+		"trainer_id":    id,
+		"from":          from.Format("2006-01-02"),
+		"to":            to.Format("2006-01-02"),
+		"duration":      dur,
+		"step":          step,
+		"working_hours": hours.String(),
+		"absences":      toAbsenceDTOs(absences),
+		"slots":         out,
+	})
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"fitness-center-manager/internal/auth"
+	"fitness-center-manager/internal/ical"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var calendarPublicURL string
+
+// SetCalendarOptions задаёт внешний адрес календарных ссылок (пусто — адрес
+// запроса). События пишутся в часовом поясе клуба (SetClubLocation).
+func SetCalendarOptions(publicURL string) {
+	calendarPublicURL = strings.TrimRight(strings.TrimSpace(publicURL), "/")
+}
+
+// Окно календарной ссылки: прошедший месяц и полгода вперёд.
+const (
+	calendarPastDays  = 30
+	calendarAheadDays = 183
+)
+
+// calendarUIDDomain — правая часть UID событий; UID = вид-id@домен и не
+// меняется, пока жива тренировка, — так правки и отмены доходят до подписчиков.
+const calendarUIDDomain = "fitness-center-manager"
+
+var feedTitles = map[string]string{
+	store.FeedTrainer: "Тренер",
+	store.FeedZone:    "Зона",
+	store.FeedClient:  "Клиент",
+}
+
+// calendarEvent — событие для ссылки вида kind.
+func calendarEvent(kind string, e models.CalendarEvent) ical.Event {
+	ev := ical.Event{
+		UID:      fmt.Sprintf("%s-%d@%s", e.Kind, e.ID, calendarUIDDomain),
+		Start:    e.StartTime,
+		End:      e.EndTime,
+		Summary:  e.Title,
+		Location: e.ZoneName,
+		Sequence: e.Revisions,
+	}
+	if e.Modified.Valid {
+		ev.Modified = e.Modified.Time
+	}
+	var desc []string
+	if e.Description != "" {
+		desc = append(desc, e.Description)
+	}
+	if kind != store.FeedTrainer {
+		desc = append(desc, "Тренер: "+e.TrainerName)
+	}
+	if kind == store.FeedZone {
+		ev.Summary += " — " + e.TrainerName
+	}
+	ev.Description = strings.Join(desc, "\n")
+
+	switch e.Status {
+	case "Отменена", "Отменил":
+		ev.Status = ical.StatusCancelled
+	case "В очереди":
+		ev.Status = ical.StatusTentative
+		ev.Summary = "(лист ожидания) " + ev.Summary
+	}
+	return ev
+}
+
+// GetCalendarFeed — GET /calendar/:token.ics: календарь по секретной ссылке,
+// без входа в систему. Неизвестный токен — 404 без подробностей.
+func GetCalendarFeed(c *fiber.Ctx) error {
+	token := strings.TrimSuffix(c.Params("token"), ".ics")
+	if token == "" {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+
+	feed, err := data.Calendar().FeedByToken(ctx, auth.HashToken(token))
+	if errors.Is(err, store.ErrNotFound) {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	if err != nil {
+		log.Printf("calendar feed: %v", err)
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	name, err := data.Calendar().OwnerName(ctx, feed.Kind, feed.OwnerID)
+	if errors.Is(err, store.ErrNotFound) {
+		// владельца удалили — ссылка больше не работает
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	if err != nil {
+		log.Printf("calendar feed owner: %v", err)
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	today := clubNow()
+	from := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -calendarPastDays)
+	events, err := data.Calendar().Events(ctx, feed.Kind, feed.OwnerID, from, from.AddDate(0, 0, calendarPastDays+calendarAheadDays))
+	if err != nil {
+		log.Printf("calendar feed events: %v", err)
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if err := data.Calendar().Touch(ctx, feed.ID); err != nil {
+		log.Printf("calendar feed touch: %v", err)
+	}
+
+	cal := ical.Calendar{Name: feedTitles[feed.Kind] + ": " + name, Location: clubLocation}
+	for _, e := range events {
+		cal.Events = append(cal.Events, calendarEvent(feed.Kind, e))
+	}
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `inline; filename="schedule.ics"`)
+	c.Set(fiber.HeaderCacheControl, "private, max-age=300")
+	return cal.Write(c.Response().BodyWriter())
+}
+
+// feedOwner — владелец ссылки из :kind/:id; расписание клиента — только
+// тем, кому доступен раздел клиентов. При отказе ответ уже отправлен.
+func feedOwner(c *fiber.Ctx) (string, int, bool, error) {
+	kind := c.Params("kind")
+	if _, ok := feedTitles[kind]; !ok {
+		return "", 0, false, jsonError(c, 400, "Неверный вид ссылки (trainer | zone | client)", nil)
+	}
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return "", 0, false, jsonError(c, 400, "Некорректный id", err)
+	}
+	if kind == store.FeedClient && !currentStaff(c).CanSee("clients") {
+		return "", 0, false, jsonError(c, fiber.StatusForbidden, "Недостаточно прав", nil)
+	}
+	return kind, id, true, nil
+}
+
+func feedURL(c *fiber.Ctx, token string) string {
+	base := calendarPublicURL
+	if base == "" {
+		base = c.BaseURL()
+	}
+	return base + "/calendar/" + token + ".ics"
+}
+
+// APIv1GetCalendarFeed — GET /api/v1/calendar-feeds/:kind/:id: есть ли
+// действующая ссылка (сам токен не хранится и повторно не показывается).
+func APIv1GetCalendarFeed(c *fiber.Ctx) error {
+	kind, id, ok, err := feedOwner(c)
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	f, err := data.Calendar().Feed(ctx, kind, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonOK(c, fiber.Map{"exists": false})
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки ссылки", err)
+	}
+	out := fiber.Map{"exists": true, "created": f.CreatedAt.Format(visitTimeFormat), "last_access": nil}
+	if f.LastAccess.Valid {
+		out["last_access"] = f.LastAccess.Time.Format(visitTimeFormat)
+	}
+	return jsonOK(c, out)
+}
+
+// APIv1CreateCalendarFeed — POST /api/v1/calendar-feeds/:kind/:id: новая
+// ссылка; прежняя перестаёт работать. URL показывается только в этом ответе.
+func APIv1CreateCalendarFeed(c *fiber.Ctx) error {
+	kind, id, ok, err := feedOwner(c)
+	if !ok {
+		return err
+	}
+	token, hash, err := auth.NewSessionToken()
+	if err != nil {
+		return jsonError(c, 500, "Не удалось создать ссылку", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	if _, err := data.Calendar().OwnerName(ctx, kind, id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return jsonError(c, 404, "Не найдено", nil)
+		}
+		return jsonError(c, 500, "Ошибка загрузки", err)
+	}
+	if _, err := data.Calendar().SaveFeed(ctx, kind, id, hash); err != nil {
+		return jsonError(c, 500, "Ошибка сохранения ссылки", err)
+	}
+	url := feedURL(c, token)
+	return jsonOK(c, fiber.Map{
+		"url":     url,
+		"webcal":  "webcal://" + strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://"),
+		"message": "Ссылка создана; прежняя ссылка больше не работает",
+	})
+}
+
+// APIv1DeleteCalendarFeed — DELETE /api/v1/calendar-feeds/:kind/:id: отключить ссылку.
+func APIv1DeleteCalendarFeed(c *fiber.Ctx) error {
+	kind, id, ok, err := feedOwner(c)
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = data.Calendar().DeleteFeed(ctx, kind, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Ссылки нет", nil)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка отключения ссылки", err)
+	}
+	return jsonOK(c, fiber.Map{"message": "Ссылка отключена"})
+}
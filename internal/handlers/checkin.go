@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"fitness-center-manager/internal/checkin"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	visitTimeFormat   = "02.01.2006 15:04"
+	clientVisitsLimit = 50
+)
+
+type visitDTO struct {
+	ID             int    `json:"id"`
+	ClientID       int    `json:"client_id"`
+	ClientName     string `json:"client_name"`
+	SubscriptionID int    `json:"subscription_id,omitempty"`
+	TariffName     string `json:"tariff_name"`
+	EnteredAt      string `json:"entered_at"`
+	ExitedAt       string `json:"exited_at"` // пусто — ещё в зале
+	Minutes        int    `json:"minutes"`
+}
+
+func toVisitDTO(v models.Visit, now time.Time) visitDTO {
+	d := visitDTO{
+		ID:             v.ID,
+		ClientID:       v.ClientID,
+		ClientName:     v.ClientName,
+		SubscriptionID: int(v.SubscriptionID.Int64),
+		TariffName:     v.TariffName,
+		EnteredAt:      v.EnteredAt.Format(visitTimeFormat),
+	}
+	until := now
+	if v.ExitedAt.Valid {
+		d.ExitedAt = v.ExitedAt.Time.Format(visitTimeFormat)
+		until = v.ExitedAt.Time
+	}
+	d.Minutes = int(until.Sub(v.EnteredAt).Minutes())
+	return d
+}
+
+func toVisitDTOs(list []models.Visit) []visitDTO {
+	now := time.Now()
+	out := make([]visitDTO, 0, len(list))
+	for _, v := range list {
+		out = append(out, toVisitDTO(v, now))
+	}
+	return out
+}
+
+// GetCheckinPage — стойка ресепшна: ввод карты/id и список «сейчас в зале»
+func GetCheckinPage(c *fiber.Ctx) error {
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+
+	inside, err := data.Visits().Inside(ctx)
+	if err != nil {
+		log.Printf("❌ visits inside error: %v", err)
+		return c.Render("checkin", fiber.Map{
+			"Title":        "Вход",
+			"Inside":       []visitDTO{},
+			"Message":      "Не удалось загрузить список посетителей",
+			"ExtraScripts": templateScript("/static/js/checkin.js"),
+		})
+	}
+	return c.Render("checkin", fiber.Map{
+		"Title":        "Вход",
+		"Inside":       toVisitDTOs(inside),
+		"ExtraScripts": templateScript("/static/js/checkin.js"),
+	})
+}
+
+// APIv1ListInside — JSON: кто сейчас в зале
+func APIv1ListInside(c *fiber.Ctx) error {
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	inside, err := data.Visits().Inside(ctx)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки посещений", err)
+	}
+	return jsonOK(c, fiber.Map{"visits": toVisitDTOs(inside)})
+}
+
+// findClientByCode — клиент по номеру карты, а если такой карты нет и код
+// числовой — по id. Сначала карта: номера карт тоже бывают цифровыми.
+func findClientByCode(ctx context.Context, s store.Store, code string) (models.Client, error) {
+	cl, err := s.Clients().FindByCard(ctx, code)
+	if !errors.Is(err, store.ErrNotFound) {
+		return cl, err
+	}
+	id, convErr := strconv.Atoi(code)
+	if convErr != nil || id <= 0 {
+		return cl, err
+	}
+	return s.Clients().Get(ctx, id)
+}
+
+type checkinForm struct {
+	Code string `form:"code" json:"code"` // номер карты или id клиента
+}
+
+func parseCheckinCode(c *fiber.Ctx) (string, bool, error) {
+	var f checkinForm
+	if err := c.BodyParser(&f); err != nil {
+		return "", false, jsonError(c, 400, "Неверные данные формы", err)
+	}
+	code := strings.TrimSpace(f.Code)
+	if code == "" {
+		return "", false, jsonError(c, 400, "Укажите номер карты или id клиента", nil)
+	}
+	return code, true, nil
+}
+
+// APIv1Checkin — POST /api/v1/checkin: проверить абонемент и отметить вход.
+// Отказ — 403 с кодом причины (reason), см. пакет checkin.
+func APIv1Checkin(c *fiber.Ctx) error {
+	code, ok, err := parseCheckinCode(c)
+	if !ok {
+		return err
+	}
+	staffID := 0
+	if me := currentStaff(c); me != nil {
+		staffID = me.ID
+	}
+
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+
+	var (
+		client   models.Client
+		decision checkin.Decision
+		visitID  int
+	)
+	err = inTx(ctx, c, func(tx store.Store) error {
+		var err error
+		if client, err = findClientByCode(ctx, tx, code); err != nil {
+			return err
+		}
+		if _, err := tx.Visits().Open(ctx, client.ID); err == nil {
+			decision.Reason = checkin.AlreadyInside
+			return nil
+		} else if !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+		subs, err := tx.Subscriptions().ListByClient(ctx, client.ID)
+		if err != nil {
+			return err
+		}
+		if decision = checkin.Decide(subs, clubNow()); !decision.Allowed() {
+			return nil
+		}
+		visitID, err = tx.Visits().Enter(ctx, client.ID, decision.Subscription.ID, staffID)
+		return err
+	})
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Клиент не найден", nil)
+	case errors.Is(err, store.ErrDuplicate): // параллельный вход того же клиента
+		decision.Reason = checkin.AlreadyInside
+	case err != nil:
+		return jsonError(c, 500, "Ошибка регистрации входа", err)
+	}
+
+	who := fiber.Map{"id": client.ID, "fio": client.FIO}
+	if !decision.Allowed() {
+		extra := fiber.Map{"client": who}
+		if s := decision.Subscription; s != nil {
+			extra["subscription"] = subscriptionBrief(*s)
+		}
+		return jsonReject(c, fiber.StatusForbidden, string(decision.Reason), decision.Reason.Message(), extra)
+	}
+	return jsonOK(c, fiber.Map{
+		"message":      "Вход отмечен",
+		"visit_id":     visitID,
+		"client":       who,
+		"subscription": subscriptionBrief(*decision.Subscription),
+	})
+}
+
+func subscriptionBrief(s models.Subscription) fiber.Map {
+	brief := fiber.Map{
+		"id":         s.ID,
+		"tariff":     s.TariffName,
+		"status":     s.Status,
+		"start_date": s.StartDate.Format(dateDisplayFormat),
+		"end_date":   s.EndDate.Format(dateDisplayFormat),
+		"hours":      s.TariffHours.String(),
+	}
+	if s.FrozenUntil.Valid {
+		brief["frozen_until"] = s.FrozenUntil.Time.Format(dateDisplayFormat)
+	}
+	return brief
+}
+
+// APIv1Checkout — POST /api/v1/checkout: отметить выход по карте/id клиента
+func APIv1Checkout(c *fiber.Ctx) error {
+	code, ok, err := parseCheckinCode(c)
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+
+	var client models.Client
+	notInside := false
+	err = inTx(ctx, c, func(tx store.Store) error {
+		var err error
+		if client, err = findClientByCode(ctx, tx, code); err != nil {
+			return err
+		}
+		v, err := tx.Visits().Open(ctx, client.ID)
+		if errors.Is(err, store.ErrNotFound) {
+			notInside = true
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Visits().Exit(ctx, v.ID)
+	})
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Клиент не найден", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка регистрации выхода", err)
+	case notInside:
+		return jsonError(c, 409, "Клиент сейчас не в зале", nil)
+	}
+	return jsonOK(c, fiber.Map{
+		"message": "Выход отмечен",
+		"client":  fiber.Map{"id": client.ID, "fio": client.FIO},
+	})
+}
+
+// APIv1VisitExit — POST /api/v1/visits/:id/exit: закрыть посещение из списка
+func APIv1VisitExit(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		return tx.Visits().Exit(ctx, id)
+	})
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Открытое посещение не найдено", nil)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка регистрации выхода", err)
+	}
+	return jsonOK(c, fiber.Map{"message": "Выход отмечен"})
+}
+
+// APIv1ClientVisits — GET /api/v1/clients/:id/visits: история посещений клиента
+func APIv1ClientVisits(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	list, err := data.Visits().ListByClient(ctx, id, clientVisitsLimit)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки посещений", err)
+	}
+	return jsonOK(c, fiber.Map{"visits": toVisitDTOs(list)})
+}
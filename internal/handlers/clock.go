@@ -0,0 +1,22 @@
+package handlers
+
+import "time"
+
+// clubLocation — часовой пояс клуба (server.timezone).
+var clubLocation = time.UTC
+
+// SetClubLocation задаёт часовой пояс клуба: в нём считаются «сегодня»,
+// часы доступа тарифов и события календарных ссылок.
+func SetClubLocation(loc *time.Location) { clubLocation = loc }
+
+// clubNow — текущий момент в часовом поясе клуба. Сервер (контейнер)
+// может работать в UTC, поэтому дни и часы берутся только отсюда.
+func clubNow() time.Time { return time.Now().In(clubLocation) }
+
+// clubWallNow — текущее «настенное» время клуба с поясом UTC. Времена
+// тренировок хранятся без пояса и сканируются как UTC (см. пакет ical),
+// поэтому сравнивать их можно только с ним, а не с time.Now().
+func clubWallNow() time.Time {
+	t := clubNow()
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"fitness-center-manager/internal/availability"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// conflictError — интервал пересекается с другими тренировками.
+type conflictError struct {
+	list []models.ScheduleConflict
+}
+
+func (e *conflictError) Error() string {
+	return fmt.Sprintf("пересечений по расписанию: %d", len(e.list))
+}
+
+// forceOverlap — форма просит сохранить несмотря на пересечения (force=1);
+// это разрешено только администратору. При отказе ответ уже отправлен.
+func forceOverlap(c *fiber.Ctx) (force, ok bool, err error) {
+	switch c.Query("force", c.FormValue("force")) {
+	case "1", "true", "on":
+	default:
+		return false, true, nil
+	}
+	if !currentStaff(c).IsAdmin() {
+		return false, false, jsonError(c, 403, "Сохранить с пересечением может только администратор", nil)
+	}
+	return true, true, nil
+}
+
+// groupConflictQuery — проверка групповой in (excludeID — она сама при правке).
+func groupConflictQuery(in store.GroupTrainingInput, excludeID int) store.ConflictQuery {
+	return store.ConflictQuery{TrainerID: in.TrainerID, ZoneID: in.ZoneID, Start: in.Start, End: in.End, ExcludeGroupID: excludeID}
+}
+
+// personalConflictQuery — проверка персональной in; отменённая тренировка
+// никому не мешает, поэтому и не проверяется (TrainerID == 0).
+func personalConflictQuery(in store.PersonalTrainingInput, excludeID int) store.ConflictQuery {
+	if in.Status == "Отменена" {
+		return store.ConflictQuery{}
+	}
+	return store.ConflictQuery{TrainerID: in.TrainerID, Start: in.Start, End: in.End, ExcludePersonalID: excludeID}
+}
+
+// checkSchedule — можно ли поставить тренировку на интервал q: ошибка
+// *availability.Error, если тренер в это время не работает, и
+// *conflictError, если интервал пересекается с другими тренировками.
+// force снимает только проверку пересечений: рабочие часы и отсутствия
+// тренера проверяются всегда. Пустой q не проверяется.
+func checkSchedule(ctx context.Context, tx store.Store, q store.ConflictQuery, force bool) error {
+	if q.TrainerID != 0 {
+		if err := checkAvailability(ctx, tx, q.TrainerID, q.Start, q.End); err != nil {
+			return err
+		}
+	}
+	if force || (q.TrainerID == 0 && q.ZoneID == 0) {
+		return nil
+	}
+	list, err := tx.Trainings().Conflicts(ctx, q)
+	if err != nil {
+		return err
+	}
+	if len(list) > 0 {
+		return &conflictError{list}
+	}
+	return nil
+}
+
+type conflictDTO struct {
+	Kind    string   `json:"kind"` // group | personal
+	ID      int      `json:"id"`
+	Title   string   `json:"title"`
+	Trainer string   `json:"trainer"`
+	Zone    string   `json:"zone,omitempty"`
+	Start   string   `json:"start"`
+	End     string   `json:"end"`
+	Reasons []string `json:"reasons"` // trainer | zone
+}
+
+// conflictReject — ответ на отказ checkSchedule (handled == true): 409
+// с кодом правила доступности, 409 со списком пересечений или 409 на
+// срабатывание ограничения БД.
+func conflictReject(c *fiber.Ctx, err error) (bool, error) {
+	var (
+		ce   *conflictError
+		rule *availability.Error
+	)
+	switch {
+	case errors.As(err, &rule):
+		return true, jsonReject(c, fiber.StatusConflict, string(rule.Reason), rule.Msg, nil)
+	case errors.As(err, &ce):
+	case errors.Is(err, store.ErrOverlap):
+		// проверку обогнала параллельная запись
+		return true, jsonReject(c, fiber.StatusConflict, "schedule_conflict",
+			"Время пересекается с другой тренировкой — обновите страницу", fiber.Map{"conflicts": []conflictDTO{}})
+	default:
+		return false, nil
+	}
+
+	out := make([]conflictDTO, 0, len(ce.list))
+	lines := make([]string, 0, len(ce.list))
+	for _, x := range ce.list {
+		d := conflictDTO{Kind: x.Kind, ID: x.ID, Title: x.Title, Trainer: x.TrainerName, Zone: x.ZoneName,
+			Start: x.StartTime.Format(visitTimeFormat), End: x.EndTime.Format(visitTimeFormat)}
+		var who []string
+		if x.SameTrainer {
+			d.Reasons = append(d.Reasons, "trainer")
+			who = append(who, "тренер "+x.TrainerName)
+		}
+		if x.SameZone {
+			d.Reasons = append(d.Reasons, "zone")
+			who = append(who, "зона «"+x.ZoneName+"»")
+		}
+		out = append(out, d)
+		lines = append(lines, fmt.Sprintf("«%s» %s–%s (%s)", x.Title, d.Start, x.EndTime.Format("15:04"), strings.Join(who, ", ")))
+	}
+	msg := "Пересечение по расписанию: " + strings.Join(lines, "; ")
+	return true, jsonReject(c, fiber.StatusConflict, "schedule_conflict", msg, fiber.Map{"conflicts": out})
+}
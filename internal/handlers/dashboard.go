@@ -2,107 +2,14 @@ package handlers
 
 import (
 	"context"
-	"database/sql"
 	"log"
-	"time"
 
-	"fitness-center-manager/internal/database"
+	"fitness-center-manager/internal/maintenance"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
 	"github.com/gofiber/fiber/v2"
 )
 
-const (
-	dashboardStatsQuery = `
-WITH
-    clients AS (
-        SELECT COUNT(*)::int AS total
-        FROM public."Клиент"
-    ),
-    trainers AS (
-        SELECT COUNT(*)::int AS total
-        FROM public."Тренер"
-    ),
-    active_subscriptions AS (
-        SELECT COUNT(*) FILTER (WHERE "Статус" = 'Активен')::int AS total
-        FROM public."Абонемент"
-    ),
-    group_trainings AS (
-        SELECT COUNT(*)::int AS upcoming
-        FROM public."Групповая_тренировка"
-        WHERE "Время_начала" >= NOW()
-    ),
-    personal_trainings AS (
-        SELECT COUNT(*)::int AS upcoming
-        FROM public."Персональная_тренировка"
-        WHERE "Время_начала" >= NOW()
-    ),
-    zones AS (
-        SELECT
-            COUNT(*) FILTER (WHERE "Статус" = 'Доступна')::int AS active,
-            COUNT(*) FILTER (WHERE "Статус" = 'На ремонте')::int AS repair,
-            COALESCE(SUM("Вместимость"), 0)::int AS capacity
-        FROM public."Зона"
-    ),
-    equipment AS (
-        SELECT
-            COUNT(*)::int AS total,
-            COUNT(*) FILTER (WHERE "Статус" = 'Работает')::int AS working,
-            COUNT(*) FILTER (WHERE "Статус" = 'На ремонте')::int AS repair,
-            COUNT(*) FILTER (WHERE "Фото" IS NULL)::int AS no_photo
-        FROM public."Оборудование"
-    )
-SELECT
-    clients.total,
-    trainers.total,
-    active_subscriptions.total,
-    group_trainings.upcoming,
-    personal_trainings.upcoming,
-    zones.active,
-    zones.repair,
-    zones.capacity,
-    equipment.total,
-    equipment.working,
-    equipment.repair,
-    equipment.no_photo
-FROM clients, trainers, active_subscriptions, group_trainings, personal_trainings, zones, equipment;
-`
-	recentClientsQuery = `
-SELECT
-    "id_клиента",
-    "ФИО",
-    "Дата_регистрации",
-    COALESCE(last_tariff, '') AS last_tariff,
-    COALESCE(last_subscription_status, '') AS last_status
-FROM view_client_enriched
-ORDER BY "Дата_регистрации" DESC
-LIMIT 5;
-`
-	expiringSubscriptionsQuery = `
-SELECT
-    a."id_абонемента",
-    c."ФИО",
-    t."Название_тарифа",
-    a."Дата_окончания"
-FROM "Абонемент" a
-JOIN "Клиент" c ON c."id_клиента" = a."id_клиента"
-JOIN "Тариф" t ON t."id_тарифа" = a."id_тарифа"
-WHERE a."Статус" = 'Активен'
-  AND a."Дата_окончания" BETWEEN CURRENT_DATE AND (CURRENT_DATE + INTERVAL '30 days')
-ORDER BY a."Дата_окончания"
-LIMIT 5;
-`
-	equipmentInRepairQuery = `
-SELECT
-    e."Название",
-    COALESCE(z."Название", '—') AS zone_name,
-    e."Дата_последнего_ТО"
-FROM "Оборудование" e
-LEFT JOIN "Зона" z ON z."id_зоны" = e."id_зоны"
-WHERE e."Статус" = 'На ремонте'
-ORDER BY e."Дата_последнего_ТО" DESC NULLS LAST
-LIMIT 5;
-`
-)
-
 const (
 	dateDisplayFormat = "02.01.2006"
 )
@@ -116,10 +23,12 @@ type recentClientCard struct {
 }
 
 type expiringSubscriptionCard struct {
-	ID     int
-	Client string
-	Tariff string
-	EndsAt string
+	ID        int
+	Client    string
+	Tariff    string
+	TariffID  int
+	EndsAt    string
+	RenewalID int // 0 — ещё не продлён
 }
 
 type equipmentRepairCard struct {
@@ -129,7 +38,10 @@ type equipmentRepairCard struct {
 }
 
 func Dashboard(c *fiber.Ctx) error {
-	db := database.GetDB()
+	// техникам сводка не нужна — сразу к оборудованию
+	if me := currentStaff(c); me != nil && !me.CanSee("dashboard") {
+		return c.Redirect(me.HomePath())
+	}
 	ctx, cancel := withDBTimeout()
 	defer cancel()
 
@@ -153,155 +65,152 @@ func Dashboard(c *fiber.Ctx) error {
 		NoPhoto int
 	}
 
-	var (
-		stats     Stats
-		zones     ZonesStats
-		equipment EquipmentStats
-		warnings  []string
-	)
+	var warnings []string
 
-	if err := db.QueryRowContext(ctx, dashboardStatsQuery).Scan(
-		&stats.Clients,
-		&stats.Trainers,
-		&stats.Subscriptions,
-		&stats.GroupTrainings,
-		&stats.PersonalTrainings,
-		&zones.Active,
-		&zones.Repair,
-		&zones.TotalCapacity,
-		&equipment.Total,
-		&equipment.Working,
-		&equipment.Repair,
-		&equipment.NoPhoto,
-	); err != nil {
+	counts, err := data.Reports().Dashboard(ctx)
+	if err != nil {
 		log.Printf("dashboard stats query failed: %v", err)
 		return c.Status(fiber.StatusInternalServerError).SendString("Не удалось получить статистику: " + err.Error())
 	}
+	stats := Stats{
+		Clients:           counts.Clients,
+		Trainers:          counts.Trainers,
+		Subscriptions:     counts.ActiveSubscriptions,
+		GroupTrainings:    counts.UpcomingGroup,
+		PersonalTrainings: counts.UpcomingPersonal,
+	}
+	zones := ZonesStats{Active: counts.ZonesActive, Repair: counts.ZonesRepair, TotalCapacity: counts.ZonesCapacity}
+	equipment := EquipmentStats{
+		Total:   counts.EquipmentTotal,
+		Working: counts.EquipmentWorking,
+		Repair:  counts.EquipmentRepair,
+		NoPhoto: counts.EquipmentNoPhoto,
+	}
 	stats.Trainings = stats.GroupTrainings + stats.PersonalTrainings
 
-	recentClients, err := loadRecentClients(ctx, db)
+	recentClients, err := loadRecentClients(ctx)
 	if err != nil {
 		log.Printf("recent clients query failed: %v", err)
 		warnings = append(warnings, "Не удалось получить список последних клиентов")
 	}
 
-	expiringSubs, err := loadExpiringSubscriptions(ctx, db)
+	expiringSubs, err := loadExpiringSubscriptions(ctx)
 	if err != nil {
 		log.Printf("expiring subscriptions query failed: %v", err)
 		warnings = append(warnings, "Не удалось получить абонементы с истекающим сроком")
 	}
 
-	equipmentRepairs, err := loadEquipmentInRepair(ctx, db)
+	equipmentRepairs, err := loadEquipmentInRepair(ctx)
 	if err != nil {
 		log.Printf("equipment repairs query failed: %v", err)
 		warnings = append(warnings, "Не удалось получить список оборудования на ремонте")
 	}
 
+	var maintenanceDue []maintenanceDueDTO
+	if due, err := loadMaintenance(ctx, data); err != nil {
+		log.Printf("maintenance due query failed: %v", err)
+		warnings = append(warnings, "Не удалось рассчитать сроки планового ТО")
+	} else {
+		for _, d := range maintenance.Pending(due) {
+			if len(maintenanceDue) == 5 {
+				break
+			}
+			dto := toMaintenanceDueDTO(d)
+			if !d.DueDate.IsZero() {
+				dto.DueDate = d.DueDate.Format(dateDisplayFormat)
+			}
+			maintenanceDue = append(maintenanceDue, dto)
+		}
+	}
+
+	visits, err := data.Visits().Stats(ctx)
+	if err != nil {
+		log.Printf("visit stats query failed: %v", err)
+		warnings = append(warnings, "Не удалось получить статистику посещений")
+		visits = store.VisitStats{}
+	}
+
+	var notifications []models.Notification
+	if currentStaff(c).CanSee("notifications") {
+		notifications, err = data.Notifications().List(ctx, store.NotificationFilter{Pending: true, Limit: 5})
+		if err != nil {
+			log.Printf("notifications query failed: %v", err)
+			warnings = append(warnings, "Не удалось получить уведомления клиентам")
+		}
+	}
+
 	return c.Render("dashboard", fiber.Map{
 		"Title":                 "Главная",
 		"Stats":                 stats,
 		"ZonesStats":            zones,
 		"EquipmentStats":        equipment,
+		"VisitStats":            visits,
 		"RecentClients":         recentClients,
 		"ExpiringSubscriptions": expiringSubs,
 		"EquipmentRepairs":      equipmentRepairs,
+		"MaintenanceDue":        maintenanceDue,
+		"PendingNotifications":  notifications,
 		"DashboardWarnings":     warnings,
+		"ExtraScripts":          templateScript("/static/js/dashboard.js"),
 	})
 }
 
-func loadRecentClients(ctx context.Context, db *sql.DB) ([]recentClientCard, error) {
-	rows, err := db.QueryContext(ctx, recentClientsQuery)
+func loadRecentClients(ctx context.Context) ([]recentClientCard, error) {
+	clients, err := data.Reports().RecentClients(ctx, 5)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var (
-		list       []recentClientCard
-		regDate    time.Time
-		lastTariff sql.NullString
-		lastStatus sql.NullString
-	)
-
-	for rows.Next() {
-		var item recentClientCard
-		if err := rows.Scan(&item.ID, &item.FIO, &regDate, &lastTariff, &lastStatus); err != nil {
-			return nil, err
+	var list []recentClientCard
+	for _, cl := range clients {
+		item := recentClientCard{
+			ID:         cl.ID,
+			FIO:        cl.FIO,
+			Registered: cl.Registered.Format(dateDisplayFormat),
+			LastTariff: cl.LastTariff,
+			LastStatus: cl.LastStatus,
 		}
-		item.Registered = regDate.Format(dateDisplayFormat)
-		if lastTariff.Valid && lastTariff.String != "" {
-			item.LastTariff = lastTariff.String
-		} else {
+		if item.LastTariff == "" {
 			item.LastTariff = "—"
 		}
-		if lastStatus.Valid && lastStatus.String != "" {
-			item.LastStatus = lastStatus.String
-		} else {
+		if item.LastStatus == "" {
 			item.LastStatus = "Нет абонементов"
 		}
 		list = append(list, item)
 	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
 	return list, nil
 }
 
-func loadExpiringSubscriptions(ctx context.Context, db *sql.DB) ([]expiringSubscriptionCard, error) {
-	rows, err := db.QueryContext(ctx, expiringSubscriptionsQuery)
+func loadExpiringSubscriptions(ctx context.Context) ([]expiringSubscriptionCard, error) {
+	subs, err := data.Reports().ExpiringSubscriptions(ctx, 5)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var (
-		list   []expiringSubscriptionCard
-		endsAt time.Time
-	)
-
-	for rows.Next() {
-		var item expiringSubscriptionCard
-		if err := rows.Scan(&item.ID, &item.Client, &item.Tariff, &endsAt); err != nil {
-			return nil, err
-		}
-		item.EndsAt = endsAt.Format(dateDisplayFormat)
-		list = append(list, item)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
+	var list []expiringSubscriptionCard
+	for _, s := range subs {
+		list = append(list, expiringSubscriptionCard{
+			ID:        s.ID,
+			Client:    s.Client,
+			Tariff:    s.Tariff,
+			TariffID:  s.TariffID,
+			EndsAt:    s.EndDate.Format(dateDisplayFormat),
+			RenewalID: s.RenewalID,
+		})
 	}
 	return list, nil
 }
 
-func loadEquipmentInRepair(ctx context.Context, db *sql.DB) ([]equipmentRepairCard, error) {
-	rows, err := db.QueryContext(ctx, equipmentInRepairQuery)
+func loadEquipmentInRepair(ctx context.Context) ([]equipmentRepairCard, error) {
+	items, err := data.Reports().RepairingEquipment(ctx, 5)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var (
-		list        []equipmentRepairCard
-		lastService sql.NullTime
-	)
-
-	for rows.Next() {
-		var item equipmentRepairCard
-		if err := rows.Scan(&item.Name, &item.Zone, &lastService); err != nil {
-			return nil, err
-		}
-		if lastService.Valid {
-			item.LastService = lastService.Time.Format(dateDisplayFormat)
-		} else {
-			item.LastService = "—"
+	var list []equipmentRepairCard
+	for _, e := range items {
+		item := equipmentRepairCard{Name: e.Name, Zone: e.Zone, LastService: "—"}
+		if !e.LastService.IsZero() {
+			item.LastService = e.LastService.Format(dateDisplayFormat)
 		}
 		list = append(list, item)
 	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
 	return list, nil
 }
@@ -2,7 +2,12 @@ package handlers
 
 import (
     "context"
+    "errors"
     "time"
+
+    "fitness-center-manager/internal/audit"
+    "fitness-center-manager/internal/store"
+    "github.com/gofiber/fiber/v2"
 )
 
 const dbTimeout = 5 * time.Second
@@ -11,3 +16,46 @@ func withDBTimeout() (context.Context, context.CancelFunc) {
     return context.WithTimeout(context.Background(), dbTimeout)
 }
 
+// data — хранилище: все данные хэндлеры читают и пишут через него.
+// Задаётся в main через SetStore.
+var data store.Store
+
+// SetStore подключает реализацию хранилища.
+func SetStore(s store.Store) { data = s }
+
+func actorOf(c *fiber.Ctx) audit.Actor {
+    if me := currentStaff(c); me != nil {
+        return audit.Actor{ID: me.ID, Login: me.Login}
+    }
+    return audit.Actor{}
+}
+
+// inTx — транзакция для изменений данных: fn работает в ней со всеми
+// репозиториями, триггеры журнала аудита запишут текущего сотрудника.
+// Все мутации идут через неё.
+func inTx(ctx context.Context, c *fiber.Ctx, fn func(tx store.Store) error) error {
+    return data.InTx(ctx, actorOf(c), fn)
+}
+
+// storeStatus — HTTP-статус для ошибки хранилища.
+func storeStatus(err error) int {
+    switch {
+    case errors.Is(err, store.ErrNotFound):
+        return fiber.StatusNotFound
+    case errors.Is(err, store.ErrDuplicate), errors.Is(err, store.ErrInUse):
+        return fiber.StatusConflict
+    default:
+        return fiber.StatusInternalServerError
+    }
+}
+
+// Healthz — проверка живости для healthcheck контейнера: доступна без
+// входа, 200 — БД отвечает, 503 — нет.
+func Healthz(c *fiber.Ctx) error {
+    ctx, cancel := withDBTimeout()
+    defer cancel()
+    if err := data.Ping(ctx); err != nil {
+        return c.Status(fiber.StatusServiceUnavailable).SendString("db: " + err.Error())
+    }
+    return c.SendString("ok")
+}
@@ -0,0 +1,369 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"fitness-center-manager/internal/enrollment"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// promoteWaitlist — пока в группе есть свободные места, переводит первых из
+// очереди в «Записан» и пишет им уведомление. occ — места, посчитанные под
+// LockOccupancy в той же транзакции.
+func promoteWaitlist(ctx context.Context, tx store.Store, groupID int, occ enrollment.Occupancy) ([]models.GroupTrainingRegistration, error) {
+	var promoted []models.GroupTrainingRegistration
+	for free := occ.Free(); free > 0; free-- {
+		e, err := tx.Trainings().FirstWaiting(ctx, groupID)
+		if errors.Is(err, store.ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Trainings().SetEnrollmentStatus(ctx, e.ID, enrollment.StatusBooked); err != nil {
+			return nil, err
+		}
+		e.Status, e.QueuePosition = enrollment.StatusBooked, 0
+		promoted = append(promoted, e)
+	}
+	if len(promoted) == 0 {
+		return nil, nil
+	}
+	g, err := tx.Trainings().GetGroup(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range promoted {
+		text := fmt.Sprintf("Освободилось место: вы записаны на «%s» %s. Если не сможете прийти, отмените запись.",
+			g.Name, g.StartTime.Format(visitTimeFormat))
+		if _, err := tx.Notifications().Create(ctx, e.ClientID, "Вы записаны из листа ожидания", text); err != nil {
+			return nil, err
+		}
+		log.Printf("🔔 запись #%d (%s) переведена из очереди на «%s»", e.ID, e.ClientName, g.Name)
+	}
+	return promoted, nil
+}
+
+type promotedDTO struct {
+	ID        int    `json:"id"`
+	ClientID  int    `json:"client_id"`
+	ClientFIO string `json:"client_fio"`
+}
+
+func toPromotedDTOs(list []models.GroupTrainingRegistration) []promotedDTO {
+	out := make([]promotedDTO, 0, len(list))
+	for _, e := range list {
+		out = append(out, promotedDTO{e.ID, e.ClientID, e.ClientName})
+	}
+	return out
+}
+
+// changeEnrollment меняет статус записи id на to по таблице переходов
+// (enrollment.Transition) под блокировкой тренировки. Возвращает запись до
+// изменения, итоговый статус (to или «В очереди», если мест нет) и тех, кого
+// перевели из очереди на освободившееся место. groupID > 0 — запись должна
+// относиться к этой тренировке.
+func changeEnrollment(ctx context.Context, tx store.Store, id, groupID int, to string) (models.GroupTrainingRegistration, string, []models.GroupTrainingRegistration, error) {
+	e, err := tx.Trainings().GetEnrollment(ctx, id)
+	if err != nil {
+		return e, "", nil, err
+	}
+	if groupID > 0 && e.GroupTrainingID != groupID {
+		return e, "", nil, errWrongGroup
+	}
+	occ, err := tx.Trainings().LockOccupancy(ctx, e.GroupTrainingID)
+	if err != nil {
+		return e, "", nil, err
+	}
+	// статус мог смениться, пока ждали блокировку
+	if e, err = tx.Trainings().GetEnrollment(ctx, id); err != nil {
+		return e, "", nil, err
+	}
+	if e.Status == to {
+		return e, to, nil, nil
+	}
+	g, err := tx.Trainings().GetGroup(ctx, e.GroupTrainingID)
+	if err != nil {
+		return e, "", nil, err
+	}
+	status, err := enrollment.Transition(e.Status, to, g.StartTime, clubWallNow(), occ)
+	if err != nil {
+		return e, "", nil, err
+	}
+	if err := tx.Trainings().SetEnrollmentStatus(ctx, id, status); err != nil {
+		return e, "", nil, err
+	}
+	if !enrollment.HoldsSeat(e.Status) || enrollment.HoldsSeat(status) {
+		return e, status, nil, nil // место не освободилось
+	}
+	occ.Taken--
+	promoted, err := promoteWaitlist(ctx, tx, e.GroupTrainingID, occ)
+	return e, status, promoted, err
+}
+
+// errWrongGroup — в массовой отметке передана запись другой тренировки.
+var errWrongGroup = errors.New("запись относится к другой тренировке")
+
+// enrollmentChangeError — ответ на ошибку смены статуса записи.
+func enrollmentChangeError(c *fiber.Ctx, err error) error {
+	var rule *enrollment.Error
+	switch {
+	case errors.As(err, &rule):
+		return jsonReject(c, fiber.StatusConflict, string(rule.Reason), rule.Msg, nil)
+	case errors.Is(err, errWrongGroup):
+		return jsonError(c, 400, err.Error(), nil)
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Запись не найдена", nil)
+	}
+	return jsonError(c, 500, "Ошибка изменения записи", err)
+}
+
+// attendanceError — ответ на ошибку массовой отметки в записи id: её номер
+// уходит в failed_id, а в тексте сказано, что откатилась вся отметка.
+func attendanceError(c *fiber.Ctx, id int, err error) error {
+	extra := fiber.Map{"failed_id": id}
+	msg := func(why string) string {
+		return fmt.Sprintf("Запись #%d: %s. Посещение не отмечено ни у кого", id, why)
+	}
+	var rule *enrollment.Error
+	switch {
+	case errors.As(err, &rule):
+		return jsonReject(c, fiber.StatusConflict, string(rule.Reason), msg(rule.Msg), extra)
+	case errors.Is(err, errWrongGroup):
+		return jsonReject(c, 400, "wrong_group", msg(err.Error()), extra)
+	case errors.Is(err, store.ErrNotFound):
+		return jsonReject(c, 404, "not_found", msg("не найдена"), extra)
+	}
+	log.Printf("handler error: запись #%d: %v", id, err)
+	return jsonReject(c, 500, "internal", msg("ошибка изменения записи"), extra)
+}
+
+// enrollmentResult — ответ на смену статуса одной записи.
+func enrollmentResult(c *fiber.Ctx, message, status string, promoted []models.GroupTrainingRegistration) error {
+	if len(promoted) > 0 {
+		message += "; из листа ожидания записан(а): " + promoted[0].ClientName
+	}
+	return jsonOK(c, fiber.Map{"message": message, "status": status, "promoted": toPromotedDTOs(promoted)})
+}
+
+// APIv1CancelEnrollment — POST /api/v1/group-enrollments/:id/cancel: отменить
+// запись. Освободившееся место получает первый из листа ожидания.
+func APIv1CancelEnrollment(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+
+	var (
+		before   models.GroupTrainingRegistration
+		promoted []models.GroupTrainingRegistration
+	)
+	err = inTx(ctx, c, func(tx store.Store) error {
+		before, _, promoted, err = changeEnrollment(ctx, tx, id, 0, enrollment.StatusCancelled)
+		return err
+	})
+	if err != nil {
+		return enrollmentChangeError(c, err)
+	}
+	if before.Status == enrollment.StatusCancelled {
+		return enrollmentResult(c, "Запись уже отменена", enrollment.StatusCancelled, nil)
+	}
+	return enrollmentResult(c, "Запись отменена", enrollment.StatusCancelled, promoted)
+}
+
+// APIv1UpdateEnrollment — PUT /api/v1/group-enrollments/:id (status=…): сменить
+// статус записи по таблице переходов.
+func APIv1UpdateEnrollment(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	var f struct {
+		Status string `json:"status" form:"status"`
+	}
+	if err := c.BodyParser(&f); err != nil {
+		return jsonError(c, 400, "Неверные данные формы", err)
+	}
+	switch f.Status {
+	case enrollment.StatusBooked, enrollment.StatusAttended, enrollment.StatusCancelled:
+	default:
+		return jsonError(c, 400, "Неверный статус записи", nil)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+
+	var (
+		status   string
+		promoted []models.GroupTrainingRegistration
+	)
+	err = inTx(ctx, c, func(tx store.Store) error {
+		_, status, promoted, err = changeEnrollment(ctx, tx, id, 0, f.Status)
+		return err
+	})
+	if err != nil {
+		return enrollmentChangeError(c, err)
+	}
+	if status == enrollment.StatusWaiting {
+		return enrollmentResult(c, "Мест нет — запись возвращена в лист ожидания", status, nil)
+	}
+	return enrollmentResult(c, "Статус записи: "+status, status, promoted)
+}
+
+// APIv1DeleteEnrollment — DELETE /api/v1/group-enrollments/:id: удалить запись
+// (ошибочную). Если она занимала место, его получает первый из очереди.
+func APIv1DeleteEnrollment(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+
+	var promoted []models.GroupTrainingRegistration
+	err = inTx(ctx, c, func(tx store.Store) error {
+		e, err := tx.Trainings().GetEnrollment(ctx, id)
+		if err != nil {
+			return err
+		}
+		occ, err := tx.Trainings().LockOccupancy(ctx, e.GroupTrainingID)
+		if err != nil {
+			return err
+		}
+		if e, err = tx.Trainings().GetEnrollment(ctx, id); err != nil {
+			return err
+		}
+		if err := tx.Trainings().DeleteEnrollment(ctx, id); err != nil {
+			return err
+		}
+		if !enrollment.HoldsSeat(e.Status) {
+			return nil
+		}
+		occ.Taken--
+		promoted, err = promoteWaitlist(ctx, tx, e.GroupTrainingID, occ)
+		return err
+	})
+	if err != nil {
+		return enrollmentChangeError(c, err)
+	}
+	return enrollmentResult(c, "Запись удалена", "", promoted)
+}
+
+// APIv1MarkAttendance — POST /api/v1/group-trainings/:id/attendance: отметить
+// посещение сразу нескольких записей тренировки (enrollment_ids). Всё или
+// ничего: если хоть одну отметить нельзя, ничего не сохраняется.
+func APIv1MarkAttendance(c *fiber.Ctx) error {
+	groupID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || groupID <= 0 {
+		return jsonError(c, 400, "Некорректный id тренировки", err)
+	}
+	var f struct {
+		IDs []int `json:"enrollment_ids" form:"enrollment_ids"`
+	}
+	if err := c.BodyParser(&f); err != nil {
+		return jsonError(c, 400, "Неверные данные формы", err)
+	}
+	if len(f.IDs) == 0 {
+		return jsonError(c, 400, "Не выбрано ни одной записи", nil)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+
+	marked, failed := 0, 0
+	err = inTx(ctx, c, func(tx store.Store) error {
+		g, err := tx.Trainings().GetGroup(ctx, groupID)
+		if err != nil {
+			return err
+		}
+		if err := enrollment.CheckAttendance(g.StartTime, clubWallNow()); err != nil {
+			return err
+		}
+		for _, id := range f.IDs {
+			before, _, _, err := changeEnrollment(ctx, tx, id, groupID, enrollment.StatusAttended)
+			if err != nil {
+				failed = id
+				return err
+			}
+			if before.Status != enrollment.StatusAttended {
+				marked++
+			}
+		}
+		return nil
+	})
+	if err != nil && failed != 0 {
+		return attendanceError(c, failed, err)
+	}
+	if err != nil {
+		return enrollmentChangeError(c, err)
+	}
+	return jsonOK(c, fiber.Map{"message": fmt.Sprintf("Посещение отмечено: %d", marked), "marked": marked})
+}
+
+type notificationDTO struct {
+	ID        int    `json:"id"`
+	ClientID  int    `json:"client_id"`
+	ClientFIO string `json:"client_fio"`
+	Subject   string `json:"subject"`
+	Text      string `json:"text"`
+	CreatedAt string `json:"created_at"`
+	SentAt    string `json:"sent_at,omitempty"`
+}
+
+// APIv1ListNotifications — GET /api/v1/notifications?client_id=&pending=1:
+// уведомления клиентам, новые сверху
+func APIv1ListNotifications(c *fiber.Ctx) error {
+	f := store.NotificationFilter{
+		ClientID: c.QueryInt("client_id"),
+		Pending:  c.Query("pending") == "1",
+		Limit:    c.QueryInt("limit"),
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	list, err := data.Notifications().List(ctx, f)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки уведомлений", err)
+	}
+	out := make([]notificationDTO, 0, len(list))
+	for _, n := range list {
+		d := notificationDTO{
+			ID:        n.ID,
+			ClientID:  n.ClientID,
+			ClientFIO: n.ClientName,
+			Subject:   n.Subject,
+			Text:      n.Text,
+			CreatedAt: n.CreatedAt.Format(visitTimeFormat),
+		}
+		if n.SentAt.Valid {
+			d.SentAt = n.SentAt.Time.Format(visitTimeFormat)
+		}
+		out = append(out, d)
+	}
+	return jsonOK(c, fiber.Map{"notifications": out})
+}
+
+// APIv1MarkNotificationSent — POST /api/v1/notifications/:id/sent: уведомление
+// доставлено (интеграцией или сотрудник сообщил клиенту сам)
+func APIv1MarkNotificationSent(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = data.Notifications().MarkSent(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Уведомление не найдено или уже отмечено", nil)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка сохранения", err)
+	}
+	return jsonOK(c, fiber.Map{"message": "Отмечено как отправленное"})
+}
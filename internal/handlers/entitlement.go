@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"fitness-center-manager/internal/entitlement"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// overrideRequest — форма просит записать вопреки правилам абонемента
+// (override=1 и обязательное основание override_reason); это разрешено
+// только администратору. Пустой reason — исключение не запрошено.
+// При отказе ответ уже отправлен.
+func overrideRequest(c *fiber.Ctx) (reason string, ok bool, err error) {
+	switch c.Query("override", c.FormValue("override")) {
+	case "1", "true", "on":
+	default:
+		return "", true, nil
+	}
+	if !currentStaff(c).IsAdmin() {
+		return "", false, jsonError(c, 403, "Записать вопреки правилам абонемента может только администратор", nil)
+	}
+	reason = strings.TrimSpace(c.FormValue("override_reason"))
+	if reason == "" {
+		return "", false, jsonError(c, 400, "Укажите основание исключения", nil)
+	}
+	if len([]rune(reason)) > 200 {
+		return "", false, jsonError(c, 400, "Основание длиннее 200 символов", nil)
+	}
+	return reason, true, nil
+}
+
+// entitle — даёт ли абонемент sub право на тренировку вида kind в
+// [start, end). При отказе с запрошенным исключением override возвращается
+// основание, которое нужно сохранить в записи; без исключения — ошибка
+// *entitlement.Error. Если правила соблюдены, основание не нужно ("").
+func entitle(ctx context.Context, tx store.Store, sub models.Subscription, kind entitlement.Kind,
+	start, end time.Time, override string) (string, error) {
+	tariff, err := tx.Tariffs().Get(ctx, sub.TariffID)
+	if err != nil {
+		return "", err
+	}
+	freezes, err := tx.Freezes().ListBySubscription(ctx, sub.ID)
+	if err != nil {
+		return "", err
+	}
+	err = entitlement.Check(sub, tariff, freezes, kind, start, end)
+	var rule *entitlement.Error
+	if override != "" && errors.As(err, &rule) {
+		return override, nil
+	}
+	return "", err
+}
+
+// entitlementReject — ответ на отказ entitle (handled == true): 403 с кодом
+// правила и кратким видом абонемента.
+func entitlementReject(c *fiber.Ctx, err error, sub models.Subscription) (bool, error) {
+	var rule *entitlement.Error
+	if !errors.As(err, &rule) {
+		return false, nil
+	}
+	return true, jsonReject(c, fiber.StatusForbidden, string(rule.Reason), rule.Msg,
+		fiber.Map{"subscription": subscriptionBrief(sub), "can_override": currentStaff(c).IsAdmin()})
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"crypto/sha256"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -13,7 +12,9 @@ import (
 	"strings"
 	"time"
 
-	"fitness-center-manager/internal/database"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/repair"
+	"fitness-center-manager/internal/store"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -31,17 +32,22 @@ func dateYMD(t sql.NullTime) string {
 	}
 	return ""
 }
-func nullableTimeArg(t sql.NullTime) any {
-	if t.Valid {
-		return t.Time
-	}
-	return nil
-}
-func nullablePhoto(b []byte) any {
-	if len(b) == 0 {
-		return nil
+
+// equipmentMap — строка оборудования в ключах шаблона/модалки.
+func equipmentMap(e models.Equipment) fiber.Map {
+	return fiber.Map{
+		"ID":              e.ID,
+		"ZoneID":          e.ZoneID,
+		"Name":            e.Name,
+		"PurchaseDate":    dateYMD(e.PurchaseDate),
+		"LastServiceDate": dateYMD(e.LastServiceDate),
+		"Status":          e.Status,
+		"HasPhoto":        e.HasPhoto,
+		"ZoneName":        e.ZoneName,
+		"Type":            e.Type,
+		"UsageHours":      e.UsageHours,
+		"ReplacementCost": e.ReplacementCost,
 	}
-	return b
 }
 
 // ---------------- Нормализация статусов ----------------
@@ -50,6 +56,8 @@ func normEqStatus(s string) string {
 	switch strings.TrimSpace(s) {
 	case "Исправен", "Работает", "исправен":
 		return "Исправен"
+	case "Требует ТО":
+		return "Требует ТО"
 	case "На ремонте", "ремонт":
 		return "На ремонте"
 	case "Списан", "Списано":
@@ -75,29 +83,12 @@ func normRepairStatus(s string) string {
 // ---------------- API: зоны для селекта ----------------
 
 func GetZonesForSelect(c *fiber.Ctx) error {
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    rows, err := db.QueryContext(ctx, `SELECT "id_зоны","Название" FROM "Зона" ORDER BY "id_зоны"`)
+    list, err := data.Zones().Options(ctx)
     if err != nil {
         return jsonError(c, 500, "Ошибка чтения зон", err)
     }
-    defer rows.Close()
-
-	type z struct {
-		ID   int    `json:"id"`
-		Name string `json:"name"`
-	}
-	var list []z
-	for rows.Next() {
-		var it z
-		if err := rows.Scan(&it.ID, &it.Name); err == nil {
-			list = append(list, it)
-		}
-	}
-    if err := rows.Err(); err != nil {
-        return jsonError(c, 500, "Ошибка курсора зон", err)
-    }
     return jsonOK(c, fiber.Map{"zones": list})
 }
 
@@ -108,65 +99,25 @@ func GetEquipmentByID(c *fiber.Ctx) error {
     if err != nil || id <= 0 {
         return jsonError(c, 400, "Некорректный id", err)
     }
-    db := database.GetDB()
-    var (
-        zoneID                 int
-        name, status, zoneName string
-        purchase, lastTO       sql.NullTime
-    )
     ctx, cancel := withDBTimeout()
     defer cancel()
-    err = db.QueryRowContext(ctx, `
-        SELECT e."id_зоны",
-               e."Название",
-               e."Дата_покупки",
-               e."Дата_последнего_ТО",
-               e."Статус",
-               z."Название" AS zone_name
-        FROM "Оборудование" e
-        JOIN "Зона" z ON z."id_зоны" = e."id_зоны"
-        WHERE e."id_оборудования"=$1
-    `, id).Scan(&zoneID, &name, &purchase, &lastTO, &status, &zoneName)
-    if errors.Is(err, sql.ErrNoRows) {
+    e, err := data.Equipment().Get(ctx, id)
+    if errors.Is(err, store.ErrNotFound) {
         return jsonError(c, 404, "Оборудование не найдено", nil)
     }
     if err != nil {
         return jsonError(c, 500, "Ошибка БД", err)
     }
-    return jsonOK(c, fiber.Map{
-        "item": fiber.Map{
-            "ID":              id,
-            "ZoneID":          zoneID,
-            "Name":            name,
-            "PurchaseDate":    dateYMD(purchase),
-            "LastServiceDate": dateYMD(lastTO),
-            "Status":          status,
-            "ZoneName":        zoneName,
-        },
-    })
+    return jsonOK(c, fiber.Map{"item": equipmentMap(e)})
 }
 
 // ---------------- Страница оборудования ----------------
 
 func GetEquipmentPage(c *fiber.Ctx) error {
-    db := database.GetDB()
-
     // Оборудование
     ctx, cancel := withDBTimeout()
     defer cancel()
-    rows, err := db.QueryContext(ctx, `
-        SELECT e."id_оборудования",
-               e."id_зоны",
-               e."Название",
-               e."Дата_покупки",
-               e."Дата_последнего_ТО",
-               e."Статус",
-               (e."Фото" IS NOT NULL) AS has_photo,
-               z."Название" AS zone_name
-        FROM "Оборудование" e
-        JOIN "Зона" z ON z."id_зоны" = e."id_зоны"
-        ORDER BY e."id_оборудования"
-    `)
+    list, err := data.Equipment().List(ctx, store.EquipmentFilter{})
 	if err != nil {
 		log.Printf("equipment list error: %v", err)
 		return c.Render("equipment", fiber.Map{
@@ -177,79 +128,51 @@ func GetEquipmentPage(c *fiber.Ctx) error {
 			"ExtraScripts": templateScript("/static/js/equipment.js"),
 		})
 	}
-	defer rows.Close()
 
 	var items []fiber.Map
-	for rows.Next() {
-		var (
-			id, zoneID             int
-			name, status, zoneName string
-			hasPhoto               bool
-			purchase, lastTO       sql.NullTime
-		)
-		if err := rows.Scan(&id, &zoneID, &name, &purchase, &lastTO, &status, &hasPhoto, &zoneName); err != nil {
-			continue
+	for _, e := range list {
+		items = append(items, equipmentMap(e))
+	}
+
+	// Заявки на ремонт: незакрытые по сроку, затем последние закрытые;
+	// их и ТО видят только администратор и техник
+	var (
+		repairs   []fiber.Map
+		assignees []store.StaffMember
+	)
+	if currentStaff(c).CanSee("repairs") {
+		latest, err := data.Repairs().List(ctx, store.RepairFilter{Limit: 20})
+		if err != nil {
+			log.Printf("repairs list error: %v", err)
 		}
-		items = append(items, fiber.Map{
-			"ID":              id,
-			"ZoneID":          zoneID,
-			"Name":            name,
-			"PurchaseDate":    dateYMD(purchase),
-			"LastServiceDate": dateYMD(lastTO),
-			"Status":          status,
-			"HasPhoto":        hasPhoto,
-			"ZoneName":        zoneName,
-		})
-	}
-	_ = rows.Err()
-
-	// Последние заявки на ремонт
-    r2, err := db.QueryContext(ctx, `
-        SELECT r."id_заявки",
-               r."id_оборудования",
-               r."Дата_создания",
-               r."Описание_проблемы",
-               r."Статус",
-               r."Приоритет",
-               (r."Фото" IS NOT NULL) AS has_photo,
-               e."Название" AS eq_name
-        FROM "Заявка_на_ремонт" r
-        JOIN "Оборудование" e ON e."id_оборудования" = r."id_оборудования"
-        ORDER BY r."id_заявки" DESC
-        LIMIT 10
-    `)
-	var repairs []fiber.Map
-	if err == nil {
-		defer r2.Close()
-		for r2.Next() {
-			var (
-				id, eqID        int
-				desc, status    string
-				priority        string
-				hasPhoto        bool
-				created         time.Time
-				eqName          string
-			)
-			if err := r2.Scan(&id, &eqID, &created, &desc, &status, &priority, &hasPhoto, &eqName); err != nil {
-				continue
-			}
+		now := time.Now()
+		for _, r := range latest {
 			repairs = append(repairs, fiber.Map{
-				"ID":            id,
-				"EquipmentID":   eqID,
-				"EquipmentName": eqName,
-				"CreatedAt":     created,
-				"Description":   desc,
-				"Status":        status,
-				"Priority":      priority,
-				"HasPhoto":      hasPhoto,
+				"ID":            r.ID,
+				"EquipmentID":   r.EquipmentID,
+				"EquipmentName": r.EquipmentName,
+				"CreatedAt":     r.CreateDate,
+				"Description":   r.ProblemDesc,
+				"Status":        r.Status,
+				"Priority":      r.Priority,
+				"HasPhoto":      r.HasPhoto,
+				"AssigneeID":    r.AssigneeID,
+				"AssigneeName":  r.AssigneeName,
+				"Deadline":      r.Deadline,
+				"Overdue":       repair.Overdue(r, now),
 			})
 		}
+		assignees, err = repairAssignees()
+		if err != nil {
+			log.Printf("repair assignees error: %v", err)
+		}
 	}
 
 	return c.Render("equipment", fiber.Map{
 		"Title":        "Оборудование",
 		"Items":        items,
 		"Repairs":      repairs,
+		"Assignees":    assignees,
 		"ExtraScripts": templateScript("/static/js/equipment.js"),
 	})
 }
@@ -263,6 +186,8 @@ func CreateEquipment(c *fiber.Ctx) error {
 		Purchase string `form:"purchase_date"`     // YYYY-MM-DD
 		LastTO   string `form:"last_service_date"` // YYYY-MM-DD
 		Status   string `form:"status"`
+		Type     string `form:"type"`
+		Usage    string `form:"usage_hours"`
 	}
 	var f formT
 	if err := c.BodyParser(&f); err != nil {
@@ -288,15 +213,24 @@ func CreateEquipment(c *fiber.Ctx) error {
 		}
 	}
 
-	db := database.GetDB()
+    typ, usage, ok, err := equipmentExtra(c, f.Type, f.Usage)
+    if !ok {
+        return err
+    }
+    replace, ok, err := amountField(c, "replacement_cost", "Стоимость замены", 0)
+    if !ok {
+        return err
+    }
+    in := store.EquipmentInput{ZoneID: f.ZoneID, Name: f.Name, PurchaseDate: purchase, LastServiceDate: lastTO, Status: f.Status,
+        Type: typ, UsageHours: usage, ReplacementCost: replace}
     var id int
     ctx, cancel := withDBTimeout()
     defer cancel()
-    err := db.QueryRowContext(ctx, `
-        INSERT INTO "Оборудование" ("id_зоны","Название","Дата_покупки","Дата_последнего_ТО","Статус")
-        VALUES ($1,$2,$3,$4,$5)
-        RETURNING "id_оборудования"
-    `, f.ZoneID, f.Name, nullableTimeArg(purchase), nullableTimeArg(lastTO), f.Status).Scan(&id)
+    err = inTx(ctx, c, func(tx store.Store) error {
+        var err error
+        id, err = tx.Equipment().Create(ctx, in)
+        return err
+    })
     if err != nil {
         return jsonError(c, 500, "Ошибка сохранения", err)
     }
@@ -316,6 +250,8 @@ func UpdateEquipment(c *fiber.Ctx) error {
 		Purchase string `form:"purchase_date"`
 		LastTO   string `form:"last_service_date"`
 		Status   string `form:"status"`
+		Type     string `form:"type"`
+		Usage    string `form:"usage_hours"`
 	}
 	var f formT
     if err := c.BodyParser(&f); err != nil {
@@ -338,23 +274,62 @@ func UpdateEquipment(c *fiber.Ctx) error {
 		}
 	}
 
-	db := database.GetDB()
+    typ, usage, ok, err := equipmentExtra(c, f.Type, f.Usage)
+    if !ok {
+        return err
+    }
+    replace, ok, err := amountField(c, "replacement_cost", "Стоимость замены", 0)
+    if !ok {
+        return err
+    }
+    in := store.EquipmentInput{ZoneID: f.ZoneID, Name: f.Name, PurchaseDate: purchase, LastServiceDate: lastTO, Status: f.Status,
+        Type: typ, UsageHours: usage, ReplacementCost: replace}
     ctx, cancel := withDBTimeout()
     defer cancel()
-    res, err := db.ExecContext(ctx, `
-        UPDATE "Оборудование"
-        SET "id_зоны"=$2, "Название"=$3, "Дата_покупки"=$4, "Дата_последнего_ТО"=$5, "Статус"=$6
-        WHERE "id_оборудования"=$1
-    `, id, f.ZoneID, f.Name, nullableTimeArg(purchase), nullableTimeArg(lastTO), f.Status)
+    err = inTx(ctx, c, func(tx store.Store) error {
+        if err := tx.Equipment().Update(ctx, id, in); err != nil {
+            return err
+        }
+        // с equipment.flag_overdue_maintenance исправным оборудование
+        // с просроченным ТО становится только после отметки о ТО
+        if !flagOverdueMaintenance || in.Status != "Исправен" {
+            return nil
+        }
+        overdue, err := maintenanceOverdue(ctx, tx, id)
+        if err == nil && overdue {
+            err = errMaintenanceOverdue
+        }
+        return err
+    })
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 404, "Оборудование не найдено", nil)
+    }
+    if errors.Is(err, errMaintenanceOverdue) {
+        return jsonError(c, 409, "ТО просрочено: сначала отметьте проведённое ТО, статус «Исправен» вернётся сам", nil)
+    }
     if err != nil {
         return jsonError(c, 500, "Ошибка обновления", err)
     }
-    if n, _ := res.RowsAffected(); n == 0 {
-        return jsonError(c, 404, "Оборудование не найдено", nil)
-    }
     return jsonOK(c, fiber.Map{"message": "Оборудование обновлено"})
 }
 
+// equipmentExtra — тип и наработка из формы оборудования.
+func equipmentExtra(c *fiber.Ctx, typ, usage string) (string, float64, bool, error) {
+	typ = strings.TrimSpace(typ)
+	if len([]rune(typ)) > 50 {
+		return "", 0, false, jsonError(c, 400, "Тип — до 50 символов", nil)
+	}
+	usage = strings.TrimSpace(usage)
+	if usage == "" {
+		return typ, 0, true, nil
+	}
+	h, err := strconv.ParseFloat(strings.Replace(usage, ",", ".", 1), 64)
+	if err != nil || h < 0 {
+		return "", 0, false, jsonError(c, 400, "Наработка — неотрицательное число", nil)
+	}
+	return typ, h, true, nil
+}
+
 // ---------------- Delete ----------------
 
 func DeleteEquipment(c *fiber.Ctx) error {
@@ -362,17 +337,24 @@ func DeleteEquipment(c *fiber.Ctx) error {
     if err != nil || id <= 0 {
         return jsonError(c, 400, "Некорректный id", err)
     }
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    res, err := db.ExecContext(ctx, `DELETE FROM "Оборудование" WHERE "id_оборудования"=$1`, id)
-    if err != nil {
-        // возможно, FK из "Заявка_на_ремонт"
-        return jsonError(c, 500, "Ошибка удаления", err)
-    }
-    if n, _ := res.RowsAffected(); n == 0 {
+    var old store.Photo
+    err = inTx(ctx, c, func(tx store.Store) error {
+        if old, err = tx.Equipment().Photo(ctx, id); err != nil {
+            return err
+        }
+        return tx.Equipment().Delete(ctx, id)
+    })
+    switch {
+    case errors.Is(err, store.ErrNotFound):
         return jsonError(c, 404, "Оборудование не найдено", nil)
+    case errors.Is(err, store.ErrInUse):
+        return jsonError(c, 409, "Невозможно удалить оборудование: есть заявки на ремонт", err)
+    case err != nil:
+        return jsonError(c, 500, "Ошибка удаления", err)
     }
+    releasePhoto(old.Key)
     return jsonOK(c, fiber.Map{"message": "Удалено"})
 }
 
@@ -415,13 +397,31 @@ func UploadEquipmentPhoto(c *fiber.Ctx) error {
         return jsonError(c, 400, "Разрешены JPEG/PNG/WebP", nil)
     }
 
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    _, err = db.ExecContext(ctx, `UPDATE "Оборудование" SET "Фото"=$2 WHERE "id_оборудования"=$1`, id, buf)
+    key, ok, err := savePhoto(ctx, c, buf)
+    if !ok {
+        return err
+    }
+    var old store.Photo
+    err = inTx(ctx, c, func(tx store.Store) error {
+        if old, err = tx.Equipment().Photo(ctx, id); err != nil {
+            return err
+        }
+        return tx.Equipment().SetPhoto(ctx, id, key)
+    })
+    if err != nil {
+        releasePhoto(key)
+    }
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 404, "Оборудование не найдено", nil)
+    }
     if err != nil {
         return jsonError(c, 500, "DB: ошибка сохранения", err)
     }
+    if old.Key != key {
+        releasePhoto(old.Key)
+    }
     return jsonOK(c, fiber.Map{"message": "Фото загружено"})
 }
 
@@ -430,33 +430,16 @@ func GetEquipmentPhoto(c *fiber.Ctx) error {
 	if err != nil || id <= 0 {
 		return c.Status(400).SendString("Некорректный id")
 	}
-    db := database.GetDB()
-    var img []byte
     ctx, cancel := withDBTimeout()
     defer cancel()
-    err = db.QueryRowContext(ctx, `SELECT "Фото" FROM "Оборудование" WHERE "id_оборудования"=$1`, id).Scan(&img)
-	if errors.Is(err, sql.ErrNoRows) {
+    p, err := data.Equipment().Photo(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
 		return c.Status(404).SendString("Оборудование не найдено")
 	}
 	if err != nil {
 		return c.Status(500).SendString("DB: ошибка чтения")
 	}
-	if len(img) == 0 {
-		return c.Status(404).SendString("Фото отсутствует")
-	}
-	head := img
-	if len(head) > 512 {
-		head = head[:512]
-	}
-	ct := http.DetectContentType(head)
-	if !strings.HasPrefix(ct, "image/") {
-		ct = "application/octet-stream"
-	}
-	c.Set("Content-Type", ct)
-	sum := sha256.Sum256(img)
-	c.Set("ETag", fmt.Sprintf(`W/"%x"`, sum[:16]))
-	c.Set("Cache-Control", "public, max-age=3600")
-	return c.Send(img)
+	return sendPhoto(c, p)
 }
 
 func DeleteEquipmentPhoto(c *fiber.Ctx) error {
@@ -464,16 +447,22 @@ func DeleteEquipmentPhoto(c *fiber.Ctx) error {
 	if err != nil || id <= 0 {
         return jsonError(c, 400, "Некорректный id", err)
 	}
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    res, err := db.ExecContext(ctx, `UPDATE "Оборудование" SET "Фото"=NULL WHERE "id_оборудования"=$1`, id)
+    var old store.Photo
+    err = inTx(ctx, c, func(tx store.Store) error {
+        if old, err = tx.Equipment().Photo(ctx, id); err != nil {
+            return err
+        }
+        return tx.Equipment().SetPhoto(ctx, id, "")
+    })
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 404, "Оборудование не найдено", nil)
+    }
     if err != nil {
         return jsonError(c, 500, "DB: ошибка обновления", err)
     }
-    if n, _ := res.RowsAffected(); n == 0 {
-        return jsonError(c, 404, "Оборудование не найдено", nil)
-    }
+    releasePhoto(old.Key)
     return jsonOK(c, fiber.Map{"message": "Фото удалено"})
 }
 
@@ -486,9 +475,7 @@ func CreateRepairRequest(c *fiber.Ctx) error {
 	if eqID <= 0 || strings.TrimSpace(desc) == "" {
         return jsonError(c, 400, "Укажите оборудование и описание", nil)
 	}
-	if priority == "" {
-		priority = "Средний"
-	}
+	priority = normRepairPriority(priority)
 
 	// опциональное фото
 	var photo []byte
@@ -522,22 +509,41 @@ func CreateRepairRequest(c *fiber.Ctx) error {
 		photo = buf
 	}
 
-	// ВАЖНО: не указываем колонку "Статус" — сработает DEFAULT в БД, который соответствует CHECK
-    db := database.GetDB()
+    // статус заявки ставит БД; оборудование переводим в "На ремонте"
+    in := store.RepairInput{EquipmentID: eqID, Description: desc, Priority: priority,
+        Deadline: repairSLA.Deadline(priority, time.Now())}
     var id int
     ctx, cancel := withDBTimeout()
     defer cancel()
-    err := db.QueryRowContext(ctx, `
-        INSERT INTO "Заявка_на_ремонт"
-        ("id_оборудования","Дата_создания","Описание_проблемы","Приоритет","Фото")
-        VALUES ($1, NOW(), $2, $3, $4)
-        RETURNING "id_заявки"
-    `, eqID, desc, priority, nullablePhoto(photo)).Scan(&id)
+    if photo != nil {
+        key, ok, err := savePhoto(ctx, c, photo)
+        if !ok {
+            return err
+        }
+        in.PhotoKey = key
+    }
+    staffID, staffName := repairActor(c)
+    err := inTx(ctx, c, func(tx store.Store) error {
+        var err error
+        if id, err = tx.Repairs().Create(ctx, in); err != nil {
+            return err
+        }
+        err = tx.Repairs().AddEvent(ctx, models.RepairEvent{RepairID: id, Status: repair.StatusOpen,
+            Comment: "Заявка создана", StaffID: staffID, StaffName: staffName})
+        if err != nil {
+            return err
+        }
+        return syncRepairEquipment(ctx, tx, eqID)
+    })
+    if err != nil {
+        releasePhoto(in.PhotoKey)
+    }
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 404, "Оборудование не найдено", nil)
+    }
     if err != nil {
         return jsonError(c, 500, "Ошибка создания заявки", err)
     }
-    // Перевести оборудование в статус "На ремонте"
-    _, _ = db.ExecContext(ctx, `UPDATE "Оборудование" SET "Статус"=$2 WHERE "id_оборудования"=$1`, eqID, "На ремонте")
     return jsonOK(c, fiber.Map{"message": "Заявка создана", "id": id})
 }
 
@@ -547,21 +553,47 @@ func DeleteRepairRequest(c *fiber.Ctx) error {
 	if err != nil || id <= 0 {
         return jsonError(c, 400, "Некорректный id", err)
 	}
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    res, err := db.ExecContext(ctx, `DELETE FROM "Заявка_на_ремонт" WHERE "id_заявки"=$1`, id)
+    var old store.Photo
+    err = inTx(ctx, c, func(tx store.Store) error {
+        r, err := tx.Repairs().Get(ctx, id)
+        if err != nil {
+            return err
+        }
+        // списанные на заявку запчасти возвращаются на склад
+        usage, err := tx.Parts().Usage(ctx, id)
+        if err != nil {
+            return err
+        }
+        for _, u := range usage {
+            if _, err := tx.Parts().Return(ctx, u.ID); err != nil {
+                return err
+            }
+        }
+        if old, err = tx.Repairs().Photo(ctx, id); err != nil {
+            return err
+        }
+        if err := tx.Repairs().Delete(ctx, id); err != nil {
+            return err
+        }
+        return syncRepairEquipment(ctx, tx, r.EquipmentID)
+    })
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 404, "Заявка не найдена", nil)
+    }
     if err != nil {
         return jsonError(c, 500, "Ошибка удаления", err)
     }
-    if n, _ := res.RowsAffected(); n == 0 {
-        return jsonError(c, 404, "Заявка не найдена", nil)
-    }
+    releasePhoto(old.Key)
     return jsonOK(c, fiber.Map{"message": "Заявка удалена"})
 }
 
 
 // ---------- Обновить заявку на ремонт ----------
+// Смена статуса идёт по порядку работы с заявкой (repair) и пишется в
+// историю с комментарием; срок по SLA пересчитывается при смене
+// приоритета. Статус оборудования меняется в той же транзакции.
 func UpdateRepairRequest(c *fiber.Ctx) error {
     id, err := strconv.Atoi(c.Params("id"))
     if err != nil || id <= 0 {
@@ -572,6 +604,7 @@ func UpdateRepairRequest(c *fiber.Ctx) error {
         Description string `form:"description"`
         Status      string `form:"status"`
         Priority    string `form:"priority"`
+        Comment     string `form:"comment"`
     }
     var f formT
     if err := c.BodyParser(&f); err != nil {
@@ -581,55 +614,43 @@ func UpdateRepairRequest(c *fiber.Ctx) error {
     if f.Description == "" {
         return jsonError(c, 400, "Описание обязательно", nil)
     }
-    // нормализуем статус заявки и приоритет
-    st := normRepairStatus(f.Status)
-    pr := strings.TrimSpace(f.Priority)
-    switch pr {
-    case "Низкий", "Средний", "Высокий":
-    default:
-        pr = "Средний"
-    }
-
-    // собираем динамический UPDATE
-    sets := []string{"\"Описание_проблемы\"=$2", "\"Статус\"=$3", "\"Приоритет\"=$4"}
-    args := []any{id, f.Description, st, pr}
-    if f.EquipmentID > 0 {
-        sets = append(sets, "\"id_оборудования\"=$5")
-        args = append(args, f.EquipmentID)
-    }
-    sqlUpd := "UPDATE \"Заявка_на_ремонт\" SET " + strings.Join(sets, ", ") + " WHERE \"id_заявки\"=$1"
+    pr := normRepairPriority(f.Priority)
 
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    res, err := db.ExecContext(ctx, sqlUpd, args...)
-    if err != nil {
-        return jsonError(c, 500, "Ошибка обновления заявки", err)
-    }
-    if n, _ := res.RowsAffected(); n == 0 {
-        return jsonError(c, 404, "Заявка не найдена", nil)
-    }
-    // Обновление статуса оборудования в зависимости от статуса заявки
-    // Определим equipmentID: если не передан — прочитаем из БД
-    eqID := f.EquipmentID
-    if eqID <= 0 {
-        _ = db.QueryRowContext(ctx, `SELECT "id_оборудования" FROM "Заявка_на_ремонт" WHERE "id_заявки"=$1`, id).Scan(&eqID)
-    }
-    if eqID > 0 {
-        if st == "Открыта" || st == "В работе" {
-            // Переводим оборудование в "На ремонте"
-            _, _ = db.ExecContext(ctx, `UPDATE "Оборудование" SET "Статус"='На ремонте' WHERE "id_оборудования"=$1`, eqID)
-        } else if st == "Закрыта" {
-            // Если нет других активных заявок, вернуть статус в "Исправен"
-            var cnt int
-            _ = db.QueryRowContext(ctx, `
-                SELECT COUNT(*) FROM "Заявка_на_ремонт"
-                WHERE "id_оборудования"=$1 AND "Статус" IN ('Открыта','В работе')
-            `, eqID).Scan(&cnt)
-            if cnt == 0 {
-                _, _ = db.ExecContext(ctx, `UPDATE "Оборудование" SET "Статус"='Исправен' WHERE "id_оборудования"=$1`, eqID)
-            }
+    err = inTx(ctx, c, func(tx store.Store) error {
+        r, err := tx.Repairs().Get(ctx, id)
+        if err != nil {
+            return err
+        }
+        in := store.RepairUpdate{EquipmentID: f.EquipmentID, Description: f.Description, Priority: pr, Deadline: r.Deadline}
+        if pr != r.Priority {
+            in.Deadline = repairSLA.Deadline(pr, r.CreateDate)
+        }
+        if err := tx.Repairs().Update(ctx, id, in); err != nil {
+            return err
+        }
+        oldEquipment := r.EquipmentID
+        if f.EquipmentID > 0 {
+            r.EquipmentID = f.EquipmentID
+        }
+        st := r.Status
+        if strings.TrimSpace(f.Status) != "" {
+            st = normRepairStatus(f.Status)
+        }
+        if err := changeRepairStatus(ctx, tx, c, r, st, strings.TrimSpace(f.Comment)); err != nil {
+            return err
+        }
+        if oldEquipment == r.EquipmentID {
+            return syncRepairEquipment(ctx, tx, r.EquipmentID)
+        }
+        if err := syncRepairEquipment(ctx, tx, oldEquipment); err != nil {
+            return err
         }
+        return syncRepairEquipment(ctx, tx, r.EquipmentID)
+    })
+    if err != nil {
+        return repairError(c, err, "Ошибка обновления заявки")
     }
     return jsonOK(c, fiber.Map{"message": "Заявка обновлена"})
 }
@@ -669,42 +690,42 @@ func UploadRepairPhoto(c *fiber.Ctx) error {
         return jsonError(c, fiber.StatusBadRequest, "Разрешены JPEG/PNG/WebP", nil)
     }
 
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    res, err := db.ExecContext(ctx, `UPDATE "Заявка_на_ремонт" SET "Фото"=$2 WHERE "id_заявки"=$1`, id, buf)
+    key, ok, err := savePhoto(ctx, c, buf)
+    if !ok {
+        return err
+    }
+    var old store.Photo
+    err = inTx(ctx, c, func(tx store.Store) error {
+        if old, err = tx.Repairs().Photo(ctx, id); err != nil {
+            return err
+        }
+        return tx.Repairs().SetPhoto(ctx, id, key)
+    })
     if err != nil {
-        return jsonError(c, fiber.StatusInternalServerError, "DB: ошибка сохранения", err)
+        releasePhoto(key)
     }
-    if n, _ := res.RowsAffected(); n == 0 {
+    if errors.Is(err, store.ErrNotFound) {
         return jsonError(c, fiber.StatusNotFound, "Заявка не найдена", nil)
     }
+    if err != nil {
+        return jsonError(c, fiber.StatusInternalServerError, "DB: ошибка сохранения", err)
+    }
+    if old.Key != key {
+        releasePhoto(old.Key)
+    }
     return jsonOK(c, fiber.Map{"message": "Фото загружено"})
 }
 
 
 func GetLatestRepairs(c *fiber.Ctx) error {
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    rows, err := db.QueryContext(ctx, `
-        SELECT r."id_заявки",
-               r."id_оборудования",
-               r."Дата_создания",
-               r."Описание_проблемы",
-               r."Статус",
-               r."Приоритет",
-               (r."Фото" IS NOT NULL) AS has_photo,
-               e."Название" AS eq_name
-        FROM "Заявка_на_ремонт" r
-        JOIN "Оборудование" e ON e."id_оборудования" = r."id_оборудования"
-        ORDER BY r."id_заявки" DESC
-        LIMIT 10
-    `)
+    latest, err := data.Repairs().Latest(ctx, 10)
     if err != nil {
         return jsonError(c, 500, "Ошибка загрузки заявок", err)
     }
-	defer rows.Close()
 	type row struct {
 		ID            int       `json:"id"`
 		EquipmentID   int       `json:"equipment_id"`
@@ -715,12 +736,8 @@ func GetLatestRepairs(c *fiber.Ctx) error {
 		HasPhoto      bool      `json:"has_photo"`
 	}
 	var list []row
-	for rows.Next() {
-		var r row
-		var desc string
-		if err := rows.Scan(&r.ID, &r.EquipmentID, &r.CreatedAt, &desc, &r.Status, &r.Priority, &r.HasPhoto, &r.EquipmentName); err == nil {
-			list = append(list, r)
-		}
+	for _, r := range latest {
+		list = append(list, row{r.ID, r.EquipmentID, r.EquipmentName, r.CreateDate, r.Status, r.Priority, r.HasPhoto})
 	}
     return jsonOK(c, fiber.Map{"repairs": list})
 }
@@ -730,86 +747,46 @@ func GetRepairPhoto(c *fiber.Ctx) error {
 	if err != nil || id <= 0 {
 		return c.Status(400).SendString("Некорректный id")
 	}
-    db := database.GetDB()
-    var img []byte
     ctx, cancel := withDBTimeout()
     defer cancel()
-    err = db.QueryRowContext(ctx, `SELECT "Фото" FROM "Заявка_на_ремонт" WHERE "id_заявки"=$1`, id).Scan(&img)
-	if errors.Is(err, sql.ErrNoRows) {
+    p, err := data.Repairs().Photo(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
 		return c.Status(404).SendString("Заявка не найдена")
 	}
 	if err != nil {
 		return c.Status(500).SendString("DB: ошибка чтения")
 	}
-	if len(img) == 0 {
-		return c.Status(404).SendString("Фото отсутствует")
-	}
-	head := img
-	if len(head) > 512 {
-		head = head[:512]
-	}
-	ct := http.DetectContentType(head)
-	if !strings.HasPrefix(ct, "image/") {
-		ct = "application/octet-stream"
-	}
-	c.Set("Content-Type", ct)
-	sum := sha256.Sum256(img)
-	c.Set("ETag", fmt.Sprintf(`W/"%x"`, sum[:16]))
-	c.Set("Cache-Control", "public, max-age=3600")
-	return c.Send(img)
+	return sendPhoto(c, p)
 }
 
 // ---------------- API v1: Список оборудования (JSON) ----------------
 
 func APIv1ListEquipment(c *fiber.Ctx) error {
-    db := database.GetDB()
-
     // простые фильтры (необязательные)
-    zoneID := strings.TrimSpace(c.Query("zone_id"))
-    status := strings.TrimSpace(c.Query("status"))
-    hasPhoto := c.Query("has_photo") // "1" или "0"
-
-    where := []string{}
-    args := []any{}
-    nextPH := func() string { return "$" + strconv.Itoa(len(args)+1) }
-
-    if zoneID != "" {
-        where = append(where, `e."id_зоны" = `+nextPH()+`::int`)
-        args = append(args, zoneID)
-    }
-    if status != "" {
-        where = append(where, `e."Статус" = `+nextPH())
-        args = append(args, status)
-    }
-    if hasPhoto == "1" {
-        where = append(where, `e."Фото" IS NOT NULL`)
-    } else if hasPhoto == "0" {
-        where = append(where, `e."Фото" IS NULL`)
-    }
-
-    query := `
-        SELECT e."id_оборудования",
-               e."id_зоны",
-               e."Название",
-               e."Дата_покупки",
-               e."Дата_последнего_ТО",
-               e."Статус",
-               (e."Фото" IS NOT NULL) AS has_photo,
-               z."Название" AS zone_name
-        FROM "Оборудование" e
-        JOIN "Зона" z ON z."id_зоны" = e."id_зоны"`
-    if len(where) > 0 {
-        query += " WHERE " + strings.Join(where, " AND ")
-    }
-    query += ` ORDER BY e."id_оборудования"`
+    var f store.EquipmentFilter
+    if z := strings.TrimSpace(c.Query("zone_id")); z != "" {
+        id, err := strconv.Atoi(z)
+        if err != nil {
+            return jsonError(c, 400, "Некорректный id зоны", err)
+        }
+        f.ZoneID = id
+    }
+    f.Status = strings.TrimSpace(c.Query("status"))
+    switch c.Query("has_photo") { // "1" или "0"
+    case "1":
+        yes := true
+        f.HasPhoto = &yes
+    case "0":
+        no := false
+        f.HasPhoto = &no
+    }
 
     ctx, cancel := withDBTimeout()
     defer cancel()
-    rows, err := db.QueryContext(ctx, query, args...)
+    equipment, err := data.Equipment().List(ctx, f)
     if err != nil {
         return jsonError(c, 500, "Ошибка загрузки оборудования", err)
     }
-    defer rows.Close()
 
     type item struct {
         ID              int    `json:"id"`
@@ -820,32 +797,25 @@ func APIv1ListEquipment(c *fiber.Ctx) error {
         Status          string `json:"status"`
         HasPhoto        bool   `json:"has_photo"`
         ZoneName        string `json:"zone_name"`
+        Type            string  `json:"type"`
+        UsageHours      float64 `json:"usage_hours"`
+        ReplacementCost float64 `json:"replacement_cost"` // 0 — не задана
     }
     var list []item
-    for rows.Next() {
-        var (
-            id, zoneIDv       int
-            name, statusV     string
-            zoneName          string
-            hasPhotoV         bool
-            purchase, lastTO  sql.NullTime
-        )
-        if err := rows.Scan(&id, &zoneIDv, &name, &purchase, &lastTO, &statusV, &hasPhotoV, &zoneName); err != nil {
-            return jsonError(c, 500, "Ошибка чтения оборудования", err)
-        }
+    for _, e := range equipment {
         list = append(list, item{
-            ID: id,
-            ZoneID: zoneIDv,
-            Name: name,
-            PurchaseDate: dateYMD(purchase),
-            LastServiceDate: dateYMD(lastTO),
-            Status: statusV,
-            HasPhoto: hasPhotoV,
-            ZoneName: zoneName,
+            ID: e.ID,
+            ZoneID: e.ZoneID,
+            Name: e.Name,
+            PurchaseDate: dateYMD(e.PurchaseDate),
+            LastServiceDate: dateYMD(e.LastServiceDate),
+            Status: e.Status,
+            HasPhoto: e.HasPhoto,
+            ZoneName: e.ZoneName,
+            Type: e.Type,
+            UsageHours: e.UsageHours,
+            ReplacementCost: e.ReplacementCost,
         })
     }
-    if err := rows.Err(); err != nil {
-        return jsonError(c, 500, "Ошибка курсора", err)
-    }
     return jsonOK(c, fiber.Map{"items": list})
 }
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"fitness-center-manager/internal/freeze"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type freezeDTO struct {
+	ID        int    `json:"id"`
+	StartDate string `json:"start_date"` // YYYY-MM-DD
+	EndDate   string `json:"end_date"`
+	Days      int    `json:"days"`
+	Reason    string `json:"reason"`
+	Active    bool   `json:"active"` // идёт сегодня
+	Upcoming  bool   `json:"upcoming"`
+}
+
+func toFreezeDTOs(list []models.Freeze, now time.Time) []freezeDTO {
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	out := make([]freezeDTO, 0, len(list))
+	for _, f := range list {
+		out = append(out, freezeDTO{
+			ID:        f.ID,
+			StartDate: f.StartDate.Format("2006-01-02"),
+			EndDate:   f.EndDate.Format("2006-01-02"),
+			Days:      f.Days(),
+			Reason:    f.Reason,
+			Active:    f.Covers(today),
+			Upcoming:  f.StartDate.After(today),
+		})
+	}
+	return out
+}
+
+type allowanceDTO struct {
+	freeze.Allowance
+	RemainingDays  int `json:"remaining_days"`
+	RemainingCount int `json:"remaining_count"`
+}
+
+func toAllowanceDTO(a freeze.Allowance) allowanceDTO {
+	return allowanceDTO{a, a.RemainingDays(), a.RemainingCount()}
+}
+
+// loadFreezes — заморозки абонемента и остаток лимита по его тарифу.
+func loadFreezes(ctx context.Context, s store.Store, sub models.Subscription) (fiber.Map, error) {
+	t, err := s.Tariffs().Get(ctx, sub.TariffID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.Freezes().ListBySubscription(ctx, sub.ID)
+	if err != nil {
+		return nil, err
+	}
+	return fiber.Map{
+		"freezes":   toFreezeDTOs(list, clubNow()),
+		"allowance": toAllowanceDTO(freeze.AllowanceOf(t, list)),
+	}, nil
+}
+
+// APIv1ListFreezes — GET /api/v1/subscriptions/:id/freezes: история заморозок и остаток лимита
+func APIv1ListFreezes(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+
+	sub, err := data.Subscriptions().Get(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Абонемент не найден", nil)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки абонемента", err)
+	}
+	payload, err := loadFreezes(ctx, data, sub)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки заморозок", err)
+	}
+	return jsonOK(c, payload)
+}
+
+type freezeForm struct {
+	StartDate string `form:"start_date"` // YYYY-MM-DD
+	EndDate   string `form:"end_date"`   // YYYY-MM-DD, включительно
+	Reason    string `form:"reason"`
+}
+
+// parseFreezeForm разбирает форму заморозки; при ошибке ответ уже отправлен.
+func parseFreezeForm(c *fiber.Ctx) (store.FreezeInput, bool, error) {
+	var f freezeForm
+	if err := c.BodyParser(&f); err != nil {
+		return store.FreezeInput{}, false, jsonError(c, 400, "Неверные данные формы", err)
+	}
+	reason := strings.TrimSpace(f.Reason)
+	if f.StartDate == "" || f.EndDate == "" || reason == "" {
+		return store.FreezeInput{}, false, jsonError(c, 400, "Заполните обязательные поля", nil)
+	}
+	if len([]rune(reason)) > 200 {
+		return store.FreezeInput{}, false, jsonError(c, 400, "Причина длиннее 200 символов", nil)
+	}
+	start, err := time.Parse("2006-01-02", f.StartDate)
+	if err != nil {
+		return store.FreezeInput{}, false, jsonError(c, 400, "Неверная дата начала", err)
+	}
+	end, err := time.Parse("2006-01-02", f.EndDate)
+	if err != nil {
+		return store.FreezeInput{}, false, jsonError(c, 400, "Неверная дата окончания", err)
+	}
+	if end.Before(start) {
+		return store.FreezeInput{}, false, jsonError(c, 400, "Дата окончания раньше даты начала", nil)
+	}
+	return store.FreezeInput{StartDate: start, EndDate: end, Reason: reason}, true, nil
+}
+
+// lockFreeze блокирует абонемент заморозки id и читает её заново: сдвиг
+// окончания абонемента не должен пересечься с параллельной заморозкой.
+func lockFreeze(ctx context.Context, tx store.Store, id int) (models.Freeze, error) {
+	f, err := tx.Freezes().Get(ctx, id)
+	if err != nil {
+		return f, err
+	}
+	if err := tx.Subscriptions().Lock(ctx, f.SubscriptionID); err != nil {
+		return f, err
+	}
+	return tx.Freezes().Get(ctx, id)
+}
+
+// APIv1CreateFreeze — POST /api/v1/subscriptions/:id/freezes: заморозить абонемент.
+// Нарушение правил (лимиты тарифа, пересечение, срок) — 409 с кодом reason.
+func APIv1CreateFreeze(c *fiber.Ctx) error {
+	subID, err := strconv.Atoi(c.Params("id"))
+	if err != nil || subID <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	in, ok, err := parseFreezeForm(c)
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+
+	var (
+		id  int
+		sub models.Subscription
+	)
+	err = inTx(ctx, c, func(tx store.Store) error {
+		// абонемент блокируется до чтения заморозок: параллельные заявки
+		// проверяют лимиты и пересечения по очереди
+		if err := tx.Subscriptions().Lock(ctx, subID); err != nil {
+			return err
+		}
+		var err error
+		if sub, err = tx.Subscriptions().Get(ctx, subID); err != nil {
+			return err
+		}
+		t, err := tx.Tariffs().Get(ctx, sub.TariffID)
+		if err != nil {
+			return err
+		}
+		existing, err := tx.Freezes().ListBySubscription(ctx, subID)
+		if err != nil {
+			return err
+		}
+		if err := freeze.Check(sub, t, existing, in.StartDate, in.EndDate, clubNow()); err != nil {
+			return err
+		}
+		if id, err = tx.Freezes().Create(ctx, subID, in); err != nil {
+			return err
+		}
+		sub, err = tx.Subscriptions().Get(ctx, subID)
+		return err
+	})
+	var rule *freeze.Error
+	switch {
+	case errors.As(err, &rule):
+		return jsonReject(c, fiber.StatusConflict, string(rule.Reason), rule.Msg, nil)
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Абонемент не найден", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка сохранения заморозки", err)
+	}
+	c.Set("Location", "/api/v1/subscriptions/"+strconv.Itoa(subID)+"/freezes")
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success":  true,
+		"id":       id,
+		"message":  "Абонемент заморожен, окончание перенесено на " + sub.EndDate.Format(dateDisplayFormat),
+		"end_date": sub.EndDate.Format("2006-01-02"),
+	})
+}
+
+// APIv1EndFreeze — POST /api/v1/freezes/:id/end: клиент вернулся раньше.
+// Заморозка заканчивается вчерашним днём, а ещё не начавшаяся — отменяется;
+// неиспользованные дни возвращаются в лимит и снимаются с продления абонемента.
+func APIv1EndFreeze(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+
+	var message string
+	err = inTx(ctx, c, func(tx store.Store) error {
+		f, err := lockFreeze(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		end, ok := freeze.EndEarly(f, clubNow())
+		switch {
+		case !ok:
+			message = "Заморозка отменена"
+			return tx.Freezes().Delete(ctx, id)
+		case end.Equal(f.EndDate):
+			message = "Заморозка уже закончилась"
+			return nil
+		}
+		message = "Заморозка завершена " + end.Format(dateDisplayFormat)
+		return tx.Freezes().SetEnd(ctx, id, end)
+	})
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Заморозка не найдена", nil)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка завершения заморозки", err)
+	}
+	return jsonOK(c, fiber.Map{"message": message})
+}
+
+// APIv1DeleteFreeze — DELETE /api/v1/freezes/:id: удалить ошибочную заморозку
+// целиком (абонемент сокращается на её длительность)
+func APIv1DeleteFreeze(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		if _, err := lockFreeze(ctx, tx, id); err != nil {
+			return err
+		}
+		return tx.Freezes().Delete(ctx, id)
+	})
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Заморозка не найдена", nil)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка удаления заморозки", err)
+	}
+	return jsonOK(c, fiber.Map{"message": "Заморозка удалена"})
+}
@@ -42,6 +42,25 @@ func jsonError(c *fiber.Ctx, status int, publicMsg string, err error) error {
     return c.Status(status).JSON(problem)
 }
 
+// jsonReject — отказ по бизнес-правилу (не ошибка ввода): Problem Details
+// с машинным кодом причины в поле reason и контекстом из extra.
+func jsonReject(c *fiber.Ctx, status int, reason, publicMsg string, extra fiber.Map) error {
+    problem := fiber.Map{
+        "type":     problemType(publicMsg, status, c.OriginalURL()),
+        "title":    publicMsg,
+        "status":   status,
+        "instance": c.OriginalURL(),
+        "reason":   reason,
+        "success":  false,
+        "error":    publicMsg,
+    }
+    for k, v := range extra {
+        problem[k] = v
+    }
+    c.Type("application/problem+json")
+    return c.Status(status).JSON(problem)
+}
+
 func jsonOK(c *fiber.Ctx, payload fiber.Map) error {
     if payload == nil {
         payload = fiber.Map{}
@@ -76,7 +95,7 @@ func problemType(title string, status int, _path string) string {
         code = "conflict"
     case strings.Contains(t, "файл пустой") || strings.Contains(t, "превышает 5 мб") || strings.Contains(t, "больше 5 мб"):
         code = "file-too-large"
-    case strings.Contains(t, "jpeg/png/webp") || strings.Contains(t, "разрешены jpeg") || strings.Contains(t, "только jpeg"):
+    case strings.Contains(t, "jpeg/png/webp") || strings.Contains(t, "разрешены jpeg") || strings.Contains(t, "только jpeg") || strings.Contains(t, "поддерживаются jpeg"):
         code = "invalid-image-type"
     case strings.Contains(t, "недопустимый статус") || strings.Contains(t, "неверный статус"):
         code = "invalid-status"
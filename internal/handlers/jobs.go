@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"fitness-center-manager/internal/jobs"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// recentJobRunsLimit — сколько последних запусков показывать на /admin/jobs.
+const recentJobRunsLimit = 50
+
+var scheduler *jobs.Scheduler
+
+// SetScheduler подключает планировщик фоновых задач.
+func SetScheduler(s *jobs.Scheduler) { scheduler = s }
+
+type jobRunDTO struct {
+	ID       int64  `json:"id"`
+	Job      string `json:"job"`
+	Host     string `json:"host"`
+	Started  string `json:"started"`
+	Duration string `json:"duration"` // пусто — ещё идёт
+	Status   string `json:"status"`
+	Changed  int    `json:"changed"`
+	Details  string `json:"details"`
+	Error    string `json:"error"`
+}
+
+func toJobRunDTO(r store.JobRun) jobRunDTO {
+	d := jobRunDTO{
+		ID:      r.ID,
+		Job:     r.Job,
+		Host:    r.Host,
+		Started: r.StartedAt.Format(visitTimeFormat + ":05"),
+		Status:  r.Status,
+		Changed: r.Changed,
+		Details: r.Details,
+		Error:   r.Error,
+	}
+	if r.FinishedAt.Valid {
+		d.Duration = r.Duration().Round(time.Millisecond).String()
+	}
+	return d
+}
+
+type jobDTO struct {
+	Name    string     `json:"name"`
+	Title   string     `json:"title"`
+	Cron    string     `json:"cron"`
+	NextRun string     `json:"next_run"` // пусто — в этом экземпляре задачи отключены
+	Running bool       `json:"running"`
+	Last    *jobRunDTO `json:"last"`
+}
+
+// loadJobs — задачи планировщика с последним запуском каждой и общий журнал.
+func loadJobs() ([]jobDTO, []jobRunDTO, error) {
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	last, err := data.JobRuns().Last(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	recent, err := data.JobRuns().Recent(ctx, recentJobRunsLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	list := []jobDTO{}
+	if scheduler != nil {
+		for _, st := range scheduler.States() {
+			j := jobDTO{Name: st.Name, Title: st.Title, Cron: st.Cron, Running: st.Running}
+			if !st.NextRun.IsZero() {
+				j.NextRun = st.NextRun.Format(visitTimeFormat)
+			}
+			if r, ok := last[st.Name]; ok {
+				d := toJobRunDTO(r)
+				j.Last = &d
+			}
+			list = append(list, j)
+		}
+	}
+	runs := make([]jobRunDTO, 0, len(recent))
+	for _, r := range recent {
+		runs = append(runs, toJobRunDTO(r))
+	}
+	return list, runs, nil
+}
+
+// GetJobsPage — /admin/jobs: расписание задач, последний запуск, ошибки
+func GetJobsPage(c *fiber.Ctx) error {
+	list, runs, err := loadJobs()
+	if err != nil {
+		log.Printf("❌ jobs page: %v", err)
+		return c.Render("jobs", fiber.Map{
+			"Title":        "Фоновые задачи",
+			"Jobs":         []jobDTO{},
+			"Runs":         []jobRunDTO{},
+			"Message":      "Не удалось загрузить журнал запусков",
+			"ExtraScripts": templateScript("/static/js/jobs.js"),
+		})
+	}
+	return c.Render("jobs", fiber.Map{
+		"Title":        "Фоновые задачи",
+		"Jobs":         list,
+		"Runs":         runs,
+		"ExtraScripts": templateScript("/static/js/jobs.js"),
+	})
+}
+
+// APIv1ListJobs — JSON: задачи и последние запуски
+func APIv1ListJobs(c *fiber.Ctx) error {
+	list, runs, err := loadJobs()
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки журнала запусков", err)
+	}
+	return jsonOK(c, fiber.Map{"jobs": list, "runs": runs})
+}
+
+// RunJob — POST /admin/jobs/:name/run: запустить задачу сейчас, вне расписания.
+// Запуск идёт под тем же advisory-lock, что и по расписанию.
+func RunJob(c *fiber.Ctx) error {
+	if scheduler == nil {
+		return jsonError(c, 503, "Планировщик не запущен", nil)
+	}
+	res, err := scheduler.RunNow(c.UserContext(), c.Params("name"))
+	switch {
+	case errors.Is(err, jobs.ErrUnknownJob):
+		return jsonError(c, 404, "Задача не найдена", nil)
+	case errors.Is(err, jobs.ErrLocked):
+		return jsonError(c, 409, "Задача уже выполняется", nil)
+	case err != nil:
+		return jsonError(c, 500, "Задача завершилась с ошибкой", err)
+	}
+	return jsonOK(c, fiber.Map{
+		"message": "Задача выполнена",
+		"changed": res.Changed,
+		"details": res.Details,
+	})
+}
@@ -0,0 +1,405 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"fitness-center-manager/internal/checkin"
+	"fitness-center-manager/internal/maintenance"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// flagOverdueMaintenance — оборудование с просроченным ТО нельзя отметить
+// исправным, пока ТО не проведено (equipment.flag_overdue_maintenance).
+var flagOverdueMaintenance bool
+
+// SetMaintenanceOptions включает запрет «Исправен» при просроченном ТО.
+func SetMaintenanceOptions(flagOverdue bool) { flagOverdueMaintenance = flagOverdue }
+
+// errMaintenanceOverdue — попытка отметить исправным оборудование с просроченным ТО.
+var errMaintenanceOverdue = errors.New("ТО просрочено")
+
+// loadMaintenance — сроки ТО по всем планам на сегодня.
+func loadMaintenance(ctx context.Context, tx store.Store) ([]models.MaintenanceDue, error) {
+	plans, err := tx.Maintenance().Plans(ctx)
+	if err != nil {
+		return nil, err
+	}
+	equipment, err := tx.Equipment().List(ctx, store.EquipmentFilter{})
+	if err != nil {
+		return nil, err
+	}
+	last, err := tx.Maintenance().Latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return maintenance.Schedule(plans, equipment, last, checkin.Day(time.Now())), nil
+}
+
+// maintenanceOverdue — просрочено ли ТО единицы id хотя бы по одному плану.
+func maintenanceOverdue(ctx context.Context, tx store.Store, id int) (bool, error) {
+	items, err := loadMaintenance(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	return maintenance.Overdue(items)[id], nil
+}
+
+type maintenanceDueDTO struct {
+	PlanID        int     `json:"plan_id"`
+	PlanName      string  `json:"plan_name"`
+	EquipmentID   int     `json:"equipment_id"`
+	EquipmentName string  `json:"equipment_name"`
+	ZoneName      string  `json:"zone_name"`
+	LastDate      string  `json:"last_date"` // пусто — ТО не было
+	DueDate       string  `json:"due_date"`  // пусто — интервала в днях нет
+	UsageHours    float64 `json:"usage_hours"`
+	DueHours      float64 `json:"due_hours"` // 0 — интервала в часах нет
+	State         string  `json:"state"`
+	StateTitle    string  `json:"state_title"`
+}
+
+func toMaintenanceDueDTO(d models.MaintenanceDue) maintenanceDueDTO {
+	out := maintenanceDueDTO{
+		PlanID: d.Plan.ID, PlanName: d.Plan.Name,
+		EquipmentID: d.Equipment.ID, EquipmentName: d.Equipment.Name, ZoneName: d.Equipment.ZoneName,
+		LastDate: dateYMD(d.LastDate), UsageHours: d.Equipment.UsageHours, DueHours: d.DueHours,
+		State: d.State, StateTitle: maintenance.States[d.State],
+	}
+	if !d.DueDate.IsZero() {
+		out.DueDate = d.DueDate.Format("2006-01-02")
+	}
+	return out
+}
+
+// APIv1MaintenanceDue — GET /api/v1/maintenance/due[?all=1]: подходящие и
+// просроченные ТО (с all=1 — все сроки), просроченные первыми.
+func APIv1MaintenanceDue(c *fiber.Ctx) error {
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	items, err := loadMaintenance(ctx, data)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка расчёта сроков ТО", err)
+	}
+	if c.Query("all") != "1" {
+		items = maintenance.Pending(items)
+	}
+	out := make([]maintenanceDueDTO, 0, len(items))
+	for _, d := range items {
+		out = append(out, toMaintenanceDueDTO(d))
+	}
+	return jsonOK(c, fiber.Map{"items": out})
+}
+
+type maintenancePlanDTO struct {
+	ID            int      `json:"id"`
+	Name          string   `json:"name"`
+	EquipmentID   int      `json:"equipment_id"`
+	EquipmentName string   `json:"equipment_name"`
+	EquipmentType string   `json:"equipment_type"`
+	IntervalDays  int      `json:"interval_days"`
+	IntervalHours int      `json:"interval_hours"`
+	Checklist     []string `json:"checklist"`
+}
+
+func toMaintenancePlanDTO(p models.MaintenancePlan) maintenancePlanDTO {
+	list := p.Checklist
+	if list == nil {
+		list = []string{}
+	}
+	return maintenancePlanDTO{p.ID, p.Name, p.EquipmentID, p.EquipmentName, p.EquipmentType,
+		p.IntervalDays, p.IntervalHours, list}
+}
+
+// APIv1MaintenancePlans — GET /api/v1/maintenance/plans
+func APIv1MaintenancePlans(c *fiber.Ctx) error {
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	plans, err := data.Maintenance().Plans(ctx)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки планов ТО", err)
+	}
+	out := make([]maintenancePlanDTO, 0, len(plans))
+	for _, p := range plans {
+		out = append(out, toMaintenancePlanDTO(p))
+	}
+	return jsonOK(c, fiber.Map{"plans": out})
+}
+
+// parsePlanForm — поля плана ТО (name, equipment_id | equipment_type,
+// interval_days, interval_hours, checklist — пункты построчно).
+func parsePlanForm(c *fiber.Ctx) (store.MaintenancePlanInput, bool, error) {
+	in := store.MaintenancePlanInput{
+		Name:          strings.TrimSpace(c.FormValue("name")),
+		EquipmentType: strings.TrimSpace(c.FormValue("equipment_type")),
+	}
+	if in.Name == "" || len([]rune(in.Name)) > 100 {
+		return in, false, jsonError(c, 400, "Укажите название плана (до 100 символов)", nil)
+	}
+	if s := strings.TrimSpace(c.FormValue("equipment_id")); s != "" {
+		id, err := strconv.Atoi(s)
+		if err != nil || id < 0 {
+			return in, false, jsonError(c, 400, "Некорректный id оборудования", err)
+		}
+		in.EquipmentID = id
+	}
+	if (in.EquipmentID > 0) == (in.EquipmentType != "") {
+		return in, false, jsonError(c, 400, "Укажите либо единицу оборудования, либо тип", nil)
+	}
+	if len([]rune(in.EquipmentType)) > 50 {
+		return in, false, jsonError(c, 400, "Тип — до 50 символов", nil)
+	}
+	for _, f := range []struct {
+		name string
+		dst  *int
+	}{{"interval_days", &in.IntervalDays}, {"interval_hours", &in.IntervalHours}} {
+		s := strings.TrimSpace(c.FormValue(f.name))
+		if s == "" {
+			continue
+		}
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 {
+			return in, false, jsonError(c, 400, "Интервал — целое положительное число", nil)
+		}
+		*f.dst = v
+	}
+	if in.IntervalDays == 0 && in.IntervalHours == 0 {
+		return in, false, jsonError(c, 400, "Укажите интервал в днях и/или моточасах", nil)
+	}
+	for _, line := range strings.Split(c.FormValue("checklist"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			in.Checklist = append(in.Checklist, line)
+		}
+	}
+	return in, true, nil
+}
+
+func planSaveError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "План не найден", nil)
+	case errors.Is(err, store.ErrInUse):
+		return jsonError(c, 400, "Оборудование не найдено", nil)
+	}
+	return jsonError(c, 500, "Ошибка сохранения плана ТО", err)
+}
+
+// APIv1CreateMaintenancePlan — POST /api/v1/maintenance/plans
+func APIv1CreateMaintenancePlan(c *fiber.Ctx) error {
+	in, ok, err := parsePlanForm(c)
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var id int
+	err = inTx(ctx, c, func(tx store.Store) error {
+		id, err = tx.Maintenance().CreatePlan(ctx, in)
+		return err
+	})
+	if err != nil {
+		return planSaveError(c, err)
+	}
+	return jsonOK(c, fiber.Map{"id": id, "message": "План ТО создан"})
+}
+
+// APIv1UpdateMaintenancePlan — PUT /api/v1/maintenance/plans/:id
+func APIv1UpdateMaintenancePlan(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	in, ok, err := parsePlanForm(c)
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		return tx.Maintenance().UpdatePlan(ctx, id, in)
+	})
+	if err != nil {
+		return planSaveError(c, err)
+	}
+	return jsonOK(c, fiber.Map{"message": "План ТО обновлён"})
+}
+
+// APIv1DeleteMaintenancePlan — DELETE /api/v1/maintenance/plans/:id:
+// история проведённых по плану ТО остаётся.
+func APIv1DeleteMaintenancePlan(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		return tx.Maintenance().DeletePlan(ctx, id)
+	})
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "План не найден", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка удаления плана ТО", err)
+	}
+	return jsonOK(c, fiber.Map{"message": "План ТО удалён"})
+}
+
+type maintenanceRecordDTO struct {
+	ID          int                    `json:"id"`
+	PlanID      int                    `json:"plan_id"`
+	PlanName    string                 `json:"plan_name"` // пусто — внеплановое
+	Date        string                 `json:"date"`
+	UsageHours  *float64               `json:"usage_hours"`
+	Checklist   []models.ChecklistItem `json:"checklist"`
+	Comment     string                 `json:"comment"`
+	PerformedBy string                 `json:"performed_by"`
+}
+
+// APIv1EquipmentMaintenance — GET /api/v1/equipment/:id/maintenance: сроки
+// ТО единицы по планам и история обслуживания.
+func APIv1EquipmentMaintenance(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	e, err := data.Equipment().Get(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Оборудование не найдено", nil)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка БД", err)
+	}
+	items, err := loadMaintenance(ctx, data)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка расчёта сроков ТО", err)
+	}
+	due := []maintenanceDueDTO{}
+	checklists := fiber.Map{}
+	for _, d := range items {
+		if d.Equipment.ID == id {
+			due = append(due, toMaintenanceDueDTO(d))
+			checklists[strconv.Itoa(d.Plan.ID)] = toMaintenancePlanDTO(d.Plan).Checklist
+		}
+	}
+	history, err := data.Maintenance().History(ctx, id)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки истории ТО", err)
+	}
+	out := make([]maintenanceRecordDTO, 0, len(history))
+	for _, m := range history {
+		r := maintenanceRecordDTO{ID: m.ID, PlanID: m.PlanID, PlanName: m.PlanName, Date: m.Date.Format("2006-01-02"),
+			Checklist: m.Checklist, Comment: m.Comment, PerformedBy: m.PerformedBy}
+		if m.UsageHours.Valid {
+			h := m.UsageHours.Float64
+			r.UsageHours = &h
+		}
+		if r.Checklist == nil {
+			r.Checklist = []models.ChecklistItem{}
+		}
+		out = append(out, r)
+	}
+	return jsonOK(c, fiber.Map{"item": equipmentMap(e), "due": due, "checklists": checklists, "history": out})
+}
+
+// APIv1LogMaintenance — POST /api/v1/equipment/:id/maintenance (plan_id,
+// date, usage_hours, done — номера выполненных пунктов чек-листа, comment):
+// запись в историю, "Дата_последнего_ТО" и наработка сдвигаются вперёд.
+// «Требует ТО» снимается, если просроченных планов больше нет.
+func APIv1LogMaintenance(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	me := currentStaff(c)
+	in := store.MaintenanceInput{EquipmentID: id, Comment: strings.TrimSpace(c.FormValue("comment"))}
+	if me != nil {
+		in.StaffID, in.PerformedBy = me.ID, me.FIO
+	}
+	if in.PerformedBy == "" {
+		in.PerformedBy = "—"
+	}
+	if s := strings.TrimSpace(c.FormValue("plan_id")); s != "" {
+		if in.PlanID, err = strconv.Atoi(s); err != nil || in.PlanID < 0 {
+			return jsonError(c, 400, "Некорректный план", err)
+		}
+	}
+	in.Date = checkin.Day(time.Now())
+	if s := c.FormValue("date"); s != "" {
+		if in.Date, err = time.Parse("2006-01-02", s); err != nil {
+			return jsonError(c, 400, "Неверная дата ТО", err)
+		}
+		if in.Date.After(checkin.Day(time.Now())) {
+			return jsonError(c, 400, "Дата ТО не может быть в будущем", nil)
+		}
+	}
+	if s := strings.TrimSpace(c.FormValue("usage_hours")); s != "" {
+		h, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+		if err != nil || h < 0 {
+			return jsonError(c, 400, "Наработка — неотрицательное число", nil)
+		}
+		in.UsageHours = sql.NullFloat64{Float64: h, Valid: true}
+	}
+	done := map[int]bool{}
+	for _, v := range c.Request().PostArgs().PeekMulti("done") {
+		if i, err := strconv.Atoi(string(v)); err == nil {
+			done[i] = true
+		}
+	}
+
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var recID int
+	var noPlan bool
+	err = inTx(ctx, c, func(tx store.Store) error {
+		e, err := tx.Equipment().Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if in.PlanID > 0 {
+			p, err := tx.Maintenance().Plan(ctx, in.PlanID)
+			if errors.Is(err, store.ErrNotFound) || (err == nil && !maintenance.Applies(p, e)) {
+				noPlan = true
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			for i, item := range p.Checklist {
+				in.Checklist = append(in.Checklist, models.ChecklistItem{Item: item, Done: done[i]})
+			}
+		}
+		if recID, err = tx.Maintenance().Log(ctx, in); err != nil {
+			return err
+		}
+		if err := tx.Equipment().MarkServiced(ctx, id, in.Date, in.UsageHours); err != nil {
+			return err
+		}
+		if e.Status != "Требует ТО" {
+			return nil
+		}
+		overdue, err := maintenanceOverdue(ctx, tx, id)
+		if err != nil || overdue {
+			return err
+		}
+		return tx.Equipment().SetStatus(ctx, id, "Исправен")
+	})
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Оборудование не найдено", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка сохранения ТО", err)
+	case noPlan:
+		return jsonError(c, 400, "План не найден или не относится к этому оборудованию", nil)
+	}
+	return jsonOK(c, fiber.Map{"id": recID, "message": "ТО записано"})
+}
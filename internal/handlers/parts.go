@@ -0,0 +1,518 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"fitness-center-manager/internal/ledger"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/repair"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetRepairCostsPage — подрядчики, склад запчастей и затраты на ремонт
+// (администратор и техник).
+func GetRepairCostsPage(c *fiber.Ctx) error {
+	from, to := defaultSpendMonths()
+	return c.Render("repair_costs", fiber.Map{
+		"Title":        "Затраты на ремонт",
+		"From":         from.Format("2006-01"),
+		"To":           to.Format("2006-01"),
+		"ExtraScripts": templateScript("/static/js/repair_costs.js"),
+	})
+}
+
+// defaultSpendMonths — последние 12 месяцев, включая текущий.
+func defaultSpendMonths() (from, to time.Time) {
+	now := time.Now()
+	to = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return to.AddDate(0, -11, 0), to
+}
+
+// idParam — положительный id из параметра маршрута.
+func idParam(c *fiber.Ctx) (int, bool, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return 0, false, jsonError(c, 400, "Некорректный id", err)
+	}
+	return id, true, nil
+}
+
+// ---------- Подрядчики ----------
+
+// parseVendorForm — поля подрядчика (name, contact, phone, email, note).
+func parseVendorForm(c *fiber.Ctx) (store.VendorInput, bool, error) {
+	in := store.VendorInput{
+		Name:    strings.TrimSpace(c.FormValue("name")),
+		Contact: strings.TrimSpace(c.FormValue("contact")),
+		Phone:   strings.TrimSpace(c.FormValue("phone")),
+		Email:   strings.TrimSpace(c.FormValue("email")),
+		Note:    strings.TrimSpace(c.FormValue("note")),
+	}
+	switch {
+	case in.Name == "" || len([]rune(in.Name)) > 150:
+		return in, false, jsonError(c, 400, "Укажите название подрядчика (до 150 символов)", nil)
+	case len([]rune(in.Contact)) > 150:
+		return in, false, jsonError(c, 400, "Контакт — до 150 символов", nil)
+	case len([]rune(in.Phone)) > 30:
+		return in, false, jsonError(c, 400, "Телефон — до 30 символов", nil)
+	case len(in.Email) > 100 || (in.Email != "" && !strings.Contains(in.Email, "@")):
+		return in, false, jsonError(c, 400, "Некорректный email", nil)
+	}
+	return in, true, nil
+}
+
+func vendorSaveError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Подрядчик не найден", nil)
+	case errors.Is(err, store.ErrDuplicate):
+		return jsonError(c, 409, "Подрядчик с таким названием уже есть", nil)
+	}
+	return jsonError(c, 500, "Ошибка сохранения подрядчика", err)
+}
+
+// APIv1Vendors — GET /api/v1/vendors
+func APIv1Vendors(c *fiber.Ctx) error {
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	list, err := data.Vendors().List(ctx)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки подрядчиков", err)
+	}
+	if list == nil {
+		list = []models.Vendor{}
+	}
+	return jsonOK(c, fiber.Map{"vendors": list})
+}
+
+// APIv1CreateVendor — POST /api/v1/vendors
+func APIv1CreateVendor(c *fiber.Ctx) error {
+	in, ok, err := parseVendorForm(c)
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var id int
+	err = inTx(ctx, c, func(tx store.Store) error {
+		id, err = tx.Vendors().Create(ctx, in)
+		return err
+	})
+	if err != nil {
+		return vendorSaveError(c, err)
+	}
+	return jsonOK(c, fiber.Map{"id": id, "message": "Подрядчик добавлен"})
+}
+
+// APIv1UpdateVendor — PUT /api/v1/vendors/:id
+func APIv1UpdateVendor(c *fiber.Ctx) error {
+	id, ok, err := idParam(c)
+	if !ok {
+		return err
+	}
+	in, ok, err := parseVendorForm(c)
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		return tx.Vendors().Update(ctx, id, in)
+	})
+	if err != nil {
+		return vendorSaveError(c, err)
+	}
+	return jsonOK(c, fiber.Map{"message": "Подрядчик сохранён"})
+}
+
+// APIv1DeleteVendor — DELETE /api/v1/vendors/:id: у заявок и запчастей
+// подрядчик просто снимается.
+func APIv1DeleteVendor(c *fiber.Ctx) error {
+	id, ok, err := idParam(c)
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		return tx.Vendors().Delete(ctx, id)
+	})
+	if err != nil {
+		return vendorSaveError(c, err)
+	}
+	return jsonOK(c, fiber.Map{"message": "Подрядчик удалён"})
+}
+
+// ---------- Склад запчастей ----------
+
+type partDTO struct {
+	models.SparePart
+	Low bool `json:"low"` // остаток не выше порога заказа
+}
+
+func toPartDTO(p models.SparePart) partDTO {
+	return partDTO{p, p.ReorderLevel > 0 && p.Stock <= p.ReorderLevel}
+}
+
+// parsePartForm — карточка запчасти (name, sku, unit, reorder_level, price, vendor_id).
+func parsePartForm(c *fiber.Ctx) (store.PartInput, bool, error) {
+	in := store.PartInput{
+		Name: strings.TrimSpace(c.FormValue("name")),
+		SKU:  strings.TrimSpace(c.FormValue("sku")),
+		Unit: strings.TrimSpace(c.FormValue("unit")),
+	}
+	if in.Unit == "" {
+		in.Unit = "шт"
+	}
+	switch {
+	case in.Name == "" || len([]rune(in.Name)) > 150:
+		return in, false, jsonError(c, 400, "Укажите название запчасти (до 150 символов)", nil)
+	case len([]rune(in.SKU)) > 50:
+		return in, false, jsonError(c, 400, "Артикул — до 50 символов", nil)
+	case len([]rune(in.Unit)) > 10:
+		return in, false, jsonError(c, 400, "Единица — до 10 символов", nil)
+	}
+	var ok bool
+	var err error
+	if in.ReorderLevel, ok, err = amountField(c, "reorder_level", "Порог заказа", 0); !ok {
+		return in, false, err
+	}
+	if in.Price, ok, err = amountField(c, "price", "Цена", 0); !ok {
+		return in, false, err
+	}
+	if s := strings.TrimSpace(c.FormValue("vendor_id")); s != "" {
+		if in.VendorID, err = strconv.Atoi(s); err != nil || in.VendorID < 0 {
+			return in, false, jsonError(c, 400, "Некорректный поставщик", err)
+		}
+	}
+	return in, true, nil
+}
+
+func partSaveError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Запчасть не найдена", nil)
+	case errors.Is(err, store.ErrDuplicate):
+		return jsonError(c, 409, "Запчасть с таким артикулом уже есть", nil)
+	case errors.Is(err, store.ErrInUse):
+		return jsonError(c, 409, "Запчасть уже списывали на заявки или поставщик не найден", nil)
+	}
+	return jsonError(c, 500, "Ошибка сохранения запчасти", err)
+}
+
+// APIv1Parts — GET /api/v1/parts?low=1: склад; low — только те, что пора заказать.
+func APIv1Parts(c *fiber.Ctx) error {
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	list, err := data.Parts().List(ctx)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки склада", err)
+	}
+	out := make([]partDTO, 0, len(list))
+	lowOnly := c.Query("low") == "1"
+	for _, p := range list {
+		if d := toPartDTO(p); d.Low || !lowOnly {
+			out = append(out, d)
+		}
+	}
+	return jsonOK(c, fiber.Map{"parts": out})
+}
+
+// APIv1CreatePart — POST /api/v1/parts (+ stock — начальный остаток).
+func APIv1CreatePart(c *fiber.Ctx) error {
+	in, ok, err := parsePartForm(c)
+	if !ok {
+		return err
+	}
+	stock, ok, err := amountField(c, "stock", "Остаток", 0)
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var id int
+	err = inTx(ctx, c, func(tx store.Store) error {
+		if id, err = tx.Parts().Create(ctx, in); err != nil || stock == 0 {
+			return err
+		}
+		return tx.Parts().Receive(ctx, id, stock)
+	})
+	if err != nil {
+		return partSaveError(c, err)
+	}
+	return jsonOK(c, fiber.Map{"id": id, "message": "Запчасть добавлена"})
+}
+
+// APIv1UpdatePart — PUT /api/v1/parts/:id: карточка без остатка.
+func APIv1UpdatePart(c *fiber.Ctx) error {
+	id, ok, err := idParam(c)
+	if !ok {
+		return err
+	}
+	in, ok, err := parsePartForm(c)
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		return tx.Parts().Update(ctx, id, in)
+	})
+	if err != nil {
+		return partSaveError(c, err)
+	}
+	return jsonOK(c, fiber.Map{"message": "Запчасть сохранена"})
+}
+
+// APIv1DeletePart — DELETE /api/v1/parts/:id
+func APIv1DeletePart(c *fiber.Ctx) error {
+	id, ok, err := idParam(c)
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		return tx.Parts().Delete(ctx, id)
+	})
+	if err != nil {
+		return partSaveError(c, err)
+	}
+	return jsonOK(c, fiber.Map{"message": "Запчасть удалена"})
+}
+
+// APIv1ReceivePart — POST /api/v1/parts/:id/receive (quantity): приход на склад.
+func APIv1ReceivePart(c *fiber.Ctx) error {
+	id, ok, err := idParam(c)
+	if !ok {
+		return err
+	}
+	qty, ok, err := amountField(c, "quantity", "Количество", 0)
+	if !ok {
+		return err
+	}
+	if qty <= 0 {
+		return jsonError(c, 400, "Количество — больше нуля", nil)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		return tx.Parts().Receive(ctx, id, qty)
+	})
+	if err != nil {
+		return partSaveError(c, err)
+	}
+	return jsonOK(c, fiber.Map{"message": "Приход записан"})
+}
+
+// ---------- Затраты по заявке ----------
+
+// APIv1RepairCosts — GET /api/v1/repairs/:id/costs: подрядчик, работы,
+// списанные запчасти и итог.
+func APIv1RepairCosts(c *fiber.Ctx) error {
+	id, ok, err := idParam(c)
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	r, err := data.Repairs().Get(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Заявка не найдена", nil)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки заявки", err)
+	}
+	usage, err := data.Parts().Usage(ctx, id)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки расхода запчастей", err)
+	}
+	if usage == nil {
+		usage = []models.PartUsage{}
+	}
+	total := float64(ledger.Cents(r.LabourCost)+ledger.Cents(r.PartsCost)) / 100
+	return jsonOK(c, fiber.Map{"vendor_id": r.VendorID, "vendor_name": r.VendorName, "labour_cost": r.LabourCost,
+		"parts_cost": r.PartsCost, "total": total, "parts": usage})
+}
+
+// APIv1SetRepairCosts — PUT /api/v1/repairs/:id/costs (vendor_id — пусто,
+// если своими силами; labour_cost).
+func APIv1SetRepairCosts(c *fiber.Ctx) error {
+	id, ok, err := idParam(c)
+	if !ok {
+		return err
+	}
+	labour, ok, err := amountField(c, "labour_cost", "Стоимость работ", 0)
+	if !ok {
+		return err
+	}
+	var vendorID int
+	if s := strings.TrimSpace(c.FormValue("vendor_id")); s != "" {
+		if vendorID, err = strconv.Atoi(s); err != nil || vendorID < 0 {
+			return jsonError(c, 400, "Некорректный подрядчик", err)
+		}
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var noVendor bool
+	err = inTx(ctx, c, func(tx store.Store) error {
+		if vendorID > 0 {
+			if _, err := tx.Vendors().Get(ctx, vendorID); errors.Is(err, store.ErrNotFound) {
+				noVendor = true
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+		return tx.Repairs().SetCosts(ctx, id, vendorID, labour)
+	})
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Заявка не найдена", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка сохранения затрат", err)
+	case noVendor:
+		return jsonError(c, 400, "Подрядчик не найден", nil)
+	}
+	return jsonOK(c, fiber.Map{"message": "Затраты сохранены"})
+}
+
+// APIv1ConsumePart — POST /api/v1/repairs/:id/parts (part_id, quantity):
+// списать запчасть со склада на заявку по текущей цене.
+func APIv1ConsumePart(c *fiber.Ctx) error {
+	id, ok, err := idParam(c)
+	if !ok {
+		return err
+	}
+	partID, err := strconv.Atoi(c.FormValue("part_id"))
+	if err != nil || partID <= 0 {
+		return jsonError(c, 400, "Выберите запчасть", err)
+	}
+	qty, ok, err := amountField(c, "quantity", "Количество", 0)
+	if !ok {
+		return err
+	}
+	if qty <= 0 {
+		return jsonError(c, 400, "Количество — больше нуля", nil)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var usageID int
+	err = inTx(ctx, c, func(tx store.Store) error {
+		if _, err := tx.Repairs().Get(ctx, id); err != nil {
+			return err
+		}
+		usageID, err = tx.Parts().Consume(ctx, id, partID, qty)
+		return err
+	})
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Заявка или запчасть не найдена", nil)
+	case errors.Is(err, store.ErrOutOfStock):
+		return jsonError(c, 409, "На складе меньше, чем нужно списать", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка списания запчасти", err)
+	}
+	return jsonOK(c, fiber.Map{"id": usageID, "message": "Запчасть списана"})
+}
+
+// APIv1ReturnPart — DELETE /api/v1/repair-parts/:id: отменить списание,
+// количество возвращается на склад.
+func APIv1ReturnPart(c *fiber.Ctx) error {
+	id, ok, err := idParam(c)
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		_, err := tx.Parts().Return(ctx, id)
+		return err
+	})
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Списание не найдено", nil)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка отмены списания", err)
+	}
+	return jsonOK(c, fiber.Map{"message": "Запчасть возвращена на склад"})
+}
+
+// ---------- Отчёт о затратах ----------
+
+// APIv1RepairSpend — GET /api/v1/reports/repair-spend?from=ГГГГ-ММ&to=ГГГГ-ММ&replace_share=:
+// затраты на ремонт по единицам, зонам и месяцам (по умолчанию — за
+// 12 месяцев) и единицы, ремонт которых за всё время обошёлся не меньше
+// replace_share % стоимости замены (по умолчанию 100).
+func APIv1RepairSpend(c *fiber.Ctx) error {
+	from, to := defaultSpendMonths()
+	var ok bool
+	var err error
+	if s := c.Query("from"); s != "" {
+		if from, ok, err = monthParam(c, s); !ok {
+			return err
+		}
+	}
+	if s := c.Query("to"); s != "" {
+		if to, ok, err = monthParam(c, s); !ok {
+			return err
+		}
+	}
+	if to.Before(from) {
+		return jsonError(c, 400, "Конец периода раньше начала", nil)
+	}
+	share := 100.0
+	if s := c.Query("replace_share"); s != "" {
+		if share, err = strconv.ParseFloat(s, 64); err != nil || share <= 0 || share > 1000 {
+			return jsonError(c, 400, "replace_share — от 1 до 1000 %", nil)
+		}
+	}
+
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	rows, err := data.Repairs().Spend(ctx, from, to.AddDate(0, 1, 0))
+	if err != nil {
+		return jsonError(c, 500, "Ошибка расчёта затрат", err)
+	}
+	lf, lt := repair.Lifetime()
+	lifetime, err := data.Repairs().Spend(ctx, lf, lt)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка расчёта затрат", err)
+	}
+	equipment, err := data.Equipment().List(ctx, store.EquipmentFilter{})
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки оборудования", err)
+	}
+	parts, err := data.Parts().List(ctx)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки склада", err)
+	}
+	low := []partDTO{}
+	for _, p := range parts {
+		if d := toPartDTO(p); d.Low {
+			low = append(low, d)
+		}
+	}
+
+	byMonth := repair.ByMonth(rows)
+	var labour, partsCost int64
+	for _, m := range byMonth {
+		labour += ledger.Cents(m.Labour)
+		partsCost += ledger.Cents(m.Parts)
+	}
+	candidates := repair.ReplaceCandidates(equipment, lifetime, share/100)
+	if candidates == nil {
+		candidates = []repair.ReplaceCandidate{}
+	}
+	return jsonOK(c, fiber.Map{
+		"from": from.Format("2006-01"), "to": to.Format("2006-01"),
+		"labour": float64(labour) / 100, "parts": float64(partsCost) / 100, "total": float64(labour+partsCost) / 100,
+		"by_equipment": repair.ByEquipment(rows), "by_zone": repair.ByZone(rows), "by_month": byMonth,
+		"replace_candidates": candidates, "low_stock": low,
+	})
+}
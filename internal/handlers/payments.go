@@ -0,0 +1,385 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"fitness-center-manager/internal/auth"
+	"fitness-center-manager/internal/ledger"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type paymentDTO struct {
+	ID                 int     `json:"id"`
+	Kind               string  `json:"kind"`
+	Amount             float64 `json:"amount"`
+	Method             string  `json:"method"`
+	PaidAt             string  `json:"paid_at"`
+	Cashier            string  `json:"cashier"`
+	Comment            string  `json:"comment"`
+	SubscriptionID     int64   `json:"subscription_id,omitempty"`
+	PersonalTrainingID int64   `json:"personal_training_id,omitempty"`
+}
+
+func toPaymentDTOs(list []models.Payment) []paymentDTO {
+	out := make([]paymentDTO, 0, len(list))
+	for _, p := range list {
+		out = append(out, paymentDTO{
+			ID:                 p.ID,
+			Kind:               p.Kind,
+			Amount:             p.Amount,
+			Method:             p.Method,
+			PaidAt:             p.PaidAt.Format(visitTimeFormat),
+			Cashier:            p.Cashier,
+			Comment:            p.Comment,
+			SubscriptionID:     p.SubscriptionID.Int64,
+			PersonalTrainingID: p.PersonalTrainingID.Int64,
+		})
+	}
+	return out
+}
+
+func sumPaid(list []models.Payment) float64 {
+	var paid float64
+	for _, p := range list {
+		paid += p.Signed()
+	}
+	return paid
+}
+
+// ledgerPayload — цена позиции, оплачено, остаток и история платежей.
+func ledgerPayload(price float64, list []models.Payment) fiber.Map {
+	paid := sumPaid(list)
+	return fiber.Map{
+		"price":    price,
+		"paid":     paid,
+		"owed":     ledger.Owed(price, paid),
+		"status":   ledger.Status(price, paid),
+		"payments": toPaymentDTOs(list),
+		"methods":  ledger.Methods,
+	}
+}
+
+// APIv1SubscriptionPayments — GET /api/v1/subscriptions/:id/payments: оплаты абонемента
+func APIv1SubscriptionPayments(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	sub, err := data.Subscriptions().Get(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Абонемент не найден", nil)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки абонемента", err)
+	}
+	list, err := data.Payments().ListBySubscription(ctx, id)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки платежей", err)
+	}
+	payload := ledgerPayload(sub.Price, list)
+	payload["title"] = "Абонемент #" + strconv.Itoa(sub.ID) + " — " + sub.ClientName + ", " + sub.TariffName
+	return jsonOK(c, payload)
+}
+
+// APIv1PersonalTrainingPayments — GET /api/v1/personal-trainings/:id/payments:
+// оплаты персональной тренировки
+func APIv1PersonalTrainingPayments(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	p, err := data.Trainings().GetPersonal(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Тренировка не найдена", nil)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки тренировки", err)
+	}
+	list, err := data.Payments().ListByPersonalTraining(ctx, id)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки платежей", err)
+	}
+	payload := ledgerPayload(ledger.PersonalPrice(p.Status, p.Price), list)
+	payload["title"] = "Персональная #" + strconv.Itoa(p.ID) + " — " + p.ClientName + ", " + p.StartTime.Format(visitTimeFormat)
+	return jsonOK(c, payload)
+}
+
+type paymentForm struct {
+	SubscriptionID     int    `form:"subscription_id"`
+	PersonalTrainingID int    `form:"personal_training_id"`
+	Kind               string `form:"kind"` // пусто — оплата
+	Amount             string `form:"amount"`
+	Method             string `form:"method"`
+	Comment            string `form:"comment"`
+}
+
+// parsePaymentForm разбирает форму платежа; при ошибке ответ уже отправлен.
+func parsePaymentForm(c *fiber.Ctx) (store.PaymentInput, bool, error) {
+	var f paymentForm
+	if err := c.BodyParser(&f); err != nil {
+		return store.PaymentInput{}, false, jsonError(c, 400, "Неверные данные формы", err)
+	}
+	if (f.SubscriptionID > 0) == (f.PersonalTrainingID > 0) {
+		return store.PaymentInput{}, false, jsonError(c, 400, "Укажите абонемент или персональную тренировку", nil)
+	}
+	if f.Kind == "" {
+		f.Kind = ledger.KindPayment
+	}
+	if !ledger.ValidKind(f.Kind) {
+		return store.PaymentInput{}, false, jsonError(c, 400, "Недопустимый вид платежа", nil)
+	}
+	if !ledger.ValidMethod(f.Method) {
+		return store.PaymentInput{}, false, jsonError(c, 400, "Недопустимый способ оплаты", nil)
+	}
+	amount, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(f.Amount), ",", ".", 1), 64)
+	if err != nil || ledger.Cents(amount) <= 0 {
+		return store.PaymentInput{}, false, jsonError(c, 400, "Сумма должна быть больше нуля", err)
+	}
+	comment := strings.TrimSpace(f.Comment)
+	if len([]rune(comment)) > 200 {
+		return store.PaymentInput{}, false, jsonError(c, 400, "Комментарий длиннее 200 символов", nil)
+	}
+	return store.PaymentInput{
+		SubscriptionID:     f.SubscriptionID,
+		PersonalTrainingID: f.PersonalTrainingID,
+		Kind:               f.Kind,
+		Amount:             float64(ledger.Cents(amount)) / 100,
+		Method:             f.Method,
+		Comment:            comment,
+	}, true, nil
+}
+
+// APIv1CreatePayment — POST /api/v1/payments: принять оплату (можно частями)
+// или оформить возврат. Возврат — только администратор. Оплата больше
+// остатка и возврат больше оплаченного — 409 с кодом reason.
+func APIv1CreatePayment(c *fiber.Ctx) error {
+	in, ok, err := parsePaymentForm(c)
+	if !ok {
+		return err
+	}
+	me := currentStaff(c)
+	if in.Kind == ledger.KindRefund && !me.HasRole(auth.RoleAdmin) {
+		return jsonError(c, 403, "Возврат оформляет администратор", nil)
+	}
+	if me != nil {
+		in.StaffID, in.Cashier = me.ID, me.Login
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+
+	var (
+		id   int
+		paid float64
+	)
+	err = inTx(ctx, c, func(tx store.Store) error {
+		var (
+			price float64
+			list  []models.Payment
+		)
+		// строка того, за что платят, блокируется до суммирования оплат:
+		// параллельные платежи по одному абонементу проверяются по очереди
+		if in.SubscriptionID > 0 {
+			if err := tx.Subscriptions().Lock(ctx, in.SubscriptionID); err != nil {
+				return err
+			}
+			sub, err := tx.Subscriptions().Get(ctx, in.SubscriptionID)
+			if err != nil {
+				return err
+			}
+			in.ClientID, price = sub.ClientID, sub.Price
+			if list, err = tx.Payments().ListBySubscription(ctx, sub.ID); err != nil {
+				return err
+			}
+		} else {
+			if err := tx.Trainings().LockPersonal(ctx, in.PersonalTrainingID); err != nil {
+				return err
+			}
+			p, err := tx.Trainings().GetPersonal(ctx, in.PersonalTrainingID)
+			if err != nil {
+				return err
+			}
+			in.ClientID, price = p.ClientID, ledger.PersonalPrice(p.Status, p.Price)
+			if list, err = tx.Payments().ListByPersonalTraining(ctx, p.ID); err != nil {
+				return err
+			}
+		}
+		paid = sumPaid(list)
+		if err := ledger.Check(in.Kind, in.Amount, price, paid); err != nil {
+			return err
+		}
+		var err error
+		if id, err = tx.Payments().Create(ctx, in); err != nil {
+			return err
+		}
+		paid += models.Payment{Kind: in.Kind, Amount: in.Amount}.Signed()
+		return nil
+	})
+	var rule *ledger.Error
+	switch {
+	case errors.As(err, &rule):
+		return jsonReject(c, fiber.StatusConflict, string(rule.Reason), rule.Msg, nil)
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Абонемент или тренировка не найдены", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка сохранения платежа", err)
+	}
+	message := "Оплата принята"
+	if in.Kind == ledger.KindRefund {
+		message = "Возврат оформлен"
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"id":      id,
+		"message": message,
+		"paid":    paid,
+	})
+}
+
+type dueDTO struct {
+	Item   string  `json:"item"`
+	ID     int     `json:"id"`
+	Title  string  `json:"title"`
+	Date   string  `json:"date"`
+	Price  float64 `json:"price"`
+	Paid   float64 `json:"paid"`
+	Owed   float64 `json:"owed"`
+	Status string  `json:"status"`
+}
+
+func toDueDTO(d store.Due) dueDTO {
+	return dueDTO{
+		Item:   d.Item,
+		ID:     d.ID,
+		Title:  d.Title,
+		Date:   d.Date.Format(dateDisplayFormat),
+		Price:  d.Price,
+		Paid:   d.Paid,
+		Owed:   ledger.Owed(d.Price, d.Paid),
+		Status: ledger.Status(d.Price, d.Paid),
+	}
+}
+
+// APIv1ClientBalance — GET /api/v1/clients/:id/balance: начислено, оплачено
+// и баланс клиента (отрицательный — долг) по позициям, плюс история платежей
+func APIv1ClientBalance(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	dues, err := data.Payments().Dues(ctx, store.DueFilter{ClientID: id})
+	if err != nil {
+		return jsonError(c, 500, "Ошибка расчёта баланса", err)
+	}
+	payments, err := data.Payments().ListByClient(ctx, id)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки платежей", err)
+	}
+	var charged, paid int64
+	items := make([]dueDTO, 0, len(dues))
+	for _, d := range dues {
+		charged += ledger.Cents(d.Price)
+		paid += ledger.Cents(d.Paid)
+		items = append(items, toDueDTO(d))
+	}
+	return jsonOK(c, fiber.Map{
+		"charged":  float64(charged) / 100,
+		"paid":     float64(paid) / 100,
+		"balance":  float64(paid-charged) / 100,
+		"items":    items,
+		"payments": toPaymentDTOs(payments),
+	})
+}
+
+// ======= Отчёты по деньгам =======
+
+// ReportDebts — POST /about/query/debts: должники и их неоплаченные позиции
+func ReportDebts(c *fiber.Ctx) error {
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	dues, err := data.Payments().Dues(ctx, store.DueFilter{OnlyDebts: true})
+	if err != nil {
+		return jsonError(c, 500, "DB: ошибка выборки долгов", err)
+	}
+	type rowT struct {
+		ClientID int      `json:"id_клиента"`
+		FIO      string   `json:"фио"`
+		Items    int      `json:"позиций"`
+		Owed     float64  `json:"долг"`
+		Details  []dueDTO `json:"позиции"`
+	}
+	var (
+		out   []rowT
+		total int64
+	)
+	for _, d := range dues { // Dues отсортированы по клиенту
+		if len(out) == 0 || out[len(out)-1].ClientID != d.ClientID {
+			out = append(out, rowT{ClientID: d.ClientID, FIO: d.ClientName})
+		}
+		r := &out[len(out)-1]
+		dto := toDueDTO(d)
+		r.Items++
+		r.Owed += dto.Owed
+		r.Details = append(r.Details, dto)
+		total += ledger.Cents(dto.Owed)
+	}
+	return jsonOK(c, fiber.Map{"rows": out, "summary": fiber.Map{"клиентов": len(out), "долг": float64(total) / 100}})
+}
+
+// ReportCashDaily — POST /about/query/cash-daily: деньги по дням и способам
+// оплаты за период (start_date, end_date включительно)
+func ReportCashDaily(c *fiber.Ctx) error {
+	start, err := time.Parse("2006-01-02", c.FormValue("start_date"))
+	if err != nil {
+		return jsonError(c, 400, "Неверная дата начала", err)
+	}
+	end, err := time.Parse("2006-01-02", c.FormValue("end_date"))
+	if err != nil {
+		return jsonError(c, 400, "Неверная дата окончания", err)
+	}
+	if end.Before(start) {
+		return jsonError(c, 400, "Дата окончания раньше даты начала", nil)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	totals, err := data.Payments().CashTotals(ctx, start, end)
+	if err != nil {
+		return jsonError(c, 500, "DB: ошибка выборки кассы", err)
+	}
+	type rowT struct {
+		Day      string  `json:"день"`
+		Method   string  `json:"способ"`
+		Count    int     `json:"платежей"`
+		Received float64 `json:"поступило"`
+		Refunded float64 `json:"возвращено"`
+		Net      float64 `json:"итого"`
+	}
+	out := make([]rowT, 0, len(totals))
+	byMethod := map[string]float64{}
+	var net int64
+	for _, t := range totals {
+		n := ledger.Cents(t.Received) - ledger.Cents(t.Refunded)
+		out = append(out, rowT{
+			Day:      t.Day.Format(dateDisplayFormat),
+			Method:   t.Method,
+			Count:    t.Count,
+			Received: t.Received,
+			Refunded: t.Refunded,
+			Net:      float64(n) / 100,
+		})
+		byMethod[t.Method] += float64(n) / 100
+		net += n
+	}
+	return jsonOK(c, fiber.Map{"rows": out, "summary": fiber.Map{"итого": float64(net) / 100, "по_способам": byMethod}})
+}
@@ -0,0 +1,430 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fitness-center-manager/internal/checkin"
+	"fitness-center-manager/internal/ledger"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/payroll"
+	"fitness-center-manager/internal/store"
+	"fitness-center-manager/internal/xlsx"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type payrollLineDTO struct {
+	Kind        string  `json:"kind"`
+	KindTitle   string  `json:"kind_title"`
+	TrainingID  int     `json:"training_id"`
+	Time        string  `json:"time"`
+	Description string  `json:"description"`
+	Base        float64 `json:"base"` // стоимость персональной или заполняемость групповой, %
+	Amount      float64 `json:"amount"`
+}
+
+func toPayrollLineDTO(l models.PayrollLine) payrollLineDTO {
+	return payrollLineDTO{l.Kind, payroll.Kinds[l.Kind], l.TrainingID, l.Time.Format(visitTimeFormat),
+		l.Description, l.Base, l.Amount}
+}
+
+// trainerStatement — ведомость тренера за месяц. У закрытого месяца Lines —
+// сохранённые при закрытии строки, а LiveTotal — сумма по текущим данным:
+// расхождение (Changed) показывается, но выплаченное не меняет.
+type trainerStatement struct {
+	Rule      models.PayRule
+	Lines     []models.PayrollLine
+	Total     float64
+	LiveTotal float64
+	Changed   bool
+}
+
+type payrollStatement struct {
+	Month    time.Time
+	Closed   bool
+	ClosedAt time.Time
+	Trainers []trainerStatement
+}
+
+// liveLines — начисления тренера с правилом rule за месяц по текущим данным.
+func liveLines(ctx context.Context, tx store.Store, rule models.PayRule, month time.Time) ([]models.PayrollLine, error) {
+	if !rule.Configured {
+		return nil, nil
+	}
+	from, to := payroll.Bounds(month)
+	groups, personal, err := tx.Payroll().Work(ctx, rule.TrainerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return payroll.Lines(rule, groups, personal, clubWallNow()), nil
+}
+
+// loadStatement — ведомость за месяц по тренеру trainerID (0 — по всем).
+func loadStatement(ctx context.Context, month time.Time, trainerID int) (payrollStatement, error) {
+	st := payrollStatement{Month: month}
+	var rules []models.PayRule
+	if trainerID > 0 {
+		r, err := data.Payroll().Rule(ctx, trainerID)
+		if err != nil {
+			return st, err
+		}
+		rules = []models.PayRule{r}
+	} else {
+		var err error
+		if rules, err = data.Payroll().Rules(ctx); err != nil {
+			return st, err
+		}
+	}
+
+	closed := map[int][]models.PayrollLine{}
+	period, err := data.Payroll().Period(ctx, month)
+	switch {
+	case err == nil:
+		st.Closed, st.ClosedAt = true, period.ClosedAt
+		lines, err := data.Payroll().ClosedLines(ctx, period.ID, trainerID)
+		if err != nil {
+			return st, err
+		}
+		for _, l := range lines {
+			closed[l.TrainerID] = append(closed[l.TrainerID], l)
+		}
+	case !errors.Is(err, store.ErrNotFound):
+		return st, err
+	}
+
+	for _, r := range rules {
+		live, err := liveLines(ctx, data, r, month)
+		if err != nil {
+			return st, err
+		}
+		t := trainerStatement{Rule: r, Lines: live, LiveTotal: payroll.Total(live)}
+		if st.Closed {
+			t.Lines = closed[r.TrainerID]
+		}
+		t.Total = payroll.Total(t.Lines)
+		t.Changed = ledger.Cents(t.Total) != ledger.Cents(t.LiveTotal)
+		st.Trainers = append(st.Trainers, t)
+	}
+	return st, nil
+}
+
+// monthParam — расчётный месяц из ?month=ГГГГ-ММ (пусто — текущий).
+func monthParam(c *fiber.Ctx, s string) (time.Time, bool, error) {
+	if s == "" {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), true, nil
+	}
+	m, err := payroll.Month(s)
+	if err != nil {
+		return time.Time{}, false, jsonError(c, 400, err.Error(), nil)
+	}
+	return m, true, nil
+}
+
+func statementError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Тренер не найден", nil)
+	}
+	return jsonError(c, 500, "Ошибка расчёта зарплаты", err)
+}
+
+// GetPayrollPage — /payroll: правила оплаты и ведомости по месяцам
+func GetPayrollPage(c *fiber.Ctx) error {
+	return c.Render("payroll", fiber.Map{
+		"Title":        "Зарплата тренеров",
+		"Month":        time.Now().Format("2006-01"),
+		"ExtraScripts": templateScript("/static/js/payroll.js"),
+	})
+}
+
+type payRuleDTO struct {
+	TrainerID       int     `json:"trainer_id"`
+	TrainerName     string  `json:"trainer_name"`
+	Configured      bool    `json:"configured"`
+	PerGroup        float64 `json:"per_group"`
+	PersonalPercent float64 `json:"personal_percent"`
+	FillThreshold   float64 `json:"fill_threshold"`
+	FillBonus       float64 `json:"fill_bonus"`
+}
+
+func toPayRuleDTO(r models.PayRule) payRuleDTO {
+	return payRuleDTO{r.TrainerID, r.TrainerName, r.Configured, r.PerGroup, r.PersonalPercent, r.FillThreshold, r.FillBonus}
+}
+
+// APIv1PayRules — GET /api/v1/payroll/rules: все тренеры с правилами оплаты.
+func APIv1PayRules(c *fiber.Ctx) error {
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	rules, err := data.Payroll().Rules(ctx)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки правил оплаты", err)
+	}
+	out := make([]payRuleDTO, 0, len(rules))
+	for _, r := range rules {
+		out = append(out, toPayRuleDTO(r))
+	}
+	return jsonOK(c, fiber.Map{"rules": out})
+}
+
+// amountField — неотрицательное число из формы (пусто — 0); max > 0 —
+// верхняя граница.
+func amountField(c *fiber.Ctx, name, title string, max float64) (float64, bool, error) {
+	s := strings.TrimSpace(c.FormValue(name))
+	if s == "" {
+		return 0, true, nil
+	}
+	v, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+	if err != nil || v < 0 || (max > 0 && v > max) {
+		if max > 0 {
+			return 0, false, jsonError(c, 400, fmt.Sprintf("%s — от 0 до %.0f", title, max), nil)
+		}
+		return 0, false, jsonError(c, 400, title+" — неотрицательное число", nil)
+	}
+	return v, true, nil
+}
+
+// APIv1SetPayRule — PUT /api/v1/trainers/:id/pay-rule (per_group,
+// personal_percent, fill_threshold, fill_bonus). Закрытые месяцы не
+// пересчитываются.
+func APIv1SetPayRule(c *fiber.Ctx) error {
+	id, ok, err := trainerParam(c)
+	if !ok {
+		return err
+	}
+	rule := models.PayRule{TrainerID: id}
+	if rule.PerGroup, ok, err = amountField(c, "per_group", "Оплата за групповую", 0); !ok {
+		return err
+	}
+	if rule.PersonalPercent, ok, err = amountField(c, "personal_percent", "Процент от персональной", 100); !ok {
+		return err
+	}
+	if rule.FillThreshold, ok, err = amountField(c, "fill_threshold", "Порог заполняемости", 100); !ok {
+		return err
+	}
+	if rule.FillBonus, ok, err = amountField(c, "fill_bonus", "Бонус за заполняемость", 0); !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		if _, err := tx.Payroll().Rule(ctx, id); err != nil {
+			return err
+		}
+		return tx.Payroll().SetRule(ctx, rule)
+	})
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Тренер не найден", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка сохранения правил оплаты", err)
+	}
+	return jsonOK(c, fiber.Map{"message": "Правила оплаты сохранены"})
+}
+
+type payrollSummaryDTO struct {
+	TrainerID   int     `json:"trainer_id"`
+	TrainerName string  `json:"trainer_name"`
+	Configured  bool    `json:"configured"`
+	Lines       int     `json:"lines"`
+	Total       float64 `json:"total"`
+	LiveTotal   float64 `json:"live_total"`
+	Changed     bool    `json:"changed"` // закрытый месяц: данные изменились после закрытия
+}
+
+func periodInfo(st payrollStatement) fiber.Map {
+	m := fiber.Map{"month": st.Month.Format("2006-01"), "closed": st.Closed, "closed_at": ""}
+	if st.Closed {
+		m["closed_at"] = st.ClosedAt.Format(visitTimeFormat)
+	}
+	return m
+}
+
+// APIv1Payroll — GET /api/v1/payroll?month=ГГГГ-ММ: итоги по тренерам за
+// месяц. У закрытого месяца суммы — зафиксированные при закрытии.
+func APIv1Payroll(c *fiber.Ctx) error {
+	month, ok, err := monthParam(c, c.Query("month"))
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	st, err := loadStatement(ctx, month, 0)
+	if err != nil {
+		return statementError(c, err)
+	}
+	out := make([]payrollSummaryDTO, 0, len(st.Trainers))
+	var total []models.PayrollLine
+	for _, t := range st.Trainers {
+		out = append(out, payrollSummaryDTO{t.Rule.TrainerID, t.Rule.TrainerName, t.Rule.Configured,
+			len(t.Lines), t.Total, t.LiveTotal, st.Closed && t.Changed})
+		total = append(total, t.Lines...)
+	}
+	res := periodInfo(st)
+	res["trainers"], res["total"] = out, payroll.Total(total)
+	return jsonOK(c, res)
+}
+
+// APIv1TrainerPayroll — GET /api/v1/payroll/:trainer_id?month=: ведомость
+// тренера построчно.
+func APIv1TrainerPayroll(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("trainer_id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	month, ok, err := monthParam(c, c.Query("month"))
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	st, err := loadStatement(ctx, month, id)
+	if err != nil {
+		return statementError(c, err)
+	}
+	t := st.Trainers[0]
+	lines := make([]payrollLineDTO, 0, len(t.Lines))
+	for _, l := range t.Lines {
+		lines = append(lines, toPayrollLineDTO(l))
+	}
+	res := periodInfo(st)
+	res["rule"], res["lines"] = toPayRuleDTO(t.Rule), lines
+	res["total"], res["live_total"], res["changed"] = t.Total, t.LiveTotal, st.Closed && t.Changed
+	return jsonOK(c, res)
+}
+
+// APIv1ExportPayroll — GET /api/v1/payroll/export?month=&format=csv|xlsx:
+// ведомость месяца файлом. В CSV — все строки с тренером в первом столбце
+// (разделитель «;», UTF-8 с BOM — так его открывает Excel); в XLSX — листы
+// «Сводка» и «Начисления».
+func APIv1ExportPayroll(c *fiber.Ctx) error {
+	month, ok, err := monthParam(c, c.Query("month"))
+	if !ok {
+		return err
+	}
+	format := c.Query("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		return jsonError(c, 400, "Формат — csv или xlsx", nil)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	st, err := loadStatement(ctx, month, 0)
+	if err != nil {
+		return statementError(c, err)
+	}
+
+	header := []any{"Тренер", "Вид", "id тренировки", "Время", "Описание", "База", "Сумма"}
+	lines := [][]any{header}
+	summary := [][]any{{"Тренер", "Строк", "Итого"}}
+	for _, t := range st.Trainers {
+		if len(t.Lines) == 0 {
+			continue
+		}
+		summary = append(summary, []any{t.Rule.TrainerName, len(t.Lines), t.Total})
+		for _, l := range t.Lines {
+			lines = append(lines, []any{t.Rule.TrainerName, payroll.Kinds[l.Kind], l.TrainingID,
+				l.Time.Format(visitTimeFormat), l.Description, l.Base, l.Amount})
+		}
+	}
+
+	name := "payroll-" + month.Format("2006-01")
+	var buf bytes.Buffer
+	if format == "xlsx" {
+		if err := xlsx.Write(&buf,
+			xlsx.Sheet{Name: "Сводка", Rows: summary},
+			xlsx.Sheet{Name: "Начисления", Rows: lines}); err != nil {
+			return jsonError(c, 500, "Ошибка выгрузки", err)
+		}
+		c.Set(fiber.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	} else {
+		buf.WriteString("\ufeff")
+		w := csv.NewWriter(&buf)
+		w.Comma = ';'
+		for _, row := range lines {
+			rec := make([]string, len(row))
+			for i, v := range row {
+				switch x := v.(type) {
+				case float64:
+					rec[i] = strings.Replace(strconv.FormatFloat(x, 'f', 2, 64), ".", ",", 1)
+				default:
+					rec[i] = fmt.Sprint(x)
+				}
+			}
+			_ = w.Write(rec)
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return jsonError(c, 500, "Ошибка выгрузки", err)
+		}
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	}
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	return c.Send(buf.Bytes())
+}
+
+// APIv1ClosePayroll — POST /api/v1/payroll/periods (month): закрыть
+// закончившийся месяц. Начисления сохраняются строками, и дальнейшие
+// правки тренировок этого месяца их не меняют.
+func APIv1ClosePayroll(c *fiber.Ctx) error {
+	month, err := payroll.Month(c.FormValue("month"))
+	if err != nil {
+		return jsonError(c, 400, err.Error(), nil)
+	}
+	if _, to := payroll.Bounds(month); to.After(checkin.Day(time.Now())) {
+		return jsonError(c, 409, "Месяц ещё не закончился", nil)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var total []models.PayrollLine
+	err = inTx(ctx, c, func(tx store.Store) error {
+		rules, err := tx.Payroll().Rules(ctx)
+		if err != nil {
+			return err
+		}
+		for _, r := range rules {
+			lines, err := liveLines(ctx, tx, r, month)
+			if err != nil {
+				return err
+			}
+			total = append(total, lines...)
+		}
+		_, err = tx.Payroll().ClosePeriod(ctx, month, total)
+		return err
+	})
+	switch {
+	case errors.Is(err, store.ErrDuplicate):
+		return jsonError(c, 409, "Месяц уже закрыт", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка закрытия месяца", err)
+	}
+	return jsonOK(c, fiber.Map{
+		"message": fmt.Sprintf("Месяц %s закрыт: начислено %.2f ₽", month.Format("01.2006"), payroll.Total(total)),
+	})
+}
+
+// APIv1ReopenPayroll — DELETE /api/v1/payroll/periods/:month: переоткрыть
+// месяц; сохранённые строки удаляются, суммы снова считаются по данным.
+func APIv1ReopenPayroll(c *fiber.Ctx) error {
+	month, err := payroll.Month(c.Params("month"))
+	if err != nil {
+		return jsonError(c, 400, err.Error(), nil)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		return tx.Payroll().ReopenPeriod(ctx, month)
+	})
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Месяц не закрыт", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка переоткрытия месяца", err)
+	}
+	return jsonOK(c, fiber.Map{"message": "Месяц " + month.Format("01.2006") + " переоткрыт"})
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"fitness-center-manager/internal/blob"
+	"fitness-center-manager/internal/photo"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// blobs — хранилище файлов для фото зон, оборудования и заявок.
+var blobs blob.Store
+
+// SetBlobStore задаёт хранилище фото (см. storage в config.yaml).
+func SetBlobStore(b blob.Store) { blobs = b }
+
+// imageType — MIME по первым байтам файла; не картинка — octet-stream.
+func imageType(img []byte) string {
+	if len(img) > 512 {
+		img = img[:512]
+	}
+	if ct := http.DetectContentType(img); strings.HasPrefix(ct, "image/") {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+// savePhoto обрабатывает загруженное фото (без метаданных, с учётом
+// поворота, с миниатюрой и средним размером) и кладёт в хранилище.
+// Нечитаемая картинка — 400.
+func savePhoto(ctx context.Context, c *fiber.Ctx, img []byte) (string, bool, error) {
+	key, err := photo.Save(ctx, blobs, img)
+	switch {
+	case errors.Is(err, photo.ErrUnsupported), errors.Is(err, photo.ErrTooLarge):
+		return "", false, jsonError(c, fiber.StatusBadRequest, err.Error(), nil)
+	case err != nil:
+		return "", false, jsonError(c, fiber.StatusInternalServerError, "Ошибка сохранения фото", err)
+	}
+	return key, true, nil
+}
+
+// releasePhoto удаляет файл (со всеми размерами), на который больше не
+// ссылается ни одна запись (фото заменили или удалили). Ошибки только логируются: запись уже
+// сохранена, лишний файл в хранилище ничего не ломает.
+func releasePhoto(key string) {
+	if key == "" {
+		return
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	used, err := data.Photos().InUse(ctx, key)
+	if err == nil && !used {
+		err = photo.Remove(ctx, blobs, key)
+	}
+	if err != nil {
+		log.Printf("⚠️  Фото %s: не удалось удалить из хранилища: %v", key, err)
+	}
+}
+
+// readCloser — буферизованное чтение с закрытием исходного файла.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// sendPhoto отдаёт фото для <img> в размере ?size=thumb|medium|original
+// (по умолчанию original). ETag — ключ файла этого размера, поэтому
+// повторный запрос с If-None-Match не трогает хранилище. Фото, ещё не
+// перенесённые из БД, отдаются как есть в любом размере.
+func sendPhoto(c *fiber.Ctx, p store.Photo) error {
+	size, ok := photo.ParseSize(c.Query("size"))
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).SendString("size: thumb, medium или original")
+	}
+	if p.Empty() {
+		return c.Status(fiber.StatusNotFound).SendString("Фото отсутствует")
+	}
+	key := photo.VariantKey(p.Key, size)
+	if p.Key == "" {
+		key = blob.Key(p.Legacy)
+	}
+	etag := `"` + key + `"`
+	c.Set("ETag", etag)
+	c.Set("Cache-Control", "public, max-age=3600")
+	if c.Get("If-None-Match") == etag {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	if p.Key == "" {
+		c.Set("Content-Type", imageType(p.Legacy))
+		return c.Send(p.Legacy)
+	}
+	// тело читается уже после выхода из хэндлера — контекст без отмены,
+	// время ограничивает само хранилище
+	rc, n, err := photo.Open(context.Background(), blobs, p.Key, size)
+	if errors.Is(err, blob.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).SendString("Файл фото не найден в хранилище")
+	}
+	if err != nil {
+		log.Printf("❌ Фото %s: %v", p.Key, err)
+		return c.Status(fiber.StatusInternalServerError).SendString("Ошибка чтения фото")
+	}
+	br := bufio.NewReaderSize(rc, 512)
+	head, _ := br.Peek(512)
+	c.Set("Content-Type", imageType(head))
+	return c.SendStream(readCloser{br, rc}, int(n))
+}
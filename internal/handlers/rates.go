@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"fitness-center-manager/internal/access"
+	"fitness-center-manager/internal/checkin"
+	"fitness-center-manager/internal/ledger"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/pricing"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// pricePersonal — стоимость персональной in по ставке тренера на день
+// тренировки, если стоимость не введена: Price и снимок ставки Rate.
+// Нет ставки — тренировка остаётся без стоимости, как раньше.
+func pricePersonal(ctx context.Context, tx store.Store, in *store.PersonalTrainingInput) error {
+	in.Rate = nil
+	if in.Price != nil {
+		return nil
+	}
+	rates, err := tx.Rates().List(ctx, in.TrainerID)
+	if err != nil {
+		return err
+	}
+	rate, ok := pricing.Current(rates, checkin.Day(in.Start))
+	if !ok {
+		return nil
+	}
+	snap := pricing.Quote(rate, in.Start, in.End)
+	in.Price, in.Rate = &snap.Price, &snap
+	return nil
+}
+
+// repricePersonal — стоимость при правке персональной old. Посчитанная по
+// ставке стоимость (пустая или совпадающая с прежней) пересчитывается,
+// только если сменились тренер или время, иначе остаётся прежней вместе
+// со снимком ставки — даже если ставки с тех пор поменялись.
+func repricePersonal(ctx context.Context, tx store.Store, in *store.PersonalTrainingInput, old models.PersonalTraining) error {
+	if old.Rate != nil && in.Price != nil && ledger.Cents(*in.Price) == ledger.Cents(old.Price) {
+		in.Price = nil
+	}
+	moved := in.TrainerID != old.TrainerID || !in.Start.Equal(old.StartTime) || !in.End.Equal(old.EndTime)
+	if in.Price == nil && old.Rate != nil && !moved {
+		in.Price, in.Rate = &old.Price, old.Rate
+		return nil
+	}
+	return pricePersonal(ctx, tx, in)
+}
+
+type rateDTO struct {
+	ID               int     `json:"id"`
+	EffectiveFrom    string  `json:"effective_from"` // YYYY-MM-DD
+	Category         string  `json:"category"`
+	HourlyRate       float64 `json:"hourly_rate"`
+	PeakHours        string  `json:"peak_hours"` // пусто — пиковых часов нет
+	PeakSurcharge    float64 `json:"peak_surcharge"`
+	WeekendSurcharge float64 `json:"weekend_surcharge"`
+	Current          bool    `json:"current"` // действует сегодня
+}
+
+// APIv1TrainerRates — GET /api/v1/trainers/:id/rates: ставки тренера,
+// новые первыми; current — действующая сегодня.
+func APIv1TrainerRates(c *fiber.Ctx) error {
+	id, ok, err := trainerParam(c)
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	if _, err := data.Availability().Hours(ctx, id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return jsonError(c, 404, "Тренер не найден", nil)
+		}
+		return jsonError(c, 500, "Ошибка загрузки тренера", err)
+	}
+	rates, err := data.Rates().List(ctx, id)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки ставок", err)
+	}
+	current, _ := pricing.Current(rates, checkin.Day(time.Now()))
+	out := make([]rateDTO, 0, len(rates))
+	for _, r := range rates {
+		peak := ""
+		if !r.PeakHours.Unrestricted() {
+			peak = r.PeakHours.String()
+		}
+		out = append(out, rateDTO{r.ID, r.EffectiveFrom.Format("2006-01-02"), r.Category, r.HourlyRate,
+			peak, r.PeakSurcharge, r.WeekendSurcharge, r.ID == current.ID})
+	}
+	return jsonOK(c, fiber.Map{"rates": out})
+}
+
+// percentField — надбавка в процентах из формы (пусто — 0).
+func percentField(c *fiber.Ctx, name, title string) (float64, bool, error) {
+	s := strings.TrimSpace(c.FormValue(name))
+	if s == "" {
+		return 0, true, nil
+	}
+	v, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+	if err != nil || v < 0 || v > 500 {
+		return 0, false, jsonError(c, 400, title+" — от 0 до 500 %", nil)
+	}
+	return v, true, nil
+}
+
+// APIv1CreateRate — POST /api/v1/trainers/:id/rates (effective_from,
+// category, hourly_rate, peak_hours, peak_surcharge, weekend_surcharge).
+// Уже посчитанные тренировки не пересчитываются.
+func APIv1CreateRate(c *fiber.Ctx) error {
+	trainerID, ok, err := trainerParam(c)
+	if !ok {
+		return err
+	}
+	in := store.RateInput{Category: strings.TrimSpace(c.FormValue("category"))}
+	if in.EffectiveFrom, err = time.Parse("2006-01-02", c.FormValue("effective_from")); err != nil {
+		return jsonError(c, 400, "Неверная дата начала действия", err)
+	}
+	if in.Category == "" || len([]rune(in.Category)) > 50 {
+		return jsonError(c, 400, "Укажите категорию (до 50 символов)", nil)
+	}
+	in.HourlyRate, err = strconv.ParseFloat(strings.Replace(strings.TrimSpace(c.FormValue("hourly_rate")), ",", ".", 1), 64)
+	if err != nil || in.HourlyRate < 0 {
+		return jsonError(c, 400, "Неверная ставка за час", nil)
+	}
+	if in.PeakHours, err = access.Parse(c.FormValue("peak_hours")); err != nil {
+		return jsonError(c, 400, "Пиковые часы: "+err.Error(), nil)
+	}
+	if in.PeakSurcharge, ok, err = percentField(c, "peak_surcharge", "Надбавка за пиковые часы"); !ok {
+		return err
+	}
+	if in.WeekendSurcharge, ok, err = percentField(c, "weekend_surcharge", "Надбавка за выходные"); !ok {
+		return err
+	}
+
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var id int
+	err = inTx(ctx, c, func(tx store.Store) error {
+		if _, err := tx.Availability().Hours(ctx, trainerID); err != nil {
+			return err
+		}
+		id, err = tx.Rates().Create(ctx, trainerID, in)
+		return err
+	})
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Тренер не найден", nil)
+	case errors.Is(err, store.ErrDuplicate):
+		return jsonError(c, 409, "С этой даты у тренера уже есть ставка", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка сохранения ставки", err)
+	}
+	return jsonOK(c, fiber.Map{"id": id, "message": "Ставка действует с " + in.EffectiveFrom.Format(dateDisplayFormat)})
+}
+
+// APIv1DeleteRate — DELETE /api/v1/trainer-rates/:id: удалить можно только
+// ещё не вступившую в силу ставку; действующую заменяют новой.
+func APIv1DeleteRate(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var started bool
+	err = inTx(ctx, c, func(tx store.Store) error {
+		r, err := tx.Rates().Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !r.EffectiveFrom.After(checkin.Day(time.Now())) {
+			started = true
+			return nil
+		}
+		return tx.Rates().Delete(ctx, id)
+	})
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Ставка не найдена", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка удаления ставки", err)
+	case started:
+		return jsonError(c, 409, "Ставка уже действует — добавьте новую с нужной даты", nil)
+	}
+	return jsonOK(c, fiber.Map{"message": "Ставка удалена"})
+}
+
+// APIv1PriceQuote — GET /api/v1/trainers/:id/price-quote?date=&start_time=&end_time=:
+// стоимость персональной по ставке тренера, без сохранения.
+func APIv1PriceQuote(c *fiber.Ctx) error {
+	id, ok, err := trainerParam(c)
+	if !ok {
+		return err
+	}
+	start, err1 := time.Parse("2006-01-02 15:04", c.Query("date")+" "+c.Query("start_time"))
+	end, err2 := time.Parse("2006-01-02 15:04", c.Query("date")+" "+c.Query("end_time"))
+	if err1 != nil || err2 != nil || !end.After(start) {
+		return jsonError(c, 400, "Некорректное время начала/окончания", nil)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	in := store.PersonalTrainingInput{TrainerID: id, Start: start, End: end}
+	if err := pricePersonal(ctx, data, &in); err != nil {
+		return jsonError(c, 500, "Ошибка расчёта стоимости", err)
+	}
+	if in.Rate == nil {
+		return jsonOK(c, fiber.Map{"quote": nil, "message": "У тренера нет ставки на этот день — стоимость вводится вручную"})
+	}
+	return jsonOK(c, fiber.Map{"quote": in.Rate})
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"fitness-center-manager/internal/checkin"
+	"fitness-center-manager/internal/repair"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// reliabilityDays — период отчёта о надёжности по умолчанию.
+const reliabilityDays = 90
+
+// GetReliabilityPage — отказы, MTBF/MTTR и простой оборудования (администратор и техник).
+func GetReliabilityPage(c *fiber.Ctx) error {
+	today := checkin.Day(time.Now())
+	return c.Render("reliability", fiber.Map{
+		"Title":        "Надёжность оборудования",
+		"From":         today.AddDate(0, 0, -reliabilityDays+1).Format("2006-01-02"),
+		"To":           today.Format("2006-01-02"),
+		"ExtraScripts": templateScript("/static/js/reliability.js"),
+	})
+}
+
+// APIv1EquipmentReliability — GET /api/v1/reports/equipment-reliability?from=&to=&limit=:
+// по единицам и зонам — число отказов, MTBF, MTTR, простой и доступность
+// за период (даты ГГГГ-ММ-ДД включительно, по умолчанию — 90 дней по
+// сегодня) и limit худших единиц (по умолчанию 5).
+func APIv1EquipmentReliability(c *fiber.Ctx) error {
+	to := checkin.Day(time.Now())
+	from := to.AddDate(0, 0, -reliabilityDays+1)
+	for _, p := range []struct {
+		key string
+		dst *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		if v := c.Query(p.key); v != "" {
+			t, err := time.ParseInLocation("2006-01-02", v, time.UTC)
+			if err != nil {
+				return jsonError(c, 400, "Неверный формат даты ("+p.key+")", err)
+			}
+			*p.dst = t
+		}
+	}
+	if to.Before(from) {
+		return jsonError(c, 400, "Дата окончания раньше даты начала", nil)
+	}
+	limit := 5
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 50 {
+			return jsonError(c, 400, "limit — от 1 до 50", err)
+		}
+		limit = n
+	}
+	end := to.AddDate(0, 0, 1)
+
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	repairs, err := data.Repairs().List(ctx, store.RepairFilter{From: from, To: end})
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки заявок", err)
+	}
+	equipment, err := data.Equipment().List(ctx, store.EquipmentFilter{})
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки оборудования", err)
+	}
+	items, zones := repair.Analyze(equipment, repairs, from, end, time.Now())
+	if items == nil {
+		items = []repair.Reliability{}
+	}
+	if zones == nil {
+		zones = []repair.Reliability{}
+	}
+	return jsonOK(c, fiber.Map{
+		"from": from.Format("2006-01-02"), "to": to.Format("2006-01-02"),
+		"equipment": items, "zones": zones, "worst": repair.Worst(items, limit),
+	})
+}
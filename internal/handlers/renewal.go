@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"fitness-center-manager/internal/checkin"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/renewal"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type renewForm struct {
+	TariffID int    `form:"tariff_id"` // 0 — тот же тариф
+	EndDate  string `form:"end_date"`  // YYYY-MM-DD; пусто — такой же срок, как у текущего
+}
+
+// APIv1RenewSubscription — POST /api/v1/subscriptions/:id/renew: продлить абонемент.
+// Новый абонемент начинается на следующий день после окончания текущего
+// (см. пакет renewal), цена — текущая стоимость тарифа. Повторное
+// продление — 409 с reason already_renewed и id существующего.
+func APIv1RenewSubscription(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	var f renewForm
+	if err := c.BodyParser(&f); err != nil {
+		return jsonError(c, 400, "Неверные данные формы", err)
+	}
+	var endOverride time.Time
+	if f.EndDate != "" {
+		if endOverride, err = time.Parse("2006-01-02", f.EndDate); err != nil {
+			return jsonError(c, 400, "Неверная дата окончания", err)
+		}
+	}
+
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+
+	var (
+		cur       models.Subscription
+		tariff    models.Tariff
+		in        store.SubscriptionInput
+		newID     int
+		missing   string
+		badPeriod bool
+	)
+	err = inTx(ctx, c, func(tx store.Store) error {
+		// блокировка — против параллельной заморозки, двигающей окончание
+		if err := tx.Subscriptions().Lock(ctx, id); err != nil {
+			missing = "Абонемент не найден"
+			return err
+		}
+		var err error
+		if cur, err = tx.Subscriptions().Get(ctx, id); err != nil {
+			missing = "Абонемент не найден"
+			return err
+		}
+		if cur.RenewalID.Valid {
+			return nil
+		}
+		tariffID := cur.TariffID
+		if f.TariffID > 0 {
+			tariffID = f.TariffID
+		}
+		if tariff, err = tx.Tariffs().Get(ctx, tariffID); err != nil {
+			missing = "Тариф не найден"
+			return err
+		}
+		freezes, err := tx.Freezes().ListBySubscription(ctx, cur.ID)
+		if err != nil {
+			return err
+		}
+		frozen := 0
+		for _, fr := range freezes {
+			frozen += fr.Days()
+		}
+
+		now := time.Now()
+		start, end := renewal.Period(cur, frozen, now)
+		if !endOverride.IsZero() {
+			if endOverride.Before(start) {
+				badPeriod = true
+				return nil
+			}
+			end = endOverride
+		}
+		status := checkin.StatusActive
+		if start.After(checkin.Day(now)) {
+			status = checkin.StatusPending
+		}
+		in = store.SubscriptionInput{
+			ClientID:   cur.ClientID,
+			TariffID:   tariff.ID,
+			StartDate:  start,
+			EndDate:    end,
+			Status:     status,
+			Price:      &tariff.Price,
+			PreviousID: cur.ID,
+		}
+		newID, err = tx.Subscriptions().Create(ctx, in)
+		return err
+	})
+	switch {
+	case missing != "" && errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, missing, nil)
+	case errors.Is(err, store.ErrDuplicate): // параллельное продление того же абонемента
+		return jsonReject(c, fiber.StatusConflict, "already_renewed", "Абонемент уже продлён", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка продления абонемента", err)
+	case cur.RenewalID.Valid:
+		return jsonReject(c, fiber.StatusConflict, "already_renewed", "Абонемент уже продлён",
+			fiber.Map{"renewal_id": cur.RenewalID.Int64})
+	case badPeriod:
+		return jsonError(c, 400, "Дата окончания раньше даты начала", nil)
+	}
+
+	c.Set("Location", "/api/v1/subscriptions/"+strconv.Itoa(newID))
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success":    true,
+		"id":         newID,
+		"message":    "Абонемент продлён: " + tariff.Name + " с " + in.StartDate.Format(dateDisplayFormat) + " по " + in.EndDate.Format(dateDisplayFormat),
+		"start_date": in.StartDate.Format("2006-01-02"),
+		"end_date":   in.EndDate.Format("2006-01-02"),
+		"status":     in.Status,
+		"price":      tariff.Price,
+		"tariff":     tariff.Name,
+	})
+}
+
+type clientSubscriptionDTO struct {
+	ID         int     `json:"id"`
+	TariffName string  `json:"tariff_name"`
+	StartDate  string  `json:"start_date"`
+	EndDate    string  `json:"end_date"`
+	Status     string  `json:"status"`
+	Price      float64 `json:"price"`
+	PreviousID int64   `json:"previous_id,omitempty"` // какой абонемент продлевает
+	RenewalID  int64   `json:"renewal_id,omitempty"`  // каким продлён
+}
+
+// APIv1ClientSubscriptions — GET /api/v1/clients/:id/subscriptions:
+// абонементы клиента со ссылками цепочки продлений, новые сверху
+func APIv1ClientSubscriptions(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	subs, err := data.Subscriptions().ListByClient(ctx, id)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки абонементов", err)
+	}
+	list := make([]clientSubscriptionDTO, 0, len(subs))
+	for _, s := range subs {
+		list = append(list, clientSubscriptionDTO{
+			ID:         s.ID,
+			TariffName: s.TariffName,
+			StartDate:  s.StartDate.Format(dateDisplayFormat),
+			EndDate:    s.EndDate.Format(dateDisplayFormat),
+			Status:     s.Status,
+			Price:      s.Price,
+			PreviousID: s.PreviousID.Int64,
+			RenewalID:  s.RenewalID.Int64,
+		})
+	}
+	return jsonOK(c, fiber.Map{"subscriptions": list})
+}
@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"fitness-center-manager/internal/auth"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/repair"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// repairSLA — сроки устранения по приоритету (equipment.repair_sla_hours).
+var repairSLA = repair.DefaultSLA
+
+// SetRepairSLA задаёт сроки устранения заявок по приоритету.
+func SetRepairSLA(sla repair.SLA) { repairSLA = sla }
+
+var (
+	// errRepairTransition — недопустимая смена статуса заявки.
+	errRepairTransition = errors.New("недопустимая смена статуса")
+	// errRepairComment — закрытие или переоткрытие без комментария.
+	errRepairComment = errors.New("нужен комментарий")
+)
+
+// normRepairPriority — приоритет заявки; неизвестный — «Средний».
+func normRepairPriority(s string) string {
+	switch s = strings.TrimSpace(s); s {
+	case repair.PriorityLow, repair.PriorityMedium, repair.PriorityHigh:
+		return s
+	}
+	return repair.PriorityMedium
+}
+
+// repairActor — сотрудник для записи истории заявки.
+func repairActor(c *fiber.Ctx) (int, string) {
+	if me := currentStaff(c); me != nil {
+		return me.ID, me.FIO
+	}
+	return 0, "—"
+}
+
+// changeRepairStatus переводит заявку r в статус to с комментарием
+// (тот же статус — только комментарий в историю) и синхронизирует
+// статус оборудования. Вызывается внутри транзакции.
+func changeRepairStatus(ctx context.Context, tx store.Store, c *fiber.Ctx, r models.RepairRequest, to, comment string) error {
+	staffID, staffName := repairActor(c)
+	event := models.RepairEvent{RepairID: r.ID, Status: to, Comment: comment, StaffID: staffID, StaffName: staffName}
+	if to == r.Status {
+		if comment == "" {
+			return nil
+		}
+		return tx.Repairs().AddEvent(ctx, event)
+	}
+	if !repair.Allowed(r.Status, to) {
+		return errRepairTransition
+	}
+	if repair.NeedsComment(r.Status, to) && comment == "" {
+		return errRepairComment
+	}
+	if err := tx.Repairs().SetStatus(ctx, r.ID, to); err != nil {
+		return err
+	}
+	event.FromStatus = r.Status
+	if err := tx.Repairs().AddEvent(ctx, event); err != nil {
+		return err
+	}
+	return syncRepairEquipment(ctx, tx, r.EquipmentID)
+}
+
+// syncRepairEquipment приводит статус оборудования к его заявкам: есть
+// незакрытые — «На ремонте», нет — «Исправен» (или «Требует ТО», если
+// включена отметка просроченного ТО). Списанное не трогается.
+func syncRepairEquipment(ctx context.Context, tx store.Store, equipmentID int) error {
+	e, err := tx.Equipment().Get(ctx, equipmentID)
+	if err != nil {
+		return err
+	}
+	n, err := tx.Repairs().OpenCount(ctx, equipmentID)
+	if err != nil {
+		return err
+	}
+	status := e.Status
+	switch {
+	case e.Status == "Списан":
+		return nil
+	case n > 0:
+		status = "На ремонте"
+	case e.Status == "На ремонте":
+		status = "Исправен"
+		if flagOverdueMaintenance {
+			overdue, err := maintenanceOverdue(ctx, tx, equipmentID)
+			if err != nil {
+				return err
+			}
+			if overdue {
+				status = "Требует ТО"
+			}
+		}
+	}
+	if status == e.Status {
+		return nil
+	}
+	return tx.Equipment().SetStatus(ctx, equipmentID, status)
+}
+
+// repairError — ответ на ошибку изменения заявки.
+func repairError(c *fiber.Ctx, err error, title string) error {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Заявка не найдена", nil)
+	case errors.Is(err, errRepairTransition):
+		return jsonError(c, 409, "Недопустимая смена статуса заявки", nil)
+	case errors.Is(err, errRepairComment):
+		return jsonError(c, 400, "При закрытии и переоткрытии заявки нужен комментарий", nil)
+	}
+	return jsonError(c, 500, title, err)
+}
+
+// assigneeParam — исполнитель заявки из поля name: активный техник или
+// администратор; пусто или 0 — не назначен.
+func assigneeParam(c *fiber.Ctx, name string) (int, bool, error) {
+	s := strings.TrimSpace(c.FormValue(name))
+	if s == "" || s == "0" {
+		return 0, true, nil
+	}
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 0 {
+		return 0, false, jsonError(c, 400, "Некорректный исполнитель", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	m, err := data.Staff().Get(ctx, id)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return 0, false, jsonError(c, 500, "Ошибка проверки исполнителя", err)
+	}
+	if err != nil || !m.Active || (m.Role != auth.RoleTechnician && m.Role != auth.RoleAdmin) {
+		return 0, false, jsonError(c, 400, "Исполнителем может быть только активный техник или администратор", nil)
+	}
+	return id, true, nil
+}
+
+// repairAssignees — кого можно назначить исполнителем.
+func repairAssignees() ([]store.StaffMember, error) {
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	return data.Staff().Active(ctx, auth.RoleTechnician, auth.RoleAdmin)
+}
+
+type repairDTO struct {
+	ID            int       `json:"id"`
+	EquipmentID   int       `json:"equipment_id"`
+	EquipmentName string    `json:"equipment_name"`
+	ZoneName      string    `json:"zone_name"`
+	CreatedAt     time.Time `json:"created_at"`
+	Description   string    `json:"description"`
+	Status        string    `json:"status"`
+	Priority      string    `json:"priority"`
+	HasPhoto      bool      `json:"has_photo"`
+	AssigneeID    int       `json:"assignee_id"` // 0 — не назначен
+	AssigneeName  string    `json:"assignee_name"`
+	Deadline      time.Time `json:"deadline"`
+	ClosedAt      *string   `json:"closed_at"`
+	Overdue       bool      `json:"overdue"` // незакрыта, срок прошёл
+	Next          []string  `json:"next"`    // допустимые следующие статусы
+}
+
+func toRepairDTO(r models.RepairRequest, now time.Time) repairDTO {
+	out := repairDTO{ID: r.ID, EquipmentID: r.EquipmentID, EquipmentName: r.EquipmentName, ZoneName: r.ZoneName,
+		CreatedAt: r.CreateDate, Description: r.ProblemDesc, Status: r.Status, Priority: r.Priority,
+		HasPhoto: r.HasPhoto, AssigneeID: r.AssigneeID, AssigneeName: r.AssigneeName,
+		Deadline: r.Deadline, Overdue: repair.Overdue(r, now), Next: repair.Next(r.Status)}
+	if r.ClosedAt.Valid {
+		s := r.ClosedAt.Time.Format(time.RFC3339)
+		out.ClosedAt = &s
+	}
+	if out.Next == nil {
+		out.Next = []string{}
+	}
+	return out
+}
+
+// APIv1ListRepairs — GET /api/v1/repairs?active=1&overdue=1&mine=1&assignee_id=&limit=:
+// незакрытые заявки по сроку, затем закрытые.
+func APIv1ListRepairs(c *fiber.Ctx) error {
+	now := time.Now()
+	f := store.RepairFilter{Active: c.Query("active") == "1", Limit: c.QueryInt("limit", 100)}
+	if c.Query("overdue") == "1" {
+		f.OverdueAt = now
+	}
+	if c.Query("mine") == "1" {
+		if me := currentStaff(c); me != nil {
+			f.AssigneeID = me.ID
+		}
+	} else if s := c.Query("assignee_id"); s != "" {
+		id, err := strconv.Atoi(s)
+		if err != nil || id <= 0 {
+			return jsonError(c, 400, "Некорректный исполнитель", err)
+		}
+		f.AssigneeID = id
+	}
+	if f.Limit <= 0 || f.Limit > 500 {
+		f.Limit = 100
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	list, err := data.Repairs().List(ctx, f)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки заявок", err)
+	}
+	out := make([]repairDTO, 0, len(list))
+	for _, r := range list {
+		out = append(out, toRepairDTO(r, now))
+	}
+	return jsonOK(c, fiber.Map{"repairs": out})
+}
+
+// APIv1RepairAssignees — GET /api/v1/repairs/assignees: активные техники и администраторы.
+func APIv1RepairAssignees(c *fiber.Ctx) error {
+	list, err := repairAssignees()
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки сотрудников", err)
+	}
+	type row struct {
+		ID   int    `json:"id"`
+		FIO  string `json:"fio"`
+		Role string `json:"role"`
+	}
+	out := make([]row, 0, len(list))
+	for _, s := range list {
+		out = append(out, row{s.ID, s.FIO, s.Role})
+	}
+	return jsonOK(c, fiber.Map{"assignees": out})
+}
+
+// APIv1RepairHistory — GET /api/v1/repairs/:id/history: заявка и её история.
+func APIv1RepairHistory(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	r, err := data.Repairs().Get(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Заявка не найдена", nil)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки заявки", err)
+	}
+	history, err := data.Repairs().History(ctx, id)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки истории заявки", err)
+	}
+	type eventDTO struct {
+		Time       time.Time `json:"time"`
+		FromStatus string    `json:"from_status"` // пусто — создание заявки
+		Status     string    `json:"status"`
+		Comment    string    `json:"comment"`
+		StaffName  string    `json:"staff_name"`
+	}
+	out := make([]eventDTO, 0, len(history))
+	for _, e := range history {
+		out = append(out, eventDTO{e.Time, e.FromStatus, e.Status, e.Comment, e.StaffName})
+	}
+	return jsonOK(c, fiber.Map{"repair": toRepairDTO(r, time.Now()), "history": out})
+}
+
+// APIv1RepairStatus — POST /api/v1/repairs/:id/status (status, comment):
+// смена статуса с записью в историю; без смены статуса — просто комментарий.
+func APIv1RepairStatus(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	to := strings.TrimSpace(c.FormValue("status"))
+	comment := strings.TrimSpace(c.FormValue("comment"))
+	switch to {
+	case repair.StatusOpen, repair.StatusInProgress, repair.StatusClosed:
+	case "":
+		if comment == "" {
+			return jsonError(c, 400, "Укажите статус или комментарий", nil)
+		}
+	default:
+		return jsonError(c, 400, "Неизвестный статус заявки", nil)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		r, err := tx.Repairs().Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if to == "" {
+			to = r.Status
+		}
+		return changeRepairStatus(ctx, tx, c, r, to, comment)
+	})
+	if err != nil {
+		return repairError(c, err, "Ошибка смены статуса заявки")
+	}
+	return jsonOK(c, fiber.Map{"message": "Заявка: " + to})
+}
+
+// APIv1AssignRepair — PUT /api/v1/repairs/:id/assignee (staff_id; пусто —
+// снять исполнителя). Смена исполнителя попадает в историю заявки.
+func APIv1AssignRepair(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	staffID, ok, err := assigneeParam(c, "staff_id")
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		r, err := tx.Repairs().Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		return assignRepair(ctx, tx, c, r, staffID)
+	})
+	if err != nil {
+		return repairError(c, err, "Ошибка назначения исполнителя")
+	}
+	return jsonOK(c, fiber.Map{"message": "Исполнитель сохранён"})
+}
+
+// assignRepair назначает исполнителя заявки r и пишет это в историю.
+func assignRepair(ctx context.Context, tx store.Store, c *fiber.Ctx, r models.RepairRequest, staffID int) error {
+	if staffID == r.AssigneeID {
+		return nil
+	}
+	if err := tx.Repairs().Assign(ctx, r.ID, staffID); err != nil {
+		return err
+	}
+	comment := "Исполнитель снят"
+	if staffID > 0 {
+		a, err := tx.Repairs().Get(ctx, r.ID)
+		if err != nil {
+			return err
+		}
+		comment = "Исполнитель: " + a.AssigneeName
+	}
+	return changeRepairStatus(ctx, tx, c, r, r.Status, comment)
+}
@@ -0,0 +1,721 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"fitness-center-manager/internal/checkin"
+	"fitness-center-manager/internal/enrollment"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/recurrence"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Правка и удаление занятия серии: только это, это и следующие, вся серия.
+const (
+	scopeThis      = "this"
+	scopeFollowing = "following"
+	scopeAll       = "all"
+)
+
+// seriesScope — scope из query или формы; пусто — только это занятие.
+func seriesScope(c *fiber.Ctx) (string, error) {
+	switch s := c.Query("scope", c.FormValue("scope")); s {
+	case "", scopeThis:
+		return scopeThis, nil
+	case scopeFollowing, scopeAll:
+		return s, nil
+	default:
+		return "", fmt.Errorf("scope: %q", s)
+	}
+}
+
+// errNotInSeries — scope following/all для разового занятия.
+var errNotInSeries = errors.New("тренировка не входит в серию")
+
+// seriesDay — дата в формате ключа и параметров URL.
+const seriesDay = "2006-01-02"
+
+// atClock — день date со временем суток clock.
+func atClock(date, clock time.Time) time.Time {
+	y, m, d := date.Date()
+	return time.Date(y, m, d, clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+}
+
+// seriesInputFrom — шаблон серии из формы групповой: время и длительность
+// занятия берутся из начала и окончания.
+func seriesInputFrom(in store.GroupTrainingInput, rule string) store.SeriesInput {
+	return store.SeriesInput{
+		TrainerID:   in.TrainerID,
+		ZoneID:      in.ZoneID,
+		Title:       in.Title,
+		Description: in.Description,
+		Max:         in.Max,
+		Level:       in.Level,
+		Start:       in.Start,
+		DurationMin: int(in.End.Sub(in.Start).Minutes()),
+		Rule:        rule,
+	}
+}
+
+// seriesInputOf — текущий шаблон серии s.
+func seriesInputOf(s models.TrainingSeries) store.SeriesInput {
+	return store.SeriesInput{
+		TrainerID:   s.TrainerID,
+		ZoneID:      s.ZoneID,
+		Title:       s.Name,
+		Description: s.Description,
+		Max:         s.MaxParticipants,
+		Level:       s.DifficultyLevel,
+		Start:       s.Start,
+		DurationMin: s.DurationMin,
+		Rule:        s.Rule,
+	}
+}
+
+// occurrenceOf — занятие серии s в день date.
+func occurrenceOf(s models.TrainingSeries, date time.Time) store.GroupTrainingInput {
+	start := atClock(date, s.Start)
+	return store.GroupTrainingInput{
+		TrainerID:   s.TrainerID,
+		ZoneID:      s.ZoneID,
+		Title:       s.Name,
+		Description: s.Description,
+		Max:         s.MaxParticipants,
+		Level:       s.DifficultyLevel,
+		Start:       start,
+		End:         start.Add(time.Duration(s.DurationMin) * time.Minute),
+	}
+}
+
+// matches — занятие g уже совпадает с шаблоном in (лишний UPDATE не нужен:
+// каждый попадает в журнал аудита).
+func matches(g models.GroupTraining, in store.GroupTrainingInput) bool {
+	return g.TrainerID == in.TrainerID && g.ZoneID == in.ZoneID && g.Name == in.Title &&
+		g.Description == in.Description && g.MaxParticipants == in.Max && g.DifficultyLevel == in.Level &&
+		g.StartTime.Equal(in.Start) && g.EndTime.Equal(in.End)
+}
+
+// seriesSync — итог приведения занятий серии к правилу.
+type seriesSync struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Cancelled int `json:"cancelled"`
+	Promoted  int `json:"promoted"`
+}
+
+func (r seriesSync) String() string {
+	return fmt.Sprintf("создано занятий: %d, изменено: %d, отменено: %d", r.Created, r.Updated, r.Cancelled)
+}
+
+// syncSeries приводит будущие занятия серии с даты since к её правилу и
+// шаблону: создаёт недостающие, отменяет лишние (исключения, даты вне
+// правила), обновляет остальные. Прошедшие занятия и правленые отдельно
+// («Изменена») не трогает. Без force пересечения по расписанию всех занятий
+// собираются в одну *conflictError.
+func syncSeries(ctx context.Context, tx store.Store, id int, since time.Time, force bool) (seriesSync, error) {
+	var res seriesSync
+	s, err := tx.Series().Get(ctx, id)
+	if err != nil {
+		return res, err
+	}
+	rule, err := recurrence.Parse(s.Rule)
+	if err != nil {
+		return res, err
+	}
+	dates, err := rule.Dates(s.Start)
+	if err != nil {
+		return res, err
+	}
+	excs, err := tx.Series().Exceptions(ctx, id)
+	if err != nil {
+		return res, err
+	}
+	skip := make(map[string]string, len(excs)) // дата → причина
+	for _, e := range excs {
+		skip[e.Date.Format(seriesDay)] = coalesceStr(e.Reason, "исключение в расписании серии")
+	}
+	now := clubWallNow()
+	since = checkin.Day(since)
+	inRange := func(date, start time.Time) bool { return !date.Before(since) && !start.Before(now) }
+
+	want := make(map[string]bool)
+	for _, d := range dates {
+		if _, ok := skip[d.Format(seriesDay)]; !ok && inRange(d, occurrenceOf(s, d).Start) {
+			want[d.Format(seriesDay)] = true
+		}
+	}
+
+	occs, err := tx.Series().Occurrences(ctx, id)
+	if err != nil {
+		return res, err
+	}
+	var conflicts []models.ScheduleConflict
+	// free — занятие in можно записать; иначе пересечения копятся, а запись
+	// пропускается (её всё равно отклонило бы ограничение БД)
+	free := func(in store.GroupTrainingInput, excludeID int) (bool, error) {
+		err := checkSchedule(ctx, tx, groupConflictQuery(in, excludeID), force)
+		var ce *conflictError
+		if errors.As(err, &ce) {
+			conflicts = append(conflicts, ce.list...)
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	have := make(map[string]bool, len(occs))
+	for _, g := range occs {
+		date := g.SeriesDate.Time
+		have[date.Format(seriesDay)] = true
+		if !inRange(date, g.StartTime) {
+			continue
+		}
+		if !want[date.Format(seriesDay)] {
+			reason := coalesceStr(skip[date.Format(seriesDay)], "расписание серии изменилось")
+			if err := cancelOccurrence(ctx, tx, g, reason); err != nil {
+				return res, err
+			}
+			res.Cancelled++
+			continue
+		}
+		in := occurrenceOf(s, date)
+		if g.Modified || matches(g, in) {
+			continue
+		}
+		in.AllowOverlap = force
+		if ok, err := free(in, g.ID); !ok {
+			if err != nil {
+				return res, err
+			}
+			continue
+		}
+		if err := tx.Trainings().UpdateGroup(ctx, g.ID, in); err != nil {
+			return res, err
+		}
+		res.Updated++
+		// вместимость могли увеличить — свободные места достаются очереди
+		occ, err := tx.Trainings().LockOccupancy(ctx, g.ID)
+		if err != nil {
+			return res, err
+		}
+		promoted, err := promoteWaitlist(ctx, tx, g.ID, occ)
+		if err != nil {
+			return res, err
+		}
+		res.Promoted += len(promoted)
+	}
+
+	for _, d := range dates {
+		if !want[d.Format(seriesDay)] || have[d.Format(seriesDay)] {
+			continue
+		}
+		in := occurrenceOf(s, d)
+		in.AllowOverlap = force
+		if ok, err := free(in, 0); !ok {
+			if err != nil {
+				return res, err
+			}
+			continue
+		}
+		gid, err := tx.Trainings().CreateGroup(ctx, in)
+		if err != nil {
+			return res, err
+		}
+		if err := tx.Series().Attach(ctx, gid, id, d); err != nil {
+			return res, err
+		}
+		res.Created++
+	}
+	if len(conflicts) > 0 {
+		return res, &conflictError{conflicts}
+	}
+	return res, nil
+}
+
+// cancelOccurrence удаляет занятие и пишет уведомления записанным на него
+// и стоящим в очереди.
+func cancelOccurrence(ctx context.Context, tx store.Store, g models.GroupTraining, reason string) error {
+	list, err := tx.Trainings().ListEnrollments(ctx, g.ID)
+	if err != nil {
+		return err
+	}
+	for _, e := range list {
+		if e.Status != enrollment.StatusBooked && e.Status != enrollment.StatusWaiting {
+			continue
+		}
+		text := fmt.Sprintf("Тренировка «%s» %s отменена", g.Name, g.StartTime.Format(visitTimeFormat))
+		if reason != "" {
+			text += ": " + reason
+		}
+		if _, err := tx.Notifications().Create(ctx, e.ClientID, "Тренировка отменена", text+"."); err != nil {
+			return err
+		}
+	}
+	if err := tx.Trainings().DeleteGroup(ctx, g.ID); err != nil {
+		return err
+	}
+	log.Printf("🗓 занятие #%d «%s» %s отменено (%s)", g.ID, g.Name, g.StartTime.Format(visitTimeFormat), reason)
+	return nil
+}
+
+// deleteSeries отменяет будущие занятия серии и удаляет её; прошедшие
+// занятия остаются разовыми.
+func deleteSeries(ctx context.Context, tx store.Store, id int, reason string) (int, error) {
+	occs, err := tx.Series().Occurrences(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	cancelled := 0
+	for _, g := range occs {
+		if g.StartTime.Before(clubWallNow()) {
+			continue
+		}
+		if err := cancelOccurrence(ctx, tx, g, reason); err != nil {
+			return cancelled, err
+		}
+		cancelled++
+	}
+	return cancelled, tx.Series().Delete(ctx, id)
+}
+
+// parseRule разбирает правило повторения; при ошибке ответ уже отправлен.
+func parseRule(c *fiber.Ctx, s string) (recurrence.Rule, bool, error) {
+	rule, err := recurrence.Parse(s)
+	if err != nil {
+		return rule, false, jsonError(c, 400, err.Error(), nil)
+	}
+	return rule, true, nil
+}
+
+// ====== API: серии ======
+
+type seriesDTO struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	TrainerID   int    `json:"trainer_id"`
+	TrainerName string `json:"trainer_name"`
+	ZoneID      int    `json:"zone_id"`
+	ZoneName    string `json:"zone_name"`
+	Max         int    `json:"max"`
+	Level       string `json:"level,omitempty"`
+	Date        string `json:"date"`
+	StartTime   string `json:"start_time"`
+	DurationMin int    `json:"duration_min"`
+	RRule       string `json:"rrule"`
+	RuleText    string `json:"rule_text"`
+	Upcoming    int    `json:"upcoming"`
+}
+
+func toSeriesDTO(s models.TrainingSeries) seriesDTO {
+	d := seriesDTO{
+		ID: s.ID, Title: s.Name, Description: s.Description,
+		TrainerID: s.TrainerID, TrainerName: s.TrainerName, ZoneID: s.ZoneID, ZoneName: s.ZoneName,
+		Max: s.MaxParticipants, Level: s.DifficultyLevel,
+		Date: s.Start.Format(seriesDay), StartTime: s.Start.Format("15:04"), DurationMin: s.DurationMin,
+		RRule: s.Rule, RuleText: s.Rule, Upcoming: s.Upcoming,
+	}
+	if r, err := recurrence.Parse(s.Rule); err == nil {
+		d.RuleText = r.Describe()
+	}
+	return d
+}
+
+// APIv1ListSeries — GET /api/v1/training-series: все серии.
+func APIv1ListSeries(c *fiber.Ctx) error {
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	list, err := data.Series().List(ctx)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки серий", err)
+	}
+	out := make([]seriesDTO, 0, len(list))
+	for _, s := range list {
+		out = append(out, toSeriesDTO(s))
+	}
+	return jsonOK(c, fiber.Map{"series": out})
+}
+
+// APIv1GetSeries — GET /api/v1/training-series/:id: серия, её занятия и исключения.
+func APIv1GetSeries(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	s, err := data.Series().Get(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return jsonError(c, 404, "Серия не найдена", nil)
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка БД", err)
+	}
+	occs, err := data.Series().Occurrences(ctx, id)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки занятий", err)
+	}
+	excs, err := data.Series().Exceptions(ctx, id)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка загрузки исключений", err)
+	}
+
+	type occurrence struct {
+		ID        int    `json:"id"`
+		Date      string `json:"date"`
+		Start     string `json:"start"`
+		End       string `json:"end"`
+		FreeSlots int    `json:"free_slots"`
+		Modified  bool   `json:"modified"`
+	}
+	type exception struct {
+		Date   string `json:"date"`
+		Reason string `json:"reason,omitempty"`
+	}
+	outOccs := make([]occurrence, 0, len(occs))
+	for _, g := range occs {
+		outOccs = append(outOccs, occurrence{g.ID, g.SeriesDate.Time.Format(seriesDay),
+			g.StartTime.Format(visitTimeFormat), g.EndTime.Format(visitTimeFormat), g.FreeSlots, g.Modified})
+	}
+	outExcs := make([]exception, 0, len(excs))
+	for _, e := range excs {
+		outExcs = append(outExcs, exception{e.Date.Format(seriesDay), e.Reason})
+	}
+	return jsonOK(c, fiber.Map{"series": toSeriesDTO(s), "occurrences": outOccs, "exceptions": outExcs})
+}
+
+// APIv1CreateSeries — POST /api/v1/training-series: поля групповой (date —
+// первая дата) и rrule; занятия создаются сразу.
+func APIv1CreateSeries(c *fiber.Ctx) error {
+	in, ok, err := parseGroupForm(c)
+	if !ok {
+		return err
+	}
+	rule, ok, err := parseRule(c, c.FormValue("rrule"))
+	if !ok {
+		return err
+	}
+	dates, err := rule.Dates(in.Start)
+	if err != nil {
+		return jsonError(c, 400, err.Error(), nil)
+	}
+	if len(dates) == 0 {
+		return jsonError(c, 400, "Правило не даёт ни одного занятия", nil)
+	}
+
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var (
+		id  int
+		res seriesSync
+	)
+	err = inTx(ctx, c, func(tx store.Store) error {
+		var err error
+		if id, err = tx.Series().Create(ctx, seriesInputFrom(in, rule.String())); err != nil {
+			return err
+		}
+		res, err = syncSeries(ctx, tx, id, time.Time{}, in.AllowOverlap)
+		return err
+	})
+	if handled, err := conflictReject(c, err); handled {
+		return err
+	}
+	if err != nil {
+		return jsonError(c, 500, "Ошибка сохранения серии", err)
+	}
+	return jsonOK(c, fiber.Map{"id": id, "sync": res, "message": "Серия создана: " + rule.Describe() + "; " + res.String()})
+}
+
+// APIv1UpdateSeries — PUT /api/v1/training-series/:id: правка всей серии
+// (поля групповой, date — новая первая дата, rrule — пусто, чтобы оставить).
+// Прошедшие и правленые отдельно занятия не меняются.
+func APIv1UpdateSeries(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	in, ok, err := parseGroupForm(c)
+	if !ok {
+		return err
+	}
+	ruleStr := c.FormValue("rrule")
+	if ruleStr != "" {
+		rule, ok, err := parseRule(c, ruleStr)
+		if !ok {
+			return err
+		}
+		ruleStr = rule.String()
+	}
+
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var res seriesSync
+	err = inTx(ctx, c, func(tx store.Store) error {
+		s, err := tx.Series().Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Series().Update(ctx, id, seriesInputFrom(in, coalesceStr(ruleStr, s.Rule))); err != nil {
+			return err
+		}
+		res, err = syncSeries(ctx, tx, id, time.Time{}, in.AllowOverlap)
+		return err
+	})
+	return seriesResult(c, err, "Серия обновлена", res)
+}
+
+// APIv1DeleteSeries — DELETE /api/v1/training-series/:id: удалить серию и
+// отменить её будущие занятия (записанным уйдёт уведомление).
+func APIv1DeleteSeries(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var res seriesSync
+	err = inTx(ctx, c, func(tx store.Store) error {
+		var err error
+		res.Cancelled, err = deleteSeries(ctx, tx, id, c.FormValue("reason"))
+		return err
+	})
+	return seriesResult(c, err, "Серия удалена", res)
+}
+
+// APIv1AddSeriesException — POST /api/v1/training-series/:id/exceptions
+// (date, reason): в этот день занятия нет; уже созданное отменяется.
+func APIv1AddSeriesException(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	date, err := time.Parse(seriesDay, c.FormValue("date"))
+	if err != nil {
+		return jsonError(c, 400, "Некорректная дата", err)
+	}
+	reason := c.FormValue("reason")
+	force, ok, err := forceOverlap(c)
+	if !ok {
+		return err
+	}
+
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var res seriesSync
+	var added bool
+	err = inTx(ctx, c, func(tx store.Store) error {
+		var err error
+		if added, err = tx.Series().AddException(ctx, id, date, reason); err != nil || !added {
+			return err
+		}
+		res, err = syncSeries(ctx, tx, id, date, force)
+		return err
+	})
+	if err == nil && !added {
+		return jsonError(c, 409, "Эта дата уже в исключениях", nil)
+	}
+	if errors.Is(err, store.ErrInUse) {
+		return jsonError(c, 404, "Серия не найдена", nil)
+	}
+	return seriesResult(c, err, "Исключение добавлено", res)
+}
+
+// APIv1DeleteSeriesException — DELETE /api/v1/training-series/:id/exceptions/:date:
+// вернуть занятие в этот день (если он ещё не прошёл).
+func APIv1DeleteSeriesException(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный id", err)
+	}
+	date, err := time.Parse(seriesDay, c.Params("date"))
+	if err != nil {
+		return jsonError(c, 400, "Некорректная дата", err)
+	}
+	// возвращённое занятие могло за это время пересечься с другими
+	force, ok, err := forceOverlap(c)
+	if !ok {
+		return err
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var res seriesSync
+	err = inTx(ctx, c, func(tx store.Store) error {
+		if err := tx.Series().DeleteException(ctx, id, date); err != nil {
+			return err
+		}
+		var err error
+		res, err = syncSeries(ctx, tx, id, date, force)
+		return err
+	})
+	return seriesResult(c, err, "Исключение снято", res)
+}
+
+// seriesResult — общий ответ операций над серией.
+func seriesResult(c *fiber.Ctx, err error, message string, res seriesSync) error {
+	if handled, err := conflictReject(c, err); handled {
+		return err
+	}
+	switch {
+	case errors.Is(err, errNotInSeries):
+		return jsonError(c, 400, "Тренировка не входит в серию", nil)
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Не найдено", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка сохранения серии", err)
+	}
+	return jsonOK(c, fiber.Map{"sync": res, "message": message + "; " + res.String()})
+}
+
+// ====== занятие серии: «это и следующие» / «вся серия» ======
+
+// updateOccurrenceScope — правка занятия id с scope following или all. in —
+// новые поля занятия; rrule в форме (необязательно) — новое правило для
+// изменяемой части серии.
+func updateOccurrenceScope(c *fiber.Ctx, id int, in store.GroupTrainingInput, scope string) error {
+	ruleStr := c.FormValue("rrule")
+	var newRule recurrence.Rule
+	if ruleStr != "" {
+		var ok bool
+		var err error
+		if newRule, ok, err = parseRule(c, ruleStr); !ok {
+			return err
+		}
+	}
+
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var res seriesSync
+	err := inTx(ctx, c, func(tx store.Store) error {
+		g, err := tx.Trainings().GetGroup(ctx, id)
+		if err != nil {
+			return err
+		}
+		if g.SeriesID == 0 {
+			return errNotInSeries
+		}
+		s, err := tx.Series().Get(ctx, g.SeriesID)
+		if err != nil {
+			return err
+		}
+		cur, err := recurrence.Parse(s.Rule)
+		if err != nil {
+			return err
+		}
+		at := g.SeriesDate.Time
+		head, tail, kept, err := cur.Split(s.Start, at)
+		if err != nil {
+			return err
+		}
+		// правленое занятие снова следует серии
+		if err := tx.Series().SetModified(ctx, id, false); err != nil {
+			return err
+		}
+
+		if scope == scopeAll || kept == 0 {
+			// первая дата серии остаётся прежней, меняется время и шаблон
+			tmpl := seriesInputFrom(in, s.Rule)
+			tmpl.Start = atClock(s.Start, in.Start)
+			if ruleStr != "" {
+				tmpl.Rule = newRule.String()
+			}
+			if err := tx.Series().Update(ctx, s.ID, tmpl); err != nil {
+				return err
+			}
+			res, err = syncSeries(ctx, tx, s.ID, time.Time{}, in.AllowOverlap)
+			return err
+		}
+
+		// «это и следующие»: серия делится — старая заканчивается накануне,
+		// с этого занятия начинается новая с изменённым шаблоном
+		tmpl := seriesInputFrom(in, tail.String())
+		tmpl.Start = atClock(at, in.Start)
+		if ruleStr != "" {
+			tmpl.Rule = newRule.String()
+		}
+		newID, err := tx.Series().Create(ctx, tmpl)
+		if err != nil {
+			return err
+		}
+		if err := tx.Series().Move(ctx, s.ID, newID, at); err != nil {
+			return err
+		}
+		old := seriesInputOf(s)
+		old.Rule = head.String()
+		if err := tx.Series().Update(ctx, s.ID, old); err != nil {
+			return err
+		}
+		res, err = syncSeries(ctx, tx, newID, at, in.AllowOverlap)
+		return err
+	})
+	return seriesResult(c, err, "Серия обновлена", res)
+}
+
+// deleteOccurrenceScope — удаление занятия id с scope following или all.
+func deleteOccurrenceScope(c *fiber.Ctx, id int, scope string) error {
+	reason := c.Query("reason", c.FormValue("reason"))
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var res seriesSync
+	err := inTx(ctx, c, func(tx store.Store) error {
+		g, err := tx.Trainings().GetGroup(ctx, id)
+		if err != nil {
+			return err
+		}
+		if g.SeriesID == 0 {
+			return errNotInSeries
+		}
+		s, err := tx.Series().Get(ctx, g.SeriesID)
+		if err != nil {
+			return err
+		}
+		cur, err := recurrence.Parse(s.Rule)
+		if err != nil {
+			return err
+		}
+		at := g.SeriesDate.Time
+		head, _, kept, err := cur.Split(s.Start, at)
+		if err != nil {
+			return err
+		}
+		if scope == scopeAll || kept == 0 {
+			res.Cancelled, err = deleteSeries(ctx, tx, s.ID, reason)
+			return err
+		}
+		// серия заканчивается накануне этого занятия
+		old := seriesInputOf(s)
+		old.Rule = head.String()
+		if err := tx.Series().Update(ctx, s.ID, old); err != nil {
+			return err
+		}
+		occs, err := tx.Series().Occurrences(ctx, s.ID)
+		if err != nil {
+			return err
+		}
+		for _, o := range occs {
+			if o.SeriesDate.Time.Before(at) || o.StartTime.Before(clubWallNow()) {
+				continue
+			}
+			if err := cancelOccurrence(ctx, tx, o, reason); err != nil {
+				return err
+			}
+			res.Cancelled++
+		}
+		return nil
+	})
+	return seriesResult(c, err, "Занятия удалены", res)
+}
+
+// deleteOccurrence — удаление одного занятия серии: дата уходит в исключения,
+// чтобы правка серии не вернула занятие.
+func deleteOccurrence(ctx context.Context, tx store.Store, g models.GroupTraining, reason string) error {
+	if _, err := tx.Series().AddException(ctx, g.SeriesID, g.SeriesDate.Time, coalesceStr(reason, "занятие отменено")); err != nil {
+		return err
+	}
+	return cancelOccurrence(ctx, tx, g, reason)
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"fitness-center-manager/internal/auth"
+	"fitness-center-manager/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetStaffPage — управление учётными записями (только администратор)
+func GetStaffPage(c *fiber.Ctx) error {
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	list, err := data.Staff().List(ctx)
+	msg := ""
+	if err != nil {
+		log.Printf("❌ staff list error: %v", err)
+		msg = "Не удалось загрузить список сотрудников"
+	}
+	return c.Render("staff", fiber.Map{
+		"Title":        "Сотрудники",
+		"Staff":        list,
+		"Roles":        auth.Roles,
+		"Message":      msg,
+		"ExtraScripts": tplScript(`/static/js/staff.js`),
+	})
+}
+
+// APIv1ListStaff — JSON список сотрудников
+func APIv1ListStaff(c *fiber.Ctx) error {
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	list, err := data.Staff().List(ctx)
+	if err != nil {
+		return jsonError(c, 500, "Ошибка БД при загрузке сотрудников", err)
+	}
+	return jsonOK(c, fiber.Map{"staff": list})
+}
+
+// GetStaffByID — одна учётная запись
+func GetStaffByID(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный ID", err)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	s, err := data.Staff().Get(ctx, id)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Сотрудник не найден", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка БД при загрузке сотрудника", err)
+	}
+	return jsonOK(c, fiber.Map{"staff": s})
+}
+
+type staffForm struct {
+	Login     string `form:"login"`
+	FIO       string `form:"fio"`
+	Role      string `form:"role"`
+	TrainerID int    `form:"trainer_id"`
+	Active    string `form:"active"`
+	Password  string `form:"password"`
+}
+
+func (f *staffForm) normalize() error {
+	f.Login = strings.TrimSpace(f.Login)
+	f.FIO = strings.TrimSpace(f.FIO)
+	f.Role = strings.TrimSpace(f.Role)
+	if !auth.ValidRole(f.Role) {
+		return errors.New("Недопустимая роль: " + f.Role)
+	}
+	if f.Role != auth.RoleTrainer {
+		f.TrainerID = 0 // привязка к тренеру имеет смысл только для роли «Тренер»
+	}
+	return nil
+}
+
+func (f *staffForm) input(active bool, hash string) store.StaffInput {
+	return store.StaffInput{Login: f.Login, FIO: f.FIO, Role: f.Role, TrainerID: max(f.TrainerID, 0),
+		Active: active, PasswordHash: hash}
+}
+
+// CreateStaff — новая учётная запись
+func CreateStaff(c *fiber.Ctx) error {
+	var f staffForm
+	if err := c.BodyParser(&f); err != nil {
+		return jsonError(c, 400, "Неверные данные формы", err)
+	}
+	if err := f.normalize(); err != nil {
+		return jsonError(c, 400, err.Error(), nil)
+	}
+	if f.Login == "" || f.FIO == "" || f.Password == "" {
+		return jsonError(c, 400, "Заполните обязательные поля: логин, ФИО, пароль", nil)
+	}
+	hash, err := auth.HashPassword(f.Password)
+	if err != nil {
+		if errors.Is(err, auth.ErrWeakPassword) {
+			return jsonError(c, 400, "Пароль должен содержать не менее 8 символов", nil)
+		}
+		return jsonError(c, 500, "Не удалось обработать пароль", err)
+	}
+
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	var id int
+	err = inTx(ctx, c, func(tx store.Store) error {
+		var err error
+		id, err = tx.Staff().Create(ctx, f.input(true, hash))
+		return err
+	})
+	switch {
+	case errors.Is(err, store.ErrDuplicate):
+		return jsonError(c, 409, "Логин уже занят", err)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка сохранения в БД", err)
+	}
+	return jsonOK(c, fiber.Map{"message": "Сотрудник добавлен", "id": id})
+}
+
+// UpdateStaff — изменить ФИО/роль/активность; пароль меняется, только если передан.
+func UpdateStaff(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный ID", err)
+	}
+	var f staffForm
+	if err := c.BodyParser(&f); err != nil {
+		return jsonError(c, 400, "Неверные данные формы", err)
+	}
+	if err := f.normalize(); err != nil {
+		return jsonError(c, 400, err.Error(), nil)
+	}
+	if f.FIO == "" {
+		return jsonError(c, 400, "Заполните обязательные поля: ФИО", nil)
+	}
+	active := f.Active == "on" || f.Active == "true" || f.Active == "1"
+
+	// администратор не может сам себя заблокировать или понизить
+	if me := currentStaff(c); me != nil && me.ID == id && (!active || f.Role != auth.RoleAdmin) {
+		return jsonError(c, 409, "Нельзя снять роль администратора или заблокировать свою учётную запись", nil)
+	}
+
+	var hash string
+	if f.Password != "" {
+		if hash, err = auth.HashPassword(f.Password); err != nil {
+			if errors.Is(err, auth.ErrWeakPassword) {
+				return jsonError(c, 400, "Пароль должен содержать не менее 8 символов", nil)
+			}
+			return jsonError(c, 500, "Не удалось обработать пароль", err)
+		}
+	}
+
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		if err := tx.Staff().Update(ctx, id, f.input(active, hash)); err != nil {
+			return err
+		}
+		// смена пароля или блокировка — завершаем все сессии сотрудника
+		if hash != "" || !active {
+			return tx.Sessions().DeleteByStaff(ctx, id)
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Сотрудник не найден", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка сохранения в БД", err)
+	}
+	return jsonOK(c, fiber.Map{"message": "Сотрудник обновлён"})
+}
+
+// DeleteStaff — удалить учётную запись (сессии удаляются каскадом)
+func DeleteStaff(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return jsonError(c, 400, "Некорректный ID", err)
+	}
+	if me := currentStaff(c); me != nil && me.ID == id {
+		return jsonError(c, 409, "Невозможно удалить собственную учётную запись", nil)
+	}
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	err = inTx(ctx, c, func(tx store.Store) error {
+		return tx.Staff().Delete(ctx, id)
+	})
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return jsonError(c, 404, "Сотрудник не найден", nil)
+	case err != nil:
+		return jsonError(c, 500, "Ошибка БД при удалении сотрудника", err)
+	}
+	return jsonOK(c, fiber.Map{"message": "Сотрудник удалён"})
+}
+
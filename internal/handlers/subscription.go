@@ -1,39 +1,24 @@
 package handlers
 
 import (
-    "database/sql"
+    "errors"
     "log"
     "strconv"
     "time"
 
-    "fitness-center-manager/internal/database"
+    "fitness-center-manager/internal/checkin"
     "fitness-center-manager/internal/models"
+    "fitness-center-manager/internal/store"
 
     "github.com/gofiber/fiber/v2"
 )
 
 // ====== Страница со списком ======
 func GetSubscriptionsPage(c *fiber.Ctx) error {
-    db := database.GetDB()
-
     ctx, cancel := withDBTimeout()
     defer cancel()
 
-    rows, err := db.QueryContext(ctx, `
-        SELECT s."id_абонемента",
-               s."id_клиента",
-               s."id_тарифа",
-               s."Дата_начала",
-               s."Дата_окончания",
-               s."Статус",
-               s."Цена",
-               c."ФИО"              AS client_name,
-               t."Название_тарифа"  AS tariff_name
-        FROM "Абонемент" s
-        JOIN "Клиент" c ON c."id_клиента" = s."id_клиента"
-        JOIN "Тариф"  t ON t."id_тарифа"  = s."id_тарифа"
-        ORDER BY s."id_абонемента" DESC
-    `)
+    subs, err := data.Subscriptions().List(ctx)
     if err != nil {
         log.Printf("❌ subscriptions list error: %v", err)
         return c.Render("subscriptions", fiber.Map{
@@ -43,25 +28,6 @@ func GetSubscriptionsPage(c *fiber.Ctx) error {
             "ExtraScripts":  templateScript(`/static/js/subscriptions.js`),
         })
     }
-	defer rows.Close()
-
-	var subs []models.Subscription
-	for rows.Next() {
-		var s models.Subscription
-		if err := rows.Scan(
-			&s.ID, &s.ClientID, &s.TariffID,
-			&s.StartDate, &s.EndDate,
-			&s.Status, &s.Price,
-			&s.ClientName, &s.TariffName,
-		); err != nil {
-			log.Printf("❌ scan sub: %v", err)
-			continue
-		}
-		subs = append(subs, s)
-	}
-	if err = rows.Err(); err != nil {
-		log.Printf("❌ rows err: %v", err)
-	}
 
 	log.Printf("✅ загружено абонементов: %d", len(subs))
 
@@ -74,28 +40,12 @@ func GetSubscriptionsPage(c *fiber.Ctx) error {
 
 // APIv1ListSubscriptions — JSON список абонементов
 func APIv1ListSubscriptions(c *fiber.Ctx) error {
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    rows, err := db.QueryContext(ctx, `
-        SELECT s."id_абонемента",
-               s."id_клиента",
-               s."id_тарифа",
-               s."Дата_начала",
-               s."Дата_окончания",
-               s."Статус",
-               s."Цена",
-               c."ФИО"              AS client_name,
-               t."Название_тарифа"  AS tariff_name
-        FROM "Абонемент" s
-        JOIN "Клиент" c ON c."id_клиента" = s."id_клиента"
-        JOIN "Тариф"  t ON t."id_тарифа"  = s."id_тарифа"
-        ORDER BY s."id_абонемента" DESC
-    `)
+    subs, err := data.Subscriptions().List(ctx)
     if err != nil {
         return jsonError(c, 500, "Ошибка загрузки абонементов", err)
     }
-    defer rows.Close()
     type dto struct {
         ID         int     `json:"id"`
         ClientID   int     `json:"client_id"`
@@ -108,11 +58,7 @@ func APIv1ListSubscriptions(c *fiber.Ctx) error {
         TariffName string  `json:"tariff_name"`
     }
     var list []dto
-    for rows.Next() {
-        var s models.Subscription
-        if err := rows.Scan(&s.ID, &s.ClientID, &s.TariffID, &s.StartDate, &s.EndDate, &s.Status, &s.Price, &s.ClientName, &s.TariffName); err != nil {
-            return jsonError(c, 500, "Ошибка чтения абонемента", err)
-        }
+    for _, s := range subs {
         list = append(list, dto{
             ID: s.ID,
             ClientID: s.ClientID,
@@ -125,58 +71,80 @@ func APIv1ListSubscriptions(c *fiber.Ctx) error {
             TariffName: s.TariffName,
         })
     }
-    if err := rows.Err(); err != nil {
-        return jsonError(c, 500, "Ошибка курсора", err)
-    }
     return jsonOK(c, fiber.Map{"subscriptions": list})
 }
 
-// APIv1CreateSubscription — 201 + Location (повторяет CreateSubscription)
-func APIv1CreateSubscription(c *fiber.Ctx) error {
-    type formT struct {
-        ClientID  int    `form:"client_id"`
-        TariffID  int    `form:"tariff_id"`
-        StartDate string `form:"start_date"`
-        EndDate   string `form:"end_date"`
-        Status    string `form:"status"`
-        Price     string `form:"price"`
-    }
-    var f formT
+type subscriptionForm struct {
+    ClientID  int    `form:"client_id"`
+    TariffID  int    `form:"tariff_id"`
+    StartDate string `form:"start_date"` // YYYY-MM-DD
+    EndDate   string `form:"end_date"`   // YYYY-MM-DD
+    Status    string `form:"status"`
+    Price     string `form:"price"` // если пусто — из тарифа (создание) или прежняя (обновление)
+}
+
+// parseSubscriptionForm разбирает и проверяет форму абонемента; при ошибке ответ уже отправлен.
+func parseSubscriptionForm(c *fiber.Ctx, requireStatus bool) (store.SubscriptionInput, bool, error) {
+    var f subscriptionForm
     if err := c.BodyParser(&f); err != nil {
-        return jsonError(c, 400, "Неверные данные формы", err)
+        return store.SubscriptionInput{}, false, jsonError(c, 400, "Неверные данные формы", err)
     }
-    if f.ClientID <= 0 || f.TariffID <= 0 || f.StartDate == "" || f.EndDate == "" {
-        return jsonError(c, 400, "Заполните обязательные поля", nil)
+    if f.ClientID <= 0 || f.TariffID <= 0 || f.StartDate == "" || f.EndDate == "" || (requireStatus && f.Status == "") {
+        return store.SubscriptionInput{}, false, jsonError(c, 400, "Заполните обязательные поля", nil)
     }
+
     start, err := time.Parse("2006-01-02", f.StartDate)
-    if err != nil { return jsonError(c, 400, "Неверная дата начала", err) }
+    if err != nil {
+        return store.SubscriptionInput{}, false, jsonError(c, 400, "Неверная дата начала", err)
+    }
     end, err := time.Parse("2006-01-02", f.EndDate)
-    if err != nil { return jsonError(c, 400, "Неверная дата окончания", err) }
-    if end.Before(start) { return jsonError(c, 400, "Дата окончания раньше даты начала", nil) }
+    if err != nil {
+        return store.SubscriptionInput{}, false, jsonError(c, 400, "Неверная дата окончания", err)
+    }
+    if end.Before(start) {
+        return store.SubscriptionInput{}, false, jsonError(c, 400, "Дата окончания раньше даты начала", nil)
+    }
 
-    db := database.GetDB()
-    var price float64
+    in := store.SubscriptionInput{
+        ClientID:  f.ClientID,
+        TariffID:  f.TariffID,
+        StartDate: start,
+        EndDate:   end,
+        Status:    f.Status,
+    }
     if f.Price != "" {
         p, err := strconv.ParseFloat(f.Price, 64)
-        if err != nil { return jsonError(c, 400, "Неверная цена", err) }
-        price = p
-    } else {
-        // взять стоимость из тарифа
-        ctxP, cancelP := withDBTimeout()
-        defer cancelP()
-        if err := db.QueryRowContext(ctxP, `SELECT "Стоимость" FROM "Тариф" WHERE "id_тарифа"=$1`, f.TariffID).Scan(&price); err != nil {
-            return jsonError(c, 400, "Не удалось получить стоимость тарифа", err)
+        if err != nil {
+            return store.SubscriptionInput{}, false, jsonError(c, 400, "Неверная цена", err)
         }
+        in.Price = &p
     }
+    return in, true, nil
+}
 
+func createSubscription(c *fiber.Ctx, in store.SubscriptionInput) (int, error) {
     ctx, cancel := withDBTimeout()
     defer cancel()
     var id int
-    err = db.QueryRowContext(ctx, `
-        INSERT INTO "Абонемент" ("id_клиента","id_тарифа","Дата_начала","Дата_окончания","Статус","Цена")
-        VALUES ($1,$2,$3,$4,$5,$6)
-        RETURNING "id_абонемента"
-    `, f.ClientID, f.TariffID, start, end, f.Status, price).Scan(&id)
+    err := inTx(ctx, c, func(tx store.Store) error {
+        var err error
+        id, err = tx.Subscriptions().Create(ctx, in)
+        return err
+    })
+    return id, err
+}
+
+// APIv1CreateSubscription — 201 + Location (повторяет CreateSubscription)
+func APIv1CreateSubscription(c *fiber.Ctx) error {
+    in, ok, err := parseSubscriptionForm(c, false)
+    if !ok {
+        return err
+    }
+
+    id, err := createSubscription(c, in)
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 400, "Не удалось получить стоимость тарифа", err)
+    }
     if err != nil {
         return jsonError(c, 500, "Ошибка создания абонемента", err)
     }
@@ -185,63 +153,22 @@ func APIv1CreateSubscription(c *fiber.Ctx) error {
 }
 
 func CreateSubscription(c *fiber.Ctx) error {
-	type formT struct {
-		ClientID  int    `form:"client_id"`
-		TariffID  int    `form:"tariff_id"`
-		StartDate string `form:"start_date"` // YYYY-MM-DD
-		EndDate   string `form:"end_date"`   // YYYY-MM-DD
-		Status    string `form:"status"`
-		Price     string `form:"price"` // если пусто — возьмём из тарифа
+    in, ok, err := parseSubscriptionForm(c, false)
+    if !ok {
+        return err
+    }
+	if in.Status == "" {
+		// оформленный заранее ждёт даты начала, его активирует фоновая задача
+		in.Status = checkin.StatusActive
+		if in.StartDate.After(checkin.Day(time.Now())) {
+			in.Status = checkin.StatusPending
+		}
 	}
-    var f formT
-    if err := c.BodyParser(&f); err != nil {
-        return jsonError(c, 400, "Неверные данные формы", err)
-    }
-    if f.ClientID <= 0 || f.TariffID <= 0 || f.StartDate == "" || f.EndDate == "" {
-        return jsonError(c, 400, "Заполните обязательные поля", nil)
-    }
-
-    start, err := time.Parse("2006-01-02", f.StartDate)
-    if err != nil {
-        return jsonError(c, 400, "Неверная дата начала", err)
-    }
-    end, err := time.Parse("2006-01-02", f.EndDate)
-    if err != nil {
-        return jsonError(c, 400, "Неверная дата окончания", err)
-    }
-    if end.Before(start) {
-        return jsonError(c, 400, "Дата окончания раньше даты начала", nil)
-    }
-
-	db := database.GetDB()
 
-	var price float64
-    if f.Price != "" {
-        p, err := strconv.ParseFloat(f.Price, 64)
-        if err != nil {
-            return jsonError(c, 400, "Неверная цена", err)
-        }
-        price = p
-    } else {
-        ctx, cancel := withDBTimeout()
-        defer cancel()
-        if err := db.QueryRowContext(ctx, `SELECT "Стоимость" FROM "Тариф" WHERE "id_тарифа"=$1`, f.TariffID).Scan(&price); err != nil {
-            return jsonError(c, 400, "Не удалось получить стоимость тарифа", err)
-        }
+    id, err := createSubscription(c, in)
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 400, "Не удалось получить стоимость тарифа", err)
     }
-
-	if f.Status == "" {
-		f.Status = "Активен"
-	}
-
-	var id int
-    ctx, cancel := withDBTimeout()
-    defer cancel()
-    err = db.QueryRowContext(ctx, `
-        INSERT INTO "Абонемент" ("id_клиента","id_тарифа","Дата_начала","Дата_окончания","Статус","Цена")
-        VALUES ($1,$2,$3,$4,$5,$6)
-        RETURNING "id_абонемента"
-    `, f.ClientID, f.TariffID, start, end, f.Status, price).Scan(&id)
     if err != nil {
         log.Printf("❌ create sub: %v", err)
         return jsonError(c, 500, "Ошибка сохранения в БД", err)
@@ -256,8 +183,18 @@ func GetSubscriptionByID(c *fiber.Ctx) error {
         return jsonError(c, 400, "Некорректный id", err)
     }
 
-	db := database.GetDB()
-	var s struct {
+    ctx, cancel := withDBTimeout()
+    defer cancel()
+    sub, err := data.Subscriptions().Get(ctx, id)
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 404, "Абонемент не найден", nil)
+    }
+    if err != nil {
+        log.Printf("❌ get sub: %v", err)
+        return jsonError(c, 500, "Ошибка БД", err)
+    }
+
+	s := struct {
 		ID         int       `json:"id"`
 		ClientID   int       `json:"client_id"`
 		TariffID   int       `json:"tariff_id"`
@@ -267,38 +204,15 @@ func GetSubscriptionByID(c *fiber.Ctx) error {
 		Price      float64   `json:"price"`
 		ClientName string    `json:"client_name"`
 		TariffName string    `json:"tariff_name"`
-	}
+	}{sub.ID, sub.ClientID, sub.TariffID, sub.StartDate, sub.EndDate, sub.Status, sub.Price, sub.ClientName, sub.TariffName}
 
-    ctx, cancel := withDBTimeout()
-    defer cancel()
-    err = db.QueryRowContext(ctx, `
-        SELECT s."id_абонемента",
-               s."id_клиента",
-               s."id_тарифа",
-               s."Дата_начала",
-               s."Дата_окончания",
-               s."Статус",
-               s."Цена",
-               c."ФИО"              AS client_name,
-               t."Название_тарифа"  AS tariff_name
-        FROM "Абонемент" s
-        JOIN "Клиент" c ON c."id_клиента" = s."id_клиента"
-        JOIN "Тариф"  t ON t."id_тарифа"  = s."id_тарифа"
-        WHERE s."id_абонемента"=$1
-    `, id).Scan(
-        &s.ID, &s.ClientID, &s.TariffID,
-        &s.StartDate, &s.EndDate,
-        &s.Status, &s.Price,
-        &s.ClientName, &s.TariffName,
-    )
-    if err == sql.ErrNoRows {
-        return jsonError(c, 404, "Абонемент не найден", nil)
-    }
+    // история заморозок и остаток лимита — для модалки редактирования
+    payload, err := loadFreezes(ctx, data, sub)
     if err != nil {
-        log.Printf("❌ get sub: %v", err)
-        return jsonError(c, 500, "Ошибка БД", err)
+        return jsonError(c, 500, "Ошибка загрузки заморозок", err)
     }
-    return jsonOK(c, fiber.Map{"subscription": s})
+    payload["subscription"] = s
+    return jsonOK(c, payload)
 }
 
 // ====== Update ======
@@ -308,66 +222,24 @@ func UpdateSubscription(c *fiber.Ctx) error {
         return jsonError(c, 400, "Некорректный id", err)
     }
 
-	type formT struct {
-		ClientID  int    `form:"client_id"`
-		TariffID  int    `form:"tariff_id"`
-		StartDate string `form:"start_date"`
-		EndDate   string `form:"end_date"`
-		Status    string `form:"status"`
-		Price     string `form:"price"`
-	}
-	var f formT
-    if err := c.BodyParser(&f); err != nil {
-        return jsonError(c, 400, "Неверные данные формы", err)
-    }
-    if f.ClientID <= 0 || f.TariffID <= 0 || f.StartDate == "" || f.EndDate == "" || f.Status == "" {
-        return jsonError(c, 400, "Заполните обязательные поля", nil)
-    }
-
-	start, err := time.Parse("2006-01-02", f.StartDate)
-    if err != nil {
-        return jsonError(c, 400, "Неверная дата начала", err)
-    }
-	end, err := time.Parse("2006-01-02", f.EndDate)
-    if err != nil {
-        return jsonError(c, 400, "Неверная дата окончания", err)
-    }
-    if end.Before(start) {
-        return jsonError(c, 400, "Дата окончания раньше даты начала", nil)
+    // пустая цена — оставить прежнюю
+    in, ok, err := parseSubscriptionForm(c, true)
+    if !ok {
+        return err
     }
 
-	var price float64
-    if f.Price != "" {
-        p, err := strconv.ParseFloat(f.Price, 64)
-        if err != nil {
-            return jsonError(c, 400, "Неверная цена", err)
-        }
-        price = p
-    } else {
-        // оставить прежнюю цену
-        db := database.GetDB()
-        ctx, cancel := withDBTimeout()
-        defer cancel()
-        if err := db.QueryRowContext(ctx, `SELECT "Цена" FROM "Абонемент" WHERE "id_абонемента"=$1`, id).Scan(&price); err != nil {
-            return jsonError(c, 400, "Не удалось получить текущую цену", err)
-        }
-    }
-
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    res, err := db.ExecContext(ctx, `
-        UPDATE "Абонемент"
-        SET "id_клиента"=$2, "id_тарифа"=$3, "Дата_начала"=$4, "Дата_окончания"=$5, "Статус"=$6, "Цена"=$7
-        WHERE "id_абонемента"=$1
-    `, id, f.ClientID, f.TariffID, start, end, f.Status, price)
+    err = inTx(ctx, c, func(tx store.Store) error {
+        return tx.Subscriptions().Update(ctx, id, in)
+    })
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 404, "Абонемент не найден", nil)
+    }
     if err != nil {
         log.Printf("❌ update sub: %v", err)
         return jsonError(c, 500, "Ошибка обновления в БД", err)
     }
-    if n, _ := res.RowsAffected(); n == 0 {
-        return jsonError(c, 404, "Абонемент не найден", nil)
-    }
     return jsonOK(c, fiber.Map{"message": "Абонемент обновлён"})
 }
 
@@ -378,41 +250,21 @@ func DeleteSubscription(c *fiber.Ctx) error {
         return jsonError(c, 400, "Некорректный id", err)
     }
 
-    db := database.GetDB()
+    // персональные тренировки и записи на групповые удаляются вместе с абонементом
     ctx, cancel := withDBTimeout()
     defer cancel()
-    tx, err := db.BeginTx(ctx, nil)
-    if err != nil {
-        return jsonError(c, 500, "Не удалось начать транзакцию", err)
-    }
-    defer func() {
-        if err != nil {
-            _ = tx.Rollback()
-        }
-    }()
-
-    // 1) Персональные тренировки этого абонемента
-    if _, err = tx.ExecContext(ctx, `DELETE FROM "Персональная_тренировка" WHERE "id_абонемента" = $1`, id); err != nil {
-        return jsonError(c, 500, "Невозможно удалить связанные персональные тренировки", err)
+    err = inTx(ctx, c, func(tx store.Store) error {
+        return tx.Subscriptions().Delete(ctx, id)
+    })
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 404, "Абонемент не найден", nil)
     }
-
-    // 2) Записи на групповые тренировки этого абонемента
-    if _, err = tx.ExecContext(ctx, `DELETE FROM "Запись_на_групповую_тренировку" WHERE "id_абонемента" = $1`, id); err != nil {
-        return jsonError(c, 500, "Невозможно удалить групповые записи абонемента", err)
+    if errors.Is(err, store.ErrInUse) { // по абонементу или его тренировкам есть платежи
+        return jsonError(c, 409, "По абонементу есть платежи — удалить нельзя, оформите возврат", nil)
     }
-
-    // 3) Сам абонемент
-    res, err := tx.ExecContext(ctx, `DELETE FROM "Абонемент" WHERE "id_абонемента" = $1`, id)
     if err != nil {
         return jsonError(c, 500, "Ошибка удаления абонемента", err)
     }
-    if n, _ := res.RowsAffected(); n == 0 {
-        return jsonError(c, 404, "Абонемент не найден", nil)
-    }
-
-    if err = tx.Commit(); err != nil {
-        return jsonError(c, 500, "Ошибка фиксации транзакции", err)
-    }
 
     return jsonOK(c, fiber.Map{"message": "Абонемент и связанные данные удалены"})
 }
@@ -420,18 +272,12 @@ func DeleteSubscription(c *fiber.Ctx) error {
 
 // ====== API: тарифы для селекта ======
 func GetTariffsForSelect(c *fiber.Ctx) error {
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    rows, err := db.QueryContext(ctx, `
-        SELECT "id_тарифа","Название_тарифа","Стоимость"
-        FROM "Тариф"
-        ORDER BY "id_тарифа"
-    `)
+    tariffs, err := data.Tariffs().List(ctx)
     if err != nil {
         return jsonError(c, 500, "Ошибка чтения тарифов", err)
     }
-    defer rows.Close()
 
 	type t struct {
 		ID    int     `json:"id"`
@@ -439,11 +285,8 @@ func GetTariffsForSelect(c *fiber.Ctx) error {
 		Price float64 `json:"price"`
 	}
 	var list []t
-	for rows.Next() {
-		var item t
-		if err := rows.Scan(&item.ID, &item.Name, &item.Price); err == nil {
-			list = append(list, item)
-		}
+	for i := len(tariffs) - 1; i >= 0; i-- { // List — новые сверху, селекту нужен порядок по id
+		list = append(list, t{ID: tariffs[i].ID, Name: tariffs[i].Name, Price: tariffs[i].Price})
 	}
     return jsonOK(c, fiber.Map{"tariffs": list})
 }
@@ -1,36 +1,24 @@
 package handlers
 
 import (
-    "database/sql"
     "errors"
     "log"
     "strconv"
     "strings"
 
-    "fitness-center-manager/internal/database"
+    "fitness-center-manager/internal/access"
     "fitness-center-manager/internal/models"
+    "fitness-center-manager/internal/store"
 
     "github.com/gofiber/fiber/v2"
 )
 
 // GetTariffsPage — страница со списком тарифов
 func GetTariffsPage(c *fiber.Ctx) error {
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
 
-    rows, err := db.QueryContext(ctx, `
-        SELECT 
-            "id_тарифа",
-            "Название_тарифа",
-            COALESCE("Описание", ''),
-            COALESCE("Стоимость", 0),
-            COALESCE("Время_доступа", '0 hours'::interval),
-            COALESCE("Наличие_групповых_тренировок", false),
-            COALESCE("Наличие_персональных_тренировок", false)
-        FROM "Тариф"
-        ORDER BY "id_тарифа" DESC
-    `)
+    list, err := data.Tariffs().List(ctx)
     if err != nil {
         log.Printf("❌ tariffs list error: %v", err)
         return c.Render("tariffs", fiber.Map{
@@ -40,28 +28,17 @@ func GetTariffsPage(c *fiber.Ctx) error {
             "ExtraScripts": templateScript("/static/js/tariffs.js"),
         })
     }
-    defer rows.Close()
 
-    var list []models.Tariff
-    for rows.Next() {
-        var t models.Tariff
-        var access sql.NullString
-        if err := rows.Scan(&t.ID, &t.Name, &t.Description, &t.Price, &access, &t.HasGroupTrainings, &t.HasPersonalTrainings); err != nil {
-            log.Printf("❌ scan tariff: %v", err)
-            continue
+    issues := 0
+    for _, t := range list {
+        if t.AccessHoursIssue != "" {
+            issues++
         }
-        if access.Valid {
-            t.AccessTime = access.String
-        }
-        list = append(list, t)
-    }
-    if err = rows.Err(); err != nil {
-        log.Printf("❌ rows err: %v", err)
     }
-
     return c.Render("tariffs", fiber.Map{
         "Title":        "Тарифы",
         "Tariffs":      list,
+        "AccessIssues": issues,
         "ExtraScripts": templateScript("/static/js/tariffs.js"),
     })
 }
@@ -72,78 +49,107 @@ func GetTariffByID(c *fiber.Ctx) error {
     if err != nil || id <= 0 {
         return jsonError(c, 400, "Некорректный id", err)
     }
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
 
-    var t models.Tariff
-    var access sql.NullString
-    err = db.QueryRowContext(ctx, `
-        SELECT 
-            "id_тарифа",
-            "Название_тарифа",
-            COALESCE("Описание", ''),
-            COALESCE("Стоимость", 0),
-            COALESCE("Время_доступа", '0 hours'::interval),
-            COALESCE("Наличие_групповых_тренировок", false),
-            COALESCE("Наличие_персональных_тренировок", false)
-        FROM "Тариф"
-        WHERE "id_тарифа"=$1
-    `, id).Scan(&t.ID, &t.Name, &t.Description, &t.Price, &access, &t.HasGroupTrainings, &t.HasPersonalTrainings)
-
+    t, err := data.Tariffs().Get(ctx, id)
     switch {
-    case errors.Is(err, sql.ErrNoRows):
+    case errors.Is(err, store.ErrNotFound):
         return jsonError(c, 404, "Тариф не найден", nil)
     case err != nil:
         return jsonError(c, 500, "DB: ошибка чтения", err)
     }
-    if access.Valid {
-        t.AccessTime = access.String
+    // часы доступа — ещё и текстом, в том виде, в каком их принимает форма
+    return jsonOK(c, fiber.Map{"tariff": t, "access_hours": t.AccessHours.String()})
+}
+
+type tariffForm struct {
+    Name        string `form:"name"`
+    Description string `form:"description"`
+    Price       string `form:"price"`
+    AccessHours string `form:"access_hours"` // напр. "будни 07:00–16:00; выходные 09:00–14:00"
+    HasGroup    string `form:"has_group"`
+    HasPersonal string `form:"has_personal"`
+    FreezeDays  string `form:"freeze_max_days"`  // пусто — 30
+    FreezeCount string `form:"freeze_max_count"` // пусто — 2, 0 — без заморозки
+}
+
+// Лимиты заморозки по умолчанию (как DEFAULT в БД).
+const (
+    defaultFreezeMaxDays  = 30
+    defaultFreezeMaxCount = 2
+)
+
+// parseLimit — целое >= 0; пустая строка — def.
+func parseLimit(s string, def int) (int, error) {
+    s = strings.TrimSpace(s)
+    if s == "" {
+        return def, nil
+    }
+    n, err := strconv.Atoi(s)
+    if err == nil && n < 0 {
+        err = strconv.ErrRange
     }
-    return jsonOK(c, fiber.Map{"tariff": t})
+    return n, err
 }
 
-// CreateTariff — создать тариф
-func CreateTariff(c *fiber.Ctx) error {
-    type formT struct {
-        Name        string `form:"name"`
-        Description string `form:"description"`
-        Price       string `form:"price"`
-        AccessTime  string `form:"access_time"` // строка interval: напр. "30 days", "1 mon"
-        HasGroup    string `form:"has_group"`
-        HasPersonal string `form:"has_personal"`
-    }
-    var f formT
+// parseTariffForm разбирает и проверяет форму тарифа; при ошибке ответ уже отправлен.
+func parseTariffForm(c *fiber.Ctx) (store.TariffInput, bool, error) {
+    var f tariffForm
     if err := c.BodyParser(&f); err != nil {
-        return jsonError(c, 400, "Неверные данные формы", err)
+        return store.TariffInput{}, false, jsonError(c, 400, "Неверные данные формы", err)
     }
     name := strings.TrimSpace(f.Name)
     if name == "" {
-        return jsonError(c, 400, "Название тарифа обязательно", nil)
+        return store.TariffInput{}, false, jsonError(c, 400, "Название тарифа обязательно", nil)
     }
-    var price float64
-    if strings.TrimSpace(f.Price) != "" {
-        p, err := strconv.ParseFloat(strings.ReplaceAll(f.Price, ",", "."), 64)
-        if err != nil || p <= 0 {
-            return jsonError(c, 400, "Неверная стоимость", err)
-        }
-        price = p
-    } else {
-        return jsonError(c, 400, "Стоимость обязательна", nil)
+    if strings.TrimSpace(f.Price) == "" {
+        return store.TariffInput{}, false, jsonError(c, 400, "Стоимость обязательна", nil)
+    }
+    p, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(f.Price), ",", "."), 64)
+    if err != nil || p <= 0 {
+        return store.TariffInput{}, false, jsonError(c, 400, "Неверная стоимость", err)
+    }
+    hours, err := access.Parse(f.AccessHours)
+    if err != nil {
+        return store.TariffInput{}, false, jsonError(c, 400, "Неверные часы доступа: "+err.Error(), nil)
+    }
+    freezeDays, err := parseLimit(f.FreezeDays, defaultFreezeMaxDays)
+    if err != nil {
+        return store.TariffInput{}, false, jsonError(c, 400, "Неверный лимит дней заморозки", err)
+    }
+    freezeCount, err := parseLimit(f.FreezeCount, defaultFreezeMaxCount)
+    if err != nil {
+        return store.TariffInput{}, false, jsonError(c, 400, "Неверное число заморозок", err)
+    }
+    return store.TariffInput{
+        Name:        name,
+        Description: f.Description,
+        Price:       p,
+        AccessHours: hours,
+        HasGroup:    strings.ToLower(strings.TrimSpace(f.HasGroup)) == "on",
+        HasPersonal: strings.ToLower(strings.TrimSpace(f.HasPersonal)) == "on",
+
+        FreezeMaxDays:  freezeDays,
+        FreezeMaxCount: freezeCount,
+    }, true, nil
+}
+
+// CreateTariff — создать тариф
+func CreateTariff(c *fiber.Ctx) error {
+    in, ok, err := parseTariffForm(c)
+    if !ok {
+        return err
     }
-    hasGroup := strings.ToLower(strings.TrimSpace(f.HasGroup)) == "on"
-    hasPersonal := strings.ToLower(strings.TrimSpace(f.HasPersonal)) == "on"
 
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-
     var id int
-    err := db.QueryRowContext(ctx, `
-        INSERT INTO "Тариф" ("Название_тарифа","Описание","Стоимость","Время_доступа","Наличие_групповых_тренировок","Наличие_персональных_тренировок")
-        VALUES ($1,$2,$3, NULLIF($4,'')::interval, $5, $6)
-        RETURNING "id_тарифа"
-    `, name, f.Description, price, strings.TrimSpace(f.AccessTime), hasGroup, hasPersonal).Scan(&id)
+    err = inTx(ctx, c, func(tx store.Store) error {
+        var err error
+        id, err = tx.Tariffs().Create(ctx, in)
+        return err
+    })
     if err != nil {
         return jsonError(c, 500, "Ошибка создания тарифа", err)
     }
@@ -156,48 +162,22 @@ func UpdateTariff(c *fiber.Ctx) error {
     if err != nil || id <= 0 {
         return jsonError(c, 400, "Некорректный id", err)
     }
-    type formT struct {
-        Name        string `form:"name"`
-        Description string `form:"description"`
-        Price       string `form:"price"`
-        AccessTime  string `form:"access_time"`
-        HasGroup    string `form:"has_group"`
-        HasPersonal string `form:"has_personal"`
-    }
-    var f formT
-    if err := c.BodyParser(&f); err != nil {
-        return jsonError(c, 400, "Неверные данные формы", err)
-    }
-    name := strings.TrimSpace(f.Name)
-    if name == "" {
-        return jsonError(c, 400, "Название тарифа обязательно", nil)
-    }
-    p, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(f.Price), ",", "."), 64)
-    if err != nil || p <= 0 {
-        return jsonError(c, 400, "Неверная стоимость", err)
+    in, ok, err := parseTariffForm(c)
+    if !ok {
+        return err
     }
-    hasGroup := strings.ToLower(strings.TrimSpace(f.HasGroup)) == "on"
-    hasPersonal := strings.ToLower(strings.TrimSpace(f.HasPersonal)) == "on"
 
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    res, err := db.ExecContext(ctx, `
-        UPDATE "Тариф"
-        SET "Название_тарифа"=$2,
-            "Описание"=$3,
-            "Стоимость"=$4,
-            "Время_доступа"=NULLIF($5,'')::interval,
-            "Наличие_групповых_тренировок"=$6,
-            "Наличие_персональных_тренировок"=$7
-        WHERE "id_тарифа"=$1
-    `, id, name, f.Description, p, strings.TrimSpace(f.AccessTime), hasGroup, hasPersonal)
+    err = inTx(ctx, c, func(tx store.Store) error {
+        return tx.Tariffs().Update(ctx, id, in)
+    })
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 404, "Тариф не найден", nil)
+    }
     if err != nil {
         return jsonError(c, 500, "DB: ошибка обновления", err)
     }
-    if n, _ := res.RowsAffected(); n == 0 {
-        return jsonError(c, 404, "Тариф не найден", nil)
-    }
     return jsonOK(c, fiber.Map{"message": "Тариф обновлён"})
 }
 
@@ -207,13 +187,18 @@ func DeleteTariff(c *fiber.Ctx) error {
     if err != nil || id <= 0 {
         return jsonError(c, 400, "Некорректный id", err)
     }
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    _, err = db.ExecContext(ctx, `DELETE FROM "Тариф" WHERE "id_тарифа"=$1`, id)
-    if err != nil {
+    err = inTx(ctx, c, func(tx store.Store) error {
+        return tx.Tariffs().Delete(ctx, id)
+    })
+    switch {
+    case errors.Is(err, store.ErrNotFound):
+        return jsonError(c, 404, "Тариф не найден", nil)
+    case errors.Is(err, store.ErrInUse):
         return jsonError(c, 409, "Невозможно удалить тариф: есть связанные абонементы", err)
+    case err != nil:
+        return jsonError(c, 500, "DB: ошибка удаления", err)
     }
     return jsonOK(c, fiber.Map{"message": "Тариф удалён"})
 }
-
@@ -1,15 +1,15 @@
 package handlers
 
 import (
-    "database/sql"
+    "errors"
     "fmt"
     "html/template"
     "log"
     "strconv"
     "time"
 
-    "fitness-center-manager/internal/database"
     "fitness-center-manager/internal/models"
+    "fitness-center-manager/internal/store"
     "github.com/gofiber/fiber/v2"
 )
 
@@ -17,23 +17,15 @@ func tplScript(src string) template.HTML { // маленький помощни
 	return template.HTML(fmt.Sprintf(`<script src="%s"></script>`, src))
 }
 
-func GetTrainersPage(c *fiber.Ctx) error {
-    db := database.GetDB()
+func trainerInput(fio, phone, specialization string, hire time.Time, experience int) store.TrainerInput {
+	return store.TrainerInput{FIO: fio, Phone: phone, Specialization: specialization, HireDate: hire, Experience: experience}
+}
 
+func GetTrainersPage(c *fiber.Ctx) error {
     ctx, cancel := withDBTimeout()
     defer cancel()
 
-    rows, err := db.QueryContext(ctx, `
-        SELECT 
-            "id_тренера",
-            "ФИО",
-            "Номер_телефона",
-            "Специализация",
-            "Дата_найма",
-            "Стаж_работы"
-        FROM "Тренер"
-        ORDER BY "id_тренера" DESC
-    `)
+    list, err := data.Trainers().List(ctx)
 	if err != nil {
 		log.Printf("❌ trainers list error: %v", err)
 		return c.Render("trainers", fiber.Map{
@@ -43,27 +35,6 @@ func GetTrainersPage(c *fiber.Ctx) error {
 			"ExtraScripts": tplScript(`/static/js/trainers.js`),
 		})
 	}
-	defer rows.Close()
-
-	var list []models.Trainer
-	for rows.Next() {
-		var t models.Trainer
-		if err := rows.Scan(
-			&t.ID,
-			&t.FIO,
-			&t.Phone,
-			&t.Specialization,
-			&t.HireDate,
-			&t.Experience,
-		); err != nil {
-			log.Printf("❌ scan trainer: %v", err)
-			continue
-		}
-		list = append(list, t)
-	}
-	if err = rows.Err(); err != nil {
-		log.Printf("❌ trainers rows err: %v", err)
-	}
 
 	return c.Render("trainers", fiber.Map{
 		"Title":        "Тренеры",
@@ -74,24 +45,12 @@ func GetTrainersPage(c *fiber.Ctx) error {
 
 // APIv1ListTrainers — JSON список тренеров
 func APIv1ListTrainers(c *fiber.Ctx) error {
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    rows, err := db.QueryContext(ctx, `
-        SELECT 
-            "id_тренера",
-            "ФИО",
-            "Номер_телефона",
-            "Специализация",
-            "Дата_найма",
-            "Стаж_работы"
-        FROM "Тренер"
-        ORDER BY "id_тренера" DESC
-    `)
+    trainers, err := data.Trainers().List(ctx)
     if err != nil {
         return jsonError(c, 500, "Ошибка загрузки тренеров", err)
     }
-    defer rows.Close()
     type dto struct {
         ID             int    `json:"id"`
         FIO            string `json:"fio"`
@@ -101,11 +60,7 @@ func APIv1ListTrainers(c *fiber.Ctx) error {
         Experience     int    `json:"experience"`
     }
     var list []dto
-    for rows.Next() {
-        var t models.Trainer
-        if err := rows.Scan(&t.ID, &t.FIO, &t.Phone, &t.Specialization, &t.HireDate, &t.Experience); err != nil {
-            return jsonError(c, 500, "Ошибка чтения тренера", err)
-        }
+    for _, t := range trainers {
         list = append(list, dto{
             ID: t.ID,
             FIO: t.FIO,
@@ -115,9 +70,6 @@ func APIv1ListTrainers(c *fiber.Ctx) error {
             Experience: t.Experience,
         })
     }
-    if err := rows.Err(); err != nil {
-        return jsonError(c, 500, "Ошибка курсора", err)
-    }
     return jsonOK(c, fiber.Map{"trainers": list})
 }
 
@@ -141,15 +93,14 @@ func CreateTrainer(c *fiber.Ctx) error {
         return jsonError(c, 400, "Неверная дата найма", err)
     }
 
-	db := database.GetDB()
 	var id int
     ctx, cancel := withDBTimeout()
     defer cancel()
-    err = db.QueryRowContext(ctx, `
-        INSERT INTO "Тренер" ("ФИО","Номер_телефона","Специализация","Дата_найма","Стаж_работы")
-        VALUES ($1,$2,$3,$4,$5)
-        RETURNING "id_тренера"
-    `, f.FIO, f.Phone, f.Specialization, hire, f.Experience).Scan(&id)
+    err = inTx(ctx, c, func(tx store.Store) error {
+        var err error
+        id, err = tx.Trainers().Create(ctx, trainerInput(f.FIO, f.Phone, f.Specialization, hire, f.Experience))
+        return err
+    })
     if err != nil {
         log.Printf("❌ create trainer: %v", err)
         return jsonError(c, 500, "Ошибка сохранения тренера", err)
@@ -177,15 +128,14 @@ func APIv1CreateTrainer(c *fiber.Ctx) error {
     if err != nil {
         return jsonError(c, 400, "Неверная дата найма", err)
     }
-    db := database.GetDB()
     var id int
     ctx, cancel := withDBTimeout()
     defer cancel()
-    err = db.QueryRowContext(ctx, `
-        INSERT INTO "Тренер" ("ФИО","Номер_телефона","Специализация","Дата_найма","Стаж_работы")
-        VALUES ($1,$2,$3,$4,$5)
-        RETURNING "id_тренера"
-    `, f.FIO, f.Phone, f.Specialization, hire, f.Experience).Scan(&id)
+    err = inTx(ctx, c, func(tx store.Store) error {
+        var err error
+        id, err = tx.Trainers().Create(ctx, trainerInput(f.FIO, f.Phone, f.Specialization, hire, f.Experience))
+        return err
+    })
     if err != nil {
         return jsonError(c, 500, "Ошибка сохранения тренера", err)
     }
@@ -198,24 +148,10 @@ func GetTrainerByID(c *fiber.Ctx) error {
     if err != nil || id <= 0 {
         return jsonError(c, 400, "Некорректный id", err)
     }
-    db := database.GetDB()
-    var t models.Trainer
     ctx, cancel := withDBTimeout()
     defer cancel()
-    err = db.QueryRowContext(ctx, `
-        SELECT 
-            "id_тренера",
-            "ФИО",
-            "Номер_телефона",
-            "Специализация",
-            "Дата_найма",
-            "Стаж_работы"
-        FROM "Тренер"
-        WHERE "id_тренера"=$1
-    `, id).Scan(
-        &t.ID, &t.FIO, &t.Phone, &t.Specialization, &t.HireDate, &t.Experience,
-    )
-    if err == sql.ErrNoRows {
+    t, err := data.Trainers().Get(ctx, id)
+    if errors.Is(err, store.ErrNotFound) {
         return jsonError(c, 404, "Тренер не найден", nil)
     }
     if err != nil {
@@ -259,21 +195,18 @@ func UpdateTrainer(c *fiber.Ctx) error {
         return jsonError(c, 400, "Неверная дата найма", err)
     }
 
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    res, err := db.ExecContext(ctx, `
-        UPDATE "Тренер"
-        SET "ФИО"=$2, "Номер_телефона"=$3, "Специализация"=$4, "Дата_найма"=$5, "Стаж_работы"=$6
-        WHERE "id_тренера"=$1
-    `, id, f.FIO, f.Phone, f.Specialization, hire, f.Experience)
+    err = inTx(ctx, c, func(tx store.Store) error {
+        return tx.Trainers().Update(ctx, id, trainerInput(f.FIO, f.Phone, f.Specialization, hire, f.Experience))
+    })
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 404, "Тренер не найден", nil)
+    }
     if err != nil {
         log.Printf("❌ update trainer: %v", err)
         return jsonError(c, 500, "Ошибка обновления", err)
     }
-    if n, _ := res.RowsAffected(); n == 0 {
-        return jsonError(c, 404, "Тренер не найден", nil)
-    }
     return jsonOK(c, fiber.Map{"message": "Данные тренера обновлены"})
 }
 
@@ -283,47 +216,30 @@ func DeleteTrainer(c *fiber.Ctx) error {
     if err != nil || id <= 0 {
         return jsonError(c, 400, "Некорректный id", err)
     }
-    db := database.GetDB()
-
     // Если на тренера ссылаются тренировки, тут может быть FK.
     ctx, cancel := withDBTimeout()
     defer cancel()
-    res, err := db.ExecContext(ctx, `DELETE FROM "Тренер" WHERE "id_тренера"=$1`, id)
-    if err != nil {
-        return jsonError(c, 409, "Невозможно удалить: есть связанные тренировки", err)
-    }
-    if n, _ := res.RowsAffected(); n == 0 {
+    err = inTx(ctx, c, func(tx store.Store) error {
+        return tx.Trainers().Delete(ctx, id)
+    })
+    switch {
+    case errors.Is(err, store.ErrNotFound):
         return jsonError(c, 404, "Тренер не найден", nil)
+    case errors.Is(err, store.ErrInUse):
+        return jsonError(c, 409, "Невозможно удалить: есть связанные тренировки", err)
+    case err != nil:
+        return jsonError(c, 500, "Ошибка БД при удалении тренера", err)
     }
     return jsonOK(c, fiber.Map{"message": "Тренер удалён"})
 }
 
 func GetTrainersForSelect(c *fiber.Ctx) error {
-	db := database.GetDB()
-
     ctx, cancel := withDBTimeout()
     defer cancel()
-    rows, err := db.QueryContext(ctx, `
-        SELECT "id_тренера", "ФИО"
-        FROM "Тренер"
-        ORDER BY "ФИО"
-    `)
+    out, err := data.Trainers().Options(ctx)
     if err != nil {
         log.Printf("❌ trainers-for-select: %v", err)
         return jsonError(c, 500, "Ошибка чтения тренеров", err)
     }
-	defer rows.Close()
-
-	type item struct {
-		ID   int    `json:"id"`
-		Name string `json:"name"`
-	}
-	var out []item
-	for rows.Next() {
-		var it item
-		if err := rows.Scan(&it.ID, &it.Name); err == nil {
-			out = append(out, it)
-		}
-	}
     return jsonOK(c, fiber.Map{"trainers": out})
 }
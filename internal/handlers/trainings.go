@@ -1,8 +1,12 @@
 package handlers
 
 import (
-    "database/sql"
-    "fitness-center-manager/internal/database"
+    "context"
+    "errors"
+    "fitness-center-manager/internal/enrollment"
+    "fitness-center-manager/internal/entitlement"
+    "fitness-center-manager/internal/models"
+    "fitness-center-manager/internal/store"
     "fmt"
     "log"
     "strconv"
@@ -14,35 +18,24 @@ import (
 
 // для записи на групповую: нужен список абонементов (id + «ФИО (абонемент #)»)
 func GetSubscriptionsForSelect(c *fiber.Ctx) error {
-	db := database.GetDB()
-	rows, err := db.Query(`
-		SELECT s."id_абонемента", c."ФИО", s."Статус"
-		FROM "Абонемент" s
-		JOIN "Клиент" c ON c."id_клиента" = s."id_клиента"
-		ORDER BY s."id_абонемента" DESC
-	`)
+	ctx, cancel := withDBTimeout()
+	defer cancel()
+	subs, err := data.Subscriptions().Options(ctx)
     if err != nil {
         return jsonError(c, 500, "Ошибка загрузки абонементов", err)
     }
-	defer rows.Close()
 	type item struct{ ID int; Label string }
 	var out []item
-	for rows.Next() {
-		var id int
-		var fio, st string
-		if err := rows.Scan(&id, &fio, &st); err == nil {
-			out = append(out, item{ID: id, Label: fmt.Sprintf("%s (абонемент #%d, %s)", fio, id, st)})
-		}
+	for _, s := range subs {
+		out = append(out, item{ID: s.ID, Label: fmt.Sprintf("%s (абонемент #%d, %s)", s.ClientName, s.ID, s.Status)})
 	}
 	return c.JSON(fiber.Map{"success": true, "subscriptions": out})
 }
 
 
-// ====== Страница тренировок (сводка) ======
-func GetTrainingsPage(c *fiber.Ctx) error {
-	db := database.GetDB()
-
-	// ---- параметры фильтра ----
+// trainingFilter — фильтры списков тренировок из query-строки.
+// Возвращает и исходные значения — для формы фильтра на странице.
+func trainingFilter(c *fiber.Ctx) (store.TrainingFilter, fiber.Map, error) {
 	q          := strings.TrimSpace(c.Query("q"))          // общий поиск
 	qTrainer   := strings.TrimSpace(c.Query("trainer_id")) // ID тренера
 	qZone      := strings.TrimSpace(c.Query("zone_id"))    // ID зоны (групповые)
@@ -53,233 +46,102 @@ func GetTrainingsPage(c *fiber.Ctx) error {
 	onlyUpcoming := c.Query("upcoming") == "1"
 	recent30     := c.Query("recent") == "1"
 
-	// ================== ГРУППОВЫЕ (из vw_group_training_with_slots) ==================
-	whereG := []string{}
-	argsG  := []any{}
-	nextPHG := func(n int) []string {
-		start := len(argsG) + 1
-		ph := make([]string, n)
-		for i := 0; i < n; i++ {
-			ph[i] = "$" + strconv.Itoa(start+i)
-		}
-		return ph
-	}
-
-	queryG := `
-		SELECT 
-			v."id_групповой_тренировки",
-			v."Название",
-			COALESCE(v."Описание",'')               AS description,
-			COALESCE(v."Максимум_участников",0)     AS max,
-			v."Время_начала",
-			v."Время_окончания",
-			COALESCE(v."Уровень_сложности",'')      AS level,
-			v.trainer_name,
-			v."id_тренера",
-			v.zone_name,
-			v."id_зоны",
-			v.free_slots                             -- вычисляемая
-		FROM vw_group_training_with_slots v
-	`
-
-	if q != "" {
-		like := "%" + q + "%"
-		ph := nextPHG(3)
-		whereG = append(whereG, `(
-			v."Название"    ILIKE `+ph[0]+` OR
-			v.trainer_name  ILIKE `+ph[1]+` OR
-			v.zone_name     ILIKE `+ph[2]+`
-		)`)
-		argsG = append(argsG, like, like, like)
-	}
+	raw := fiber.Map{
+		"q":          q,
+		"trainer_id": qTrainer,
+		"zone_id":    qZone,
+		"level":      qLevel,
+		"status":     qStatus,
+		"from":       qFrom,
+		"to":         qTo,
+		"upcoming":   onlyUpcoming,
+		"recent":     recent30,
+	}
+	f := store.TrainingFilter{
+		Query:    q,
+		Level:    qLevel,
+		Status:   qStatus,
+		Upcoming: onlyUpcoming,
+		Recent:   recent30,
+	}
+	var err error
 	if qTrainer != "" {
-		ph := nextPHG(1)
-		whereG = append(whereG, `v."id_тренера" = `+ph[0]+`::int`)
-		argsG = append(argsG, qTrainer)
+		if f.TrainerID, err = strconv.Atoi(qTrainer); err != nil {
+			return f, raw, fmt.Errorf("trainer_id: %w", err)
+		}
 	}
 	if qZone != "" {
-		ph := nextPHG(1)
-		whereG = append(whereG, `v."id_зоны" = `+ph[0]+`::int`)
-		argsG = append(argsG, qZone)
-	}
-	if qLevel != "" {
-		ph := nextPHG(1)
-		whereG = append(whereG, `v."Уровень_сложности" = `+ph[0])
-		argsG = append(argsG, qLevel)
-	}
-	if qFrom != "" {
-		ph := nextPHG(1)
-		whereG = append(whereG, `v."Время_начала" >= `+ph[0]+`::timestamp`)
-		argsG = append(argsG, qFrom)
-	}
-	if qTo != "" {
-		ph := nextPHG(1)
-		whereG = append(whereG, `v."Время_начала" <= `+ph[0]+`::timestamp`)
-		argsG = append(argsG, qTo)
-	}
-	if onlyUpcoming {
-		ph := nextPHG(1)
-		whereG = append(whereG, `v."Время_начала" >= `+ph[0]+`::timestamp`)
-		argsG = append(argsG, time.Now())
+		if f.ZoneID, err = strconv.Atoi(qZone); err != nil {
+			return f, raw, fmt.Errorf("zone_id: %w", err)
+		}
 	}
-	if recent30 {
-		whereG = append(whereG, `v."Время_начала" >= NOW() - INTERVAL '30 days'`)
+	if f.From, err = parseFilterTime(qFrom); err != nil {
+		return f, raw, fmt.Errorf("from: %w", err)
 	}
-
-	if len(whereG) > 0 {
-		queryG += " WHERE " + strings.Join(whereG, " AND ")
+	if f.To, err = parseFilterTime(qTo); err != nil {
+		return f, raw, fmt.Errorf("to: %w", err)
 	}
-	queryG += ` ORDER BY v."Время_начала" DESC, v."id_групповой_тренировки" DESC`
+	return f, raw, nil
+}
 
-	var groups []fiber.Map
-	{
-		ctx, cancel := withDBTimeout()
-		defer cancel()
-		rows, err := db.QueryContext(ctx, queryG, argsG...)
-		if err != nil {
-			log.Printf("groups list err: %v", err)
-		} else {
-			defer rows.Close()
-			for rows.Next() {
-				var (
-					id, max, trainerID, zoneID int
-					title, desc, level, trainerName, zoneName string
-					start, end time.Time
-					freeSlots int
-				)
-				if err := rows.Scan(
-					&id, &title, &desc, &max, &start, &end, &level,
-					&trainerName, &trainerID, &zoneName, &zoneID, &freeSlots,
-				); err == nil {
-					groups = append(groups, fiber.Map{
-						"ID": id, "Title": title, "Description": desc, "Max": max,
-						"Start": start, "End": end, "Level": level,
-						"TrainerName": trainerName, "TrainerID": trainerID,
-						"ZoneName": zoneName, "ZoneID": zoneID,
-						"FreeSlots": freeSlots, // можно вывести в UI при желании
-					})
-				}
-			}
-		}
+// parseFilterTime — дата или дата со временем; пустая строка — нулевое время.
+func parseFilterTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
 	}
-
-	// ================== ПЕРСОНАЛЬНЫЕ (из vw_personal_training_enriched) ==================
-	whereP := []string{}
-	argsP  := []any{}
-	nextPHP := func(n int) []string {
-		start := len(argsP) + 1
-		ph := make([]string, n)
-		for i := 0; i < n; i++ {
-			ph[i] = "$" + strconv.Itoa(start+i)
-		}
-		return ph
+	if t, err := time.Parse("2006-01-02T15:04", s); err == nil {
+		return t, nil
 	}
+	return time.Parse("2006-01-02", s)
+}
 
-	queryP := `
-		SELECT 
-			v."id_персональной_тренировки",
-			v."Время_начала",
-			v."Время_окончания",
-			v."Статус",
-			COALESCE(v."Стоимость",0)     AS price,
-			v."id_абонемента",
-			v."id_клиента",
-			v.client_fio,
-			v."id_тренера",
-			v.trainer_fio
-			-- v.duration_minutes, v.is_upcoming   -- есть во вью, если захочешь — добери
-		FROM vw_personal_training_enriched v
-	`
-
-	if q != "" {
-		like := "%" + q + "%"
-		ph := nextPHP(2)
-		whereP = append(whereP, `(
-			v.client_fio  ILIKE `+ph[0]+` OR
-			v.trainer_fio ILIKE `+ph[1]+`
-		)`)
-		argsP = append(argsP, like, like)
-	}
-	if qTrainer != "" {
-		ph := nextPHP(1)
-		whereP = append(whereP, `v."id_тренера" = `+ph[0]+`::int`)
-		argsP = append(argsP, qTrainer)
-	}
-	if qStatus != "" {
-		ph := nextPHP(1)
-		whereP = append(whereP, `v."Статус" = `+ph[0])
-		argsP = append(argsP, qStatus)
-	}
-	if qFrom != "" {
-		ph := nextPHP(1)
-		whereP = append(whereP, `v."Время_начала" >= `+ph[0]+`::timestamp`)
-		argsP = append(argsP, qFrom)
-	}
-	if qTo != "" {
-		ph := nextPHP(1)
-		whereP = append(whereP, `v."Время_начала" <= `+ph[0]+`::timestamp`)
-		argsP = append(argsP, qTo)
-	}
-	if onlyUpcoming {
-		ph := nextPHP(1)
-		whereP = append(whereP, `v."Время_начала" >= `+ph[0]+`::timestamp`)
-		argsP = append(argsP, time.Now())
-	}
-	if recent30 {
-		whereP = append(whereP, `v."Время_начала" >= NOW() - INTERVAL '30 days'`)
+// ====== Страница тренировок (сводка) ======
+func GetTrainingsPage(c *fiber.Ctx) error {
+	f, raw, err := trainingFilter(c)
+	if err != nil {
+		log.Printf("trainings filter: %v", err)
 	}
 
-	if len(whereP) > 0 {
-		queryP += " WHERE " + strings.Join(whereP, " AND ")
-	}
-	queryP += ` ORDER BY v."Время_начала" DESC, v."id_персональной_тренировки" DESC`
+	ctx, cancel := withDBTimeout()
+	defer cancel()
 
+	// ================== ГРУППОВЫЕ ==================
+	var groups []fiber.Map
+	gl, err := data.Trainings().ListGroups(ctx, f)
+	if err != nil {
+		log.Printf("groups list err: %v", err)
+	}
+	for _, g := range gl {
+		groups = append(groups, fiber.Map{
+			"ID": g.ID, "Title": g.Name, "Description": g.Description, "Max": g.MaxParticipants,
+			"Start": g.StartTime, "End": g.EndTime, "Level": g.DifficultyLevel,
+			"TrainerName": g.TrainerName, "TrainerID": g.TrainerID,
+			"ZoneName": g.ZoneName, "ZoneID": g.ZoneID,
+			"FreeSlots": g.FreeSlots, // можно вывести в UI при желании
+			"SeriesID": g.SeriesID, "Modified": g.Modified,
+		})
+	}
+
+	// ================== ПЕРСОНАЛЬНЫЕ ==================
 	var personal []fiber.Map
-	{
-		ctx, cancel := withDBTimeout()
-		defer cancel()
-		rows, err := db.QueryContext(ctx, queryP, argsP...)
-		if err != nil {
-			log.Printf("personal list err: %v", err)
-		} else {
-			defer rows.Close()
-			for rows.Next() {
-				var (
-					id, subID, clientID, trainerID int
-					start, end time.Time
-					status string
-					price float64
-					clientFIO, trainerFIO string
-				)
-				if err := rows.Scan(
-					&id, &start, &end, &status, &price,
-					&subID, &clientID, &clientFIO, &trainerID, &trainerFIO,
-				); err == nil {
-					personal = append(personal, fiber.Map{
-						"ID": id, "Start": start, "End": end, "Status": status, "Price": price,
-						"SubscriptionID": subID, "ClientID": clientID, "ClientFIO": clientFIO,
-						"TrainerID": trainerID, "TrainerFIO": trainerFIO,
-					})
-				}
-			}
-		}
+	pl, err := data.Trainings().ListPersonal(ctx, f)
+	if err != nil {
+		log.Printf("personal list err: %v", err)
+	}
+	for _, p := range pl {
+		personal = append(personal, fiber.Map{
+			"ID": p.ID, "Start": p.StartTime, "End": p.EndTime, "Status": p.Status, "Price": p.Price,
+			"SubscriptionID": p.SubscriptionID, "ClientID": p.ClientID, "ClientFIO": p.ClientName,
+			"TrainerID": p.TrainerID, "TrainerFIO": p.TrainerName, "Paid": p.Paid,
+			"OverrideReason": p.OverrideReason,
+		})
 	}
 
 	return c.Render("trainings", fiber.Map{
 		"Title":    "Тренировки",
 		"Groups":   groups,
 		"Personal": personal,
-		"Filter": fiber.Map{
-			"q":          q,
-			"trainer_id": qTrainer,
-			"zone_id":    qZone,
-			"level":      qLevel,
-			"status":     qStatus,
-			"from":       qFrom,
-			"to":         qTo,
-			"upcoming":   onlyUpcoming,
-			"recent":     recent30,
-		},
+		"Filter":   raw,
 		"ExtraScripts": templateScript("/static/js/trainings.js"),
 	})
 }
@@ -288,94 +150,15 @@ func GetTrainingsPage(c *fiber.Ctx) error {
 
 // APIv1ListGroupTrainings — JSON-список групповых тренировок с фильтрами
 func APIv1ListGroupTrainings(c *fiber.Ctx) error {
-    db := database.GetDB()
-    q := strings.TrimSpace(c.Query("q"))
-    qTrainer := strings.TrimSpace(c.Query("trainer_id"))
-    qZone := strings.TrimSpace(c.Query("zone_id"))
-    qLevel := strings.TrimSpace(c.Query("level"))
-    qFrom := strings.TrimSpace(c.Query("from"))
-    qTo := strings.TrimSpace(c.Query("to"))
-    onlyUpcoming := c.Query("upcoming") == "1"
-    recent30 := c.Query("recent") == "1"
-
-    where := []string{}
-    args := []any{}
-    nextPH := func(n int) []string {
-        start := len(args) + 1
-        ph := make([]string, n)
-        for i := 0; i < n; i++ { ph[i] = "$" + strconv.Itoa(start+i) }
-        return ph
-    }
-
-    query := `
-        SELECT 
-            v."id_групповой_тренировки",
-            v."Название",
-            COALESCE(v."Описание",'')               AS description,
-            COALESCE(v."Максимум_участников",0)     AS max,
-            v."Время_начала",
-            v."Время_окончания",
-            COALESCE(v."Уровень_сложности",'')      AS level,
-            v.trainer_name,
-            v."id_тренера",
-            v.zone_name,
-            v."id_зоны",
-            v.free_slots
-        FROM vw_group_training_with_slots v`
-
-    if q != "" {
-        like := "%" + q + "%"
-        ph := nextPH(3)
-        where = append(where, `(
-            v."Название" ILIKE `+ph[0]+` OR
-            v.trainer_name ILIKE `+ph[1]+` OR
-            v.zone_name ILIKE `+ph[2]+`
-        )`)
-        args = append(args, like, like, like)
-    }
-    if qTrainer != "" {
-        ph := nextPH(1)
-        where = append(where, `v."id_тренера" = `+ph[0]+`::int`)
-        args = append(args, qTrainer)
-    }
-    if qZone != "" {
-        ph := nextPH(1)
-        where = append(where, `v."id_зоны" = `+ph[0]+`::int`)
-        args = append(args, qZone)
-    }
-    if qLevel != "" {
-        ph := nextPH(1)
-        where = append(where, `v."Уровень_сложности" = `+ph[0])
-        args = append(args, qLevel)
-    }
-    if qFrom != "" {
-        ph := nextPH(1)
-        where = append(where, `v."Время_начала" >= `+ph[0]+`::timestamp`)
-        args = append(args, qFrom)
-    }
-    if qTo != "" {
-        ph := nextPH(1)
-        where = append(where, `v."Время_начала" <= `+ph[0]+`::timestamp`)
-        args = append(args, qTo)
-    }
-    if onlyUpcoming {
-        ph := nextPH(1)
-        where = append(where, `v."Время_начала" >= `+ph[0]+`::timestamp`)
-        args = append(args, time.Now())
-    }
-    if recent30 {
-        where = append(where, `v."Время_начала" >= NOW() - INTERVAL '30 days'`)
-    }
-    if len(where) > 0 {
-        query += " WHERE " + strings.Join(where, " AND ")
-    }
-    query += ` ORDER BY v."Время_начала" DESC, v."id_групповой_тренировки" DESC`
+    f, _, err := trainingFilter(c)
+    if err != nil {
+        return jsonError(c, 400, "Некорректный фильтр", err)
+    }
 
     ctx, cancel := withDBTimeout()
     defer cancel()
-    rows, err := db.QueryContext(ctx, query, args...)
+    gl, err := data.Trainings().ListGroups(ctx, f)
     if err != nil { return jsonError(c, 500, "Ошибка загрузки групповых тренировок", err) }
-    defer rows.Close()
 
     type dto struct {
         ID          int       `json:"id"`
@@ -390,104 +173,26 @@ func APIv1ListGroupTrainings(c *fiber.Ctx) error {
         ZoneName    string    `json:"zone_name"`
         ZoneID      int       `json:"zone_id"`
         FreeSlots   int       `json:"free_slots"`
+        SeriesID    int       `json:"series_id,omitempty"`
     }
     var list []dto
-    for rows.Next() {
-        var (
-            id, max, trainerID, zoneID, free int
-            title, desc, level, tname, zname string
-            start, end time.Time
-        )
-        if err := rows.Scan(&id, &title, &desc, &max, &start, &end, &level, &tname, &trainerID, &zname, &zoneID, &free); err != nil {
-            return jsonError(c, 500, "Ошибка чтения строки", err)
-        }
-        list = append(list, dto{ID: id, Title: title, Description: desc, Max: max, Start: start, End: end, Level: level, TrainerName: tname, TrainerID: trainerID, ZoneName: zname, ZoneID: zoneID, FreeSlots: free})
+    for _, g := range gl {
+        list = append(list, dto{ID: g.ID, Title: g.Name, Description: g.Description, Max: g.MaxParticipants, Start: g.StartTime, End: g.EndTime, Level: g.DifficultyLevel, TrainerName: g.TrainerName, TrainerID: g.TrainerID, ZoneName: g.ZoneName, ZoneID: g.ZoneID, FreeSlots: g.FreeSlots, SeriesID: g.SeriesID})
     }
-    if err := rows.Err(); err != nil { return jsonError(c, 500, "Ошибка курсора", err) }
     return jsonOK(c, fiber.Map{"groups": list})
 }
 
 // APIv1ListPersonalTrainings — JSON-список персональных тренировок с фильтрами
 func APIv1ListPersonalTrainings(c *fiber.Ctx) error {
-    db := database.GetDB()
-    q := strings.TrimSpace(c.Query("q"))
-    qTrainer := strings.TrimSpace(c.Query("trainer_id"))
-    qStatus := strings.TrimSpace(c.Query("status"))
-    qFrom := strings.TrimSpace(c.Query("from"))
-    qTo := strings.TrimSpace(c.Query("to"))
-    onlyUpcoming := c.Query("upcoming") == "1"
-    recent30 := c.Query("recent") == "1"
-
-    where := []string{}
-    args := []any{}
-    nextPH := func(n int) []string {
-        start := len(args) + 1
-        ph := make([]string, n)
-        for i := 0; i < n; i++ { ph[i] = "$" + strconv.Itoa(start+i) }
-        return ph
-    }
-
-    query := `
-        SELECT 
-            v."id_персональной_тренировки",
-            v."Время_начала",
-            v."Время_окончания",
-            v."Статус",
-            COALESCE(v."Стоимость",0)     AS price,
-            v."id_абонемента",
-            v."id_клиента",
-            v.client_fio,
-            v."id_тренера",
-            v.trainer_fio
-        FROM vw_personal_training_enriched v`
-
-    if q != "" {
-        like := "%" + q + "%"
-        ph := nextPH(2)
-        where = append(where, `(
-            v.client_fio  ILIKE `+ph[0]+` OR
-            v.trainer_fio ILIKE `+ph[1]+`
-        )`)
-        args = append(args, like, like)
-    }
-    if qTrainer != "" {
-        ph := nextPH(1)
-        where = append(where, `v."id_тренера" = `+ph[0]+`::int`)
-        args = append(args, qTrainer)
-    }
-    if qStatus != "" {
-        ph := nextPH(1)
-        where = append(where, `v."Статус" = `+ph[0])
-        args = append(args, qStatus)
-    }
-    if qFrom != "" {
-        ph := nextPH(1)
-        where = append(where, `v."Время_начала" >= `+ph[0]+`::timestamp`)
-        args = append(args, qFrom)
-    }
-    if qTo != "" {
-        ph := nextPH(1)
-        where = append(where, `v."Время_начала" <= `+ph[0]+`::timestamp`)
-        args = append(args, qTo)
-    }
-    if onlyUpcoming {
-        ph := nextPH(1)
-        where = append(where, `v."Время_начала" >= `+ph[0]+`::timestamp`)
-        args = append(args, time.Now())
-    }
-    if recent30 {
-        where = append(where, `v."Время_начала" >= NOW() - INTERVAL '30 days'`)
-    }
-    if len(where) > 0 {
-        query += " WHERE " + strings.Join(where, " AND ")
-    }
-    query += ` ORDER BY v."Время_начала" DESC, v."id_персональной_тренировки" DESC`
+    f, _, err := trainingFilter(c)
+    if err != nil {
+        return jsonError(c, 400, "Некорректный фильтр", err)
+    }
 
     ctx, cancel := withDBTimeout()
     defer cancel()
-    rows, err := db.QueryContext(ctx, query, args...)
+    pl, err := data.Trainings().ListPersonal(ctx, f)
     if err != nil { return jsonError(c, 500, "Ошибка загрузки персональных тренировок", err) }
-    defer rows.Close()
 
     type dto struct {
         ID            int       `json:"id"`
@@ -502,20 +207,9 @@ func APIv1ListPersonalTrainings(c *fiber.Ctx) error {
         TrainerFIO    string    `json:"trainer_fio"`
     }
     var list []dto
-    for rows.Next() {
-        var (
-            id, subID, clientID, trainerID int
-            start, end time.Time
-            status string
-            price float64
-            clientFIO, trainerFIO string
-        )
-        if err := rows.Scan(&id, &start, &end, &status, &price, &subID, &clientID, &clientFIO, &trainerID, &trainerFIO); err != nil {
-            return jsonError(c, 500, "Ошибка чтения строки", err)
-        }
-        list = append(list, dto{ID: id, Start: start, End: end, Status: status, Price: price, SubscriptionID: subID, ClientID: clientID, ClientFIO: clientFIO, TrainerID: trainerID, TrainerFIO: trainerFIO})
+    for _, p := range pl {
+        list = append(list, dto{ID: p.ID, Start: p.StartTime, End: p.EndTime, Status: p.Status, Price: p.Price, SubscriptionID: p.SubscriptionID, ClientID: p.ClientID, ClientFIO: p.ClientName, TrainerID: p.TrainerID, TrainerFIO: p.TrainerName})
     }
-    if err := rows.Err(); err != nil { return jsonError(c, 500, "Ошибка курсора", err) }
     return jsonOK(c, fiber.Map{"personal": list})
 }
 
@@ -527,37 +221,27 @@ func GetGroupTrainingByID(c *fiber.Ctx) error {
     if id <= 0 {
         return jsonError(c, 400, "Некорректный id", nil)
     }
-	db := database.GetDB()
-	var (
-		title, desc, level string
-		max, trainerID, zoneID int
-		start, end time.Time
-	)
     ctx, cancel := withDBTimeout()
     defer cancel()
-    err := db.QueryRowContext(ctx, `
-        SELECT "Название", COALESCE("Описание",''), COALESCE("Уровень_сложности",''), 
-               COALESCE("Максимум_участников",0),
-               "Время_начала","Время_окончания",
-               "id_тренера","id_зоны"
-        FROM "Групповая_тренировка" WHERE "id_групповой_тренировки"=$1
-    `, id).Scan(&title, &desc, &level, &max, &start, &end, &trainerID, &zoneID)
-    if err == sql.ErrNoRows {
+    g, err := data.Trainings().GetGroup(ctx, id)
+    if errors.Is(err, store.ErrNotFound) {
         return jsonError(c, 404, "Не найдено", nil)
     }
     if err != nil {
         return jsonError(c, 500, "Ошибка БД", err)
     }
     return jsonOK(c, fiber.Map{"item": fiber.Map{
-        "ID": id, "Title": title, "Description": desc, "Level": level, "Max": max,
-        "Date": start.Format("2006-01-02"),
-        "StartTime": start.Format("15:04"),
-        "EndTime":   end.Format("15:04"),
-        "TrainerID": trainerID, "ZoneID": zoneID,
+        "ID": id, "Title": g.Name, "Description": g.Description, "Level": g.DifficultyLevel, "Max": g.MaxParticipants,
+        "Date": g.StartTime.Format("2006-01-02"),
+        "StartTime": g.StartTime.Format("15:04"),
+        "EndTime":   g.EndTime.Format("15:04"),
+        "TrainerID": g.TrainerID, "ZoneID": g.ZoneID,
+        "SeriesID": g.SeriesID, "Modified": g.Modified,
     }})
 }
 
-func CreateGroupTraining(c *fiber.Ctx) error {
+// parseGroupForm разбирает и проверяет форму групповой; при ошибке ответ уже отправлен.
+func parseGroupForm(c *fiber.Ctx) (store.GroupTrainingInput, bool, error) {
 	type fT struct {
 		Title   string `form:"title"`
 		Desc    string `form:"description"`
@@ -571,31 +255,58 @@ func CreateGroupTraining(c *fiber.Ctx) error {
 	}
 	var f fT
     if err := c.BodyParser(&f); err != nil {
-        return jsonError(c, 400, "Неверные данные формы", err)
+        return store.GroupTrainingInput{}, false, jsonError(c, 400, "Неверные данные формы", err)
     }
     if f.Title == "" || f.Date == "" || f.Start == "" || f.End == "" || f.Trainer <= 0 || f.Zone <= 0 || f.Max <= 0 {
-        return jsonError(c, 400, "Заполните обязательные поля", nil)
+        return store.GroupTrainingInput{}, false, jsonError(c, 400, "Заполните обязательные поля", nil)
     }
 	switch f.Level {
 	case "", "Начальный", "Средний", "Продвинутый":
 	default:
-        return jsonError(c, 400, "Неверный уровень сложности", nil)
+        return store.GroupTrainingInput{}, false, jsonError(c, 400, "Неверный уровень сложности", nil)
 	}
 	start, err1 := time.Parse("2006-01-02 15:04", f.Date+" "+f.Start)
 	end,   err2 := time.Parse("2006-01-02 15:04", f.Date+" "+f.End)
     if err1 != nil || err2 != nil || !end.After(start) {
-        return jsonError(c, 400, "Некорректное время начала/окончания", nil)
+        return store.GroupTrainingInput{}, false, jsonError(c, 400, "Некорректное время начала/окончания", nil)
+    }
+    force, ok, err := forceOverlap(c)
+    if !ok {
+        return store.GroupTrainingInput{}, false, err
+    }
+    return store.GroupTrainingInput{
+        TrainerID:   f.Trainer,
+        ZoneID:      f.Zone,
+        Title:       f.Title,
+        Description: f.Desc,
+        Max:         f.Max,
+        Level:       f.Level,
+        Start:       start,
+        End:         end,
+        AllowOverlap: force,
+    }, true, nil
+}
+
+func CreateGroupTraining(c *fiber.Ctx) error {
+    in, ok, err := parseGroupForm(c)
+    if !ok {
+        return err
     }
-	db := database.GetDB()
 	var id int
     ctx, cancel := withDBTimeout()
     defer cancel()
-    err := db.QueryRowContext(ctx, `
-        INSERT INTO "Групповая_тренировка"
-        ("id_тренера","id_зоны","Название","Описание","Максимум_участников","Время_начала","Время_окончания","Уровень_сложности")
-        VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
-        RETURNING "id_групповой_тренировки"
-    `, f.Trainer, f.Zone, f.Title, nullIfEmpty(f.Desc), f.Max, start, end, nullIfEmpty(f.Level)).Scan(&id)
+    err = inTx(ctx, c, func(tx store.Store) error {
+        // тренер и зона не должны быть заняты в это время
+        if err := checkSchedule(ctx, tx, groupConflictQuery(in, 0), in.AllowOverlap); err != nil {
+            return err
+        }
+        var err error
+        id, err = tx.Trainings().CreateGroup(ctx, in)
+        return err
+    })
+    if handled, err := conflictReject(c, err); handled {
+        return err
+    }
     if err != nil {
         log.Printf("create group err: %v", err)
         return jsonError(c, 500, "Ошибка сохранения", err)
@@ -608,49 +319,58 @@ func UpdateGroupTraining(c *fiber.Ctx) error {
     if id <= 0 {
         return jsonError(c, 400, "Некорректный id", nil)
     }
-	type fT struct {
-		Title   string `form:"title"`
-		Desc    string `form:"description"`
-		Max     int    `form:"max"`
-		Level   string `form:"level"`
-		Date    string `form:"date"`
-		Start   string `form:"start_time"`
-		End     string `form:"end_time"`
-		Trainer int    `form:"trainer_id"`
-		Zone    int    `form:"zone_id"`
-	}
-	var f fT
-    if err := c.BodyParser(&f); err != nil {
-        return jsonError(c, 400, "Неверные данные формы", err)
+    in, ok, err := parseGroupForm(c)
+    if !ok {
+        return err
     }
-	if f.Title == "" || f.Date == "" || f.Start == "" || f.End == "" || f.Trainer <= 0 || f.Zone <= 0 || f.Max <= 0 {
-        return jsonError(c, 400, "Заполните обязательные поля", nil)
-	}
-	switch f.Level {
-	case "", "Начальный", "Средний", "Продвинутый":
-	default:
-        return jsonError(c, 400, "Неверный уровень сложности", nil)
-	}
-	start, err1 := time.Parse("2006-01-02 15:04", f.Date+" "+f.Start)
-	end,   err2 := time.Parse("2006-01-02 15:04", f.Date+" "+f.End)
-    if err1 != nil || err2 != nil || !end.After(start) {
-        return jsonError(c, 400, "Некорректное время начала/окончания", nil)
+    // занятие серии: scope=following|all меняет и другие занятия
+    scope, err := seriesScope(c)
+    if err != nil {
+        return jsonError(c, 400, "Неверный scope (this|following|all)", err)
+    }
+    if scope != scopeThis {
+        return updateOccurrenceScope(c, id, in, scope)
     }
 
-	db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    res, err := db.ExecContext(ctx, `
-        UPDATE "Групповая_тренировка"
-        SET "id_тренера"=$2,"id_зоны"=$3,"Название"=$4,"Описание"=$5,"Максимум_участников"=$6,
-            "Время_начала"=$7,"Время_окончания"=$8,"Уровень_сложности"=$9
-        WHERE "id_групповой_тренировки"=$1
-    `, id, f.Trainer, f.Zone, f.Title, nullIfEmpty(f.Desc), f.Max, start, end, nullIfEmpty(f.Level))
+    var promoted []models.GroupTrainingRegistration
+    err = inTx(ctx, c, func(tx store.Store) error {
+        g, err := tx.Trainings().GetGroup(ctx, id)
+        if err != nil {
+            return err
+        }
+        if err := checkSchedule(ctx, tx, groupConflictQuery(in, id), in.AllowOverlap); err != nil {
+            return err
+        }
+        if err := tx.Trainings().UpdateGroup(ctx, id, in); err != nil {
+            return err
+        }
+        // правленое отдельно занятие правки всей серии больше не трогают
+        if g.SeriesID > 0 {
+            if err := tx.Series().SetModified(ctx, id, true); err != nil {
+                return err
+            }
+        }
+        // вместимость могли увеличить — свободные места достаются очереди
+        occ, err := tx.Trainings().LockOccupancy(ctx, id)
+        if err != nil {
+            return err
+        }
+        promoted, err = promoteWaitlist(ctx, tx, id, occ)
+        return err
+    })
+    if handled, err := conflictReject(c, err); handled {
+        return err
+    }
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 404, "Не найдено", nil)
+    }
     if err != nil {
         return jsonError(c, 500, "Ошибка обновления", err)
     }
-    if n, _ := res.RowsAffected(); n == 0 {
-        return jsonError(c, 404, "Не найдено", nil)
+    if len(promoted) > 0 {
+        return jsonOK(c, fiber.Map{"message": fmt.Sprintf("Обновлено; из листа ожидания записано: %d", len(promoted))})
     }
     return jsonOK(c, fiber.Map{"message": "Обновлено"})
 }
@@ -660,17 +380,33 @@ func DeleteGroupTraining(c *fiber.Ctx) error {
     if id <= 0 {
         return jsonError(c, 400, "Некорректный id", nil)
     }
-	db := database.GetDB()
+    scope, err := seriesScope(c)
+    if err != nil {
+        return jsonError(c, 400, "Неверный scope (this|following|all)", err)
+    }
+    if scope != scopeThis {
+        return deleteOccurrenceScope(c, id, scope)
+    }
+    // записи в "Запись_на_групповую_тренировку" удаляются каскадом
     ctx, cancel := withDBTimeout()
     defer cancel()
-    res, err := db.ExecContext(ctx, `DELETE FROM "Групповая_тренировка" WHERE "id_групповой_тренировки"=$1`, id)
+    err = inTx(ctx, c, func(tx store.Store) error {
+        g, err := tx.Trainings().GetGroup(ctx, id)
+        if err != nil {
+            return err
+        }
+        // занятие серии: дата уходит в исключения, записанным — уведомление
+        if g.SeriesID > 0 {
+            return deleteOccurrence(ctx, tx, g, c.Query("reason", c.FormValue("reason")))
+        }
+        return tx.Trainings().DeleteGroup(ctx, id)
+    })
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 404, "Не найдено", nil)
+    }
     if err != nil {
-        // связанные записи в "Запись_на_групповую_тренировку" могут мешать, но там ON DELETE CASCADE — должно удалиться
         return jsonError(c, 500, "Ошибка удаления", err)
     }
-    if n, _ := res.RowsAffected(); n == 0 {
-        return jsonError(c, 404, "Не найдено", nil)
-    }
     return jsonOK(c, fiber.Map{"message": "Удалено"})
 }
 
@@ -681,18 +417,10 @@ func GetPersonalTrainingByID(c *fiber.Ctx) error {
     if id <= 0 {
         return jsonError(c, 400, "Некорректный id", nil)
     }
-	db := database.GetDB()
-	var (
-		start, end time.Time
-		status string
-		price sql.NullFloat64
-		subID, trainerID int
-	)
-	err := db.QueryRow(`
-		SELECT "Время_начала","Время_окончания","Статус",COALESCE("Стоимость",0),"id_абонемента","id_тренера"
-		FROM "Персональная_тренировка" WHERE "id_персональной_тренировки"=$1
-	`, id).Scan(&start, &end, &status, &price, &subID, &trainerID)
-    if err == sql.ErrNoRows {
+    ctx, cancel := withDBTimeout()
+    defer cancel()
+    p, err := data.Trainings().GetPersonal(ctx, id)
+    if errors.Is(err, store.ErrNotFound) {
         return jsonError(c, 404, "Не найдено", nil)
     }
     if err != nil {
@@ -700,17 +428,20 @@ func GetPersonalTrainingByID(c *fiber.Ctx) error {
     }
 	return c.JSON(fiber.Map{"success": true, "item": fiber.Map{
 		"ID": id,
-		"Date": start.Format("2006-01-02"),
-		"StartTime": start.Format("15:04"),
-		"EndTime": end.Format("15:04"),
-		"Status": status,
-		"Price": fmt.Sprintf("%.2f", price.Float64),
-		"SubscriptionID": subID,
-		"TrainerID": trainerID,
+		"Date": p.StartTime.Format("2006-01-02"),
+		"StartTime": p.StartTime.Format("15:04"),
+		"EndTime": p.EndTime.Format("15:04"),
+		"Status": p.Status,
+		"Price": fmt.Sprintf("%.2f", p.Price),
+		"SubscriptionID": p.SubscriptionID,
+		"TrainerID": p.TrainerID,
+		"Rate": p.Rate, // nil — стоимость введена вручную
 	}})
 }
 
-func CreatePersonalTraining(c *fiber.Ctx) error {
+// parsePersonalForm разбирает и проверяет форму персональной; при ошибке ответ уже отправлен.
+// Пустой статус допустим только при создании (requireStatus == false).
+func parsePersonalForm(c *fiber.Ctx, requireStatus bool) (store.PersonalTrainingInput, bool, error) {
 	type fT struct {
 		Subscription int    `form:"subscription_id"`
 		Trainer      int    `form:"trainer_id"`
@@ -722,44 +453,113 @@ func CreatePersonalTraining(c *fiber.Ctx) error {
 	}
 	var f fT
     if err := c.BodyParser(&f); err != nil {
-        return jsonError(c, 400, "Неверные данные формы", err)
+        return store.PersonalTrainingInput{}, false, jsonError(c, 400, "Неверные данные формы", err)
     }
     if f.Subscription <= 0 || f.Trainer <= 0 || f.Date == "" || f.Start == "" || f.End == "" {
-        return jsonError(c, 400, "Заполните обязательные поля", nil)
+        return store.PersonalTrainingInput{}, false, jsonError(c, 400, "Заполните обязательные поля", nil)
     }
 	switch f.Status {
-	case "", "Запланирована", "Завершена", "Отменена":
+	case "Запланирована", "Завершена", "Отменена":
+	case "":
+		if requireStatus {
+			return store.PersonalTrainingInput{}, false, jsonError(c, 400, "Неверный статус", nil)
+		}
+		f.Status = "Запланирована"
     default:
-        return jsonError(c, 400, "Неверный статус", nil)
+        return store.PersonalTrainingInput{}, false, jsonError(c, 400, "Неверный статус", nil)
 	}
 	start, err1 := time.Parse("2006-01-02 15:04", f.Date+" "+f.Start)
 	end,   err2 := time.Parse("2006-01-02 15:04", f.Date+" "+f.End)
     if err1 != nil || err2 != nil || !end.After(start) {
-        return jsonError(c, 400, "Некорректное время начала/окончания", nil)
+        return store.PersonalTrainingInput{}, false, jsonError(c, 400, "Некорректное время начала/окончания", nil)
     }
 	var price *float64
 	if f.Price != "" {
 		if p, err := strconv.ParseFloat(f.Price, 64); err == nil {
 			price = &p
 		} else {
-            return jsonError(c, 400, "Неверная стоимость", err)
+            return store.PersonalTrainingInput{}, false, jsonError(c, 400, "Неверная стоимость", err)
         }
     }
-    db := database.GetDB()
+    force, ok, err := forceOverlap(c)
+    if !ok {
+        return store.PersonalTrainingInput{}, false, err
+    }
+    // пока это запрошенное исключение; в запись оно попадёт, только если
+    // правила абонемента действительно нарушены (см. entitle)
+    override, ok, err := overrideRequest(c)
+    if !ok {
+        return store.PersonalTrainingInput{}, false, err
+    }
+    return store.PersonalTrainingInput{
+        SubscriptionID: f.Subscription,
+        TrainerID:      f.Trainer,
+        Start:          start,
+        End:            end,
+        Status:         f.Status,
+        Price:          price,
+        AllowOverlap:   force,
+        OverrideReason: override,
+    }, true, nil
+}
+
+// entitlePersonal — проверка абонемента персональной тренировки in;
+// in.OverrideReason заменяется тем, что нужно сохранить. sub — для ответа.
+func entitlePersonal(ctx context.Context, tx store.Store, in *store.PersonalTrainingInput, sub *models.Subscription) error {
+    var err error
+    if *sub, err = tx.Subscriptions().Get(ctx, in.SubscriptionID); err != nil {
+        return err
+    }
+    in.OverrideReason, err = entitle(ctx, tx, *sub, entitlement.Personal, in.Start, in.End, in.OverrideReason)
+    return err
+}
+
+func CreatePersonalTraining(c *fiber.Ctx) error {
+    in, ok, err := parsePersonalForm(c, false)
+    if !ok {
+        return err
+    }
     var id int
+    var sub models.Subscription
     ctx, cancel := withDBTimeout()
     defer cancel()
-    err := db.QueryRowContext(ctx, `
-        INSERT INTO "Персональная_тренировка"
-        ("id_абонемента","id_тренера","Время_начала","Время_окончания","Статус","Стоимость")
-        VALUES ($1,$2,$3,$4,$5,$6)
-        RETURNING "id_персональной_тренировки"
-    `, f.Subscription, f.Trainer, start, end, coalesceStr(f.Status, "Запланирована"), nullablePrice(price)).Scan(&id)
+    err = inTx(ctx, c, func(tx store.Store) error {
+        if in.Status != "Отменена" {
+            if err := entitlePersonal(ctx, tx, &in, &sub); err != nil {
+                return err
+            }
+        }
+        if err := checkSchedule(ctx, tx, personalConflictQuery(in, 0), in.AllowOverlap); err != nil {
+            return err
+        }
+        if err := pricePersonal(ctx, tx, &in); err != nil {
+            return err
+        }
+        var err error
+        id, err = tx.Trainings().CreatePersonal(ctx, in)
+        return err
+    })
+    if handled, err := entitlementReject(c, err, sub); handled {
+        return err
+    }
+    if handled, err := conflictReject(c, err); handled {
+        return err
+    }
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 400, "Абонемент не найден", nil)
+    }
     if err != nil {
         log.Printf("create personal err: %v", err)
         return jsonError(c, 500, "Ошибка сохранения", err)
     }
-    return jsonOK(c, fiber.Map{"id": id, "message": "Персональная тренировка создана"})
+    message := "Персональная тренировка создана"
+    if in.OverrideReason != "" {
+        message += " как исключение: " + in.OverrideReason
+    }
+    if in.Rate != nil {
+        message += fmt.Sprintf("; стоимость по ставке «%s»: %.2f", in.Rate.Category, in.Rate.Price)
+    }
+    return jsonOK(c, fiber.Map{"id": id, "override_reason": in.OverrideReason, "rate": in.Rate, "message": message})
 }
 
 func UpdatePersonalTraining(c *fiber.Ctx) error {
@@ -767,54 +567,53 @@ func UpdatePersonalTraining(c *fiber.Ctx) error {
     if id <= 0 {
         return jsonError(c, 400, "Некорректный id", nil)
     }
-	type fT struct {
-		Subscription int    `form:"subscription_id"`
-		Trainer      int    `form:"trainer_id"`
-		Date         string `form:"date"`
-		Start        string `form:"start_time"`
-		End          string `form:"end_time"`
-		Status       string `form:"status"`
-		Price        string `form:"price"`
-	}
-	var f fT
-    if err := c.BodyParser(&f); err != nil {
-        return jsonError(c, 400, "Неверные данные формы", err)
+    in, ok, err := parsePersonalForm(c, true)
+    if !ok {
+        return err
     }
-    if f.Subscription <= 0 || f.Trainer <= 0 || f.Date == "" || f.Start == "" || f.End == "" {
-        return jsonError(c, 400, "Заполните обязательные поля", nil)
+    ctx, cancel := withDBTimeout()
+    defer cancel()
+    var sub models.Subscription
+    var noSub bool
+    err = inTx(ctx, c, func(tx store.Store) error {
+        old, err := tx.Trainings().GetPersonal(ctx, id)
+        if err != nil {
+            return err
+        }
+        // абонемент проверяется заново, только если тренировку перенесли,
+        // сменили абонемент или вернули из отмены: отметить прошедшую
+        // тренировку завершённой можно и по закончившемуся абонементу
+        moved := in.SubscriptionID != old.SubscriptionID || !in.Start.Equal(old.StartTime) ||
+            !in.End.Equal(old.EndTime) || old.Status == "Отменена"
+        if in.Status == "Отменена" || !moved {
+            in.OverrideReason = old.OverrideReason
+        } else if err := entitlePersonal(ctx, tx, &in, &sub); err != nil {
+            noSub = errors.Is(err, store.ErrNotFound)
+            return err
+        }
+        if err := checkSchedule(ctx, tx, personalConflictQuery(in, id), in.AllowOverlap); err != nil {
+            return err
+        }
+        if err := repricePersonal(ctx, tx, &in, old); err != nil {
+            return err
+        }
+        return tx.Trainings().UpdatePersonal(ctx, id, in)
+    })
+    if handled, err := entitlementReject(c, err, sub); handled {
+        return err
     }
-	switch f.Status {
-	case "Запланирована", "Завершена", "Отменена":
-    default:
-        return jsonError(c, 400, "Неверный статус", nil)
-	}
-	start, err1 := time.Parse("2006-01-02 15:04", f.Date+" "+f.Start)
-	end,   err2 := time.Parse("2006-01-02 15:04", f.Date+" "+f.End)
-    if err1 != nil || err2 != nil || !end.After(start) {
-        return jsonError(c, 400, "Некорректное время начала/окончания", nil)
+    if handled, err := conflictReject(c, err); handled {
+        return err
     }
-	var price *float64
-	if f.Price != "" {
-		if p, err := strconv.ParseFloat(f.Price, 64); err == nil {
-			price = &p
-		} else {
-            return jsonError(c, 400, "Неверная стоимость", err)
-        }
+    if noSub {
+        return jsonError(c, 400, "Абонемент не найден", nil)
+    }
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 404, "Не найдено", nil)
     }
-    db := database.GetDB()
-    ctx, cancel := withDBTimeout()
-    defer cancel()
-    res, err := db.ExecContext(ctx, `
-        UPDATE "Персональная_тренировка"
-        SET "id_абонемента"=$2,"id_тренера"=$3,"Время_начала"=$4,"Время_окончания"=$5,"Статус"=$6,"Стоимость"=$7
-        WHERE "id_персональной_тренировки"=$1
-    `, id, f.Subscription, f.Trainer, start, end, f.Status, nullablePrice(price))
     if err != nil {
         return jsonError(c, 500, "Ошибка обновления", err)
     }
-    if n, _ := res.RowsAffected(); n == 0 {
-        return jsonError(c, 404, "Не найдено", nil)
-    }
     return jsonOK(c, fiber.Map{"message": "Обновлено"})
 }
 
@@ -823,16 +622,20 @@ func DeletePersonalTraining(c *fiber.Ctx) error {
     if id <= 0 {
         return jsonError(c, 400, "Некорректный id", nil)
     }
-    db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    res, err := db.ExecContext(ctx, `DELETE FROM "Персональная_тренировка" WHERE "id_персональной_тренировки"=$1`, id)
+    err := inTx(ctx, c, func(tx store.Store) error {
+        return tx.Trainings().DeletePersonal(ctx, id)
+    })
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 404, "Не найдено", nil)
+    }
+    if errors.Is(err, store.ErrInUse) {
+        return jsonError(c, 409, "По тренировке есть платежи — удалить нельзя, отмените её", nil)
+    }
     if err != nil {
         return jsonError(c, 500, "Ошибка удаления", err)
     }
-    if n, _ := res.RowsAffected(); n == 0 {
-        return jsonError(c, 404, "Не найдено", nil)
-    }
     return jsonOK(c, fiber.Map{"message": "Удалено"})
 }
 
@@ -852,36 +655,92 @@ func CreateGroupEnrollment(c *fiber.Ctx) error {
         return jsonError(c, 400, "Выберите тренировку и абонемент", nil)
     }
 	switch f.Status {
-	case "", "Записан", "Посетил", "Отменил":
+	case "", enrollment.StatusBooked, enrollment.StatusAttended, enrollment.StatusCancelled:
     default:
         return jsonError(c, 400, "Неверный статус записи", nil)
 	}
 
-    db := database.GetDB()
-    // проверим, что групповая существует
-    var exists int
-    ctx, cancel := withDBTimeout()
-    defer cancel()
-    if err := db.QueryRowContext(ctx, `SELECT 1 FROM "Групповая_тренировка" WHERE "id_групповой_тренировки"=$1`, f.GroupID).Scan(&exists); err != nil {
-        return jsonError(c, 400, "Групповая тренировка не найдена", err)
-    }
-    // и абонемент существует
-    if err := db.QueryRowContext(ctx, `SELECT 1 FROM "Абонемент" WHERE "id_абонемента"=$1`, f.SubID).Scan(&exists); err != nil {
-        return jsonError(c, 400, "Абонемент не найден", err)
+    override, ok, err := overrideRequest(c)
+    if !ok {
+        return err
     }
 
+    ctx, cancel := withDBTimeout()
+    defer cancel()
     var id int
-    err := db.QueryRowContext(ctx, `
-        INSERT INTO "Запись_на_групповую_тренировку"
-        ("id_групповой_тренировки","id_абонемента","Статус")
-        VALUES ($1,$2,$3)
-        RETURNING "id_записи"
-    `, f.GroupID, f.SubID, coalesceStr(f.Status, "Записан")).Scan(&id)
-    if err != nil {
+    var missing string
+    var full *enrollment.Occupancy
+    var sub models.Subscription
+    var occ enrollment.Occupancy
+    var rule *enrollment.Error
+    var reason string // основание исключения, если запись вопреки правилам
+    status := coalesceStr(f.Status, enrollment.StatusBooked)
+    err = inTx(ctx, c, func(tx store.Store) error {
+        // проверим, что групповая и абонемент существуют
+        g, err := tx.Trainings().GetGroup(ctx, f.GroupID)
+        if err != nil {
+            missing = "Групповая тренировка не найдена"
+            return err
+        }
+        sub, err = tx.Subscriptions().Get(ctx, f.SubID)
+        if err != nil {
+            missing = "Абонемент не найден"
+            return err
+        }
+        // тариф, статус и срок абонемента, часы доступа, заморозки
+        if status != enrollment.StatusCancelled {
+            if reason, err = entitle(ctx, tx, sub, entitlement.Group, g.StartTime, g.EndTime, override); err != nil {
+                return err
+            }
+        }
+        // посещение отмечают только после начала тренировки
+        if status == enrollment.StatusAttended {
+            if err := enrollment.CheckAttendance(g.StartTime, clubWallNow()); err != nil {
+                return err
+            }
+        }
+        // места считаем под блокировкой строки тренировки: параллельные записи
+        // ждут друг друга и не переполняют группу; сверх лимита — в очередь
+        if occ, err = tx.Trainings().LockOccupancy(ctx, f.GroupID); err != nil {
+            return err
+        }
+        switch {
+        case status == enrollment.StatusBooked:
+            status = enrollment.Place(occ)
+        case enrollment.HoldsSeat(status) && occ.Free() == 0:
+            full = &occ
+            return nil
+        }
+        id, err = tx.Trainings().Enroll(ctx, f.GroupID, f.SubID, status, reason)
+        return err
+    })
+    if handled, err := entitlementReject(c, err, sub); handled {
+        return err
+    }
+    switch {
+    case missing != "" && errors.Is(err, store.ErrNotFound):
+        return jsonError(c, 400, missing, err)
+    case full != nil:
+        return jsonReject(c, fiber.StatusConflict, string(enrollment.Full),
+            fmt.Sprintf("Мест нет: занято %d из %d", full.Taken, full.Max), nil)
+    case errors.As(err, &rule):
+        return jsonReject(c, fiber.StatusConflict, string(rule.Reason), rule.Msg, nil)
+    case errors.Is(err, store.ErrDuplicate):
+        return jsonError(c, 409, "Абонемент уже записан на эту тренировку", err)
+    case err != nil:
         log.Printf("enrollment err: %v", err)
-        return jsonError(c, 500, "Не удалось создать запись (возможно, дубликат)", err)
+        return jsonError(c, 500, "Не удалось создать запись", err)
+    }
+    if status == enrollment.StatusWaiting {
+        position := occ.Waiting + 1
+        return jsonOK(c, fiber.Map{"id": id, "status": status, "queue_position": position,
+            "message": fmt.Sprintf("Мест нет — клиент в листе ожидания, место в очереди: %d", position)})
+    }
+    message := "Запись создана"
+    if reason != "" {
+        message += " как исключение: " + reason
     }
-    return jsonOK(c, fiber.Map{"id": id, "message": "Запись создана"})
+    return jsonOK(c, fiber.Map{"id": id, "status": status, "override_reason": reason, "message": message})
 }
 
 func ListGroupEnrollments(c *fiber.Ctx) error {
@@ -890,26 +749,12 @@ func ListGroupEnrollments(c *fiber.Ctx) error {
         return jsonError(c, 400, "Некорректный id тренировки", nil)
     }
 
-	db := database.GetDB()
     ctx, cancel := withDBTimeout()
     defer cancel()
-    rows, err := db.QueryContext(ctx, `
-        SELECT 
-            e."id_записи",
-            e."Статус",
-            s."id_абонемента",
-            c."id_клиента",
-            c."ФИО"
-        FROM "Запись_на_групповую_тренировку" e
-        JOIN "Абонемент" s ON s."id_абонемента" = e."id_абонемента"
-        JOIN "Клиент"    c ON c."id_клиента"    = s."id_клиента"
-        WHERE e."id_групповой_тренировки" = $1
-        ORDER BY e."id_записи" DESC
-    `, id)
+    enrollments, err := data.Trainings().ListEnrollments(ctx, id)
     if err != nil {
         return jsonError(c, 500, "Ошибка загрузки записей", err)
     }
-	defer rows.Close()
 
 	type item struct {
 		ID            int    `json:"id"`
@@ -917,13 +762,14 @@ func ListGroupEnrollments(c *fiber.Ctx) error {
 		Subscription  int    `json:"subscription_id"`
 		ClientID      int    `json:"client_id"`
 		ClientFIO     string `json:"client_fio"`
+		QueuePosition int    `json:"queue_position,omitempty"`
+		EnrolledAt    string `json:"enrolled_at"`
+		Override      string `json:"override_reason,omitempty"` // записан вопреки правилам абонемента
 	}
 	var list []item
-	for rows.Next() {
-		var it item
-		if err := rows.Scan(&it.ID, &it.Status, &it.Subscription, &it.ClientID, &it.ClientFIO); err == nil {
-			list = append(list, it)
-		}
+	for _, e := range enrollments {
+		list = append(list, item{e.ID, e.Status, e.SubscriptionID, e.ClientID, e.ClientName,
+			e.QueuePosition, e.EnrolledAt.Format(visitTimeFormat), e.OverrideReason})
 	}
 
     return jsonOK(c, fiber.Map{"enrollments": list})
@@ -931,22 +777,9 @@ func ListGroupEnrollments(c *fiber.Ctx) error {
 
 // ====== helpers ======
 
-func nullIfEmpty(s string) any {
-	if s == "" {
-		return nil
-	}
-	return s
-}
 func coalesceStr(s, def string) string {
 	if s == "" {
 		return def
 	}
 	return s
 }
-func nullablePrice(p *float64) any {
-	if p == nil {
-		return nil
-	}
-	return *p
-}
-
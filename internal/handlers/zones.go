@@ -1,8 +1,6 @@
 package handlers
 
 import (
-	"crypto/sha256"
-	"database/sql"
 	"errors"
 	"fmt"
 	"html/template"
@@ -11,12 +9,11 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 
-	"fitness-center-manager/internal/database"
 	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
 )
 
 // ==== helpers ===================================================================================
@@ -40,24 +37,17 @@ func validateZoneInput(name string, capacity int, status string) error {
 	return nil
 }
 
+func zoneInput(name, description string, capacity int, status string) store.ZoneInput {
+	return store.ZoneInput{Name: name, Description: description, Capacity: capacity, Status: status}
+}
+
 // GetZones — страница/список зон (рендер шаблона)
 func GetZones(c *fiber.Ctx) error {
-	db := database.GetDB()
 	log.Println("🔍 Получение зон из БД...")
 
 	ctx, cancel := withDBTimeout()
 	defer cancel()
-	rows, err := db.QueryContext(ctx, `
-		SELECT 
-			"id_зоны",
-			"Название", 
-			"Описание",
-			"Вместимость",
-			"Статус",
-			("Фото" IS NOT NULL) AS has_photo
-		FROM "Зона" 
-		ORDER BY "id_зоны" DESC
-	`)
+	zones, err := data.Zones().List(ctx)
 	if err != nil {
 		log.Printf("❌ Ошибка получения зон: %v", err)
 		return c.Render("zones", fiber.Map{
@@ -66,27 +56,6 @@ func GetZones(c *fiber.Ctx) error {
 			"Error": "Не удалось загрузить данные зон: " + err.Error(),
 		})
 	}
-	defer rows.Close()
-
-	var zones []models.Zone
-	for rows.Next() {
-		var z models.Zone
-		if err := rows.Scan(
-			&z.ID,
-			&z.Name,
-			&z.Description,
-			&z.Capacity,
-			&z.Status,
-			&z.HasPhoto,
-		); err != nil {
-			log.Printf("❌ Ошибка сканирования зоны: %v", err)
-			continue
-		}
-		zones = append(zones, z)
-	}
-	if err = rows.Err(); err != nil {
-		log.Printf("❌ Ошибка после итерации по зонам: %v", err)
-	}
 
 	log.Printf("✅ Загружено %d зон из БД", len(zones))
 	return c.Render("zones", fiber.Map{
@@ -102,18 +71,11 @@ func GetZoneByID(c *fiber.Ctx) error {
         return jsonError(c, 400, "Некорректный id", err)
     }
 
-	db := database.GetDB()
-	var z models.Zone
 	ctx, cancel := withDBTimeout()
 	defer cancel()
-	err = db.QueryRowContext(ctx, `
-		SELECT 
-			"id_зоны", "Название", "Описание", "Вместимость", "Статус",
-			("Фото" IS NOT NULL) AS has_photo
-		FROM "Зона" WHERE "id_зоны"=$1
-	`, id).Scan(&z.ID, &z.Name, &z.Description, &z.Capacity, &z.Status, &z.HasPhoto)
+	z, err := data.Zones().Get(ctx, id)
     switch {
-    case errors.Is(err, sql.ErrNoRows):
+    case errors.Is(err, store.ErrNotFound):
         return jsonError(c, 404, "Зона не найдена", nil)
     case err != nil:
         return jsonError(c, 500, "DB: ошибка чтения", err)
@@ -142,15 +104,15 @@ func CreateZone(c *fiber.Ctx) error {
         return jsonError(c, 400, err.Error(), nil)
     }
 
-	db := database.GetDB()
 	var zoneID int
 	ctx, cancel := withDBTimeout()
 	defer cancel()
-    if err := db.QueryRowContext(ctx, `
-        INSERT INTO "Зона" ("Название","Описание","Вместимость","Статус")
-        VALUES ($1,$2,$3,$4)
-        RETURNING "id_зоны"
-    `, f.Name, f.Description, f.Capacity, f.Status).Scan(&zoneID); err != nil {
+	err := inTx(ctx, c, func(tx store.Store) error {
+		var err error
+		zoneID, err = tx.Zones().Create(ctx, zoneInput(f.Name, f.Description, f.Capacity, f.Status))
+		return err
+	})
+    if err != nil {
         log.Printf("❌ Ошибка создания зоны: %v", err)
         return jsonError(c, 500, "Ошибка создания зоны", err)
     }
@@ -182,40 +144,42 @@ func UpdateZone(c *fiber.Ctx) error {
         return jsonError(c, 400, err.Error(), nil)
     }
 
-	db := database.GetDB()
 	ctx, cancel := withDBTimeout()
 	defer cancel()
-	res, err := db.ExecContext(ctx, `
-		UPDATE "Зона"
-		SET "Название"=$2, "Описание"=$3, "Вместимость"=$4, "Статус"=$5
-		WHERE "id_зоны"=$1
-	`, id, f.Name, f.Description, f.Capacity, f.Status)
+	err = inTx(ctx, c, func(tx store.Store) error {
+		return tx.Zones().Update(ctx, id, zoneInput(f.Name, f.Description, f.Capacity, f.Status))
+	})
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 404, "Зона не найдена", nil)
+    }
     if err != nil {
         return jsonError(c, 500, "DB: ошибка обновления", err)
     }
-    aff, _ := res.RowsAffected()
-    if aff == 0 {
-        return jsonError(c, 404, "Зона не найдена", nil)
-    }
     return jsonOK(c, fiber.Map{"message": "Зона обновлена"})
 }
 
-// ClearZonePhoto — установить Фото = NULL
+// ClearZonePhoto — убрать фото зоны (файл удаляется, если больше не нужен)
 func ClearZonePhoto(c *fiber.Ctx) error {
 	id, err := strconv.Atoi(c.Params("id"))
     if err != nil || id <= 0 {
         return jsonError(c, 400, "Некорректный id", err)
     }
-	db := database.GetDB()
 	ctx, cancel := withDBTimeout()
 	defer cancel()
-	res, err := db.ExecContext(ctx, `UPDATE "Зона" SET "Фото"=NULL WHERE "id_зоны"=$1`, id)
+	var old store.Photo
+	err = inTx(ctx, c, func(tx store.Store) error {
+		if old, err = tx.Zones().Photo(ctx, id); err != nil {
+			return err
+		}
+		return tx.Zones().SetPhoto(ctx, id, "")
+	})
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, 404, "Зона не найдена", nil)
+    }
     if err != nil {
         return jsonError(c, 500, "DB: ошибка обновления", err)
     }
-    if rows, _ := res.RowsAffected(); rows == 0 {
-        return jsonError(c, 404, "Зона не найдена", nil)
-    }
+    releasePhoto(old.Key)
     return jsonOK(c, fiber.Map{"message": "Фото удалено"})
 }
 
@@ -227,24 +191,30 @@ func DeleteZone(c *fiber.Ctx) error {
     if err != nil || id <= 0 {
         return jsonError(c, 400, "Некорректный id", err)
     }
-	db := database.GetDB()
-
 	ctx, cancel := withDBTimeout()
 	defer cancel()
-	res, err := db.ExecContext(ctx, `DELETE FROM "Зона" WHERE "id_зоны"=$1`, id)
-    if err != nil {
-        return jsonError(c, 500, "DB: ошибка удаления", err)
-    }
-	aff, _ := res.RowsAffected()
-    if aff == 0 {
+	var old store.Photo
+	err = inTx(ctx, c, func(tx store.Store) error {
+		if old, err = tx.Zones().Photo(ctx, id); err != nil {
+			return err
+		}
+		return tx.Zones().Delete(ctx, id)
+	})
+    switch {
+    case errors.Is(err, store.ErrNotFound):
         return jsonError(c, 404, "Зона не найдена", nil)
+    case errors.Is(err, store.ErrInUse):
+        return jsonError(c, 409, "Невозможно удалить зону: в ней есть оборудование или тренировки", err)
+    case err != nil:
+        return jsonError(c, 500, "DB: ошибка удаления", err)
     }
+    releasePhoto(old.Key)
     return jsonOK(c, fiber.Map{"message": "Зона удалена"})
 }
 
 // ==== upload/read photo =========================================================================
 
-// UploadZonePhoto — загрузить фото (bytea) для зоны
+// UploadZonePhoto — загрузить фото зоны в хранилище файлов
 func UploadZonePhoto(c *fiber.Ctx) error {
 	id, err := strconv.Atoi(c.Params("id"))
     if err != nil || id <= 0 {
@@ -285,16 +255,31 @@ func UploadZonePhoto(c *fiber.Ctx) error {
         return jsonError(c, fiber.StatusBadRequest, "Разрешены JPEG/PNG/WebP", nil)
     }
 
-	db := database.GetDB()
 	ctx, cancel := withDBTimeout()
 	defer cancel()
-	res, err := db.ExecContext(ctx, `UPDATE "Зона" SET "Фото"=$2 WHERE "id_зоны"=$1`, id, buf)
+	key, ok, err := savePhoto(ctx, c, buf)
+	if !ok {
+		return err
+	}
+	var old store.Photo
+	err = inTx(ctx, c, func(tx store.Store) error {
+		if old, err = tx.Zones().Photo(ctx, id); err != nil {
+			return err
+		}
+		return tx.Zones().SetPhoto(ctx, id, key)
+	})
+	if err != nil {
+		releasePhoto(key)
+	}
+    if errors.Is(err, store.ErrNotFound) {
+        return jsonError(c, fiber.StatusNotFound, "Зона не найдена", nil)
+    }
     if err != nil {
         return jsonError(c, fiber.StatusInternalServerError, "DB: ошибка сохранения", err)
     }
-    if rows, _ := res.RowsAffected(); rows == 0 {
-        return jsonError(c, fiber.StatusNotFound, "Зона не найдена", nil)
-    }
+	if old.Key != key {
+		releasePhoto(old.Key)
+	}
 
     return jsonOK(c, fiber.Map{"message": "Фото загружено"})
 }
@@ -306,39 +291,14 @@ func GetZonePhoto(c *fiber.Ctx) error {
         return c.Status(fiber.StatusBadRequest).SendString("Некорректный id зоны")
     }
 
-	db := database.GetDB()
-	var img []byte
 	ctx, cancel := withDBTimeout()
 	defer cancel()
-	err = db.QueryRowContext(ctx, `SELECT "Фото" FROM "Зона" WHERE "id_зоны"=$1`, id).Scan(&img)
+	p, err := data.Zones().Photo(ctx, id)
 	switch {
-	case errors.Is(err, sql.ErrNoRows):
+	case errors.Is(err, store.ErrNotFound):
 		return c.Status(fiber.StatusNotFound).SendString("Зона не найдена")
 	case err != nil:
 		return c.Status(fiber.StatusInternalServerError).SendString("DB: ошибка чтения")
 	}
-	if len(img) == 0 {
-		return c.Status(fiber.StatusNotFound).SendString("Фото отсутствует")
-	}
-
-	head := img
-	if len(head) > 512 {
-		head = head[:512]
-	}
-	mime := http.DetectContentType(head)
-	if !strings.HasPrefix(mime, "image/") {
-		mime = "application/octet-stream"
-	}
-	c.Set("Content-Type", mime)
-
-	sum := sha256.Sum256(img)
-	etag := fmt.Sprintf(`W/"%x"`, sum[:16])
-	c.Set("ETag", etag)
-	if inm := c.Get("If-None-Match"); inm != "" && inm == etag {
-		return c.SendStatus(fiber.StatusNotModified)
-	}
-
-	c.Set("Cache-Control", "public, max-age=3600")
-	c.Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
-	return c.Send(img)
+	return sendPhoto(c, p)
 }
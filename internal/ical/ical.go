@@ -0,0 +1,198 @@
+// Package ical — запись календаря в формате iCalendar (RFC 5545) для
+// подписки из телефона или почтового клиента.
+//
+// Времена тренировок в БД — «настенное» время клуба без пояса, поэтому
+// события пишутся с TZID часового пояса клуба, а сам пояс — компонентом
+// VTIMEZONE, собранным из переходов time.Location на нужные годы.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Статусы события.
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusTentative = "TENTATIVE"
+	StatusCancelled = "CANCELLED"
+)
+
+// Event — событие календаря. Start/End — настенное время клуба (пояс
+// значения игнорируется). UID не должен меняться между выгрузками:
+// по нему клиент узнаёт изменённое или отменённое событие.
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+	Status      string    // пусто — CONFIRMED
+	Sequence    int       // номер редакции события
+	Modified    time.Time // LAST-MODIFIED; нулевое — не пишется
+}
+
+// Calendar — календарь из событий в часовом поясе Location.
+type Calendar struct {
+	Name     string // X-WR-CALNAME — подпись календаря у подписчика
+	Location *time.Location
+	Events   []Event
+}
+
+const (
+	prodID     = "-//fitness-center-manager//RU"
+	localStamp = "20060102T150405"
+	utcStamp   = "20060102T150405Z"
+	maxLine    = 75 // октетов в строке, дальше — перенос (RFC 5545, 3.1)
+)
+
+// Write пишет календарь в w (строки CRLF, длинные строки переносятся).
+func (c Calendar) Write(w io.Writer) error {
+	lw := &lineWriter{w: bufio.NewWriter(w)}
+	now := time.Now().UTC()
+	tz := c.Location.String()
+
+	lw.line("BEGIN:VCALENDAR")
+	lw.line("VERSION:2.0")
+	lw.line("PRODID:" + prodID)
+	lw.line("CALSCALE:GREGORIAN")
+	lw.line("METHOD:PUBLISH")
+	if c.Name != "" {
+		lw.line("X-WR-CALNAME:" + escape(c.Name))
+	}
+	lw.line("X-WR-TIMEZONE:" + tz)
+	c.writeTimezone(lw)
+
+	for _, e := range c.Events {
+		lw.line("BEGIN:VEVENT")
+		lw.line("UID:" + e.UID)
+		lw.line("DTSTAMP:" + now.Format(utcStamp))
+		lw.line("DTSTART;TZID=" + tz + ":" + e.Start.Format(localStamp))
+		lw.line("DTEND;TZID=" + tz + ":" + e.End.Format(localStamp))
+		lw.line("SUMMARY:" + escape(e.Summary))
+		if e.Description != "" {
+			lw.line("DESCRIPTION:" + escape(e.Description))
+		}
+		if e.Location != "" {
+			lw.line("LOCATION:" + escape(e.Location))
+		}
+		status := e.Status
+		if status == "" {
+			status = StatusConfirmed
+		}
+		lw.line("STATUS:" + status)
+		lw.line(fmt.Sprintf("SEQUENCE:%d", e.Sequence))
+		if !e.Modified.IsZero() {
+			lw.line("LAST-MODIFIED:" + e.Modified.UTC().Format(utcStamp))
+		}
+		lw.line("END:VEVENT")
+	}
+	lw.line("END:VCALENDAR")
+	return lw.flush()
+}
+
+// writeTimezone — VTIMEZONE пояса календаря на годы его событий: начальное
+// смещение и каждый переход (без RRULE — переходы берутся из tzdata).
+func (c Calendar) writeTimezone(lw *lineWriter) {
+	from, to := time.Now(), time.Now()
+	for _, e := range c.Events {
+		if e.Start.Before(from) {
+			from = e.Start
+		}
+		if e.End.After(to) {
+			to = e.End
+		}
+	}
+	loc := c.Location
+	t := time.Date(from.Year(), 1, 1, 0, 0, 0, 0, loc)
+	end := time.Date(to.Year()+1, 1, 1, 0, 0, 0, 0, loc)
+
+	name, offset := t.Zone()
+	lw.line("BEGIN:VTIMEZONE")
+	lw.line("TZID:" + loc.String())
+	observance(lw, t.IsDST(), time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), offset, offset, name)
+	for {
+		_, next := t.ZoneBounds()
+		if next.IsZero() || !next.Before(end) {
+			break
+		}
+		prev := offset
+		name, offset = next.Zone()
+		// начало перехода — по часам, действовавшим до него
+		onset := next.In(time.FixedZone("", prev))
+		observance(lw, next.IsDST(), onset, prev, offset, name)
+		t = next
+	}
+	lw.line("END:VTIMEZONE")
+}
+
+func observance(lw *lineWriter, dst bool, onset time.Time, from, to int, name string) {
+	kind := "STANDARD"
+	if dst {
+		kind = "DAYLIGHT"
+	}
+	lw.line("BEGIN:" + kind)
+	lw.line("DTSTART:" + onset.Format(localStamp))
+	lw.line("TZOFFSETFROM:" + utcOffset(from))
+	lw.line("TZOFFSETTO:" + utcOffset(to))
+	if name != "" {
+		lw.line("TZNAME:" + escape(name))
+	}
+	lw.line("END:" + kind)
+}
+
+// utcOffset — смещение в виде +0300.
+func utcOffset(sec int) string {
+	sign := '+'
+	if sec < 0 {
+		sign, sec = '-', -sec
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, sec/3600, sec%3600/60)
+}
+
+// escape — экранирование TEXT (RFC 5545, 3.3.11).
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "").Replace(s)
+}
+
+// lineWriter пишет строки контента с CRLF и переносом по 75 октетов,
+// не разрывая символы UTF-8. Первая ошибка записи запоминается.
+type lineWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (lw *lineWriter) line(s string) {
+	if lw.err != nil {
+		return
+	}
+	limit := maxLine
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		lw.write(s[:cut] + "\r\n ")
+		s = s[cut:]
+		limit = maxLine - 1 // продолжение начинается с пробела
+	}
+	lw.write(s + "\r\n")
+}
+
+func (lw *lineWriter) write(s string) {
+	if lw.err == nil {
+		_, lw.err = lw.w.WriteString(s)
+	}
+}
+
+func (lw *lineWriter) flush() error {
+	if lw.err != nil {
+		return lw.err
+	}
+	return lw.w.Flush()
+}
@@ -0,0 +1,139 @@
+package jobs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron — расписание в формате cron из пяти полей:
+// минута час день-месяца месяц день-недели (0 и 7 — воскресенье).
+// Поддерживаются «*», списки «1,15», диапазоны «1-5», шаг «*/10», «8-18/2»
+// и сокращения @hourly, @daily (@midnight), @weekly, @monthly.
+type Cron struct {
+	spec                     string
+	minute, hour, dom, month uint64 // битовые маски допустимых значений
+	dow                      uint64
+	domAny, dowAny           bool // поле «*»: для правила «день месяца ИЛИ день недели»
+}
+
+var cronAliases = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// ParseCron разбирает cron-выражение.
+func ParseCron(spec string) (Cron, error) {
+	spec = strings.TrimSpace(spec)
+	src := spec
+	if alias, ok := cronAliases[strings.ToLower(spec)]; ok {
+		src = alias
+	}
+	fields := strings.Fields(src)
+	if len(fields) != 5 {
+		return Cron{}, fmt.Errorf("cron «%s»: нужно 5 полей, а не %d", spec, len(fields))
+	}
+	c := Cron{spec: spec, domAny: fields[2] == "*", dowAny: fields[4] == "*"}
+	var err error
+	parsers := []struct {
+		dst      *uint64
+		min, max int
+		name     string
+	}{
+		{&c.minute, 0, 59, "минута"},
+		{&c.hour, 0, 23, "час"},
+		{&c.dom, 1, 31, "день месяца"},
+		{&c.month, 1, 12, "месяц"},
+		{&c.dow, 0, 7, "день недели"},
+	}
+	for i, p := range parsers {
+		if *p.dst, err = parseCronField(fields[i], p.min, p.max); err != nil {
+			return Cron{}, fmt.Errorf("cron «%s», поле «%s»: %w", spec, p.name, err)
+		}
+	}
+	if c.dow&(1<<7) != 0 { // 7 — тоже воскресенье
+		c.dow |= 1
+	}
+	return c, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("неверный шаг «%s»", part)
+			}
+			rng, step = part[:i], n
+		}
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(a)
+			hi, err2 = strconv.Atoi(b)
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("неверный диапазон «%s»", rng)
+			}
+		default:
+			n, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, fmt.Errorf("неверное значение «%s»", rng)
+			}
+			lo, hi = n, n
+			if step > 1 { // «5/15» — с 5 до конца с шагом
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("«%s» вне диапазона %d–%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << v
+		}
+	}
+	return mask, nil
+}
+
+// String — исходная запись.
+func (c Cron) String() string { return c.spec }
+
+func (c Cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<t.Weekday()) != 0
+	// как в cron: если ограничены оба поля, достаточно совпадения одного
+	if !c.domAny && !c.dowAny {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// Next — ближайший момент срабатывания строго после t (с точностью до минуты).
+// Нулевое время — выражение не срабатывает в ближайшие пять лет (напр. 31 февраля).
+func (c Cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,218 @@
+// Package jobs — фоновые задачи внутри процесса приложения: запуск при
+// старте и по cron-расписанию, журнал запусков в БД.
+//
+// Если запущено несколько экземпляров приложения, задачу выполняет только
+// тот, кто взял её advisory-lock в Postgres; остальные пропускают запуск.
+// Изменения задачи делаются в аудируемой транзакции от имени Actor, так что
+// в журнале аудита видно, что статус сменил планировщик, а не сотрудник.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fitness-center-manager/internal/audit"
+	"fitness-center-manager/internal/store"
+)
+
+// Actor — от чьего имени задачи пишут в журнал аудита.
+var Actor = audit.Actor{Login: "scheduler"}
+
+// runTimeout — сколько может идти один запуск.
+const runTimeout = 5 * time.Minute
+
+// Result — итог запуска: сколько записей изменено и что именно (построчно).
+type Result struct {
+	Changed int
+	Details []string
+}
+
+// Job — фоновая задача. Run выполняется в одной транзакции tx.
+type Job struct {
+	Name  string // ключ: advisory-lock, журнал запусков, URL «запустить»
+	Title string // для страницы /admin/jobs
+	Cron  Cron
+	Run   func(ctx context.Context, tx store.Store, now time.Time) (Result, error)
+}
+
+// Locker — межпроцессная блокировка задачи (pgstore.Store.TryLock).
+type Locker interface {
+	TryLock(ctx context.Context, name string) (release func(), ok bool, err error)
+}
+
+// ErrUnknownJob — нет задачи с таким именем.
+var ErrUnknownJob = errors.New("неизвестная задача")
+
+// ErrLocked — задачу сейчас выполняет другой экземпляр приложения.
+var ErrLocked = errors.New("задача уже выполняется")
+
+// State — задача для страницы: расписание и ближайший запуск в этом процессе.
+type State struct {
+	Name    string
+	Title   string
+	Cron    string
+	NextRun time.Time
+	Running bool
+}
+
+// Scheduler — набор задач и их таймеры.
+type Scheduler struct {
+	data   store.Store
+	locker Locker
+	host   string
+	loc    *time.Location // часовой пояс клуба: в нём расписание и «сегодня» задач
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	next    map[string]time.Time
+	running map[string]bool
+}
+
+// New — планировщик поверх хранилища на пуле (не транзакции). Расписание
+// cron и время, которое получают задачи, — в часовом поясе loc.
+func New(data store.Store, locker Locker, loc *time.Location) *Scheduler {
+	host, _ := os.Hostname()
+	return &Scheduler{
+		data:    data,
+		locker:  locker,
+		loc:     loc,
+		host:    fmt.Sprintf("%s:%d", host, os.Getpid()),
+		jobs:    map[string]*Job{},
+		next:    map[string]time.Time{},
+		running: map[string]bool{},
+	}
+}
+
+// Add регистрирует задачу; вызывать до Start.
+func (s *Scheduler) Add(j Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[j.Name] = &j
+}
+
+// Start запускает все задачи сразу и дальше по расписанию — до отмены ctx.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		go s.loop(ctx, j)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *Job) {
+	s.runLogged(ctx, j) // при старте: догнать то, что пропустили, пока приложение стояло
+	for {
+		next := j.Cron.Next(time.Now().In(s.loc))
+		if next.IsZero() {
+			log.Printf("⏰ %s: расписание «%s» больше не срабатывает", j.Name, j.Cron)
+			return
+		}
+		s.mu.Lock()
+		s.next[j.Name] = next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.runLogged(ctx, j)
+		}
+	}
+}
+
+func (s *Scheduler) runLogged(ctx context.Context, j *Job) {
+	if _, err := s.run(ctx, j); err != nil && !errors.Is(err, ErrLocked) {
+		log.Printf("❌ задача %s: %v", j.Name, err)
+	}
+}
+
+// RunNow — запустить задачу вне расписания (кнопка на /admin/jobs).
+func (s *Scheduler) RunNow(ctx context.Context, name string) (Result, error) {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return Result{}, ErrUnknownJob
+	}
+	return s.run(ctx, j)
+}
+
+// run — один запуск под advisory-lock с записью в журнал запусков.
+func (s *Scheduler) run(ctx context.Context, j *Job) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, runTimeout)
+	defer cancel()
+
+	release, ok, err := s.locker.TryLock(ctx, j.Name)
+	if err != nil {
+		return Result{}, fmt.Errorf("блокировка: %w", err)
+	}
+	if !ok {
+		log.Printf("⏰ %s: выполняется другим экземпляром, пропуск", j.Name)
+		return Result{}, ErrLocked
+	}
+	defer release()
+
+	s.setRunning(j.Name, true)
+	defer s.setRunning(j.Name, false)
+
+	runID, err := s.data.JobRuns().Start(ctx, j.Name, s.host)
+	if err != nil {
+		return Result{}, fmt.Errorf("журнал запусков: %w", err)
+	}
+	started := time.Now()
+	var res Result
+	err = s.data.InTx(ctx, Actor, func(tx store.Store) error {
+		var err error
+		res, err = j.Run(ctx, tx, started.In(s.loc))
+		return err
+	})
+
+	status, errText := store.RunOK, ""
+	if err != nil {
+		status, errText = store.RunFailed, err.Error()
+		res = Result{} // транзакция откатилась — ничего не изменено
+	}
+	// итог пишем и после истёкшего ctx запуска
+	finCtx, finCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer finCancel()
+	if ferr := s.data.JobRuns().Finish(finCtx, runID, status, res.Changed, strings.Join(res.Details, "\n"), errText); ferr != nil {
+		log.Printf("❌ задача %s: не удалось записать итог: %v", j.Name, ferr)
+	}
+	if err == nil {
+		log.Printf("⏰ %s: изменено %d за %s", j.Name, res.Changed, time.Since(started).Round(time.Millisecond))
+	}
+	return res, err
+}
+
+func (s *Scheduler) setRunning(name string, on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running[name] = on
+}
+
+// States — задачи по имени с ближайшим запуском в этом процессе.
+func (s *Scheduler) States() []State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]State, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		list = append(list, State{
+			Name:    j.Name,
+			Title:   j.Title,
+			Cron:    j.Cron.String(),
+			NextRun: s.next[j.Name],
+			Running: s.running[j.Name],
+		})
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].Name < list[b].Name })
+	return list
+}
@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"fitness-center-manager/internal/checkin"
+	"fitness-center-manager/internal/maintenance"
+	"fitness-center-manager/internal/store"
+)
+
+// MaintenanceOverdueJob — имя задачи отметки просроченного ТО.
+const MaintenanceOverdueJob = "maintenance-overdue"
+
+// MaintenanceOverdue — задача, которая переводит исправное оборудование с
+// просроченным плановым ТО в «Требует ТО». Статус снимает отметка о
+// проведённом ТО; повторный запуск ничего не меняет.
+func MaintenanceOverdue(c Cron) Job {
+	return Job{
+		Name:  MaintenanceOverdueJob,
+		Title: "Отметка оборудования с просроченным ТО",
+		Cron:  c,
+		Run:   runMaintenanceOverdue,
+	}
+}
+
+func runMaintenanceOverdue(ctx context.Context, tx store.Store, now time.Time) (Result, error) {
+	plans, err := tx.Maintenance().Plans(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("планы ТО: %w", err)
+	}
+	equipment, err := tx.Equipment().List(ctx, store.EquipmentFilter{Status: "Исправен"})
+	if err != nil {
+		return Result{}, fmt.Errorf("оборудование: %w", err)
+	}
+	last, err := tx.Maintenance().Latest(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("история ТО: %w", err)
+	}
+	var res Result
+	flagged := map[int]bool{}
+	for _, d := range maintenance.Schedule(plans, equipment, last, checkin.Day(now)) {
+		if d.State != maintenance.StateOverdue || flagged[d.Equipment.ID] {
+			continue
+		}
+		if err := tx.Equipment().SetStatus(ctx, d.Equipment.ID, "Требует ТО"); err != nil {
+			return Result{}, fmt.Errorf("оборудование #%d: %w", d.Equipment.ID, err)
+		}
+		flagged[d.Equipment.ID] = true
+		line := fmt.Sprintf("оборудование #%d (%s): просрочено ТО «%s» → Требует ТО", d.Equipment.ID, d.Equipment.Name, d.Plan.Name)
+		log.Printf("⏰ %s", line)
+		res.Details = append(res.Details, line)
+	}
+	res.Changed = len(res.Details)
+	return res, nil
+}
@@ -0,0 +1,46 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"fitness-center-manager/internal/checkin"
+	"fitness-center-manager/internal/store"
+)
+
+// SubscriptionStatusJob — имя задачи статусов абонементов.
+const SubscriptionStatusJob = "subscription-status"
+
+// SubscriptionStatus — задача, которая приводит статусы абонементов к датам:
+// «Ожидает» → «Активен» в день начала, всё незавершённое → «Завершен»
+// на следующий день после окончания. Повторный запуск ничего не меняет.
+func SubscriptionStatus(c Cron) Job {
+	return Job{
+		Name:  SubscriptionStatusJob,
+		Title: "Статусы абонементов по датам",
+		Cron:  c,
+		Run:   runSubscriptionStatus,
+	}
+}
+
+func runSubscriptionStatus(ctx context.Context, tx store.Store, now time.Time) (Result, error) {
+	today := checkin.Day(now)
+	activated, err := tx.Subscriptions().Activate(ctx, today)
+	if err != nil {
+		return Result{}, fmt.Errorf("активация: %w", err)
+	}
+	expired, err := tx.Subscriptions().Expire(ctx, today)
+	if err != nil {
+		return Result{}, fmt.Errorf("завершение: %w", err)
+	}
+	var res Result
+	for _, ch := range append(activated, expired...) {
+		line := fmt.Sprintf("абонемент #%d (%s): %s → %s", ch.ID, ch.ClientName, ch.From, ch.To)
+		log.Printf("⏰ %s", line)
+		res.Details = append(res.Details, line)
+	}
+	res.Changed = len(res.Details)
+	return res, nil
+}
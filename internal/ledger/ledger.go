@@ -0,0 +1,115 @@
+// Package ledger — правила учёта платежей: частичная оплата, возврат и
+// статус оплаты абонемента или персональной тренировки.
+//
+// К оплате — цена позиции (Абонемент.Цена, Персональная_тренировка.Стоимость),
+// оплачено — сумма оплат минус возвраты. Платить можно частями, но не больше
+// остатка; вернуть — не больше оплаченного. Суммы сравниваются в копейках.
+package ledger
+
+import (
+	"fmt"
+	"math"
+)
+
+// Вид платежа ("Платёж"."Вид").
+const (
+	KindPayment = "Оплата"
+	KindRefund  = "Возврат"
+)
+
+// Способ оплаты ("Платёж"."Способ").
+const (
+	MethodCash     = "Наличные"
+	MethodCard     = "Карта"
+	MethodTransfer = "Перевод"
+)
+
+// Methods — способы оплаты в порядке отображения.
+var Methods = []string{MethodCash, MethodCard, MethodTransfer}
+
+// ValidMethod — проверка способа оплаты из формы.
+func ValidMethod(m string) bool {
+	for _, v := range Methods {
+		if v == m {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidKind — проверка вида платежа из формы.
+func ValidKind(k string) bool { return k == KindPayment || k == KindRefund }
+
+// Статус оплаты позиции.
+const (
+	StatusUnpaid   = "Не оплачен"
+	StatusPartial  = "Частично"
+	StatusPaid     = "Оплачен"
+	StatusOverpaid = "Переплата"
+)
+
+// Status — статус оплаты позиции с ценой price, по которой оплачено paid.
+func Status(price, paid float64) string {
+	p, d := Cents(price), Cents(paid)
+	switch {
+	case d > p:
+		return StatusOverpaid
+	case d == p:
+		return StatusPaid
+	case d > 0:
+		return StatusPartial
+	default:
+		return StatusUnpaid
+	}
+}
+
+// Owed — остаток к оплате (отрицательный — переплата, к возврату).
+func Owed(price, paid float64) float64 {
+	return float64(Cents(price)-Cents(paid)) / 100
+}
+
+// PersonalPrice — сколько стоит персональная тренировка к оплате:
+// отменённая — 0, оплату по ней можно только вернуть (так же считает store).
+func PersonalPrice(status string, price float64) float64 {
+	if status == "Отменена" {
+		return 0
+	}
+	return price
+}
+
+// Cents — сумма в копейках.
+func Cents(v float64) int64 { return int64(math.Round(v * 100)) }
+
+// Reason — код нарушения правила (уходит в JSON как есть).
+type Reason string
+
+const (
+	Overpaid      Reason = "overpaid"            // оплата больше остатка
+	RefundTooMuch Reason = "refund_exceeds_paid" // возврат больше оплаченного
+)
+
+// Error — нарушение правила учёта; Error() — текст для сотрудника.
+type Error struct {
+	Reason Reason
+	Msg    string
+}
+
+func (e *Error) Error() string { return e.Msg }
+
+// Check проверяет платёж вида kind на сумму amount по позиции с ценой price,
+// по которой уже оплачено paid. Ошибка — *Error.
+func Check(kind string, amount, price, paid float64) error {
+	a := Cents(amount)
+	switch kind {
+	case KindPayment:
+		if owed := Cents(price) - Cents(paid); a > owed {
+			return &Error{Overpaid, fmt.Sprintf("Оплата %.2f ₽ больше остатка к оплате %.2f ₽",
+				amount, float64(max(owed, 0))/100)}
+		}
+	case KindRefund:
+		if a > Cents(paid) {
+			return &Error{RefundTooMuch, fmt.Sprintf("Возврат %.2f ₽ больше оплаченного %.2f ₽", amount, paid)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// Package maintenance — сроки планового ТО оборудования. План задаёт
+// интервал в днях, в моточасах или в обоих (срок — что наступит раньше)
+// и действует на одну единицу или на все единицы типа.
+//
+// Отсчёт идёт от последнего ТО по этому плану, а если его не было — от
+// "Дата_последнего_ТО" единицы, затем от даты покупки; моточасы — от
+// показания при последнем ТО по плану (без него — от нуля). Списанное
+// оборудование в расчёт не входит.
+package maintenance
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+// Состояния срока ТО.
+const (
+	StateOK      = "ok"
+	StateDue     = "due"     // срок подходит или наступил сегодня
+	StateOverdue = "overdue" // срок прошёл
+)
+
+// States — подписи состояний.
+var States = map[string]string{
+	StateOK:      "В норме",
+	StateDue:     "Скоро",
+	StateOverdue: "Просрочено",
+}
+
+const (
+	// SoonDays — за сколько дней до срока ТО считается подходящим.
+	SoonDays = 7
+	// SoonHoursShare — доля интервала в моточасах, за которую до срока ТО
+	// считается подходящим.
+	SoonHoursShare = 0.1
+)
+
+// Applies — действует ли план p на единицу e.
+func Applies(p models.MaintenancePlan, e models.Equipment) bool {
+	if e.Status == "Списан" {
+		return false
+	}
+	if p.EquipmentID > 0 {
+		return p.EquipmentID == e.ID
+	}
+	return p.EquipmentType != "" && strings.EqualFold(strings.TrimSpace(p.EquipmentType), strings.TrimSpace(e.Type))
+}
+
+// Due — срок ТО единицы e по плану p на день today; last — последнее ТО
+// единицы по этому плану (nil — не было). Без даты отсчёта ТО нужно сегодня.
+func Due(p models.MaintenancePlan, e models.Equipment, last *models.MaintenanceRecord, today time.Time) models.MaintenanceDue {
+	d := models.MaintenanceDue{Plan: p, Equipment: e, State: StateOK}
+	base := e.LastServiceDate
+	if !base.Valid {
+		base = e.PurchaseDate
+	}
+	var baseHours float64
+	if last != nil {
+		base = sql.NullTime{Time: last.Date, Valid: true}
+		if last.UsageHours.Valid {
+			baseHours = last.UsageHours.Float64
+		}
+	}
+	d.LastDate = base
+
+	if p.IntervalDays > 0 {
+		if base.Valid {
+			d.DueDate = base.Time.AddDate(0, 0, p.IntervalDays)
+		} else {
+			d.DueDate = today
+		}
+		switch {
+		case d.DueDate.Before(today):
+			d.State = StateOverdue
+		case !d.DueDate.After(today.AddDate(0, 0, SoonDays)):
+			d.State = StateDue
+		}
+	}
+	if p.IntervalHours > 0 {
+		d.DueHours = baseHours + float64(p.IntervalHours)
+		switch {
+		case e.UsageHours > d.DueHours:
+			d.State = StateOverdue
+		case e.UsageHours >= d.DueHours-float64(p.IntervalHours)*SoonHoursShare && d.State == StateOK:
+			d.State = StateDue
+		}
+	}
+	return d
+}
+
+// Schedule — сроки по всем планам для всех единиц, к которым они относятся:
+// сначала просроченные, затем подходящие, внутри — по сроку. last —
+// последние ТО по планам (по одному на пару план–единица).
+func Schedule(plans []models.MaintenancePlan, equipment []models.Equipment, last []models.MaintenanceRecord, today time.Time) []models.MaintenanceDue {
+	type key struct{ plan, eq int }
+	byKey := make(map[key]*models.MaintenanceRecord, len(last))
+	for i := range last {
+		byKey[key{last[i].PlanID, last[i].EquipmentID}] = &last[i]
+	}
+	var out []models.MaintenanceDue
+	for _, p := range plans {
+		for _, e := range equipment {
+			if Applies(p, e) {
+				out = append(out, Due(p, e, byKey[key{p.ID, e.ID}], today))
+			}
+		}
+	}
+	rank := map[string]int{StateOverdue: 0, StateDue: 1, StateOK: 2}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if rank[a.State] != rank[b.State] {
+			return rank[a.State] < rank[b.State]
+		}
+		if !a.DueDate.Equal(b.DueDate) {
+			return !a.DueDate.IsZero() && (b.DueDate.IsZero() || a.DueDate.Before(b.DueDate))
+		}
+		return a.Equipment.Name < b.Equipment.Name
+	})
+	return out
+}
+
+// Pending — только подходящие и просроченные сроки.
+func Pending(items []models.MaintenanceDue) []models.MaintenanceDue {
+	var out []models.MaintenanceDue
+	for _, d := range items {
+		if d.State != StateOK {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// Overdue — id единиц, у которых просрочено ТО хотя бы по одному плану.
+func Overdue(items []models.MaintenanceDue) map[int]bool {
+	out := map[int]bool{}
+	for _, d := range items {
+		if d.State == StateOverdue {
+			out[d.Equipment.ID] = true
+		}
+	}
+	return out
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"database/sql"
 	"time"
+
+	"fitness-center-manager/internal/access"
 )
 
 type Client struct {
@@ -12,37 +14,110 @@ type Client struct {
 	BirthDate    time.Time      `json:"дата_рождения"`
 	RegisterDate time.Time      `json:"дата_регистрации"`
 	MedicalData  sql.NullString `json:"медицинские_данные"`
+	CardNumber   sql.NullString `json:"номер_карты"`
 }
 
 type Tariff struct {
-	ID                   int     `json:"id_тарифа"`
-	Name                 string  `json:"название_тарифа"`
-	Description          string  `json:"описание"`
-	Price                float64 `json:"стоимость"`
-	AccessTime           string  `json:"время_доступа"`
-	HasGroupTrainings    bool    `json:"наличие_групповых_тренировок"`
-	HasPersonalTrainings bool    `json:"наличие_персональных_тренировок"`
+	ID                   int             `json:"id_тарифа"`
+	Name                 string          `json:"название_тарифа"`
+	Description          string          `json:"описание"`
+	Price                float64         `json:"стоимость"`
+	AccessTime           string          `json:"время_доступа"` // исходная запись (interval), см. AccessHours
+	HasGroupTrainings    bool            `json:"наличие_групповых_тренировок"`
+	HasPersonalTrainings bool            `json:"наличие_персональных_тренировок"`
+	AccessHours          access.Schedule `json:"часы_доступа"`
+	AccessHoursIssue     string          `json:"часы_доступа_ошибка"` // не удалось перенести AccessTime
+	FreezeMaxDays        int             `json:"заморозка_макс_дней"`
+	FreezeMaxCount       int             `json:"заморозка_макс_раз"`
 }
 
 type Trainer struct {
-	ID             int       `json:"id_тренера"`
-	FIO            string    `json:"фио"`
-	Phone          string    `json:"номер_телефона"`
-	Specialization string    `json:"специализация"`
-	HireDate       time.Time `json:"дата_найма"`
-	Experience     int       `json:"стаж_работы"`
+	ID             int             `json:"id_тренера"`
+	FIO            string          `json:"фио"`
+	Phone          string          `json:"номер_телефона"`
+	Specialization string          `json:"специализация"`
+	HireDate       time.Time       `json:"дата_найма"`
+	Experience     int             `json:"стаж_работы"`
+	WorkingHours   access.Schedule `json:"рабочие_часы"` // пусто — без ограничений
+}
+
+// TrainerAbsence — отпуск, больничный или отгул тренера; даты включительно.
+type TrainerAbsence struct {
+	ID        int       `json:"id_отсутствия"`
+	TrainerID int       `json:"id_тренера"`
+	Kind      string    `json:"тип"` // Отпуск | Больничный | Отгул
+	StartDate time.Time `json:"дата_начала"`
+	EndDate   time.Time `json:"дата_окончания"`
+	Reason    string    `json:"причина"`
+	CreatedAt time.Time `json:"создано"`
+}
+
+// Covers — попадает ли день в отсутствие.
+func (a TrainerAbsence) Covers(day time.Time) bool {
+	y, m, d := day.Date()
+	day = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return !day.Before(a.StartDate) && !day.After(a.EndDate)
 }
 
 type Subscription struct {
-	ID         int       `json:"id_абонемента"`
-	ClientID   int       `json:"id_клиента"`
-	TariffID   int       `json:"id_тарифа"`
-	StartDate  time.Time `json:"дата_начала"`
-	EndDate    time.Time `json:"дата_окончания"`
-	Status     string    `json:"статус"`
-	Price      float64   `json:"цена"`
-	ClientName string    `json:"фио_клиента"`     // Для JOIN запросов
-	TariffName string    `json:"название_тарифа"` // Для JOIN запросов
+	ID          int             `json:"id_абонемента"`
+	ClientID    int             `json:"id_клиента"`
+	TariffID    int             `json:"id_тарифа"`
+	StartDate   time.Time       `json:"дата_начала"`
+	EndDate     time.Time       `json:"дата_окончания"`
+	Status      string          `json:"статус"`
+	Price       float64         `json:"цена"`
+	ClientName  string          `json:"фио_клиента"`     // Для JOIN запросов
+	TariffName  string          `json:"название_тарифа"` // Для JOIN запросов
+	TariffHours access.Schedule `json:"часы_доступа"`    // Для JOIN запросов
+	FrozenUntil sql.NullTime    `json:"заморожен_до"`    // заморозка, идущая сегодня
+	PreviousID  sql.NullInt64   `json:"id_предыдущего"`  // какой абонемент продлевает
+	RenewalID   sql.NullInt64   `json:"id_продления"`    // каким абонементом продлён
+	Paid        float64         `json:"оплачено"`        // оплаты минус возвраты
+}
+
+// Freeze — заморозка абонемента; даты включительно.
+type Freeze struct {
+	ID             int       `json:"id_заморозки"`
+	SubscriptionID int       `json:"id_абонемента"`
+	StartDate      time.Time `json:"дата_начала"`
+	EndDate        time.Time `json:"дата_окончания"`
+	Reason         string    `json:"причина"`
+	CreatedAt      time.Time `json:"создана"`
+}
+
+// Days — длительность заморозки в днях.
+func (f Freeze) Days() int { return int(f.EndDate.Sub(f.StartDate).Hours()/24) + 1 }
+
+// Covers — попадает ли день в заморозку.
+func (f Freeze) Covers(day time.Time) bool {
+	y, m, d := day.Date()
+	day = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return !day.Before(f.StartDate) && !day.After(f.EndDate)
+}
+
+// Payment — оплата или возврат по абонементу либо персональной тренировке.
+// Amount всегда положительная, направление задаёт Kind.
+type Payment struct {
+	ID                 int           `json:"id_платежа"`
+	ClientID           int           `json:"id_клиента"`
+	SubscriptionID     sql.NullInt64 `json:"id_абонемента"`
+	PersonalTrainingID sql.NullInt64 `json:"id_персональной_тренировки"`
+	Kind               string        `json:"вид"`
+	Amount             float64       `json:"сумма"`
+	Method             string        `json:"способ"`
+	PaidAt             time.Time     `json:"дата_время"`
+	Cashier            string        `json:"кассир"`
+	Comment            string        `json:"комментарий"`
+	ClientName         string        `json:"фио_клиента"` // Для JOIN запросов
+}
+
+// Signed — сумма со знаком: возврат отрицательный.
+func (p Payment) Signed() float64 {
+	if p.Kind == "Возврат" {
+		return -p.Amount
+	}
+	return p.Amount
 }
 
 type Zone struct {
@@ -55,60 +130,353 @@ type Zone struct {
 }
 
 type Equipment struct {
-	ID              int       `json:"id_оборудования"`
-	ZoneID          int       `json:"id_зоны"`
-	Name            string    `json:"название"`
-	PurchaseDate    time.Time `json:"дата_покупки"`
-	LastServiceDate time.Time `json:"дата_последнего_то"`
-	Status          string    `json:"статус"`
-	PhotoPath       string    `json:"фото_path"`
-	ZoneName        string    `json:"название_зоны"` // Для JOIN запросов
+	ID              int          `json:"id_оборудования"`
+	ZoneID          int          `json:"id_зоны"`
+	Name            string       `json:"название"`
+	PurchaseDate    sql.NullTime `json:"дата_покупки"`
+	LastServiceDate sql.NullTime `json:"дата_последнего_то"`
+	Status          string       `json:"статус"`
+	HasPhoto        bool         `json:"есть_фото"`
+	ZoneName        string       `json:"название_зоны"` // Для JOIN запросов
+	Type            string       `json:"тип"`           // пусто — тип не задан
+	UsageHours      float64      `json:"наработка_часов"`
+	ReplacementCost float64      `json:"стоимость_замены"` // 0 — не задана
+}
+
+// TrainerRate — ставка тренера с даты EffectiveFrom (до начала следующей):
+// стоимость часа персональной и надбавки в процентах.
+type TrainerRate struct {
+	ID               int             `json:"id_ставки"`
+	TrainerID        int             `json:"id_тренера"`
+	EffectiveFrom    time.Time       `json:"действует_с"`
+	Category         string          `json:"категория"`
+	HourlyRate       float64         `json:"ставка_в_час"`
+	PeakHours        access.Schedule `json:"пиковые_часы"` // пусто — пиковых часов нет
+	PeakSurcharge    float64         `json:"надбавка_пик"`
+	WeekendSurcharge float64         `json:"надбавка_выходные"`
+	CreatedAt        time.Time       `json:"создано"`
+}
+
+// RateSnapshot — по какой ставке посчитана стоимость персональной.
+// Хранится в самой тренировке: правка ставок её не меняет.
+type RateSnapshot struct {
+	RateID           int     `json:"id_ставки"`
+	Category         string  `json:"категория"`
+	EffectiveFrom    string  `json:"действует_с"` // YYYY-MM-DD
+	HourlyRate       float64 `json:"ставка_в_час"`
+	PeakSurcharge    float64 `json:"надбавка_пик"`
+	WeekendSurcharge float64 `json:"надбавка_выходные"`
+	Minutes          int     `json:"минут"`
+	PeakMinutes      int     `json:"минут_пик"`
+	WeekendMinutes   int     `json:"минут_выходные"`
+	Price            float64 `json:"стоимость"`
 }
 
 type PersonalTraining struct {
-	ID             int       `json:"id_персональной_тренировки"`
-	SubscriptionID int       `json:"id_абонемента"`
-	TrainerID      int       `json:"id_тренера"`
-	StartTime      time.Time `json:"время_начала"`
-	EndTime        time.Time `json:"время_окончания"`
-	Status         string    `json:"статус"`
-	Price          float64   `json:"стоимость"`
-	ClientName     string    `json:"фио_клиента"` // Для JOIN запросов
-	TrainerName    string    `json:"фио_тренера"` // Для JOIN запросов
+	ID             int           `json:"id_персональной_тренировки"`
+	SubscriptionID int           `json:"id_абонемента"`
+	TrainerID      int           `json:"id_тренера"`
+	StartTime      time.Time     `json:"время_начала"`
+	EndTime        time.Time     `json:"время_окончания"`
+	Status         string        `json:"статус"`
+	Price          float64       `json:"стоимость"`
+	ClientID       int           `json:"id_клиента"`           // Для JOIN запросов
+	ClientName     string        `json:"фио_клиента"`          // Для JOIN запросов
+	TrainerName    string        `json:"фио_тренера"`          // Для JOIN запросов
+	Paid           float64       `json:"оплачено"`             // оплаты минус возвраты
+	OverrideReason string        `json:"основание_исключения"` // записан вопреки правилам абонемента
+	Rate           *RateSnapshot `json:"расчёт_стоимости"`     // nil — стоимость введена вручную
 }
 
 type GroupTraining struct {
-	ID              int       `json:"id_групповой_тренировки"`
+	ID              int          `json:"id_групповой_тренировки"`
+	TrainerID       int          `json:"id_тренера"`
+	ZoneID          int          `json:"id_зоны"`
+	Name            string       `json:"название"`
+	Description     string       `json:"описание"`
+	MaxParticipants int          `json:"максимум_участников"`
+	StartTime       time.Time    `json:"время_начала"`
+	EndTime         time.Time    `json:"время_окончания"`
+	DifficultyLevel string       `json:"уровень_сложности"`
+	SeriesID        int          `json:"id_серии"`      // 0 — разовое занятие
+	SeriesDate      sql.NullTime `json:"дата_в_серии"`  // дата по правилу серии
+	Modified        bool         `json:"изменена"`      // правлено отдельно от серии
+	FreeSlots       int          `json:"свободно_мест"` // вычисляемая (view)
+	TrainerName     string       `json:"фио_тренера"`   // Для JOIN запросов
+	ZoneName        string       `json:"название_зоны"` // Для JOIN запросов
+}
+
+// TrainingSeries — серия групповых тренировок: шаблон занятия и правило
+// повторения (RRULE). Start — первая дата и время начала занятий.
+type TrainingSeries struct {
+	ID              int       `json:"id_серии"`
 	TrainerID       int       `json:"id_тренера"`
 	ZoneID          int       `json:"id_зоны"`
 	Name            string    `json:"название"`
 	Description     string    `json:"описание"`
 	MaxParticipants int       `json:"максимум_участников"`
-	StartTime       time.Time `json:"время_начала"`
-	EndTime         time.Time `json:"время_окончания"`
 	DifficultyLevel string    `json:"уровень_сложности"`
-	PhotoPath       string    `json:"фото_path"`
+	Start           time.Time `json:"начало"`
+	DurationMin     int       `json:"длительность_мин"`
+	Rule            string    `json:"правило"`
+	Upcoming        int       `json:"предстоящих"`   // вычисляемая: будущие занятия
 	TrainerName     string    `json:"фио_тренера"`   // Для JOIN запросов
 	ZoneName        string    `json:"название_зоны"` // Для JOIN запросов
 }
 
+// ScheduleConflict — тренировка, пересекающаяся по времени с проверяемой:
+// у неё тот же тренер (SameTrainer) и/или та же зона (SameZone).
+type ScheduleConflict struct {
+	Kind        string    `json:"вид"` // group | personal
+	ID          int       `json:"id"`
+	Title       string    `json:"название"`
+	StartTime   time.Time `json:"время_начала"`
+	EndTime     time.Time `json:"время_окончания"`
+	SameTrainer bool      `json:"тот_же_тренер"`
+	SameZone    bool      `json:"та_же_зона"`
+	TrainerName string    `json:"фио_тренера"`   // Для JOIN запросов
+	ZoneName    string    `json:"название_зоны"` // Для JOIN запросов
+}
+
+// SeriesException — дата, в которую занятия серии нет (EXDATE).
+type SeriesException struct {
+	ID       int       `json:"id_исключения"`
+	SeriesID int       `json:"id_серии"`
+	Date     time.Time `json:"дата"`
+	Reason   string    `json:"причина"`
+}
+
 type GroupTrainingRegistration struct {
-	ID              int    `json:"id_записи"`
-	GroupTrainingID int    `json:"id_групповой_тренировки"`
-	SubscriptionID  int    `json:"id_абонемента"`
-	Status          string `json:"статус"`
-	ClientName      string `json:"фио_клиента"`         // Для JOIN запросов
-	TrainingName    string `json:"название_тренировки"` // Для JOIN запросов
+	ID              int       `json:"id_записи"`
+	GroupTrainingID int       `json:"id_групповой_тренировки"`
+	SubscriptionID  int       `json:"id_абонемента"`
+	Status          string    `json:"статус"`
+	EnrolledAt      time.Time `json:"записан_в"`
+	QueuePosition   int       `json:"место_в_очереди"`      // 0 — не в очереди
+	ClientID        int       `json:"id_клиента"`           // Для JOIN запросов
+	ClientName      string    `json:"фио_клиента"`          // Для JOIN запросов
+	TrainingName    string    `json:"название_тренировки"`  // Для JOIN запросов
+	OverrideReason  string    `json:"основание_исключения"` // записан вопреки правилам абонемента
+}
+
+// Notification — исходящее уведомление клиенту; SentAt пусто — не доставлено.
+type Notification struct {
+	ID         int          `json:"id_уведомления"`
+	ClientID   int          `json:"id_клиента"`
+	Subject    string       `json:"тема"`
+	Text       string       `json:"текст"`
+	CreatedAt  time.Time    `json:"создано"`
+	SentAt     sql.NullTime `json:"отправлено"`
+	ClientName string       `json:"фио_клиента"` // Для JOIN запросов
 }
 
 type RepairRequest struct {
-	ID            int       `json:"id_заявки"`
+	ID            int          `json:"id_заявки"`
+	EquipmentID   int          `json:"id_оборудования"`
+	CreateDate    time.Time    `json:"дата_создания"`
+	ProblemDesc   string       `json:"описание_проблемы"`
+	Status        string       `json:"статус"`
+	Priority      string       `json:"приоритет"`
+	HasPhoto      bool         `json:"есть_фото"`
+	EquipmentName string       `json:"название_оборудования"` // Для JOIN запросов
+	ZoneName      string       `json:"название_зоны"`         // Для JOIN запросов
+	AssigneeID    int          `json:"id_исполнителя"`        // 0 — не назначен
+	AssigneeName  string       `json:"исполнитель"`
+	Deadline      time.Time    `json:"срок_устранения"`
+	ClosedAt      sql.NullTime `json:"дата_закрытия"`
+	VendorID      int          `json:"id_подрядчика"` // 0 — своими силами
+	VendorName    string       `json:"подрядчик"`
+	LabourCost    float64      `json:"стоимость_работ"`
+	PartsCost     float64      `json:"стоимость_запчастей"` // сумма расхода запчастей
+}
+
+// Vendor — подрядчик: сервисная организация или поставщик запчастей.
+type Vendor struct {
+	ID      int    `json:"id_подрядчика"`
+	Name    string `json:"название"`
+	Contact string `json:"контакт"`
+	Phone   string `json:"телефон"`
+	Email   string `json:"email"`
+	Note    string `json:"примечание"`
+}
+
+// SparePart — запчасть на складе: остаток, порог заказа и цена единицы.
+type SparePart struct {
+	ID           int     `json:"id_запчасти"`
+	Name         string  `json:"название"`
+	SKU          string  `json:"артикул"`
+	Unit         string  `json:"единица"`
+	Stock        float64 `json:"остаток"`
+	ReorderLevel float64 `json:"порог_заказа"` // 0 — не отслеживать
+	Price        float64 `json:"цена"`
+	VendorID     int     `json:"id_подрядчика"` // 0 — поставщик не указан
+	VendorName   string  `json:"поставщик"`
+}
+
+// PartUsage — расход запчасти на заявку по цене на момент списания.
+type PartUsage struct {
+	ID       int       `json:"id_расхода"`
+	RepairID int       `json:"id_заявки"`
+	PartID   int       `json:"id_запчасти"`
+	PartName string    `json:"запчасть"`
+	Unit     string    `json:"единица"`
+	Quantity float64   `json:"количество"`
+	Price    float64   `json:"цена"`
+	Date     time.Time `json:"дата"`
+}
+
+// RepairSpend — затраты на ремонт единицы оборудования за месяц: работы
+// по заявкам, созданным в этом месяце, и запчасти, списанные в нём.
+type RepairSpend struct {
 	EquipmentID   int       `json:"id_оборудования"`
-	CreateDate    time.Time `json:"дата_создания"`
-	ProblemDesc   string    `json:"описание_проблемы"`
-	Status        string    `json:"статус"`
-	Priority      string    `json:"приоритет"`
-	PhotoPath     string    `json:"фото_path"`
-	EquipmentName string    `json:"название_оборудования"` // Для JOIN запросов
-	ZoneName      string    `json:"название_зоны"`         // Для JOIN запросов
+	EquipmentName string    `json:"название_оборудования"`
+	ZoneID        int       `json:"id_зоны"`
+	ZoneName      string    `json:"название_зоны"`
+	Month         time.Time `json:"месяц"`
+	Labour        float64   `json:"работы"`
+	Parts         float64   `json:"запчасти"`
+	Repairs       int       `json:"заявок"`
+}
+
+// RepairEvent — запись истории заявки: смена статуса (FromStatus пуст
+// при создании) или комментарий без смены.
+type RepairEvent struct {
+	ID         int       `json:"id_записи"`
+	RepairID   int       `json:"id_заявки"`
+	Time       time.Time `json:"время"`
+	FromStatus string    `json:"статус_до"`
+	Status     string    `json:"статус"`
+	Comment    string    `json:"комментарий"`
+	StaffID    int       `json:"id_сотрудника"`
+	StaffName  string    `json:"сотрудник"`
+}
+
+type Visit struct {
+	ID             int           `json:"id_посещения"`
+	ClientID       int           `json:"id_клиента"`
+	SubscriptionID sql.NullInt64 `json:"id_абонемента"`
+	EnteredAt      time.Time     `json:"время_входа"`
+	ExitedAt       sql.NullTime  `json:"время_выхода"`    // NULL — клиент ещё в зале
+	ClientName     string        `json:"фио_клиента"`     // Для JOIN запросов
+	TariffName     string        `json:"название_тарифа"` // Для JOIN запросов
+}
+
+// CalendarFeed — календарная ссылка (iCalendar) тренера, зоны или клиента.
+type CalendarFeed struct {
+	ID         int          `json:"id_ссылки"`
+	Kind       string       `json:"тип"` // trainer | zone | client
+	OwnerID    int          `json:"id_владельца"`
+	CreatedAt  time.Time    `json:"создана"`
+	LastAccess sql.NullTime `json:"последний_доступ"`
+}
+
+// CalendarEvent — тренировка для календарной ссылки. Status — статус
+// персональной или записи клиента на групповую (пусто — групповая как есть).
+// Revisions и Modified — число правок и время последнего изменения по
+// журналу аудита.
+type CalendarEvent struct {
+	Kind        string       `json:"вид"` // group | personal
+	ID          int          `json:"id"`
+	Title       string       `json:"название"`
+	Description string       `json:"описание"`
+	StartTime   time.Time    `json:"время_начала"`
+	EndTime     time.Time    `json:"время_окончания"`
+	TrainerName string       `json:"фио_тренера"`
+	ZoneName    string       `json:"название_зоны"`
+	Status      string       `json:"статус"`
+	Revisions   int          `json:"правок"`
+	Modified    sql.NullTime `json:"изменено"`
+}
+
+// PayRule — правила оплаты тренера; Configured == false — правил нет,
+// тренеру ничего не начисляется.
+type PayRule struct {
+	TrainerID       int     `json:"id_тренера"`
+	TrainerName     string  `json:"фио_тренера"` // Для JOIN запросов
+	Configured      bool    `json:"задано"`
+	PerGroup        float64 `json:"за_групповую"`
+	PersonalPercent float64 `json:"процент_персональной"`
+	FillThreshold   float64 `json:"порог_заполняемости"` // %
+	FillBonus       float64 `json:"бонус_заполняемости"`
+}
+
+// PayrollGroup — групповая тренера за расчётный период.
+type PayrollGroup struct {
+	ID              int       `json:"id_групповой_тренировки"`
+	Title           string    `json:"название"`
+	StartTime       time.Time `json:"время_начала"`
+	EndTime         time.Time `json:"время_окончания"`
+	MaxParticipants int       `json:"максимум_участников"`
+	Attended        int       `json:"посетили"`
+}
+
+// PayrollPersonal — завершённая персональная тренера за расчётный период.
+type PayrollPersonal struct {
+	ID         int       `json:"id_персональной_тренировки"`
+	ClientName string    `json:"фио_клиента"`
+	StartTime  time.Time `json:"время_начала"`
+	Price      float64   `json:"стоимость"`
+}
+
+// PayrollLine — строка ведомости тренера. Base — стоимость персональной
+// или заполняемость групповой в процентах.
+type PayrollLine struct {
+	TrainerID   int       `json:"id_тренера"`
+	Kind        string    `json:"вид"` // group | personal | bonus
+	TrainingID  int       `json:"id_тренировки"`
+	Time        time.Time `json:"время"`
+	Description string    `json:"описание"`
+	Base        float64   `json:"база"`
+	Amount      float64   `json:"сумма"`
+}
+
+// PayrollPeriod — закрытый расчётный месяц (Month — первое число).
+type PayrollPeriod struct {
+	ID       int       `json:"id_периода"`
+	Month    time.Time `json:"месяц"`
+	ClosedAt time.Time `json:"закрыт"`
+}
+
+// MaintenancePlan — план ТО на единицу оборудования (EquipmentID) или на
+// все единицы типа (EquipmentType). Интервал 0 — не задан.
+type MaintenancePlan struct {
+	ID            int      `json:"id_плана"`
+	Name          string   `json:"название"`
+	EquipmentID   int      `json:"id_оборудования"`
+	EquipmentName string   `json:"название_оборудования"` // Для JOIN запросов
+	EquipmentType string   `json:"тип_оборудования"`
+	IntervalDays  int      `json:"интервал_дней"`
+	IntervalHours int      `json:"интервал_часов"`
+	Checklist     []string `json:"чек_лист"`
+}
+
+// ChecklistItem — пункт чек-листа в записи о проведённом ТО.
+type ChecklistItem struct {
+	Item string `json:"пункт"`
+	Done bool   `json:"выполнен"`
+}
+
+// MaintenanceRecord — проведённое ТО; PlanID 0 — внеплановое.
+type MaintenanceRecord struct {
+	ID          int             `json:"id_обслуживания"`
+	EquipmentID int             `json:"id_оборудования"`
+	PlanID      int             `json:"id_плана"`
+	PlanName    string          `json:"название_плана"` // Для JOIN запросов
+	Date        time.Time       `json:"дата"`
+	UsageHours  sql.NullFloat64 `json:"наработка_часов"`
+	Checklist   []ChecklistItem `json:"чек_лист"`
+	Comment     string          `json:"комментарий"`
+	PerformedBy string          `json:"выполнил"`
+	CreatedAt   time.Time       `json:"создано"`
+}
+
+// MaintenanceDue — срок ТО единицы оборудования по плану. DueDate нулевая,
+// если у плана нет интервала в днях; DueHours — 0, если нет интервала в часах.
+type MaintenanceDue struct {
+	Plan      MaintenancePlan `json:"план"`
+	Equipment Equipment       `json:"оборудование"`
+	LastDate  sql.NullTime    `json:"последнее_то"`
+	DueDate   time.Time       `json:"срок"`
+	DueHours  float64         `json:"срок_часов"`
+	State     string          `json:"состояние"` // ok | due | overdue
 }
@@ -0,0 +1,103 @@
+// Package payroll — начисления тренерам за месяц по их правилам оплаты:
+// фиксированная сумма за каждую проведённую групповую, процент от
+// стоимости каждой завершённой персональной и бонус за групповую,
+// заполненную не меньше чем на порог (доля мест с отметкой «Посетил»).
+//
+// Расчёт не зависит от БД и HTTP: хэндлер загружает правила и тренировки
+// через store и получает строки ведомости. Закрытый месяц хранится
+// строками и этим пакетом больше не пересчитывается.
+package payroll
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"fitness-center-manager/internal/ledger"
+	"fitness-center-manager/internal/models"
+)
+
+// Виды строк ведомости.
+const (
+	KindGroup    = "group"
+	KindPersonal = "personal"
+	KindBonus    = "bonus"
+)
+
+// Kinds — подписи видов строк.
+var Kinds = map[string]string{
+	KindGroup:    "Групповая",
+	KindPersonal: "Персональная",
+	KindBonus:    "Бонус за заполняемость",
+}
+
+// Month — расчётный месяц из «2006-01»: первое число месяца.
+func Month(s string) (time.Time, error) {
+	m, err := time.Parse("2006-01", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("месяц — в виде ГГГГ-ММ")
+	}
+	return m, nil
+}
+
+// Bounds — границы месяца [from, to).
+func Bounds(month time.Time) (from, to time.Time) {
+	return month, month.AddDate(0, 1, 0)
+}
+
+// Lines — ведомость тренера с правилом rule. Групповая считается
+// проведённой, когда она закончилась к моменту now; заполняемость — доля
+// мест с отметкой «Посетил». Без правил строк нет.
+func Lines(rule models.PayRule, groups []models.PayrollGroup, personal []models.PayrollPersonal, now time.Time) []models.PayrollLine {
+	if !rule.Configured {
+		return nil
+	}
+	var lines []models.PayrollLine
+	for _, g := range groups {
+		if g.EndTime.After(now) {
+			continue
+		}
+		fill := FillRate(g)
+		lines = append(lines, models.PayrollLine{
+			TrainerID: rule.TrainerID, Kind: KindGroup, TrainingID: g.ID, Time: g.StartTime,
+			Description: fmt.Sprintf("«%s», посетили %d из %d", g.Title, g.Attended, g.MaxParticipants),
+			Base:        fill, Amount: rule.PerGroup,
+		})
+		if rule.FillBonus > 0 && fill >= rule.FillThreshold {
+			lines = append(lines, models.PayrollLine{
+				TrainerID: rule.TrainerID, Kind: KindBonus, TrainingID: g.ID, Time: g.StartTime,
+				Description: fmt.Sprintf("«%s»: заполняемость %.0f%% (порог %.0f%%)", g.Title, fill, rule.FillThreshold),
+				Base:        fill, Amount: rule.FillBonus,
+			})
+		}
+	}
+	for _, p := range personal {
+		lines = append(lines, models.PayrollLine{
+			TrainerID: rule.TrainerID, Kind: KindPersonal, TrainingID: p.ID, Time: p.StartTime,
+			Description: fmt.Sprintf("Персональная: %s, %.0f%% от стоимости", p.ClientName, rule.PersonalPercent),
+			Base:        p.Price, Amount: round(p.Price * rule.PersonalPercent / 100),
+		})
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].Time.Before(lines[j].Time) })
+	return lines
+}
+
+// FillRate — заполняемость групповой, % (0, если мест не задано).
+func FillRate(g models.PayrollGroup) float64 {
+	if g.MaxParticipants <= 0 {
+		return 0
+	}
+	return round(float64(g.Attended) * 100 / float64(g.MaxParticipants))
+}
+
+// Total — сумма строк (в копейках, без накопления ошибки float).
+func Total(lines []models.PayrollLine) float64 {
+	var cents int64
+	for _, l := range lines {
+		cents += ledger.Cents(l.Amount)
+	}
+	return float64(cents) / 100
+}
+
+func round(v float64) float64 { return math.Round(v*100) / 100 }
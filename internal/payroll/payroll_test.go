@@ -0,0 +1,145 @@
+package payroll
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func group(id int, start string, max, attended int) models.PayrollGroup {
+	return models.PayrollGroup{ID: id, Title: "Йога", StartTime: at(start),
+		EndTime: at(start).Add(time.Hour), MaxParticipants: max, Attended: attended}
+}
+
+// brief — строки ведомости в виде «вид #id сумма (база)».
+func brief(lines []models.PayrollLine) []string {
+	var out []string
+	for _, l := range lines {
+		out = append(out, fmt.Sprintf("%s #%d %.2f (%.2f)", l.Kind, l.TrainingID, l.Amount, l.Base))
+	}
+	return out
+}
+
+func TestLines(t *testing.T) {
+	rule := models.PayRule{TrainerID: 5, Configured: true, PerGroup: 1500,
+		PersonalPercent: 40, FillThreshold: 80, FillBonus: 500}
+	now := at("2026-10-20 12:00")
+	tests := []struct {
+		name     string
+		rule     models.PayRule
+		groups   []models.PayrollGroup
+		personal []models.PayrollPersonal
+		want     []string
+	}{
+		{
+			name:   "правила не заданы",
+			rule:   models.PayRule{TrainerID: 5, PerGroup: 1500},
+			groups: []models.PayrollGroup{group(1, "2026-10-01 10:00", 10, 10)},
+			want:   nil,
+		},
+		{
+			name: "бонус с порога включительно",
+			rule: rule,
+			groups: []models.PayrollGroup{
+				group(1, "2026-10-01 10:00", 10, 8),
+				group(2, "2026-10-02 10:00", 10, 7),
+			},
+			want: []string{
+				"group #1 1500.00 (80.00)", "bonus #1 500.00 (80.00)",
+				"group #2 1500.00 (70.00)",
+			},
+		},
+		{
+			name: "не закончилась к now",
+			rule: rule,
+			groups: []models.PayrollGroup{
+				group(1, "2026-10-20 11:00", 10, 0), // закончилась ровно в now
+				group(2, "2026-10-20 11:30", 10, 10),
+				group(3, "2026-10-25 10:00", 10, 0),
+			},
+			want: []string{"group #1 1500.00 (0.00)"},
+		},
+		{
+			name:   "бонус не задан",
+			rule:   models.PayRule{TrainerID: 5, Configured: true, PerGroup: 1500, FillThreshold: 80},
+			groups: []models.PayrollGroup{group(1, "2026-10-01 10:00", 10, 10)},
+			want:   []string{"group #1 1500.00 (100.00)"},
+		},
+		{
+			name:   "мест не задано — заполняемость 0",
+			rule:   rule,
+			groups: []models.PayrollGroup{group(1, "2026-10-01 10:00", 0, 3)},
+			want:   []string{"group #1 1500.00 (0.00)"},
+		},
+		{
+			name: "процент от персональной с округлением",
+			rule: rule,
+			personal: []models.PayrollPersonal{
+				{ID: 7, ClientName: "Иванов И.И.", StartTime: at("2026-10-03 09:00"), Price: 1833.33},
+				{ID: 8, ClientName: "Петров П.П.", StartTime: at("2026-10-04 09:00"), Price: 0},
+			},
+			want: []string{"personal #7 733.33 (1833.33)", "personal #8 0.00 (0.00)"},
+		},
+		{
+			name: "по времени, бонус сразу за своей групповой",
+			rule: rule,
+			groups: []models.PayrollGroup{
+				group(1, "2026-10-05 10:00", 3, 3),
+				group(2, "2026-10-01 10:00", 3, 2),
+			},
+			personal: []models.PayrollPersonal{
+				{ID: 7, ClientName: "Иванов И.И.", StartTime: at("2026-10-03 09:00"), Price: 2000},
+			},
+			want: []string{
+				"group #2 1500.00 (66.67)",
+				"personal #7 800.00 (2000.00)",
+				"group #1 1500.00 (100.00)", "bonus #1 500.00 (100.00)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := Lines(tt.rule, tt.groups, tt.personal, now)
+			if got := brief(lines); !slices.Equal(got, tt.want) {
+				t.Errorf("Lines = %q, want %q", got, tt.want)
+			}
+			for _, l := range lines {
+				if l.TrainerID != tt.rule.TrainerID {
+					t.Errorf("строка %+v: тренер %d, want %d", l, l.TrainerID, tt.rule.TrainerID)
+				}
+			}
+		})
+	}
+}
+
+func TestTotal(t *testing.T) {
+	tests := []struct {
+		amounts []float64
+		want    float64
+	}{
+		{nil, 0},
+		{[]float64{1500, 500, 733.33}, 2733.33},
+		{[]float64{0.1, 0.2, 0.3, 0.4}, 1},
+		{[]float64{733.33, 733.33, 733.34}, 2200},
+	}
+	for _, tt := range tests {
+		var lines []models.PayrollLine
+		for _, a := range tt.amounts {
+			lines = append(lines, models.PayrollLine{Amount: a})
+		}
+		if got := Total(lines); got != tt.want {
+			t.Errorf("Total(%v) = %v, want %v", tt.amounts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package photo
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// orientation — значение EXIF-тега Orientation (1..8) из JPEG (APP1 Exif),
+// PNG (чанк eXIf) или WebP (чанк EXIF); 1 — если тега нет или файл не
+// разобрать.
+func orientation(data []byte) int {
+	var tiff []byte
+	switch {
+	case bytes.HasPrefix(data, []byte("\xff\xd8")):
+		tiff = jpegExif(data)
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		tiff = pngExif(data)
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		tiff = webpExif(data)
+	}
+	if o := tiffOrientation(tiff); o >= 1 && o <= 8 {
+		return o
+	}
+	return 1
+}
+
+// jpegExif — TIFF-данные из сегмента APP1 "Exif\0\0" (до начала скана).
+func jpegExif(data []byte) []byte {
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xff {
+			return nil
+		}
+		marker := data[i+1]
+		if marker == 0xff { // заполнитель
+			i++
+			continue
+		}
+		if marker == 0xda || marker == 0xd9 { // SOS, EOI
+			return nil
+		}
+		n := int(binary.BigEndian.Uint16(data[i+2:]))
+		if n < 2 || i+2+n > len(data) {
+			return nil
+		}
+		seg := data[i+4 : i+2+n]
+		if marker == 0xe1 && bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+			return seg[6:]
+		}
+		i += 2 + n
+	}
+	return nil
+}
+
+// pngExif — данные чанка eXIf (до IDAT).
+func pngExif(data []byte) []byte {
+	for i := 8; i+8 <= len(data); {
+		n := int(binary.BigEndian.Uint32(data[i:]))
+		typ := string(data[i+4 : i+8])
+		if n < 0 || i+12+n > len(data) || typ == "IDAT" {
+			return nil
+		}
+		if typ == "eXIf" {
+			return data[i+8 : i+8+n]
+		}
+		i += 12 + n
+	}
+	return nil
+}
+
+// webpExif — данные чанка EXIF контейнера RIFF; префикс "Exif\0\0",
+// который пишут некоторые программы, отбрасывается.
+func webpExif(data []byte) []byte {
+	for i := 12; i+8 <= len(data); {
+		typ := string(data[i : i+4])
+		n := int(binary.LittleEndian.Uint32(data[i+4:]))
+		if n < 0 || i+8+n > len(data) {
+			return nil
+		}
+		if typ == "EXIF" {
+			return bytes.TrimPrefix(data[i+8:i+8+n], []byte("Exif\x00\x00"))
+		}
+		i += 8 + n + n&1 // чанки выровнены на чётную длину
+	}
+	return nil
+}
+
+// tiffOrientation ищет тег 0x0112 в IFD0; 0 — не найден.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var bo binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		bo = binary.LittleEndian
+	case "MM":
+		bo = binary.BigEndian
+	default:
+		return 0
+	}
+	if bo.Uint16(tiff[2:]) != 42 {
+		return 0
+	}
+	ifd := int(bo.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	count := int(bo.Uint16(tiff[ifd:]))
+	for k := 0; k < count; k++ {
+		e := ifd + 2 + k*12
+		if e+12 > len(tiff) {
+			return 0
+		}
+		if bo.Uint16(tiff[e:]) == 0x0112 && bo.Uint16(tiff[e+2:]) == 3 { // SHORT
+			return int(bo.Uint16(tiff[e+8:]))
+		}
+	}
+	return 0
+}
@@ -0,0 +1,171 @@
+package photo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"testing"
+)
+
+// tiffWith — TIFF с одним тегом в IFD0 (по умолчанию Orientation = o, SHORT).
+func tiffWith(bo binary.ByteOrder, tag, typ, o uint16) []byte {
+	var b bytes.Buffer
+	if bo == binary.LittleEndian {
+		b.WriteString("II")
+	} else {
+		b.WriteString("MM")
+	}
+	binary.Write(&b, bo, uint16(42))
+	binary.Write(&b, bo, uint32(8)) // IFD0 сразу за заголовком
+	binary.Write(&b, bo, uint16(1))
+	binary.Write(&b, bo, tag)
+	binary.Write(&b, bo, typ)
+	binary.Write(&b, bo, uint32(1))
+	binary.Write(&b, bo, o)
+	binary.Write(&b, bo, uint16(0))
+	binary.Write(&b, bo, uint32(0)) // следующего IFD нет
+	return b.Bytes()
+}
+
+func tiffOrient(o uint16) []byte { return tiffWith(binary.LittleEndian, 0x0112, 3, o) }
+
+// jpegSegment — сегмент маркера m с данными payload.
+func jpegSegment(m byte, payload []byte) []byte {
+	seg := []byte{0xff, m, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// jpegWithExif — SOI, APP0, APP1 Exif с tiff, начало скана. Картинки нет —
+// orientation она не нужна.
+func jpegWithExif(tiff []byte) []byte {
+	b := []byte{0xff, 0xd8}
+	b = append(b, jpegSegment(0xe0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))...)
+	b = append(b, jpegSegment(0xe1, append([]byte("Exif\x00\x00"), tiff...))...)
+	return append(b, 0xff, 0xda, 0, 2)
+}
+
+// pngChunk — чанк PNG с верной CRC (её проверяет image/png).
+func pngChunk(typ string, data []byte) []byte {
+	b := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	b = append(b, typ...)
+	b = append(b, data...)
+	return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(b[4:]))
+}
+
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+func pngWithExif(tiff []byte) []byte {
+	b := []byte(pngSignature)
+	b = append(b, pngChunk("IHDR", make([]byte, 13))...)
+	b = append(b, pngChunk("eXIf", tiff)...)
+	return append(b, pngChunk("IDAT", nil)...)
+}
+
+// riffChunk — чанк RIFF, дополненный до чётной длины.
+func riffChunk(typ string, data []byte) []byte {
+	b := append([]byte(typ), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+	b = append(b, data...)
+	if len(data)%2 == 1 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func webpOf(chunks ...[]byte) []byte {
+	body := []byte("WEBP")
+	for _, c := range chunks {
+		body = append(body, c...)
+	}
+	return append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...), body...)
+}
+
+func webpWithExif(tiff []byte) []byte {
+	// чанк нечётной длины перед EXIF проверяет выравнивание
+	return webpOf(riffChunk("VP8X", make([]byte, 10)), riffChunk("ICCP", []byte{1, 2, 3}), riffChunk("EXIF", tiff))
+}
+
+func TestOrientation(t *testing.T) {
+	type container struct {
+		name string
+		wrap func(tiff []byte) []byte
+	}
+	containers := []container{
+		{"jpeg", jpegWithExif},
+		{"png", pngWithExif},
+		{"webp", webpWithExif},
+		{"webp Exif\\0\\0", func(tiff []byte) []byte { return webpWithExif(append([]byte("Exif\x00\x00"), tiff...)) }},
+	}
+	for _, c := range containers {
+		for o := uint16(1); o <= 8; o++ {
+			t.Run(fmt.Sprintf("%s %d", c.name, o), func(t *testing.T) {
+				if got := orientation(c.wrap(tiffOrient(o))); got != int(o) {
+					t.Errorf("orientation = %d, want %d", got, o)
+				}
+			})
+		}
+	}
+	// порядок байт Motorola
+	if got := orientation(jpegWithExif(tiffWith(binary.BigEndian, 0x0112, 3, 6))); got != 6 {
+		t.Errorf("orientation(MM) = %d, want 6", got)
+	}
+}
+
+func TestOrientationMalformed(t *testing.T) {
+	good := tiffOrient(6)
+	hugePNG := append([]byte(pngSignature), 0xff, 0xff, 0xff, 0xff, 'e', 'X', 'I', 'f')
+	hugeWebP := append(webpOf(), 'E', 'X', 'I', 'F', 0xff, 0xff, 0xff, 0xff)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"пусто", nil},
+		{"не картинка", []byte("hello, world")},
+		{"только SOI", []byte{0xff, 0xd8}},
+		{"JPEG: мусор вместо маркера", []byte{0xff, 0xd8, 0x00, 0xe1, 0x00, 0x10}},
+		{"JPEG: длина сегмента больше файла", []byte{0xff, 0xd8, 0xff, 0xe1, 0xff, 0xff, 'E', 'x'}},
+		{"JPEG: длина сегмента меньше 2", []byte{0xff, 0xd8, 0xff, 0xe1, 0x00, 0x01, 0, 0}},
+		{"JPEG: Exif после начала скана", append([]byte{0xff, 0xd8, 0xff, 0xda, 0, 2}, jpegSegment(0xe1, append([]byte("Exif\x00\x00"), good...))...)},
+		{"JPEG: APP1 без Exif (XMP)", append([]byte{0xff, 0xd8}, jpegSegment(0xe1, []byte("http://ns.adobe.com/xap/1.0/\x00"))...)},
+		{"JPEG: заполнители до конца", []byte{0xff, 0xd8, 0xff, 0xff, 0xff, 0xff}},
+		{"TIFF: неизвестный порядок байт", jpegWithExif(append([]byte("XX"), good[2:]...))},
+		{"TIFF: не 42", jpegWithExif(append(good[:2:2], 43, 0, 8, 0, 0, 0))},
+		{"TIFF: короче заголовка", jpegWithExif(good[:6])},
+		{"TIFF: IFD за концом", jpegWithExif(append(good[:4:4], 0xff, 0xff, 0xff, 0x7f))},
+		{"TIFF: IFD внутри заголовка", jpegWithExif(append(good[:4:4], 2, 0, 0, 0))},
+		{"TIFF: записей больше, чем данных", jpegWithExif(append(good[:8:8], 0xff, 0xff))},
+		{"TIFF: Orientation типа LONG", jpegWithExif(tiffWith(binary.LittleEndian, 0x0112, 4, 6))},
+		{"TIFF: другой тег", jpegWithExif(tiffWith(binary.LittleEndian, 0x0110, 3, 6))},
+		{"TIFF: Orientation 0", jpegWithExif(tiffOrient(0))},
+		{"TIFF: Orientation 9", jpegWithExif(tiffOrient(9))},
+		{"PNG: длина чанка больше файла", hugePNG},
+		{"PNG: eXIf после IDAT", append(append([]byte(pngSignature), pngChunk("IDAT", nil)...), pngChunk("eXIf", good)...)},
+		{"WebP: длина чанка больше файла", hugeWebP},
+		{"WebP: только заголовок RIFF", []byte("RIFF\x04\x00\x00\x00WEB")},
+		{"WebP: RIFF не WebP", append([]byte("RIFF\x00\x00\x00\x00WAVE"), riffChunk("EXIF", good)...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orientation(tt.data); got != 1 {
+				t.Errorf("orientation = %d, want 1", got)
+			}
+		})
+	}
+}
+
+// Любой обрезанный файл разбирается без паники.
+func TestOrientationTruncated(t *testing.T) {
+	for _, data := range [][]byte{
+		jpegWithExif(tiffOrient(6)),
+		jpegWithExif(tiffWith(binary.BigEndian, 0x0112, 3, 8)),
+		pngWithExif(tiffOrient(6)),
+		webpWithExif(tiffOrient(6)),
+	} {
+		for n := range data {
+			if got := orientation(data[:n]); got < 1 || got > 8 {
+				t.Fatalf("orientation(%x) = %d", data[:n], got)
+			}
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Package photo — обработка загруженных фото и их размеры в хранилище
+// файлов (пакет blob).
+//
+// При загрузке фото декодируется, поворачивается по EXIF Orientation и
+// кодируется заново — метаданные (в том числе GPS с телефона) в файл не
+// попадают. Рядом с исходником сохраняются миниатюра (Thumb, ровно
+// 320×240 с обрезкой по центру) и средний размер (Medium, вписан в
+// 1280×1280). Поддерживаются JPEG, PNG и WebP (golang.org/x/image/webp).
+// Кодировщика WebP нет, поэтому WebP сохраняется как JPEG, а с
+// прозрачностью — как PNG.
+//
+// Ключ исходника — SHA-256 обработанного файла, ключи размеров выводятся из
+// него (VariantKey), поэтому в БД по-прежнему хранится один ключ.
+package photo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"fitness-center-manager/internal/blob"
+
+	_ "golang.org/x/image/webp" // декодер для image.Decode
+)
+
+// Size — размер фото при выдаче.
+type Size string
+
+const (
+	Thumb    Size = "thumb"
+	Medium   Size = "medium"
+	Original Size = "original"
+)
+
+// ParseSize — размер из параметра ?size=; пустой — Original.
+func ParseSize(s string) (Size, bool) {
+	switch Size(s) {
+	case "", Original:
+		return Original, true
+	case Thumb, Medium:
+		return Size(s), true
+	}
+	return "", false
+}
+
+const (
+	thumbW, thumbH   = 320, 240
+	mediumW, mediumH = 1280, 1280
+
+	// maxPixels — защита от «бомб»: маленький файл с огромными размерами.
+	maxPixels = 50_000_000
+)
+
+// ErrUnsupported — файл не JPEG/PNG/WebP или не декодируется.
+var ErrUnsupported = errors.New("не удалось прочитать изображение (поддерживаются JPEG, PNG и WebP)")
+
+// ErrTooLarge — слишком большое разрешение.
+var ErrTooLarge = errors.New("слишком большое разрешение изображения")
+
+// Rendition — закодированный размер фото.
+type Rendition struct {
+	Data        []byte
+	ContentType string
+}
+
+// Processed — результат обработки: исходник без метаданных и размеры.
+type Processed struct {
+	Original, Thumb, Medium Rendition
+}
+
+// Process декодирует фото, применяет EXIF Orientation, убирает метаданные
+// и строит миниатюру и средний размер. Формат сохраняется: JPEG → JPEG,
+// PNG → PNG (с прозрачностью); WebP → JPEG, с прозрачностью — PNG.
+func Process(data []byte) (*Processed, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "jpeg" && format != "png" && format != "webp") {
+		return nil, ErrUnsupported
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	src := orient(toRGBA(img), orientation(data))
+	if format == "webp" {
+		format = "jpeg"
+		if !src.Opaque() {
+			format = "png"
+		}
+	}
+
+	enc := func(m image.Image, quality int) (Rendition, error) {
+		var buf bytes.Buffer
+		if format == "png" {
+			err := png.Encode(&buf, m)
+			return Rendition{buf.Bytes(), "image/png"}, err
+		}
+		err := jpeg.Encode(&buf, m, &jpeg.Options{Quality: quality})
+		return Rendition{buf.Bytes(), "image/jpeg"}, err
+	}
+	var p Processed
+	if p.Original, err = enc(src, 90); err != nil {
+		return nil, err
+	}
+	if p.Medium, err = enc(fit(src, mediumW, mediumH), 85); err != nil {
+		return nil, err
+	}
+	if p.Thumb, err = enc(cover(src, thumbW, thumbH), 80); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// VariantKey — ключ файла нужного размера по ключу исходника.
+func VariantKey(key string, s Size) string {
+	if s == Original || s == "" {
+		return key
+	}
+	return blob.Key([]byte(key + "/" + string(s)))
+}
+
+// Save обрабатывает фото (Process), кладёт все размеры в хранилище и
+// возвращает ключ исходника.
+func Save(ctx context.Context, b blob.Store, data []byte) (string, error) {
+	p, err := Process(data)
+	if err != nil {
+		return "", err
+	}
+	key := blob.Key(p.Original.Data)
+	// исходник — последним: есть исходник, значит, есть и размеры
+	for _, v := range []struct {
+		s Size
+		r Rendition
+	}{{Thumb, p.Thumb}, {Medium, p.Medium}, {Original, p.Original}} {
+		if err := b.Put(ctx, VariantKey(key, v.s), v.r.Data, v.r.ContentType); err != nil {
+			return "", err
+		}
+	}
+	return key, nil
+}
+
+// Remove удаляет исходник и все его размеры.
+func Remove(ctx context.Context, b blob.Store, key string) error {
+	for _, s := range []Size{Thumb, Medium, Original} {
+		if err := b.Delete(ctx, VariantKey(key, s)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open — файл нужного размера. Если размеров нет (фото перенесено в
+// хранилище без обработки), отдаётся исходник.
+func Open(ctx context.Context, b blob.Store, key string, s Size) (io.ReadCloser, int64, error) {
+	rc, n, err := b.Open(ctx, VariantKey(key, s))
+	if errors.Is(err, blob.ErrNotFound) && s != Original {
+		return b.Open(ctx, key)
+	}
+	return rc, n, err
+}
+
+// HasVariants — сохранены ли для исходника размеры (по наличию миниатюры).
+func HasVariants(ctx context.Context, b blob.Store, key string) (bool, error) {
+	rc, _, err := b.Open(ctx, VariantKey(key, Thumb))
+	if errors.Is(err, blob.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	rc.Close()
+	return true, nil
+}
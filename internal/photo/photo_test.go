@@ -0,0 +1,200 @@
+package photo
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"testing"
+)
+
+// secret — метка в EXIF исходника (как GPS с телефона): в обработанный
+// файл она попадать не должна.
+const secret = "GPS 55.7558N 37.6173E"
+
+func exifPayload(o uint16) []byte { return append(tiffOrient(o), secret...) }
+
+func sample(w, h int) image.Image {
+	m := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			m.SetRGBA(x, y, color.RGBA{R: byte(x), G: byte(y), B: 128, A: 255})
+		}
+	}
+	return m
+}
+
+func encodeJPEG(t *testing.T, w, h int, o uint16) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, sample(w, h), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if o == 0 {
+		return data
+	}
+	// APP1 Exif сразу за SOI
+	app1 := jpegSegment(0xe1, append([]byte("Exif\x00\x00"), exifPayload(o)...))
+	return append(append(data[:2:2], app1...), data[2:]...)
+}
+
+func encodePNG(t *testing.T, w, h int, o uint16) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, sample(w, h)); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if o == 0 {
+		return data
+	}
+	// eXIf сразу за IHDR (подпись 8 байт + IHDR 25)
+	return append(append(data[:33:33], pngChunk("eXIf", exifPayload(o))...), data[33:]...)
+}
+
+// readWebP — тестовый WebP 150×100 (lossy, из golang.org/x/image); с o —
+// в расширенном формате (VP8X) с чанком EXIF.
+func readWebP(t *testing.T, o uint16) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/blue-purple-pink.lossy.webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o == 0 {
+		return data
+	}
+	vp8x := []byte{0x08, 0, 0, 0, 149, 0, 0, 99, 0, 0} // флаг EXIF, 150×100
+	return webpOf(riffChunk("VP8X", vp8x), data[12:], riffChunk("EXIF", exifPayload(o)))
+}
+
+func TestProcess(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         func(t *testing.T) []byte
+		wantType     string
+		origW, origH int
+		medW, medH   int
+	}{
+		{name: "JPEG без EXIF", data: func(t *testing.T) []byte { return encodeJPEG(t, 40, 20, 0) },
+			wantType: "image/jpeg", origW: 40, origH: 20, medW: 40, medH: 20},
+		{name: "JPEG, Orientation 6", data: func(t *testing.T) []byte { return encodeJPEG(t, 40, 20, 6) },
+			wantType: "image/jpeg", origW: 20, origH: 40, medW: 20, medH: 40},
+		{name: "JPEG, Orientation 3", data: func(t *testing.T) []byte { return encodeJPEG(t, 40, 20, 3) },
+			wantType: "image/jpeg", origW: 40, origH: 20, medW: 40, medH: 20},
+		{name: "большой JPEG", data: func(t *testing.T) []byte { return encodeJPEG(t, 2000, 1500, 0) },
+			wantType: "image/jpeg", origW: 2000, origH: 1500, medW: 1280, medH: 960},
+		{name: "PNG, Orientation 8", data: func(t *testing.T) []byte { return encodePNG(t, 40, 20, 8) },
+			wantType: "image/png", origW: 20, origH: 40, medW: 20, medH: 40},
+		{name: "WebP", data: func(t *testing.T) []byte { return readWebP(t, 0) },
+			wantType: "image/jpeg", origW: 150, origH: 100, medW: 150, medH: 100},
+		{name: "WebP, Orientation 6", data: func(t *testing.T) []byte { return readWebP(t, 6) },
+			wantType: "image/jpeg", origW: 100, origH: 150, medW: 100, medH: 150},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Process(tt.data(t))
+			if err != nil {
+				t.Fatalf("Process: %v", err)
+			}
+			for _, r := range []struct {
+				size Size
+				r    Rendition
+				w, h int
+			}{
+				{Original, p.Original, tt.origW, tt.origH},
+				{Medium, p.Medium, tt.medW, tt.medH},
+				{Thumb, p.Thumb, thumbW, thumbH},
+			} {
+				if r.r.ContentType != tt.wantType {
+					t.Errorf("%s: тип %s, want %s", r.size, r.r.ContentType, tt.wantType)
+				}
+				cfg, format, err := image.DecodeConfig(bytes.NewReader(r.r.Data))
+				if err != nil {
+					t.Fatalf("%s не декодируется: %v", r.size, err)
+				}
+				if "image/"+format != tt.wantType || cfg.Width != r.w || cfg.Height != r.h {
+					t.Errorf("%s: %s %d×%d, want %s %d×%d", r.size, format, cfg.Width, cfg.Height, tt.wantType, r.w, r.h)
+				}
+				// метаданные не переносятся
+				out := r.r.Data
+				if jpegExif(out) != nil || bytes.Contains(out, []byte("Exif\x00\x00")) ||
+					bytes.Contains(out, []byte("eXIf")) || bytes.Contains(out, []byte(secret)) {
+					t.Errorf("%s: в файле остался EXIF", r.size)
+				}
+				if o := orientation(out); o != 1 {
+					t.Errorf("%s: Orientation %d, want 1", r.size, o)
+				}
+			}
+		})
+	}
+}
+
+// Поворот применён к пикселям: у PNG с Orientation 6 (90° по часовой)
+// левый верхний угол результата — левый нижний угол исходника.
+func TestProcessRotatesPixels(t *testing.T) {
+	var buf bytes.Buffer
+	src := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			c := color.RGBA{A: 255}
+			if x < 10 && y >= 10 { // левый нижний угол — белый
+				c = color.RGBA{255, 255, 255, 255}
+			}
+			src.SetRGBA(x, y, c)
+		}
+	}
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	data = append(append(data[:33:33], pngChunk("eXIf", tiffOrient(6))...), data[33:]...)
+	p, err := Process(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := png.Decode(bytes.NewReader(p.Original.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, _, _, _ := m.At(0, 0).RGBA(); r != 0xffff {
+		t.Errorf("левый верхний угол не белый: %v", m.At(0, 0))
+	}
+	if r, _, _, _ := m.At(19, 39).RGBA(); r != 0 {
+		t.Errorf("правый нижний угол не чёрный: %v", m.At(19, 39))
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	var gifBuf bytes.Buffer
+	if err := gif.Encode(&gifBuf, sample(4, 4), nil); err != nil {
+		t.Fatal(err)
+	}
+	// PNG, в заголовке которого 10000×10000: до декодирования пикселей не доходит
+	ihdr := []byte{0, 0, 0x27, 0x10, 0, 0, 0x27, 0x10, 8, 2, 0, 0, 0}
+	bomb := append([]byte(pngSignature), pngChunk("IHDR", ihdr)...)
+	bomb = append(bomb, pngChunk("IDAT", nil)...)
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"пусто", nil, ErrUnsupported},
+		{"текст", []byte("not an image"), ErrUnsupported},
+		{"GIF", gifBuf.Bytes(), ErrUnsupported},
+		{"обрезанный JPEG", encodeJPEG(t, 40, 20, 0)[:200], ErrUnsupported},
+		{"огромное разрешение", bomb, ErrTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Process(tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("Process: %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
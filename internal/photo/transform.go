@@ -0,0 +1,110 @@
+package photo
+
+import (
+	"image"
+	"image/draw"
+)
+
+// toRGBA копирует картинку любого формата в RGBA с началом в (0,0).
+func toRGBA(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// orient поворачивает и отражает картинку так, чтобы EXIF Orientation o
+// стал 1 (как её показывает камера).
+func orient(src *image.RGBA, o int) *image.RGBA {
+	if o <= 1 || o > 8 {
+		return src
+	}
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := w, h
+	if o >= 5 { // 5..8 — поворот на 90°, стороны меняются местами
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch o {
+			case 2: // отражение по горизонтали
+				sx, sy = w-1-x, y
+			case 3: // 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // отражение по вертикали
+				sx, sy = x, h-1-y
+			case 5: // транспонирование
+				sx, sy = y, x
+			case 6: // 90° по часовой
+				sx, sy = y, h-1-x
+			case 7: // транспонирование по побочной диагонали
+				sx, sy = w-1-y, h-1-x
+			case 8: // 90° против часовой
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):][:4], src.Pix[src.PixOffset(sx, sy):][:4])
+		}
+	}
+	return dst
+}
+
+// resize масштабирует область r картинки src до w×h усреднением
+// пикселей (для уменьшения — без «лесенки»; увеличение — как ближайший
+// сосед).
+func resize(src *image.RGBA, r image.Rectangle, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	sw, sh := r.Dx(), r.Dy()
+	for y := 0; y < h; y++ {
+		y0 := r.Min.Y + y*sh/h
+		y1 := max(r.Min.Y+(y+1)*sh/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := r.Min.X + x*sw/w
+			x1 := max(r.Min.X+(x+1)*sw/w, x0+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				p := src.Pix[src.PixOffset(x0, sy):]
+				for sx := 0; sx < x1-x0; sx++ {
+					sum[0] += int(p[sx*4])
+					sum[1] += int(p[sx*4+1])
+					sum[2] += int(p[sx*4+2])
+					sum[3] += int(p[sx*4+3])
+				}
+			}
+			n := (x1 - x0) * (y1 - y0)
+			d := dst.Pix[dst.PixOffset(x, y):]
+			for i := range sum {
+				d[i] = uint8((sum[i] + n/2) / n)
+			}
+		}
+	}
+	return dst
+}
+
+// fit — картинка, вписанная в w×h с сохранением пропорций; меньшие не
+// увеличиваются.
+func fit(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	if sw <= w && sh <= h {
+		return src
+	}
+	dw, dh := w, sh*w/sw
+	if dh > h {
+		dw, dh = sw*h/sh, h
+	}
+	return resize(src, src.Rect, max(dw, 1), max(dh, 1))
+}
+
+// cover — ровно w×h: картинка масштабируется по меньшей стороне, лишнее
+// обрезается по центру.
+func cover(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	cw, ch := sw, sw*h/w
+	if ch > sh {
+		cw, ch = sh*w/h, sh
+	}
+	cw, ch = max(cw, 1), max(ch, 1)
+	x0, y0 := (sw-cw)/2, (sh-ch)/2
+	return resize(src, image.Rect(x0, y0, x0+cw, y0+ch), w, h)
+}
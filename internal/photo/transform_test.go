@@ -0,0 +1,139 @@
+package photo
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// labeled — картинка w×h, у пикселя (x, y) красный канал — буква 'a'+y*w+x.
+func labeled(w, h int) *image.RGBA {
+	m := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			m.SetRGBA(x, y, color.RGBA{R: byte('a' + y*w + x), A: 255})
+		}
+	}
+	return m
+}
+
+// labels — буквы картинки построчно через «/».
+func labels(m *image.RGBA) string {
+	var rows []string
+	for y := 0; y < m.Rect.Dy(); y++ {
+		var row []byte
+		for x := 0; x < m.Rect.Dx(); x++ {
+			row = append(row, m.RGBAAt(x, y).R)
+		}
+		rows = append(rows, string(row))
+	}
+	return strings.Join(rows, "/")
+}
+
+func TestOrient(t *testing.T) {
+	// исходник 3×2:  abc
+	//                def
+	tests := []struct {
+		o    int
+		want string
+	}{
+		{0, "abc/def"},
+		{1, "abc/def"},
+		{2, "cba/fed"},
+		{3, "fed/cba"},
+		{4, "def/abc"},
+		{5, "ad/be/cf"},
+		{6, "da/eb/fc"},
+		{7, "fc/eb/da"},
+		{8, "cf/be/ad"},
+		{9, "abc/def"},
+	}
+	for _, tt := range tests {
+		if got := labels(orient(labeled(3, 2), tt.o)); got != tt.want {
+			t.Errorf("orient(%d) = %s, want %s", tt.o, got, tt.want)
+		}
+	}
+}
+
+func TestCover(t *testing.T) {
+	tests := []struct {
+		name string
+		w, h int
+	}{
+		{"альбомная 4:3", 4000, 3000},
+		{"портретная", 3000, 4000},
+		{"шире 4:3", 1920, 1080},
+		{"меньше миниатюры", 100, 50},
+		{"ровно миниатюра", 320, 240},
+		{"1×1", 1, 1},
+		{"полоса", 5000, 10},
+		{"столбец", 10, 5000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cover(image.NewRGBA(image.Rect(0, 0, tt.w, tt.h)), thumbW, thumbH)
+			if got.Rect != image.Rect(0, 0, thumbW, thumbH) {
+				t.Errorf("cover %d×%d = %v, want 320×240", tt.w, tt.h, got.Rect.Size())
+			}
+		})
+	}
+}
+
+// Обрезка — по центру: у широкой картинки уходят края.
+func TestCoverCrop(t *testing.T) {
+	red, green := color.RGBA{R: 255, A: 255}, color.RGBA{G: 255, A: 255}
+	src := image.NewRGBA(image.Rect(0, 0, 960, 240))
+	for y := 0; y < 240; y++ {
+		for x := 0; x < 960; x++ {
+			c := red
+			if x >= 320 && x < 640 {
+				c = green
+			}
+			src.SetRGBA(x, y, c)
+		}
+	}
+	got := cover(src, thumbW, thumbH)
+	for _, p := range []image.Point{{0, 0}, {319, 0}, {160, 120}, {0, 239}, {319, 239}} {
+		if c := got.RGBAAt(p.X, p.Y); c != green {
+			t.Errorf("пиксель %v = %v, want зелёный", p, c)
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name         string
+		w, h         int
+		wantW, wantH int
+	}{
+		{"альбомная", 4000, 3000, 1280, 960},
+		{"портретная", 3000, 4000, 960, 1280},
+		{"квадрат", 2000, 2000, 1280, 1280},
+		{"меньше — без увеличения", 1000, 500, 1000, 500},
+		{"ровно по границе", 1280, 1280, 1280, 1280},
+		{"полоса", 5000, 1, 1280, 1},
+		{"столбец", 1, 5000, 1, 1280},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fit(image.NewRGBA(image.Rect(0, 0, tt.w, tt.h)), mediumW, mediumH)
+			if got.Rect != image.Rect(0, 0, tt.wantW, tt.wantH) {
+				t.Errorf("fit %d×%d = %v, want %d×%d", tt.w, tt.h, got.Rect.Size(), tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+// Уменьшение усредняет пиксели, а не берёт ближайший.
+func TestResizeAverages(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	src.SetRGBA(0, 0, color.RGBA{255, 255, 255, 255})
+	src.SetRGBA(1, 1, color.RGBA{255, 255, 255, 255})
+	src.SetRGBA(1, 0, color.RGBA{A: 255})
+	src.SetRGBA(0, 1, color.RGBA{A: 255})
+	got := resize(src, src.Rect, 1, 1).RGBAAt(0, 0)
+	if want := (color.RGBA{128, 128, 128, 255}); got != want {
+		t.Errorf("resize = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,58 @@
+// Package pricing — стоимость персональной тренировки по ставке тренера:
+// ставка за час, пропорционально длительности, плюс надбавки в процентах
+// за минуты в пиковые часы и в выходные (сб, вс). Надбавки складываются:
+// минута в пиковое время субботы стоит ставку плюс обе надбавки.
+//
+// Время — «настенное» время клуба, как и во всех тренировках.
+package pricing
+
+import (
+	"math"
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+// Current — ставка, действующая в день day: последняя из rates с
+// EffectiveFrom не позже day; ok=false — ставки на этот день нет.
+func Current(rates []models.TrainerRate, day time.Time) (models.TrainerRate, bool) {
+	var (
+		best models.TrainerRate
+		ok   bool
+	)
+	for _, r := range rates {
+		if !r.EffectiveFrom.After(day) && (!ok || r.EffectiveFrom.After(best.EffectiveFrom)) {
+			best, ok = r, true
+		}
+	}
+	return best, ok
+}
+
+// Quote — стоимость тренировки [start, end) по ставке rate со всеми
+// слагаемыми расчёта; округление — до копеек.
+func Quote(rate models.TrainerRate, start, end time.Time) models.RateSnapshot {
+	s := models.RateSnapshot{
+		RateID:           rate.ID,
+		Category:         rate.Category,
+		EffectiveFrom:    rate.EffectiveFrom.Format("2006-01-02"),
+		HourlyRate:       rate.HourlyRate,
+		PeakSurcharge:    rate.PeakSurcharge,
+		WeekendSurcharge: rate.WeekendSurcharge,
+	}
+	// пустые часы в access означают «всегда», а для пика — «никогда»
+	peak := !rate.PeakHours.Unrestricted()
+	for t := start; t.Before(end); t = t.Add(time.Minute) {
+		s.Minutes++
+		if peak && rate.PeakHours.Allows(t) {
+			s.PeakMinutes++
+		}
+		if wd := t.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			s.WeekendMinutes++
+		}
+	}
+	minutes := float64(s.Minutes) +
+		float64(s.PeakMinutes)*rate.PeakSurcharge/100 +
+		float64(s.WeekendMinutes)*rate.WeekendSurcharge/100
+	s.Price = math.Round(rate.HourlyRate*minutes/60*100) / 100
+	return s
+}
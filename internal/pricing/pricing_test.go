@@ -0,0 +1,108 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"fitness-center-manager/internal/access"
+	"fitness-center-manager/internal/models"
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestQuote(t *testing.T) {
+	// 20.10.2026 — вторник, 23.10 — пятница, 25.10 — воскресенье
+	tests := []struct {
+		name       string
+		peak       string // часы пик в записи access.Parse; пусто — нет
+		start, end string
+		minutes    int
+		peakMin    int
+		weekendMin int
+		price      float64
+	}{
+		{name: "без пика", start: "2026-10-20 10:00", end: "2026-10-20 11:00",
+			minutes: 60, price: 1200},
+		{name: "часть в пик", peak: "ежедневно 18:00–21:00", start: "2026-10-20 17:30", end: "2026-10-20 18:30",
+			minutes: 60, peakMin: 30, price: 1500},
+		{name: "пик до конца суток", peak: "ежедневно 18:00–24:00", start: "2026-10-20 23:30", end: "2026-10-21 00:30",
+			minutes: 60, peakMin: 30, price: 1500},
+		{name: "пик через полночь целиком", peak: "ежедневно 22:00–02:00", start: "2026-10-20 23:00", end: "2026-10-21 01:00",
+			minutes: 120, peakMin: 120, price: 3600},
+		{name: "вечерняя часть ночного пика", peak: "ежедневно 22:00–02:00", start: "2026-10-20 21:30", end: "2026-10-20 22:30",
+			minutes: 60, peakMin: 30, price: 1500},
+		{name: "утренняя часть ночного пика", peak: "ежедневно 22:00–02:00", start: "2026-10-21 01:30", end: "2026-10-21 02:30",
+			minutes: 60, peakMin: 30, price: 1500},
+		{name: "ночной пик пятницы в ночь на субботу", peak: "пт 22:00–02:00", start: "2026-10-23 23:00", end: "2026-10-24 01:00",
+			minutes: 120, peakMin: 120, weekendMin: 60, price: 3840},
+		{name: "утро субботы после ночного пика пятницы", peak: "пт 22:00–02:00", start: "2026-10-24 00:30", end: "2026-10-24 01:30",
+			minutes: 60, peakMin: 60, weekendMin: 60, price: 2040},
+		{name: "ночной пик пятницы не действует в ночь на пятницу", peak: "пт 22:00–02:00", start: "2026-10-22 23:00", end: "2026-10-23 01:00",
+			minutes: 120, price: 2400},
+		{name: "из воскресенья в понедельник", peak: "ежедневно 22:00–02:00", start: "2026-10-25 23:00", end: "2026-10-26 01:00",
+			minutes: 120, peakMin: 120, weekendMin: 60, price: 3840},
+		{name: "округление до копеек", start: "2026-10-20 10:00", end: "2026-10-20 10:01",
+			minutes: 1, price: 20},
+		{name: "пустой интервал", start: "2026-10-20 10:00", end: "2026-10-20 10:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate := models.TrainerRate{ID: 3, EffectiveFrom: at("2026-10-01 00:00"), Category: "Старший",
+				HourlyRate: 1200, PeakSurcharge: 50, WeekendSurcharge: 20}
+			if tt.peak != "" {
+				s, err := access.Parse(tt.peak)
+				if err != nil {
+					t.Fatalf("access.Parse(%q): %v", tt.peak, err)
+				}
+				rate.PeakHours = s
+			}
+			got := Quote(rate, at(tt.start), at(tt.end))
+			want := models.RateSnapshot{RateID: 3, Category: "Старший", EffectiveFrom: "2026-10-01",
+				HourlyRate: 1200, PeakSurcharge: 50, WeekendSurcharge: 20,
+				Minutes: tt.minutes, PeakMinutes: tt.peakMin, WeekendMinutes: tt.weekendMin, Price: tt.price}
+			if got != want {
+				t.Errorf("Quote = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestQuoteRounding(t *testing.T) {
+	rate := models.TrainerRate{HourlyRate: 1000, PeakSurcharge: 15}
+	rate.PeakHours, _ = access.Parse("ежедневно 00:00–24:00")
+	// 50 минут: 1000 × (50 + 50×0,15) / 60 = 958,333…
+	if got := Quote(rate, at("2026-10-20 10:00"), at("2026-10-20 10:50")).Price; got != 958.33 {
+		t.Errorf("Price = %v, want 958.33", got)
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	rates := []models.TrainerRate{
+		{ID: 2, EffectiveFrom: at("2026-09-01 00:00")},
+		{ID: 1, EffectiveFrom: at("2026-01-01 00:00")},
+		{ID: 3, EffectiveFrom: at("2026-11-01 00:00")},
+	}
+	tests := []struct {
+		day    string
+		wantID int // 0 — ставки нет
+	}{
+		{"2025-12-31 00:00", 0},
+		{"2026-01-01 00:00", 1},
+		{"2026-08-31 00:00", 1},
+		{"2026-09-01 00:00", 2},
+		{"2026-10-31 00:00", 2},
+		{"2027-01-01 00:00", 3},
+	}
+	for _, tt := range tests {
+		r, ok := Current(rates, at(tt.day))
+		if ok != (tt.wantID != 0) || r.ID != tt.wantID {
+			t.Errorf("Current(%s) = #%d, %v; want #%d", tt.day, r.ID, ok, tt.wantID)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Package recurrence — правила повторения групповых тренировок в духе RRULE
+// из RFC 5545 и развёртка правила в даты занятий.
+//
+// Поддерживается подмножество: FREQ=DAILY|WEEKLY, INTERVAL, BYDAY (только
+// для WEEKLY, неделя с понедельника) и ровно одно из UNTIL или COUNT —
+// бесконечных серий нет. В отличие от RFC, дата начала серии не считается
+// занятием сама по себе, если не подходит под BYDAY: серия «пн, ср»,
+// начатая во вторник, откроется средой.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fitness-center-manager/internal/checkin"
+)
+
+// MaxOccurrences — больше занятий в одной серии не разворачиваем.
+const MaxOccurrences = 366
+
+// Частоты повторения.
+const (
+	Daily  = "DAILY"
+	Weekly = "WEEKLY"
+)
+
+// ErrTooMany — правило даёт больше MaxOccurrences занятий.
+var ErrTooMany = fmt.Errorf("в серии больше %d занятий — сократите срок", MaxOccurrences)
+
+var (
+	dayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+	dayNames = [...]string{"вс", "пн", "вт", "ср", "чт", "пт", "сб"}
+)
+
+// Rule — разобранное правило повторения.
+type Rule struct {
+	Freq     string         // Daily | Weekly
+	Interval int            // каждые N дней или недель, ≥ 1
+	ByDay    []time.Weekday // дни недели (Weekly); пусто — день начала серии
+	Until    time.Time      // последняя дата включительно; нулевая — серию ограничивает Count
+	Count    int            // число занятий; 0 — серию ограничивает Until
+}
+
+// weekOffset — номер дня в неделе, начинающейся с понедельника.
+func weekOffset(d time.Weekday) int { return (int(d) + 6) % 7 }
+
+// Parse разбирает строку вида "FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20261231"
+// (префикс "RRULE:" допускается).
+func Parse(s string) (Rule, error) {
+	r := Rule{Interval: 1}
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "RRULE:")
+	if s == "" {
+		return r, errors.New("не задано правило повторения")
+	}
+	for _, part := range strings.Split(s, ";") {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok || v == "" {
+			return r, fmt.Errorf("RRULE: %q без значения", part)
+		}
+		switch k {
+		case "FREQ":
+			if v != Daily && v != Weekly {
+				return r, fmt.Errorf("RRULE: частота %s не поддерживается (только DAILY и WEEKLY)", v)
+			}
+			r.Freq = v
+		case "INTERVAL":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return r, fmt.Errorf("RRULE: некорректный INTERVAL %q", v)
+			}
+			r.Interval = n
+		case "BYDAY":
+			for _, code := range strings.Split(v, ",") {
+				d := slices.Index(dayCodes[:], code)
+				if d < 0 {
+					return r, fmt.Errorf("RRULE: неизвестный день недели %q", code)
+				}
+				if !slices.Contains(r.ByDay, time.Weekday(d)) {
+					r.ByDay = append(r.ByDay, time.Weekday(d))
+				}
+			}
+		case "UNTIL":
+			// 20261231 или 20261231T235959Z — время не важно, берём дату
+			if len(v) < 8 {
+				return r, fmt.Errorf("RRULE: некорректный UNTIL %q", v)
+			}
+			t, err := time.Parse("20060102", v[:8])
+			if err != nil {
+				return r, fmt.Errorf("RRULE: некорректный UNTIL %q", v)
+			}
+			r.Until = t
+		case "COUNT":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return r, fmt.Errorf("RRULE: некорректный COUNT %q", v)
+			}
+			r.Count = n
+		case "WKST":
+			if v != "MO" {
+				return r, errors.New("RRULE: поддерживается только WKST=MO")
+			}
+		default:
+			return r, fmt.Errorf("RRULE: параметр %s не поддерживается", k)
+		}
+	}
+	switch {
+	case r.Freq == "":
+		return r, errors.New("RRULE: не задан FREQ")
+	case r.Freq == Daily && len(r.ByDay) > 0:
+		return r, errors.New("RRULE: BYDAY поддерживается только с FREQ=WEEKLY")
+	case r.Until.IsZero() == (r.Count == 0):
+		return r, errors.New("RRULE: нужен ровно один из UNTIL (до даты) или COUNT (число занятий)")
+	case r.Count > MaxOccurrences:
+		return r, ErrTooMany
+	}
+	slices.SortFunc(r.ByDay, func(a, b time.Weekday) int { return weekOffset(a) - weekOffset(b) })
+	return r, nil
+}
+
+// String — правило в виде RRULE (без префикса).
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			codes[i] = dayCodes[d]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Describe — правило по-русски для интерфейса: «еженедельно: пн, ср; до 31.12.2026».
+func (r Rule) Describe() string {
+	var b strings.Builder
+	switch {
+	case r.Freq == Daily && r.Interval > 1:
+		fmt.Fprintf(&b, "раз в %d дн.", r.Interval)
+	case r.Freq == Daily:
+		b.WriteString("ежедневно")
+	case r.Interval > 1:
+		fmt.Fprintf(&b, "раз в %d нед.", r.Interval)
+	default:
+		b.WriteString("еженедельно")
+	}
+	if len(r.ByDay) > 0 {
+		names := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			names[i] = dayNames[d]
+		}
+		b.WriteString(": " + strings.Join(names, ", "))
+	}
+	if !r.Until.IsZero() {
+		b.WriteString("; до " + r.Until.Format("02.01.2006"))
+	}
+	if r.Count > 0 {
+		fmt.Fprintf(&b, "; занятий: %d", r.Count)
+	}
+	return b.String()
+}
+
+// Dates — даты занятий серии, начинающейся в день start, по возрастанию.
+func (r Rule) Dates(start time.Time) ([]time.Time, error) {
+	start = checkin.Day(start)
+	interval := max(r.Interval, 1)
+	var out []time.Time
+	// add — false, когда серия закончилась
+	add := func(d time.Time) (bool, error) {
+		if (!r.Until.IsZero() && d.After(r.Until)) || (r.Count > 0 && len(out) == r.Count) {
+			return false, nil
+		}
+		if len(out) == MaxOccurrences {
+			return false, ErrTooMany
+		}
+		out = append(out, d)
+		return true, nil
+	}
+
+	if r.Freq == Daily {
+		for d := start; ; d = d.AddDate(0, 0, interval) {
+			if ok, err := add(d); !ok {
+				return out, err
+			}
+		}
+	}
+	days := r.ByDay
+	if len(days) == 0 {
+		days = []time.Weekday{start.Weekday()}
+	}
+	for week := start.AddDate(0, 0, -weekOffset(start.Weekday())); ; week = week.AddDate(0, 0, 7*interval) {
+		for _, wd := range days {
+			d := week.AddDate(0, 0, weekOffset(wd))
+			if d.Before(start) {
+				continue
+			}
+			if ok, err := add(d); !ok {
+				return out, err
+			}
+		}
+	}
+}
+
+// Split делит серию, начатую start, на занятия до дня at и с него: head
+// заканчивается накануне at, tail продолжает правило с at. kept — сколько
+// занятий остаётся в head (0 — делить нечего, at — первое занятие); при
+// COUNT остаток счёта переходит в tail.
+func (r Rule) Split(start, at time.Time) (head, tail Rule, kept int, err error) {
+	dates, err := r.Dates(start)
+	if err != nil {
+		return r, r, 0, err
+	}
+	at = checkin.Day(at)
+	for _, d := range dates {
+		if d.Before(at) {
+			kept++
+		}
+	}
+	head, tail = r, r
+	if r.Count > 0 {
+		head.Count = kept
+		tail.Count = r.Count - kept
+	} else {
+		head.Until = at.AddDate(0, 0, -1)
+	}
+	return head, tail, kept, nil
+}
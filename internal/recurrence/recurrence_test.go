@@ -0,0 +1,274 @@
+package recurrence
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func days(ss ...string) []time.Time {
+	out := make([]time.Time, len(ss))
+	for i, s := range ss {
+		out[i] = day(s)
+	}
+	return out
+}
+
+// errAny — в таблицах: подходит любая ошибка.
+var errAny = errors.New("любая ошибка")
+
+func mustParse(t *testing.T, s string) Rule {
+	t.Helper()
+	r, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", s, err)
+	}
+	return r
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string // String() разобранного правила
+		wantErr error  // errAny — любая ошибка
+	}{
+		{in: "RRULE:FREQ=WEEKLY;BYDAY=WE,MO;UNTIL=20261231T235959Z", want: "FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20261231"},
+		{in: "freq=daily;interval=2;count=10", want: "FREQ=DAILY;INTERVAL=2;COUNT=10"},
+		{in: "FREQ=WEEKLY;BYDAY=SU,MO,SU;WKST=MO;COUNT=3", want: "FREQ=WEEKLY;BYDAY=MO,SU;COUNT=3"},
+		{in: "FREQ=DAILY;INTERVAL=1;COUNT=366", want: "FREQ=DAILY;COUNT=366"},
+		{in: "", wantErr: errAny},
+		{in: "COUNT=3", wantErr: errAny},
+		{in: "FREQ=MONTHLY;COUNT=3", wantErr: errAny},
+		{in: "FREQ=DAILY;BYDAY=MO;COUNT=3", wantErr: errAny},
+		{in: "FREQ=DAILY", wantErr: errAny},
+		{in: "FREQ=DAILY;COUNT=3;UNTIL=20261231", wantErr: errAny},
+		{in: "FREQ=DAILY;INTERVAL=0;COUNT=3", wantErr: errAny},
+		{in: "FREQ=WEEKLY;BYDAY=XX;COUNT=3", wantErr: errAny},
+		{in: "FREQ=WEEKLY;WKST=SU;COUNT=3", wantErr: errAny},
+		{in: "FREQ=DAILY;UNTIL=2026", wantErr: errAny},
+		{in: "FREQ=DAILY;COUNT", wantErr: errAny},
+		{in: "FREQ=DAILY;COUNT=367", wantErr: ErrTooMany},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			r, err := Parse(tt.in)
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("ошибка: %v", err)
+			case tt.wantErr == nil:
+				if got := r.String(); got != tt.want {
+					t.Errorf("String() = %q, want %q", got, tt.want)
+				}
+			case err == nil:
+				t.Fatalf("нет ошибки, правило %q", r)
+			case tt.wantErr != errAny && !errors.Is(err, tt.wantErr):
+				t.Errorf("ошибка %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDates(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		want  []time.Time
+	}{
+		{
+			name:  "BYDAY с середины недели",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4",
+			start: day("2026-10-20"), // вторник
+			want:  days("2026-10-21", "2026-10-26", "2026-10-28", "2026-11-02"),
+		},
+		{
+			name:  "без BYDAY — день начала",
+			rule:  "FREQ=WEEKLY;COUNT=3",
+			start: day("2026-10-20"),
+			want:  days("2026-10-20", "2026-10-27", "2026-11-03"),
+		},
+		{
+			name:  "DAILY с INTERVAL до UNTIL включительно",
+			rule:  "FREQ=DAILY;INTERVAL=3;UNTIL=20261101",
+			start: day("2026-10-20"),
+			want:  days("2026-10-20", "2026-10-23", "2026-10-26", "2026-10-29", "2026-11-01"),
+		},
+		{
+			name:  "время начала не важно",
+			rule:  "FREQ=DAILY;COUNT=2",
+			start: time.Date(2026, 10, 20, 23, 30, 0, 0, msk),
+			want:  days("2026-10-20", "2026-10-21"),
+		},
+		{
+			name:  "UNTIL раньше начала",
+			rule:  "FREQ=DAILY;UNTIL=20261001",
+			start: day("2026-10-20"),
+			want:  nil,
+		},
+		{
+			name:  "UNTIL в день начала",
+			rule:  "FREQ=WEEKLY;BYDAY=TU;UNTIL=20261020",
+			start: day("2026-10-20"),
+			want:  days("2026-10-20"),
+		},
+		{
+			// недели отсчитываются от понедельника недели начала: 19.10 — «своя»
+			// неделя (пн до начала пропущен), 26.10 — пропуск, 02.11 — своя
+			name:  "INTERVAL выравнивает недели по началу",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=4",
+			start: day("2026-10-21"), // среда
+			want:  days("2026-10-23", "2026-11-02", "2026-11-06", "2026-11-16"),
+		},
+		{
+			name:  "INTERVAL с началом в воскресенье",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,SU;COUNT=3",
+			start: day("2026-10-25"), // воскресенье — последний день недели
+			want:  days("2026-10-25", "2026-11-02", "2026-11-08"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mustParse(t, tt.rule).Dates(tt.start)
+			if err != nil {
+				t.Fatalf("ошибка: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Dates = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDatesCap(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		start   time.Time
+		want    int
+		wantErr error
+	}{
+		{name: "ровно 366 по COUNT", rule: "FREQ=DAILY;COUNT=366", start: day("2026-01-01"), want: 366},
+		{name: "ровно 366 по UNTIL", rule: "FREQ=DAILY;UNTIL=20270101", start: day("2026-01-01"), want: 366},
+		{name: "367 по UNTIL", rule: "FREQ=DAILY;UNTIL=20270102", start: day("2026-01-01"), want: 366, wantErr: ErrTooMany},
+		{name: "несколько лет по неделям", rule: "FREQ=WEEKLY;BYDAY=MO,WE,FR;UNTIL=20291231", start: day("2026-01-01"), want: 366, wantErr: ErrTooMany},
+		{name: "год по пн, ср, пт", rule: "FREQ=WEEKLY;BYDAY=MO,WE,FR;UNTIL=20261231", start: day("2026-01-01"), want: 156},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mustParse(t, tt.rule).Dates(tt.start)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ошибка %v, want %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("занятий %d, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		start    time.Time
+		at       time.Time
+		wantKept int
+		wantHead string
+		wantTail string
+	}{
+		{
+			name:     "по первому занятию, COUNT",
+			rule:     "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4",
+			start:    day("2026-10-20"),
+			at:       day("2026-10-21"),
+			wantKept: 0,
+			wantHead: "FREQ=WEEKLY;BYDAY=MO,WE",
+			wantTail: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4",
+		},
+		{
+			name:     "по первому занятию, UNTIL",
+			rule:     "FREQ=DAILY;UNTIL=20261031",
+			start:    day("2026-10-20"),
+			at:       day("2026-10-20"),
+			wantKept: 0,
+			wantHead: "FREQ=DAILY;UNTIL=20261019",
+			wantTail: "FREQ=DAILY;UNTIL=20261031",
+		},
+		{
+			name:     "остаток COUNT переходит в хвост",
+			rule:     "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=5",
+			start:    day("2026-10-20"),
+			at:       day("2026-10-26"),
+			wantKept: 1,
+			wantHead: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=1",
+			wantTail: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4",
+		},
+		{
+			name:     "UNTIL головы — накануне",
+			rule:     "FREQ=DAILY;INTERVAL=2;UNTIL=20261030",
+			start:    day("2026-10-20"),
+			at:       day("2026-10-24"),
+			wantKept: 2,
+			wantHead: "FREQ=DAILY;INTERVAL=2;UNTIL=20261023",
+			wantTail: "FREQ=DAILY;INTERVAL=2;UNTIL=20261030",
+		},
+		{
+			name:     "INTERVAL: хвост с занятия сохраняет недели",
+			rule:     "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=6",
+			start:    day("2026-10-21"),
+			at:       day("2026-11-02"),
+			wantKept: 1,
+			wantHead: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=1",
+			wantTail: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=5",
+		},
+		{
+			name:     "по последнему занятию",
+			rule:     "FREQ=WEEKLY;BYDAY=TU;COUNT=3",
+			start:    day("2026-10-20"),
+			at:       day("2026-11-03"),
+			wantKept: 2,
+			wantHead: "FREQ=WEEKLY;BYDAY=TU;COUNT=2",
+			wantTail: "FREQ=WEEKLY;BYDAY=TU;COUNT=1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := mustParse(t, tt.rule)
+			head, tail, kept, err := r.Split(tt.start, tt.at)
+			if err != nil {
+				t.Fatalf("ошибка: %v", err)
+			}
+			if kept != tt.wantKept || head.String() != tt.wantHead || tail.String() != tt.wantTail {
+				t.Fatalf("Split = %q, %q, %d; want %q, %q, %d",
+					head, tail, kept, tt.wantHead, tt.wantTail, tt.wantKept)
+			}
+
+			// голова и хвост вместе дают те же занятия, что и вся серия
+			all, _ := r.Dates(tt.start)
+			var got []time.Time
+			if kept > 0 {
+				got, err = head.Dates(tt.start)
+				if err != nil {
+					t.Fatalf("head.Dates: %v", err)
+				}
+			}
+			rest, err := tail.Dates(tt.at)
+			if err != nil {
+				t.Fatalf("tail.Dates: %v", err)
+			}
+			if got = append(got, rest...); !slices.Equal(got, all) {
+				t.Errorf("head+tail = %v, want %v", got, all)
+			}
+		})
+	}
+}
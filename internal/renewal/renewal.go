@@ -0,0 +1,47 @@
+// Package renewal — период продления абонемента.
+//
+// Продление начинается на следующий день после окончания текущего
+// абонемента и длится столько же, сколько он был оформлен: дни заморозок,
+// которыми его продлевали, не в счёт. Если текущий уже истёк, продление
+// начинается сегодня — задним числом дни не выдаются.
+package renewal
+
+import (
+	"time"
+
+	"fitness-center-manager/internal/checkin"
+	"fitness-center-manager/internal/models"
+)
+
+// maxMonths — до скольких месяцев распознаём «ровно N месяцев».
+const maxMonths = 36
+
+// Period — даты продления абонемента cur на день today; frozenDays —
+// сколько дней заморозок добавлено к его окончанию.
+func Period(cur models.Subscription, frozenDays int, today time.Time) (start, end time.Time) {
+	today = checkin.Day(today)
+	curStart, curEnd := checkin.Day(cur.StartDate), checkin.Day(cur.EndDate)
+
+	start = curEnd.AddDate(0, 0, 1)
+	if start.Before(today) {
+		start = today
+	}
+
+	// оформленный срок без заморозок
+	bookedEnd := curEnd.AddDate(0, 0, -frozenDays)
+	if bookedEnd.Before(curStart) {
+		bookedEnd = curStart
+	}
+	// «с 15.01 по 14.02» — месяц: продлеваем тоже на месяц, а не на 31 день
+	for n := 1; n <= maxMonths; n++ {
+		m := curStart.AddDate(0, n, -1)
+		if m.Equal(bookedEnd) {
+			return start, start.AddDate(0, n, -1)
+		}
+		if m.After(bookedEnd) {
+			break
+		}
+	}
+	days := int(bookedEnd.Sub(curStart).Hours()/24) + 1
+	return start, start.AddDate(0, 0, days-1)
+}
@@ -0,0 +1,124 @@
+package repair
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"fitness-center-manager/internal/ledger"
+	"fitness-center-manager/internal/models"
+)
+
+// Spend — итог затрат на ремонт по ключу отчёта (единица, зона или месяц).
+type Spend struct {
+	Key     string  `json:"key"` // id единицы/зоны или «2006-01»
+	Name    string  `json:"name"`
+	Labour  float64 `json:"labour"`
+	Parts   float64 `json:"parts"`
+	Total   float64 `json:"total"`
+	Repairs int     `json:"repairs"`
+}
+
+// spendSum копит суммы в копейках, чтобы не набегала ошибка float.
+type spendSum struct {
+	name          string
+	labour, parts int64
+	repairs       int
+}
+
+func group(rows []models.RepairSpend, key func(models.RepairSpend) (string, string)) []Spend {
+	sums := map[string]*spendSum{}
+	for _, r := range rows {
+		k, name := key(r)
+		s, ok := sums[k]
+		if !ok {
+			s = &spendSum{name: name}
+			sums[k] = s
+		}
+		s.labour += ledger.Cents(r.Labour)
+		s.parts += ledger.Cents(r.Parts)
+		s.repairs += r.Repairs
+	}
+	out := make([]Spend, 0, len(sums))
+	for k, s := range sums {
+		out = append(out, Spend{Key: k, Name: s.name, Labour: float64(s.labour) / 100, Parts: float64(s.parts) / 100,
+			Total: float64(s.labour+s.parts) / 100, Repairs: s.repairs})
+	}
+	return out
+}
+
+// byTotal — дорогие сверху, при равенстве — по названию.
+func byTotal(list []Spend) []Spend {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Total != list[j].Total {
+			return list[i].Total > list[j].Total
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// ByEquipment — затраты по единицам оборудования, дорогие сверху.
+func ByEquipment(rows []models.RepairSpend) []Spend {
+	return byTotal(group(rows, func(r models.RepairSpend) (string, string) {
+		return strconv.Itoa(r.EquipmentID), r.EquipmentName
+	}))
+}
+
+// ByZone — затраты по зонам, дорогие сверху.
+func ByZone(rows []models.RepairSpend) []Spend {
+	return byTotal(group(rows, func(r models.RepairSpend) (string, string) {
+		return strconv.Itoa(r.ZoneID), r.ZoneName
+	}))
+}
+
+// ByMonth — затраты по месяцам по порядку; ключ и название — «2006-01».
+func ByMonth(rows []models.RepairSpend) []Spend {
+	list := group(rows, func(r models.RepairSpend) (string, string) {
+		m := r.Month.Format("2006-01")
+		return m, m
+	})
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list
+}
+
+// ReplaceCandidate — единица, ремонт которой обошёлся не дешевле замены.
+type ReplaceCandidate struct {
+	EquipmentID     int     `json:"equipment_id"`
+	EquipmentName   string  `json:"equipment_name"`
+	ZoneName        string  `json:"zone_name"`
+	Spend           float64 `json:"spend"`            // затраты на ремонт за всё время
+	ReplacementCost float64 `json:"replacement_cost"` // стоимость замены новой
+	Ratio           float64 `json:"ratio"`            // Spend / ReplacementCost, %
+}
+
+// ReplaceCandidates — единицы с заданной стоимостью замены, на ремонт
+// которых за всё время (lifetime — строки Spend без ограничения периода)
+// ушло не меньше share этой стоимости (1 — вся стоимость); дорогие
+// относительно замены сверху. Списанное оборудование не учитывается.
+func ReplaceCandidates(equipment []models.Equipment, lifetime []models.RepairSpend, share float64) []ReplaceCandidate {
+	spend := map[string]float64{}
+	for _, s := range ByEquipment(lifetime) {
+		spend[s.Key] = s.Total
+	}
+	var out []ReplaceCandidate
+	for _, e := range equipment {
+		if e.ReplacementCost <= 0 || e.Status == "Списан" {
+			continue
+		}
+		total := spend[strconv.Itoa(e.ID)]
+		if total < e.ReplacementCost*share {
+			continue
+		}
+		out = append(out, ReplaceCandidate{EquipmentID: e.ID, EquipmentName: e.Name, ZoneName: e.ZoneName,
+			Spend: total, ReplacementCost: e.ReplacementCost, Ratio: math.Round(total/e.ReplacementCost*1000) / 10})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Ratio > out[j].Ratio })
+	return out
+}
+
+// Lifetime — период «за всё время» для Spend.
+func Lifetime() (from, to time.Time) {
+	return time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+}
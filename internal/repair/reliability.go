@@ -0,0 +1,198 @@
+package repair
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+// Reliability — показатели надёжности единицы оборудования или зоны за
+// период. Отказ — заявка, созданная в периоде; простой — время, когда по
+// единице была незакрытая заявка (пересекающиеся заявки не суммируются).
+type Reliability struct {
+	Key           string   `json:"key"` // id единицы или зоны
+	Name          string   `json:"name"`
+	ZoneName      string   `json:"zone_name,omitempty"`
+	Failures      int      `json:"failures"`
+	Repaired      int      `json:"repaired"`   // закрыто в периоде — по ним считается MTTR
+	MTBFHours     *float64 `json:"mtbf_hours"` // nil — отказов не было
+	MTTRHours     *float64 `json:"mttr_hours"` // nil — закрытых заявок не было
+	DowntimeHours float64  `json:"downtime_hours"`
+	Availability  float64  `json:"availability"` // доля времени без простоя, %
+	Unknown       int      `json:"unknown"`      // закрытые без времени закрытия — в простой и MTTR не входят
+}
+
+// span — полуинтервал [from, to).
+type span struct{ from, to time.Time }
+
+// acc — суммы для расчёта показателей единицы или зоны.
+type acc struct {
+	failures, repaired, unknown int
+	window, downtime, repair    time.Duration
+}
+
+func (a *acc) add(b acc) {
+	a.failures += b.failures
+	a.repaired += b.repaired
+	a.unknown += b.unknown
+	a.window += b.window
+	a.downtime += b.downtime
+	a.repair += b.repair
+}
+
+func hours(d time.Duration) float64 { return math.Round(d.Hours()*10) / 10 }
+
+func (a acc) result(key, name, zone string) Reliability {
+	r := Reliability{Key: key, Name: name, ZoneName: zone, Failures: a.failures, Repaired: a.repaired,
+		DowntimeHours: hours(a.downtime), Availability: 100, Unknown: a.unknown}
+	uptime := a.window - a.downtime
+	if a.failures > 0 {
+		v := hours(uptime / time.Duration(a.failures))
+		r.MTBFHours = &v
+	}
+	if a.repaired > 0 {
+		v := hours(a.repair / time.Duration(a.repaired))
+		r.MTTRHours = &v
+	}
+	if a.window > 0 {
+		r.Availability = math.Round(float64(uptime)/float64(a.window)*1000) / 10
+	}
+	return r
+}
+
+// downtime — суммарная длина объединения интервалов.
+func downtime(list []span) time.Duration {
+	sort.Slice(list, func(i, j int) bool { return list[i].from.Before(list[j].from) })
+	var total time.Duration
+	var cur span
+	for i, s := range list {
+		switch {
+		case i == 0:
+			cur = s
+		case !s.from.After(cur.to):
+			if s.to.After(cur.to) {
+				cur.to = s.to
+			}
+		default:
+			total += cur.to.Sub(cur.from)
+			cur = s
+		}
+	}
+	if len(list) > 0 {
+		total += cur.to.Sub(cur.from)
+	}
+	return total
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// Analyze считает надёжность за период [from, to) по единицам и зонам.
+// repairs — заявки, пересекающиеся с периодом; незакрытые простаивают до
+// now. Период единицы начинается не раньше даты покупки. Списанное
+// оборудование без отказов в периоде не выводится. Единицы — по числу
+// отказов и простою (худшие сверху), зоны — так же.
+func Analyze(equipment []models.Equipment, repairs []models.RepairRequest, from, to, now time.Time) (items, zones []Reliability) {
+	byEquipment := map[int][]models.RepairRequest{}
+	for _, r := range repairs {
+		byEquipment[r.EquipmentID] = append(byEquipment[r.EquipmentID], r)
+	}
+	end := minTime(to, now)
+	zoneAcc := map[int]*acc{}
+	zoneName := map[int]string{}
+	for _, e := range equipment {
+		start := from
+		if e.PurchaseDate.Valid {
+			start = maxTime(start, e.PurchaseDate.Time)
+		}
+		var a acc
+		if end.After(start) {
+			a.window = end.Sub(start)
+		}
+		var down []span
+		for _, r := range byEquipment[e.ID] {
+			failed := !r.CreateDate.Before(from) && r.CreateDate.Before(to)
+			if failed {
+				a.failures++
+			}
+			stop := now
+			switch {
+			case r.ClosedAt.Valid:
+				stop = r.ClosedAt.Time
+				if !stop.Before(from) && stop.Before(to) {
+					a.repaired++
+					a.repair += stop.Sub(r.CreateDate)
+				}
+			case r.Status == StatusClosed:
+				if failed {
+					a.unknown++
+				}
+				continue
+			}
+			if s := (span{maxTime(r.CreateDate, start), minTime(stop, end)}); s.to.After(s.from) {
+				down = append(down, s)
+			}
+		}
+		a.downtime = downtime(down)
+		if e.Status == "Списан" && a.failures == 0 {
+			continue
+		}
+		items = append(items, a.result(strconv.Itoa(e.ID), e.Name, e.ZoneName))
+		z, ok := zoneAcc[e.ZoneID]
+		if !ok {
+			z = &acc{}
+			zoneAcc[e.ZoneID] = z
+			zoneName[e.ZoneID] = e.ZoneName
+		}
+		z.add(a)
+	}
+	for id, a := range zoneAcc {
+		zones = append(zones, a.result(strconv.Itoa(id), zoneName[id], ""))
+	}
+	byFailures(items)
+	byFailures(zones)
+	return items, zones
+}
+
+// byFailures — больше отказов сверху, затем больше простой, затем по названию.
+func byFailures(list []Reliability) {
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Failures != b.Failures {
+			return a.Failures > b.Failures
+		}
+		if a.DowntimeHours != b.DowntimeHours {
+			return a.DowntimeHours > b.DowntimeHours
+		}
+		return a.Name < b.Name
+	})
+}
+
+// Worst — первые n единиц из отсортированного Analyze списка, у которых
+// были отказы или простой.
+func Worst(items []Reliability, n int) []Reliability {
+	out := []Reliability{}
+	for _, r := range items {
+		if len(out) == n {
+			break
+		}
+		if r.Failures > 0 || r.DowntimeHours > 0 {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,160 @@
+package repair
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func ptr(v float64) *float64 { return &v }
+
+// closed — заявка, закрытая в closedAt.
+func closed(id int, created, closedAt string) models.RepairRequest {
+	return models.RepairRequest{EquipmentID: id, CreateDate: at(created), Status: StatusClosed,
+		ClosedAt: sql.NullTime{Time: at(closedAt), Valid: true}}
+}
+
+func TestAnalyze(t *testing.T) {
+	// период — 10 суток, 240 ч
+	from, to := at("2026-10-01 00:00"), at("2026-10-11 00:00")
+	later := at("2026-10-20 00:00")
+	tests := []struct {
+		name     string
+		purchase string // пусто — дата покупки не задана
+		repairs  []models.RepairRequest
+		now      time.Time
+		want     Reliability
+	}{
+		{
+			name: "пересекающиеся простои не суммируются",
+			repairs: []models.RepairRequest{
+				closed(1, "2026-10-02 00:00", "2026-10-03 00:00"),
+				closed(1, "2026-10-02 12:00", "2026-10-04 00:00"),
+				closed(1, "2026-10-06 00:00", "2026-10-06 12:00"),
+			},
+			now: later,
+			// простой 48 + 12 ч; MTTR (24+36+12)/3; MTBF (240-60)/3
+			want: Reliability{Failures: 3, Repaired: 3, MTBFHours: ptr(60), MTTRHours: ptr(24),
+				DowntimeHours: 60, Availability: 75},
+		},
+		{
+			name: "вложенный простой",
+			repairs: []models.RepairRequest{
+				closed(1, "2026-10-02 00:00", "2026-10-05 00:00"),
+				closed(1, "2026-10-03 00:00", "2026-10-04 00:00"),
+			},
+			now: later,
+			want: Reliability{Failures: 2, Repaired: 2, MTBFHours: ptr(84), MTTRHours: ptr(48),
+				DowntimeHours: 72, Availability: 70},
+		},
+		{
+			name: "смежные простои",
+			repairs: []models.RepairRequest{
+				closed(1, "2026-10-02 00:00", "2026-10-03 00:00"),
+				closed(1, "2026-10-03 00:00", "2026-10-04 00:00"),
+			},
+			now: later,
+			want: Reliability{Failures: 2, Repaired: 2, MTBFHours: ptr(96), MTTRHours: ptr(24),
+				DowntimeHours: 48, Availability: 80},
+		},
+		{
+			name:    "незакрытая — простой до конца периода",
+			repairs: []models.RepairRequest{{EquipmentID: 1, CreateDate: at("2026-10-09 00:00"), Status: StatusInProgress}},
+			now:     later,
+			want:    Reliability{Failures: 1, MTBFHours: ptr(192), DowntimeHours: 48, Availability: 80},
+		},
+		{
+			name:    "незакрытая — период до now",
+			repairs: []models.RepairRequest{{EquipmentID: 1, CreateDate: at("2026-10-05 00:00"), Status: StatusOpen}},
+			now:     at("2026-10-06 00:00"),
+			want:    Reliability{Failures: 1, MTBFHours: ptr(96), DowntimeHours: 24, Availability: 80},
+		},
+		{
+			name:    "отказ до периода, ремонт в периоде",
+			repairs: []models.RepairRequest{closed(1, "2026-09-28 00:00", "2026-10-02 00:00")},
+			now:     later,
+			want:    Reliability{Repaired: 1, MTTRHours: ptr(96), DowntimeHours: 24, Availability: 90},
+		},
+		{
+			name:    "закрыта без времени закрытия",
+			repairs: []models.RepairRequest{{EquipmentID: 1, CreateDate: at("2026-10-03 00:00"), Status: StatusClosed}},
+			now:     later,
+			want:    Reliability{Failures: 1, MTBFHours: ptr(240), Availability: 100, Unknown: 1},
+		},
+		{
+			name:     "куплено в периоде",
+			purchase: "2026-10-06 00:00",
+			repairs:  []models.RepairRequest{closed(1, "2026-10-07 00:00", "2026-10-07 12:00")},
+			now:      later,
+			want: Reliability{Failures: 1, Repaired: 1, MTBFHours: ptr(108), MTTRHours: ptr(12),
+				DowntimeHours: 12, Availability: 90},
+		},
+		{
+			name: "без заявок",
+			now:  later,
+			want: Reliability{Availability: 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := models.Equipment{ID: 1, ZoneID: 1, Name: "Беговая дорожка", ZoneName: "Кардио"}
+			if tt.purchase != "" {
+				e.PurchaseDate = sql.NullTime{Time: at(tt.purchase), Valid: true}
+			}
+			items, _ := Analyze([]models.Equipment{e}, tt.repairs, from, to, tt.now)
+			want := tt.want
+			want.Key, want.Name, want.ZoneName = "1", e.Name, e.ZoneName
+			if len(items) != 1 || !reflect.DeepEqual(items[0], want) {
+				t.Errorf("Analyze = %+v, want %+v", items, want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeZones(t *testing.T) {
+	from, to := at("2026-10-01 00:00"), at("2026-10-11 00:00")
+	equipment := []models.Equipment{
+		{ID: 1, ZoneID: 7, Name: "Беговая дорожка", ZoneName: "Кардио"},
+		{ID: 2, ZoneID: 7, Name: "Велотренажёр", ZoneName: "Кардио"},
+		{ID: 3, ZoneID: 7, Name: "Степпер", ZoneName: "Кардио", Status: "Списан"},
+	}
+	repairs := []models.RepairRequest{
+		closed(2, "2026-10-02 00:00", "2026-10-04 00:00"),
+		closed(1, "2026-10-05 00:00", "2026-10-05 12:00"),
+		closed(1, "2026-10-07 00:00", "2026-10-07 12:00"),
+	}
+	items, zones := Analyze(equipment, repairs, from, to, at("2026-10-20 00:00"))
+
+	// списанный без отказов не выводится; больше отказов — выше
+	wantItems := []Reliability{
+		{Key: "1", Name: "Беговая дорожка", ZoneName: "Кардио", Failures: 2, Repaired: 2,
+			MTBFHours: ptr(108), MTTRHours: ptr(12), DowntimeHours: 24, Availability: 90},
+		{Key: "2", Name: "Велотренажёр", ZoneName: "Кардио", Failures: 1, Repaired: 1,
+			MTBFHours: ptr(192), MTTRHours: ptr(48), DowntimeHours: 48, Availability: 80},
+	}
+	if !reflect.DeepEqual(items, wantItems) {
+		t.Errorf("items = %+v, want %+v", items, wantItems)
+	}
+	// зона — суммы по единицам: 480 ч, простой 72 ч, 3 отказа
+	wantZones := []Reliability{
+		{Key: "7", Name: "Кардио", Failures: 3, Repaired: 3,
+			MTBFHours: ptr(136), MTTRHours: ptr(24), DowntimeHours: 72, Availability: 85},
+	}
+	if !reflect.DeepEqual(zones, wantZones) {
+		t.Errorf("zones = %+v, want %+v", zones, wantZones)
+	}
+	if w := Worst(items, 1); len(w) != 1 || w[0].Key != "1" {
+		t.Errorf("Worst = %+v, want единицу 1", w)
+	}
+}
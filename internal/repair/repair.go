@@ -0,0 +1,90 @@
+// Package repair — порядок работы с заявкой на ремонт: допустимые смены
+// статуса и срок устранения (SLA) по приоритету, а также сводка затрат на
+// ремонт и кандидаты на замену (cost.go) и показатели надёжности —
+// отказы, MTBF, MTTR и простой (reliability.go).
+//
+// Пакет не зависит от БД и HTTP: хэндлер проверяет переход и считает
+// срок, а store сохраняет заявку и её историю.
+package repair
+
+import (
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+// Статусы заявки.
+const (
+	StatusOpen       = "Открыта"
+	StatusInProgress = "В работе"
+	StatusClosed     = "Закрыта"
+)
+
+// Приоритеты заявки.
+const (
+	PriorityLow    = "Низкий"
+	PriorityMedium = "Средний"
+	PriorityHigh   = "Высокий"
+)
+
+// transitions — куда можно перевести заявку из статуса.
+var transitions = map[string][]string{
+	StatusOpen:       {StatusInProgress, StatusClosed},
+	StatusInProgress: {StatusOpen, StatusClosed},
+	StatusClosed:     {StatusOpen},
+}
+
+// Allowed — допустима ли смена статуса from → to.
+func Allowed(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Next — статусы, в которые можно перевести заявку из from.
+func Next(from string) []string { return transitions[from] }
+
+// NeedsComment — закрытие и переоткрытие заявки требуют комментария:
+// что сделано или почему вернули.
+func NeedsComment(from, to string) bool {
+	return to == StatusClosed || from == StatusClosed
+}
+
+// SLA — срок устранения по приоритету.
+type SLA map[string]time.Duration
+
+// DefaultSLA — сутки на высокий, трое суток на средний, неделя на низкий.
+var DefaultSLA = SLA{
+	PriorityHigh:   24 * time.Hour,
+	PriorityMedium: 72 * time.Hour,
+	PriorityLow:    168 * time.Hour,
+}
+
+// NewSLA — SLA из часов по приоритетам; 0 — значение по умолчанию.
+func NewSLA(high, medium, low int) SLA {
+	sla := SLA{}
+	for p, h := range map[string]int{PriorityHigh: high, PriorityMedium: medium, PriorityLow: low} {
+		sla[p] = DefaultSLA[p]
+		if h > 0 {
+			sla[p] = time.Duration(h) * time.Hour
+		}
+	}
+	return sla
+}
+
+// Deadline — срок устранения заявки с приоритетом priority, созданной в created.
+func (s SLA) Deadline(priority string, created time.Time) time.Time {
+	d, ok := s[priority]
+	if !ok {
+		d = s[PriorityMedium]
+	}
+	return created.Add(d)
+}
+
+// Overdue — незакрытая заявка, срок которой прошёл к моменту now.
+func Overdue(r models.RepairRequest, now time.Time) bool {
+	return r.Status != StatusClosed && now.After(r.Deadline)
+}
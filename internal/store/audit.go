@@ -0,0 +1,13 @@
+package store
+
+import (
+	"context"
+
+	"fitness-center-manager/internal/audit"
+)
+
+// AuditRepo — чтение журнала аудита (пишут его триггеры БД).
+type AuditRepo interface {
+	// List — страница журнала (новые сверху) и общее число записей по фильтру.
+	List(ctx context.Context, f audit.Filter) ([]audit.Entry, int, error)
+}
@@ -0,0 +1,32 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/access"
+	"fitness-center-manager/internal/models"
+)
+
+// AbsenceInput — отсутствие тренера (даты включительно).
+type AbsenceInput struct {
+	Kind      string // Отпуск | Больничный | Отгул
+	StartDate time.Time
+	EndDate   time.Time
+	Reason    string
+}
+
+// AvailabilityRepo — когда тренер работает: недельные рабочие часы и
+// отсутствия. Занятость тренировками — TrainingRepo.Conflicts.
+type AvailabilityRepo interface {
+	// Hours — рабочие часы тренера; пустые — без ограничений.
+	Hours(ctx context.Context, trainerID int) (access.Schedule, error)
+	SetHours(ctx context.Context, trainerID int, hours access.Schedule) error
+
+	// Absences — отсутствия тренера, задевающие дни [from, to]; нулевые
+	// границы — без ограничения с этой стороны.
+	Absences(ctx context.Context, trainerID int, from, to time.Time) ([]models.TrainerAbsence, error)
+	GetAbsence(ctx context.Context, id int) (models.TrainerAbsence, error)
+	CreateAbsence(ctx context.Context, trainerID int, in AbsenceInput) (int, error)
+	DeleteAbsence(ctx context.Context, id int) error
+}
@@ -0,0 +1,35 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+// Владельцы календарных ссылок.
+const (
+	FeedTrainer = "trainer"
+	FeedZone    = "zone"
+	FeedClient  = "client"
+)
+
+// CalendarRepo — календарные ссылки и тренировки для них.
+type CalendarRepo interface {
+	// Feed — действующая ссылка владельца.
+	Feed(ctx context.Context, kind string, ownerID int) (models.CalendarFeed, error)
+	// FeedByToken — ссылка по SHA-256 токена.
+	FeedByToken(ctx context.Context, tokenHash string) (models.CalendarFeed, error)
+	// SaveFeed заводит ссылку владельцу или заменяет токен действующей.
+	SaveFeed(ctx context.Context, kind string, ownerID int, tokenHash string) (models.CalendarFeed, error)
+	DeleteFeed(ctx context.Context, kind string, ownerID int) error
+	// Touch отмечает обращение к ссылке.
+	Touch(ctx context.Context, id int) error
+
+	// OwnerName — ФИО тренера или клиента, название зоны; ErrNotFound — нет такого.
+	OwnerName(ctx context.Context, kind string, ownerID int) (string, error)
+	// Events — тренировки владельца с началом в [from, to): тренера —
+	// групповые и персональные, зоны — групповые, клиента — его записи
+	// на групповые и персональные по всем его абонементам.
+	Events(ctx context.Context, kind string, ownerID int, from, to time.Time) ([]models.CalendarEvent, error)
+}
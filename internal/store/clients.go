@@ -0,0 +1,42 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+// ClientFilter — фильтры и страница списка клиентов.
+type ClientFilter struct {
+	Query       string // ФИО, телефон, номер карты или id
+	WithMedical bool   // только с медицинскими данными
+	Recent      bool   // зарегистрированы за последние 30 дней
+	Limit       int
+	Offset      int
+}
+
+// ClientInput — поля клиента, которые задаёт сотрудник.
+type ClientInput struct {
+	FIO         string
+	Phone       string
+	BirthDate   time.Time
+	MedicalData string
+	CardNumber  string // пусто — карта не выдана
+}
+
+// ClientRepo — клиенты.
+type ClientRepo interface {
+	// List — страница списка (с вычисляемыми полями) и общее число по фильтру.
+	List(ctx context.Context, f ClientFilter) ([]models.ClientEnriched, int, error)
+	Get(ctx context.Context, id int) (models.Client, error)
+	// FindByCard — клиент по номеру клубной карты.
+	FindByCard(ctx context.Context, card string) (models.Client, error)
+	Options(ctx context.Context) ([]Option, error)
+	// SubscriptionCount — сколько абонементов оформлено на клиента.
+	SubscriptionCount(ctx context.Context, id int) (int, error)
+
+	Create(ctx context.Context, in ClientInput) (int, error)
+	Update(ctx context.Context, id int, in ClientInput) error
+	Delete(ctx context.Context, id int) error
+}
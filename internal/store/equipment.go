@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+// EquipmentFilter — фильтры списка оборудования.
+type EquipmentFilter struct {
+	ZoneID   int
+	Status   string
+	HasPhoto *bool // nil — не важно
+}
+
+// EquipmentInput — поля единицы оборудования.
+type EquipmentInput struct {
+	ZoneID          int
+	Name            string
+	PurchaseDate    sql.NullTime
+	LastServiceDate sql.NullTime
+	Status          string
+	Type            string  // пусто — без типа
+	UsageHours      float64 // наработка, моточасы
+	ReplacementCost float64 // стоимость замены новой, 0 — не задана
+}
+
+// EquipmentRepo — оборудование.
+type EquipmentRepo interface {
+	List(ctx context.Context, f EquipmentFilter) ([]models.Equipment, error)
+	Get(ctx context.Context, id int) (models.Equipment, error)
+
+	Create(ctx context.Context, in EquipmentInput) (int, error)
+	Update(ctx context.Context, id int, in EquipmentInput) error
+	// Delete возвращает ErrInUse, если на оборудование есть заявки.
+	Delete(ctx context.Context, id int) error
+	SetStatus(ctx context.Context, id int, status string) error
+	// MarkServiced сдвигает "Дата_последнего_ТО" и наработку вперёд (не назад)
+	// до day и hours (hours.Valid == false — наработку не трогает).
+	MarkServiced(ctx context.Context, id int, day time.Time, hours sql.NullFloat64) error
+
+	Photo(ctx context.Context, id int) (Photo, error)
+	SetPhoto(ctx context.Context, id int, key string) error
+}
+
+// RepairInput — новая заявка на ремонт; статус ставит БД («Открыта»).
+type RepairInput struct {
+	EquipmentID int
+	Description string
+	Priority    string
+	PhotoKey    string    // ключ фото в хранилище файлов; "" — без фото
+	Deadline    time.Time // срок устранения по SLA приоритета
+}
+
+// RepairUpdate — изменение заявки; EquipmentID == 0 оставляет прежнее
+// оборудование. Статус меняется отдельно (SetStatus + история).
+type RepairUpdate struct {
+	EquipmentID int
+	Description string
+	Priority    string
+	Deadline    time.Time
+}
+
+// RepairFilter — фильтры списка заявок.
+type RepairFilter struct {
+	Active     bool      // только незакрытые
+	AssigneeID int       // 0 — любой исполнитель
+	OverdueAt  time.Time // не нулевое — только незакрытые с истёкшим к этому моменту сроком
+	// From, To — не нулевые: заявки, пересекающиеся с периодом [From, To),
+	// то есть созданные до To и не закрытые до From.
+	From, To time.Time
+	Limit    int // 0 — без ограничения
+}
+
+// RepairRepo — заявки на ремонт оборудования.
+type RepairRepo interface {
+	// Latest — последние заявки (новые сверху).
+	Latest(ctx context.Context, limit int) ([]models.RepairRequest, error)
+	// List — заявки по фильтру: незакрытые по сроку, затем закрытые (новые сверху).
+	List(ctx context.Context, f RepairFilter) ([]models.RepairRequest, error)
+	Get(ctx context.Context, id int) (models.RepairRequest, error)
+	// OpenCount — число незакрытых заявок по оборудованию.
+	OpenCount(ctx context.Context, equipmentID int) (int, error)
+
+	Create(ctx context.Context, in RepairInput) (int, error)
+	Update(ctx context.Context, id int, in RepairUpdate) error
+	// SetStatus меняет статус; "Дата_закрытия" ставится при закрытии и
+	// сбрасывается при переоткрытии.
+	SetStatus(ctx context.Context, id int, status string) error
+	// Assign назначает исполнителя (0 — снять).
+	Assign(ctx context.Context, id, staffID int) error
+	// SetCosts — подрядчик (0 — своими силами) и стоимость работ.
+	SetCosts(ctx context.Context, id, vendorID int, labour float64) error
+	Delete(ctx context.Context, id int) error
+
+	// Spend — затраты по оборудованию и месяцам за [from, to): работы по
+	// заявкам, созданным в периоде, и запчасти, списанные в нём.
+	Spend(ctx context.Context, from, to time.Time) ([]models.RepairSpend, error)
+
+	// History — история заявки по времени; AddEvent — новая запись.
+	History(ctx context.Context, id int) ([]models.RepairEvent, error)
+	AddEvent(ctx context.Context, e models.RepairEvent) error
+
+	Photo(ctx context.Context, id int) (Photo, error)
+	SetPhoto(ctx context.Context, id int, key string) error
+}
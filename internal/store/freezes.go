@@ -0,0 +1,31 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+// FreezeInput — период заморозки (даты включительно) и её причина.
+type FreezeInput struct {
+	StartDate time.Time
+	EndDate   time.Time
+	Reason    string
+}
+
+// FreezeRepo — заморозки абонементов. Изменения заморозки двигают
+// "Абонемент"."Дата_окончания" на разницу в днях, поэтому вызывать их
+// нужно в транзакции вместе с проверкой правил (пакет freeze).
+type FreezeRepo interface {
+	// ListBySubscription — заморозки абонемента по дате начала.
+	ListBySubscription(ctx context.Context, subscriptionID int) ([]models.Freeze, error)
+	Get(ctx context.Context, id int) (models.Freeze, error)
+
+	// Create добавляет заморозку и продлевает абонемент на её длительность.
+	Create(ctx context.Context, subscriptionID int, in FreezeInput) (int, error)
+	// SetEnd переносит окончание заморозки, сдвигая окончание абонемента.
+	SetEnd(ctx context.Context, id int, end time.Time) error
+	// Delete удаляет заморозку и возвращает абонементу её дни.
+	Delete(ctx context.Context, id int) error
+}
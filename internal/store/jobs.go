@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Статусы запуска задачи (CHECK в таблице "Запуск_задачи").
+const (
+	RunRunning = "Выполняется"
+	RunOK      = "Успешно"
+	RunFailed  = "Ошибка"
+)
+
+// JobRun — один запуск фоновой задачи.
+type JobRun struct {
+	ID         int64
+	Job        string
+	Host       string
+	StartedAt  time.Time
+	FinishedAt sql.NullTime
+	Status     string
+	Changed    int
+	Details    string
+	Error      string
+}
+
+// Duration — длительность завершённого запуска (0, пока идёт).
+func (r JobRun) Duration() time.Duration {
+	if !r.FinishedAt.Valid {
+		return 0
+	}
+	return r.FinishedAt.Time.Sub(r.StartedAt)
+}
+
+// JobRunRepo — журнал запусков фоновых задач.
+type JobRunRepo interface {
+	// Start записывает начало запуска и возвращает его id.
+	Start(ctx context.Context, job, host string) (int64, error)
+	// Finish закрывает запуск: status — RunOK или RunFailed.
+	Finish(ctx context.Context, id int64, status string, changed int, details, errText string) error
+	// Last — последний запуск каждой задачи.
+	Last(ctx context.Context) (map[string]JobRun, error)
+	// Recent — последние limit запусков всех задач.
+	Recent(ctx context.Context, limit int) ([]JobRun, error)
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+// MaintenancePlanInput — поля плана ТО: либо EquipmentID, либо EquipmentType.
+type MaintenancePlanInput struct {
+	Name          string
+	EquipmentID   int
+	EquipmentType string
+	IntervalDays  int // 0 — не задан
+	IntervalHours int // 0 — не задан
+	Checklist     []string
+}
+
+// MaintenanceInput — запись о проведённом ТО; PlanID 0 — внеплановое.
+type MaintenanceInput struct {
+	EquipmentID int
+	PlanID      int
+	Date        time.Time
+	UsageHours  sql.NullFloat64
+	Checklist   []models.ChecklistItem
+	Comment     string
+	StaffID     int
+	PerformedBy string
+}
+
+// MaintenanceRepo — планы ТО и история обслуживания оборудования.
+type MaintenanceRepo interface {
+	Plans(ctx context.Context) ([]models.MaintenancePlan, error)
+	Plan(ctx context.Context, id int) (models.MaintenancePlan, error)
+	CreatePlan(ctx context.Context, in MaintenancePlanInput) (int, error)
+	UpdatePlan(ctx context.Context, id int, in MaintenancePlanInput) error
+	// DeletePlan — история ТО по плану остаётся (без ссылки на план).
+	DeletePlan(ctx context.Context, id int) error
+
+	// Latest — последнее ТО по каждой паре план–единица (без внеплановых).
+	Latest(ctx context.Context) ([]models.MaintenanceRecord, error)
+	// History — ТО единицы оборудования, новые первыми.
+	History(ctx context.Context, equipmentID int) ([]models.MaintenanceRecord, error)
+	Log(ctx context.Context, in MaintenanceInput) (int, error)
+}
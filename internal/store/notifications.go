@@ -0,0 +1,24 @@
+package store
+
+import (
+	"context"
+
+	"fitness-center-manager/internal/models"
+)
+
+// NotificationFilter — отбор уведомлений: клиент (0 — все), только
+// недоставленные и сколько последних вернуть (0 — 100).
+type NotificationFilter struct {
+	ClientID int
+	Pending  bool
+	Limit    int
+}
+
+// NotificationRepo — исходящие уведомления клиентам.
+type NotificationRepo interface {
+	Create(ctx context.Context, clientID int, subject, text string) (int, error)
+	// List — уведомления, новые сверху.
+	List(ctx context.Context, f NotificationFilter) ([]models.Notification, error)
+	// MarkSent отмечает уведомление доставленным.
+	MarkSent(ctx context.Context, id int) error
+}
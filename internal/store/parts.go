@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+
+	"fitness-center-manager/internal/models"
+)
+
+// VendorInput — поля подрядчика.
+type VendorInput struct {
+	Name    string
+	Contact string
+	Phone   string
+	Email   string
+	Note    string
+}
+
+// VendorRepo — подрядчики и поставщики.
+type VendorRepo interface {
+	List(ctx context.Context) ([]models.Vendor, error)
+	Get(ctx context.Context, id int) (models.Vendor, error)
+	// Create и Update возвращают ErrDuplicate при совпадении названия.
+	Create(ctx context.Context, in VendorInput) (int, error)
+	Update(ctx context.Context, id int, in VendorInput) error
+	Delete(ctx context.Context, id int) error
+}
+
+// PartInput — карточка запчасти; остаток меняют Receive и расход.
+type PartInput struct {
+	Name         string
+	SKU          string // пусто — без артикула
+	Unit         string
+	ReorderLevel float64
+	Price        float64
+	VendorID     int // 0 — поставщик не указан
+}
+
+// PartRepo — склад запчастей и их расход на заявки.
+type PartRepo interface {
+	List(ctx context.Context) ([]models.SparePart, error)
+	Get(ctx context.Context, id int) (models.SparePart, error)
+	// Create и Update возвращают ErrDuplicate при совпадении артикула.
+	Create(ctx context.Context, in PartInput) (int, error)
+	Update(ctx context.Context, id int, in PartInput) error
+	// Delete возвращает ErrInUse, если запчасть уже списывали на заявки.
+	Delete(ctx context.Context, id int) error
+	// Receive — приход на склад: остаток увеличивается на qty.
+	Receive(ctx context.Context, id int, qty float64) error
+
+	// Usage — расход запчастей по заявке.
+	Usage(ctx context.Context, repairID int) ([]models.PartUsage, error)
+	// Consume списывает qty запчасти на заявку по текущей цене;
+	// ErrOutOfStock, если на складе меньше.
+	Consume(ctx context.Context, repairID, partID int, qty float64) (int, error)
+	// Return отменяет расход: строка удаляется, остаток возвращается.
+	Return(ctx context.Context, usageID int) (models.PartUsage, error)
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+// PaymentInput — новый платёж: ровно одно из SubscriptionID и
+// PersonalTrainingID. Cashier — логин сотрудника, принявшего деньги.
+type PaymentInput struct {
+	ClientID           int
+	SubscriptionID     int
+	PersonalTrainingID int
+	Kind               string // ledger.KindPayment / ledger.KindRefund
+	Amount             float64
+	Method             string
+	StaffID            int
+	Cashier            string
+	Comment            string
+}
+
+// Виды позиций к оплате (Due.Item).
+const (
+	DueSubscription = "subscription"
+	DuePersonal     = "personal_training"
+)
+
+// Due — позиция к оплате: абонемент или персональная тренировка клиента.
+// Отменённая тренировка стоит 0 — оплату по ней можно только вернуть.
+type Due struct {
+	Item       string    `json:"item"`
+	ID         int       `json:"id"`
+	ClientID   int       `json:"client_id"`
+	ClientName string    `json:"client_name"`
+	Title      string    `json:"title"`
+	Date       time.Time `json:"date"`
+	Price      float64   `json:"price"`
+	Paid       float64   `json:"paid"`
+}
+
+// DueFilter — отбор позиций: клиент (0 — все) и только неоплаченные.
+type DueFilter struct {
+	ClientID  int
+	OnlyDebts bool
+}
+
+// CashTotal — деньги за день по одному способу оплаты.
+type CashTotal struct {
+	Day      time.Time
+	Method   string
+	Count    int
+	Received float64
+	Refunded float64
+}
+
+// PaymentRepo — журнал платежей. Платежи только добавляются: ошибочную
+// оплату исправляют возвратом.
+type PaymentRepo interface {
+	ListBySubscription(ctx context.Context, subscriptionID int) ([]models.Payment, error)
+	ListByPersonalTraining(ctx context.Context, personalTrainingID int) ([]models.Payment, error)
+	ListByClient(ctx context.Context, clientID int) ([]models.Payment, error)
+	Create(ctx context.Context, in PaymentInput) (int, error)
+
+	// Dues — цены позиций и сколько по ним оплачено, по клиентам и дате.
+	Dues(ctx context.Context, f DueFilter) ([]Due, error)
+	// CashTotals — поступления и возвраты по дням и способам оплаты,
+	// даты from..to включительно.
+	CashTotals(ctx context.Context, from, to time.Time) ([]CashTotal, error)
+}
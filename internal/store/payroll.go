@@ -0,0 +1,31 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+// PayrollRepo — правила оплаты тренеров, их работа за период и закрытые
+// расчётные месяцы.
+type PayrollRepo interface {
+	// Rules — все тренеры с их правилами (Configured == false — правил нет), по ФИО.
+	Rules(ctx context.Context) ([]models.PayRule, error)
+	// Rule — правила тренера; неизвестный тренер — ErrNotFound.
+	Rule(ctx context.Context, trainerID int) (models.PayRule, error)
+	SetRule(ctx context.Context, rule models.PayRule) error
+
+	// Work — групповые тренера, начавшиеся в [from, to), с числом отметок
+	// «Посетил», и его завершённые персональные за тот же период.
+	Work(ctx context.Context, trainerID int, from, to time.Time) ([]models.PayrollGroup, []models.PayrollPersonal, error)
+
+	// Period — закрытый месяц; ещё открытый — ErrNotFound.
+	Period(ctx context.Context, month time.Time) (models.PayrollPeriod, error)
+	// ClosePeriod закрывает месяц со строками lines; уже закрыт — ErrDuplicate.
+	ClosePeriod(ctx context.Context, month time.Time, lines []models.PayrollLine) (models.PayrollPeriod, error)
+	// ReopenPeriod удаляет закрытие месяца вместе с его строками.
+	ReopenPeriod(ctx context.Context, month time.Time) error
+	// ClosedLines — строки закрытого месяца; trainerID == 0 — всех тренеров.
+	ClosedLines(ctx context.Context, periodID, trainerID int) ([]models.PayrollLine, error)
+}
@@ -0,0 +1,13 @@
+package pgstore
+
+import (
+	"context"
+
+	"fitness-center-manager/internal/audit"
+)
+
+type auditRepo struct{ q querier }
+
+func (r auditRepo) List(ctx context.Context, f audit.Filter) ([]audit.Entry, int, error) {
+	return audit.List(ctx, r.q, f)
+}
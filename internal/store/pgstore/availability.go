@@ -0,0 +1,79 @@
+package pgstore
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/access"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type availabilityRepo struct{ q querier }
+
+func (r availabilityRepo) Hours(ctx context.Context, trainerID int) (access.Schedule, error) {
+	var hours access.Schedule
+	err := r.q.QueryRowContext(ctx,
+		`SELECT "Рабочие_часы" FROM "Тренер" WHERE "id_тренера"=$1`, trainerID).Scan(&hours)
+	return hours, wrapErr(err)
+}
+
+func (r availabilityRepo) SetHours(ctx context.Context, trainerID int, hours access.Schedule) error {
+	return mustAffect(r.q.ExecContext(ctx,
+		`UPDATE "Тренер" SET "Рабочие_часы"=$2 WHERE "id_тренера"=$1`, trainerID, hours))
+}
+
+const absenceSelect = `
+    SELECT "id_отсутствия", "id_тренера", "Тип", "Дата_начала", "Дата_окончания",
+           COALESCE("Причина",''), "Создано"
+    FROM "Отсутствие_тренера"`
+
+func scanAbsence(row scanner) (models.TrainerAbsence, error) {
+	var a models.TrainerAbsence
+	err := row.Scan(&a.ID, &a.TrainerID, &a.Kind, &a.StartDate, &a.EndDate, &a.Reason, &a.CreatedAt)
+	return a, err
+}
+
+func (r availabilityRepo) Absences(ctx context.Context, trainerID int, from, to time.Time) ([]models.TrainerAbsence, error) {
+	w := where{}
+	w.add(`"id_тренера" = ` + w.ph(trainerID))
+	if !from.IsZero() {
+		w.add(`"Дата_окончания" >= ` + w.ph(from))
+	}
+	if !to.IsZero() {
+		w.add(`"Дата_начала" <= ` + w.ph(to))
+	}
+	rows, err := r.q.QueryContext(ctx, absenceSelect+w.sql()+` ORDER BY "Дата_начала"`, w.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.TrainerAbsence
+	for rows.Next() {
+		a, err := scanAbsence(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+func (r availabilityRepo) GetAbsence(ctx context.Context, id int) (models.TrainerAbsence, error) {
+	a, err := scanAbsence(r.q.QueryRowContext(ctx, absenceSelect+` WHERE "id_отсутствия"=$1`, id))
+	return a, wrapErr(err)
+}
+
+func (r availabilityRepo) CreateAbsence(ctx context.Context, trainerID int, in store.AbsenceInput) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Отсутствие_тренера" ("id_тренера","Тип","Дата_начала","Дата_окончания","Причина")
+        VALUES ($1,$2,$3,$4,$5)
+        RETURNING "id_отсутствия"
+    `, trainerID, in.Kind, in.StartDate, in.EndDate, nullIfEmpty(in.Reason)).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r availabilityRepo) DeleteAbsence(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Отсутствие_тренера" WHERE "id_отсутствия"=$1`, id))
+}
@@ -0,0 +1,148 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type calendarRepo struct{ q querier }
+
+const feedSelect = `
+    SELECT "id_ссылки", "Тип", "id_владельца", "Создана", "Последний_доступ"
+    FROM "Календарная_ссылка"`
+
+func scanFeed(row scanner) (models.CalendarFeed, error) {
+	var f models.CalendarFeed
+	err := row.Scan(&f.ID, &f.Kind, &f.OwnerID, &f.CreatedAt, &f.LastAccess)
+	return f, err
+}
+
+func (r calendarRepo) Feed(ctx context.Context, kind string, ownerID int) (models.CalendarFeed, error) {
+	f, err := scanFeed(r.q.QueryRowContext(ctx, feedSelect+` WHERE "Тип"=$1 AND "id_владельца"=$2`, kind, ownerID))
+	return f, wrapErr(err)
+}
+
+func (r calendarRepo) FeedByToken(ctx context.Context, tokenHash string) (models.CalendarFeed, error) {
+	f, err := scanFeed(r.q.QueryRowContext(ctx, feedSelect+` WHERE "Токен_хеш"=$1`, tokenHash))
+	return f, wrapErr(err)
+}
+
+func (r calendarRepo) SaveFeed(ctx context.Context, kind string, ownerID int, tokenHash string) (models.CalendarFeed, error) {
+	f, err := scanFeed(r.q.QueryRowContext(ctx, `
+        INSERT INTO "Календарная_ссылка" ("Тип","id_владельца","Токен_хеш") VALUES ($1,$2,$3)
+        ON CONFLICT ("Тип","id_владельца") DO UPDATE
+            SET "Токен_хеш"=EXCLUDED."Токен_хеш", "Создана"=NOW(), "Последний_доступ"=NULL
+        RETURNING "id_ссылки", "Тип", "id_владельца", "Создана", "Последний_доступ"
+    `, kind, ownerID, tokenHash))
+	return f, wrapErr(err)
+}
+
+func (r calendarRepo) DeleteFeed(ctx context.Context, kind string, ownerID int) error {
+	return mustAffect(r.q.ExecContext(ctx,
+		`DELETE FROM "Календарная_ссылка" WHERE "Тип"=$1 AND "id_владельца"=$2`, kind, ownerID))
+}
+
+func (r calendarRepo) Touch(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx,
+		`UPDATE "Календарная_ссылка" SET "Последний_доступ"=NOW() WHERE "id_ссылки"=$1`, id))
+}
+
+func (r calendarRepo) OwnerName(ctx context.Context, kind string, ownerID int) (string, error) {
+	var query string
+	switch kind {
+	case store.FeedTrainer:
+		query = `SELECT "ФИО" FROM "Тренер" WHERE "id_тренера"=$1`
+	case store.FeedZone:
+		query = `SELECT "Название" FROM "Зона" WHERE "id_зоны"=$1`
+	case store.FeedClient:
+		query = `SELECT "ФИО" FROM "Клиент" WHERE "id_клиента"=$1`
+	default:
+		return "", fmt.Errorf("calendar: неизвестный владелец %q", kind)
+	}
+	var name string
+	err := r.q.QueryRowContext(ctx, query, ownerID).Scan(&name)
+	return name, wrapErr(err)
+}
+
+// revisions — число правок и время последнего изменения строки по журналу
+// аудита (для SEQUENCE и LAST-MODIFIED); %s — условие на записи журнала.
+const revisions = `
+        CROSS JOIN LATERAL (
+            SELECT COUNT(*) FILTER (WHERE a."Действие"='update') AS n, MAX(a."Время") AS at
+            FROM "Журнал_аудита" a WHERE %s
+        ) rev`
+
+const groupRevisions = `a."Сущность"='group_training' AND a."id_сущности"=g."id_групповой_тренировки"`
+
+const personalRevisions = `a."Сущность"='personal_training' AND a."id_сущности"=p."id_персональной_тренировки"`
+
+// calendarGroups — групповые; $1 — владелец, $2/$3 — период.
+var calendarGroups = `
+        SELECT 'group', g."id_групповой_тренировки", g."Название", COALESCE(g."Описание",''),
+               g."Время_начала", g."Время_окончания", t."ФИО", z."Название", '', rev.n, rev.at
+        FROM "Групповая_тренировка" g
+        JOIN "Тренер" t ON t."id_тренера" = g."id_тренера"
+        JOIN "Зона"   z ON z."id_зоны"    = g."id_зоны"` + fmt.Sprintf(revisions, groupRevisions)
+
+// calendarPersonal — персональные с клиентом в названии.
+var calendarPersonal = `
+        SELECT 'personal', p."id_персональной_тренировки", 'Персональная: ' || c."ФИО", '',
+               p."Время_начала", p."Время_окончания", t."ФИО", '', p."Статус", rev.n, rev.at
+        FROM "Персональная_тренировка" p
+        JOIN "Тренер"    t ON t."id_тренера"    = p."id_тренера"
+        JOIN "Абонемент" s ON s."id_абонемента" = p."id_абонемента"
+        JOIN "Клиент"    c ON c."id_клиента"    = s."id_клиента"` + fmt.Sprintf(revisions, personalRevisions)
+
+const inPeriod = ` AND %[1]s."Время_начала" >= $2 AND %[1]s."Время_начала" < $3`
+
+func (r calendarRepo) Events(ctx context.Context, kind string, ownerID int, from, to time.Time) ([]models.CalendarEvent, error) {
+	var query string
+	switch kind {
+	case store.FeedTrainer:
+		query = calendarGroups + ` WHERE g."id_тренера" = $1` + fmt.Sprintf(inPeriod, "g") +
+			` UNION ALL ` + calendarPersonal + ` WHERE p."id_тренера" = $1` + fmt.Sprintf(inPeriod, "p")
+	case store.FeedZone:
+		query = calendarGroups + ` WHERE g."id_зоны" = $1` + fmt.Sprintf(inPeriod, "g")
+	case store.FeedClient:
+		// статус — запись клиента; правки записи тоже меняют событие
+		query = `
+        SELECT 'group', g."id_групповой_тренировки", g."Название", COALESCE(g."Описание",''),
+               g."Время_начала", g."Время_окончания", t."ФИО", z."Название", e."Статус", rev.n, rev.at
+        FROM "Запись_на_групповую_тренировку" e
+        JOIN "Абонемент" s ON s."id_абонемента" = e."id_абонемента"
+        JOIN "Групповая_тренировка" g ON g."id_групповой_тренировки" = e."id_групповой_тренировки"
+        JOIN "Тренер" t ON t."id_тренера" = g."id_тренера"
+        JOIN "Зона"   z ON z."id_зоны"    = g."id_зоны"` +
+			fmt.Sprintf(revisions, `(`+groupRevisions+`) OR (a."Сущность"='enrollment' AND a."id_сущности"=e."id_записи")`) + `
+        WHERE s."id_клиента" = $1` + fmt.Sprintf(inPeriod, "g") + `
+        UNION ALL
+        SELECT 'personal', p."id_персональной_тренировки", 'Персональная тренировка', '',
+               p."Время_начала", p."Время_окончания", t."ФИО", '', p."Статус", rev.n, rev.at
+        FROM "Персональная_тренировка" p
+        JOIN "Тренер"    t ON t."id_тренера"    = p."id_тренера"
+        JOIN "Абонемент" s ON s."id_абонемента" = p."id_абонемента"` + fmt.Sprintf(revisions, personalRevisions) + `
+        WHERE s."id_клиента" = $1` + fmt.Sprintf(inPeriod, "p")
+	default:
+		return nil, fmt.Errorf("calendar: неизвестный владелец %q", kind)
+	}
+
+	rows, err := r.q.QueryContext(ctx, query+` ORDER BY 5, 2`, ownerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.CalendarEvent
+	for rows.Next() {
+		var e models.CalendarEvent
+		if err := rows.Scan(&e.Kind, &e.ID, &e.Title, &e.Description, &e.StartTime, &e.EndTime,
+			&e.TrainerName, &e.ZoneName, &e.Status, &e.Revisions, &e.Modified); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,130 @@
+package pgstore
+
+import (
+	"context"
+
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type clientRepo struct{ q querier }
+
+const clientSelect = `
+    SELECT
+        v."id_клиента",
+        v."ФИО",
+        v."Номер_телефона",
+        c."Дата_рождения",
+        c."Дата_регистрации",
+        c."Медицинские_данные",
+        v.age,
+        COALESCE(v.subs_total, 0) AS subscriptions_count,
+        CASE WHEN v.subs_active > 0 THEN 'Активен' ELSE 'Неактивен' END AS active_status
+    FROM public.view_client_enriched v
+    JOIN public."Клиент" c USING ("id_клиента")`
+
+func (r clientRepo) List(ctx context.Context, f store.ClientFilter) ([]models.ClientEnriched, int, error) {
+	var w where
+	if f.Query != "" {
+		like := "%" + f.Query + "%"
+		w.add(`(v."ФИО" ILIKE ` + w.ph(like) +
+			` OR v."Номер_телефона" ILIKE ` + w.ph(like) +
+			` OR c."Номер_карты" ILIKE ` + w.ph(like) +
+			` OR CAST(v."id_клиента" AS TEXT) ILIKE ` + w.ph(like) + `)`)
+	}
+	if f.WithMedical {
+		w.add(`NULLIF(c."Медицинские_данные", '') IS NOT NULL`)
+	}
+	if f.Recent {
+		w.add(`c."Дата_регистрации" >= NOW()::date - INTERVAL '30 days'`)
+	}
+
+	var total int
+	if err := r.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM (`+clientSelect+w.sql()+`) t`, w.args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := clientSelect + w.sql() + ` ORDER BY v."ФИО" LIMIT ` + w.ph(f.Limit) + ` OFFSET ` + w.ph(f.Offset)
+	rows, err := r.q.QueryContext(ctx, query, w.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var list []models.ClientEnriched
+	for rows.Next() {
+		var cl models.ClientEnriched
+		if err := rows.Scan(&cl.ID, &cl.FIO, &cl.Phone, &cl.BirthDate, &cl.RegisterDate,
+			&cl.MedicalData, &cl.Age, &cl.SubscriptionsCnt, &cl.ActiveStatus); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, cl)
+	}
+	return list, total, rows.Err()
+}
+
+const clientRowSelect = `
+    SELECT "id_клиента", "ФИО", "Номер_телефона", "Дата_рождения", "Дата_регистрации",
+           "Медицинские_данные", "Номер_карты"
+    FROM "Клиент"`
+
+func scanClient(row scanner) (models.Client, error) {
+	var cl models.Client
+	err := row.Scan(&cl.ID, &cl.FIO, &cl.Phone, &cl.BirthDate, &cl.RegisterDate, &cl.MedicalData, &cl.CardNumber)
+	return cl, err
+}
+
+func (r clientRepo) Get(ctx context.Context, id int) (models.Client, error) {
+	cl, err := scanClient(r.q.QueryRowContext(ctx, clientRowSelect+` WHERE "id_клиента" = $1`, id))
+	return cl, wrapErr(err)
+}
+
+func (r clientRepo) FindByCard(ctx context.Context, card string) (models.Client, error) {
+	cl, err := scanClient(r.q.QueryRowContext(ctx, clientRowSelect+` WHERE "Номер_карты" = $1`, card))
+	return cl, wrapErr(err)
+}
+
+func (r clientRepo) Options(ctx context.Context) ([]store.Option, error) {
+	rows, err := r.q.QueryContext(ctx, `SELECT "id_клиента","ФИО" FROM "Клиент" ORDER BY "id_клиента"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.Option
+	for rows.Next() {
+		var o store.Option
+		if err := rows.Scan(&o.ID, &o.Name); err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
+func (r clientRepo) SubscriptionCount(ctx context.Context, id int) (int, error) {
+	var n int
+	err := r.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM "Абонемент" WHERE "id_клиента" = $1`, id).Scan(&n)
+	return n, err
+}
+
+func (r clientRepo) Create(ctx context.Context, in store.ClientInput) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Клиент" ("ФИО", "Номер_телефона", "Дата_рождения", "Медицинские_данные", "Номер_карты")
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING "id_клиента"
+    `, in.FIO, in.Phone, in.BirthDate, in.MedicalData, nullIfEmpty(in.CardNumber)).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r clientRepo) Update(ctx context.Context, id int, in store.ClientInput) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Клиент"
+        SET "ФИО" = $1, "Номер_телефона" = $2, "Дата_рождения" = $3, "Медицинские_данные" = $4,
+            "Номер_карты" = $5
+        WHERE "id_клиента" = $6
+    `, in.FIO, in.Phone, in.BirthDate, in.MedicalData, nullIfEmpty(in.CardNumber), id))
+}
+
+func (r clientRepo) Delete(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Клиент" WHERE "id_клиента" = $1`, id))
+}
@@ -0,0 +1,116 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type equipmentRepo struct{ q querier }
+
+const equipmentSelect = `
+    SELECT e."id_оборудования",
+           e."id_зоны",
+           e."Название",
+           e."Дата_покупки",
+           e."Дата_последнего_ТО",
+           e."Статус",
+           (e."Фото_ключ" IS NOT NULL OR e."Фото" IS NOT NULL) AS has_photo,
+           z."Название" AS zone_name,
+           COALESCE(e."Тип", ''),
+           e."Наработка_часов",
+           COALESCE(e."Стоимость_замены", 0)
+    FROM "Оборудование" e
+    JOIN "Зона" z ON z."id_зоны" = e."id_зоны"`
+
+func scanEquipment(row scanner) (models.Equipment, error) {
+	var e models.Equipment
+	err := row.Scan(&e.ID, &e.ZoneID, &e.Name, &e.PurchaseDate, &e.LastServiceDate, &e.Status, &e.HasPhoto, &e.ZoneName,
+		&e.Type, &e.UsageHours, &e.ReplacementCost)
+	return e, err
+}
+
+func (r equipmentRepo) List(ctx context.Context, f store.EquipmentFilter) ([]models.Equipment, error) {
+	var w where
+	if f.ZoneID > 0 {
+		w.add(`e."id_зоны" = ` + w.ph(f.ZoneID))
+	}
+	if f.Status != "" {
+		w.add(`e."Статус" = ` + w.ph(f.Status))
+	}
+	if f.HasPhoto != nil {
+		if *f.HasPhoto {
+			w.add(`(e."Фото_ключ" IS NOT NULL OR e."Фото" IS NOT NULL)`)
+		} else {
+			w.add(`e."Фото_ключ" IS NULL AND e."Фото" IS NULL`)
+		}
+	}
+	rows, err := r.q.QueryContext(ctx, equipmentSelect+w.sql()+` ORDER BY e."id_оборудования"`, w.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.Equipment
+	for rows.Next() {
+		e, err := scanEquipment(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+func (r equipmentRepo) Get(ctx context.Context, id int) (models.Equipment, error) {
+	e, err := scanEquipment(r.q.QueryRowContext(ctx, equipmentSelect+` WHERE e."id_оборудования"=$1`, id))
+	return e, wrapErr(err)
+}
+
+func (r equipmentRepo) Create(ctx context.Context, in store.EquipmentInput) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Оборудование" ("id_зоны","Название","Дата_покупки","Дата_последнего_ТО","Статус","Тип","Наработка_часов","Стоимость_замены")
+        VALUES ($1,$2,$3,$4,$5,$6,$7,NULLIF($8::numeric,0))
+        RETURNING "id_оборудования"
+    `, in.ZoneID, in.Name, nullableTime(in.PurchaseDate), nullableTime(in.LastServiceDate), in.Status,
+		nullIfEmpty(in.Type), in.UsageHours, in.ReplacementCost).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r equipmentRepo) Update(ctx context.Context, id int, in store.EquipmentInput) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Оборудование"
+        SET "id_зоны"=$2, "Название"=$3, "Дата_покупки"=$4, "Дата_последнего_ТО"=$5, "Статус"=$6,
+            "Тип"=$7, "Наработка_часов"=$8, "Стоимость_замены"=NULLIF($9::numeric,0)
+        WHERE "id_оборудования"=$1
+    `, id, in.ZoneID, in.Name, nullableTime(in.PurchaseDate), nullableTime(in.LastServiceDate), in.Status,
+		nullIfEmpty(in.Type), in.UsageHours, in.ReplacementCost))
+}
+
+func (r equipmentRepo) Delete(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Оборудование" WHERE "id_оборудования"=$1`, id))
+}
+
+func (r equipmentRepo) SetStatus(ctx context.Context, id int, status string) error {
+	return mustAffect(r.q.ExecContext(ctx, `UPDATE "Оборудование" SET "Статус"=$2 WHERE "id_оборудования"=$1`, id, status))
+}
+
+func (r equipmentRepo) MarkServiced(ctx context.Context, id int, day time.Time, hours sql.NullFloat64) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Оборудование"
+        SET "Дата_последнего_ТО" = GREATEST("Дата_последнего_ТО", $2::date),
+            "Наработка_часов"    = GREATEST("Наработка_часов", COALESCE($3::numeric, 0))
+        WHERE "id_оборудования"=$1
+    `, id, day, nullableFloat64(hours)))
+}
+
+func (r equipmentRepo) Photo(ctx context.Context, id int) (store.Photo, error) {
+	return photo(ctx, r.q, "Оборудование", "id_оборудования", id)
+}
+
+func (r equipmentRepo) SetPhoto(ctx context.Context, id int, key string) error {
+	return setPhoto(ctx, r.q, "Оборудование", "id_оборудования", id, key)
+}
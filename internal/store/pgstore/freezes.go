@@ -0,0 +1,93 @@
+package pgstore
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type freezeRepo struct{ q querier }
+
+const freezeSelect = `
+    SELECT "id_заморозки", "id_абонемента", "Дата_начала", "Дата_окончания", "Причина", "Создана"
+    FROM "Заморозка_абонемента"`
+
+func scanFreeze(row scanner) (models.Freeze, error) {
+	var f models.Freeze
+	err := row.Scan(&f.ID, &f.SubscriptionID, &f.StartDate, &f.EndDate, &f.Reason, &f.CreatedAt)
+	return f, err
+}
+
+func (r freezeRepo) ListBySubscription(ctx context.Context, subscriptionID int) ([]models.Freeze, error) {
+	rows, err := r.q.QueryContext(ctx, freezeSelect+` WHERE "id_абонемента"=$1 ORDER BY "Дата_начала"`, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.Freeze
+	for rows.Next() {
+		f, err := scanFreeze(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, f)
+	}
+	return list, rows.Err()
+}
+
+func (r freezeRepo) Get(ctx context.Context, id int) (models.Freeze, error) {
+	f, err := scanFreeze(r.q.QueryRowContext(ctx, freezeSelect+` WHERE "id_заморозки"=$1`, id))
+	return f, wrapErr(err)
+}
+
+// shift двигает окончание абонемента на days дней (отрицательное — назад).
+func (r freezeRepo) shift(ctx context.Context, subscriptionID, days int) error {
+	if days == 0 {
+		return nil
+	}
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Абонемент" SET "Дата_окончания" = "Дата_окончания" + $2::int
+        WHERE "id_абонемента"=$1
+    `, subscriptionID, days))
+}
+
+func (r freezeRepo) Create(ctx context.Context, subscriptionID int, in store.FreezeInput) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Заморозка_абонемента" ("id_абонемента","Дата_начала","Дата_окончания","Причина")
+        VALUES ($1,$2,$3,$4)
+        RETURNING "id_заморозки"
+    `, subscriptionID, in.StartDate, in.EndDate, in.Reason).Scan(&id)
+	if err != nil {
+		return 0, wrapErr(err)
+	}
+	f := models.Freeze{StartDate: in.StartDate, EndDate: in.EndDate}
+	return id, r.shift(ctx, subscriptionID, f.Days())
+}
+
+func (r freezeRepo) SetEnd(ctx context.Context, id int, end time.Time) error {
+	f, err := r.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := mustAffect(r.q.ExecContext(ctx,
+		`UPDATE "Заморозка_абонемента" SET "Дата_окончания"=$2 WHERE "id_заморозки"=$1`, id, end)); err != nil {
+		return err
+	}
+	was := f.Days()
+	f.EndDate = end
+	return r.shift(ctx, f.SubscriptionID, f.Days()-was)
+}
+
+func (r freezeRepo) Delete(ctx context.Context, id int) error {
+	f, err := r.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Заморозка_абонемента" WHERE "id_заморозки"=$1`, id)); err != nil {
+		return err
+	}
+	return r.shift(ctx, f.SubscriptionID, -f.Days())
+}
@@ -0,0 +1,103 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"fitness-center-manager/internal/store"
+)
+
+type jobRunRepo struct{ q querier }
+
+const jobRunSelect = `
+    SELECT "id_запуска", "Задача", "Узел", "Начало", "Окончание", "Статус", "Изменено", "Подробности", "Ошибка"
+    FROM "Запуск_задачи"`
+
+func scanJobRun(row scanner) (store.JobRun, error) {
+	var r store.JobRun
+	err := row.Scan(&r.ID, &r.Job, &r.Host, &r.StartedAt, &r.FinishedAt, &r.Status, &r.Changed, &r.Details, &r.Error)
+	return r, err
+}
+
+func (r jobRunRepo) list(ctx context.Context, query string, args ...any) ([]store.JobRun, error) {
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.JobRun
+	for rows.Next() {
+		run, err := scanJobRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, run)
+	}
+	return list, rows.Err()
+}
+
+func (r jobRunRepo) Start(ctx context.Context, job, host string) (int64, error) {
+	var id int64
+	err := r.q.QueryRowContext(ctx,
+		`INSERT INTO "Запуск_задачи" ("Задача","Узел") VALUES ($1,$2) RETURNING "id_запуска"`,
+		job, host).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r jobRunRepo) Finish(ctx context.Context, id int64, status string, changed int, details, errText string) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Запуск_задачи"
+        SET "Окончание"=NOW(), "Статус"=$2, "Изменено"=$3, "Подробности"=$4, "Ошибка"=$5
+        WHERE "id_запуска"=$1
+    `, id, status, changed, details, errText))
+}
+
+func (r jobRunRepo) Last(ctx context.Context) (map[string]store.JobRun, error) {
+	list, err := r.list(ctx, `
+        SELECT DISTINCT ON ("Задача") "id_запуска", "Задача", "Узел", "Начало", "Окончание", "Статус", "Изменено", "Подробности", "Ошибка"
+        FROM "Запуск_задачи"
+        ORDER BY "Задача", "Начало" DESC`)
+	if err != nil {
+		return nil, err
+	}
+	last := make(map[string]store.JobRun, len(list))
+	for _, run := range list {
+		last[run.Job] = run
+	}
+	return last, nil
+}
+
+func (r jobRunRepo) Recent(ctx context.Context, limit int) ([]store.JobRun, error) {
+	return r.list(ctx, jobRunSelect+` ORDER BY "Начало" DESC LIMIT $1`, limit)
+}
+
+// jobLockSpace — первый ключ advisory-lock задач; второй — хэш имени задачи.
+// Миграции берут однопараметровый lock (migrateLockKey), пространства не пересекаются.
+const jobLockSpace = 0x46434d4a // "FCMJ"
+
+// TryLock берёт сессионный advisory-lock задачи name на выделенном
+// соединении, не дожидаясь: ok=false — задачу сейчас выполняет другой
+// экземпляр приложения. release снимает блокировку и возвращает
+// соединение в пул. Только для хранилища на пуле (не внутри InTx).
+func (s *Store) TryLock(ctx context.Context, name string) (release func(), ok bool, err error) {
+	if s.db == nil {
+		return nil, false, fmt.Errorf("TryLock внутри транзакции")
+	}
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	key := int32(h.Sum32())
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1, $2)`, jobLockSpace, key).Scan(&ok); err != nil || !ok {
+		conn.Close()
+		return nil, false, err
+	}
+	return func() {
+		// контекст запуска мог истечь — снимаем блокировку в любом случае
+		_, _ = conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1, $2)`, jobLockSpace, key)
+		conn.Close()
+	}, true, nil
+}
@@ -0,0 +1,192 @@
+package pgstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type maintenanceRepo struct{ q querier }
+
+// jsonColumn — JSONB-колонка, разбираемая в dst (указатель).
+type jsonColumn struct{ dst any }
+
+func (j jsonColumn) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, j.dst)
+	case string:
+		return json.Unmarshal([]byte(v), j.dst)
+	default:
+		return fmt.Errorf("jsonb: неподдерживаемый тип %T", src)
+	}
+}
+
+func jsonValue(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+const planSelect = `
+    SELECT p."id_плана", p."Название", COALESCE(p."id_оборудования",0), COALESCE(e."Название",''),
+           COALESCE(p."Тип_оборудования",''), COALESCE(p."Интервал_дней",0), COALESCE(p."Интервал_часов",0),
+           p."Чек_лист"
+    FROM "План_ТО" p
+    LEFT JOIN "Оборудование" e ON e."id_оборудования" = p."id_оборудования"`
+
+func scanPlan(row scanner) (models.MaintenancePlan, error) {
+	var p models.MaintenancePlan
+	err := row.Scan(&p.ID, &p.Name, &p.EquipmentID, &p.EquipmentName, &p.EquipmentType,
+		&p.IntervalDays, &p.IntervalHours, jsonColumn{&p.Checklist})
+	return p, err
+}
+
+func (r maintenanceRepo) Plans(ctx context.Context) ([]models.MaintenancePlan, error) {
+	rows, err := r.q.QueryContext(ctx, planSelect+` ORDER BY p."Название", p."id_плана"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.MaintenancePlan
+	for rows.Next() {
+		p, err := scanPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+func (r maintenanceRepo) Plan(ctx context.Context, id int) (models.MaintenancePlan, error) {
+	p, err := scanPlan(r.q.QueryRowContext(ctx, planSelect+` WHERE p."id_плана"=$1`, id))
+	return p, wrapErr(err)
+}
+
+func planArgs(in store.MaintenancePlanInput) ([]any, error) {
+	list := in.Checklist
+	if list == nil {
+		list = []string{}
+	}
+	checklist, err := jsonValue(list)
+	if err != nil {
+		return nil, err
+	}
+	var eq, days, hours any
+	if in.EquipmentID > 0 {
+		eq = in.EquipmentID
+	}
+	if in.IntervalDays > 0 {
+		days = in.IntervalDays
+	}
+	if in.IntervalHours > 0 {
+		hours = in.IntervalHours
+	}
+	return []any{in.Name, eq, nullIfEmpty(in.EquipmentType), days, hours, checklist}, nil
+}
+
+func (r maintenanceRepo) CreatePlan(ctx context.Context, in store.MaintenancePlanInput) (int, error) {
+	args, err := planArgs(in)
+	if err != nil {
+		return 0, err
+	}
+	var id int
+	err = r.q.QueryRowContext(ctx, `
+        INSERT INTO "План_ТО"
+        ("Название","id_оборудования","Тип_оборудования","Интервал_дней","Интервал_часов","Чек_лист")
+        VALUES ($1,$2,$3,$4,$5,$6)
+        RETURNING "id_плана"
+    `, args...).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r maintenanceRepo) UpdatePlan(ctx context.Context, id int, in store.MaintenancePlanInput) error {
+	args, err := planArgs(in)
+	if err != nil {
+		return err
+	}
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "План_ТО"
+        SET "Название"=$2, "id_оборудования"=$3, "Тип_оборудования"=$4,
+            "Интервал_дней"=$5, "Интервал_часов"=$6, "Чек_лист"=$7
+        WHERE "id_плана"=$1
+    `, append([]any{id}, args...)...))
+}
+
+func (r maintenanceRepo) DeletePlan(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "План_ТО" WHERE "id_плана"=$1`, id))
+}
+
+const recordColumns = `
+           m."id_обслуживания", m."id_оборудования", COALESCE(m."id_плана",0), COALESCE(p."Название",''),
+           m."Дата", m."Наработка_часов", m."Чек_лист", COALESCE(m."Комментарий",''), m."Выполнил", m."Создано"
+    FROM "Обслуживание_оборудования" m
+    LEFT JOIN "План_ТО" p ON p."id_плана" = m."id_плана"`
+
+const recordSelect = `SELECT` + recordColumns
+
+func scanRecord(row scanner) (models.MaintenanceRecord, error) {
+	var m models.MaintenanceRecord
+	err := row.Scan(&m.ID, &m.EquipmentID, &m.PlanID, &m.PlanName, &m.Date, &m.UsageHours,
+		jsonColumn{&m.Checklist}, &m.Comment, &m.PerformedBy, &m.CreatedAt)
+	return m, err
+}
+
+func (r maintenanceRepo) records(ctx context.Context, query string, args ...any) ([]models.MaintenanceRecord, error) {
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.MaintenanceRecord
+	for rows.Next() {
+		m, err := scanRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	return list, rows.Err()
+}
+
+func (r maintenanceRepo) Latest(ctx context.Context) ([]models.MaintenanceRecord, error) {
+	return r.records(ctx, `SELECT DISTINCT ON (m."id_плана", m."id_оборудования")`+recordColumns+`
+        WHERE m."id_плана" IS NOT NULL
+        ORDER BY m."id_плана", m."id_оборудования", m."Дата" DESC, m."id_обслуживания" DESC`)
+}
+
+func (r maintenanceRepo) History(ctx context.Context, equipmentID int) ([]models.MaintenanceRecord, error) {
+	return r.records(ctx, recordSelect+` WHERE m."id_оборудования"=$1 ORDER BY m."Дата" DESC, m."id_обслуживания" DESC`, equipmentID)
+}
+
+func (r maintenanceRepo) Log(ctx context.Context, in store.MaintenanceInput) (int, error) {
+	list := in.Checklist
+	if list == nil {
+		list = []models.ChecklistItem{}
+	}
+	checklist, err := jsonValue(list)
+	if err != nil {
+		return 0, err
+	}
+	var plan, staff any
+	if in.PlanID > 0 {
+		plan = in.PlanID
+	}
+	if in.StaffID > 0 {
+		staff = in.StaffID
+	}
+	var id int
+	err = r.q.QueryRowContext(ctx, `
+        INSERT INTO "Обслуживание_оборудования"
+        ("id_оборудования","id_плана","Дата","Наработка_часов","Чек_лист","Комментарий","id_сотрудника","Выполнил")
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+        RETURNING "id_обслуживания"
+    `, in.EquipmentID, plan, in.Date, nullableFloat64(in.UsageHours), checklist,
+		nullIfEmpty(in.Comment), staff, in.PerformedBy).Scan(&id)
+	return id, wrapErr(err)
+}
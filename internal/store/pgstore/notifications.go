@@ -0,0 +1,58 @@
+package pgstore
+
+import (
+	"context"
+
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type notificationRepo struct{ q querier }
+
+func (r notificationRepo) Create(ctx context.Context, clientID int, subject, text string) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Уведомление" ("id_клиента","Тема","Текст")
+        VALUES ($1,$2,$3)
+        RETURNING "id_уведомления"
+    `, clientID, subject, text).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r notificationRepo) List(ctx context.Context, f store.NotificationFilter) ([]models.Notification, error) {
+	var w where
+	if f.ClientID > 0 {
+		w.add(`n."id_клиента" = ` + w.ph(f.ClientID))
+	}
+	if f.Pending {
+		w.add(`n."Отправлено" IS NULL`)
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT n."id_уведомления", n."id_клиента", n."Тема", n."Текст", n."Создано", n."Отправлено", c."ФИО"
+        FROM "Уведомление" n
+        JOIN "Клиент" c ON c."id_клиента" = n."id_клиента"`+w.sql()+`
+        ORDER BY n."Создано" DESC, n."id_уведомления" DESC
+        LIMIT `+w.ph(limit), w.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.Notification
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.ClientID, &n.Subject, &n.Text, &n.CreatedAt, &n.SentAt, &n.ClientName); err != nil {
+			return nil, err
+		}
+		list = append(list, n)
+	}
+	return list, rows.Err()
+}
+
+func (r notificationRepo) MarkSent(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx,
+		`UPDATE "Уведомление" SET "Отправлено" = NOW() WHERE "id_уведомления" = $1 AND "Отправлено" IS NULL`, id))
+}
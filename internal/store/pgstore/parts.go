@@ -0,0 +1,193 @@
+package pgstore
+
+import (
+	"context"
+	"errors"
+
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type vendorRepo struct{ q querier }
+
+const vendorSelect = `
+    SELECT "id_подрядчика", "Название", COALESCE("Контакт",''), COALESCE("Телефон",''),
+           COALESCE("Email",''), COALESCE("Примечание",'')
+    FROM "Подрядчик"`
+
+func scanVendor(row scanner) (models.Vendor, error) {
+	var v models.Vendor
+	err := row.Scan(&v.ID, &v.Name, &v.Contact, &v.Phone, &v.Email, &v.Note)
+	return v, err
+}
+
+func (r vendorRepo) List(ctx context.Context) ([]models.Vendor, error) {
+	rows, err := r.q.QueryContext(ctx, vendorSelect+` ORDER BY "Название"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.Vendor
+	for rows.Next() {
+		v, err := scanVendor(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, rows.Err()
+}
+
+func (r vendorRepo) Get(ctx context.Context, id int) (models.Vendor, error) {
+	v, err := scanVendor(r.q.QueryRowContext(ctx, vendorSelect+` WHERE "id_подрядчика"=$1`, id))
+	return v, wrapErr(err)
+}
+
+func (r vendorRepo) Create(ctx context.Context, in store.VendorInput) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Подрядчик" ("Название","Контакт","Телефон","Email","Примечание")
+        VALUES ($1,$2,$3,$4,$5)
+        RETURNING "id_подрядчика"
+    `, in.Name, nullIfEmpty(in.Contact), nullIfEmpty(in.Phone), nullIfEmpty(in.Email), nullIfEmpty(in.Note)).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r vendorRepo) Update(ctx context.Context, id int, in store.VendorInput) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Подрядчик"
+        SET "Название"=$2, "Контакт"=$3, "Телефон"=$4, "Email"=$5, "Примечание"=$6
+        WHERE "id_подрядчика"=$1
+    `, id, in.Name, nullIfEmpty(in.Contact), nullIfEmpty(in.Phone), nullIfEmpty(in.Email), nullIfEmpty(in.Note)))
+}
+
+func (r vendorRepo) Delete(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Подрядчик" WHERE "id_подрядчика"=$1`, id))
+}
+
+type partRepo struct{ q querier }
+
+const partSelect = `
+    SELECT p."id_запчасти", p."Название", COALESCE(p."Артикул",''), p."Единица",
+           p."Остаток", p."Порог_заказа", p."Цена",
+           COALESCE(p."id_подрядчика",0), COALESCE(v."Название",'')
+    FROM "Запчасть" p
+    LEFT JOIN "Подрядчик" v ON v."id_подрядчика" = p."id_подрядчика"`
+
+func scanPart(row scanner) (models.SparePart, error) {
+	var p models.SparePart
+	err := row.Scan(&p.ID, &p.Name, &p.SKU, &p.Unit, &p.Stock, &p.ReorderLevel, &p.Price, &p.VendorID, &p.VendorName)
+	return p, err
+}
+
+func (r partRepo) List(ctx context.Context) ([]models.SparePart, error) {
+	rows, err := r.q.QueryContext(ctx, partSelect+` ORDER BY p."Название", p."id_запчасти"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.SparePart
+	for rows.Next() {
+		p, err := scanPart(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+func (r partRepo) Get(ctx context.Context, id int) (models.SparePart, error) {
+	p, err := scanPart(r.q.QueryRowContext(ctx, partSelect+` WHERE p."id_запчасти"=$1`, id))
+	return p, wrapErr(err)
+}
+
+func (r partRepo) Create(ctx context.Context, in store.PartInput) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Запчасть" ("Название","Артикул","Единица","Порог_заказа","Цена","id_подрядчика")
+        VALUES ($1,$2,$3,$4,$5,NULLIF($6,0))
+        RETURNING "id_запчасти"
+    `, in.Name, nullIfEmpty(in.SKU), in.Unit, in.ReorderLevel, in.Price, in.VendorID).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r partRepo) Update(ctx context.Context, id int, in store.PartInput) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Запчасть"
+        SET "Название"=$2, "Артикул"=$3, "Единица"=$4, "Порог_заказа"=$5, "Цена"=$6, "id_подрядчика"=NULLIF($7,0)
+        WHERE "id_запчасти"=$1
+    `, id, in.Name, nullIfEmpty(in.SKU), in.Unit, in.ReorderLevel, in.Price, in.VendorID))
+}
+
+func (r partRepo) Delete(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Запчасть" WHERE "id_запчасти"=$1`, id))
+}
+
+func (r partRepo) Receive(ctx context.Context, id int, qty float64) error {
+	return mustAffect(r.q.ExecContext(ctx,
+		`UPDATE "Запчасть" SET "Остаток" = "Остаток" + $2 WHERE "id_запчасти"=$1`, id, qty))
+}
+
+func (r partRepo) Usage(ctx context.Context, repairID int) ([]models.PartUsage, error) {
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT u."id_расхода", u."id_заявки", u."id_запчасти", p."Название", p."Единица",
+               u."Количество", u."Цена", u."Дата"
+        FROM "Расход_запчасти" u
+        JOIN "Запчасть" p ON p."id_запчасти" = u."id_запчасти"
+        WHERE u."id_заявки"=$1
+        ORDER BY u."Дата", u."id_расхода"
+    `, repairID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.PartUsage
+	for rows.Next() {
+		var u models.PartUsage
+		if err := rows.Scan(&u.ID, &u.RepairID, &u.PartID, &u.PartName, &u.Unit, &u.Quantity, &u.Price, &u.Date); err != nil {
+			return nil, err
+		}
+		list = append(list, u)
+	}
+	return list, rows.Err()
+}
+
+// Consume уменьшает остаток условным UPDATE — два параллельных списания
+// не уведут его в минус.
+func (r partRepo) Consume(ctx context.Context, repairID, partID int, qty float64) (int, error) {
+	var price float64
+	err := r.q.QueryRowContext(ctx, `
+        UPDATE "Запчасть" SET "Остаток" = "Остаток" - $2
+        WHERE "id_запчасти"=$1 AND "Остаток" >= $2
+        RETURNING "Цена"
+    `, partID, qty).Scan(&price)
+	if err = wrapErr(err); errors.Is(err, store.ErrNotFound) {
+		if _, err := r.Get(ctx, partID); err != nil {
+			return 0, err
+		}
+		return 0, store.ErrOutOfStock
+	}
+	if err != nil {
+		return 0, err
+	}
+	var id int
+	err = r.q.QueryRowContext(ctx, `
+        INSERT INTO "Расход_запчасти" ("id_заявки","id_запчасти","Количество","Цена")
+        VALUES ($1,$2,$3,$4)
+        RETURNING "id_расхода"
+    `, repairID, partID, qty, price).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r partRepo) Return(ctx context.Context, usageID int) (models.PartUsage, error) {
+	var u models.PartUsage
+	err := r.q.QueryRowContext(ctx, `
+        DELETE FROM "Расход_запчасти" WHERE "id_расхода"=$1
+        RETURNING "id_расхода", "id_заявки", "id_запчасти", "Количество", "Цена", "Дата"
+    `, usageID).Scan(&u.ID, &u.RepairID, &u.PartID, &u.Quantity, &u.Price, &u.Date)
+	if err != nil {
+		return u, wrapErr(err)
+	}
+	return u, r.Receive(ctx, u.PartID, u.Quantity)
+}
@@ -0,0 +1,145 @@
+package pgstore
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type paymentRepo struct{ q querier }
+
+// signedAmount — сумма платежа p со знаком: возврат отрицательный.
+const signedAmount = `CASE WHEN p."Вид" = 'Возврат' THEN -p."Сумма" ELSE p."Сумма" END`
+
+const paymentSelect = `
+    SELECT p."id_платежа", p."id_клиента", p."id_абонемента", p."id_персональной_тренировки",
+           p."Вид", p."Сумма", p."Способ", p."Дата_время", p."Кассир", p."Комментарий",
+           c."ФИО" AS client_name
+    FROM "Платёж" p
+    JOIN "Клиент" c ON c."id_клиента" = p."id_клиента"`
+
+func scanPayment(row scanner) (models.Payment, error) {
+	var p models.Payment
+	err := row.Scan(&p.ID, &p.ClientID, &p.SubscriptionID, &p.PersonalTrainingID,
+		&p.Kind, &p.Amount, &p.Method, &p.PaidAt, &p.Cashier, &p.Comment, &p.ClientName)
+	return p, err
+}
+
+func (r paymentRepo) list(ctx context.Context, cond string, arg any) ([]models.Payment, error) {
+	rows, err := r.q.QueryContext(ctx, paymentSelect+` WHERE `+cond+` ORDER BY p."Дата_время", p."id_платежа"`, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.Payment
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+func (r paymentRepo) ListBySubscription(ctx context.Context, subscriptionID int) ([]models.Payment, error) {
+	return r.list(ctx, `p."id_абонемента" = $1`, subscriptionID)
+}
+
+func (r paymentRepo) ListByPersonalTraining(ctx context.Context, personalTrainingID int) ([]models.Payment, error) {
+	return r.list(ctx, `p."id_персональной_тренировки" = $1`, personalTrainingID)
+}
+
+func (r paymentRepo) ListByClient(ctx context.Context, clientID int) ([]models.Payment, error) {
+	return r.list(ctx, `p."id_клиента" = $1`, clientID)
+}
+
+func (r paymentRepo) Create(ctx context.Context, in store.PaymentInput) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Платёж" ("id_клиента","id_абонемента","id_персональной_тренировки",
+                              "Вид","Сумма","Способ","id_сотрудника","Кассир","Комментарий")
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+        RETURNING "id_платежа"
+    `, in.ClientID, nullIfZero(in.SubscriptionID), nullIfZero(in.PersonalTrainingID),
+		in.Kind, in.Amount, in.Method, nullIfZero(in.StaffID), in.Cashier, in.Comment).Scan(&id)
+	return id, wrapErr(err)
+}
+
+// duesQuery — абонементы и персональные тренировки с ценой и оплаченной суммой.
+const duesQuery = `
+    WITH paid AS (
+        SELECT p."id_абонемента" AS sub_id, p."id_персональной_тренировки" AS pt_id,
+               SUM(` + signedAmount + `) AS amount
+        FROM "Платёж" p
+        GROUP BY 1, 2
+    ), items AS (
+        SELECT 'subscription' AS item, s."id_абонемента" AS id, s."id_клиента" AS client_id,
+               t."Название_тарифа" AS title, s."Дата_начала"::timestamp AS at,
+               COALESCE(s."Цена", 0) AS price,
+               COALESCE((SELECT amount FROM paid WHERE paid.sub_id = s."id_абонемента"), 0) AS paid
+        FROM "Абонемент" s
+        JOIN "Тариф" t ON t."id_тарифа" = s."id_тарифа"
+        UNION ALL
+        SELECT 'personal_training', pt."id_персональной_тренировки", s."id_клиента",
+               'Персональная: ' || tr."ФИО", pt."Время_начала",
+               CASE WHEN pt."Статус" = 'Отменена' THEN 0 ELSE COALESCE(pt."Стоимость", 0) END,
+               COALESCE((SELECT amount FROM paid WHERE paid.pt_id = pt."id_персональной_тренировки"), 0)
+        FROM "Персональная_тренировка" pt
+        JOIN "Абонемент" s ON s."id_абонемента" = pt."id_абонемента"
+        JOIN "Тренер" tr ON tr."id_тренера" = pt."id_тренера"
+    )
+    SELECT i.item, i.id, i.client_id, c."ФИО", i.title, i.at, i.price, i.paid
+    FROM items i
+    JOIN "Клиент" c ON c."id_клиента" = i.client_id`
+
+func (r paymentRepo) Dues(ctx context.Context, f store.DueFilter) ([]store.Due, error) {
+	var w where
+	if f.ClientID > 0 {
+		w.add(`i.client_id = ` + w.ph(f.ClientID))
+	}
+	if f.OnlyDebts {
+		w.add(`i.price > i.paid`)
+	}
+	rows, err := r.q.QueryContext(ctx, duesQuery+w.sql()+` ORDER BY c."ФИО", i.client_id, i.at DESC`, w.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.Due
+	for rows.Next() {
+		var d store.Due
+		if err := rows.Scan(&d.Item, &d.ID, &d.ClientID, &d.ClientName, &d.Title, &d.Date, &d.Price, &d.Paid); err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+func (r paymentRepo) CashTotals(ctx context.Context, from, to time.Time) ([]store.CashTotal, error) {
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT p."Дата_время"::date, p."Способ", COUNT(*),
+               COALESCE(SUM(p."Сумма") FILTER (WHERE p."Вид" = 'Оплата'), 0),
+               COALESCE(SUM(p."Сумма") FILTER (WHERE p."Вид" = 'Возврат'), 0)
+        FROM "Платёж" p
+        WHERE p."Дата_время" >= $1 AND p."Дата_время" < $2
+        GROUP BY 1, 2
+        ORDER BY 1, 2
+    `, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.CashTotal
+	for rows.Next() {
+		var t store.CashTotal
+		if err := rows.Scan(&t.Day, &t.Method, &t.Count, &t.Received, &t.Refunded); err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,165 @@
+package pgstore
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+type payrollRepo struct{ q querier }
+
+const payRuleSelect = `
+    SELECT t."id_тренера", t."ФИО", r."id_тренера" IS NOT NULL,
+           COALESCE(r."За_групповую",0), COALESCE(r."Процент_персональной",0),
+           COALESCE(r."Порог_заполняемости",0), COALESCE(r."Бонус_заполняемости",0)
+    FROM "Тренер" t
+    LEFT JOIN "Правило_оплаты_тренера" r ON r."id_тренера" = t."id_тренера"`
+
+func scanPayRule(row scanner) (models.PayRule, error) {
+	var r models.PayRule
+	err := row.Scan(&r.TrainerID, &r.TrainerName, &r.Configured,
+		&r.PerGroup, &r.PersonalPercent, &r.FillThreshold, &r.FillBonus)
+	return r, err
+}
+
+func (r payrollRepo) Rules(ctx context.Context) ([]models.PayRule, error) {
+	rows, err := r.q.QueryContext(ctx, payRuleSelect+` ORDER BY t."ФИО", t."id_тренера"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.PayRule
+	for rows.Next() {
+		x, err := scanPayRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, x)
+	}
+	return list, rows.Err()
+}
+
+func (r payrollRepo) Rule(ctx context.Context, trainerID int) (models.PayRule, error) {
+	x, err := scanPayRule(r.q.QueryRowContext(ctx, payRuleSelect+` WHERE t."id_тренера"=$1`, trainerID))
+	return x, wrapErr(err)
+}
+
+func (r payrollRepo) SetRule(ctx context.Context, rule models.PayRule) error {
+	_, err := r.q.ExecContext(ctx, `
+        INSERT INTO "Правило_оплаты_тренера"
+        ("id_тренера","За_групповую","Процент_персональной","Порог_заполняемости","Бонус_заполняемости")
+        VALUES ($1,$2,$3,$4,$5)
+        ON CONFLICT ("id_тренера") DO UPDATE SET
+            "За_групповую"=EXCLUDED."За_групповую",
+            "Процент_персональной"=EXCLUDED."Процент_персональной",
+            "Порог_заполняемости"=EXCLUDED."Порог_заполняемости",
+            "Бонус_заполняемости"=EXCLUDED."Бонус_заполняемости"
+    `, rule.TrainerID, rule.PerGroup, rule.PersonalPercent, rule.FillThreshold, rule.FillBonus)
+	return wrapErr(err)
+}
+
+func (r payrollRepo) Work(ctx context.Context, trainerID int, from, to time.Time) ([]models.PayrollGroup, []models.PayrollPersonal, error) {
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT g."id_групповой_тренировки", g."Название", g."Время_начала", g."Время_окончания",
+               g."Максимум_участников",
+               (SELECT COUNT(*) FROM "Запись_на_групповую_тренировку" e
+                 WHERE e."id_групповой_тренировки" = g."id_групповой_тренировки" AND e."Статус" = 'Посетил')
+        FROM "Групповая_тренировка" g
+        WHERE g."id_тренера" = $1 AND g."Время_начала" >= $2 AND g."Время_начала" < $3
+        ORDER BY g."Время_начала", g."id_групповой_тренировки"
+    `, trainerID, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	var groups []models.PayrollGroup
+	for rows.Next() {
+		var g models.PayrollGroup
+		if err := rows.Scan(&g.ID, &g.Title, &g.StartTime, &g.EndTime, &g.MaxParticipants, &g.Attended); err != nil {
+			return nil, nil, err
+		}
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	prow, err := r.q.QueryContext(ctx, `
+        SELECT v."id_персональной_тренировки", v.client_fio, v."Время_начала", COALESCE(v."Стоимость",0)
+        FROM vw_personal_training_enriched v
+        WHERE v."id_тренера" = $1 AND v."Статус" = 'Завершена'
+          AND v."Время_начала" >= $2 AND v."Время_начала" < $3
+        ORDER BY v."Время_начала", v."id_персональной_тренировки"
+    `, trainerID, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer prow.Close()
+	var personal []models.PayrollPersonal
+	for prow.Next() {
+		var p models.PayrollPersonal
+		if err := prow.Scan(&p.ID, &p.ClientName, &p.StartTime, &p.Price); err != nil {
+			return nil, nil, err
+		}
+		personal = append(personal, p)
+	}
+	return groups, personal, prow.Err()
+}
+
+func (r payrollRepo) Period(ctx context.Context, month time.Time) (models.PayrollPeriod, error) {
+	var p models.PayrollPeriod
+	err := r.q.QueryRowContext(ctx,
+		`SELECT "id_периода", "Месяц", "Закрыт" FROM "Расчётный_период" WHERE "Месяц"=$1`, month).
+		Scan(&p.ID, &p.Month, &p.ClosedAt)
+	return p, wrapErr(err)
+}
+
+func (r payrollRepo) ClosePeriod(ctx context.Context, month time.Time, lines []models.PayrollLine) (models.PayrollPeriod, error) {
+	var p models.PayrollPeriod
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Расчётный_период" ("Месяц") VALUES ($1)
+        RETURNING "id_периода", "Месяц", "Закрыт"
+    `, month).Scan(&p.ID, &p.Month, &p.ClosedAt)
+	if err != nil {
+		return p, wrapErr(err)
+	}
+	for _, l := range lines {
+		if _, err := r.q.ExecContext(ctx, `
+            INSERT INTO "Начисление_тренеру"
+            ("id_периода","id_тренера","Вид","id_тренировки","Время","Описание","База","Сумма")
+            VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+        `, p.ID, l.TrainerID, l.Kind, l.TrainingID, l.Time, l.Description, l.Base, l.Amount); err != nil {
+			return p, wrapErr(err)
+		}
+	}
+	return p, nil
+}
+
+func (r payrollRepo) ReopenPeriod(ctx context.Context, month time.Time) error {
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Расчётный_период" WHERE "Месяц"=$1`, month))
+}
+
+func (r payrollRepo) ClosedLines(ctx context.Context, periodID, trainerID int) ([]models.PayrollLine, error) {
+	w := where{}
+	w.add(`"id_периода" = ` + w.ph(periodID))
+	if trainerID > 0 {
+		w.add(`"id_тренера" = ` + w.ph(trainerID))
+	}
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT "id_тренера", "Вид", "id_тренировки", "Время", "Описание", "База", "Сумма"
+        FROM "Начисление_тренеру"`+w.sql()+` ORDER BY "id_тренера", "Время", "id_начисления"`, w.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.PayrollLine
+	for rows.Next() {
+		var l models.PayrollLine
+		if err := rows.Scan(&l.TrainerID, &l.Kind, &l.TrainingID, &l.Time, &l.Description, &l.Base, &l.Amount); err != nil {
+			return nil, err
+		}
+		list = append(list, l)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,213 @@
+// Package pgstore — реализация store.Store поверх PostgreSQL (lib/pq).
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"fitness-center-manager/internal/audit"
+	"fitness-center-manager/internal/store"
+
+	"github.com/lib/pq"
+)
+
+// querier — общее у *sql.DB и *sql.Tx: репозитории не знают, в транзакции ли они.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// scanner — *sql.Row или *sql.Rows.
+type scanner interface{ Scan(dest ...any) error }
+
+// Store — хранилище на пуле соединений или (внутри InTx) на транзакции.
+type Store struct {
+	db  *sql.DB // nil внутри транзакции
+	q   querier
+	loc *time.Location // часовой пояс клуба: в нём считается «сегодня»
+}
+
+// wallNow — текущее «настенное» время клуба с поясом UTC: так хранятся
+// времена тренировок (TIMESTAMP без пояса), NOW() сессии с ними не сравнить.
+func wallNow(loc *time.Location) time.Time {
+	t := time.Now().In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+var _ store.Store = (*Store)(nil)
+
+// New — хранилище поверх пула соединений.
+func New(db *sql.DB) *Store {
+	return &Store{db: db, q: db, loc: time.Local}
+}
+
+// SetLocation задаёт часовой пояс клуба (server.timezone): по нему
+// определяется текущий день, например идущие заморозки абонементов.
+func (s *Store) SetLocation(loc *time.Location) { s.loc = loc }
+
+func (s *Store) Clients() store.ClientRepo             { return clientRepo{s.q} }
+func (s *Store) Subscriptions() store.SubscriptionRepo { return subscriptionRepo{s.q, s.loc} }
+func (s *Store) Tariffs() store.TariffRepo             { return tariffRepo{s.q} }
+func (s *Store) Trainings() store.TrainingRepo         { return trainingRepo{s.q} }
+func (s *Store) Zones() store.ZoneRepo                 { return zoneRepo{s.q} }
+func (s *Store) Equipment() store.EquipmentRepo        { return equipmentRepo{s.q} }
+func (s *Store) Repairs() store.RepairRepo             { return repairRepo{s.q} }
+func (s *Store) Visits() store.VisitRepo               { return visitRepo{s.q} }
+func (s *Store) Freezes() store.FreezeRepo             { return freezeRepo{s.q} }
+func (s *Store) JobRuns() store.JobRunRepo             { return jobRunRepo{s.q} }
+func (s *Store) Payments() store.PaymentRepo           { return paymentRepo{s.q} }
+func (s *Store) Notifications() store.NotificationRepo { return notificationRepo{s.q} }
+func (s *Store) Series() store.SeriesRepo              { return seriesRepo{s.q, s.loc} }
+func (s *Store) Availability() store.AvailabilityRepo  { return availabilityRepo{s.q} }
+func (s *Store) Calendar() store.CalendarRepo          { return calendarRepo{s.q} }
+func (s *Store) Rates() store.RateRepo                 { return rateRepo{s.q} }
+func (s *Store) Payroll() store.PayrollRepo            { return payrollRepo{s.q} }
+func (s *Store) Maintenance() store.MaintenanceRepo    { return maintenanceRepo{s.q} }
+func (s *Store) Vendors() store.VendorRepo             { return vendorRepo{s.q} }
+func (s *Store) Parts() store.PartRepo                 { return partRepo{s.q} }
+func (s *Store) Photos() store.PhotoRepo               { return photoRepo{s.q} }
+func (s *Store) Trainers() store.TrainerRepo           { return trainerRepo{s.q} }
+func (s *Store) Staff() store.StaffRepo                { return staffRepo{s.q} }
+func (s *Store) Sessions() store.SessionRepo           { return sessionRepo{s.q} }
+func (s *Store) Audit() store.AuditRepo                { return auditRepo{s.q} }
+func (s *Store) Reports() store.ReportRepo             { return reportRepo{s.q, s.loc} }
+
+// Ping проверяет соединение с БД; внутри транзакции оно уже есть.
+func (s *Store) Ping(ctx context.Context) error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.PingContext(ctx)
+}
+
+// InTx открывает транзакцию через audit.Begin, чтобы триггеры журнала
+// знали сотрудника. Внутри транзакции просто вызывает fn.
+func (s *Store) InTx(ctx context.Context, actor audit.Actor, fn func(tx store.Store) error) error {
+	if s.db == nil {
+		return fn(s)
+	}
+	tx, err := audit.Begin(ctx, s.db, actor)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(&Store{q: tx, loc: s.loc}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// wrapErr переводит ошибки драйвера в ошибки store (исходная — в тексте).
+func wrapErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return store.ErrNotFound
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505": // unique_violation
+			return fmt.Errorf("%w: %v", store.ErrDuplicate, err)
+		case "23503": // foreign_key_violation
+			return fmt.Errorf("%w: %v", store.ErrInUse, err)
+		case "23P01": // exclusion_violation
+			return fmt.Errorf("%w: %v", store.ErrOverlap, err)
+		}
+	}
+	return err
+}
+
+// mustAffect — ErrNotFound, если UPDATE/DELETE не задел ни одной строки.
+func mustAffect(res sql.Result, err error) error {
+	if err != nil {
+		return wrapErr(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// where — накопитель условий с плейсхолдерами $1, $2, ...
+type where struct {
+	conds []string
+	args  []any
+}
+
+// ph добавляет аргумент и возвращает его плейсхолдер.
+func (w *where) ph(v any) string {
+	w.args = append(w.args, v)
+	return fmt.Sprintf("$%d", len(w.args))
+}
+
+func (w *where) add(cond string) { w.conds = append(w.conds, cond) }
+
+func (w *where) sql() string {
+	if len(w.conds) == 0 {
+		return ""
+	}
+	s := " WHERE " + w.conds[0]
+	for _, c := range w.conds[1:] {
+		s += " AND " + c
+	}
+	return s
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func nullIfZero(id int) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+func nullableFloat(p *float64) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+func nullableFloat64(f sql.NullFloat64) any {
+	if f.Valid {
+		return f.Float64
+	}
+	return nil
+}
+
+func nullableTime(t sql.NullTime) any {
+	if t.Valid {
+		return t.Time
+	}
+	return nil
+}
+
+// photo читает "Фото_ключ" и прежний "Фото" (bytea) записи таблицы table.
+func photo(ctx context.Context, q querier, table, pk string, id int) (store.Photo, error) {
+	var p store.Photo
+	var key sql.NullString
+	err := q.QueryRowContext(ctx, `SELECT "Фото_ключ", "Фото" FROM "`+table+`" WHERE "`+pk+`"=$1`, id).Scan(&key, &p.Legacy)
+	if err != nil {
+		return p, wrapErr(err)
+	}
+	p.Key = key.String
+	return p, nil
+}
+
+// setPhoto записывает ключ фото ("" — удалить) и очищает прежний bytea.
+func setPhoto(ctx context.Context, q querier, table, pk string, id int, key string) error {
+	return mustAffect(q.ExecContext(ctx,
+		`UPDATE "`+table+`" SET "Фото_ключ"=$2, "Фото"=NULL WHERE "`+pk+`"=$1`, id, nullIfEmpty(key)))
+}
@@ -0,0 +1,92 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+
+	"fitness-center-manager/internal/store"
+)
+
+type photoRepo struct{ q querier }
+
+// photoTables — таблицы с фото: вид записи → таблица и первичный ключ.
+var photoTables = map[string][2]string{
+	"zone":      {"Зона", "id_зоны"},
+	"equipment": {"Оборудование", "id_оборудования"},
+	"repair":    {"Заявка_на_ремонт", "id_заявки"},
+}
+
+func (r photoRepo) InUse(ctx context.Context, key string) (bool, error) {
+	var used bool
+	err := r.q.QueryRowContext(ctx, `
+        SELECT EXISTS (SELECT 1 FROM "Зона" WHERE "Фото_ключ"=$1)
+            OR EXISTS (SELECT 1 FROM "Оборудование" WHERE "Фото_ключ"=$1)
+            OR EXISTS (SELECT 1 FROM "Заявка_на_ремонт" WHERE "Фото_ключ"=$1)
+    `, key).Scan(&used)
+	return used, err
+}
+
+func (r photoRepo) Legacy(ctx context.Context, limit int) ([]store.LegacyPhoto, error) {
+	rows, err := r.q.QueryContext(ctx, `
+        (SELECT 'zone', "id_зоны", "Фото" FROM "Зона" WHERE "Фото" IS NOT NULL)
+        UNION ALL
+        (SELECT 'equipment', "id_оборудования", "Фото" FROM "Оборудование" WHERE "Фото" IS NOT NULL)
+        UNION ALL
+        (SELECT 'repair', "id_заявки", "Фото" FROM "Заявка_на_ремонт" WHERE "Фото" IS NOT NULL)
+        LIMIT $1
+    `, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.LegacyPhoto
+	for rows.Next() {
+		var p store.LegacyPhoto
+		if err := rows.Scan(&p.Kind, &p.ID, &p.Data); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+func (r photoRepo) Moved(ctx context.Context, p store.LegacyPhoto, key string) error {
+	t, ok := photoTables[p.Kind]
+	if !ok {
+		return fmt.Errorf("неизвестный вид фото: %s", p.Kind)
+	}
+	return setPhoto(ctx, r.q, t[0], t[1], p.ID, key)
+}
+
+func (r photoRepo) Keys(ctx context.Context) ([]string, error) {
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT "Фото_ключ" FROM "Зона" WHERE "Фото_ключ" IS NOT NULL
+        UNION
+        SELECT "Фото_ключ" FROM "Оборудование" WHERE "Фото_ключ" IS NOT NULL
+        UNION
+        SELECT "Фото_ключ" FROM "Заявка_на_ремонт" WHERE "Фото_ключ" IS NOT NULL
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var keys []string
+	for rows.Next() {
+		var k string
+		if err := rows.Scan(&k); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (r photoRepo) Replace(ctx context.Context, old, new string) error {
+	for _, t := range photoTables {
+		if _, err := r.q.ExecContext(ctx,
+			`UPDATE "`+t[0]+`" SET "Фото_ключ"=$2 WHERE "Фото_ключ"=$1`, old, new); err != nil {
+			return wrapErr(err)
+		}
+	}
+	return nil
+}
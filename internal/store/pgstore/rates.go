@@ -0,0 +1,93 @@
+package pgstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type rateRepo struct{ q querier }
+
+const rateSelect = `
+    SELECT "id_ставки", "id_тренера", "Действует_с", "Категория", "Ставка_в_час",
+           "Пиковые_часы", "Надбавка_пик", "Надбавка_выходные", "Создано"
+    FROM "Ставка_тренера"`
+
+func scanRate(row scanner) (models.TrainerRate, error) {
+	var r models.TrainerRate
+	err := row.Scan(&r.ID, &r.TrainerID, &r.EffectiveFrom, &r.Category, &r.HourlyRate,
+		&r.PeakHours, &r.PeakSurcharge, &r.WeekendSurcharge, &r.CreatedAt)
+	return r, err
+}
+
+func (r rateRepo) List(ctx context.Context, trainerID int) ([]models.TrainerRate, error) {
+	rows, err := r.q.QueryContext(ctx, rateSelect+` WHERE "id_тренера"=$1 ORDER BY "Действует_с" DESC`, trainerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.TrainerRate
+	for rows.Next() {
+		x, err := scanRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, x)
+	}
+	return list, rows.Err()
+}
+
+func (r rateRepo) Get(ctx context.Context, id int) (models.TrainerRate, error) {
+	x, err := scanRate(r.q.QueryRowContext(ctx, rateSelect+` WHERE "id_ставки"=$1`, id))
+	return x, wrapErr(err)
+}
+
+func (r rateRepo) Create(ctx context.Context, trainerID int, in store.RateInput) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Ставка_тренера"
+        ("id_тренера","Действует_с","Категория","Ставка_в_час","Пиковые_часы","Надбавка_пик","Надбавка_выходные")
+        VALUES ($1,$2,$3,$4,$5,$6,$7)
+        RETURNING "id_ставки"
+    `, trainerID, in.EffectiveFrom, in.Category, in.HourlyRate, in.PeakHours, in.PeakSurcharge, in.WeekendSurcharge).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r rateRepo) Delete(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Ставка_тренера" WHERE "id_ставки"=$1`, id))
+}
+
+// rateSnapshot — JSONB "Расчёт_стоимости" (NULL — стоимость введена вручную).
+type rateSnapshot struct{ p **models.RateSnapshot }
+
+func (s rateSnapshot) Scan(src any) error {
+	*s.p = nil
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("Расчёт_стоимости: неподдерживаемый тип %T", src)
+	}
+	var snap models.RateSnapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return err
+	}
+	*s.p = &snap
+	return nil
+}
+
+func snapshotValue(s *models.RateSnapshot) (any, error) {
+	if s == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(s)
+	return string(b), err
+}
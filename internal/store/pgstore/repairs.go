@@ -0,0 +1,232 @@
+package pgstore
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type repairRepo struct{ q querier }
+
+const repairSelect = `
+    SELECT r."id_заявки",
+           r."id_оборудования",
+           r."Дата_создания",
+           r."Описание_проблемы",
+           r."Статус",
+           r."Приоритет",
+           (r."Фото_ключ" IS NOT NULL OR r."Фото" IS NOT NULL) AS has_photo,
+           e."Название" AS eq_name,
+           z."Название" AS zone_name,
+           COALESCE(r."id_исполнителя", 0),
+           COALESCE(s."ФИО", ''),
+           r."Срок_устранения",
+           r."Дата_закрытия",
+           COALESCE(r."id_подрядчика", 0),
+           COALESCE(v."Название", ''),
+           r."Стоимость_работ",
+           COALESCE((SELECT SUM(u."Количество" * u."Цена") FROM "Расход_запчасти" u
+                     WHERE u."id_заявки" = r."id_заявки"), 0)
+    FROM "Заявка_на_ремонт" r
+    JOIN "Оборудование" e ON e."id_оборудования" = r."id_оборудования"
+    JOIN "Зона"         z ON z."id_зоны"         = e."id_зоны"
+    LEFT JOIN "Сотрудник" s ON s."id_сотрудника" = r."id_исполнителя"
+    LEFT JOIN "Подрядчик" v ON v."id_подрядчика" = r."id_подрядчика"`
+
+func scanRepair(row scanner) (models.RepairRequest, error) {
+	var r models.RepairRequest
+	err := row.Scan(&r.ID, &r.EquipmentID, &r.CreateDate, &r.ProblemDesc, &r.Status, &r.Priority,
+		&r.HasPhoto, &r.EquipmentName, &r.ZoneName, &r.AssigneeID, &r.AssigneeName, &r.Deadline, &r.ClosedAt,
+		&r.VendorID, &r.VendorName, &r.LabourCost, &r.PartsCost)
+	return r, err
+}
+
+func (r repairRepo) Latest(ctx context.Context, limit int) ([]models.RepairRequest, error) {
+	return r.query(ctx, repairSelect+` ORDER BY r."id_заявки" DESC LIMIT $1`, limit)
+}
+
+func (r repairRepo) List(ctx context.Context, f store.RepairFilter) ([]models.RepairRequest, error) {
+	var w where
+	if f.Active || !f.OverdueAt.IsZero() {
+		w.add(`r."Статус" <> 'Закрыта'`)
+	}
+	if f.AssigneeID > 0 {
+		w.add(`r."id_исполнителя" = ` + w.ph(f.AssigneeID))
+	}
+	if !f.OverdueAt.IsZero() {
+		w.add(`r."Срок_устранения" < ` + w.ph(f.OverdueAt))
+	}
+	if !f.To.IsZero() {
+		w.add(`r."Дата_создания" < ` + w.ph(f.To))
+	}
+	if !f.From.IsZero() {
+		w.add(`(r."Дата_закрытия" IS NULL OR r."Дата_закрытия" >= ` + w.ph(f.From) + `)`)
+	}
+	q := repairSelect + w.sql() + `
+        ORDER BY (r."Статус" = 'Закрыта'),
+                 CASE WHEN r."Статус" <> 'Закрыта' THEN r."Срок_устранения" END,
+                 r."id_заявки" DESC`
+	if f.Limit > 0 {
+		q += ` LIMIT ` + w.ph(f.Limit)
+	}
+	return r.query(ctx, q, w.args...)
+}
+
+func (r repairRepo) query(ctx context.Context, q string, args ...any) ([]models.RepairRequest, error) {
+	rows, err := r.q.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.RepairRequest
+	for rows.Next() {
+		rr, err := scanRepair(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, rr)
+	}
+	return list, rows.Err()
+}
+
+func (r repairRepo) Get(ctx context.Context, id int) (models.RepairRequest, error) {
+	rr, err := scanRepair(r.q.QueryRowContext(ctx, repairSelect+` WHERE r."id_заявки"=$1`, id))
+	return rr, wrapErr(err)
+}
+
+func (r repairRepo) OpenCount(ctx context.Context, equipmentID int) (int, error) {
+	var n int
+	err := r.q.QueryRowContext(ctx, `
+        SELECT COUNT(*) FROM "Заявка_на_ремонт"
+        WHERE "id_оборудования"=$1 AND "Статус" IN ('Открыта','В работе')
+    `, equipmentID).Scan(&n)
+	return n, err
+}
+
+// Create не указывает "Статус" — сработает DEFAULT в БД, который соответствует CHECK.
+func (r repairRepo) Create(ctx context.Context, in store.RepairInput) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Заявка_на_ремонт"
+        ("id_оборудования","Дата_создания","Описание_проблемы","Приоритет","Фото_ключ","Срок_устранения")
+        VALUES ($1, NOW(), $2, $3, $4, $5)
+        RETURNING "id_заявки"
+    `, in.EquipmentID, in.Description, in.Priority, nullIfEmpty(in.PhotoKey), in.Deadline).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r repairRepo) Update(ctx context.Context, id int, in store.RepairUpdate) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Заявка_на_ремонт"
+        SET "Описание_проблемы"=$2, "Приоритет"=$3, "Срок_устранения"=$4,
+            "id_оборудования"=COALESCE(NULLIF($5, 0), "id_оборудования")
+        WHERE "id_заявки"=$1
+    `, id, in.Description, in.Priority, in.Deadline, in.EquipmentID))
+}
+
+func (r repairRepo) SetStatus(ctx context.Context, id int, status string) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Заявка_на_ремонт"
+        SET "Статус"=$2,
+            "Дата_закрытия"=CASE WHEN $2='Закрыта' THEN COALESCE("Дата_закрытия", NOW()) END
+        WHERE "id_заявки"=$1
+    `, id, status))
+}
+
+func (r repairRepo) Assign(ctx context.Context, id, staffID int) error {
+	return mustAffect(r.q.ExecContext(ctx,
+		`UPDATE "Заявка_на_ремонт" SET "id_исполнителя"=NULLIF($2, 0) WHERE "id_заявки"=$1`, id, staffID))
+}
+
+func (r repairRepo) SetCosts(ctx context.Context, id, vendorID int, labour float64) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Заявка_на_ремонт" SET "id_подрядчика"=NULLIF($2, 0), "Стоимость_работ"=$3
+        WHERE "id_заявки"=$1
+    `, id, vendorID, labour))
+}
+
+func (r repairRepo) Spend(ctx context.Context, from, to time.Time) ([]models.RepairSpend, error) {
+	rows, err := r.q.QueryContext(ctx, `
+        WITH labour AS (
+            SELECT "id_оборудования" AS eq, date_trunc('month', "Дата_создания")::date AS m,
+                   SUM("Стоимость_работ") AS cost, COUNT(*) AS n
+            FROM "Заявка_на_ремонт"
+            WHERE "Дата_создания" >= $1 AND "Дата_создания" < $2
+            GROUP BY 1, 2
+        ), parts AS (
+            SELECT r."id_оборудования" AS eq, date_trunc('month', u."Дата")::date AS m,
+                   SUM(u."Количество" * u."Цена") AS cost
+            FROM "Расход_запчасти" u
+            JOIN "Заявка_на_ремонт" r ON r."id_заявки" = u."id_заявки"
+            WHERE u."Дата" >= $1 AND u."Дата" < $2
+            GROUP BY 1, 2
+        )
+        SELECT e."id_оборудования", e."Название", z."id_зоны", z."Название",
+               COALESCE(l.m, p.m), COALESCE(l.cost, 0), COALESCE(p.cost, 0), COALESCE(l.n, 0)
+        FROM labour l
+        FULL JOIN parts p ON p.eq = l.eq AND p.m = l.m
+        JOIN "Оборудование" e ON e."id_оборудования" = COALESCE(l.eq, p.eq)
+        JOIN "Зона" z ON z."id_зоны" = e."id_зоны"
+        ORDER BY 5, e."Название"
+    `, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.RepairSpend
+	for rows.Next() {
+		var s models.RepairSpend
+		if err := rows.Scan(&s.EquipmentID, &s.EquipmentName, &s.ZoneID, &s.ZoneName,
+			&s.Month, &s.Labour, &s.Parts, &s.Repairs); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+func (r repairRepo) History(ctx context.Context, id int) ([]models.RepairEvent, error) {
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT "id_записи", "id_заявки", "Время", COALESCE("Статус_до", ''), "Статус",
+               "Комментарий", COALESCE("id_сотрудника", 0), "Сотрудник"
+        FROM "История_заявки"
+        WHERE "id_заявки"=$1
+        ORDER BY "Время", "id_записи"
+    `, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.RepairEvent
+	for rows.Next() {
+		var e models.RepairEvent
+		if err := rows.Scan(&e.ID, &e.RepairID, &e.Time, &e.FromStatus, &e.Status,
+			&e.Comment, &e.StaffID, &e.StaffName); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+func (r repairRepo) AddEvent(ctx context.Context, e models.RepairEvent) error {
+	_, err := r.q.ExecContext(ctx, `
+        INSERT INTO "История_заявки" ("id_заявки","Статус_до","Статус","Комментарий","id_сотрудника","Сотрудник")
+        VALUES ($1, NULLIF($2, ''), $3, $4, NULLIF($5, 0), $6)
+    `, e.RepairID, e.FromStatus, e.Status, e.Comment, e.StaffID, e.StaffName)
+	return wrapErr(err)
+}
+
+func (r repairRepo) Delete(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Заявка_на_ремонт" WHERE "id_заявки"=$1`, id))
+}
+
+func (r repairRepo) Photo(ctx context.Context, id int) (store.Photo, error) {
+	return photo(ctx, r.q, "Заявка_на_ремонт", "id_заявки", id)
+}
+
+func (r repairRepo) SetPhoto(ctx context.Context, id int, key string) error {
+	return setPhoto(ctx, r.q, "Заявка_на_ремонт", "id_заявки", id, key)
+}
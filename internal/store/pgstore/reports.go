@@ -0,0 +1,332 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"fitness-center-manager/internal/store"
+)
+
+type reportRepo struct {
+	q   querier
+	loc *time.Location
+}
+
+// время начала тренировок — «настенное» время клуба ($1)
+const dashboardStatsQuery = `
+WITH
+    clients AS (
+        SELECT COUNT(*)::int AS total
+        FROM public."Клиент"
+    ),
+    trainers AS (
+        SELECT COUNT(*)::int AS total
+        FROM public."Тренер"
+    ),
+    active_subscriptions AS (
+        SELECT COUNT(*) FILTER (WHERE "Статус" = 'Активен')::int AS total
+        FROM public."Абонемент"
+    ),
+    group_trainings AS (
+        SELECT COUNT(*)::int AS upcoming
+        FROM public."Групповая_тренировка"
+        WHERE "Время_начала" >= $1::timestamp
+    ),
+    personal_trainings AS (
+        SELECT COUNT(*)::int AS upcoming
+        FROM public."Персональная_тренировка"
+        WHERE "Время_начала" >= $1::timestamp
+    ),
+    zones AS (
+        SELECT
+            COUNT(*) FILTER (WHERE "Статус" = 'Доступна')::int AS active,
+            COUNT(*) FILTER (WHERE "Статус" = 'На ремонте')::int AS repair,
+            COALESCE(SUM("Вместимость"), 0)::int AS capacity
+        FROM public."Зона"
+    ),
+    equipment AS (
+        SELECT
+            COUNT(*)::int AS total,
+            COUNT(*) FILTER (WHERE "Статус" = 'Работает')::int AS working,
+            COUNT(*) FILTER (WHERE "Статус" = 'На ремонте')::int AS repair,
+            COUNT(*) FILTER (WHERE "Фото_ключ" IS NULL AND "Фото" IS NULL)::int AS no_photo
+        FROM public."Оборудование"
+    )
+SELECT
+    clients.total,
+    trainers.total,
+    active_subscriptions.total,
+    group_trainings.upcoming,
+    personal_trainings.upcoming,
+    zones.active,
+    zones.repair,
+    zones.capacity,
+    equipment.total,
+    equipment.working,
+    equipment.repair,
+    equipment.no_photo
+FROM clients, trainers, active_subscriptions, group_trainings, personal_trainings, zones, equipment
+`
+
+func (r reportRepo) Dashboard(ctx context.Context) (store.DashboardStats, error) {
+	var s store.DashboardStats
+	err := r.q.QueryRowContext(ctx, dashboardStatsQuery, wallNow(r.loc)).Scan(
+		&s.Clients,
+		&s.Trainers,
+		&s.ActiveSubscriptions,
+		&s.UpcomingGroup,
+		&s.UpcomingPersonal,
+		&s.ZonesActive,
+		&s.ZonesRepair,
+		&s.ZonesCapacity,
+		&s.EquipmentTotal,
+		&s.EquipmentWorking,
+		&s.EquipmentRepair,
+		&s.EquipmentNoPhoto,
+	)
+	return s, err
+}
+
+func (r reportRepo) RecentClients(ctx context.Context, limit int) ([]store.RecentClient, error) {
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT "id_клиента", "ФИО", "Дата_регистрации",
+               COALESCE(last_tariff, ''), COALESCE(last_subscription_status, '')
+        FROM view_client_enriched
+        ORDER BY "Дата_регистрации" DESC
+        LIMIT $1
+    `, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.RecentClient
+	for rows.Next() {
+		var c store.RecentClient
+		if err := rows.Scan(&c.ID, &c.FIO, &c.Registered, &c.LastTariff, &c.LastStatus); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+func (r reportRepo) ExpiringSubscriptions(ctx context.Context, limit int) ([]store.ExpiringSubscription, error) {
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT a."id_абонемента", c."ФИО", t."Название_тарифа", a."Дата_окончания",
+               a."id_тарифа", COALESCE(n."id_абонемента", 0)
+        FROM "Абонемент" a
+        JOIN "Клиент" c ON c."id_клиента" = a."id_клиента"
+        JOIN "Тариф" t ON t."id_тарифа" = a."id_тарифа"
+        LEFT JOIN "Абонемент" n ON n."id_предыдущего" = a."id_абонемента"
+        WHERE a."Статус" = 'Активен'
+          AND a."Дата_окончания" BETWEEN CURRENT_DATE AND (CURRENT_DATE + INTERVAL '30 days')
+        ORDER BY a."Дата_окончания"
+        LIMIT $1
+    `, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.ExpiringSubscription
+	for rows.Next() {
+		var s store.ExpiringSubscription
+		if err := rows.Scan(&s.ID, &s.Client, &s.Tariff, &s.EndDate, &s.TariffID, &s.RenewalID); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+func (r reportRepo) RepairingEquipment(ctx context.Context, limit int) ([]store.RepairingEquipment, error) {
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT e."Название", COALESCE(z."Название", '—'), e."Дата_последнего_ТО"
+        FROM "Оборудование" e
+        LEFT JOIN "Зона" z ON z."id_зоны" = e."id_зоны"
+        WHERE e."Статус" = 'На ремонте'
+        ORDER BY e."Дата_последнего_ТО" DESC NULLS LAST
+        LIMIT $1
+    `, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.RepairingEquipment
+	for rows.Next() {
+		var (
+			e           store.RepairingEquipment
+			lastService sql.NullTime
+		)
+		if err := rows.Scan(&e.Name, &e.Zone, &lastService); err != nil {
+			return nil, err
+		}
+		e.LastService = lastService.Time
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+func (r reportRepo) ClientsRegisteredAfter(ctx context.Context, t time.Time) ([]store.ReportClient, error) {
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT "id_клиента", "ФИО", "Дата_регистрации"
+        FROM "Клиент"
+        WHERE "Дата_регистрации" > $1
+        ORDER BY "Дата_регистрации" DESC
+        LIMIT 100
+    `, t)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.ReportClient
+	for rows.Next() {
+		var c store.ReportClient
+		if err := rows.Scan(&c.ID, &c.FIO, &c.Registered); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+func (r reportRepo) SubscriptionsByStatus(ctx context.Context, status string) ([]store.ReportSubscription, error) {
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT s."id_абонемента",
+               c."ФИО"              AS client_name,
+               t."Название_тарифа"  AS tariff_name,
+               s."Дата_начала",
+               s."Дата_окончания",
+               s."Статус",
+               COALESCE(s."Цена", 0) AS price
+        FROM "Абонемент" s
+        JOIN "Клиент" c ON c."id_клиента" = s."id_клиента"
+        JOIN "Тариф"  t ON t."id_тарифа"  = s."id_тарифа"
+        WHERE s."Статус" = $1
+        ORDER BY s."id_абонемента" DESC
+        LIMIT 200
+    `, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.ReportSubscription
+	for rows.Next() {
+		var s store.ReportSubscription
+		if err := rows.Scan(&s.ID, &s.Client, &s.Tariff, &s.Start, &s.End, &s.Status, &s.Price); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+func (r reportRepo) RevenueByTariff(ctx context.Context, from, to time.Time, minRevenue float64) ([]store.TariffRevenue, error) {
+	// выручка — реально полученные деньги (оплаты минус возвраты), а не цены;
+	// начислено — сумма цен абонементов, разница — долг клиентов
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT
+            t."Название_тарифа"                AS tariff,
+            COUNT(s.*)                          AS subs_count,
+            SUM(COALESCE(s."Цена", 0))         AS charged,
+            SUM(COALESCE(p.paid, 0))           AS revenue
+        FROM "Абонемент" s
+        JOIN "Тариф" t ON t."id_тарифа" = s."id_тарифа"
+        LEFT JOIN LATERAL (
+            SELECT SUM(CASE WHEN "Вид" = 'Возврат' THEN -"Сумма" ELSE "Сумма" END) AS paid
+            FROM "Платёж" WHERE "id_абонемента" = s."id_абонемента"
+        ) p ON TRUE
+        WHERE s."Дата_начала" >= $1 AND s."Дата_окончания" <= $2
+        GROUP BY t."Название_тарифа"
+        HAVING SUM(COALESCE(p.paid, 0)) >= $3
+        ORDER BY revenue DESC
+    `, from, to, minRevenue)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.TariffRevenue
+	for rows.Next() {
+		var t store.TariffRevenue
+		if err := rows.Scan(&t.Tariff, &t.Count, &t.Charged, &t.Revenue); err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+func (r reportRepo) ZonesWithEquipment(ctx context.Context, min int) ([]store.ZoneEquipment, error) {
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT z."id_зоны", z."Название",
+               (SELECT COUNT(*) FROM "Оборудование" e WHERE e."id_зоны" = z."id_зоны") AS equip_count
+        FROM "Зона" z
+        WHERE (SELECT COUNT(*) FROM "Оборудование" e WHERE e."id_зоны" = z."id_зоны") >= $1
+        ORDER BY equip_count DESC, z."id_зоны" DESC
+    `, min)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.ZoneEquipment
+	for rows.Next() {
+		var z store.ZoneEquipment
+		if err := rows.Scan(&z.ID, &z.Name, &z.Count); err != nil {
+			return nil, err
+		}
+		list = append(list, z)
+	}
+	return list, rows.Err()
+}
+
+func (r reportRepo) ZonesAboveAvgCapacity(ctx context.Context) ([]store.ZoneCapacity, float64, error) {
+	var avg float64
+	if err := r.q.QueryRowContext(ctx,
+		`SELECT COALESCE(AVG("Вместимость")::float8, 0) FROM "Зона"`).Scan(&avg); err != nil {
+		return nil, 0, err
+	}
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT "id_зоны", "Название", "Вместимость"
+        FROM "Зона"
+        WHERE "Вместимость" > $1
+        ORDER BY "Вместимость" DESC, "id_зоны" DESC
+    `, avg)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var list []store.ZoneCapacity
+	for rows.Next() {
+		var z store.ZoneCapacity
+		if err := rows.Scan(&z.ID, &z.Name, &z.Capacity); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, z)
+	}
+	return list, avg, rows.Err()
+}
+
+func (r reportRepo) FinishedPersonal(ctx context.Context, from, to time.Time) ([]store.FinishedPersonal, error) {
+	// дата начала в пределах периода, включая весь последний день
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT id, client_fio, trainer_fio, starts_at, duration_minutes, price_effective
+        FROM public.v_personal_training_enriched
+        WHERE status = 'Завершена'
+          AND starts_at >= $1
+          AND starts_at < ($2::date + INTERVAL '1 day')
+        ORDER BY starts_at DESC, id DESC
+    `, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.FinishedPersonal
+	for rows.Next() {
+		var p store.FinishedPersonal
+		if err := rows.Scan(&p.ID, &p.Client, &p.Trainer, &p.Start, &p.Duration, &p.Price); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
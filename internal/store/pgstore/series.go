@@ -0,0 +1,175 @@
+package pgstore
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type seriesRepo struct {
+	q   querier
+	loc *time.Location
+}
+
+const seriesSelect = `
+    SELECT
+        s."id_серии",
+        s."id_тренера",
+        s."id_зоны",
+        s."Название",
+        COALESCE(s."Описание",''),
+        s."Максимум_участников",
+        COALESCE(s."Уровень_сложности",''),
+        s."Начало",
+        s."Длительность_мин",
+        s."Правило",
+        (SELECT COUNT(*) FROM "Групповая_тренировка" g
+          WHERE g."id_серии" = s."id_серии" AND g."Время_начала" >= $1::timestamp) AS upcoming,
+        t."ФИО",
+        z."Название"
+    FROM "Серия_тренировок" s
+    JOIN "Тренер" t ON t."id_тренера" = s."id_тренера"
+    JOIN "Зона"   z ON z."id_зоны"    = s."id_зоны"`
+
+func scanSeries(row scanner) (models.TrainingSeries, error) {
+	var s models.TrainingSeries
+	err := row.Scan(&s.ID, &s.TrainerID, &s.ZoneID, &s.Name, &s.Description, &s.MaxParticipants,
+		&s.DifficultyLevel, &s.Start, &s.DurationMin, &s.Rule, &s.Upcoming, &s.TrainerName, &s.ZoneName)
+	return s, err
+}
+
+func (r seriesRepo) List(ctx context.Context) ([]models.TrainingSeries, error) {
+	rows, err := r.q.QueryContext(ctx, seriesSelect+` ORDER BY s."Название", s."id_серии"`, wallNow(r.loc))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.TrainingSeries
+	for rows.Next() {
+		s, err := scanSeries(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+func (r seriesRepo) Get(ctx context.Context, id int) (models.TrainingSeries, error) {
+	s, err := scanSeries(r.q.QueryRowContext(ctx, seriesSelect+` WHERE s."id_серии" = $2`, wallNow(r.loc), id))
+	return s, wrapErr(err)
+}
+
+func (r seriesRepo) Create(ctx context.Context, in store.SeriesInput) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Серия_тренировок"
+        ("id_тренера","id_зоны","Название","Описание","Максимум_участников","Уровень_сложности",
+         "Начало","Длительность_мин","Правило")
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+        RETURNING "id_серии"
+    `, in.TrainerID, in.ZoneID, in.Title, nullIfEmpty(in.Description), in.Max, nullIfEmpty(in.Level),
+		in.Start, in.DurationMin, in.Rule).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r seriesRepo) Update(ctx context.Context, id int, in store.SeriesInput) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Серия_тренировок"
+        SET "id_тренера"=$2,"id_зоны"=$3,"Название"=$4,"Описание"=$5,"Максимум_участников"=$6,
+            "Уровень_сложности"=$7,"Начало"=$8,"Длительность_мин"=$9,"Правило"=$10
+        WHERE "id_серии"=$1
+    `, id, in.TrainerID, in.ZoneID, in.Title, nullIfEmpty(in.Description), in.Max, nullIfEmpty(in.Level),
+		in.Start, in.DurationMin, in.Rule))
+}
+
+func (r seriesRepo) Delete(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Серия_тренировок" WHERE "id_серии"=$1`, id))
+}
+
+func (r seriesRepo) Occurrences(ctx context.Context, id int) ([]models.GroupTraining, error) {
+	rows, err := r.q.QueryContext(ctx, groupSelect+`
+        WHERE v."id_серии" = $1
+        ORDER BY v."Дата_в_серии", v."id_групповой_тренировки"
+    `, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.GroupTraining
+	for rows.Next() {
+		g, err := scanGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, g)
+	}
+	return list, rows.Err()
+}
+
+func (r seriesRepo) Attach(ctx context.Context, groupID, seriesID int, date time.Time) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Групповая_тренировка" SET "id_серии"=$2, "Дата_в_серии"=$3, "Изменена"=FALSE
+        WHERE "id_групповой_тренировки"=$1
+    `, groupID, seriesID, date))
+}
+
+func (r seriesRepo) SetModified(ctx context.Context, groupID int, modified bool) error {
+	return mustAffect(r.q.ExecContext(ctx,
+		`UPDATE "Групповая_тренировка" SET "Изменена"=$2 WHERE "id_групповой_тренировки"=$1`, groupID, modified))
+}
+
+func (r seriesRepo) Move(ctx context.Context, from, to int, since time.Time) error {
+	if _, err := r.q.ExecContext(ctx, `
+        UPDATE "Групповая_тренировка" SET "id_серии"=$2
+        WHERE "id_серии"=$1 AND "Дата_в_серии" >= $3
+    `, from, to, since); err != nil {
+		return err
+	}
+	_, err := r.q.ExecContext(ctx, `
+        UPDATE "Исключение_серии" SET "id_серии"=$2
+        WHERE "id_серии"=$1 AND "Дата" >= $3
+    `, from, to, since)
+	return err
+}
+
+func (r seriesRepo) Exceptions(ctx context.Context, id int) ([]models.SeriesException, error) {
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT "id_исключения", "id_серии", "Дата", COALESCE("Причина",'')
+        FROM "Исключение_серии"
+        WHERE "id_серии" = $1
+        ORDER BY "Дата"
+    `, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.SeriesException
+	for rows.Next() {
+		var e models.SeriesException
+		if err := rows.Scan(&e.ID, &e.SeriesID, &e.Date, &e.Reason); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+func (r seriesRepo) AddException(ctx context.Context, id int, date time.Time, reason string) (bool, error) {
+	res, err := r.q.ExecContext(ctx, `
+        INSERT INTO "Исключение_серии" ("id_серии","Дата","Причина") VALUES ($1,$2,$3)
+        ON CONFLICT ("id_серии","Дата") DO NOTHING
+    `, id, date, nullIfEmpty(reason))
+	if err != nil {
+		return false, wrapErr(err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (r seriesRepo) DeleteException(ctx context.Context, id int, date time.Time) error {
+	return mustAffect(r.q.ExecContext(ctx,
+		`DELETE FROM "Исключение_серии" WHERE "id_серии"=$1 AND "Дата"=$2`, id, date))
+}
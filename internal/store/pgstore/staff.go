@@ -0,0 +1,144 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"fitness-center-manager/internal/auth"
+	"fitness-center-manager/internal/store"
+
+	"github.com/lib/pq"
+)
+
+type staffRepo struct{ q querier }
+
+const staffSelect = `
+    SELECT s."id_сотрудника", s."Логин", s."ФИО", s."Роль",
+           COALESCE(s."id_тренера", 0), COALESCE(t."ФИО", ''),
+           s."Активен", s."Дата_создания"
+    FROM "Сотрудник" s
+    LEFT JOIN "Тренер" t ON t."id_тренера" = s."id_тренера"`
+
+func scanStaffMember(row scanner) (store.StaffMember, error) {
+	var s store.StaffMember
+	err := row.Scan(&s.ID, &s.Login, &s.FIO, &s.Role, &s.TrainerID, &s.TrainerName, &s.Active, &s.CreatedAt)
+	return s, err
+}
+
+func (r staffRepo) List(ctx context.Context) ([]store.StaffMember, error) {
+	return r.list(ctx, staffSelect+` ORDER BY s."Активен" DESC, s."ФИО"`)
+}
+
+func (r staffRepo) Active(ctx context.Context, roles ...string) ([]store.StaffMember, error) {
+	return r.list(ctx, staffSelect+` WHERE s."Активен" AND s."Роль" = ANY($1) ORDER BY s."ФИО"`, pq.Array(roles))
+}
+
+func (r staffRepo) list(ctx context.Context, query string, args ...any) ([]store.StaffMember, error) {
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.StaffMember
+	for rows.Next() {
+		s, err := scanStaffMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+func (r staffRepo) Get(ctx context.Context, id int) (store.StaffMember, error) {
+	s, err := scanStaffMember(r.q.QueryRowContext(ctx, staffSelect+` WHERE s."id_сотрудника"=$1`, id))
+	return s, wrapErr(err)
+}
+
+func (r staffRepo) Count(ctx context.Context) (int, error) {
+	var n int
+	err := r.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM "Сотрудник"`).Scan(&n)
+	return n, err
+}
+
+func (r staffRepo) ByLogin(ctx context.Context, login string) (auth.Staff, string, bool, error) {
+	var (
+		s         auth.Staff
+		hash      string
+		active    bool
+		trainerID sql.NullInt64
+	)
+	err := r.q.QueryRowContext(ctx, `
+        SELECT "id_сотрудника", "Логин", "ФИО", "Роль", "id_тренера", "Хеш_пароля", "Активен"
+        FROM "Сотрудник"
+        WHERE LOWER("Логин") = LOWER($1)
+    `, login).Scan(&s.ID, &s.Login, &s.FIO, &s.Role, &trainerID, &hash, &active)
+	s.TrainerID = int(trainerID.Int64)
+	return s, hash, active, wrapErr(err)
+}
+
+func (r staffRepo) Create(ctx context.Context, in store.StaffInput) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Сотрудник" ("Логин","Хеш_пароля","ФИО","Роль","id_тренера")
+        VALUES ($1,$2,$3,$4,$5)
+        RETURNING "id_сотрудника"
+    `, in.Login, in.PasswordHash, in.FIO, in.Role, nullIfZero(in.TrainerID)).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r staffRepo) Update(ctx context.Context, id int, in store.StaffInput) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Сотрудник"
+        SET "ФИО"=$2, "Роль"=$3, "id_тренера"=$4, "Активен"=$5,
+            "Хеш_пароля"=COALESCE($6, "Хеш_пароля")
+        WHERE "id_сотрудника"=$1
+    `, id, in.FIO, in.Role, nullIfZero(in.TrainerID), in.Active, nullIfEmpty(in.PasswordHash)))
+}
+
+func (r staffRepo) Delete(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Сотрудник" WHERE "id_сотрудника"=$1`, id))
+}
+
+type sessionRepo struct{ q querier }
+
+func (r sessionRepo) Staff(ctx context.Context, tokenHash string) (auth.Staff, error) {
+	var (
+		s         auth.Staff
+		trainerID sql.NullInt64
+	)
+	err := r.q.QueryRowContext(ctx, `
+        SELECT st."id_сотрудника", st."Логин", st."ФИО", st."Роль", st."id_тренера"
+        FROM "Сессия_сотрудника" ss
+        JOIN "Сотрудник" st ON st."id_сотрудника" = ss."id_сотрудника"
+        WHERE ss."Токен_хеш" = $1
+          AND ss."Истекает" > NOW()
+          AND st."Активен"
+    `, tokenHash).Scan(&s.ID, &s.Login, &s.FIO, &s.Role, &trainerID)
+	s.TrainerID = int(trainerID.Int64)
+	return s, wrapErr(err)
+}
+
+func (r sessionRepo) Create(ctx context.Context, tokenHash string, staffID int, expires time.Time) error {
+	_, err := r.q.ExecContext(ctx, `
+        INSERT INTO "Сессия_сотрудника" ("Токен_хеш","id_сотрудника","Истекает")
+        VALUES ($1,$2,$3)
+    `, tokenHash, staffID, expires)
+	return wrapErr(err)
+}
+
+func (r sessionRepo) Delete(ctx context.Context, tokenHash string) error {
+	_, err := r.q.ExecContext(ctx, `DELETE FROM "Сессия_сотрудника" WHERE "Токен_хеш"=$1`, tokenHash)
+	return err
+}
+
+func (r sessionRepo) DeleteByStaff(ctx context.Context, staffID int) error {
+	_, err := r.q.ExecContext(ctx, `DELETE FROM "Сессия_сотрудника" WHERE "id_сотрудника"=$1`, staffID)
+	return err
+}
+
+func (r sessionRepo) DeleteExpired(ctx context.Context) error {
+	_, err := r.q.ExecContext(ctx, `DELETE FROM "Сессия_сотрудника" WHERE "Истекает" < NOW()`)
+	return err
+}
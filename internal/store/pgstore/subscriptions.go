@@ -0,0 +1,199 @@
+package pgstore
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/checkin"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type subscriptionRepo struct {
+	q   querier
+	loc *time.Location
+}
+
+// today — текущий день в часовом поясе клуба (а не сессии БД).
+func (r subscriptionRepo) today() time.Time { return checkin.Day(time.Now().In(r.loc)) }
+
+// subscriptionSelect — $1 — сегодняшний день клуба (для идущей заморозки).
+const subscriptionSelect = `
+    SELECT s."id_абонемента",
+           s."id_клиента",
+           s."id_тарифа",
+           s."Дата_начала",
+           s."Дата_окончания",
+           s."Статус",
+           s."Цена",
+           c."ФИО"              AS client_name,
+           t."Название_тарифа"  AS tariff_name,
+           t."Часы_доступа"     AS tariff_hours,
+           (SELECT MAX(f."Дата_окончания") FROM "Заморозка_абонемента" f
+             WHERE f."id_абонемента" = s."id_абонемента"
+               AND $1::date BETWEEN f."Дата_начала" AND f."Дата_окончания") AS frozen_until,
+           s."id_предыдущего",
+           (SELECT n."id_абонемента" FROM "Абонемент" n
+             WHERE n."id_предыдущего" = s."id_абонемента") AS renewal_id,
+           COALESCE((SELECT SUM(` + signedAmount + `) FROM "Платёж" p
+             WHERE p."id_абонемента" = s."id_абонемента"), 0) AS paid
+    FROM "Абонемент" s
+    JOIN "Клиент" c ON c."id_клиента" = s."id_клиента"
+    JOIN "Тариф"  t ON t."id_тарифа"  = s."id_тарифа"`
+
+func scanSubscription(row scanner) (models.Subscription, error) {
+	var s models.Subscription
+	err := row.Scan(&s.ID, &s.ClientID, &s.TariffID, &s.StartDate, &s.EndDate,
+		&s.Status, &s.Price, &s.ClientName, &s.TariffName, &s.TariffHours, &s.FrozenUntil,
+		&s.PreviousID, &s.RenewalID, &s.Paid)
+	return s, err
+}
+
+func (r subscriptionRepo) List(ctx context.Context) ([]models.Subscription, error) {
+	rows, err := r.q.QueryContext(ctx, subscriptionSelect+` ORDER BY s."id_абонемента" DESC`, r.today())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.Subscription
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+func (r subscriptionRepo) Get(ctx context.Context, id int) (models.Subscription, error) {
+	s, err := scanSubscription(r.q.QueryRowContext(ctx, subscriptionSelect+` WHERE s."id_абонемента"=$2`, r.today(), id))
+	return s, wrapErr(err)
+}
+
+func (r subscriptionRepo) Lock(ctx context.Context, id int) error {
+	var one int
+	err := r.q.QueryRowContext(ctx, `
+        SELECT 1 FROM "Абонемент" WHERE "id_абонемента" = $1 FOR UPDATE
+    `, id).Scan(&one)
+	return wrapErr(err)
+}
+
+func (r subscriptionRepo) ListByClient(ctx context.Context, clientID int) ([]models.Subscription, error) {
+	rows, err := r.q.QueryContext(ctx, subscriptionSelect+` WHERE s."id_клиента"=$2 ORDER BY s."Дата_окончания" DESC, s."id_абонемента" DESC`, r.today(), clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.Subscription
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+func (r subscriptionRepo) Options(ctx context.Context) ([]store.SubscriptionOption, error) {
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT s."id_абонемента", c."ФИО", s."Статус"
+        FROM "Абонемент" s
+        JOIN "Клиент" c ON c."id_клиента" = s."id_клиента"
+        ORDER BY s."id_абонемента" DESC
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.SubscriptionOption
+	for rows.Next() {
+		var o store.SubscriptionOption
+		if err := rows.Scan(&o.ID, &o.ClientName, &o.Status); err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
+// Create без цены берёт стоимость тарифа.
+func (r subscriptionRepo) Create(ctx context.Context, in store.SubscriptionInput) (int, error) {
+	var price float64
+	if in.Price != nil {
+		price = *in.Price
+	} else if err := r.q.QueryRowContext(ctx, `SELECT "Стоимость" FROM "Тариф" WHERE "id_тарифа"=$1`, in.TariffID).Scan(&price); err != nil {
+		return 0, wrapErr(err)
+	}
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Абонемент" ("id_клиента","id_тарифа","Дата_начала","Дата_окончания","Статус","Цена","id_предыдущего")
+        VALUES ($1,$2,$3,$4,$5,$6,$7)
+        RETURNING "id_абонемента"
+    `, in.ClientID, in.TariffID, in.StartDate, in.EndDate, in.Status, price, nullIfZero(in.PreviousID)).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r subscriptionRepo) Update(ctx context.Context, id int, in store.SubscriptionInput) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Абонемент"
+        SET "id_клиента"=$2, "id_тарифа"=$3, "Дата_начала"=$4, "Дата_окончания"=$5, "Статус"=$6,
+            "Цена"=COALESCE($7::numeric, "Цена")
+        WHERE "id_абонемента"=$1
+    `, id, in.ClientID, in.TariffID, in.StartDate, in.EndDate, in.Status, nullableFloat(in.Price)))
+}
+
+func (r subscriptionRepo) Delete(ctx context.Context, id int) error {
+	if _, err := r.q.ExecContext(ctx, `DELETE FROM "Персональная_тренировка" WHERE "id_абонемента" = $1`, id); err != nil {
+		return wrapErr(err)
+	}
+	if _, err := r.q.ExecContext(ctx, `DELETE FROM "Запись_на_групповую_тренировку" WHERE "id_абонемента" = $1`, id); err != nil {
+		return wrapErr(err)
+	}
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Абонемент" WHERE "id_абонемента" = $1`, id))
+}
+
+// changeStatus — UPDATE ... RETURNING для фоновых задач: прежний статус
+// берётся из той же строки до обновления.
+func (r subscriptionRepo) changeStatus(ctx context.Context, query string, today time.Time) ([]store.StatusChange, error) {
+	rows, err := r.q.QueryContext(ctx, query, today)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.StatusChange
+	for rows.Next() {
+		var ch store.StatusChange
+		if err := rows.Scan(&ch.ID, &ch.ClientName, &ch.From, &ch.To); err != nil {
+			return nil, err
+		}
+		list = append(list, ch)
+	}
+	return list, rows.Err()
+}
+
+func (r subscriptionRepo) Expire(ctx context.Context, today time.Time) ([]store.StatusChange, error) {
+	return r.changeStatus(ctx, `
+        UPDATE "Абонемент" s SET "Статус" = 'Завершен'
+        FROM "Абонемент" old
+        JOIN "Клиент" c ON c."id_клиента" = old."id_клиента"
+        WHERE old."id_абонемента" = s."id_абонемента"
+          AND s."Статус" <> 'Завершен'
+          AND s."Дата_окончания" < $1::date
+        RETURNING s."id_абонемента", c."ФИО", old."Статус", s."Статус"
+    `, today)
+}
+
+func (r subscriptionRepo) Activate(ctx context.Context, today time.Time) ([]store.StatusChange, error) {
+	return r.changeStatus(ctx, `
+        UPDATE "Абонемент" s SET "Статус" = 'Активен'
+        FROM "Абонемент" old
+        JOIN "Клиент" c ON c."id_клиента" = old."id_клиента"
+        WHERE old."id_абонемента" = s."id_абонемента"
+          AND s."Статус" = 'Ожидает'
+          AND s."Дата_начала" <= $1::date
+          AND s."Дата_окончания" >= $1::date
+        RETURNING s."id_абонемента", c."ФИО", old."Статус", s."Статус"
+    `, today)
+}
@@ -0,0 +1,90 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type tariffRepo struct{ q querier }
+
+const tariffSelect = `
+    SELECT
+        "id_тарифа",
+        "Название_тарифа",
+        COALESCE("Описание", ''),
+        COALESCE("Стоимость", 0),
+        COALESCE("Время_доступа", '0 hours'::interval),
+        COALESCE("Наличие_групповых_тренировок", false),
+        COALESCE("Наличие_персональных_тренировок", false),
+        "Часы_доступа",
+        COALESCE("Часы_доступа_ошибка", ''),
+        "Заморозка_макс_дней",
+        "Заморозка_макс_раз"
+    FROM "Тариф"`
+
+func scanTariff(row scanner) (models.Tariff, error) {
+	var t models.Tariff
+	var access sql.NullString
+	if err := row.Scan(&t.ID, &t.Name, &t.Description, &t.Price, &access, &t.HasGroupTrainings, &t.HasPersonalTrainings,
+		&t.AccessHours, &t.AccessHoursIssue, &t.FreezeMaxDays, &t.FreezeMaxCount); err != nil {
+		return t, err
+	}
+	t.AccessTime = access.String
+	return t, nil
+}
+
+func (r tariffRepo) List(ctx context.Context) ([]models.Tariff, error) {
+	rows, err := r.q.QueryContext(ctx, tariffSelect+` ORDER BY "id_тарифа" DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.Tariff
+	for rows.Next() {
+		t, err := scanTariff(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+func (r tariffRepo) Get(ctx context.Context, id int) (models.Tariff, error) {
+	t, err := scanTariff(r.q.QueryRowContext(ctx, tariffSelect+` WHERE "id_тарифа"=$1`, id))
+	return t, wrapErr(err)
+}
+
+func (r tariffRepo) Create(ctx context.Context, in store.TariffInput) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Тариф" ("Название_тарифа","Описание","Стоимость","Часы_доступа","Наличие_групповых_тренировок","Наличие_персональных_тренировок",
+                             "Заморозка_макс_дней","Заморозка_макс_раз")
+        VALUES ($1,$2,$3, $4::jsonb, $5, $6, $7, $8)
+        RETURNING "id_тарифа"
+    `, in.Name, in.Description, in.Price, in.AccessHours, in.HasGroup, in.HasPersonal, in.FreezeMaxDays, in.FreezeMaxCount).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r tariffRepo) Update(ctx context.Context, id int, in store.TariffInput) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Тариф"
+        SET "Название_тарифа"=$2,
+            "Описание"=$3,
+            "Стоимость"=$4,
+            "Часы_доступа"=$5::jsonb,
+            "Часы_доступа_ошибка"=NULL,
+            "Наличие_групповых_тренировок"=$6,
+            "Наличие_персональных_тренировок"=$7,
+            "Заморозка_макс_дней"=$8,
+            "Заморозка_макс_раз"=$9
+        WHERE "id_тарифа"=$1
+    `, id, in.Name, in.Description, in.Price, in.AccessHours, in.HasGroup, in.HasPersonal, in.FreezeMaxDays, in.FreezeMaxCount))
+}
+
+func (r tariffRepo) Delete(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Тариф" WHERE "id_тарифа"=$1`, id))
+}
@@ -0,0 +1,81 @@
+package pgstore
+
+import (
+	"context"
+
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type trainerRepo struct{ q querier }
+
+const trainerSelect = `
+    SELECT "id_тренера", "ФИО", "Номер_телефона", "Специализация", "Дата_найма", "Стаж_работы"
+    FROM "Тренер"`
+
+func scanTrainer(row scanner) (models.Trainer, error) {
+	var t models.Trainer
+	err := row.Scan(&t.ID, &t.FIO, &t.Phone, &t.Specialization, &t.HireDate, &t.Experience)
+	return t, err
+}
+
+func (r trainerRepo) List(ctx context.Context) ([]models.Trainer, error) {
+	rows, err := r.q.QueryContext(ctx, trainerSelect+` ORDER BY "id_тренера" DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.Trainer
+	for rows.Next() {
+		t, err := scanTrainer(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+func (r trainerRepo) Get(ctx context.Context, id int) (models.Trainer, error) {
+	t, err := scanTrainer(r.q.QueryRowContext(ctx, trainerSelect+` WHERE "id_тренера"=$1`, id))
+	return t, wrapErr(err)
+}
+
+func (r trainerRepo) Options(ctx context.Context) ([]store.Option, error) {
+	rows, err := r.q.QueryContext(ctx, `SELECT "id_тренера","ФИО" FROM "Тренер" ORDER BY "ФИО"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.Option
+	for rows.Next() {
+		var o store.Option
+		if err := rows.Scan(&o.ID, &o.Name); err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
+func (r trainerRepo) Create(ctx context.Context, in store.TrainerInput) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Тренер" ("ФИО","Номер_телефона","Специализация","Дата_найма","Стаж_работы")
+        VALUES ($1,$2,$3,$4,$5)
+        RETURNING "id_тренера"
+    `, in.FIO, in.Phone, in.Specialization, in.HireDate, in.Experience).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r trainerRepo) Update(ctx context.Context, id int, in store.TrainerInput) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Тренер"
+        SET "ФИО"=$2, "Номер_телефона"=$3, "Специализация"=$4, "Дата_найма"=$5, "Стаж_работы"=$6
+        WHERE "id_тренера"=$1
+    `, id, in.FIO, in.Phone, in.Specialization, in.HireDate, in.Experience))
+}
+
+func (r trainerRepo) Delete(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Тренер" WHERE "id_тренера"=$1`, id))
+}
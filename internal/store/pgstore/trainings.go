@@ -0,0 +1,382 @@
+package pgstore
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/enrollment"
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type trainingRepo struct{ q querier }
+
+// ---------------- Групповые (vw_group_training_with_slots) ----------------
+
+const groupSelect = `
+    SELECT
+        v."id_групповой_тренировки",
+        v."Название",
+        COALESCE(v."Описание",'')               AS description,
+        COALESCE(v."Максимум_участников",0)     AS max,
+        v."Время_начала",
+        v."Время_окончания",
+        COALESCE(v."Уровень_сложности",'')      AS level,
+        v.trainer_name,
+        v."id_тренера",
+        v.zone_name,
+        v."id_зоны",
+        v.free_slots,
+        COALESCE(v."id_серии",0),
+        v."Дата_в_серии",
+        v."Изменена"
+    FROM vw_group_training_with_slots v`
+
+func scanGroup(row scanner) (models.GroupTraining, error) {
+	var g models.GroupTraining
+	err := row.Scan(&g.ID, &g.Name, &g.Description, &g.MaxParticipants, &g.StartTime, &g.EndTime,
+		&g.DifficultyLevel, &g.TrainerName, &g.TrainerID, &g.ZoneName, &g.ZoneID, &g.FreeSlots,
+		&g.SeriesID, &g.SeriesDate, &g.Modified)
+	return g, err
+}
+
+// timeConds — общие для обоих видов тренировок условия по времени начала.
+func timeConds(w *where, f store.TrainingFilter) {
+	if !f.From.IsZero() {
+		w.add(`v."Время_начала" >= ` + w.ph(f.From))
+	}
+	if !f.To.IsZero() {
+		w.add(`v."Время_начала" <= ` + w.ph(f.To))
+	}
+	if f.Upcoming {
+		w.add(`v."Время_начала" >= ` + w.ph(time.Now()))
+	}
+	if f.Recent {
+		w.add(`v."Время_начала" >= NOW() - INTERVAL '30 days'`)
+	}
+}
+
+func (r trainingRepo) ListGroups(ctx context.Context, f store.TrainingFilter) ([]models.GroupTraining, error) {
+	var w where
+	if f.Query != "" {
+		like := "%" + f.Query + "%"
+		w.add(`(v."Название" ILIKE ` + w.ph(like) +
+			` OR v.trainer_name ILIKE ` + w.ph(like) +
+			` OR v.zone_name ILIKE ` + w.ph(like) + `)`)
+	}
+	if f.TrainerID > 0 {
+		w.add(`v."id_тренера" = ` + w.ph(f.TrainerID))
+	}
+	if f.ZoneID > 0 {
+		w.add(`v."id_зоны" = ` + w.ph(f.ZoneID))
+	}
+	if f.Level != "" {
+		w.add(`v."Уровень_сложности" = ` + w.ph(f.Level))
+	}
+	timeConds(&w, f)
+
+	rows, err := r.q.QueryContext(ctx, groupSelect+w.sql()+
+		` ORDER BY v."Время_начала" DESC, v."id_групповой_тренировки" DESC`, w.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.GroupTraining
+	for rows.Next() {
+		g, err := scanGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, g)
+	}
+	return list, rows.Err()
+}
+
+func (r trainingRepo) GetGroup(ctx context.Context, id int) (models.GroupTraining, error) {
+	g, err := scanGroup(r.q.QueryRowContext(ctx, groupSelect+` WHERE v."id_групповой_тренировки"=$1`, id))
+	return g, wrapErr(err)
+}
+
+func (r trainingRepo) CreateGroup(ctx context.Context, in store.GroupTrainingInput) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Групповая_тренировка"
+        ("id_тренера","id_зоны","Название","Описание","Максимум_участников","Время_начала","Время_окончания","Уровень_сложности",
+         "Пересечение_разрешено")
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+        RETURNING "id_групповой_тренировки"
+    `, in.TrainerID, in.ZoneID, in.Title, nullIfEmpty(in.Description), in.Max, in.Start, in.End, nullIfEmpty(in.Level),
+		in.AllowOverlap).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r trainingRepo) UpdateGroup(ctx context.Context, id int, in store.GroupTrainingInput) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Групповая_тренировка"
+        SET "id_тренера"=$2,"id_зоны"=$3,"Название"=$4,"Описание"=$5,"Максимум_участников"=$6,
+            "Время_начала"=$7,"Время_окончания"=$8,"Уровень_сложности"=$9,"Пересечение_разрешено"=$10
+        WHERE "id_групповой_тренировки"=$1
+    `, id, in.TrainerID, in.ZoneID, in.Title, nullIfEmpty(in.Description), in.Max, in.Start, in.End, nullIfEmpty(in.Level),
+		in.AllowOverlap))
+}
+
+func (r trainingRepo) DeleteGroup(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Групповая_тренировка" WHERE "id_групповой_тренировки"=$1`, id))
+}
+
+// ---------------- Персональные (vw_personal_training_enriched) ----------------
+
+const personalSelect = `
+    SELECT
+        v."id_персональной_тренировки",
+        v."Время_начала",
+        v."Время_окончания",
+        v."Статус",
+        COALESCE(v."Стоимость",0)     AS price,
+        v."id_абонемента",
+        v."id_клиента",
+        v.client_fio,
+        v."id_тренера",
+        v.trainer_fio,
+        COALESCE((SELECT SUM(` + signedAmount + `) FROM "Платёж" p
+          WHERE p."id_персональной_тренировки" = v."id_персональной_тренировки"), 0) AS paid,
+        COALESCE(v."Основание_исключения",''),
+        v."Расчёт_стоимости"
+    FROM vw_personal_training_enriched v`
+
+func scanPersonal(row scanner) (models.PersonalTraining, error) {
+	var p models.PersonalTraining
+	err := row.Scan(&p.ID, &p.StartTime, &p.EndTime, &p.Status, &p.Price,
+		&p.SubscriptionID, &p.ClientID, &p.ClientName, &p.TrainerID, &p.TrainerName, &p.Paid, &p.OverrideReason,
+		rateSnapshot{&p.Rate})
+	return p, err
+}
+
+func (r trainingRepo) ListPersonal(ctx context.Context, f store.TrainingFilter) ([]models.PersonalTraining, error) {
+	var w where
+	if f.Query != "" {
+		like := "%" + f.Query + "%"
+		w.add(`(v.client_fio ILIKE ` + w.ph(like) + ` OR v.trainer_fio ILIKE ` + w.ph(like) + `)`)
+	}
+	if f.TrainerID > 0 {
+		w.add(`v."id_тренера" = ` + w.ph(f.TrainerID))
+	}
+	if f.Status != "" {
+		w.add(`v."Статус" = ` + w.ph(f.Status))
+	}
+	timeConds(&w, f)
+
+	rows, err := r.q.QueryContext(ctx, personalSelect+w.sql()+
+		` ORDER BY v."Время_начала" DESC, v."id_персональной_тренировки" DESC`, w.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.PersonalTraining
+	for rows.Next() {
+		p, err := scanPersonal(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+func (r trainingRepo) LockPersonal(ctx context.Context, id int) error {
+	var one int
+	err := r.q.QueryRowContext(ctx, `
+        SELECT 1 FROM "Персональная_тренировка" WHERE "id_персональной_тренировки" = $1 FOR UPDATE
+    `, id).Scan(&one)
+	return wrapErr(err)
+}
+
+func (r trainingRepo) GetPersonal(ctx context.Context, id int) (models.PersonalTraining, error) {
+	p, err := scanPersonal(r.q.QueryRowContext(ctx, personalSelect+` WHERE v."id_персональной_тренировки"=$1`, id))
+	return p, wrapErr(err)
+}
+
+func (r trainingRepo) CreatePersonal(ctx context.Context, in store.PersonalTrainingInput) (int, error) {
+	rate, err := snapshotValue(in.Rate)
+	if err != nil {
+		return 0, err
+	}
+	var id int
+	err = r.q.QueryRowContext(ctx, `
+        INSERT INTO "Персональная_тренировка"
+        ("id_абонемента","id_тренера","Время_начала","Время_окончания","Статус","Стоимость","Пересечение_разрешено",
+         "Основание_исключения","Расчёт_стоимости")
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+        RETURNING "id_персональной_тренировки"
+    `, in.SubscriptionID, in.TrainerID, in.Start, in.End, in.Status, nullableFloat(in.Price), in.AllowOverlap,
+		nullIfEmpty(in.OverrideReason), rate).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r trainingRepo) UpdatePersonal(ctx context.Context, id int, in store.PersonalTrainingInput) error {
+	rate, err := snapshotValue(in.Rate)
+	if err != nil {
+		return err
+	}
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Персональная_тренировка"
+        SET "id_абонемента"=$2,"id_тренера"=$3,"Время_начала"=$4,"Время_окончания"=$5,"Статус"=$6,"Стоимость"=$7,
+            "Пересечение_разрешено"=$8,"Основание_исключения"=$9,"Расчёт_стоимости"=$10
+        WHERE "id_персональной_тренировки"=$1
+    `, id, in.SubscriptionID, in.TrainerID, in.Start, in.End, in.Status, nullableFloat(in.Price), in.AllowOverlap,
+		nullIfEmpty(in.OverrideReason), rate))
+}
+
+func (r trainingRepo) DeletePersonal(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Персональная_тренировка" WHERE "id_персональной_тренировки"=$1`, id))
+}
+
+// ---------------- Пересечения по расписанию ----------------
+
+func (r trainingRepo) Conflicts(ctx context.Context, q store.ConflictQuery) ([]models.ScheduleConflict, error) {
+	// интервалы полуоткрытые: занятие, кончающееся в 19:00, не мешает начинающемуся в 19:00
+	rows, err := r.q.QueryContext(ctx, `
+        SELECT 'group', g."id_групповой_тренировки", g."Название", g."Время_начала", g."Время_окончания",
+               g."id_тренера" = $1, g."id_зоны" = $2, t."ФИО", z."Название"
+        FROM "Групповая_тренировка" g
+        JOIN "Тренер" t ON t."id_тренера" = g."id_тренера"
+        JOIN "Зона"   z ON z."id_зоны"    = g."id_зоны"
+        WHERE (g."id_тренера" = $1 OR g."id_зоны" = $2)
+          AND g."Время_начала" < $4 AND g."Время_окончания" > $3
+          AND g."id_групповой_тренировки" <> $5
+        UNION ALL
+        SELECT 'personal', p."id_персональной_тренировки", 'Персональная: ' || c."ФИО", p."Время_начала", p."Время_окончания",
+               TRUE, FALSE, t."ФИО", ''
+        FROM "Персональная_тренировка" p
+        JOIN "Абонемент" a ON a."id_абонемента" = p."id_абонемента"
+        JOIN "Клиент"    c ON c."id_клиента"    = a."id_клиента"
+        JOIN "Тренер"    t ON t."id_тренера"    = p."id_тренера"
+        WHERE p."id_тренера" = $1 AND p."Статус" <> 'Отменена'
+          AND p."Время_начала" < $4 AND p."Время_окончания" > $3
+          AND p."id_персональной_тренировки" <> $6
+        ORDER BY 4, 2
+    `, q.TrainerID, q.ZoneID, q.Start, q.End, q.ExcludeGroupID, q.ExcludePersonalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.ScheduleConflict
+	for rows.Next() {
+		var x models.ScheduleConflict
+		if err := rows.Scan(&x.Kind, &x.ID, &x.Title, &x.StartTime, &x.EndTime,
+			&x.SameTrainer, &x.SameZone, &x.TrainerName, &x.ZoneName); err != nil {
+			return nil, err
+		}
+		list = append(list, x)
+	}
+	return list, rows.Err()
+}
+
+// ---------------- Записи на групповые ----------------
+
+// enrollmentSelect — записи с местом в очереди (по времени записи).
+const enrollmentSelect = `
+    SELECT
+        e."id_записи",
+        e."id_групповой_тренировки",
+        e."Статус",
+        e."Записан_в",
+        CASE WHEN e."Статус" = 'В очереди' THEN
+            (SELECT COUNT(*) FROM "Запись_на_групповую_тренировку" w
+              WHERE w."id_групповой_тренировки" = e."id_групповой_тренировки"
+                AND w."Статус" = 'В очереди'
+                AND (w."Записан_в", w."id_записи") <= (e."Записан_в", e."id_записи"))
+        ELSE 0 END AS queue_position,
+        s."id_абонемента",
+        c."id_клиента",
+        c."ФИО",
+        g."Название",
+        COALESCE(e."Основание_исключения",'')
+    FROM "Запись_на_групповую_тренировку" e
+    JOIN "Абонемент" s ON s."id_абонемента" = e."id_абонемента"
+    JOIN "Клиент"    c ON c."id_клиента"    = s."id_клиента"
+    JOIN "Групповая_тренировка" g ON g."id_групповой_тренировки" = e."id_групповой_тренировки"`
+
+func scanEnrollment(row scanner) (models.GroupTrainingRegistration, error) {
+	var e models.GroupTrainingRegistration
+	err := row.Scan(&e.ID, &e.GroupTrainingID, &e.Status, &e.EnrolledAt, &e.QueuePosition,
+		&e.SubscriptionID, &e.ClientID, &e.ClientName, &e.TrainingName, &e.OverrideReason)
+	return e, err
+}
+
+func (r trainingRepo) ListEnrollments(ctx context.Context, groupID int) ([]models.GroupTrainingRegistration, error) {
+	rows, err := r.q.QueryContext(ctx, enrollmentSelect+`
+        WHERE e."id_групповой_тренировки" = $1
+        ORDER BY e."id_записи" DESC
+    `, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.GroupTrainingRegistration
+	for rows.Next() {
+		e, err := scanEnrollment(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+func (r trainingRepo) GetEnrollment(ctx context.Context, id int) (models.GroupTrainingRegistration, error) {
+	e, err := scanEnrollment(r.q.QueryRowContext(ctx, enrollmentSelect+` WHERE e."id_записи" = $1`, id))
+	return e, wrapErr(err)
+}
+
+func (r trainingRepo) SetEnrollmentStatus(ctx context.Context, id int, status string) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Запись_на_групповую_тренировку"
+        SET "Статус" = $2,
+            "Записан_в" = CASE WHEN $2 = 'В очереди' THEN NOW() ELSE "Записан_в" END
+        WHERE "id_записи" = $1
+    `, id, status))
+}
+
+func (r trainingRepo) DeleteEnrollment(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Запись_на_групповую_тренировку" WHERE "id_записи"=$1`, id))
+}
+
+func (r trainingRepo) LockOccupancy(ctx context.Context, groupID int) (enrollment.Occupancy, error) {
+	var o enrollment.Occupancy
+	err := r.q.QueryRowContext(ctx, `
+        SELECT "Максимум_участников" FROM "Групповая_тренировка"
+        WHERE "id_групповой_тренировки" = $1
+        FOR UPDATE
+    `, groupID).Scan(&o.Max)
+	if err != nil {
+		return o, wrapErr(err)
+	}
+	err = r.q.QueryRowContext(ctx, `
+        SELECT COUNT(*) FILTER (WHERE "Статус" IN ('Записан','Посетил')),
+               COUNT(*) FILTER (WHERE "Статус" = 'В очереди')
+        FROM "Запись_на_групповую_тренировку"
+        WHERE "id_групповой_тренировки" = $1
+    `, groupID).Scan(&o.Taken, &o.Waiting)
+	return o, err
+}
+
+func (r trainingRepo) FirstWaiting(ctx context.Context, groupID int) (models.GroupTrainingRegistration, error) {
+	e, err := scanEnrollment(r.q.QueryRowContext(ctx, enrollmentSelect+`
+        WHERE e."id_групповой_тренировки" = $1 AND e."Статус" = 'В очереди'
+        ORDER BY e."Записан_в", e."id_записи"
+        LIMIT 1
+    `, groupID))
+	return e, wrapErr(err)
+}
+
+func (r trainingRepo) Enroll(ctx context.Context, groupID, subscriptionID int, status, overrideReason string) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Запись_на_групповую_тренировку"
+        ("id_групповой_тренировки","id_абонемента","Статус","Основание_исключения")
+        VALUES ($1,$2,$3,$4)
+        RETURNING "id_записи"
+    `, groupID, subscriptionID, status, nullIfEmpty(overrideReason)).Scan(&id)
+	return id, wrapErr(err)
+}
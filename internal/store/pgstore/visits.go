@@ -0,0 +1,92 @@
+package pgstore
+
+import (
+	"context"
+
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type visitRepo struct{ q querier }
+
+const visitSelect = `
+    SELECT v."id_посещения",
+           v."id_клиента",
+           v."id_абонемента",
+           v."Время_входа",
+           v."Время_выхода",
+           c."ФИО"                              AS client_name,
+           COALESCE(t."Название_тарифа", '')    AS tariff_name
+    FROM "Посещение" v
+    JOIN "Клиент" c        ON c."id_клиента"    = v."id_клиента"
+    LEFT JOIN "Абонемент" s ON s."id_абонемента" = v."id_абонемента"
+    LEFT JOIN "Тариф" t     ON t."id_тарифа"     = s."id_тарифа"`
+
+func scanVisit(row scanner) (models.Visit, error) {
+	var v models.Visit
+	err := row.Scan(&v.ID, &v.ClientID, &v.SubscriptionID, &v.EnteredAt, &v.ExitedAt,
+		&v.ClientName, &v.TariffName)
+	return v, err
+}
+
+func (r visitRepo) list(ctx context.Context, query string, args ...any) ([]models.Visit, error) {
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.Visit
+	for rows.Next() {
+		v, err := scanVisit(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, rows.Err()
+}
+
+func (r visitRepo) Open(ctx context.Context, clientID int) (models.Visit, error) {
+	v, err := scanVisit(r.q.QueryRowContext(ctx,
+		visitSelect+` WHERE v."id_клиента"=$1 AND v."Время_выхода" IS NULL`, clientID))
+	return v, wrapErr(err)
+}
+
+func (r visitRepo) Inside(ctx context.Context) ([]models.Visit, error) {
+	return r.list(ctx, visitSelect+` WHERE v."Время_выхода" IS NULL ORDER BY v."Время_входа"`)
+}
+
+func (r visitRepo) ListByClient(ctx context.Context, clientID, limit int) ([]models.Visit, error) {
+	return r.list(ctx, visitSelect+` WHERE v."id_клиента"=$1 ORDER BY v."Время_входа" DESC LIMIT $2`, clientID, limit)
+}
+
+func (r visitRepo) Stats(ctx context.Context) (store.VisitStats, error) {
+	var st store.VisitStats
+	err := r.q.QueryRowContext(ctx, `
+        SELECT
+            COUNT(*) FILTER (WHERE "Время_входа" >= CURRENT_DATE),
+            COUNT(*) FILTER (WHERE "Время_выхода" IS NULL),
+            COUNT(*) FILTER (WHERE "Время_входа" >= CURRENT_DATE - INTERVAL '30 days'),
+            COUNT(DISTINCT "id_клиента") FILTER (WHERE "Время_входа" >= CURRENT_DATE - INTERVAL '30 days')
+        FROM "Посещение"
+        WHERE "Время_входа" >= CURRENT_DATE - INTERVAL '30 days' OR "Время_выхода" IS NULL
+    `).Scan(&st.Today, &st.Inside, &st.Last30, &st.Clients)
+	return st, err
+}
+
+func (r visitRepo) Enter(ctx context.Context, clientID, subscriptionID, staffID int) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Посещение" ("id_клиента", "id_абонемента", "id_сотрудника")
+        VALUES ($1, $2, NULLIF($3, 0))
+        RETURNING "id_посещения"
+    `, clientID, subscriptionID, staffID).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r visitRepo) Exit(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Посещение" SET "Время_выхода" = NOW()
+        WHERE "id_посещения" = $1 AND "Время_выхода" IS NULL
+    `, id))
+}
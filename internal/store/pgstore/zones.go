@@ -0,0 +1,113 @@
+package pgstore
+
+import (
+	"context"
+
+	"fitness-center-manager/internal/models"
+	"fitness-center-manager/internal/store"
+)
+
+type zoneRepo struct{ q querier }
+
+const zoneSelect = `
+    SELECT
+        "id_зоны", "Название", COALESCE("Описание", ''), "Вместимость", "Статус",
+        ("Фото_ключ" IS NOT NULL OR "Фото" IS NOT NULL) AS has_photo
+    FROM "Зона"`
+
+func scanZone(row scanner) (models.Zone, error) {
+	var z models.Zone
+	err := row.Scan(&z.ID, &z.Name, &z.Description, &z.Capacity, &z.Status, &z.HasPhoto)
+	return z, err
+}
+
+func (r zoneRepo) List(ctx context.Context) ([]models.Zone, error) {
+	rows, err := r.q.QueryContext(ctx, zoneSelect+` ORDER BY "id_зоны" DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []models.Zone
+	for rows.Next() {
+		z, err := scanZone(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, z)
+	}
+	return list, rows.Err()
+}
+
+func (r zoneRepo) Get(ctx context.Context, id int) (models.Zone, error) {
+	z, err := scanZone(r.q.QueryRowContext(ctx, zoneSelect+` WHERE "id_зоны"=$1`, id))
+	return z, wrapErr(err)
+}
+
+func (r zoneRepo) Options(ctx context.Context) ([]store.Option, error) {
+	rows, err := r.q.QueryContext(ctx, `SELECT "id_зоны","Название" FROM "Зона" ORDER BY "id_зоны"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []store.Option
+	for rows.Next() {
+		var o store.Option
+		if err := rows.Scan(&o.ID, &o.Name); err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
+func (r zoneRepo) IDsByName(ctx context.Context, name string, limit int) ([]int, error) {
+	rows, err := r.q.QueryContext(ctx,
+		`SELECT "id_зоны" FROM "Зона" WHERE "Название"=$1 ORDER BY "id_зоны" LIMIT $2`, name, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r zoneRepo) Create(ctx context.Context, in store.ZoneInput) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+        INSERT INTO "Зона" ("Название","Описание","Вместимость","Статус")
+        VALUES ($1,$2,$3,$4)
+        RETURNING "id_зоны"
+    `, in.Name, in.Description, in.Capacity, in.Status).Scan(&id)
+	return id, wrapErr(err)
+}
+
+func (r zoneRepo) Update(ctx context.Context, id int, in store.ZoneInput) error {
+	return mustAffect(r.q.ExecContext(ctx, `
+        UPDATE "Зона"
+        SET "Название"=$2, "Описание"=$3, "Вместимость"=$4, "Статус"=$5
+        WHERE "id_зоны"=$1
+    `, id, in.Name, in.Description, in.Capacity, in.Status))
+}
+
+func (r zoneRepo) SetStatus(ctx context.Context, id int, status string) error {
+	return mustAffect(r.q.ExecContext(ctx, `UPDATE "Зона" SET "Статус"=$2 WHERE "id_зоны"=$1`, id, status))
+}
+
+func (r zoneRepo) Delete(ctx context.Context, id int) error {
+	return mustAffect(r.q.ExecContext(ctx, `DELETE FROM "Зона" WHERE "id_зоны"=$1`, id))
+}
+
+func (r zoneRepo) Photo(ctx context.Context, id int) (store.Photo, error) {
+	return photo(ctx, r.q, "Зона", "id_зоны", id)
+}
+
+func (r zoneRepo) SetPhoto(ctx context.Context, id int, key string) error {
+	return setPhoto(ctx, r.q, "Зона", "id_зоны", id, key)
+}
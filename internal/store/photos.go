@@ -0,0 +1,34 @@
+package store
+
+import "context"
+
+// Photo — фото записи: ключ в хранилище файлов (пакет blob) или, пока
+// фото не перенесено командой `photos migrate`, байты из БД.
+type Photo struct {
+	Key    string
+	Legacy []byte
+}
+
+// Empty — фото не загружено.
+func (p Photo) Empty() bool { return p.Key == "" && len(p.Legacy) == 0 }
+
+// LegacyPhoto — фото, ещё лежащее в БД.
+type LegacyPhoto struct {
+	Kind string // "zone", "equipment" или "repair"
+	ID   int
+	Data []byte
+}
+
+// PhotoRepo — ссылки записей на файлы хранилища и перенос фото из БД.
+type PhotoRepo interface {
+	// InUse — ссылается ли на ключ зона, оборудование или заявка.
+	InUse(ctx context.Context, key string) (bool, error)
+	// Legacy — до limit фото, ещё хранящихся в БД.
+	Legacy(ctx context.Context, limit int) ([]LegacyPhoto, error)
+	// Moved записывает ключ перенесённого фото и очищает байты в БД.
+	Moved(ctx context.Context, p LegacyPhoto, key string) error
+	// Keys — все различные ключи фото в хранилище.
+	Keys(ctx context.Context) ([]string, error)
+	// Replace переводит все записи с ключа old на new.
+	Replace(ctx context.Context, old, new string) error
+}
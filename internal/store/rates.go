@@ -0,0 +1,30 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/access"
+	"fitness-center-manager/internal/models"
+)
+
+// RateInput — новая ставка тренера.
+type RateInput struct {
+	EffectiveFrom    time.Time
+	Category         string
+	HourlyRate       float64
+	PeakHours        access.Schedule
+	PeakSurcharge    float64 // %
+	WeekendSurcharge float64 // %
+}
+
+// RateRepo — ставки тренеров. Ставки не правятся: изменение — новая
+// ставка с новой датой начала действия.
+type RateRepo interface {
+	// List — ставки тренера, новые (по дате начала действия) первыми.
+	List(ctx context.Context, trainerID int) ([]models.TrainerRate, error)
+	Get(ctx context.Context, id int) (models.TrainerRate, error)
+	// Create — ставка с той же датой у тренера уже есть — ErrDuplicate.
+	Create(ctx context.Context, trainerID int, in RateInput) (int, error)
+	Delete(ctx context.Context, id int) error
+}
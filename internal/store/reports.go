@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// DashboardStats — счётчики главной страницы.
+type DashboardStats struct {
+	Clients             int
+	Trainers            int
+	ActiveSubscriptions int
+	UpcomingGroup       int // групповые, которые ещё не начались
+	UpcomingPersonal    int
+	ZonesActive         int
+	ZonesRepair         int
+	ZonesCapacity       int
+	EquipmentTotal      int
+	EquipmentWorking    int
+	EquipmentRepair     int
+	EquipmentNoPhoto    int
+}
+
+// RecentClient — недавно зарегистрированный клиент и его последний абонемент.
+type RecentClient struct {
+	ID         int
+	FIO        string
+	Registered time.Time
+	LastTariff string // "" — абонементов не было
+	LastStatus string
+}
+
+// ExpiringSubscription — активный абонемент, который скоро закончится.
+type ExpiringSubscription struct {
+	ID        int
+	Client    string
+	Tariff    string
+	TariffID  int
+	EndDate   time.Time
+	RenewalID int // 0 — ещё не продлён
+}
+
+// RepairingEquipment — оборудование в статусе «На ремонте».
+type RepairingEquipment struct {
+	Name        string
+	Zone        string    // "—", если зона не указана
+	LastService time.Time // нулевое — ТО не проводилось
+}
+
+// Строки отчётов страницы «Отчетность»; JSON-поля — как в таблицах страницы.
+type (
+	ReportClient struct {
+		ID         int       `json:"id_клиента"`
+		FIO        string    `json:"фио"`
+		Registered time.Time `json:"дата_регистрации"`
+	}
+	ReportSubscription struct {
+		ID     int       `json:"id_абонемента"`
+		Client string    `json:"фио_клиента"`
+		Tariff string    `json:"название_тарифа"`
+		Start  time.Time `json:"дата_начала"`
+		End    time.Time `json:"дата_окончания"`
+		Status string    `json:"статус"`
+		Price  float64   `json:"цена"`
+	}
+	TariffRevenue struct {
+		Tariff  string  `json:"тариф"`
+		Count   int     `json:"количество"`
+		Charged float64 `json:"начислено"` // сумма цен абонементов
+		Revenue float64 `json:"выручка"`   // оплаты минус возвраты
+	}
+	ZoneEquipment struct {
+		ID    int    `json:"id_зоны"`
+		Name  string `json:"название"`
+		Count int    `json:"количество_оборудования"`
+	}
+	ZoneCapacity struct {
+		ID       int    `json:"id_зоны"`
+		Name     string `json:"название"`
+		Capacity int    `json:"вместимость"`
+	}
+	FinishedPersonal struct {
+		ID       int       `json:"id"`
+		Client   string    `json:"клиент"`
+		Trainer  string    `json:"тренер"`
+		Start    time.Time `json:"начало"`
+		Duration int       `json:"длительность_мин"`
+		Price    float64   `json:"стоимость"`
+	}
+)
+
+// ReportRepo — сводки главной страницы и отчёты страницы «Отчетность».
+type ReportRepo interface {
+	Dashboard(ctx context.Context) (DashboardStats, error)
+	// RecentClients — последние limit зарегистрированных клиентов.
+	RecentClients(ctx context.Context, limit int) ([]RecentClient, error)
+	// ExpiringSubscriptions — до limit активных абонементов, заканчивающихся
+	// в ближайшие 30 дней, по дате окончания.
+	ExpiringSubscriptions(ctx context.Context, limit int) ([]ExpiringSubscription, error)
+	// RepairingEquipment — до limit единиц оборудования на ремонте.
+	RepairingEquipment(ctx context.Context, limit int) ([]RepairingEquipment, error)
+
+	// ClientsRegisteredAfter — до 100 клиентов, зарегистрированных позже t.
+	ClientsRegisteredAfter(ctx context.Context, t time.Time) ([]ReportClient, error)
+	// SubscriptionsByStatus — до 200 последних абонементов со статусом status.
+	SubscriptionsByStatus(ctx context.Context, status string) ([]ReportSubscription, error)
+	// RevenueByTariff — выручка по тарифам за абонементы, целиком лежащие
+	// в [from, to]; тарифы с выручкой меньше minRevenue не попадают.
+	RevenueByTariff(ctx context.Context, from, to time.Time, minRevenue float64) ([]TariffRevenue, error)
+	// ZonesWithEquipment — зоны, где оборудования не меньше min.
+	ZonesWithEquipment(ctx context.Context, min int) ([]ZoneEquipment, error)
+	// ZonesAboveAvgCapacity — зоны вместительнее средней и сама средняя.
+	ZonesAboveAvgCapacity(ctx context.Context) ([]ZoneCapacity, float64, error)
+	// FinishedPersonal — завершённые персональные тренировки, начавшиеся
+	// в даты [from, to].
+	FinishedPersonal(ctx context.Context, from, to time.Time) ([]FinishedPersonal, error)
+}
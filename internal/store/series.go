@@ -0,0 +1,48 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+// SeriesInput — поля серии групповых тренировок. Start — первая дата и время
+// начала занятий, Rule — RRULE (проверяет пакет recurrence).
+type SeriesInput struct {
+	TrainerID   int
+	ZoneID      int
+	Title       string
+	Description string
+	Max         int
+	Level       string
+	Start       time.Time
+	DurationMin int
+	Rule        string
+}
+
+// SeriesRepo — серии групповых тренировок, их занятия и исключения.
+// Сами занятия создаются и правятся через TrainingRepo, здесь — только
+// привязка к серии.
+type SeriesRepo interface {
+	List(ctx context.Context) ([]models.TrainingSeries, error)
+	Get(ctx context.Context, id int) (models.TrainingSeries, error)
+	Create(ctx context.Context, in SeriesInput) (int, error)
+	Update(ctx context.Context, id int, in SeriesInput) error
+	// Delete удаляет серию; её занятия остаются разовыми.
+	Delete(ctx context.Context, id int) error
+
+	// Occurrences — занятия серии по дате.
+	Occurrences(ctx context.Context, id int) ([]models.GroupTraining, error)
+	// Attach привязывает занятие к серии на дату date.
+	Attach(ctx context.Context, groupID, seriesID int, date time.Time) error
+	// SetModified отмечает занятие правленым отдельно от серии (или снимает отметку).
+	SetModified(ctx context.Context, groupID int, modified bool) error
+	// Move переносит занятия и исключения серии from с даты since в серию to.
+	Move(ctx context.Context, from, to int, since time.Time) error
+
+	Exceptions(ctx context.Context, id int) ([]models.SeriesException, error)
+	// AddException добавляет дату в исключения; false — она там уже была.
+	AddException(ctx context.Context, id int, date time.Time, reason string) (bool, error)
+	DeleteException(ctx context.Context, id int, date time.Time) error
+}
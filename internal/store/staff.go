@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/auth"
+)
+
+// StaffMember — учётная запись сотрудника в списке.
+type StaffMember struct {
+	ID          int       `json:"id"`
+	Login       string    `json:"login"`
+	FIO         string    `json:"fio"`
+	Role        string    `json:"role"`
+	TrainerID   int       `json:"trainer_id,omitempty"`
+	TrainerName string    `json:"trainer_name,omitempty"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// StaffInput — поля учётной записи. PasswordHash == "" при правке
+// оставляет пароль прежним; Active при создании не используется — новая
+// учётная запись активна.
+type StaffInput struct {
+	Login        string
+	FIO          string
+	Role         string
+	TrainerID    int // 0 — не привязана к тренеру
+	Active       bool
+	PasswordHash string
+}
+
+// StaffRepo — учётные записи сотрудников.
+type StaffRepo interface {
+	// List — все сотрудники: сначала активные, по ФИО.
+	List(ctx context.Context) ([]StaffMember, error)
+	Get(ctx context.Context, id int) (StaffMember, error)
+	// Active — активные сотрудники с одной из ролей roles, по ФИО.
+	Active(ctx context.Context, roles ...string) ([]StaffMember, error)
+	Count(ctx context.Context) (int, error)
+	// ByLogin — сотрудник по логину без учёта регистра, хеш его пароля
+	// и признак активности; ErrNotFound, если такого логина нет.
+	ByLogin(ctx context.Context, login string) (s auth.Staff, hash string, active bool, err error)
+
+	// Create — ErrDuplicate, если логин занят.
+	Create(ctx context.Context, in StaffInput) (int, error)
+	Update(ctx context.Context, id int, in StaffInput) error
+	// Delete удаляет учётную запись вместе с её сессиями.
+	Delete(ctx context.Context, id int) error
+}
+
+// SessionRepo — сессии входа сотрудников; токен хранится только хешем.
+type SessionRepo interface {
+	// Staff — активный сотрудник непросроченной сессии; ErrNotFound, если
+	// сессии нет, она истекла или учётная запись заблокирована.
+	Staff(ctx context.Context, tokenHash string) (auth.Staff, error)
+	Create(ctx context.Context, tokenHash string, staffID int, expires time.Time) error
+	Delete(ctx context.Context, tokenHash string) error
+	// DeleteByStaff завершает все сессии сотрудника.
+	DeleteByStaff(ctx context.Context, staffID int) error
+	DeleteExpired(ctx context.Context) error
+}
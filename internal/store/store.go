@@ -0,0 +1,73 @@
+// Package store — слой доступа к данным: типизированные интерфейсы
+// репозиториев по агрегатам (клиенты, абонементы, тарифы, тренировки,
+// зоны, оборудование, заявки на ремонт, посещения, заморозки, платежи, уведомления,
+// серии групповых тренировок, рабочие часы и отсутствия тренеров,
+// календарные ссылки, ставки тренеров, зарплата тренеров, плановое ТО,
+// подрядчики и склад запчастей, ссылки на фото в хранилище файлов,
+// тренеры, сотрудники и их сессии, журнал аудита, сводки и отчёты).
+//
+// Хэндлеры зависят только от этих интерфейсов, поэтому HTML-страница и
+// JSON API читают данные одним путём, а реализацию можно подменить
+// (тесты, демо-режим). Рабочая реализация — пакет pgstore.
+package store
+
+import (
+	"context"
+	"errors"
+
+	"fitness-center-manager/internal/audit"
+)
+
+// Ошибки репозиториев. Реализации оборачивают ими ошибки драйвера,
+// проверять — через errors.Is.
+var (
+	ErrNotFound   = errors.New("запись не найдена")
+	ErrDuplicate  = errors.New("такая запись уже существует")
+	ErrInUse      = errors.New("на запись ссылаются другие данные")
+	ErrOverlap    = errors.New("пересечение по времени с другой тренировкой")
+	ErrOutOfStock = errors.New("недостаточно на складе")
+)
+
+// Store — точка входа в хранилище.
+type Store interface {
+	Clients() ClientRepo
+	Subscriptions() SubscriptionRepo
+	Tariffs() TariffRepo
+	Trainings() TrainingRepo
+	Zones() ZoneRepo
+	Equipment() EquipmentRepo
+	Repairs() RepairRepo
+	Visits() VisitRepo
+	Freezes() FreezeRepo
+	JobRuns() JobRunRepo
+	Payments() PaymentRepo
+	Notifications() NotificationRepo
+	Series() SeriesRepo
+	Availability() AvailabilityRepo
+	Calendar() CalendarRepo
+	Rates() RateRepo
+	Payroll() PayrollRepo
+	Maintenance() MaintenanceRepo
+	Vendors() VendorRepo
+	Parts() PartRepo
+	Photos() PhotoRepo
+	Trainers() TrainerRepo
+	Staff() StaffRepo
+	Sessions() SessionRepo
+	Audit() AuditRepo
+	Reports() ReportRepo
+
+	// Ping проверяет, что хранилище доступно.
+	Ping(ctx context.Context) error
+
+	// InTx выполняет fn в одной транзакции: все репозитории tx работают
+	// внутри неё, ошибка fn откатывает изменения. actor попадает в журнал
+	// аудита. Вызов InTx внутри fn использует ту же транзакцию.
+	InTx(ctx context.Context, actor audit.Actor, fn func(tx Store) error) error
+}
+
+// Option — элемент выпадающего списка (id + подпись).
+type Option struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+// SubscriptionInput — поля абонемента. Price == nil при обновлении
+// оставляет прежнюю цену. Второе продление одного абонемента — ErrDuplicate.
+type SubscriptionInput struct {
+	ClientID  int
+	TariffID  int
+	StartDate time.Time
+	EndDate   time.Time
+	Status    string
+	Price     *float64
+
+	PreviousID int // продлеваемый абонемент (только при создании); 0 — нет
+}
+
+// SubscriptionOption — абонемент для выпадающего списка.
+type SubscriptionOption struct {
+	ID         int
+	ClientName string
+	Status     string
+}
+
+// StatusChange — абонемент, которому фоновая задача сменила статус.
+type StatusChange struct {
+	ID         int
+	ClientName string
+	From       string
+	To         string
+}
+
+// SubscriptionRepo — абонементы.
+type SubscriptionRepo interface {
+	List(ctx context.Context) ([]models.Subscription, error)
+	Get(ctx context.Context, id int) (models.Subscription, error)
+	// Lock блокирует строку абонемента до конца транзакции (SELECT … FOR
+	// UPDATE): проверки «прочитать и дописать» (оплаты, заморозки,
+	// продления) по одному абонементу идут по одной. ErrNotFound — нет такого.
+	Lock(ctx context.Context, id int) error
+	// ListByClient — все абонементы клиента, новые сверху.
+	ListByClient(ctx context.Context, clientID int) ([]models.Subscription, error)
+	Options(ctx context.Context) ([]SubscriptionOption, error)
+
+	Create(ctx context.Context, in SubscriptionInput) (int, error)
+	Update(ctx context.Context, id int, in SubscriptionInput) error
+	// Delete удаляет абонемент вместе с его персональными тренировками
+	// и записями на групповые.
+	Delete(ctx context.Context, id int) error
+
+	// Expire завершает абонементы, чья Дата_окончания раньше today.
+	Expire(ctx context.Context, today time.Time) ([]StatusChange, error)
+	// Activate переводит «Ожидает» в «Активен», когда наступила Дата_начала.
+	Activate(ctx context.Context, today time.Time) ([]StatusChange, error)
+}
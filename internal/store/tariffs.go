@@ -0,0 +1,33 @@
+package store
+
+import (
+	"context"
+
+	"fitness-center-manager/internal/access"
+	"fitness-center-manager/internal/models"
+)
+
+// TariffInput — поля тарифа. Пустые AccessHours — без ограничения по времени.
+// Сохранение тарифа снимает отметку о нераспознанных часах доступа.
+type TariffInput struct {
+	Name        string
+	Description string
+	Price       float64
+	AccessHours access.Schedule
+	HasGroup    bool
+	HasPersonal bool
+
+	FreezeMaxDays  int // суммарно дней заморозки на абонемент
+	FreezeMaxCount int // заморозок на абонемент; 0 — заморозка недоступна
+}
+
+// TariffRepo — тарифы.
+type TariffRepo interface {
+	List(ctx context.Context) ([]models.Tariff, error)
+	Get(ctx context.Context, id int) (models.Tariff, error)
+
+	Create(ctx context.Context, in TariffInput) (int, error)
+	Update(ctx context.Context, id int, in TariffInput) error
+	// Delete возвращает ErrInUse, если по тарифу оформлены абонементы.
+	Delete(ctx context.Context, id int) error
+}
@@ -0,0 +1,30 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/models"
+)
+
+// TrainerInput — поля карточки тренера.
+type TrainerInput struct {
+	FIO            string
+	Phone          string
+	Specialization string
+	HireDate       time.Time
+	Experience     int
+}
+
+// TrainerRepo — тренеры клуба (рабочие часы и отсутствия — AvailabilityRepo).
+type TrainerRepo interface {
+	List(ctx context.Context) ([]models.Trainer, error)
+	Get(ctx context.Context, id int) (models.Trainer, error)
+	// Options — тренеры для выпадающих списков, по ФИО.
+	Options(ctx context.Context) ([]Option, error)
+
+	Create(ctx context.Context, in TrainerInput) (int, error)
+	Update(ctx context.Context, id int, in TrainerInput) error
+	// Delete — ErrInUse, если на тренера ссылаются тренировки.
+	Delete(ctx context.Context, id int) error
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"fitness-center-manager/internal/enrollment"
+	"fitness-center-manager/internal/models"
+)
+
+// TrainingFilter — фильтры списков тренировок; нулевые значения не ограничивают.
+// ZoneID и Level относятся только к групповым, Status — к персональным.
+type TrainingFilter struct {
+	Query     string // название, тренер, зона / клиент, тренер
+	TrainerID int
+	ZoneID    int
+	Level     string
+	Status    string
+	From      time.Time // начало не раньше
+	To        time.Time // начало не позже
+	Upcoming  bool      // ещё не начались
+	Recent    bool      // начались за последние 30 дней
+}
+
+// GroupTrainingInput — поля групповой тренировки.
+type GroupTrainingInput struct {
+	TrainerID   int
+	ZoneID      int
+	Title       string
+	Description string
+	Max         int
+	Level       string
+	Start       time.Time
+	End         time.Time
+	// AllowOverlap — администратор разрешил пересечение с другими
+	// тренировками (ограничение БД на такую строку не действует).
+	AllowOverlap bool
+}
+
+// PersonalTrainingInput — поля персональной тренировки; Price == nil — без стоимости.
+type PersonalTrainingInput struct {
+	SubscriptionID int
+	TrainerID      int
+	Start          time.Time
+	End            time.Time
+	Status         string
+	Price          *float64
+	AllowOverlap   bool
+	OverrideReason string               // основание записи вопреки правилам абонемента
+	Rate           *models.RateSnapshot // ставка, по которой посчитана Price; nil — введена вручную
+}
+
+// ConflictQuery — проверяемый интервал [Start, End): занят ли в нём тренер
+// (групповые и неотменённые персональные) или зона (групповые). Exclude* —
+// сама проверяемая тренировка при правке.
+type ConflictQuery struct {
+	TrainerID         int
+	ZoneID            int // 0 — зону не проверять (персональная)
+	Start             time.Time
+	End               time.Time
+	ExcludeGroupID    int
+	ExcludePersonalID int
+}
+
+// TrainingRepo — групповые и персональные тренировки, записи на групповые.
+type TrainingRepo interface {
+	ListGroups(ctx context.Context, f TrainingFilter) ([]models.GroupTraining, error)
+	GetGroup(ctx context.Context, id int) (models.GroupTraining, error)
+	CreateGroup(ctx context.Context, in GroupTrainingInput) (int, error)
+	UpdateGroup(ctx context.Context, id int, in GroupTrainingInput) error
+	// DeleteGroup удаляет тренировку; записи на неё удаляются каскадом.
+	DeleteGroup(ctx context.Context, id int) error
+
+	ListPersonal(ctx context.Context, f TrainingFilter) ([]models.PersonalTraining, error)
+	GetPersonal(ctx context.Context, id int) (models.PersonalTraining, error)
+	// LockPersonal блокирует строку персональной тренировки до конца
+	// транзакции (SELECT … FOR UPDATE). ErrNotFound — нет такой.
+	LockPersonal(ctx context.Context, id int) error
+	CreatePersonal(ctx context.Context, in PersonalTrainingInput) (int, error)
+	UpdatePersonal(ctx context.Context, id int, in PersonalTrainingInput) error
+	DeletePersonal(ctx context.Context, id int) error
+
+	// Conflicts — тренировки обоих видов, пересекающиеся с интервалом q
+	// по тренеру или зоне, по времени начала.
+	Conflicts(ctx context.Context, q ConflictQuery) ([]models.ScheduleConflict, error)
+
+	ListEnrollments(ctx context.Context, groupID int) ([]models.GroupTrainingRegistration, error)
+	GetEnrollment(ctx context.Context, id int) (models.GroupTrainingRegistration, error)
+	// Enroll записывает абонемент на групповую; повторная запись — ErrDuplicate.
+	// overrideReason — основание записи вопреки правилам абонемента (пусто — нет).
+	Enroll(ctx context.Context, groupID, subscriptionID int, status, overrideReason string) (int, error)
+	// SetEnrollmentStatus меняет статус записи; при переводе в «В очереди»
+	// время записи обновляется — запись встаёт в конец очереди.
+	SetEnrollmentStatus(ctx context.Context, id int, status string) error
+	DeleteEnrollment(ctx context.Context, id int) error
+
+	// LockOccupancy блокирует строку тренировки до конца транзакции
+	// (SELECT … FOR UPDATE) и считает занятые места и очередь, поэтому
+	// параллельные записи на одну тренировку проходят по одной.
+	LockOccupancy(ctx context.Context, groupID int) (enrollment.Occupancy, error)
+	// FirstWaiting — первая запись в очереди; ErrNotFound, если очередь пуста.
+	FirstWaiting(ctx context.Context, groupID int) (models.GroupTrainingRegistration, error)
+}
@@ -0,0 +1,31 @@
+package store
+
+import (
+	"context"
+
+	"fitness-center-manager/internal/models"
+)
+
+// VisitStats — посещаемость для главной страницы.
+type VisitStats struct {
+	Today   int // входов сегодня
+	Inside  int // сейчас в зале
+	Last30  int // входов за 30 дней
+	Clients int // разных клиентов за 30 дней
+}
+
+// VisitRepo — посещения (вход/выход по абонементу).
+type VisitRepo interface {
+	// Open — незакрытое посещение клиента; ErrNotFound, если клиент не в зале.
+	Open(ctx context.Context, clientID int) (models.Visit, error)
+	// Inside — все, кто сейчас в зале, по времени входа.
+	Inside(ctx context.Context) ([]models.Visit, error)
+	// ListByClient — последние limit посещений клиента.
+	ListByClient(ctx context.Context, clientID, limit int) ([]models.Visit, error)
+	Stats(ctx context.Context) (VisitStats, error)
+
+	// Enter отмечает вход; второй незакрытый визит даёт ErrDuplicate.
+	Enter(ctx context.Context, clientID, subscriptionID, staffID int) (int, error)
+	// Exit закрывает посещение текущим временем.
+	Exit(ctx context.Context, id int) error
+}
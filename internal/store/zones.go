@@ -0,0 +1,34 @@
+package store
+
+import (
+	"context"
+
+	"fitness-center-manager/internal/models"
+)
+
+// ZoneInput — поля зоны.
+type ZoneInput struct {
+	Name        string
+	Description string
+	Capacity    int
+	Status      string
+}
+
+// ZoneRepo — зоны клуба.
+type ZoneRepo interface {
+	List(ctx context.Context) ([]models.Zone, error)
+	Get(ctx context.Context, id int) (models.Zone, error)
+	Options(ctx context.Context) ([]Option, error)
+	// IDsByName — id зон с названием name, не больше limit.
+	IDsByName(ctx context.Context, name string, limit int) ([]int, error)
+
+	Create(ctx context.Context, in ZoneInput) (int, error)
+	Update(ctx context.Context, id int, in ZoneInput) error
+	SetStatus(ctx context.Context, id int, status string) error
+	Delete(ctx context.Context, id int) error
+
+	// Photo — фото зоны; пустое, если не загружено.
+	Photo(ctx context.Context, id int) (Photo, error)
+	// SetPhoto заменяет фото ключом файла в хранилище; "" — удаляет.
+	SetPhoto(ctx context.Context, id int, key string) error
+}
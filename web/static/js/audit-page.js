@@ -0,0 +1,37 @@
+async function parseJsonOrThrow(resp){
+  const ct=(resp.headers.get('content-type')||'').toLowerCase();
+  if(ct.includes('application/json')||ct.includes('application/problem+json')) return resp.json();
+  const text=await resp.text(); throw new Error(text.slice(0,300)||'Сервер вернул не-JSON');
+}
+
+document.addEventListener('DOMContentLoaded', ()=>{
+  const form=document.getElementById('auditFilterForm');
+  const rows=document.getElementById('auditRows');
+  const perPage=50;
+  let page=1, total=0;
+
+  async function load(){
+    const qs=new URLSearchParams();
+    for(const [k,v] of new FormData(form).entries()){ if(v) qs.set(k,v); }
+    qs.set('page',page); qs.set('per_page',perPage);
+    rows.innerHTML='<tr><td colspan="5" class="text-muted">⌛ Загрузка...</td></tr>';
+    try{
+      const res=await parseJsonOrThrow(await fetch('/api/v1/audit?'+qs.toString()));
+      if(!res.success) throw new Error(res.error||'Не удалось загрузить журнал');
+      total=res.total;
+      rows.innerHTML=res.items.length
+        ? res.items.map(e=>window.renderAuditRow(e,true)).join('')
+        : '<tr><td colspan="5" class="text-muted">Записей нет</td></tr>';
+    }catch(e){ rows.innerHTML=`<tr><td colspan="5" class="text-danger">❌ ${e.message}</td></tr>`; }
+    const pages=Math.max(1, Math.ceil(total/perPage));
+    document.getElementById('auditTotal').textContent=`Всего: ${total}`;
+    document.getElementById('auditPage').textContent=`Стр. ${page} из ${pages}`;
+    document.getElementById('auditPrev').disabled=page<=1;
+    document.getElementById('auditNext').disabled=page>=pages;
+  }
+
+  form.addEventListener('submit', (e)=>{ e.preventDefault(); page=1; load(); });
+  document.getElementById('auditPrev').addEventListener('click', ()=>{ if(page>1){ page--; load(); } });
+  document.getElementById('auditNext').addEventListener('click', ()=>{ page++; load(); });
+  load();
+});
@@ -0,0 +1,82 @@
+// История изменений записи (журнал аудита).
+// Любая кнопка с data-audit-entity / data-audit-id открывает модалку с историей.
+(function(){
+  const ACTIONS={create:'Создание', update:'Изменение', delete:'Удаление'};
+  const BADGES={create:'success', update:'primary', delete:'danger'};
+
+  function esc(v){
+    return String(v).replace(/[&<>"']/g, ch=>({'&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;',"'":'&#39;'}[ch]));
+  }
+  function fmtValue(field, v){
+    if(v===null||v===undefined||v==='') return '<span class="text-muted">—</span>';
+    if(field==='Фото'||field==='Фото_ключ') return '🖼️ '+esc(String(v).slice(0,8));
+    if(field==='Хеш_пароля') return '••••••';
+    if(typeof v==='object') return esc(JSON.stringify(v));
+    return esc(v);
+  }
+  function fmtTime(iso){
+    const d=new Date(iso);
+    return isNaN(d) ? esc(iso) : d.toLocaleString('ru-RU');
+  }
+  // Таблица изменений одной записи журнала
+  window.renderAuditChanges=function(e){
+    const keys=Object.keys(e.changes||{}).sort();
+    if(!keys.length) return '<span class="text-muted">—</span>';
+    return '<ul class="list-unstyled mb-0 small">'+keys.map(k=>{
+      const ch=e.changes[k];
+      if(e.action==='create') return `<li><b>${esc(k)}</b>: ${fmtValue(k,ch.new)}</li>`;
+      if(e.action==='delete') return `<li><b>${esc(k)}</b>: ${fmtValue(k,ch.old)}</li>`;
+      return `<li><b>${esc(k)}</b>: ${fmtValue(k,ch.old)} → ${fmtValue(k,ch.new)}</li>`;
+    }).join('')+'</ul>';
+  };
+  window.renderAuditRow=function(e, withEntity){
+    return `<tr>
+      <td class="text-nowrap">${fmtTime(e.at)}</td>
+      <td>${e.actor?esc(e.actor):'<span class="text-muted">система</span>'}</td>
+      ${withEntity?`<td>${esc(e.entity)} #${e.entity_id}</td>`:''}
+      <td><span class="badge bg-${BADGES[e.action]||'secondary'}">${ACTIONS[e.action]||esc(e.action)}</span></td>
+      <td>${window.renderAuditChanges(e)}</td>
+    </tr>`;
+  };
+
+  function ensureModal(){
+    let el=document.getElementById('auditHistoryModal');
+    if(el) return el;
+    el=document.createElement('div');
+    el.className='modal fade'; el.id='auditHistoryModal'; el.tabIndex=-1;
+    el.innerHTML=`<div class="modal-dialog modal-xl modal-dialog-scrollable"><div class="modal-content">
+      <div class="modal-header"><h5 class="modal-title">🕘 История изменений</h5>
+        <button type="button" class="btn-close" data-bs-dismiss="modal"></button></div>
+      <div class="modal-body"><div id="auditHistoryBody"></div></div>
+    </div></div>`;
+    document.body.appendChild(el);
+    return el;
+  }
+
+  window.showAuditHistory=async function(entity, id, title){
+    const el=ensureModal();
+    el.querySelector('.modal-title').textContent='🕘 История изменений'+(title?': '+title:'');
+    const body=el.querySelector('#auditHistoryBody');
+    body.innerHTML='<div class="text-muted">⌛ Загрузка...</div>';
+    bootstrap.Modal.getOrCreateInstance(el).show();
+    try{
+      const qs=new URLSearchParams({entity, entity_id:id, per_page:200});
+      const resp=await fetch('/api/v1/audit?'+qs.toString());
+      const ct=(resp.headers.get('content-type')||'').toLowerCase();
+      if(!ct.includes('json')) throw new Error('Сервер вернул не-JSON');
+      const res=await resp.json();
+      if(!res.success) throw new Error(res.error||'Не удалось загрузить историю');
+      if(!res.items.length){ body.innerHTML='<div class="alert alert-info mb-0">Изменений пока нет.</div>'; return; }
+      body.innerHTML=`<div class="table-responsive"><table class="table table-sm table-striped align-middle">
+        <thead class="table-dark"><tr><th>Когда</th><th>Кто</th><th>Действие</th><th>Изменения</th></tr></thead>
+        <tbody>${res.items.map(e=>window.renderAuditRow(e,false)).join('')}</tbody></table></div>`;
+    }catch(e){ body.innerHTML=`<div class="alert alert-danger mb-0">❌ ${esc(e.message)}</div>`; }
+  };
+
+  document.addEventListener('click', (ev)=>{
+    const btn=ev.target.closest('[data-audit-entity]');
+    if(!btn) return;
+    ev.preventDefault();
+    window.showAuditHistory(btn.dataset.auditEntity, btn.dataset.auditId, btn.dataset.auditTitle||'');
+  });
+})();
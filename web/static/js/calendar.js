@@ -0,0 +1,84 @@
+// Календарные ссылки (iCalendar) тренера, зоны или клиента.
+// Любая кнопка с data-calendar-kind / data-calendar-id открывает модалку:
+// есть ли ссылка, создать новую (прежняя перестаёт работать), отключить.
+(function(){
+  function esc(v){
+    return String(v).replace(/[&<>"']/g, ch=>({'&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;',"'":'&#39;'}[ch]));
+  }
+  async function api(method, kind, id){
+    const resp=await fetch(`/api/v1/calendar-feeds/${kind}/${id}`, {method, cache:'no-store'});
+    const ct=(resp.headers.get('content-type')||'').toLowerCase();
+    if(!ct.includes('json')) throw new Error('Сервер вернул не-JSON');
+    const res=await resp.json();
+    if(!res.success) throw new Error(res.error||'Ошибка');
+    return res;
+  }
+
+  function ensureModal(){
+    let el=document.getElementById('calendarFeedModal');
+    if(el) return el;
+    el=document.createElement('div');
+    el.className='modal fade'; el.id='calendarFeedModal'; el.tabIndex=-1;
+    el.innerHTML=`<div class="modal-dialog"><div class="modal-content">
+      <div class="modal-header"><h5 class="modal-title">📅 Календарь</h5>
+        <button type="button" class="btn-close" data-bs-dismiss="modal"></button></div>
+      <div class="modal-body"><div id="calendarFeedBody"></div></div>
+      <div class="modal-footer">
+        <button type="button" class="btn btn-outline-danger" id="calendarFeedOff">Отключить</button>
+        <button type="button" class="btn btn-primary" id="calendarFeedNew">Новая ссылка</button>
+      </div>
+    </div></div>`;
+    document.body.appendChild(el);
+    el.querySelector('#calendarFeedNew').addEventListener('click', async ()=>{
+      const {kind, id, exists}=el.dataset;
+      if(exists && !confirm('Прежняя ссылка перестанет работать. Продолжить?')) return;
+      try{
+        const res=await api('POST', kind, id);
+        render(el, {exists:true}, res);
+      }catch(e){ alert('❌ '+e.message); }
+    });
+    el.querySelector('#calendarFeedOff').addEventListener('click', async ()=>{
+      if(!confirm('Отключить ссылку? Подписанные календари перестанут обновляться.')) return;
+      try{ await api('DELETE', el.dataset.kind, el.dataset.id); render(el, {exists:false}); }
+      catch(e){ alert('❌ '+e.message); }
+    });
+    return el;
+  }
+
+  function render(el, state, created){
+    el.dataset.exists = state.exists ? '1' : '';
+    el.querySelector('#calendarFeedOff').hidden = !state.exists;
+    el.querySelector('#calendarFeedNew').textContent = state.exists ? 'Новая ссылка' : 'Создать ссылку';
+    const body=el.querySelector('#calendarFeedBody');
+    if(created){
+      body.innerHTML=`<p>Ссылка для подписки (показывается один раз — сохраните её):</p>
+        <div class="input-group mb-2"><input class="form-control form-control-sm" readonly value="${esc(created.url)}">
+          <button class="btn btn-outline-secondary btn-sm" type="button" id="calendarFeedCopy">📋</button></div>
+        <a class="btn btn-sm btn-outline-primary" href="${esc(created.webcal)}">Открыть в календаре</a>`;
+      body.querySelector('#calendarFeedCopy').addEventListener('click', ()=>navigator.clipboard?.writeText(created.url));
+      return;
+    }
+    body.innerHTML = state.exists
+      ? `<p class="mb-1">Ссылка создана ${esc(state.created)}.</p>
+         <p class="text-muted small mb-0">${state.last_access?'Последнее обновление календаря: '+esc(state.last_access):'Календарь по ней ещё не обращался.'}
+         Саму ссылку повторно показать нельзя — при необходимости создайте новую.</p>`
+      : '<p class="mb-0">Ссылки нет. Создайте её, чтобы подписаться на расписание из календаря телефона.</p>';
+  }
+
+  window.showCalendarFeed=async function(kind, id, title){
+    const el=ensureModal();
+    el.dataset.kind=kind; el.dataset.id=id;
+    el.querySelector('.modal-title').textContent='📅 Календарь'+(title?': '+title:'');
+    el.querySelector('#calendarFeedBody').innerHTML='<div class="text-muted">⌛ Загрузка...</div>';
+    bootstrap.Modal.getOrCreateInstance(el).show();
+    try{ render(el, await api('GET', kind, id)); }
+    catch(e){ el.querySelector('#calendarFeedBody').innerHTML=`<div class="alert alert-danger mb-0">❌ ${esc(e.message)}</div>`; }
+  };
+
+  document.addEventListener('click', (ev)=>{
+    const btn=ev.target.closest('[data-calendar-kind]');
+    if(!btn) return;
+    ev.preventDefault();
+    window.showCalendarFeed(btn.dataset.calendarKind, btn.dataset.calendarId, btn.dataset.calendarTitle||'');
+  });
+})();
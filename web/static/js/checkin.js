@@ -0,0 +1,72 @@
+// Стойка ресепшна: вход/выход по карте или id клиента.
+async function parseJsonOrThrow(response){
+  const ct=(response.headers.get('content-type')||'').toLowerCase();
+  if(ct.includes('application/json')||ct.includes('application/problem+json')) return response.json();
+  const text=await response.text(); throw new Error(text.slice(0,300)||'Сервер вернул не-JSON');
+}
+function esc(v){
+  return String(v??'').replace(/[&<>"']/g, ch=>({'&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;',"'":'&#39;'}[ch]));
+}
+
+function showResult(kind, html){
+  document.getElementById('checkinResult').innerHTML=`<div class="alert alert-${kind} mb-0">${html}</div>`;
+}
+
+function describeSubscription(s){
+  if(!s) return '';
+  return `<div class="small mt-1">🎫 ${esc(s.tariff)} · ${esc(s.status)} · ${esc(s.start_date)} — ${esc(s.end_date)} · 🕘 ${esc(s.hours)}${s.frozen_until ? ` · ❄️ до ${esc(s.frozen_until)}` : ''}</div>`;
+}
+
+async function refreshInside(){
+  try{
+    const result=await parseJsonOrThrow(await fetch('/api/v1/visits/inside'));
+    if(!result.success) return;
+    const list=result.visits||[];
+    document.getElementById('insideCount').textContent=list.length;
+    document.getElementById('insideBody').innerHTML = list.length ? list.map(v=>`<tr>
+        <td>${esc(v.client_name)} <span class="text-muted small">#${v.client_id}</span></td>
+        <td>${v.tariff_name?esc(v.tariff_name):'—'}</td>
+        <td class="text-nowrap">${esc(v.entered_at)}</td>
+        <td>${v.minutes}</td>
+        <td><button class="btn btn-sm btn-outline-secondary visit-exit-btn" data-visit-id="${v.id}" title="Отметить выход">👋</button></td>
+      </tr>`).join('') : '<tr><td colspan="5" class="text-muted">В зале никого нет</td></tr>';
+  }catch(e){ console.error(e); }
+}
+
+async function sendCode(url){
+  const input=document.getElementById('checkinCode');
+  const code=input.value.trim();
+  if(!code){ input.focus(); return; }
+  try{
+    const response=await fetch(url,{method:'POST', body:new URLSearchParams({code})});
+    const result=await parseJsonOrThrow(response);
+    const who=result.client?`<b>${esc(result.client.fio)}</b> <span class="text-muted">#${result.client.id}</span>`:'';
+    if(result.success){
+      showResult('success', `✅ ${esc(result.message)}: ${who}${describeSubscription(result.subscription)}`);
+    }else{
+      showResult(result.reason?'danger':'warning', `⛔ ${esc(result.error)}${who?': '+who:''}${describeSubscription(result.subscription)}`);
+    }
+  }catch(e){ showResult('warning', '❌ '+esc(e.message)); }
+  input.value=''; input.focus();
+  refreshInside();
+}
+
+document.addEventListener('DOMContentLoaded', function () {
+  document.getElementById('checkinForm')?.addEventListener('submit', function (e) {
+    e.preventDefault();
+    sendCode('/api/v1/checkin');
+  });
+  document.querySelector('[data-action="checkout"]')?.addEventListener('click', function () {
+    sendCode('/api/v1/checkout');
+  });
+  document.getElementById('insideBody')?.addEventListener('click', async function (e) {
+    const btn=e.target.closest('.visit-exit-btn');
+    if(!btn) return;
+    btn.disabled=true;
+    try{
+      const result=await parseJsonOrThrow(await fetch(`/api/v1/visits/${btn.dataset.visitId}/exit`,{method:'POST'}));
+      if(!result.success) alert('❌ '+(result.error||'Не удалось отметить выход'));
+    }catch(err){ alert('❌ '+err.message); }
+    refreshInside();
+  });
+});
@@ -25,6 +25,7 @@ function initializeEditButtons() {
         document.getElementById('editPhone').value   = c.phone || c.Phone || '';
         document.getElementById('editBirthDate').value = c.birth_date || c.BirthDate || '';
         document.getElementById('editMedicalData').value = c.medical_data || (c.MedicalData ? c.MedicalData.String : '') || '';
+        document.getElementById('editCardNumber').value = c.card_number || '';
         new bootstrap.Modal(document.getElementById('editClientModal')).show();
       } catch (e) { alert('❌ '+e.message); }
     });
@@ -60,6 +61,69 @@ document.getElementById('addClientForm')?.addEventListener('submit', async funct
   finally { btn.disabled=false; btn.innerHTML='Сохранить'; }
 });
 
+// ===== абонементы и цепочка продлений =====
+function initializeSubscriptionButtons() {
+  document.querySelectorAll('.subs-client-btn').forEach(button => {
+    button.addEventListener('click', () => showClientSubscriptions(button.dataset.clientId, button.dataset.clientName));
+  });
+}
+async function showClientSubscriptions(clientId, clientName) {
+  const modalEl = document.getElementById('clientSubscriptionsModal');
+  modalEl.querySelector('.modal-title').textContent = '🎫 Абонементы: ' + (clientName || '#' + clientId);
+  const body = document.getElementById('clientSubscriptionsBody');
+  body.innerHTML = '<div class="text-muted">⌛ Загрузка...</div>';
+  bootstrap.Modal.getOrCreateInstance(modalEl).show();
+  try {
+    const result = await parseJsonOrThrow(await fetch(`/api/v1/clients/${clientId}/subscriptions`));
+    if (!result.success) throw new Error(result.error || result.detail || 'Не удалось загрузить абонементы');
+    const list = result.subscriptions || [];
+    if (!list.length) { body.innerHTML = '<div class="alert alert-info mb-0">Абонементов пока нет</div>'; return; }
+    const esc = v => String(v ?? '').replace(/[&<>"']/g, ch => ({'&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;',"'":'&#39;'}[ch]));
+    body.innerHTML = `<table class="table table-sm table-striped mb-0">
+      <thead><tr><th>ID</th><th>Тариф</th><th>Срок</th><th>Статус</th><th>Цена</th><th>Продление</th></tr></thead>
+      <tbody>${list.map(s => `<tr>
+        <td>#${s.id}</td>
+        <td>${esc(s.tariff_name)}</td>
+        <td class="text-nowrap">${esc(s.start_date)} — ${esc(s.end_date)}</td>
+        <td>${esc(s.status)}</td>
+        <td>${Number(s.price).toFixed(2)} ₽</td>
+        <td class="text-nowrap">
+          ${s.previous_id ? `<span class="badge bg-light text-dark">продление #${s.previous_id}</span>` : ''}
+          ${s.renewal_id ? `<span class="badge bg-success">продлён → #${s.renewal_id}</span>` : ''}
+        </td>
+      </tr>`).join('')}</tbody></table>`;
+  } catch (e) { body.innerHTML = `<div class="alert alert-danger mb-0">❌ ${e.message}</div>`; }
+}
+
+// ===== посещения =====
+function initializeVisitButtons() {
+  document.querySelectorAll('.visits-client-btn').forEach(button => {
+    button.addEventListener('click', () => showClientVisits(button.dataset.clientId, button.dataset.clientName));
+  });
+}
+async function showClientVisits(clientId, clientName) {
+  const modalEl = document.getElementById('clientVisitsModal');
+  modalEl.querySelector('.modal-title').textContent = '🚪 Посещения: ' + (clientName || '#' + clientId);
+  const body = document.getElementById('clientVisitsBody');
+  body.innerHTML = '<div class="text-muted">⌛ Загрузка...</div>';
+  bootstrap.Modal.getOrCreateInstance(modalEl).show();
+  try {
+    const result = await parseJsonOrThrow(await fetch(`/api/v1/clients/${clientId}/visits`));
+    if (!result.success) throw new Error(result.error || 'Не удалось загрузить посещения');
+    const list = result.visits || [];
+    if (!list.length) { body.innerHTML = '<div class="alert alert-info mb-0">Посещений пока не было</div>'; return; }
+    const esc = v => String(v ?? '').replace(/[&<>"']/g, ch => ({'&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;',"'":'&#39;'}[ch]));
+    body.innerHTML = `<table class="table table-sm table-striped mb-0">
+      <thead><tr><th>Вход</th><th>Выход</th><th>Минут</th><th>Тариф</th></tr></thead>
+      <tbody>${list.map(v => `<tr>
+        <td class="text-nowrap">${esc(v.entered_at)}</td>
+        <td class="text-nowrap">${v.exited_at ? esc(v.exited_at) : '<span class="badge bg-success">в зале</span>'}</td>
+        <td>${v.minutes}</td>
+        <td>${v.tariff_name ? esc(v.tariff_name) : '—'}</td>
+      </tr>`).join('')}</tbody></table>`;
+  } catch (e) { body.innerHTML = `<div class="alert alert-danger mb-0">❌ ${e.message}</div>`; }
+}
+
 // ===== удаление =====
 function initializeDeleteButtons() {
   document.querySelectorAll('.delete-client-btn').forEach(button => {
@@ -192,4 +256,6 @@ document.addEventListener('DOMContentLoaded', function () {
   updateFilterStatus();
   initializeEditButtons();
   initializeDeleteButtons();
+  initializeVisitButtons();
+  initializeSubscriptionButtons();
 });
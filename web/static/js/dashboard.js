@@ -0,0 +1,64 @@
+async function parseJsonOrThrow(response){
+  const ct=(response.headers.get('content-type')||'').toLowerCase();
+  if(ct.includes('application/json')||ct.includes('application/problem+json')) return response.json();
+  const text=await response.text(); throw new Error(text.slice(0,300)||'Сервер вернул не-JSON');
+}
+
+// 🔁 продление из виджета «Истекающие абонементы»
+async function fillRenewTariffs(selectedId){
+  const sel=document.getElementById('renewTariffSelect');
+  sel.innerHTML='';
+  const res=await parseJsonOrThrow(await fetch('/api/tariffs-for-select'));
+  if(!res.success) throw new Error(res.error||'Не удалось загрузить тарифы');
+  res.tariffs.forEach(t=>{
+    const o=document.createElement('option');
+    o.value=t.id; o.textContent=`${t.name} (${t.price} ₽)`;
+    if(String(selectedId)===String(t.id)) o.selected=true;
+    sel.appendChild(o);
+  });
+}
+
+document.addEventListener('DOMContentLoaded', () => {
+  // ✅ уведомление доставлено
+  document.addEventListener('click', async (ev) => {
+    const btn = ev.target.closest('.notification-sent-btn');
+    if(!btn) return;
+    btn.disabled = true;
+    try{
+      const res = await parseJsonOrThrow(await fetch(`/api/v1/notifications/${btn.dataset.id}/sent`, {method:'POST'}));
+      if(!res.success) throw new Error(res.error || 'Ошибка');
+      btn.closest('li')?.remove();
+    }catch(err){ alert('❌ ' + err.message); btn.disabled = false; }
+  });
+
+  document.addEventListener('click', async (ev) => {
+    const btn = ev.target.closest('.renew-sub-btn');
+    if(!btn) return;
+    const form = document.getElementById('renewSubscriptionForm');
+    form.reset();
+    document.getElementById('renewSubId').value = btn.dataset.subId;
+    document.getElementById('renewClientName').textContent = `${btn.dataset.clientName} — абонемент #${btn.dataset.subId}`;
+    try{
+      await fillRenewTariffs(btn.dataset.tariffId);
+      bootstrap.Modal.getOrCreateInstance(document.getElementById('renewSubscriptionModal')).show();
+    }catch(err){ alert('❌ ' + err.message); }
+  });
+
+  document.getElementById('renewSubscriptionForm')?.addEventListener('submit', async function(e){
+    e.preventDefault();
+    const id = document.getElementById('renewSubId').value;
+    const btn = this.querySelector('button[type="submit"]');
+    btn.disabled = true;
+    try{
+      const resp = await fetch(`/api/v1/subscriptions/${id}/renew`, {method:'POST', body:new FormData(this)});
+      const res = await parseJsonOrThrow(resp);
+      if(!res.success){
+        const extra = res.renewal_id ? ` (#${res.renewal_id})` : '';
+        throw new Error((res.detail || res.error || 'Ошибка продления') + extra);
+      }
+      alert('✅ ' + res.message);
+      location.reload();
+    }catch(err){ alert('❌ ' + err.message); }
+    finally{ btn.disabled = false; }
+  });
+});
@@ -31,6 +31,7 @@ function normalizeEqStatus(s) {
   s = (s || '').trim();
   if (s === 'Исправен' || s === 'Работает' || s === 'Исправно') return 'Исправен';
   if (s === 'На ремонте' || s.toLowerCase() === 'ремонт') return 'На ремонте';
+  if (s === 'Требует ТО') return 'Требует ТО';
   if (s === 'Списан' || s === 'Списано') return 'Списан';
   return 'Исправен';
 }
@@ -151,6 +152,9 @@ document.addEventListener('DOMContentLoaded', () => {
         document.getElementById('editEqPurchase').value = data.item.PurchaseDate || '';
         document.getElementById('editEqLastTO').value = data.item.LastServiceDate || '';
         document.getElementById('editEqStatus').value = normalizeEqStatus(data.item.Status);
+        document.getElementById('editEqType').value = data.item.Type || '';
+        document.getElementById('editEqUsage').value = data.item.UsageHours ?? '';
+        document.getElementById('editEqReplacement').value = data.item.ReplacementCost || '';
 
         const select = document.getElementById('editEqZoneSelect');
         await loadZonesIntoSelect(select);
@@ -267,11 +271,11 @@ document.querySelectorAll('.view-repair-photo-btn').forEach(btn => {
     imgEl.src = '';
     errEl.classList.add('d-none');
 
-    const url = `/repairs/${id}/photo`;
+    const url = `/repairs/${id}/photo?size=medium`;
     try {
       const resp = await fetch(url, { method: 'GET', cache: 'no-store' });
       if (!resp.ok) throw new Error('not ok');
-      imgEl.src = url + `?t=${Date.now()}`;
+      imgEl.src = url + `&t=${Date.now()}`;
     } catch {
       errEl.classList.remove('d-none');
     }
@@ -323,6 +327,7 @@ document.querySelectorAll('.edit-repair-btn').forEach(btn => {
     document.getElementById('editRepairDesc').value = desc;
     document.getElementById('editRepairStatus').value = status;
     document.getElementById('editRepairPriority').value = priority;
+    document.querySelector('#editRepairForm textarea[name="comment"]').value = '';
     new bootstrap.Modal(document.getElementById('editRepairModal')).show();
   });
 });
@@ -350,3 +355,392 @@ document.getElementById('editRepairForm')?.addEventListener('submit', async (e)
     btn.disabled = false; btn.textContent = 'Сохранить';
   }
 });
+
+// ===== Ход работ по заявке =====
+// время в БД — «настенное» время клуба: берём как есть, без перевода поясов
+function formatDateTime(s) {
+  if (!s) return '—';
+  const [date, time] = s.split('T');
+  return `${ymdToRu(date)} ${time.slice(0, 5)}`;
+}
+
+async function openRepairWorkflow(id) {
+  const resp = await fetch(`/api/v1/repairs/${id}/history`, { cache: 'no-store' });
+  const data = await parseJsonOrThrow(resp);
+  if (!data.success) throw new Error(data.error || 'Не удалось получить заявку');
+  const r = data.repair;
+
+  const modalEl = document.getElementById('repairWorkflowModal');
+  modalEl.querySelector('.modal-title').textContent = `Заявка #${r.id}: ${r.equipment_name}`;
+  document.getElementById('repairWorkflowId').value = r.id;
+  const deadline = r.status === 'Закрыта'
+    ? `закрыта ${formatDateTime(r.closed_at)}`
+    : `срок: ${formatDateTime(r.deadline)}` + (r.overdue ? ' <span class="badge bg-danger">⏰ просрочено</span>' : '');
+  document.getElementById('repairWorkflowInfo').innerHTML = `
+    <div>${escapeHtml(r.description)}</div>
+    <div class="text-muted small mt-1">${escapeHtml(r.priority)} приоритет · статус «${escapeHtml(r.status)}» · ${deadline}</div>`;
+  document.getElementById('repairAssignee').value = r.assignee_id || '';
+
+  const next = document.getElementById('repairNextStatus');
+  next.innerHTML = '<option value="">— только комментарий —</option>' +
+    r.next.map(s => `<option value="${escapeHtml(s)}">${escapeHtml(s)}</option>`).join('');
+  document.querySelector('#repairStatusForm input[name="comment"]').value = '';
+
+  document.getElementById('repairHistoryList').innerHTML = data.history.length
+    ? data.history.map(e => `
+      <li class="list-group-item">
+        <div class="d-flex justify-content-between">
+          <span class="fw-semibold">${e.from_status ? `${escapeHtml(e.from_status)} → ${escapeHtml(e.status)}` : escapeHtml(e.status)}</span>
+          <small class="text-muted">${formatDateTime(e.time)} · ${escapeHtml(e.staff_name)}</small>
+        </div>
+        ${e.comment ? `<div class="small">${escapeHtml(e.comment)}</div>` : ''}
+      </li>`).join('')
+    : '<li class="list-group-item text-muted">История пуста</li>';
+
+  if (document.getElementById('repairCosts')) await loadRepairCosts(r.id);
+  bootstrap.Modal.getOrCreateInstance(modalEl).show();
+}
+
+// ===== Затраты по заявке (администратор и техник) =====
+function formatMoney(v) {
+  return Number(v || 0).toLocaleString('ru-RU', { minimumFractionDigits: 2, maximumFractionDigits: 2 });
+}
+
+async function loadRepairCosts(id) {
+  const [costs, vendors, parts] = await Promise.all(
+    [`/api/v1/repairs/${id}/costs`, '/api/v1/vendors', '/api/v1/parts'].map(async url => {
+      const data = await parseJsonOrThrow(await fetch(url, { cache: 'no-store' }));
+      if (!data.success) throw new Error(data.error || 'Не удалось получить затраты');
+      return data;
+    }));
+
+  const vendor = document.getElementById('repairVendor');
+  vendor.innerHTML = '<option value="">— своими силами —</option>' +
+    vendors.vendors.map(v => `<option value="${v['id_подрядчика']}">${escapeHtml(v['название'])}</option>`).join('');
+  vendor.value = costs.vendor_id || '';
+  document.getElementById('repairLabour').value = costs.labour_cost || '';
+  document.getElementById('repairCostsTotal').textContent =
+    `работы ${formatMoney(costs.labour_cost)} ₽ + запчасти ${formatMoney(costs.parts_cost)} ₽ = ${formatMoney(costs.total)} ₽`;
+
+  document.getElementById('repairPart').innerHTML = parts.parts.length
+    ? parts.parts.map(p => `<option value="${p['id_запчасти']}" ${p['остаток'] > 0 ? '' : 'disabled'}>` +
+        `${escapeHtml(p['название'])}${p['артикул'] ? ' [' + escapeHtml(p['артикул']) + ']' : ''} — ` +
+        `${p['остаток']} ${escapeHtml(p['единица'])}, ${formatMoney(p['цена'])} ₽</option>`).join('')
+    : '<option value="">Склад пуст</option>';
+
+  document.getElementById('repairPartsList').innerHTML = costs.parts.length
+    ? costs.parts.map(u => `
+      <tr>
+        <td>${formatDateTime(u['дата'])}</td>
+        <td>${escapeHtml(u['запчасть'])}</td>
+        <td>${u['количество']} ${escapeHtml(u['единица'])}</td>
+        <td>${formatMoney(u['цена'])}</td>
+        <td>${formatMoney(u['количество'] * u['цена'])}</td>
+        <td><button class="btn btn-sm btn-outline-danger repair-part-return" data-usage-id="${u['id_расхода']}" type="button" title="Вернуть на склад">↩️</button></td>
+      </tr>`).join('')
+    : '<tr><td colspan="6" class="text-muted">Запчасти не списывались</td></tr>';
+}
+
+async function submitRepairCosts(url, method, body) {
+  const id = document.getElementById('repairWorkflowId').value;
+  try {
+    const resp = await fetch(url, { method, body });
+    const data = await parseJsonOrThrow(resp);
+    if (!data.success) throw new Error(data.error || 'Не удалось сохранить');
+    await loadRepairCosts(id);
+  } catch (err) {
+    alert('❌ ' + err.message);
+  }
+}
+
+document.getElementById('repairCostsForm')?.addEventListener('submit', (e) => {
+  e.preventDefault();
+  const id = document.getElementById('repairWorkflowId').value;
+  submitRepairCosts(`/api/v1/repairs/${id}/costs`, 'PUT', new URLSearchParams(new FormData(e.currentTarget)));
+});
+
+document.getElementById('repairPartForm')?.addEventListener('submit', (e) => {
+  e.preventDefault();
+  const id = document.getElementById('repairWorkflowId').value;
+  submitRepairCosts(`/api/v1/repairs/${id}/parts`, 'POST', new URLSearchParams(new FormData(e.currentTarget)));
+});
+
+document.getElementById('repairPartsList')?.addEventListener('click', (e) => {
+  const btn = e.target.closest('.repair-part-return');
+  if (!btn || !confirm('Отменить списание и вернуть запчасть на склад?')) return;
+  submitRepairCosts(`/api/v1/repair-parts/${btn.dataset.usageId}`, 'DELETE');
+});
+
+document.querySelectorAll('.repair-workflow-btn').forEach(btn => {
+  btn.addEventListener('click', async () => {
+    try {
+      await openRepairWorkflow(btn.getAttribute('data-repair-id'));
+    } catch (e) {
+      alert('❌ ' + e.message);
+    }
+  });
+});
+
+async function submitRepairWorkflow(form, url, method) {
+  const btn = form.querySelector('button[type="submit"]');
+  const label = btn.textContent;
+  btn.disabled = true; btn.textContent = '⌛ Сохранение...';
+  try {
+    const resp = await fetch(url, { method, body: new URLSearchParams(new FormData(form)) });
+    const data = await parseJsonOrThrow(resp);
+    if (!data.success) throw new Error(data.error || 'Не удалось сохранить');
+    bootstrap.Modal.getInstance(document.getElementById('repairWorkflowModal')).hide();
+    location.reload();
+  } catch (err) {
+    alert('❌ ' + err.message);
+  } finally {
+    btn.disabled = false; btn.textContent = label;
+  }
+}
+
+document.getElementById('repairAssignForm')?.addEventListener('submit', (e) => {
+  e.preventDefault();
+  const id = document.getElementById('repairWorkflowId').value;
+  submitRepairWorkflow(e.currentTarget, `/api/v1/repairs/${id}/assignee`, 'PUT');
+});
+
+document.getElementById('repairStatusForm')?.addEventListener('submit', (e) => {
+  e.preventDefault();
+  const id = document.getElementById('repairWorkflowId').value;
+  submitRepairWorkflow(e.currentTarget, `/api/v1/repairs/${id}/status`, 'POST');
+});
+
+// ===== Плановое ТО =====
+function escapeHtml(s) {
+  return String(s ?? '').replace(/[&<>"']/g, ch => ({ '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;' }[ch]));
+}
+
+function ymdToRu(s) {
+  if (!s) return '—';
+  const [y, m, d] = s.split('-');
+  return `${d}.${m}.${y}`;
+}
+
+const maintenanceBadges = { overdue: 'bg-danger', due: 'bg-warning text-dark', ok: 'bg-success' };
+
+function maintenanceTerm(d) {
+  const parts = [];
+  if (d.due_date) parts.push(ymdToRu(d.due_date));
+  if (d.due_hours) parts.push(`${d.due_hours} ч`);
+  return parts.join(' / ') || '—';
+}
+
+async function loadMaintenanceDue() {
+  const body = document.getElementById('maintenanceDueBody');
+  if (!body) return;
+  const all = document.getElementById('maintenanceShowAll')?.checked ? '?all=1' : '';
+  try {
+    const resp = await fetch('/api/v1/maintenance/due' + all, { cache: 'no-store' });
+    const data = await parseJsonOrThrow(resp);
+    if (!data.success) throw new Error(data.error || 'Не удалось получить сроки ТО');
+    if (!data.items.length) {
+      body.innerHTML = `<tr><td colspan="6" class="text-muted">${all ? 'Планов ТО пока нет' : 'Просроченного и ближайшего ТО нет'}</td></tr>`;
+      return;
+    }
+    body.innerHTML = data.items.map(d => `
+      <tr>
+        <td>${escapeHtml(d.equipment_name)} <span class="text-muted small">${escapeHtml(d.zone_name)}</span></td>
+        <td>${escapeHtml(d.plan_name)}</td>
+        <td>${ymdToRu(d.last_date)}</td>
+        <td>${maintenanceTerm(d)}</td>
+        <td>${d.usage_hours}</td>
+        <td><span class="badge ${maintenanceBadges[d.state] || 'bg-secondary'}">${escapeHtml(d.state_title)}</span></td>
+      </tr>`).join('');
+  } catch (e) {
+    body.innerHTML = `<tr><td colspan="6" class="text-danger">${escapeHtml(e.message)}</td></tr>`;
+  }
+}
+
+let maintenancePlans = [];
+
+async function loadMaintenancePlans() {
+  const body = document.getElementById('maintenancePlansBody');
+  if (!body) return;
+  try {
+    const resp = await fetch('/api/v1/maintenance/plans', { cache: 'no-store' });
+    const data = await parseJsonOrThrow(resp);
+    if (!data.success) throw new Error(data.error || 'Не удалось получить планы ТО');
+    maintenancePlans = data.plans;
+    if (!maintenancePlans.length) {
+      body.innerHTML = '<tr><td colspan="5" class="text-muted">Планов пока нет</td></tr>';
+      return;
+    }
+    body.innerHTML = maintenancePlans.map(p => {
+      const target = p.equipment_id ? escapeHtml(p.equipment_name) : `тип «${escapeHtml(p.equipment_type)}»`;
+      const interval = [p.interval_days ? `${p.interval_days} дн.` : '', p.interval_hours ? `${p.interval_hours} ч` : ''].filter(Boolean).join(' / ');
+      return `
+      <tr>
+        <td>${escapeHtml(p.name)}</td>
+        <td>${target}</td>
+        <td>${interval}</td>
+        <td>${p.checklist.length ? p.checklist.length + ' п.' : '—'}</td>
+        <td class="text-end text-nowrap">
+          <button class="btn btn-sm btn-outline-primary edit-plan-btn" data-plan-id="${p.id}" title="Изменить план">✏️</button>
+          <button class="btn btn-sm btn-outline-secondary" data-audit-entity="maintenance_plan" data-audit-id="${p.id}" title="История изменений">🕘</button>
+          <button class="btn btn-sm btn-outline-danger delete-plan-btn" data-plan-id="${p.id}" title="Удалить план">🗑️</button>
+        </td>
+      </tr>`;
+    }).join('');
+  } catch (e) {
+    body.innerHTML = `<tr><td colspan="5" class="text-danger">${escapeHtml(e.message)}</td></tr>`;
+  }
+}
+
+function fillPlanForm(p) {
+  document.getElementById('planId').value = p ? p.id : '';
+  document.getElementById('planName').value = p ? p.name : '';
+  document.getElementById('planEquipment').value = p && p.equipment_id ? p.equipment_id : '';
+  document.getElementById('planType').value = p ? p.equipment_type : '';
+  document.getElementById('planDays').value = p && p.interval_days ? p.interval_days : '';
+  document.getElementById('planHours').value = p && p.interval_hours ? p.interval_hours : '';
+  document.getElementById('planChecklist').value = p ? p.checklist.join('\n') : '';
+}
+
+document.getElementById('maintenanceShowAll')?.addEventListener('change', loadMaintenanceDue);
+
+document.getElementById('planModal')?.addEventListener('show.bs.modal', (ev) => {
+  // открыта кнопкой «➕ План ТО» — новый план
+  if (ev.relatedTarget) fillPlanForm(null);
+});
+
+document.getElementById('maintenancePlansBody')?.addEventListener('click', async (e) => {
+  const editBtn = e.target.closest('.edit-plan-btn');
+  if (editBtn) {
+    const p = maintenancePlans.find(x => String(x.id) === editBtn.getAttribute('data-plan-id'));
+    if (!p) return;
+    fillPlanForm(p);
+    new bootstrap.Modal(document.getElementById('planModal')).show();
+    return;
+  }
+  const delBtn = e.target.closest('.delete-plan-btn');
+  if (!delBtn) return;
+  if (!confirm('Удалить план ТО? История обслуживания сохранится.')) return;
+  try {
+    const resp = await fetch(`/api/v1/maintenance/plans/${delBtn.getAttribute('data-plan-id')}`, { method: 'DELETE' });
+    const data = await parseJsonOrThrow(resp);
+    if (!data.success) throw new Error(data.error || 'Не удалось удалить план');
+    loadMaintenancePlans();
+    loadMaintenanceDue();
+  } catch (err) {
+    alert('❌ ' + err.message);
+  }
+});
+
+document.getElementById('planForm')?.addEventListener('submit', async (e) => {
+  e.preventDefault();
+  const form = e.currentTarget;
+  const id = document.getElementById('planId').value;
+  const btn = form.querySelector('button[type="submit"]');
+  btn.disabled = true; btn.textContent = '⌛ Сохранение...';
+  try {
+    const body = new URLSearchParams(new FormData(form));
+    const resp = await fetch(id ? `/api/v1/maintenance/plans/${id}` : '/api/v1/maintenance/plans', { method: id ? 'PUT' : 'POST', body });
+    const data = await parseJsonOrThrow(resp);
+    if (!data.success) throw new Error(data.error || 'Не удалось сохранить план');
+    bootstrap.Modal.getInstance(document.getElementById('planModal')).hide();
+    loadMaintenancePlans();
+    loadMaintenanceDue();
+  } catch (err) {
+    alert('❌ ' + err.message);
+  } finally {
+    btn.disabled = false; btn.textContent = 'Сохранить';
+  }
+});
+
+// ТО единицы: сроки, отметка и история
+let eqChecklists = {};
+
+function renderLogChecklist() {
+  const planId = document.getElementById('logMaintenancePlan').value;
+  const items = eqChecklists[planId] || [];
+  document.getElementById('logMaintenanceChecklist').innerHTML = items.map((item, i) => `
+    <div class="form-check">
+      <input class="form-check-input" type="checkbox" name="done" value="${i}" id="logDone${i}">
+      <label class="form-check-label" for="logDone${i}">${escapeHtml(item)}</label>
+    </div>`).join('');
+}
+
+async function openEquipmentMaintenance(id, name) {
+  const modalEl = document.getElementById('maintenanceModal');
+  modalEl.querySelector('.modal-title').textContent = `Плановое ТО: ${name}`;
+  document.getElementById('logMaintenanceEqId').value = id;
+  const resp = await fetch(`/api/v1/equipment/${id}/maintenance`, { cache: 'no-store' });
+  const data = await parseJsonOrThrow(resp);
+  if (!data.success) throw new Error(data.error || 'Не удалось получить данные ТО');
+
+  eqChecklists = data.checklists || {};
+  document.getElementById('eqMaintenanceDue').innerHTML = data.due.length
+    ? data.due.map(d => `
+      <li class="list-group-item d-flex justify-content-between align-items-center">
+        <span>${escapeHtml(d.plan_name)} <span class="text-muted small">последнее: ${ymdToRu(d.last_date)}, срок: ${maintenanceTerm(d)}</span></span>
+        <span class="badge ${maintenanceBadges[d.state] || 'bg-secondary'}">${escapeHtml(d.state_title)}</span>
+      </li>`).join('')
+    : '<li class="list-group-item text-muted">Планов ТО для этой единицы нет</li>';
+
+  const planSelect = document.getElementById('logMaintenancePlan');
+  planSelect.innerHTML = '<option value="">Внеплановое ТО</option>' +
+    data.due.map(d => `<option value="${d.plan_id}">${escapeHtml(d.plan_name)}</option>`).join('');
+  const first = data.due.find(d => d.state !== 'ok') || data.due[0];
+  planSelect.value = first ? first.plan_id : '';
+  renderLogChecklist();
+  document.getElementById('logMaintenanceDate').value = new Date().toISOString().slice(0, 10);
+  document.getElementById('logMaintenanceUsage').value = data.item.UsageHours ?? '';
+  document.querySelector('#logMaintenanceForm textarea[name="comment"]').value = '';
+
+  document.getElementById('eqMaintenanceHistory').innerHTML = data.history.length
+    ? data.history.map(h => {
+      const done = h.checklist.filter(x => x.done).length;
+      return `
+      <tr>
+        <td>${ymdToRu(h.date)}</td>
+        <td>${escapeHtml(h.plan_name || 'Внеплановое')}</td>
+        <td>${h.usage_hours ?? '—'}</td>
+        <td title="${escapeHtml(h.checklist.map(x => (x.done ? '✔ ' : '✘ ') + x.item).join('\n'))}">${h.checklist.length ? `${done} из ${h.checklist.length}` : '—'}</td>
+        <td>${escapeHtml(h.performed_by)}</td>
+        <td>${escapeHtml(h.comment)}</td>
+      </tr>`;
+    }).join('')
+    : '<tr><td colspan="6" class="text-muted">ТО ещё не проводилось</td></tr>';
+
+  bootstrap.Modal.getOrCreateInstance(modalEl).show();
+}
+
+document.querySelectorAll('.maintenance-btn').forEach(btn => {
+  btn.addEventListener('click', async () => {
+    try {
+      await openEquipmentMaintenance(btn.getAttribute('data-eq-id'), btn.getAttribute('data-eq-name'));
+    } catch (e) {
+      alert('❌ ' + e.message);
+    }
+  });
+});
+
+document.getElementById('logMaintenancePlan')?.addEventListener('change', renderLogChecklist);
+
+document.getElementById('logMaintenanceForm')?.addEventListener('submit', async (e) => {
+  e.preventDefault();
+  const form = e.currentTarget;
+  const id = document.getElementById('logMaintenanceEqId').value;
+  const btn = form.querySelector('button[type="submit"]');
+  btn.disabled = true; btn.textContent = '⌛ Сохранение...';
+  try {
+    const body = new URLSearchParams(new FormData(form));
+    const resp = await fetch(`/api/v1/equipment/${id}/maintenance`, { method: 'POST', body });
+    const data = await parseJsonOrThrow(resp);
+    if (!data.success) throw new Error(data.error || 'Не удалось отметить ТО');
+    bootstrap.Modal.getInstance(document.getElementById('maintenanceModal')).hide();
+    location.reload();
+  } catch (err) {
+    alert('❌ ' + err.message);
+  } finally {
+    btn.disabled = false; btn.textContent = '✅ Отметить ТО';
+  }
+});
+
+loadMaintenanceDue();
+loadMaintenancePlans();
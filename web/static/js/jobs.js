@@ -0,0 +1,24 @@
+async function parseJsonOrThrow(response){
+  const ct=(response.headers.get('content-type')||'').toLowerCase();
+  if(ct.includes('application/json')||ct.includes('application/problem+json')) return response.json();
+  const text=await response.text(); throw new Error(text.slice(0,300)||'Сервер вернул не-JSON');
+}
+
+document.addEventListener('DOMContentLoaded', () => {
+  // ▶️ запуск задачи вне расписания
+  document.addEventListener('click', async (ev) => {
+    const btn = ev.target.closest('.run-job-btn');
+    if(!btn) return;
+    const name = btn.getAttribute('data-job');
+    if(!confirm(`Запустить задачу «${name}» сейчас?`)) return;
+    btn.disabled = true;
+    try{
+      const resp = await fetch(`/admin/jobs/${encodeURIComponent(name)}/run`, {method:'POST'});
+      const res = await parseJsonOrThrow(resp);
+      if(!res.success) throw new Error(res.detail || res.error || 'Ошибка запуска');
+      const lines = (res.details||[]).slice(0, 20).join('\n');
+      alert(`${res.message||'Готово'}: изменено ${res.changed}` + (lines ? `\n\n${lines}` : ''));
+      location.reload();
+    }catch(err){ alert('❌ ' + err.message); btn.disabled = false; }
+  });
+});
@@ -0,0 +1,139 @@
+// Платежи: оплата частями, возвраты и баланс клиента.
+// Кнопки с data-pay-subscription / data-pay-personal открывают платежи позиции,
+// с data-client-balance — баланс клиента.
+(function(){
+  let changed=false; // после закрытия модалки перезагрузить страницу
+
+  function esc(v){
+    return String(v??'').replace(/[&<>"']/g, ch=>({'&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;',"'":'&#39;'}[ch]));
+  }
+  function money(n){ return Number(n||0).toLocaleString('ru-RU', {minimumFractionDigits:2, maximumFractionDigits:2})+' ₽'; }
+  const STATUS_BADGES={'Оплачен':'success','Частично':'warning','Не оплачен':'danger','Переплата':'info'};
+  function statusBadge(s){ return `<span class="badge bg-${STATUS_BADGES[s]||'secondary'}">${esc(s)}</span>`; }
+
+  async function getJSON(url, opts){
+    const resp=await fetch(url, opts);
+    const ct=(resp.headers.get('content-type')||'').toLowerCase();
+    if(!ct.includes('json')) throw new Error('Сервер вернул не-JSON');
+    const res=await resp.json();
+    if(!res.success) throw new Error(res.error||'Ошибка запроса');
+    return res;
+  }
+
+  function ensureModal(){
+    let el=document.getElementById('paymentsModal');
+    if(el) return el;
+    el=document.createElement('div');
+    el.className='modal fade'; el.id='paymentsModal'; el.tabIndex=-1;
+    el.innerHTML=`<div class="modal-dialog modal-lg modal-dialog-scrollable"><div class="modal-content">
+      <div class="modal-header"><h5 class="modal-title">💳 Оплата</h5>
+        <button type="button" class="btn-close" data-bs-dismiss="modal"></button></div>
+      <div class="modal-body"><div id="paymentsBody"></div></div>
+    </div></div>`;
+    el.addEventListener('hidden.bs.modal', ()=>{ if(changed) location.reload(); });
+    document.body.appendChild(el);
+    return el;
+  }
+
+  function paymentsTable(list, withItem){
+    if(!list.length) return '<div class="text-muted small">Платежей пока нет</div>';
+    return `<table class="table table-sm table-striped mb-0">
+      <thead><tr><th>Когда</th>${withItem?'<th>За что</th>':''}<th>Вид</th><th>Сумма</th><th>Способ</th><th>Кассир</th><th>Комментарий</th></tr></thead>
+      <tbody>${list.map(p=>`<tr>
+        <td class="text-nowrap">${esc(p.paid_at)}</td>
+        ${withItem?`<td>${p.subscription_id?'абонемент #'+p.subscription_id:'персональная #'+p.personal_training_id}</td>`:''}
+        <td>${p.kind==='Возврат'?'<span class="badge bg-danger">Возврат</span>':'<span class="badge bg-success">Оплата</span>'}</td>
+        <td class="text-nowrap">${money(p.amount)}</td>
+        <td>${esc(p.method)}</td>
+        <td>${esc(p.cashier)}</td>
+        <td>${esc(p.comment)}</td>
+      </tr>`).join('')}</tbody></table>`;
+  }
+
+  // Платежи одной позиции: item — 'subscription' | 'personal_training'
+  window.showPayments=async function(item, id){
+    const el=ensureModal();
+    const body=el.querySelector('#paymentsBody');
+    body.innerHTML='<div class="text-muted">⌛ Загрузка...</div>';
+    bootstrap.Modal.getOrCreateInstance(el).show();
+    const url=item==='subscription' ? `/api/v1/subscriptions/${id}/payments` : `/api/v1/personal-trainings/${id}/payments`;
+    try{
+      const res=await getJSON(url);
+      el.querySelector('.modal-title').textContent='💳 '+res.title;
+      const owed=Math.max(Number(res.owed||0), 0);
+      body.innerHTML=`
+        <div class="d-flex flex-wrap gap-3 mb-3">
+          <div>К оплате: <b>${money(res.price)}</b></div>
+          <div>Оплачено: <b>${money(res.paid)}</b></div>
+          <div>Остаток: <b>${money(res.owed)}</b></div>
+          <div>${statusBadge(res.status)}</div>
+        </div>
+        <form id="paymentForm" class="row g-2 align-items-end mb-3">
+          <input type="hidden" name="${item==='subscription'?'subscription_id':'personal_training_id'}" value="${esc(id)}">
+          <div class="col-md-2"><label class="form-label">Вид</label>
+            <select class="form-select" name="kind"><option>Оплата</option><option>Возврат</option></select></div>
+          <div class="col-md-2"><label class="form-label">Сумма</label>
+            <input class="form-control" type="number" step="0.01" min="0.01" name="amount" value="${owed?owed.toFixed(2):''}" required></div>
+          <div class="col-md-3"><label class="form-label">Способ</label>
+            <select class="form-select" name="method">${(res.methods||[]).map(m=>`<option>${esc(m)}</option>`).join('')}</select></div>
+          <div class="col-md-3"><label class="form-label">Комментарий</label>
+            <input class="form-control" name="comment" maxlength="200"></div>
+          <div class="col-md-2"><button class="btn btn-success w-100" type="submit">Провести</button></div>
+        </form>
+        <h6>История</h6>
+        ${paymentsTable(res.payments||[], false)}`;
+      body.querySelector('#paymentForm').addEventListener('submit', async (ev)=>{
+        ev.preventDefault();
+        const btn=ev.target.querySelector('button[type="submit"]');
+        btn.disabled=true;
+        try{
+          const r=await getJSON('/api/v1/payments', {method:'POST', body:new FormData(ev.target)});
+          changed=true;
+          alert('✅ '+r.message);
+          window.showPayments(item, id);
+        }catch(err){ alert('❌ '+err.message); btn.disabled=false; }
+      });
+    }catch(e){ body.innerHTML=`<div class="alert alert-danger mb-0">❌ ${esc(e.message)}</div>`; }
+  };
+
+  // Баланс клиента: начислено, оплачено, позиции и история платежей
+  window.showClientBalance=async function(clientId, clientName){
+    const el=ensureModal();
+    el.querySelector('.modal-title').textContent='💰 Баланс: '+(clientName||'#'+clientId);
+    const body=el.querySelector('#paymentsBody');
+    body.innerHTML='<div class="text-muted">⌛ Загрузка...</div>';
+    bootstrap.Modal.getOrCreateInstance(el).show();
+    try{
+      const res=await getJSON(`/api/v1/clients/${clientId}/balance`);
+      const bal=Number(res.balance||0);
+      body.innerHTML=`
+        <div class="d-flex flex-wrap gap-3 mb-3">
+          <div>Начислено: <b>${money(res.charged)}</b></div>
+          <div>Оплачено: <b>${money(res.paid)}</b></div>
+          <div>Баланс: <b class="${bal<0?'text-danger':'text-success'}">${money(bal)}</b>${bal<0?' (долг)':''}</div>
+        </div>
+        <h6>Позиции</h6>
+        ${(res.items||[]).length ? `<table class="table table-sm table-striped">
+          <thead><tr><th>Что</th><th>Дата</th><th>Цена</th><th>Оплачено</th><th>Статус</th><th></th></tr></thead>
+          <tbody>${res.items.map(d=>`<tr>
+            <td>${d.item==='subscription'?'🎫':'🏋️'} ${esc(d.title)} #${d.id}</td>
+            <td>${esc(d.date)}</td>
+            <td class="text-nowrap">${money(d.price)}</td>
+            <td class="text-nowrap">${money(d.paid)}</td>
+            <td>${statusBadge(d.status)}</td>
+            <td><button class="btn btn-sm btn-outline-success" data-pay-${d.item==='subscription'?'subscription':'personal'}="${d.id}" title="Платежи">💳</button></td>
+          </tr>`).join('')}</tbody></table>` : '<div class="text-muted small mb-3">Начислений нет</div>'}
+        <h6>Платежи</h6>
+        ${paymentsTable(res.payments||[], true)}`;
+    }catch(e){ body.innerHTML=`<div class="alert alert-danger mb-0">❌ ${esc(e.message)}</div>`; }
+  };
+
+  document.addEventListener('click', (ev)=>{
+    const btn=ev.target.closest('[data-pay-subscription],[data-pay-personal],[data-client-balance]');
+    if(!btn) return;
+    ev.preventDefault();
+    if(btn.dataset.clientBalance) window.showClientBalance(btn.dataset.clientBalance, btn.dataset.clientName||'');
+    else if(btn.dataset.paySubscription) window.showPayments('subscription', btn.dataset.paySubscription);
+    else window.showPayments('personal_training', btn.dataset.payPersonal);
+  });
+})();
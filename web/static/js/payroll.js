@@ -0,0 +1,108 @@
+async function parseJsonOrThrow(response){
+  const ct=(response.headers.get('content-type')||'').toLowerCase();
+  if(ct.includes('application/json')||ct.includes('application/problem+json')) return response.json();
+  const text=await response.text(); throw new Error(text.slice(0,300)||'Сервер вернул не-JSON');
+}
+
+function esc(s){ return String(s ?? '').replace(/[&<>"']/g, ch => ({'&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;',"'":'&#39;'}[ch])); }
+function money(v){ return Number(v||0).toLocaleString('ru-RU',{minimumFractionDigits:2, maximumFractionDigits:2}); }
+
+document.addEventListener('DOMContentLoaded', () => {
+  const monthInput=document.getElementById('prMonth');
+  const month=()=>monthInput.value;
+
+  async function api(url, opts){
+    const res=await parseJsonOrThrow(await fetch(url, Object.assign({cache:'no-store'}, opts||{})));
+    if(!res.success) throw new Error(res.detail || res.error || 'Ошибка');
+    return res;
+  }
+
+  // 📋 сводка за месяц
+  async function loadSummary(){
+    const m=month();
+    document.getElementById('prCsv').href=`/api/v1/payroll/export?month=${m}&format=csv`;
+    document.getElementById('prXlsx').href=`/api/v1/payroll/export?month=${m}&format=xlsx`;
+    const res=await api(`/api/v1/payroll?month=${encodeURIComponent(m)}`);
+    const status=document.getElementById('prStatus');
+    status.textContent=res.closed ? `закрыт ${res.closed_at}` : 'открыт';
+    status.className='badge ms-2 '+(res.closed ? 'bg-dark' : 'bg-secondary');
+    document.getElementById('prClose').classList.toggle('d-none', res.closed);
+    document.getElementById('prReopen').classList.toggle('d-none', !res.closed);
+    document.getElementById('prSummary').innerHTML = res.trainers.map(t=>`
+      <tr class="${t.configured||t.lines ? '' : 'text-muted'}">
+        <td>${esc(t.trainer_name)}${t.configured ? '' : ' <span class="small">(нет правил)</span>'}</td>
+        <td>${t.lines}</td>
+        <td>${money(t.total)}${t.changed ? ` <span class="badge bg-warning text-dark" title="По текущим данным: ${money(t.live_total)} ₽">данные изменились</span>` : ''}</td>
+        <td><button class="btn btn-sm btn-outline-primary pr-lines-btn" data-id="${t.trainer_id}" data-name="${esc(t.trainer_name)}" type="button">📄 Строки</button></td>
+      </tr>`).join('') || '<tr><td colspan="4" class="text-muted">Тренеров нет</td></tr>';
+    document.getElementById('prTotal').textContent=money(res.total);
+  }
+
+  // ⚙️ правила оплаты
+  async function loadRules(){
+    const res=await api('/api/v1/payroll/rules');
+    document.getElementById('prRules').innerHTML = res.rules.map(r=>`
+      <tr>
+        <td>${esc(r.trainer_name)}</td>
+        <td><input type="number" class="form-control form-control-sm" name="per_group" min="0" step="0.01" value="${r.per_group}"></td>
+        <td><input type="number" class="form-control form-control-sm" name="personal_percent" min="0" max="100" step="0.01" value="${r.personal_percent}"></td>
+        <td><input type="number" class="form-control form-control-sm" name="fill_threshold" min="0" max="100" step="0.01" value="${r.fill_threshold}"></td>
+        <td><input type="number" class="form-control form-control-sm" name="fill_bonus" min="0" step="0.01" value="${r.fill_bonus}"></td>
+        <td><button class="btn btn-sm ${r.configured ? 'btn-outline-success' : 'btn-success'} pr-rule-save" data-id="${r.trainer_id}" type="button">💾</button></td>
+      </tr>`).join('') || '<tr><td colspan="6" class="text-muted">Тренеров нет</td></tr>';
+  }
+
+  async function reload(){
+    try{ await Promise.all([loadSummary(), loadRules()]); }
+    catch(err){ alert('❌ ' + err.message); }
+  }
+
+  monthInput.addEventListener('change', ()=>{ loadSummary().catch(err=>alert('❌ ' + err.message)); });
+
+  document.addEventListener('click', async (ev) => {
+    const save=ev.target.closest('.pr-rule-save');
+    if(save){
+      const body=new URLSearchParams();
+      save.closest('tr').querySelectorAll('input').forEach(i=>body.append(i.name, i.value));
+      save.disabled=true;
+      try{ await api(`/api/v1/trainers/${save.dataset.id}/pay-rule`, {method:'PUT', body}); await reload(); }
+      catch(err){ alert('❌ ' + err.message); save.disabled=false; }
+      return;
+    }
+
+    const linesBtn=ev.target.closest('.pr-lines-btn');
+    if(linesBtn){
+      try{
+        const res=await api(`/api/v1/payroll/${linesBtn.dataset.id}?month=${encodeURIComponent(month())}`);
+        document.getElementById('prLinesName').textContent=`${linesBtn.dataset.name}, ${month()}`;
+        document.getElementById('prLinesNote').innerHTML = res.changed
+          ? `<div class="alert alert-warning">После закрытия месяца данные изменились: по текущим данным ${money(res.live_total)} ₽. Выплачено по строкам ниже.</div>` : '';
+        document.getElementById('prLines').innerHTML = res.lines.map(l=>`
+          <tr><td>${esc(l.time)}</td><td>${esc(l.kind_title)}</td><td>${esc(l.description)}</td>
+              <td>${l.kind==='personal' ? money(l.base)+' ₽' : l.base+' %'}</td><td>${money(l.amount)}</td></tr>`).join('')
+          || '<tr><td colspan="5" class="text-muted">Начислений нет</td></tr>';
+        document.getElementById('prLinesTotal').textContent=money(res.total);
+        new bootstrap.Modal(document.getElementById('prLinesModal')).show();
+      }catch(err){ alert('❌ ' + err.message); }
+      return;
+    }
+
+    if(ev.target.closest('#prClose')){
+      if(!confirm(`Закрыть ${month()}? Начисления будут зафиксированы.`)) return;
+      try{
+        const body=new URLSearchParams({month: month()});
+        const res=await api('/api/v1/payroll/periods', {method:'POST', body});
+        alert(res.message); await loadSummary();
+      }catch(err){ alert('❌ ' + err.message); }
+      return;
+    }
+
+    if(ev.target.closest('#prReopen')){
+      if(!confirm(`Переоткрыть ${month()}? Зафиксированные начисления будут удалены и пересчитаны по текущим данным.`)) return;
+      try{ const res=await api(`/api/v1/payroll/periods/${month()}`, {method:'DELETE'}); alert(res.message); await loadSummary(); }
+      catch(err){ alert('❌ ' + err.message); }
+    }
+  });
+
+  reload();
+});
@@ -0,0 +1,42 @@
+async function parseJsonOrThrow(response){
+  const ct=(response.headers.get('content-type')||'').toLowerCase();
+  if(ct.includes('application/json')||ct.includes('application/problem+json')) return response.json();
+  const text=await response.text(); throw new Error(text.slice(0,300)||'Сервер вернул не-JSON');
+}
+
+function esc(s){ return String(s ?? '').replace(/[&<>"']/g, ch => ({'&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;',"'":'&#39;'}[ch])); }
+function num(v){ return v==null ? '—' : Number(v).toLocaleString('ru-RU',{maximumFractionDigits:1}); }
+
+document.addEventListener('DOMContentLoaded', () => {
+  function cells(r){
+    const unknown=r.unknown ? ` <span class="badge bg-secondary" title="Закрыты без времени закрытия">+${r.unknown}?</span>` : '';
+    return `<td>${r.failures}</td><td>${r.repaired}${unknown}</td><td>${num(r.downtime_hours)}</td>
+      <td>${num(r.mtbf_hours)}</td><td>${num(r.mttr_hours)}</td>
+      <td class="${r.availability<95 ? 'text-danger fw-semibold' : ''}">${num(r.availability)}</td>`;
+  }
+
+  async function load(){
+    const q=new URLSearchParams({
+      from: document.getElementById('rlFrom').value,
+      to: document.getElementById('rlTo').value,
+      limit: document.getElementById('rlLimit').value || '5',
+    });
+    const res=await parseJsonOrThrow(await fetch(`/api/v1/reports/equipment-reliability?${q}`, {cache:'no-store'}));
+    if(!res.success) throw new Error(res.detail || res.error || 'Ошибка');
+
+    document.getElementById('rlWorst').innerHTML=res.worst.map((r,i)=>`
+      <tr><td>${i+1}</td><td>${esc(r.name)}</td><td>${esc(r.zone_name)}</td><td class="fw-semibold">${r.failures}</td>
+        <td>${num(r.downtime_hours)}</td><td>${num(r.mtbf_hours)}</td><td>${num(r.mttr_hours)}</td><td>${num(r.availability)}</td></tr>`).join('')
+      || '<tr><td colspan="8" class="text-muted">Отказов за период не было 🎉</td></tr>';
+    document.getElementById('rlZones').innerHTML=res.zones.map(r=>`
+      <tr><td>${esc(r.name)}</td>${cells(r)}</tr>`).join('')
+      || '<tr><td colspan="7" class="text-muted">Нет данных</td></tr>';
+    document.getElementById('rlEquipment').innerHTML=res.equipment.map(r=>`
+      <tr><td>${esc(r.name)}</td><td>${esc(r.zone_name)}</td>${cells(r)}</tr>`).join('')
+      || '<tr><td colspan="8" class="text-muted">Нет данных</td></tr>';
+  }
+
+  const reload=()=>load().catch(err=>alert('❌ ' + err.message));
+  ['rlFrom','rlTo','rlLimit'].forEach(id=>document.getElementById(id).addEventListener('change', reload));
+  reload();
+});
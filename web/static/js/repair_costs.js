@@ -0,0 +1,165 @@
+async function parseJsonOrThrow(response){
+  const ct=(response.headers.get('content-type')||'').toLowerCase();
+  if(ct.includes('application/json')||ct.includes('application/problem+json')) return response.json();
+  const text=await response.text(); throw new Error(text.slice(0,300)||'Сервер вернул не-JSON');
+}
+
+function esc(s){ return String(s ?? '').replace(/[&<>"']/g, ch => ({'&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;',"'":'&#39;'}[ch])); }
+function money(v){ return Number(v||0).toLocaleString('ru-RU',{minimumFractionDigits:2, maximumFractionDigits:2}); }
+
+document.addEventListener('DOMContentLoaded', () => {
+  const isAdmin=document.getElementById('rcPage').dataset.admin==='1';
+  let vendors=[], parts=[];
+
+  async function api(url, opts){
+    const res=await parseJsonOrThrow(await fetch(url, Object.assign({cache:'no-store'}, opts||{})));
+    if(!res.success) throw new Error(res.detail || res.error || 'Ошибка');
+    return res;
+  }
+
+  function spendRows(list, empty){
+    return list.map(s=>`
+      <tr><td>${esc(s.name)}</td><td>${s.repairs}</td><td>${money(s.labour)}</td><td>${money(s.parts)}</td><td class="fw-semibold">${money(s.total)}</td></tr>`).join('')
+      || `<tr><td colspan="5" class="text-muted">${empty}</td></tr>`;
+  }
+
+  // 📊 затраты за период и кандидаты на замену
+  async function loadSpend(){
+    const q=new URLSearchParams({
+      from: document.getElementById('rcFrom').value,
+      to: document.getElementById('rcTo').value,
+      replace_share: document.getElementById('rcShare').value || '100',
+    });
+    const res=await api(`/api/v1/reports/repair-spend?${q}`);
+    document.getElementById('rcLabour').textContent=money(res.labour);
+    document.getElementById('rcParts').textContent=money(res.parts);
+    document.getElementById('rcTotal').textContent=money(res.total);
+    document.getElementById('rcByEquipment').innerHTML=spendRows(res.by_equipment, 'Затрат за период нет');
+    document.getElementById('rcByZone').innerHTML=spendRows(res.by_zone, 'Затрат за период нет');
+    document.getElementById('rcByMonth').innerHTML=spendRows(res.by_month, 'Затрат за период нет');
+    document.getElementById('rcCandidates').innerHTML=res.replace_candidates.map(r=>`
+      <tr class="${r.ratio>=100 ? 'table-danger' : ''}">
+        <td>${esc(r.equipment_name)}</td><td>${esc(r.zone_name)}</td>
+        <td>${money(r.spend)}</td><td>${money(r.replacement_cost)}</td><td>${r.ratio}</td>
+      </tr>`).join('') || '<tr><td colspan="5" class="text-muted">Таких единиц нет</td></tr>';
+  }
+
+  // 📦 склад
+  async function loadParts(){
+    const low=document.getElementById('rcLowOnly').checked;
+    parts=(await api(`/api/v1/parts${low ? '?low=1' : ''}`)).parts;
+    document.getElementById('rcPartsBody').innerHTML=parts.map(p=>`
+      <tr class="${p.low ? 'table-warning' : ''}">
+        <td>${esc(p['название'])}${p.low ? ' <span class="badge bg-warning text-dark">заказать</span>' : ''}</td>
+        <td>${esc(p['артикул'])}</td>
+        <td>${p['остаток']} ${esc(p['единица'])}</td>
+        <td>${p['порог_заказа'] || '—'}</td>
+        <td>${money(p['цена'])}</td>
+        <td>${esc(p['поставщик']) || '—'}</td>
+        <td class="text-nowrap">
+          <button class="btn btn-sm btn-outline-success rc-receive" data-id="${p['id_запчасти']}" type="button" title="Приход">📥</button>
+          <button class="btn btn-sm btn-outline-primary rc-edit-part" data-id="${p['id_запчасти']}" type="button" title="Изменить">✏️</button>
+          ${isAdmin ? `<button class="btn btn-sm btn-outline-danger rc-del-part" data-id="${p['id_запчасти']}" type="button" title="Удалить">🗑️</button>` : ''}
+        </td>
+      </tr>`).join('') || `<tr><td colspan="7" class="text-muted">${low ? 'Заказывать нечего' : 'Склад пуст'}</td></tr>`;
+  }
+
+  // 🤝 подрядчики
+  async function loadVendors(){
+    vendors=(await api('/api/v1/vendors')).vendors;
+    document.getElementById('rcVendorsBody').innerHTML=vendors.map(v=>`
+      <tr>
+        <td>${esc(v['название'])}</td><td>${esc(v['контакт'])}</td><td>${esc(v['телефон'])}</td>
+        <td>${v.email ? `<a href="mailto:${esc(v.email)}">${esc(v.email)}</a>` : ''}</td><td class="small">${esc(v['примечание'])}</td>
+        <td class="text-nowrap">
+          <button class="btn btn-sm btn-outline-primary rc-edit-vendor" data-id="${v['id_подрядчика']}" type="button" title="Изменить">✏️</button>
+          ${isAdmin ? `<button class="btn btn-sm btn-outline-danger rc-del-vendor" data-id="${v['id_подрядчика']}" type="button" title="Удалить">🗑️</button>` : ''}
+        </td>
+      </tr>`).join('') || '<tr><td colspan="6" class="text-muted">Подрядчиков нет</td></tr>';
+    document.getElementById('rcPartVendor').innerHTML='<option value="">— не указан —</option>' +
+      vendors.map(v=>`<option value="${v['id_подрядчика']}">${esc(v['название'])}</option>`).join('');
+  }
+
+  const fail=err=>alert('❌ ' + err.message);
+
+  function reload(){
+    return Promise.all([loadSpend(), loadVendors().then(loadParts)]).catch(fail);
+  }
+
+  ['rcFrom','rcTo','rcShare'].forEach(id=>document.getElementById(id).addEventListener('change', ()=>loadSpend().catch(fail)));
+  document.getElementById('rcLowOnly').addEventListener('change', ()=>loadParts().catch(fail));
+
+  function openPart(p){
+    const f=document.getElementById('rcPartForm');
+    f.reset();
+    f.id.value=p ? p['id_запчасти'] : '';
+    if(p){
+      f.name.value=p['название']; f.sku.value=p['артикул']; f.unit.value=p['единица'];
+      f.reorder_level.value=p['порог_заказа'] || ''; f.price.value=p['цена'] || '';
+      f.vendor_id.value=p['id_подрядчика'] || '';
+    }
+    // остаток меняется только приходом и списанием на заявки
+    document.getElementById('rcPartStockCol').classList.toggle('d-none', !!p);
+    bootstrap.Modal.getOrCreateInstance(document.getElementById('rcPartModal')).show();
+  }
+
+  function openVendor(v){
+    const f=document.getElementById('rcVendorForm');
+    f.reset();
+    f.id.value=v ? v['id_подрядчика'] : '';
+    if(v){
+      f.name.value=v['название']; f.contact.value=v['контакт']; f.phone.value=v['телефон'];
+      f.email.value=v.email; f.note.value=v['примечание'];
+    }
+    bootstrap.Modal.getOrCreateInstance(document.getElementById('rcVendorModal')).show();
+  }
+
+  async function submitForm(form, base, modalId){
+    const id=form.id.value;
+    const body=new URLSearchParams(new FormData(form));
+    body.delete('id');
+    const btn=form.querySelector('button[type="submit"]');
+    btn.disabled=true;
+    try{
+      await api(id ? `${base}/${id}` : base, {method: id ? 'PUT' : 'POST', body});
+      bootstrap.Modal.getInstance(document.getElementById(modalId)).hide();
+      await reload();
+    }catch(err){ fail(err); }
+    finally{ btn.disabled=false; }
+  }
+
+  document.getElementById('rcPartForm').addEventListener('submit', ev=>{ ev.preventDefault(); submitForm(ev.currentTarget, '/api/v1/parts', 'rcPartModal'); });
+  document.getElementById('rcVendorForm').addEventListener('submit', ev=>{ ev.preventDefault(); submitForm(ev.currentTarget, '/api/v1/vendors', 'rcVendorModal'); });
+  document.getElementById('rcAddPart').addEventListener('click', ()=>openPart(null));
+  document.getElementById('rcAddVendor').addEventListener('click', ()=>openVendor(null));
+
+  document.addEventListener('click', async (ev) => {
+    const btn=ev.target.closest('.rc-receive, .rc-edit-part, .rc-del-part, .rc-edit-vendor, .rc-del-vendor');
+    if(!btn) return;
+    const id=Number(btn.dataset.id);
+    const part=parts.find(p=>p['id_запчасти']===id);
+    const vendor=vendors.find(v=>v['id_подрядчика']===id);
+    try{
+      if(btn.classList.contains('rc-receive')){
+        const qty=prompt(`Приход: ${part['название']}, ${part['единица']}`, '1');
+        if(!qty) return;
+        await api(`/api/v1/parts/${id}/receive`, {method:'POST', body:new URLSearchParams({quantity: qty})});
+        await loadParts();
+      } else if(btn.classList.contains('rc-edit-part')){
+        openPart(part);
+      } else if(btn.classList.contains('rc-del-part')){
+        if(!confirm(`Удалить «${part['название']}» со склада?`)) return;
+        await api(`/api/v1/parts/${id}`, {method:'DELETE'});
+        await loadParts();
+      } else if(btn.classList.contains('rc-edit-vendor')){
+        openVendor(vendor);
+      } else if(btn.classList.contains('rc-del-vendor')){
+        if(!confirm(`Удалить подрядчика «${vendor['название']}»? У заявок и запчастей он будет снят.`)) return;
+        await api(`/api/v1/vendors/${id}`, {method:'DELETE'});
+        await reload();
+      }
+    }catch(err){ fail(err); }
+  });
+
+  reload();
+});
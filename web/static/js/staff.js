@@ -0,0 +1,104 @@
+async function parseJsonOrThrow(resp){
+  const ct=(resp.headers.get('content-type')||'').toLowerCase();
+  if(ct.includes('application/json')||ct.includes('application/problem+json')) return resp.json();
+  const text=await resp.text(); throw new Error(text.slice(0,300)||'Сервер вернул не-JSON');
+}
+
+// Селект тренера показываем только для роли «Тренер»
+function syncTrainerSelect(form){
+  const role=form.querySelector('.staff-role')?.value;
+  form.querySelector('.staff-trainer-wrap')?.classList.toggle('d-none', role!=='Тренер');
+}
+
+async function loadTrainers(){
+  try{
+    const res=await parseJsonOrThrow(await fetch('/api/v1/trainers-for-select'));
+    if(!res.success) return;
+    document.querySelectorAll('.staff-trainer').forEach(sel=>{
+      (res.trainers||[]).forEach(t=>{
+        const o=document.createElement('option'); o.value=t.id; o.textContent=t.name; sel.appendChild(o);
+      });
+    });
+  }catch(e){ console.warn('trainers-for-select:', e.message); }
+}
+
+document.addEventListener('DOMContentLoaded', ()=>{
+  loadTrainers();
+  ['addStaffForm','editStaffForm'].forEach(id=>{
+    const form=document.getElementById(id);
+    form?.querySelector('.staff-role')?.addEventListener('change', ()=>syncTrainerSelect(form));
+    if(form) syncTrainerSelect(form);
+  });
+
+  // CREATE
+  document.getElementById('addStaffForm')?.addEventListener('submit', async (e)=>{
+    e.preventDefault();
+    const form=e.currentTarget, btn=form.querySelector('button[type="submit"]');
+    btn.disabled=true; btn.innerHTML='⌛ Сохранение...';
+    try{
+      const resp=await fetch('/staff',{method:'POST', body:new URLSearchParams(new FormData(form))});
+      const res=await parseJsonOrThrow(resp);
+      if(res.success){
+        alert('✅ '+(res.message||'Добавлен'));
+        bootstrap.Modal.getInstance(document.getElementById('addStaffModal')).hide();
+        form.reset(); location.reload();
+      } else { alert('❌ '+(res.error||'Не удалось сохранить')); }
+    }catch(e2){ alert('❌ '+e2.message); }
+    finally{ btn.disabled=false; btn.innerHTML='Сохранить'; }
+  });
+
+  // OPEN EDIT
+  document.querySelectorAll('.edit-staff-btn').forEach(btn=>{
+    btn.addEventListener('click', async ()=>{
+      const id=btn.getAttribute('data-staff-id');
+      try{
+        const res=await parseJsonOrThrow(await fetch(`/api/v1/staff/${id}`));
+        if(!res.success) throw new Error(res.error||'Не удалось получить сотрудника');
+        const s=res.staff;
+        const form=document.getElementById('editStaffForm');
+        form.reset();
+        document.getElementById('editStaffId').value=s.id;
+        document.getElementById('editStaffLogin').value=s.login||'';
+        document.getElementById('editStaffFio').value=s.fio||'';
+        document.getElementById('editStaffRole').value=s.role||'';
+        document.getElementById('editStaffTrainer').value=s.trainer_id||0;
+        document.getElementById('editStaffActive').checked=!!s.active;
+        syncTrainerSelect(form);
+        new bootstrap.Modal(document.getElementById('editStaffModal')).show();
+      }catch(e){ alert('❌ '+e.message); }
+    });
+  });
+
+  // UPDATE
+  document.getElementById('editStaffForm')?.addEventListener('submit', async (e)=>{
+    e.preventDefault();
+    const form=e.currentTarget, btn=form.querySelector('button[type="submit"]');
+    const id=document.getElementById('editStaffId').value;
+    btn.disabled=true; btn.innerHTML='⌛ Обновление...';
+    try{
+      const body=new URLSearchParams(new FormData(form));
+      const resp=await fetch(`/staff/${id}`,{method:'PUT', body});
+      const res=await parseJsonOrThrow(resp);
+      if(res.success){
+        alert('✅ '+(res.message||'Обновлено'));
+        bootstrap.Modal.getInstance(document.getElementById('editStaffModal')).hide();
+        location.reload();
+      } else { alert('❌ '+(res.error||'Не удалось обновить')); }
+    }catch(e2){ alert('❌ '+e2.message); }
+    finally{ btn.disabled=false; btn.innerHTML='Обновить'; }
+  });
+
+  // DELETE
+  document.querySelectorAll('.delete-staff-btn').forEach(btn=>{
+    btn.addEventListener('click', async ()=>{
+      const id=btn.getAttribute('data-staff-id');
+      const name=btn.getAttribute('data-staff-name')||'сотрудник';
+      if(!confirm(`Удалить учётную запись «${name}»?`)) return;
+      try{
+        const res=await parseJsonOrThrow(await fetch(`/staff/${id}`,{method:'DELETE'}));
+        if(res.success){ alert('✅ '+(res.message||'Удалено')); location.reload(); }
+        else { alert('❌ '+(res.error||'Не удалось удалить')); }
+      }catch(e){ alert('❌ '+e.message); }
+    });
+  });
+});
@@ -40,6 +40,51 @@ async function fillTariffs(selectId, selectedId){
   }catch(e){ console.error('tariffs-for-select', e); }
 }
 
+function fmtDate(iso){
+  const [y,m,d]=(iso||'').split('-');
+  return d ? `${d}.${m}.${y}` : '';
+}
+
+function escapeHtml(s){
+  return String(s??'').replace(/[&<>"']/g, ch=>({'&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;',"'":'&#39;'}[ch]));
+}
+
+// история заморозок и остаток лимита в модалке редактирования
+function renderFreezes(freezes, a){
+  const rows=document.getElementById('freezeRows');
+  if(!rows) return;
+  rows.innerHTML='';
+  if(!freezes || !freezes.length){
+    rows.innerHTML='<tr><td colspan="4" class="text-muted">Заморозок не было</td></tr>';
+  }
+  (freezes||[]).forEach(f=>{
+    const tr=document.createElement('tr');
+    const mark=f.active ? ' <span class="badge bg-info text-dark">идёт</span>' : (f.upcoming ? ' <span class="badge bg-light text-dark">впереди</span>' : '');
+    const action=(f.active||f.upcoming)
+      ? `<button type="button" class="btn btn-sm btn-outline-secondary end-freeze-btn" data-freeze-id="${f.id}">${f.upcoming?'Отменить':'Завершить'}</button>`
+      : '';
+    tr.innerHTML=`<td>${fmtDate(f.start_date)} — ${fmtDate(f.end_date)}${mark}</td><td>${f.days}</td><td>${escapeHtml(f.reason)}</td><td>${action}</td>`;
+    rows.appendChild(tr);
+  });
+  const info=document.getElementById('freezeAllowance');
+  const add=document.getElementById('freezeAddRow');
+  if(!a){ if(info) info.textContent=''; return; }
+  if(info){
+    info.textContent = a.max_count > 0
+      ? `Осталось: ${a.remaining_count} из ${a.max_count} раз, ${a.remaining_days} из ${a.max_days} дн.`
+      : 'Тариф без заморозки';
+  }
+  const can = a.remaining_count > 0 && a.remaining_days > 0;
+  add?.querySelectorAll('input,button').forEach(el=>{ el.disabled=!can; });
+}
+
+async function reloadFreezes(subId){
+  const resp=await fetch(`/api/v1/subscriptions/${subId}/freezes`);
+  const res=await parseJsonOrThrow(resp);
+  if(!res.success) throw new Error(res.error||'Не удалось загрузить заморозки');
+  renderFreezes(res.freezes, res.allowance);
+}
+
 document.addEventListener('DOMContentLoaded', () => {
   // селекты в модалке "Добавить"
   fillClients('clientSelect');
@@ -85,6 +130,8 @@ document.addEventListener('DOMContentLoaded', () => {
       document.getElementById('editEndDate').value   = (s.end_date||'').slice(0,10);
       document.getElementById('editStatus').value    = s.status || 'Активен';
       document.getElementById('editPrice').value     = s.price != null ? String(s.price) : '';
+      renderFreezes(res.freezes, res.allowance);
+      ['freezeStart','freezeEnd','freezeReason'].forEach(i=>{ document.getElementById(i).value=''; });
 
       new bootstrap.Modal(document.getElementById('editSubscriptionModal')).show();
     }catch(err){ alert('❌ ' + err.message); }
@@ -111,6 +158,63 @@ document.addEventListener('DOMContentLoaded', () => {
     finally{ btn.disabled=false; btn.textContent='Обновить'; }
   });
 
+  // ЗАМОРОЗИТЬ
+  document.getElementById('freezeAddBtn')?.addEventListener('click', async (e) => {
+    const btn = e.currentTarget;
+    const id = document.getElementById('editSubId').value;
+    const body = new URLSearchParams({
+      start_date: document.getElementById('freezeStart').value,
+      end_date:   document.getElementById('freezeEnd').value,
+      reason:     document.getElementById('freezeReason').value,
+    });
+    btn.disabled = true;
+    try{
+      const resp = await fetch(`/api/v1/subscriptions/${id}/freezes`, {method:'POST', body});
+      const res = await parseJsonOrThrow(resp);
+      if(!res.success) throw new Error(res.error||'Не удалось заморозить');
+      alert(res.message||'Абонемент заморожен');
+      document.getElementById('editEndDate').value = res.end_date || document.getElementById('editEndDate').value;
+      ['freezeStart','freezeEnd','freezeReason'].forEach(i=>{ document.getElementById(i).value=''; });
+      await reloadFreezes(id);
+    }catch(err){ alert('❌ ' + err.message); }
+    finally{ btn.disabled=false; }
+  });
+
+  // ЗАВЕРШИТЬ / ОТМЕНИТЬ ЗАМОРОЗКУ
+  document.addEventListener('click', async (ev) => {
+    const btn = ev.target.closest('.end-freeze-btn');
+    if(!btn) return;
+    if(!confirm(`${btn.textContent.trim()} заморозку? Неиспользованные дни вернутся в лимит, окончание абонемента сдвинется назад.`)) return;
+    const id = document.getElementById('editSubId').value;
+    try{
+      const resp = await fetch(`/api/v1/freezes/${btn.getAttribute('data-freeze-id')}/end`, {method:'POST'});
+      const res = await parseJsonOrThrow(resp);
+      if(!res.success) throw new Error(res.error||'Не удалось завершить заморозку');
+      const sresp = await fetch(`/subscriptions/${id}`);
+      const sres = await parseJsonOrThrow(sresp);
+      if(sres.success){
+        document.getElementById('editEndDate').value = (sres.subscription.end_date||'').slice(0,10);
+        renderFreezes(sres.freezes, sres.allowance);
+      }
+      alert(res.message||'Готово');
+    }catch(err){ alert('❌ ' + err.message); }
+  });
+
+  // ПРОДЛЕНИЕ (🔁) на тот же тариф; сменить тариф — из виджета на главной
+  document.addEventListener('click', async (ev) => {
+    const btn = ev.target.closest('.renew-sub-btn');
+    if(!btn) return;
+    const id = btn.getAttribute('data-sub-id');
+    const clientName = btn.getAttribute('data-client-name')||'';
+    if(!confirm(`Продлить абонемент #${id} клиента «${clientName}» на тот же тариф?`)) return;
+    try{
+      const resp = await fetch(`/api/v1/subscriptions/${id}/renew`, {method:'POST', body:new FormData()});
+      const res = await parseJsonOrThrow(resp);
+      if(res.success){ alert('✅ ' + res.message); location.reload(); }
+      else { alert('❌ ' + (res.error||'Ошибка продления') + (res.renewal_id ? ` (#${res.renewal_id})` : '')); }
+    }catch(err){ alert('❌ ' + err.message); }
+  });
+
   // УДАЛЕНИЕ (🗑️)
   document.addEventListener('click', async (ev) => {
     const btn = ev.target.closest('.delete-sub-btn');
@@ -40,7 +40,8 @@ document.addEventListener('DOMContentLoaded', () => {
       const tname = t.name || t.Name || t['название_тарифа'] || '';
       const tdesc = t.description || t.Description || t['описание'] || '';
       const tprice = (t.price != null ? t.price : (t['стоимость'] != null ? t['стоимость'] : ''));
-      const taccess = t.access_time || t.AccessTime || t['время_доступа'] || '';
+      const thours = res.access_hours || '';
+      const tissue = t['часы_доступа_ошибка'] || '';
       const thasGroup = !!(t.has_group_trainings || t.HasGroupTrainings || t['наличие_групповых_тренировок']);
       const thasPersonal = !!(t.has_personal_trainings || t.HasPersonalTrainings || t['наличие_персональных_тренировок']);
 
@@ -48,7 +49,10 @@ document.addEventListener('DOMContentLoaded', () => {
       document.getElementById('editName').value = tname;
       document.getElementById('editDescription').value = tdesc;
       document.getElementById('editPrice').value = tprice !== '' ? String(tprice) : '';
-      document.getElementById('editAccessTime').value = taccess;
+      document.getElementById('editAccessHours').value = thours;
+      document.getElementById('editAccessIssue').textContent = tissue ? `⚠️ Старое значение «${t['время_доступа']||''}»: ${tissue}` : '';
+      document.getElementById('editFreezeMaxCount').value = t['заморозка_макс_раз'] != null ? String(t['заморозка_макс_раз']) : '';
+      document.getElementById('editFreezeMaxDays').value = t['заморозка_макс_дней'] != null ? String(t['заморозка_макс_дней']) : '';
       document.getElementById('editHasGroup').checked = thasGroup;
       document.getElementById('editHasPersonal').checked = thasPersonal;
       new bootstrap.Modal(document.getElementById('editTariffModal')).show();
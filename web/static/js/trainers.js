@@ -63,6 +63,131 @@ document.addEventListener('DOMContentLoaded', ()=>{
     finally{ btn.disabled=false; btn.innerHTML='Обновить'; }
   });
 
+  // SCHEDULE: рабочие часы, отсутствия, свободные слоты
+  const tsModal=document.getElementById('trainerScheduleModal');
+  const esc=(v)=>String(v??'').replace(/[&<>"']/g,ch=>({'&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;',"'":'&#39;'}[ch]));
+  const ruDate=(d)=>d.split('-').reverse().join('.');
+
+  async function loadSchedule(){
+    const id=tsModal.dataset.id;
+    const res=await parseJsonOrThrow(await fetch(`/api/v1/trainers/${id}/schedule`,{cache:'no-store'}));
+    if(!res.success) throw new Error(res.error||'Не удалось загрузить расписание');
+    document.getElementById('tsHours').value = res.hours?.length ? res.working_hours : '';
+    const ul=document.getElementById('tsAbsences');
+    ul.innerHTML = res.absences.length ? res.absences.map(a=>`
+      <li class="list-group-item d-flex justify-content-between align-items-center">
+        <span><b>${esc(a.kind)}</b> ${ruDate(a.start_date)} — ${ruDate(a.end_date)}
+          <span class="text-muted">${esc(a.reason)}</span></span>
+        <button class="btn btn-sm btn-outline-danger ts-absence-del" data-id="${a.id}" title="Удалить">✖</button>
+      </li>`).join('') : '<li class="list-group-item text-muted">Нет</li>';
+  }
+
+  document.querySelectorAll('.schedule-tr-btn').forEach(btn=>{
+    btn.addEventListener('click', async ()=>{
+      tsModal.dataset.id=btn.getAttribute('data-tr-id');
+      document.getElementById('tsName').textContent=btn.getAttribute('data-tr-name')||'';
+      document.getElementById('tsSlots').innerHTML='';
+      try{ await loadSchedule(); new bootstrap.Modal(tsModal).show(); }
+      catch(e){ alert('❌ '+e.message); }
+    });
+  });
+
+  document.getElementById('tsHoursForm')?.addEventListener('submit', async (e)=>{
+    e.preventDefault();
+    try{
+      const body=new URLSearchParams(new FormData(e.currentTarget));
+      const res=await parseJsonOrThrow(await fetch(`/api/v1/trainers/${tsModal.dataset.id}/working-hours`,{method:'PUT', body}));
+      if(!res.success) throw new Error(res.error||'Не удалось сохранить');
+      alert('✅ '+res.message);
+      await loadSchedule();
+    }catch(e2){ alert('❌ '+e2.message); }
+  });
+
+  document.getElementById('tsAbsenceForm')?.addEventListener('submit', async (e)=>{
+    e.preventDefault();
+    const form=e.currentTarget;
+    try{
+      const res=await parseJsonOrThrow(await fetch(`/api/v1/trainers/${tsModal.dataset.id}/absences`,{method:'POST', body:new FormData(form)}));
+      if(!res.success) throw new Error(res.error||'Не удалось сохранить');
+      const list=(res.trainings||[]).map(t=>`• ${t.title} ${t.start}`).join('\n');
+      alert('✅ '+res.message+(list?'\n'+list:''));
+      form.reset();
+      await loadSchedule();
+    }catch(e2){ alert('❌ '+e2.message); }
+  });
+
+  tsModal?.addEventListener('click', async (e)=>{
+    const btn=e.target.closest('.ts-absence-del');
+    if(!btn || !confirm('Удалить отсутствие?')) return;
+    try{
+      const res=await parseJsonOrThrow(await fetch(`/api/v1/trainer-absences/${btn.dataset.id}`,{method:'DELETE'}));
+      if(!res.success) throw new Error(res.error||'Не удалось удалить');
+      await loadSchedule();
+    }catch(e2){ alert('❌ '+e2.message); }
+  });
+
+  document.getElementById('tsSlotsForm')?.addEventListener('submit', async (e)=>{
+    e.preventDefault();
+    const box=document.getElementById('tsSlots');
+    const qs=new URLSearchParams([...new FormData(e.currentTarget)].filter(([,v])=>v!==''));
+    try{
+      const res=await parseJsonOrThrow(await fetch(`/api/v1/trainers/${tsModal.dataset.id}/availability?${qs}`,{cache:'no-store'}));
+      if(!res.success) throw new Error(res.error||'Не удалось найти слоты');
+      if(!res.slots.length){ box.innerHTML='<div class="text-muted">Свободного времени нет</div>'; return; }
+      const byDay={};
+      res.slots.forEach(s=>{ (byDay[s.date]??=[]).push(s.start); });
+      box.innerHTML=Object.entries(byDay).map(([d,list])=>
+        `<div class="mb-1"><b>${ruDate(d)}</b>: ${list.join(', ')}</div>`).join('');
+    }catch(e2){ box.innerHTML=`<div class="text-danger">${esc(e2.message)}</div>`; }
+  });
+
+  // RATES: ставки тренера
+  const trModal=document.getElementById('trainerRatesModal');
+
+  async function loadRates(){
+    const res=await parseJsonOrThrow(await fetch(`/api/v1/trainers/${trModal.dataset.id}/rates`,{cache:'no-store'}));
+    if(!res.success) throw new Error(res.error||'Не удалось загрузить ставки');
+    const canEdit=!!trModal.dataset.canEdit, today=new Date().toISOString().slice(0,10);
+    document.getElementById('trRates').innerHTML = res.rates.length ? res.rates.map(r=>`
+      <tr class="${r.current?'table-success':''}">
+        <td>${ruDate(r.effective_from)}</td><td>${esc(r.category)}</td><td>${r.hourly_rate.toFixed(2)}</td>
+        <td>${esc(r.peak_hours)||'—'}</td><td>${r.peak_surcharge}</td><td>${r.weekend_surcharge}</td>
+        <td>${canEdit && r.effective_from>today
+          ? `<button class="btn btn-sm btn-outline-danger tr-rate-del" data-id="${r.id}" title="Удалить">✖</button>` : ''}</td>
+      </tr>`).join('') : '<tr><td colspan="7" class="text-muted">Ставок нет — стоимость персональных вводится вручную</td></tr>';
+  }
+
+  document.querySelectorAll('.rates-tr-btn').forEach(btn=>{
+    btn.addEventListener('click', async ()=>{
+      trModal.dataset.id=btn.getAttribute('data-tr-id');
+      document.getElementById('trName').textContent=btn.getAttribute('data-tr-name')||'';
+      try{ await loadRates(); new bootstrap.Modal(trModal).show(); }
+      catch(e){ alert('❌ '+e.message); }
+    });
+  });
+
+  document.getElementById('trRateForm')?.addEventListener('submit', async (e)=>{
+    e.preventDefault();
+    const form=e.currentTarget;
+    try{
+      const res=await parseJsonOrThrow(await fetch(`/api/v1/trainers/${trModal.dataset.id}/rates`,{method:'POST', body:new FormData(form)}));
+      if(!res.success) throw new Error(res.error||'Не удалось сохранить');
+      alert('✅ '+res.message);
+      form.reset();
+      await loadRates();
+    }catch(e2){ alert('❌ '+e2.message); }
+  });
+
+  trModal?.addEventListener('click', async (e)=>{
+    const btn=e.target.closest('.tr-rate-del');
+    if(!btn || !confirm('Удалить ставку?')) return;
+    try{
+      const res=await parseJsonOrThrow(await fetch(`/api/v1/trainer-rates/${btn.dataset.id}`,{method:'DELETE'}));
+      if(!res.success) throw new Error(res.error||'Не удалось удалить');
+      await loadRates();
+    }catch(e2){ alert('❌ '+e2.message); }
+  });
+
   // DELETE
   document.querySelectorAll('.delete-tr-btn').forEach(btn=>{
     btn.addEventListener('click', async ()=>{
@@ -6,6 +6,30 @@ async function parseJsonOrThrow(resp) {
   if (ct.includes('application/json') || ct.includes('application/problem+json')) return resp.json();
   throw new Error((await resp.text()) || 'Сервер вернул не-JSON');
 }
+// Запрос, который сервер может отклонить из-за пересечения по расписанию
+// (409, reason schedule_conflict): показываем список, администратору
+// предлагаем сохранить всё равно — повтор с force=1.
+// Отказ по правилам абонемента (403, can_override) администратор может
+// обойти, указав основание, — повтор с override=1 и override_reason.
+async function sendChecked(url, init) {
+  let data = await parseJsonOrThrow(await fetch(url, init));
+  if (!data.success && data.can_override && init.body instanceof FormData) {
+    const reason = prompt('⚠️ ' + data.error + '\n\nЗаписать как исключение? Укажите основание:');
+    if (!reason || !reason.trim()) return { success: false, cancelled: true };
+    init.body.set('override', '1');
+    init.body.set('override_reason', reason.trim());
+    data = await parseJsonOrThrow(await fetch(url, init));
+  }
+  if (data.success || data.reason !== 'schedule_conflict') return data;
+  const list = (data.conflicts || []).map(x =>
+    `• ${x.title} ${x.start}–${x.end.slice(-5)} (${x.reasons.map(r => r === 'trainer' ? 'тренер ' + x.trainer : 'зона «' + x.zone + '»').join(', ')})`);
+  // сохранить с пересечением может только администратор; текст ошибки
+  // сервера и так перечисляет пересечения
+  if (!document.getElementById('trainingsHeader')?.dataset.canForce || !list.length) return data;
+  if (!confirm('⚠️ Пересечение по расписанию:\n' + list.join('\n') + '\n\nСохранить несмотря на пересечение?')) return { success: false, cancelled: true };
+  const forced = url + (url.includes('?') ? '&' : '?') + 'force=1';
+  return parseJsonOrThrow(await fetch(forced, init));
+}
 async function fill(url, select, key) {
   try {
     const resp = await fetch(url, { cache: 'no-store' });
@@ -68,15 +92,38 @@ document.addEventListener('DOMContentLoaded', () => {
       const btn = e.submitter ?? e.target.querySelector('button[type="submit"]');
       if (btn) { btn.disabled = true; btn.textContent = '⌛...'; }
       try {
-        const resp = await fetch('/group-trainings', { method: 'POST', body: new FormData(e.target) });
-        const data = await parseJsonOrThrow(resp);
-        if (data.success) { bootstrap.Modal.getInstance(addGroupModal)?.hide(); location.reload(); }
-        else alert('❌ ' + (data.error || 'Ошибка'));
+        // с повтором — создаём серию (RRULE), иначе разовое занятие
+        const body = new FormData(e.target);
+        const rrule = buildRRule();
+        if (rrule) body.append('rrule', rrule);
+        const data = await sendChecked(rrule ? '/api/v1/training-series' : '/group-trainings', { method: 'POST', body });
+        if (data.success) {
+          if (rrule) alert('✅ ' + data.message);
+          bootstrap.Modal.getInstance(addGroupModal)?.hide(); location.reload();
+        }
+        else if (!data.cancelled) alert('❌ ' + (data.error || 'Ошибка'));
       } catch (er) { alert('❌ ' + er.message); }
       finally { if (btn) { btn.disabled = false; btn.textContent = 'Сохранить'; } }
     });
   }
 
+  // ===== Групповые: повтор (серия) =====
+  document.getElementById('grpRepeat')?.addEventListener('change', (e) => {
+    document.getElementById('grpByDayBox').hidden = e.target.value !== 'WEEKLY';
+  });
+  function buildRRule() {
+    const freq = document.getElementById('grpRepeat')?.value;
+    if (!freq) return '';
+    const parts = ['FREQ=' + freq];
+    const days = [...document.querySelectorAll('.grp-byday:checked')].map((el) => el.value);
+    if (freq === 'WEEKLY' && days.length) parts.push('BYDAY=' + days.join(','));
+    const until = document.getElementById('grpUntil').value;
+    const count = document.getElementById('grpCount').value;
+    if (until) parts.push('UNTIL=' + until.replaceAll('-', ''));
+    else if (count) parts.push('COUNT=' + count);
+    return parts.join(';');
+  }
+
 // ===== Групповые: редактирование =====
 document.querySelectorAll('.edit-group-btn').forEach(btn => {
   btn.addEventListener('click', async () => {
@@ -98,6 +145,9 @@ document.querySelectorAll('.edit-group-btn').forEach(btn => {
       document.getElementById('egEnd').value       = data.item.EndTime;
       document.getElementById('egTrainer').value   = data.item.TrainerID;
       document.getElementById('egZone').value      = data.item.ZoneID;
+      document.getElementById('egScopeBox').hidden = !data.item.SeriesID;
+      document.getElementById('egScope').value     = 'this';
+      document.getElementById('egRRule').disabled  = true;
 
       const modal = new bootstrap.Modal(document.getElementById('editGroupModal'));
       modal.show();
@@ -124,17 +174,28 @@ document.querySelectorAll('.edit-group-btn').forEach(btn => {
     const btn = e.submitter ?? form.querySelector('button[type="submit"]');
     if (btn) { btn.disabled = true; btn.textContent = '⌛...'; }
     try {
-      const resp = await fetch(`/group-trainings/${id}`, { method: 'PUT', body: new FormData(form) });
-      const data = await parseJsonOrThrow(resp);
+      const data = await sendChecked(`/group-trainings/${id}`, { method: 'PUT', body: new FormData(form) });
       if (data.success) { bootstrap.Modal.getInstance(document.getElementById('editGroupModal'))?.hide(); location.reload(); }
-      else alert('❌ ' + (data.error || 'Ошибка обновления'));
+      else if (!data.cancelled) alert('❌ ' + (data.error || 'Ошибка обновления'));
     } catch (er) { alert('❌ ' + er.message); }
     finally { if (btn) { btn.disabled = false; btn.textContent = 'Сохранить'; } }
   });
 
+  document.getElementById('egScope')?.addEventListener('change', (e) => {
+    document.getElementById('egRRule').disabled = e.target.value === 'this';
+  });
+
   // ===== Групповые: удаление =====
   document.querySelectorAll('.delete-group-btn').forEach(btn => {
     btn.addEventListener('click', async () => {
+      if (btn.dataset.seriesId) {
+        // занятие серии: спросим, что именно удалить
+        document.getElementById('dgId').value = btn.dataset.id;
+        document.getElementById('dgThis').checked = true;
+        document.getElementById('dgReason').value = '';
+        bootstrap.Modal.getOrCreateInstance(document.getElementById('deleteGroupScopeModal')).show();
+        return;
+      }
       if (!confirm('Удалить групповую тренировку?')) return;
       const id = btn.getAttribute('data-id');
       try {
@@ -144,6 +205,74 @@ document.querySelectorAll('.edit-group-btn').forEach(btn => {
     });
   });
 
+  document.getElementById('deleteGroupScopeForm')?.addEventListener('submit', async (e) => {
+    e.preventDefault();
+    const id = document.getElementById('dgId').value;
+    const scope = e.target.querySelector('input[name="scope"]:checked')?.value || 'this';
+    const qs = new URLSearchParams({ scope, reason: document.getElementById('dgReason').value.trim() });
+    try {
+      const data = await parseJsonOrThrow(await fetch(`/group-trainings/${id}?${qs}`, { method: 'DELETE' }));
+      if (data.success) location.reload(); else alert('❌ ' + (data.error || 'Ошибка'));
+    } catch (er) { alert('❌ ' + er.message); }
+  });
+
+  // ===== Серии: просмотр и исключения =====
+  const seriesModal = document.getElementById('seriesModal');
+  async function openSeries(id) {
+    try {
+      const data = await parseJsonOrThrow(await fetch(`/api/v1/training-series/${id}`, { cache: 'no-store' }));
+      if (!data.success) throw new Error(data.error || 'Серия не найдена');
+      const sr = data.series;
+      seriesModal.dataset.id = sr.id;
+      document.getElementById('seriesTitle').textContent = `🔁 Серия #${sr.id} — ${sr.title}`;
+      document.getElementById('seriesRule').textContent =
+        `${sr.rule_text}, с ${sr.date.split('-').reverse().join('.')} в ${sr.start_time}, ${sr.duration_min} мин`;
+      document.getElementById('seriesInfo').textContent =
+        `${sr.trainer_name} · ${sr.zone_name} · до ${sr.max} чел. · предстоящих занятий: ${sr.upcoming} · ${sr.rrule}`;
+      const ex = data.exceptions || [];
+      document.getElementById('seriesExceptions').innerHTML = ex.length
+        ? `<ul class="list-group">${ex.map((x) => `
+            <li class="list-group-item d-flex justify-content-between align-items-center">
+              <span>${x.date.split('-').reverse().join('.')} <span class="text-muted">${x.reason || ''}</span></span>
+              <button class="btn btn-sm btn-outline-primary series-restore-btn" data-date="${x.date}" title="Вернуть занятие">↺</button>
+            </li>`).join('')}</ul>`
+        : '<div class="text-muted">Нет.</div>';
+      bootstrap.Modal.getOrCreateInstance(seriesModal).show();
+    } catch (er) { alert('❌ ' + er.message); }
+  }
+  document.addEventListener('click', (e) => {
+    const btn = e.target.closest('.series-btn');
+    if (btn) openSeries(btn.dataset.seriesId);
+  });
+  document.addEventListener('click', async (e) => {
+    const btn = e.target.closest('.series-restore-btn');
+    if (!btn) return;
+    btn.disabled = true;
+    try {
+      const data = await sendChecked(
+        `/api/v1/training-series/${seriesModal.dataset.id}/exceptions/${btn.dataset.date}`, { method: 'DELETE' });
+      if (data.cancelled) { btn.disabled = false; return; }
+      if (!data.success) throw new Error(data.error || 'Ошибка');
+      alert('✅ ' + data.message);
+      seriesModal.dataset.changed = '1';
+      openSeries(seriesModal.dataset.id);
+    } catch (er) { alert('❌ ' + er.message); btn.disabled = false; }
+  });
+  document.getElementById('seriesExceptionForm')?.addEventListener('submit', async (e) => {
+    e.preventDefault();
+    try {
+      const data = await parseJsonOrThrow(await fetch(`/api/v1/training-series/${seriesModal.dataset.id}/exceptions`,
+        { method: 'POST', body: new FormData(e.target) }));
+      if (!data.success) throw new Error(data.error || 'Ошибка');
+      alert('✅ ' + data.message);
+      e.target.reset();
+      seriesModal.dataset.changed = '1';
+      openSeries(seriesModal.dataset.id);
+    } catch (er) { alert('❌ ' + er.message); }
+  });
+  // исключения меняют список занятий — обновим страницу
+  seriesModal?.addEventListener('hidden.bs.modal', () => { if (seriesModal.dataset.changed) location.reload(); });
+
   // ===== Персональная: добавление =====
   const addPersonalModal = document.getElementById('addPersonalModal');
   if (addPersonalModal) {
@@ -151,15 +280,33 @@ document.querySelectorAll('.edit-group-btn').forEach(btn => {
       await fill('/api/subscriptions-for-select', document.getElementById('perSub'),    'subscriptions');
       await fill('/api/trainers-for-select',      document.getElementById('perTrainer'),'trainers');
     });
-    document.getElementById('addPersonalForm')?.addEventListener('submit', async (e) => {
+    // стоимость по ставке тренера — подсказка, пока поле «Стоимость» пустое
+    const perForm = document.getElementById('addPersonalForm');
+    perForm?.addEventListener('change', async (e) => {
+      if (!['trainer_id', 'date', 'start_time', 'end_time'].includes(e.target.name)) return;
+      const box = document.getElementById('perQuote');
+      const f = new FormData(perForm);
+      box.textContent = '';
+      if (!f.get('trainer_id') || !f.get('date') || !f.get('start_time') || !f.get('end_time')) return;
+      const qs = new URLSearchParams({ date: f.get('date'), start_time: f.get('start_time'), end_time: f.get('end_time') });
+      try {
+        const data = await parseJsonOrThrow(await fetch(`/api/v1/trainers/${f.get('trainer_id')}/price-quote?${qs}`, { cache: 'no-store' }));
+        if (!data.success) return;
+        const q = data.quote;
+        box.textContent = q
+          ? `По ставке «${q['категория']}»: ${q['стоимость'].toFixed(2)} ₽ (${q['минут']} мин` +
+            (q['минут_пик'] ? `, пик ${q['минут_пик']} мин` : '') + (q['минут_выходные'] ? ', выходной' : '') + ')'
+          : data.message;
+      } catch (er) { console.error('[price-quote]', er); }
+    });
+    perForm?.addEventListener('submit', async (e) => {
       e.preventDefault();
       const btn = e.submitter ?? e.target.querySelector('button[type="submit"]');
       if (btn) { btn.disabled = true; btn.textContent = '⌛...'; }
       try {
-        const resp = await fetch('/personal-trainings', { method: 'POST', body: new FormData(e.target) });
-        const data = await parseJsonOrThrow(resp);
+        const data = await sendChecked('/personal-trainings', { method: 'POST', body: new FormData(e.target) });
         if (data.success) { bootstrap.Modal.getInstance(addPersonalModal)?.hide(); location.reload(); }
-        else alert('❌ ' + (data.error || 'Ошибка'));
+        else if (!data.cancelled) alert('❌ ' + (data.error || 'Ошибка'));
       } catch (er) { alert('❌ ' + er.message); }
       finally { if (btn) { btn.disabled = false; btn.textContent = 'Сохранить'; } }
     });
@@ -184,6 +331,10 @@ document.querySelectorAll('.edit-personal-btn').forEach(btn => {
       document.getElementById('epEnd').value    = data.item.EndTime;
       document.getElementById('epStatus').value = data.item.Status;
       document.getElementById('epPrice').value  = data.item.Price;
+      const rate = data.item.Rate;
+      document.getElementById('epRate').textContent = rate
+        ? `Посчитано по ставке «${rate['категория']}» от ${rate['действует_с'].split('-').reverse().join('.')}; при переносе пересчитается`
+        : '';
 
       const modal = new bootstrap.Modal(document.getElementById('editPersonalModal'));
       modal.show();
@@ -209,10 +360,9 @@ document.querySelectorAll('.edit-personal-btn').forEach(btn => {
     const btn = e.submitter ?? form.querySelector('button[type="submit"]');
     if (btn) { btn.disabled = true; btn.textContent = '⌛...'; }
     try {
-      const resp = await fetch(`/personal-trainings/${id}`, { method: 'PUT', body: new FormData(form) });
-      const data = await parseJsonOrThrow(resp);
+      const data = await sendChecked(`/personal-trainings/${id}`, { method: 'PUT', body: new FormData(form) });
       if (data.success) { bootstrap.Modal.getInstance(document.getElementById('editPersonalModal'))?.hide(); location.reload(); }
-      else alert('❌ ' + (data.error || 'Ошибка обновления'));
+      else if (!data.cancelled) alert('❌ ' + (data.error || 'Ошибка обновления'));
     } catch (er) { alert('❌ ' + er.message); }
     finally { if (btn) { btn.disabled = false; btn.textContent = 'Сохранить'; } }
   });
@@ -231,9 +381,12 @@ document.querySelectorAll('.edit-personal-btn').forEach(btn => {
     const btn = e.submitter ?? e.target.querySelector('button[type="submit"]');
     if (btn) { btn.disabled = true; btn.textContent = '⌛...'; }
     try {
-      const data = await parseJsonOrThrow(await fetch('/group-enrollments', { method:'POST', body: new FormData(e.target) }));
-      if (data.success) { bootstrap.Modal.getInstance(document.getElementById('enrollModal'))?.hide(); location.reload(); }
-      else alert('❌ ' + (data.error || 'Ошибка'));
+      const data = await sendChecked('/group-enrollments', { method:'POST', body: new FormData(e.target) });
+      if (data.success) {
+        if (data.status === 'В очереди') alert('⏳ ' + data.message);
+        bootstrap.Modal.getInstance(document.getElementById('enrollModal'))?.hide(); location.reload();
+      }
+      else if (!data.cancelled) alert('❌ ' + (data.error || 'Ошибка'));
     } catch (e2) { alert('❌ ' + e2.message); }
     finally { if (btn) { btn.disabled = false; btn.textContent = 'Создать запись'; } }
   });
@@ -284,80 +437,6 @@ document.querySelectorAll('.edit-personal-btn').forEach(btn => {
   }
 });
 
-// === список записей на групповую ===
-document.addEventListener('click', async (e) => {
-  const btn = e.target.closest('.list-enroll-btn');
-  if (!btn) return;
-
-  const groupId = btn.getAttribute('data-id');
-  const title   = btn.getAttribute('data-title') || '';
-
-  let data;
-  try {
-    data = await parseJsonOrThrow(
-      await fetch(`/api/group-trainings/${groupId}/enrollments`, { cache: 'no-store' })
-    );
-    if (!data.success) throw new Error(data.error || 'Ошибка загрузки');
-  } catch (err) {
-    console.error(err);
-    alert('❌ ' + err.message);
-    return;
-  }
-
-  const modalEl = document.getElementById('enrollListModal');
-  const titleEl = document.getElementById('enrollListTitle');
-  const boxEl   = document.getElementById('enrollListContainer');
-
-  if (!modalEl || !titleEl || !boxEl) {
-    console.warn('Модалка списка записей не найдена в DOM');
-    // как минимум покажем данные
-    alert(data.enrollments?.length ? 'Записанных: ' + data.enrollments.length : 'Пока никто не записан.');
-    return;
-  }
-
-  titleEl.value = `#${groupId} — ${title}`;
-
-  const list = data.enrollments || [];
-  if (list.length === 0) {
-    boxEl.innerHTML = `<div class="alert alert-info mb-0">Пока никто не записан.</div>`;
-  } else {
-    const rows = list.map((e, idx) => `
-      <tr>
-        <td>${idx+1}</td>
-        <td>${e.client_fio} <span class="text-muted">(#${e.client_id})</span></td>
-        <td>#${e.subscription_id}</td>
-        <td>
-          <span class="badge ${
-            e.status === 'Посетил' ? 'bg-success' :
-            e.status === 'Отменил' ? 'bg-secondary' : 'bg-primary'
-          }">${e.status}</span>
-        </td>
-        <td class="text-muted">id: ${e.id}</td>
-      </tr>
-    `).join('');
-    boxEl.innerHTML = `
-      <div class="table-responsive">
-        <table class="table table-striped table-hover align-middle">
-          <thead class="table-dark">
-            <tr>
-              <th>#</th><th>Клиент</th><th>Абонемент</th><th>Статус</th><th>Запись</th>
-            </tr>
-          </thead>
-          <tbody>${rows}</tbody>
-        </table>
-      </div>
-    `;
-  }
-
-  // 3) Открываем модалку 
-  if (window.bootstrap?.Modal) {
-    new bootstrap.Modal(modalEl).show();
-  } else {
-    console.warn('Bootstrap JS не найден');
-  }
-});
-
-
 // ================== СПИСОК ЗАПИСАННЫХ (полная реализация) ==================
 (() => {
   // Создаём (или берём) модалку. Если в HTML её нет — добавим динамически.
@@ -415,12 +494,16 @@ document.addEventListener('click', async (e) => {
       boxEl.innerHTML = `<div class="alert alert-info mb-0">Пока никто не записан.</div>`;
       return;
     }
+    const esc = v => String(v ?? '').replace(/[&<>"']/g, ch => ({'&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;',"'":'&#39;'}[ch]));
     const rows = list
       .map(
         (e, idx) => `
         <tr>
-          <td>${idx + 1}</td>
-          <td>${e.client_fio} <span class="text-muted">(#${e.client_id})</span></td>
+          <td>${e.status === 'Записан'
+            ? `<input type="checkbox" class="form-check-input attend-check" value="${e.id}" title="Пришёл">`
+            : idx + 1}</td>
+          <td>${e.client_fio} <span class="text-muted">(#${e.client_id})</span>${e.override_reason
+            ? ` <span class="badge bg-danger-subtle text-danger-emphasis" title="${esc(e.override_reason)}">исключение</span>` : ''}</td>
           <td>#${e.subscription_id}</td>
           <td>
             <span class="badge ${
@@ -428,10 +511,19 @@ document.addEventListener('click', async (e) => {
                 ? 'bg-success'
                 : e.status === 'Отменил'
                 ? 'bg-secondary'
+                : e.status === 'В очереди'
+                ? 'bg-warning text-dark'
                 : 'bg-primary'
-            }">${e.status}</span>
+            }">${e.status}${e.queue_position ? ' #' + e.queue_position : ''}</span>
           </td>
-          <td class="text-muted">id: ${e.id}</td>
+          <td class="text-muted">id: ${e.id}<small class="d-block">${e.enrolled_at || ''}</small></td>
+          <td class="text-nowrap">${
+            e.status === 'Отменил'
+              ? `<button class="btn btn-sm btn-outline-primary enroll-status-btn" data-id="${e.id}" data-status="Записан" title="Вернуть запись">↺</button>`
+              : e.status === 'Посетил'
+              ? `<button class="btn btn-sm btn-outline-secondary enroll-status-btn" data-id="${e.id}" data-status="Записан" title="Снять отметку о посещении">↩</button>`
+              : `<button class="btn btn-sm btn-outline-danger cancel-enroll-btn" data-id="${e.id}" title="Отменить запись">✖</button>`
+          }</td>
         </tr>
       `
       )
@@ -442,22 +534,86 @@ document.addEventListener('click', async (e) => {
         <table class="table table-striped table-hover align-middle">
           <thead class="table-dark">
             <tr>
-              <th>#</th><th>Клиент</th><th>Абонемент</th><th>Статус</th><th>Запись</th>
+              <th>#</th><th>Клиент</th><th>Абонемент</th><th>Статус</th><th>Запись</th><th></th>
             </tr>
           </thead>
           <tbody>${rows}</tbody>
         </table>
       </div>
+      ${list.some((e) => e.status === 'Записан')
+        ? `<button type="button" class="btn btn-success btn-sm" id="markAttendanceBtn">✔ Отметить пришедших</button>`
+        : ''}
     `;
   }
 
-  // Делегированный единственный обработчик клика
+  function reloadEnrollList() {
+    const modalEl = ensureEnrollListModal();
+    openEnrollList(modalEl.dataset.groupId, modalEl.dataset.title);
+  }
+
+  // ✔ массовая отметка посещения по галочкам
   document.addEventListener('click', async (e) => {
+    const btn = e.target.closest('#markAttendanceBtn');
+    if (!btn) return;
+    const ids = [...document.querySelectorAll('#enrollListContainer .attend-check:checked')].map((el) => Number(el.value));
+    if (ids.length === 0) { alert('Отметьте галочками пришедших'); return; }
+    const groupId = ensureEnrollListModal().dataset.groupId;
+    btn.disabled = true;
+    try {
+      const data = await parseJsonOrThrow(await fetch(`/api/v1/group-trainings/${groupId}/attendance`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ enrollment_ids: ids }),
+      }));
+      if (!data.success) {
+        // отметка откатилась целиком — подсвечиваем запись, из-за которой
+        document.querySelector(`#enrollListContainer .attend-check[value="${data.failed_id}"]`)
+          ?.closest('tr')?.classList.add('table-danger');
+        throw new Error(data.error || 'Ошибка отметки');
+      }
+      alert('✅ ' + data.message);
+      reloadEnrollList();
+    } catch (err) { alert('❌ ' + err.message); btn.disabled = false; }
+  });
+
+  // ↺ / ↩ смена статуса одной записи
+  document.addEventListener('click', async (e) => {
+    const btn = e.target.closest('.enroll-status-btn');
+    if (!btn) return;
+    btn.disabled = true;
+    try {
+      const body = new URLSearchParams({ status: btn.dataset.status });
+      const data = await parseJsonOrThrow(await fetch(`/api/v1/group-enrollments/${btn.dataset.id}`, { method: 'PUT', body }));
+      if (!data.success) throw new Error(data.error || 'Ошибка изменения');
+      reloadEnrollList();
+    } catch (err) { alert('❌ ' + err.message); btn.disabled = false; }
+  });
+
+  // ✖ отмена записи: место получает первый из листа ожидания
+  document.addEventListener('click', async (e) => {
+    const btn = e.target.closest('.cancel-enroll-btn');
+    if (!btn) return;
+    if (!confirm('Отменить запись?')) return;
+    btn.disabled = true;
+    try {
+      const data = await parseJsonOrThrow(await fetch(`/api/v1/group-enrollments/${btn.dataset.id}/cancel`, { method: 'POST' }));
+      if (!data.success) throw new Error(data.error || 'Ошибка отмены');
+      alert('✅ ' + data.message);
+      reloadEnrollList();
+    } catch (err) { alert('❌ ' + err.message); btn.disabled = false; }
+  });
+
+  // Делегированный единственный обработчик клика
+  document.addEventListener('click', (e) => {
     const btn = e.target.closest('.list-enroll-btn');
     if (!btn) return;
+    openEnrollList(btn.getAttribute('data-id'), btn.getAttribute('data-title') || '');
+  });
 
-    const groupId = btn.getAttribute('data-id');
-    const title = btn.getAttribute('data-title') || '';
+  async function openEnrollList(groupId, title) {
+    const listModal = ensureEnrollListModal();
+    listModal.dataset.groupId = groupId;
+    listModal.dataset.title = title;
 
     // 1) Тянем данные (Network-запрос выполнится независимо от наличия модалки в DOM)
     let data;
@@ -496,6 +652,6 @@ document.addEventListener('click', async (e) => {
     } else {
       console.warn('[enroll-list] Bootstrap JS не найден — модалка не откроется.');
     }
-  });
+  }
 })();
 
@@ -131,7 +131,7 @@ document.addEventListener('DOMContentLoaded', () => {
     const overlay = document.createElement('div');
     overlay.style.cssText = 'position:fixed;inset:0;background:rgba(0,0,0,.8);display:flex;justify-content:center;align-items:center;z-index:9999;cursor:zoom-out;';
     const img = document.createElement('img');
-    img.src = e.target.src;
+    img.src = e.target.src.replace('size=thumb', 'size=medium');
     img.style.cssText = 'max-width:90%;max-height:90%;border-radius:8px;box-shadow:0 0 30px rgba(0,0,0,.5);';
     overlay.appendChild(img);
     overlay.addEventListener('click', () => document.body.removeChild(overlay));